@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -64,11 +65,23 @@ type ThanosCompactSpec struct {
 	// Minimum time range to serve. Any data earlier than this lower time range will be ignored.
 	// If not set, will be set as zero value, so most recent blocks will be served.
 	// +kubebuilder:validation:Optional
-	MinTime *Duration `json:"minTime,omitempty"`
+	MinTime *TimeOrDuration `json:"minTime,omitempty"`
 	// Maximum time range to serve. Any data after this upper time range will be ignored.
 	// If not set, will be set as max value, so all blocks will be served.
 	// +kubebuilder:validation:Optional
-	MaxTime *Duration `json:"maxTime,omitempty"`
+	MaxTime *TimeOrDuration `json:"maxTime,omitempty"`
+	// BlockMarkers marks specific blocks for deletion or excludes them from compaction.
+	// Each entry is executed once through a managed Job using the object storage configuration of this resource.
+	// An entry is only executed once it has been confirmed.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	BlockMarkers []BlockMarker `json:"blockMarkers,omitempty"`
+	// Schedule restricts compaction and downsampling to recurring time windows.
+	// Outside of the windows, the Compactor is scaled to zero.
+	// If not set, the Compactor runs continuously.
+	// +kubebuilder:validation:Optional
+	Schedule *CompactSchedule `json:"schedule,omitempty"`
 	// When a resource is paused, no actions except for deletion
 	// will be performed on the underlying objects.
 	// +kubebuilder:validation:Optional
@@ -88,6 +101,87 @@ type ThanosCompactSpec struct {
 type ThanosCompactStatus struct {
 	// Conditions represent the latest available observations of the state of the hashring.
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// Schedule reports the state of the compaction schedule, if configured.
+	// +kubebuilder:validation:Optional
+	Schedule *CompactScheduleStatus `json:"schedule,omitempty"`
+	// BlockMarkers reports the state of the block markers in the spec.
+	// +kubebuilder:validation:Optional
+	BlockMarkers []BlockMarkerStatus `json:"blockMarkers,omitempty"`
+	// Defaults are the values deployed for the image, version and logging configuration of the Compactor,
+	// including the defaults of the operator for the fields which are not set.
+	// +kubebuilder:validation:Optional
+	Defaults *EffectiveDefaults `json:"defaults,omitempty"`
+}
+
+// BlockMarkerStatus reports the state of a block marker.
+type BlockMarkerStatus struct {
+	// Name of the block marker.
+	Name string `json:"name"`
+	// Phase of the Job applying the marker. It is empty while the marker is awaiting confirmation.
+	// +kubebuilder:validation:Optional
+	Phase ThanosToolsPhase `json:"phase,omitempty"`
+	// JobName is the name of the Job applying the marker.
+	// +kubebuilder:validation:Optional
+	JobName string `json:"jobName,omitempty"`
+	// Message is a human readable message about the state of the marker.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// CompactSchedule defines the time windows during which the Compactor runs.
+type CompactSchedule struct {
+	// Windows during which the Compactor runs. The Compactor runs while any of the windows is open.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	Windows []CompactWindow `json:"windows"`
+	// TimeZone is the IANA name of the time zone the windows are evaluated in, e.g. Europe/Berlin.
+	// +kubebuilder:default="UTC"
+	// +kubebuilder:validation:Optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// CompactWindow is a recurring time window.
+type CompactWindow struct {
+	// Start is a standard five field cron expression at which the window opens, e.g. "0 1 * * *" for every night at 01:00.
+	// +kubebuilder:validation:Required
+	Start string `json:"start"`
+	// Duration is the time the window stays open for.
+	// +kubebuilder:validation:Required
+	Duration Duration `json:"duration"`
+}
+
+// CompactScheduleStatus reports the state of the compaction schedule.
+type CompactScheduleStatus struct {
+	// Active is true while a compaction window is open.
+	Active bool `json:"active"`
+	// NextTransition is the time at which a window is next expected to open or close.
+	// +kubebuilder:validation:Optional
+	NextTransition *metav1.Time `json:"nextTransition,omitempty"`
+}
+
+// BlockMarker defines a marker to apply to a set of blocks.
+type BlockMarker struct {
+	// Name of the marker request. It is used as a suffix for the Job executing the request.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Marker is the type of marker to apply.
+	// +kubebuilder:validation:Enum=deletion-mark.json;no-compact-mark.json
+	// +kubebuilder:validation:Required
+	Marker MarkerType `json:"marker"`
+	// BlockIDs are the ULIDs of the blocks to mark.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="blockIDs are immutable"
+	BlockIDs []string `json:"blockIDs"`
+	// Details is a human readable string explaining why the blocks were marked.
+	// +kubebuilder:validation:Required
+	Details string `json:"details"`
+	// Confirm must be set to true for the marker to be applied.
+	// Until then, the operator only records the pending request as an event when the marker is added.
+	// +kubebuilder:validation:Optional
+	Confirm bool `json:"confirm,omitempty"`
 }
 
 // BlockConfig defines settings for block handling.
@@ -136,6 +230,7 @@ type CompactConfig struct {
 }
 
 // DownsamplingConfig defines the downsampling configuration for the compact component.
+// +kubebuilder:validation:XValidation:rule="!has(self.worker) || !has(self.downsamplingEnabled) || !self.downsamplingEnabled",message="worker cannot be set when downsampling is disabled"
 type DownsamplingConfig struct {
 	// Disable downsampling.
 	// +kubebuilder:default=false
@@ -144,6 +239,23 @@ type DownsamplingConfig struct {
 	// +kubebuilder:default=1
 	// +kubebuilder:validation:Optional
 	Concurrency *int32 `json:"downsamplingConcurrency,omitempty"`
+	// Worker runs downsampling in a separate StatefulSet instead of in the Compactors, so that CPU bound
+	// downsampling does not starve compaction. The Compactors run with downsampling disabled,
+	// and a single worker downsamples the blocks of all shards using Concurrency.
+	// +kubebuilder:validation:Optional
+	Worker *DownsamplingWorker `json:"worker,omitempty"`
+}
+
+// DownsamplingWorker configures the workload which downsamples blocks separately from the Compactors.
+type DownsamplingWorker struct {
+	// ResourceRequirements of the Thanos container of the worker.
+	// If not set, the resource requirements of the Compactors are used.
+	// +kubebuilder:validation:Optional
+	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+	// StorageSize is the size of the volume the worker downloads blocks to.
+	// If not set, the storage size of the Compactors is used.
+	// +kubebuilder:validation:Optional
+	StorageSize *StorageSize `json:"storageSize,omitempty"`
 }
 
 // RetentionResolutionConfig defines the retention configuration for the compact component.
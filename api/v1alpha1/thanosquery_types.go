@@ -21,6 +21,7 @@ import (
 )
 
 // ThanosQuerySpec defines the desired state of ThanosQuery
+// +kubebuilder:validation:XValidation:rule="!has(self.queryFrontend) || !has(self.queryFrontend.logQueriesLongerThan) || !has(self.timeout) || !self.queryFrontend.logQueriesLongerThan.matches('^([0-9]+(ms|s|m|h))+$') || !self.timeout.matches('^([0-9]+(ms|s|m|h))+$') || duration(self.queryFrontend.logQueriesLongerThan) < duration(self.timeout)",message="queryFrontend.logQueriesLongerThan must be less than timeout"
 type ThanosQuerySpec struct {
 	CommonFields `json:",inline"`
 	// Replicas is the number of querier replicas.
@@ -35,18 +36,121 @@ type ThanosQuerySpec struct {
 	// Data can still be queried without deduplication using 'dedup=false' parameter.
 	// Data includes time series, recording rules, and alerting rules.
 	// Refer to https://thanos.io/tip/components/query.md/#deduplication-replica-labels
-	// +kubebuilder:default:={"replica"}
+	// Defaults to the replica labels set by Prometheus, ThanosReceive and ThanosRuler.
+	// The operator warns about labels which are not set on any Store API endpoint of the Querier.
+	// +kubebuilder:default:={"replica","prometheus_replica","rule_replica"}
+	// +kubebuilder:validation:items:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	// +listType=set
 	// +kubebuilder:validation:Optional
 	ReplicaLabels []string `json:"replicaLabels,omitempty"`
+	// Timeout is the maximum time to process a query by the Querier.
+	// +kubebuilder:default="15m"
+	// +kubebuilder:validation:XValidation:rule="self != '0'",message="timeout must be greater than zero"
+	// +kubebuilder:validation:Optional
+	Timeout *Duration `json:"timeout,omitempty"`
+	// LookbackDelta is the maximum lookback duration for retrieving metrics during expression evaluations.
+	// Series without samples within the lookback delta of an evaluation step are considered stale.
+	// +kubebuilder:default="5m"
+	// +kubebuilder:validation:Optional
+	LookbackDelta *Duration `json:"lookbackDelta,omitempty"`
+	// MaxConcurrent is the maximum number of queries processed concurrently by each replica of the Querier.
+	// Further queries are queued until a query completes.
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	MaxConcurrent *int32 `json:"maxConcurrent,omitempty"`
+	// PodDisruptionConfig configures the PodDisruptionBudget of the Querier and of the Queriers of each query pool.
+	// +kubebuilder:validation:Optional
+	PodDisruptionConfig *PodDisruptionConfig `json:"podDisruptionConfig,omitempty"`
+	// Autoscaling configures a HorizontalPodAutoscaler scaling the Querier, in which case Replicas is ignored.
+	// The Queriers of endpoint groups and query pools are not autoscaled.
+	// +kubebuilder:validation:Optional
+	Autoscaling *AutoscalingConfig `json:"autoscaling,omitempty"`
 	// StoreLabelSelector enables adding additional labels to build a custom label selector
 	// for discoverable StoreAPIs. Values provided here will be appended to the default which are
 	// {"operator.thanos.io/store-api": "true", "app.kubernetes.io/part-of": "thanos"}.
 	// +kubebuilder:validation:Optional
 	StoreLabelSelector *metav1.LabelSelector `json:"customStoreLabelSelector,omitempty"`
+	// StoreDiscoveryLabels replace the default labels a Service must carry to be discovered as a StoreAPI,
+	// which are {"operator.thanos.io/store-api": "true", "app.kubernetes.io/part-of": "thanos"}.
+	// Setting distinct labels allows independent query layers in the same namespace to each own a distinct set of StoreAPIs.
+	// The StoreLabelSelector is appended to these labels.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinProperties=1
+	StoreDiscoveryLabels map[string]string `json:"storeDiscoveryLabels,omitempty"`
+	// EndpointTypeOverrides overrides the endpoint type advertised by the discovered StoreAPIs.
+	// The first override whose selector matches the labels of a StoreAPI Service applies.
+	// StoreAPIs not matched by any override are attached as the type they advertise.
+	// +kubebuilder:validation:Optional
+	EndpointTypeOverrides []EndpointTypeOverride `json:"endpointTypeOverrides,omitempty"`
+	// CustomStoreEndpoints are StoreAPI endpoints attached to the Querier in addition to the discovered StoreAPI Services,
+	// e.g. Thanos components running outside the cluster or managed by other tooling.
+	// They are not served through endpoint groups.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=address
+	CustomStoreEndpoints []CustomStoreEndpoint `json:"customStoreEndpoints,omitempty"`
+	// EndpointGroups fan out to the StoreAPIs matching their selector through a dedicated Querier,
+	// with its own timeout and concurrency settings, e.g. to give external federated endpoints a longer timeout.
+	// The Querier of each group is attached to the Querier of this resource as a single endpoint.
+	// The first group whose selector matches the labels of a StoreAPI Service applies.
+	// StoreAPIs not matched by any group are attached to the Querier of this resource directly.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	EndpointGroups []EndpointGroup `json:"endpointGroups,omitempty"`
+	// QueryPools are additional pools of Queriers serving the same StoreAPIs as the Querier of this resource,
+	// each with its own replicas and query limits, e.g. an interactive pool with low concurrency
+	// and a rule evaluation pool with a longer timeout.
+	// The resources of each pool are labeled with operator.thanos.io/query-pool set to the name of the pool,
+	// so that they can be selected, e.g. by the queryLabelSelector of a ThanosRuler.
+	// The names of the pools must differ from the names of the endpoint groups.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	QueryPools []QueryPool `json:"queryPools,omitempty"`
+	// RequestLoggingConfig configures request logging for the HTTP and gRPC servers.
+	// +kubebuilder:validation:Optional
+	RequestLoggingConfig *RequestLoggingConfig `json:"requestLoggingConfig,omitempty"`
+	// LogForwarding forwards the logs of the Queriers, including the Queriers of endpoint groups and pools, to an HTTP endpoint.
+	// +kubebuilder:validation:Optional
+	LogForwarding *LogForwarding `json:"logForwarding,omitempty"`
+	// GRPCServerTLS enables TLS on the gRPC server of the Queriers, including the Queriers of endpoint groups and pools.
+	// +kubebuilder:validation:Optional
+	GRPCServerTLS *GRPCServerTLSConfig `json:"grpcServerTLS,omitempty"`
+	// GRPCServer configures the gRPC server of the Queriers, including the Queriers of endpoint groups and pools.
+	// +kubebuilder:validation:Optional
+	GRPCServer *GRPCServerConfig `json:"grpcServer,omitempty"`
+	// GRPCClientTLS enables TLS on the connections of the Queriers to their StoreAPI endpoints.
+	// It applies to all endpoints, which must all serve TLS, including the Queriers of endpoint groups.
+	// +kubebuilder:validation:Optional
+	GRPCClientTLS *GRPCClientTLSConfig `json:"grpcClientTLS,omitempty"`
 	// QueryFrontend is the configuration for the Query Frontend
 	// If you specify this, the operator will create a Query Frontend in front of your query deployment.
 	// +kubebuilder:validation:Optional
 	QueryFrontend *QueryFrontendSpec `json:"queryFrontend,omitempty"`
+	// GrafanaDatasource configures a Grafana datasource provisioning ConfigMap for this resource.
+	// The datasource targets the Query Frontend if it is configured, otherwise the Querier.
+	// +kubebuilder:validation:Optional
+	GrafanaDatasource *GrafanaDatasourceSpec `json:"grafanaDatasource,omitempty"`
+	// Rollback configures the rollback of the Querier and Query Frontend to their last known good state
+	// when a rollout of a new generation of this resource fails.
+	// The failed generation is not retried until the resource is updated.
+	// +kubebuilder:validation:Optional
+	Rollback *RollbackSpec `json:"rollback,omitempty"`
+	// StackIngress exposes the UIs of this resource and the members of its stack on a single Ingress.
+	// The Querier, or the Query Frontend if configured, is served at the root path.
+	// ThanosRulers and the bucket UI of ThanosCompacts in the same stack, identified by the monitoring.thanos.io/stack label,
+	// are served under /ruler/<name> and /bucket/<name> respectively, and are configured with the matching --web.route-prefix.
+	// +kubebuilder:validation:Optional
+	StackIngress *StackIngressSpec `json:"stackIngress,omitempty"`
+	// TimeSplit splits the data served by the members of the stack of this resource by time,
+	// so that queries for recent data are served by ThanosReceives and queries for older data by ThanosStores.
+	// ThanosStores in the same stack, identified by the monitoring.thanos.io/stack label, serve only data older than the boundary,
+	// and the retention of the ingesters of ThanosReceives in the stack is extended to at least the boundary, so that no range is left uncovered.
+	// The Querier skips the StoreAPIs whose time range does not overlap with the queried range.
+	// +kubebuilder:validation:Optional
+	TimeSplit *TimeSplitSpec `json:"timeSplit,omitempty"`
 	// When a resource is paused, no actions except for deletion
 	// will be performed on the underlying objects.
 	// +kubebuilder:validation:Optional
@@ -63,6 +167,7 @@ type ThanosQuerySpec struct {
 }
 
 // QueryFrontendSpec defines the desired state of ThanosQueryFrontend
+// +kubebuilder:validation:XValidation:rule="!has(self.labelsSplitInterval) || !has(self.labelsDefaultTimeRange) || !self.labelsSplitInterval.matches('^([0-9]+(ms|s|m|h))+$') || !self.labelsDefaultTimeRange.matches('^([0-9]+(ms|s|m|h))+$') || duration(self.labelsSplitInterval) <= duration(self.labelsDefaultTimeRange)",message="labelsSplitInterval must not be larger than labelsDefaultTimeRange"
 type QueryFrontendSpec struct {
 	CommonFields `json:",inline"`
 	// +kubebuilder:validation:Minimum=1
@@ -77,41 +182,300 @@ type QueryFrontendSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:={matchLabels:{"operator.thanos.io/query-api": "true"}}
 	QueryLabelSelector *metav1.LabelSelector `json:"queryLabelSelector,omitempty"`
-	// LogQueriesLongerThan sets the duration threshold for logging long queries
+	// DownstreamURL is the URL of an external Query API, such as a Thanos Query running in another cluster.
+	// When set, the Query Frontend forwards requests to this URL and the operator does not deploy
+	// a Thanos Query for this resource, turning the Query Frontend into a standalone caching layer.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+	DownstreamURL *string `json:"downstreamURL,omitempty"`
+	// LogQueriesLongerThan sets the duration threshold for logging long queries.
+	// It must be less than the timeout of the Querier, since longer queries are aborted. Zero disables logging.
+	// +kubebuilder:default="0"
 	// +kubebuilder:validation:Optional
 	LogQueriesLongerThan *Duration `json:"logQueriesLongerThan,omitempty"`
+	// LogForwarding forwards the logs of the Query Frontend, including the slow query logs, to an HTTP endpoint.
+	// +kubebuilder:validation:Optional
+	LogForwarding *LogForwarding `json:"logForwarding,omitempty"`
 	// QueryRangeResponseCacheConfig holds the configuration for the query range response cache
 	// +kubebuilder:validation:Optional
 	QueryRangeResponseCacheConfig *CacheConfig `json:"queryRangeResponseCacheConfig,omitempty"`
-	// QueryRangeSplitInterval sets the split interval for query range
+	// QueryRangeSplitInterval sets the split interval for query range. Zero disables splitting.
+	// +kubebuilder:default="24h"
 	// +kubebuilder:validation:Optional
 	QueryRangeSplitInterval *Duration `json:"queryRangeSplitInterval,omitempty"`
-	// LabelsSplitInterval sets the split interval for labels
+	// LabelsSplitInterval sets the split interval for labels. Zero disables splitting.
+	// It must not be larger than the LabelsDefaultTimeRange.
+	// +kubebuilder:default="24h"
 	// +kubebuilder:validation:Optional
 	LabelsSplitInterval *Duration `json:"labelsSplitInterval,omitempty"`
 	// QueryRangeMaxRetries sets the maximum number of retries for query range requests
 	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=20
 	// +kubebuilder:default=5
 	QueryRangeMaxRetries int `json:"queryRangeMaxRetries,omitempty"`
 	// LabelsMaxRetries sets the maximum number of retries for label requests
 	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=20
 	// +kubebuilder:default=5
 	LabelsMaxRetries int `json:"labelsMaxRetries,omitempty"`
 	// LabelsDefaultTimeRange sets the default time range for label queries
+	// +kubebuilder:default="24h"
 	// +kubebuilder:validation:Optional
 	LabelsDefaultTimeRange *Duration `json:"labelsDefaultTimeRange,omitempty"`
+	// CoScheduling prefers scheduling the Query Frontend in the same topology domain as the Queriers it forwards to,
+	// e.g. the same zone, to cut cross-zone latency and egress cost in multi-zone clusters.
+	// It has no effect if DownstreamURL is set.
+	// +kubebuilder:validation:Optional
+	CoScheduling *CoSchedulingSpec `json:"coScheduling,omitempty"`
+	// PodDisruptionConfig configures the PodDisruptionBudget of the Query Frontend.
+	// +kubebuilder:validation:Optional
+	PodDisruptionConfig *PodDisruptionConfig `json:"podDisruptionConfig,omitempty"`
+	// Autoscaling configures a HorizontalPodAutoscaler scaling the Query Frontend, in which case Replicas is ignored.
+	// +kubebuilder:validation:Optional
+	Autoscaling *AutoscalingConfig `json:"autoscaling,omitempty"`
+	// QueryPool is the name of the query pool the Query Frontend forwards requests to.
+	// Defaults to the Querier of this resource.
+	// +kubebuilder:validation:Optional
+	QueryPool *string `json:"queryPool,omitempty"`
 	// Additional configuration for the Thanos components
 	Additional `json:",inline"`
 }
 
+// CoSchedulingSpec configures the preferred pod affinity of a component towards the pods it sends requests to.
+type CoSchedulingSpec struct {
+	// TopologyKey is the node label identifying the topology domain the pods are preferably co-located in.
+	// +kubebuilder:default="topology.kubernetes.io/zone"
+	// +kubebuilder:validation:Optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+	// Weight of the preference, relative to the other scheduling preferences of the pods.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=50
+	// +kubebuilder:validation:Optional
+	Weight int32 `json:"weight,omitempty"`
+}
+
+// GrafanaDatasourceSpec defines the Grafana datasource generated for a ThanosQuery.
+// The datasource is published as a ConfigMap in the Grafana provisioning format,
+// which can be picked up by the Grafana sidecar or mounted into Grafana directly.
+type GrafanaDatasourceSpec struct {
+	// Name is the name of the datasource in Grafana.
+	// Defaults to the name of the ThanosQuery resource.
+	// +kubebuilder:validation:Optional
+	Name *string `json:"name,omitempty"`
+	// IsDefault marks the datasource as the default datasource in Grafana.
+	// +kubebuilder:validation:Optional
+	IsDefault bool `json:"isDefault,omitempty"`
+	// Labels are the labels to add to the ConfigMap, used by Grafana to discover the datasource.
+	// +kubebuilder:default:={"grafana_datasource": "1"}
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// TimeInterval is the lowest interval for queries in Grafana.
+	// Should match the scrape interval of the underlying data.
+	// +kubebuilder:validation:Optional
+	TimeInterval *Duration `json:"timeInterval,omitempty"`
+}
+
+// EndpointTypeOverride overrides the endpoint type of the StoreAPI Services matching a selector.
+type EndpointTypeOverride struct {
+	// Selector selects the StoreAPI Services the override applies to.
+	// +kubebuilder:validation:Required
+	Selector metav1.LabelSelector `json:"selector"`
+	// Type is the endpoint type the selected StoreAPIs are attached as.
+	// +kubebuilder:validation:Required
+	Type EndpointType `json:"type"`
+}
+
+// CustomStoreEndpoint is a StoreAPI endpoint which is not discovered from a Service.
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || !self.type.startsWith('group') || !self.address.contains('+')",message="group endpoints do not support DNS service discovery prefixes"
+type CustomStoreEndpoint struct {
+	// Address is the address of the endpoint, as a DNS name or IP address with port, e.g. thanos-sidecar.example.com:10901.
+	// The address of regular and strict endpoints may use a DNS service discovery prefix, e.g. dnssrv+_grpc._tcp.thanos.example.com.
+	// See https://thanos.io/tip/thanos/service-discovery.md/#dns-service-discovery
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^((dns|dnssrv|dnssrvnoa)\+)?[^\s/+]+$`
+	Address string `json:"address"`
+	// Type is the endpoint type the endpoint is attached as.
+	// +kubebuilder:default=regular
+	// +kubebuilder:validation:Optional
+	Type EndpointType `json:"type,omitempty"`
+}
+
+// EndpointGroup configures a dedicated Querier for the StoreAPI Services matching a selector.
+type EndpointGroup struct {
+	// Name is the name of the group, used as a suffix for the resources of its Querier.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=30
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Selector selects the StoreAPI Services served by the Querier of the group.
+	// +kubebuilder:validation:Required
+	Selector metav1.LabelSelector `json:"selector"`
+	// Replicas is the number of replicas of the Querier of the group.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// ResponseTimeout is the maximum time to wait for a response from an endpoint of the group.
+	// Endpoints which do not respond in time are left out of the response, or fail the query if the
+	// partial response strategy of the query is abort. Defaults to no timeout.
+	// +kubebuilder:validation:Optional
+	ResponseTimeout *Duration `json:"responseTimeout,omitempty"`
+	// MaxConcurrentSelect is the maximum number of concurrent selects the Querier of the group runs per query.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	MaxConcurrentSelect *int32 `json:"maxConcurrentSelect,omitempty"`
+	// Strict attaches the Querier of the group as a strict endpoint, so it is always queried even when
+	// its health checks fail. Otherwise, it is attached as a regular endpoint.
+	// +kubebuilder:validation:Optional
+	Strict bool `json:"strict,omitempty"`
+}
+
+// QueryPool configures an additional pool of Queriers.
+// Settings which are not set are inherited from the Querier of the ThanosQuery.
+type QueryPool struct {
+	// Name is the name of the pool, used as a suffix for the resources of its Queriers.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=30
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Replicas is the number of Querier replicas of the pool.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// Timeout is the maximum time to process a query by the Queriers of the pool.
+	// +kubebuilder:validation:XValidation:rule="self != '0'",message="timeout must be greater than zero"
+	// +kubebuilder:validation:Optional
+	Timeout *Duration `json:"timeout,omitempty"`
+	// MaxConcurrent is the maximum number of queries processed concurrently by each Querier replica of the pool.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	MaxConcurrent *int32 `json:"maxConcurrent,omitempty"`
+}
+
+// StackIngressSpec configures the Ingress exposing the UIs of a stack.
+type StackIngressSpec struct {
+	// Host is the host name the Ingress serves.
+	// +kubebuilder:validation:Required
+	Host string `json:"host"`
+	// IngressClassName is the name of the IngressClass to use.
+	// +kubebuilder:validation:Optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// TLSSecretName is the name of the Secret holding the TLS certificate for the host.
+	// If not set, the Ingress serves plain HTTP.
+	// +kubebuilder:validation:Optional
+	TLSSecretName *string `json:"tlsSecretName,omitempty"`
+	// Annotations are additional annotations to add to the Ingress.
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// TimeSplitSpec configures the time based split of the data served by the members of a stack.
+type TimeSplitSpec struct {
+	// Boundary is the age of data at which queries are routed from ThanosReceives to ThanosStores.
+	// It must exceed the time it takes ingesters to upload a block to object storage and Store Gateways to discover it.
+	// If the ThanosQueries of a stack configure different boundaries, ThanosStores use the lowest
+	// and ThanosReceives retain data for at least the highest boundary.
+	// +kubebuilder:validation:Required
+	Boundary Duration `json:"boundary"`
+}
+
 // ThanosQueryStatus defines the observed state of ThanosQuery
 type ThanosQueryStatus struct {
 	// Conditions represent the latest available observations of the state of the Querier.
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// ObservedGeneration is the most recent generation of the ThanosQuery observed by the controller.
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Replicas is the number of Querier pods targeted by the Deployment.
+	// +kubebuilder:validation:Optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// ReadyReplicas is the number of ready Querier pods.
+	// +kubebuilder:validation:Optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// UpdatedReplicas is the number of Querier pods running the current pod template.
+	// +kubebuilder:validation:Optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+	// AvailableReplicas is the number of available Querier pods.
+	// +kubebuilder:validation:Optional
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+	// Endpoints reports the health of the Store API endpoints the Querier is connected to, as seen by the Querier.
+	// +kubebuilder:validation:Optional
+	Endpoints []EndpointStatus `json:"endpoints,omitempty"`
+	// Stack reports the upgrade progress of each component of the stack the ThanosQuery belongs to, in upgrade order.
+	// +kubebuilder:validation:Optional
+	Stack []StackComponentStatus `json:"stack,omitempty"`
+	// QueryFrontend summarizes the statistics of the Query Frontend, sampled periodically from its instances.
+	// +kubebuilder:validation:Optional
+	QueryFrontend *QueryFrontendStatus `json:"queryFrontend,omitempty"`
+	// Defaults are the values deployed for the image, version and logging configuration of the Querier,
+	// including the defaults of the operator for the fields which are not set.
+	// +kubebuilder:validation:Optional
+	Defaults *EffectiveDefaults `json:"defaults,omitempty"`
+}
+
+// QueryFrontendStatus summarizes the statistics of the instances of a Query Frontend.
+// Counters are cumulative since the start of each instance, so they drop when instances restart.
+type QueryFrontendStatus struct {
+	// SampledReplicas is the number of ready instances the statistics were sampled from.
+	SampledReplicas int32 `json:"sampledReplicas"`
+	// CacheHitPercent is the percentage of the response cache lookups which were hits.
+	// It is not set before the first lookup.
+	// +kubebuilder:validation:Optional
+	CacheHitPercent *int32 `json:"cacheHitPercent,omitempty"`
+	// InflightRequests is the number of requests being served when the statistics were sampled.
+	InflightRequests int64 `json:"inflightRequests"`
+	// Queries is the number of queries received.
+	Queries int64 `json:"queries"`
+}
+
+// StackUpgradePhase is the progress of a component of a stack towards the Thanos versions requested for it.
+// +kubebuilder:validation:Enum=Pending;Upgrading;Upgraded
+type StackUpgradePhase string
+
+const (
+	// StackUpgradePending means the workloads of the component do not deploy the requested versions yet.
+	StackUpgradePending StackUpgradePhase = "Pending"
+	// StackUpgradeUpgrading means the workloads of the component deploy the requested versions and are rolling out.
+	StackUpgradeUpgrading StackUpgradePhase = "Upgrading"
+	// StackUpgradeUpgraded means the workloads of the component run the requested versions.
+	StackUpgradeUpgraded StackUpgradePhase = "Upgraded"
+)
+
+// StackComponentStatus is the upgrade progress of a component of a member of a stack.
+type StackComponentStatus struct {
+	// Kind is the kind of the member of the stack, e.g. ThanosStore.
+	Kind string `json:"kind"`
+	// Name is the name of the member of the stack.
+	Name string `json:"name"`
+	// Component is the Thanos component, one of store, receive, compact, query, query-frontend or ruler.
+	Component string `json:"component"`
+	// Versions are the Thanos versions requested for the component.
+	Versions []string `json:"versions"`
+	// Phase is the progress of the component towards the requested versions.
+	Phase StackUpgradePhase `json:"phase"`
+}
+
+// EndpointStatus is the health of a Store API endpoint as seen by the Querier.
+type EndpointStatus struct {
+	// Name is the address of the endpoint.
+	Name string `json:"name"`
+	// Type is the component type advertised by the endpoint, e.g. store, sidecar or receive.
+	Type string `json:"type"`
+	// Up is true if the last health check of the endpoint by the Querier succeeded.
+	Up bool `json:"up"`
+	// LastError is the error of the last health check of the endpoint, if it failed.
+	// +kubebuilder:validation:Optional
+	LastError string `json:"lastError,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas"
+//+kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ThanosQuery is the Schema for the thanosqueries API
 type ThanosQuery struct {
@@ -43,6 +43,10 @@ type RouterSpec struct {
 	// +kubebuilder:default={receive: "true"}
 	// +kubebuilder:validation:Required
 	ExternalLabels ExternalLabels `json:"externalLabels,omitempty"`
+	// Limits are the write limits enforced by the router.
+	// The limits of a tenant set by a ThanosTenant take precedence over the limits set here.
+	// +kubebuilder:validation:Optional
+	Limits *ReceiveLimits `json:"limits,omitempty"`
 	// Additional configuration for the Thanos components. Allows you to add
 	// additional args, containers, volumes, and volume mounts to Thanos Deployments,
 	// and StatefulSets. Ideal to use for things like sidecars.
@@ -50,6 +54,45 @@ type RouterSpec struct {
 	Additional `json:",inline"`
 }
 
+// ReceiveLimits defines the write limits enforced by the router.
+// The router reloads the limits when they change, without restarting.
+// +kubebuilder:validation:XValidation:rule="has(self.metaMonitoringURL) || !has(self.defaultLimits) || !has(self.defaultLimits.activeSeriesLimit)",message="metaMonitoringURL is required to enforce active series limits"
+// +kubebuilder:validation:XValidation:rule="has(self.metaMonitoringURL) || !has(self.tenants) || self.tenants.all(t, !has(t.limits.activeSeriesLimit))",message="metaMonitoringURL is required to enforce active series limits"
+type ReceiveLimits struct {
+	// MaxConcurrency is the maximum number of remote write requests the router handles concurrently.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	MaxConcurrency *int32 `json:"maxConcurrency,omitempty"`
+	// MetaMonitoringURL is the URL of a Prometheus compatible API the router queries for the number
+	// of active series of the tenants. It is required to enforce active series limits.
+	// +kubebuilder:validation:Pattern=`^https?://.+`
+	// +kubebuilder:validation:Optional
+	MetaMonitoringURL *string `json:"metaMonitoringURL,omitempty"`
+	// MetaMonitoringLimitQuery is the PromQL query returning the number of active series per tenant.
+	// If not set, the default query of the router is used.
+	// +kubebuilder:validation:Optional
+	MetaMonitoringLimitQuery *string `json:"metaMonitoringLimitQuery,omitempty"`
+	// DefaultLimits are applied to the tenants without limits of their own.
+	// +kubebuilder:validation:Optional
+	DefaultLimits *TenantLimits `json:"defaultLimits,omitempty"`
+	// Tenants are the limits of individual tenants.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=tenant
+	Tenants []ReceiveTenantLimits `json:"tenants,omitempty"`
+}
+
+// ReceiveTenantLimits defines the write limits of a single tenant.
+type ReceiveTenantLimits struct {
+	// Tenant is the ID of the tenant.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Tenant string `json:"tenant"`
+	// Limits are the write limits of the tenant.
+	// +kubebuilder:validation:Required
+	Limits TenantLimits `json:"limits"`
+}
+
 // IngesterSpec represents the configuration for the ingestor
 type IngesterSpec struct {
 	// DefaultObjectStorageConfig is the secret that contains the object storage configuration for the ingest components.
@@ -62,6 +105,17 @@ type IngesterSpec struct {
 	// +listType=map
 	// +listMapKey=name
 	Hashrings []IngesterHashringSpec `json:"hashrings,omitempty"`
+	// Backup configures how the persistent volumes of the ingesters of all hashrings participate in cluster backups.
+	// +kubebuilder:validation:Optional
+	Backup *BackupConfig `json:"backup,omitempty"`
+	// Snapshots configures periodic VolumeSnapshots of the volumes of the ingesters of all hashrings.
+	// They allow to restore an ingester whose volume was lost before its data was uploaded to object storage,
+	// see RestoreIngesterAnnotation. Requires the CSI snapshot controller to be installed in the cluster.
+	// +kubebuilder:validation:Optional
+	Snapshots *IngesterSnapshotConfig `json:"snapshots,omitempty"`
+	// GRPCServer configures the gRPC server serving the StoreAPI of the ingesters of all hashrings.
+	// +kubebuilder:validation:Optional
+	GRPCServer *GRPCServerConfig `json:"grpcServer,omitempty"`
 	// Additional configuration for the Thanos components. Allows you to add
 	// additional args, containers, volumes, and volume mounts to Thanos Deployments,
 	// and StatefulSets. Ideal to use for things like sidecars.
@@ -69,6 +123,23 @@ type IngesterSpec struct {
 	Additional `json:",inline"`
 }
 
+// IngesterSnapshotConfig configures periodic VolumeSnapshots of the volumes of the ingesters.
+type IngesterSnapshotConfig struct {
+	// VolumeSnapshotClassName is the VolumeSnapshotClass of the snapshots.
+	// If not set, the default VolumeSnapshotClass of the cluster is used.
+	// +kubebuilder:validation:Optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+	// Interval is the time between two snapshots of a volume.
+	// +kubebuilder:default="2h"
+	// +kubebuilder:validation:Optional
+	Interval Duration `json:"interval,omitempty"`
+	// Retain is the number of snapshots kept per volume. The most recent snapshot ready to use is always kept.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=2
+	// +kubebuilder:validation:Optional
+	Retain int32 `json:"retain,omitempty"`
+}
+
 // IngesterHashringSpec represents the configuration for a hashring to be used by the Thanos Receive StatefulSet.
 type IngesterHashringSpec struct {
 	// CommonFields are the options available to all Thanos components.
@@ -85,6 +156,15 @@ type IngesterHashringSpec struct {
 	// Labels set here will overwrite the labels inherited from the ThanosReceive object if they have the same key.
 	// +kubebuilder:validation:Optional
 	Labels map[string]string `json:"labels,omitempty"`
+	// StoreAPIServiceLabels are additional labels added only to the Store API Service of the hashring.
+	// They can be matched by the customStoreLabelSelector of a ThanosQuery, so that several query layers
+	// can discover disjoint subsets of the same fleet. The labels required for discovery cannot be overridden.
+	// +kubebuilder:validation:Optional
+	StoreAPIServiceLabels map[string]string `json:"storeAPIServiceLabels,omitempty"`
+	// EndpointType is the type of endpoint the ingesters of the hashring advertise to Queriers.
+	// If not set, the ingesters are advertised as regular endpoints.
+	// +kubebuilder:validation:Optional
+	EndpointType EndpointType `json:"endpointType,omitempty"`
 	// ExternalLabels to add to the ingesters tsdb blocks.
 	// +kubebuilder:default={replica: "$(POD_NAME)"}
 	// +kubebuilder:validation:Required
@@ -132,10 +212,41 @@ type ThanosReceiveSpec struct {
 	FeatureGates *FeatureGates `json:"featureGates,omitempty"`
 }
 
+// HashringStatus is the state of a hashring as configured in the routers.
+type HashringStatus struct {
+	// Name is the name of the hashring.
+	Name string `json:"name"`
+	// Tenants are the tenants whose series are written to the ingesters of the hashring.
+	// A hashring without tenants receives the series of all tenants which do not match another hashring.
+	// +kubebuilder:validation:Optional
+	Tenants []string `json:"tenants,omitempty"`
+	// TenantMatcherType is the type of matching of the tenants of the hashring.
+	// +kubebuilder:validation:Optional
+	TenantMatcherType string `json:"tenantMatcherType,omitempty"`
+	// Members are the addresses of the ingesters of the hashring, across which the series of its tenants are spread.
+	// +kubebuilder:validation:Optional
+	Members []string `json:"members,omitempty"`
+	// Defaults are the values deployed for the image, version and logging configuration of the ingesters of the hashring,
+	// including the defaults of the operator for the fields which are not set.
+	// +kubebuilder:validation:Optional
+	Defaults *EffectiveDefaults `json:"defaults,omitempty"`
+}
+
 // ThanosReceiveStatus defines the observed state of ThanosReceive
 type ThanosReceiveStatus struct {
 	// Conditions represent the latest available observations of the state of the hashring.
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// HashringsHash identifies the hashring configuration of the routers. It changes whenever the configuration changes.
+	// +kubebuilder:validation:Optional
+	HashringsHash string `json:"hashringsHash,omitempty"`
+	// Hashrings is the state of each hashring as configured in the routers.
+	// +kubebuilder:validation:Optional
+	Hashrings []HashringStatus `json:"hashrings,omitempty"`
+	// Defaults are the values deployed for the image, version and logging configuration of the routers,
+	// including the defaults of the operator for the fields which are not set.
+	// The values deployed for the ingesters are reported per hashring.
+	// +kubebuilder:validation:Optional
+	Defaults *EffectiveDefaults `json:"defaults,omitempty"`
 }
 
 //+kubebuilder:object:root=true
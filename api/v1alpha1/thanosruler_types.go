@@ -26,6 +26,11 @@ type ThanosRulerSpec struct {
 	// Labels are additional labels to add to the Ruler component.
 	// +kubebuilder:validation:Optional
 	Labels map[string]string `json:"labels,omitempty"`
+	// StoreAPIServiceLabels are additional labels added only to the Store API Service of the Ruler.
+	// They can be matched by the customStoreLabelSelector of a ThanosQuery, so that several query layers
+	// can discover disjoint subsets of the same fleet. The labels required for discovery cannot be overridden.
+	// +kubebuilder:validation:Optional
+	StoreAPIServiceLabels map[string]string `json:"storeAPIServiceLabels,omitempty"`
 	// Replicas is the number of Ruler replicas.
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:default=1
@@ -83,6 +88,16 @@ type ThanosRulerSpec struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:XValidation:rule="self.matchLabels.size() >= 1 || self.matchExpressions.size() >= 1",message="PrometheusRuleSelector must have at least one label selector"
 	PrometheusRuleSelector metav1.LabelSelector `json:"prometheusRuleSelector,omitempty"`
+	// RuleNamespaceSelector is the label selector to discover namespaces from which rule ConfigMaps and PrometheusRules
+	// are gathered, in addition to the namespace of the Ruler.
+	// Rules found in other namespaces are copied into ConfigMaps in the namespace of the Ruler.
+	// An empty selector matches all namespaces. If not set, rules are only gathered from the namespace of the Ruler.
+	// +kubebuilder:validation:Optional
+	RuleNamespaceSelector *metav1.LabelSelector `json:"ruleNamespaceSelector,omitempty"`
+	// RuleNamespaceDenyList is a list of namespaces from which rules are never gathered,
+	// even if they match the RuleNamespaceSelector. It does not apply to the namespace of the Ruler.
+	// +kubebuilder:validation:Optional
+	RuleNamespaceDenyList []string `json:"ruleNamespaceDenyList,omitempty"`
 	// Additional configuration for the Thanos components. Allows you to add
 	// additional args, containers, volumes, and volume mounts to Thanos Deployments,
 	// and StatefulSets. Ideal to use for things like sidecars.
@@ -96,6 +111,10 @@ type ThanosRulerSpec struct {
 type ThanosRulerStatus struct {
 	// Conditions represent the latest available observations of the state of the Ruler.
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// Defaults are the values deployed for the image, version and logging configuration of the Ruler,
+	// including the defaults of the operator for the fields which are not set.
+	// +kubebuilder:validation:Optional
+	Defaults *EffectiveDefaults `json:"defaults,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -17,21 +17,55 @@ limitations under the License.
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ThanosStoreSpec defines the desired state of ThanosStore
+// +kubebuilder:validation:XValidation:rule="has(self.objectStorageConfig) != has(self.objectStorage)",message="exactly one of objectStorageConfig and objectStorage must be set"
 type ThanosStoreSpec struct {
 	CommonFields `json:",inline"`
 	// Labels are additional labels to add to the Store component.
 	// +kubebuilder:validation:Optional
 	Labels map[string]string `json:"labels,omitempty"`
+	// StoreAPIServiceLabels are additional labels added only to the Store API Services of the Store Gateways.
+	// They can be matched by the customStoreLabelSelector of a ThanosQuery, so that several query layers
+	// can discover disjoint subsets of the same fleet. The labels required for discovery cannot be overridden.
+	// +kubebuilder:validation:Optional
+	StoreAPIServiceLabels map[string]string `json:"storeAPIServiceLabels,omitempty"`
+	// EndpointType is the type of endpoint the Store Gateways advertise to Queriers.
+	// If not set, Store Gateways with more than one replica per shard are advertised as group
+	// and all others as regular endpoints.
+	// +kubebuilder:validation:Optional
+	EndpointType EndpointType `json:"endpointType,omitempty"`
 	// ObjectStorageConfig is the secret that contains the object storage configuration for Store Gateways.
-	// +kubebuilder:validation:Required
-	ObjectStorageConfig ObjectStorageConfig `json:"objectStorageConfig,omitempty"`
+	// Exactly one of objectStorageConfig and objectStorage must be set.
+	// +kubebuilder:validation:Optional
+	ObjectStorageConfig *ObjectStorageConfig `json:"objectStorageConfig,omitempty"`
+	// ObjectStorage is an object storage configuration for Store Gateways, which the operator renders into a managed Secret.
+	// Exactly one of objectStorageConfig and objectStorage must be set.
+	// +kubebuilder:validation:Optional
+	ObjectStorage *ObjectStorage `json:"objectStorage,omitempty"`
 	// StorageSize is the size of the storage to be used by the Thanos Store StatefulSets.
+	// It can be increased if the StorageClass of the volumes allows volume expansion, but not decreased.
 	// +kubebuilder:validation:Required
 	StorageSize StorageSize `json:"storageSize"`
+	// StorageClassName is the name of the StorageClass of the PersistentVolumeClaims of the Store Gateways.
+	// If not set, the default StorageClass of the cluster is used.
+	// Volume claim templates are immutable, so changes only apply to the StatefulSets of new shards and tiers.
+	// +kubebuilder:validation:Optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// VolumeClaimLabels are additional labels added to the PersistentVolumeClaims of the Store Gateways.
+	// +kubebuilder:validation:Optional
+	VolumeClaimLabels map[string]string `json:"volumeClaimLabels,omitempty"`
+	// VolumeClaimAnnotations are additional annotations added to the PersistentVolumeClaims of the Store Gateways.
+	// +kubebuilder:validation:Optional
+	VolumeClaimAnnotations map[string]string `json:"volumeClaimAnnotations,omitempty"`
+	// PersistentVolumeClaimRetentionPolicy controls whether the PersistentVolumeClaims of the Store Gateways
+	// are deleted when a shard is scaled down or the ThanosStore is deleted. Claims are retained by default.
+	// +kubebuilder:validation:Optional
+	PersistentVolumeClaimRetentionPolicy *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy `json:"persistentVolumeClaimRetentionPolicy,omitempty"`
 	// Duration after which the blocks marked for deletion will be filtered out while fetching blocks.
 	// The idea of ignore-deletion-marks-delay is to ignore blocks that are marked for deletion with some delay.
 	// This ensures store can still serve blocks that are meant to be deleted but do not have a replacement yet.
@@ -50,14 +84,36 @@ type ThanosStoreSpec struct {
 	// ShardingStrategy defines the sharding strategy for the Store Gateways across object storage blocks.
 	// +kubebuilder:validation:Required
 	ShardingStrategy ShardingStrategy `json:"shardingStrategy,omitempty"`
+	// PodDisruptionConfig configures the PodDisruptionBudget of the Store Gateways of each shard.
+	// +kubebuilder:validation:Optional
+	PodDisruptionConfig *PodDisruptionConfig `json:"podDisruptionConfig,omitempty"`
+	// Backup configures how the persistent volumes of the Store Gateways participate in cluster backups.
+	// +kubebuilder:validation:Optional
+	Backup *BackupConfig `json:"backup,omitempty"`
 	// Minimum time range to serve. Any data earlier than this lower time range will be ignored.
 	// If not set, will be set as zero value, so most recent blocks will be served.
 	// +kubebuilder:validation:Optional
-	MinTime *Duration `json:"minTime,omitempty"`
+	MinTime *TimeOrDuration `json:"minTime,omitempty"`
 	// Maximum time range to serve. Any data after this upper time range will be ignored.
 	// If not set, will be set as max value, so all blocks will be served.
 	// +kubebuilder:validation:Optional
-	MaxTime *Duration `json:"maxTime,omitempty"`
+	MaxTime *TimeOrDuration `json:"maxTime,omitempty"`
+	// RequestLoggingConfig configures request logging for the HTTP and gRPC servers.
+	// +kubebuilder:validation:Optional
+	RequestLoggingConfig *RequestLoggingConfig `json:"requestLoggingConfig,omitempty"`
+	// GRPCServerTLS enables TLS on the gRPC server of the Store Gateways.
+	// +kubebuilder:validation:Optional
+	GRPCServerTLS *GRPCServerTLSConfig `json:"grpcServerTLS,omitempty"`
+	// GRPCServer configures the gRPC server of the Store Gateways.
+	// +kubebuilder:validation:Optional
+	GRPCServer *GRPCServerConfig `json:"grpcServer,omitempty"`
+	// Tiers splits the Store Gateways into time based tiers, for example a hot tier serving recent data
+	// and a cold tier serving older data. Each tier is deployed as its own set of StatefulSets and can be
+	// sized independently. When set, MinTime and MaxTime are ignored in favour of the per-tier time ranges.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	Tiers []StoreTier `json:"tiers,omitempty"`
 	// When a resource is paused, no actions except for deletion
 	// will be performed on the underlying objects.
 	// +kubebuilder:validation:Optional
@@ -78,16 +134,27 @@ type ShardingStrategyType string
 const (
 	// Block is the block modulo sharding strategy for sharding Stores according to block ids.
 	Block ShardingStrategyType = "block"
+	// Time is the sharding strategy for sharding Stores into consecutive, non-overlapping time windows.
+	Time ShardingStrategyType = "time"
 )
 
 // ShardingStrategy controls the automatic deployment of multiple store gateways sharded by block ID
-// by hashmoding __block_id label value.
+// by hashmoding __block_id label value, or by time.
+// The block selector relabel configuration of each shard is rendered into a ConfigMap with the name of the shard.
 type ShardingStrategy struct {
 	// Type here is the type of sharding strategy.
+	// With the block strategy, every shard serves the same time range and blocks are distributed by their ID.
+	// With the time strategy, the first shard serves the most recent Interval of data, every following shard
+	// serves the Interval before that of the previous shard and the last shard serves all older data.
+	// The windows are relative to the maximum time of the ThanosStore or tier, so they do not change between reconciles.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:default="block"
-	// +kubebuilder:validation:Enum=block
+	// +kubebuilder:validation:Enum=block;time
 	Type ShardingStrategyType `json:"type,omitempty"`
+	// Interval is the length of the time window served by each shard with the time sharding strategy,
+	// except the last shard. Defaults to 1w. Ignored by the block sharding strategy.
+	// +kubebuilder:validation:Optional
+	Interval *Duration `json:"interval,omitempty"`
 	// Shards is the number of shards to split the data into.
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:default=1
@@ -98,14 +165,82 @@ type ShardingStrategy struct {
 	ShardReplicas int32 `json:"shardReplicas,omitempty"`
 }
 
+// StoreTier defines a time range of blocks to be served by a dedicated set of Store Gateways.
+// Fields that are not set are inherited from the parent ThanosStoreSpec.
+type StoreTier struct {
+	// Name is the name of the tier. It is used as a suffix for the generated resources
+	// and as the value of the store tier label.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=16
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Minimum time range to serve for this tier.
+	// +kubebuilder:validation:Optional
+	MinTime *TimeOrDuration `json:"minTime,omitempty"`
+	// Maximum time range to serve for this tier.
+	// +kubebuilder:validation:Optional
+	MaxTime *TimeOrDuration `json:"maxTime,omitempty"`
+	// Resources for the Store Gateways of this tier.
+	// +kubebuilder:validation:Optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// StorageSize is the size of the storage to be used by the Store Gateways of this tier.
+	// +kubebuilder:validation:Optional
+	StorageSize *StorageSize `json:"storageSize,omitempty"`
+	// StorageClassName is the name of the StorageClass of the PersistentVolumeClaims of the Store Gateways of this tier.
+	// +kubebuilder:validation:Optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// IndexCacheConfig allows configuration of the index cache for this tier.
+	// +kubebuilder:validation:Optional
+	IndexCacheConfig *CacheConfig `json:"indexCacheConfig,omitempty"`
+	// CachingBucketConfig allows configuration of the caching bucket for this tier.
+	// +kubebuilder:validation:Optional
+	CachingBucketConfig *CacheConfig `json:"cachingBucketConfig,omitempty"`
+	// StoreAPIServiceLabels are additional labels added only to the Store API Services of this tier.
+	// Labels set here will overwrite the StoreAPIServiceLabels of the ThanosStore if they have the same key.
+	// +kubebuilder:validation:Optional
+	StoreAPIServiceLabels map[string]string `json:"storeAPIServiceLabels,omitempty"`
+}
+
+// StoreShardStatus is the observed state of the StatefulSet of a Store Gateway shard.
+type StoreShardStatus struct {
+	// Name is the name of the StatefulSet of the shard.
+	Name string `json:"name"`
+	// Replicas is the desired number of replicas of the shard.
+	Replicas int32 `json:"replicas"`
+	// ReadyReplicas is the number of ready replicas of the shard.
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// Paused is true if the reconciliation of the shard is paused with the PauseShardAnnotation.
+	// +kubebuilder:validation:Optional
+	Paused bool `json:"paused,omitempty"`
+}
+
 // ThanosStoreStatus defines the observed state of ThanosStore
 type ThanosStoreStatus struct {
 	// Conditions represent the latest available observations of the state of the Querier.
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// ObservedGeneration is the generation of the ThanosStore the status was last reconciled for.
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Shards is the observed state of each Store Gateway shard, across all tiers.
+	// The ThanosStore is Available when all of its shards are ready.
+	// +kubebuilder:validation:Optional
+	Shards []StoreShardStatus `json:"shards,omitempty"`
+	// BlockShards is the number of shards the blocks are distributed across with the block sharding strategy,
+	// once all shards of that count were rolled out.
+	// While it differs from spec.shardingStrategy.shards, every shard serves the blocks assigned to it
+	// by both shard counts, and shards which are no longer needed are kept, so that all blocks stay queryable.
+	// +kubebuilder:validation:Optional
+	BlockShards int32 `json:"blockShards,omitempty"`
+	// Defaults are the values deployed for the image, version and logging configuration of the Store Gateways,
+	// including the defaults of the operator for the fields which are not set.
+	// +kubebuilder:validation:Optional
+	Defaults *EffectiveDefaults `json:"defaults,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ThanosStore is the Schema for the thanosstores API
 type ThanosStore struct {
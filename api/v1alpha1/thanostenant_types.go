@@ -0,0 +1,178 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ThanosTenantSpec defines the desired state of ThanosTenant
+type ThanosTenantSpec struct {
+	// ReceiveName is the name of the ThanosReceive resource in the same namespace
+	// that the tenant writes to.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="receiveName is immutable"
+	ReceiveName string `json:"receiveName"`
+	// TenantID is the value of the tenant header sent by remote write clients.
+	// Defaults to the name of the ThanosTenant resource.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="tenantID is immutable"
+	TenantID *string `json:"tenantID,omitempty"`
+	// TargetNamespace is the namespace of the tenant.
+	// A ConfigMap containing a remote write configuration snippet is published to this namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="targetNamespace is immutable"
+	TargetNamespace string `json:"targetNamespace"`
+	// Limits are the write limits applied to the tenant by the receive router.
+	// +kubebuilder:validation:Optional
+	Limits *TenantLimits `json:"limits,omitempty"`
+	// Ingress exposes a dedicated remote write path for the tenant outside the cluster.
+	// +kubebuilder:validation:Optional
+	Ingress *TenantIngress `json:"ingress,omitempty"`
+	// PrometheusRemoteWrite publishes the remote write endpoint of the tenant in the format of the remoteWrite field
+	// of prometheus-operator Prometheus resources, together with the credentials it references.
+	// +kubebuilder:validation:Optional
+	PrometheusRemoteWrite *TenantPrometheusRemoteWrite `json:"prometheusRemoteWrite,omitempty"`
+	// When a resource is paused, no actions except for deletion
+	// will be performed on the underlying objects.
+	// +kubebuilder:validation:Optional
+	Paused *bool `json:"paused,omitempty"`
+}
+
+// TenantLimits defines the write limits for a tenant.
+// Unset limits fall back to the defaults of the receive router.
+// +kubebuilder:validation:MinProperties=1
+type TenantLimits struct {
+	// SizeBytesLimit is the maximum size in bytes of the body of a remote write request.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	SizeBytesLimit *int64 `json:"sizeBytesLimit,omitempty"`
+	// SeriesLimit is the maximum number of series in a single remote write request.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	SeriesLimit *int64 `json:"seriesLimit,omitempty"`
+	// SamplesLimit is the maximum number of samples in a single remote write request.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	SamplesLimit *int64 `json:"samplesLimit,omitempty"`
+	// ActiveSeriesLimit is the maximum number of active series of the tenant across the ingesters.
+	// It is only enforced if the metaMonitoringURL of the limits of the ThanosReceive is set.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	ActiveSeriesLimit *int64 `json:"activeSeriesLimit,omitempty"`
+}
+
+// TenantIngress configures an Ingress for the remote write endpoint of a tenant.
+type TenantIngress struct {
+	// Host is the host name the Ingress serves.
+	// +kubebuilder:validation:Required
+	Host string `json:"host"`
+	// Path is the path the Ingress serves for the tenant.
+	// +kubebuilder:default="/api/v1/receive"
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+	// IngressClassName is the name of the IngressClass to use.
+	// +kubebuilder:validation:Optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// TLSSecretName is the name of the Secret holding the TLS certificate for the host.
+	// When set, the published remote write URL uses https.
+	// +kubebuilder:validation:Optional
+	TLSSecretName *string `json:"tlsSecretName,omitempty"`
+	// BasicAuthSecret is a Secret in the namespace of the ThanosReceive holding a htpasswd file under the key `auth`.
+	// When set, the Ingress is annotated to require basic authentication for the ingress-nginx controller.
+	// +kubebuilder:validation:Optional
+	BasicAuthSecret *corev1.LocalObjectReference `json:"basicAuthSecret,omitempty"`
+	// Annotations are additional annotations to add to the Ingress.
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// TenantPrometheusRemoteWrite configures the Secret published for prometheus-operator Prometheus resources of a tenant.
+// The Secret is named like the remote write ConfigMap and holds a remoteWrite entry under the key `remote-write.yaml`,
+// which references the credentials copied into the Secret.
+type TenantPrometheusRemoteWrite struct {
+	// NamespaceSelector selects the namespaces the Secret is published to in addition to the target namespace.
+	// +kubebuilder:validation:Optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// BasicAuthSecret is a Secret in the namespace of the ThanosTenant holding the credentials remote write clients
+	// authenticate with under the keys `username` and `password`.
+	// +kubebuilder:validation:Optional
+	BasicAuthSecret *corev1.LocalObjectReference `json:"basicAuthSecret,omitempty"`
+	// CA selects the CA bundle remote write clients verify the endpoint against in a Secret in the namespace
+	// of the ThanosTenant.
+	// +kubebuilder:validation:Optional
+	CA *corev1.SecretKeySelector `json:"ca,omitempty"`
+}
+
+const (
+	// ThanosTenantConditionReady indicates whether the tenant has been onboarded.
+	ThanosTenantConditionReady = "Ready"
+)
+
+// ThanosTenantStatus defines the observed state of ThanosTenant
+type ThanosTenantStatus struct {
+	// Conditions represent the latest available observations of the state of the tenant.
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// TenantID is the tenant ID in use for the tenant.
+	// +kubebuilder:validation:Optional
+	TenantID string `json:"tenantID,omitempty"`
+	// RemoteWriteURL is the URL remote write clients of the tenant should send data to.
+	// +kubebuilder:validation:Optional
+	RemoteWriteURL string `json:"remoteWriteURL,omitempty"`
+	// PublishedNamespaces are the namespaces the Prometheus remote write Secret is published to.
+	// +kubebuilder:validation:Optional
+	PublishedNamespaces []string `json:"publishedNamespaces,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Tenant",type="string",JSONPath=".status.tenantID"
+//+kubebuilder:printcolumn:name="Receive",type="string",JSONPath=".spec.receiveName"
+//+kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.remoteWriteURL"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ThanosTenant is the Schema for the thanostenants API.
+// A ThanosTenant onboards a tenant onto a ThanosReceive in the same namespace.
+type ThanosTenant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ThanosTenantSpec   `json:"spec,omitempty"`
+	Status ThanosTenantStatus `json:"status,omitempty"`
+}
+
+// GetTenantID returns the tenant ID for the ThanosTenant.
+func (t ThanosTenant) GetTenantID() string {
+	if t.Spec.TenantID != nil && *t.Spec.TenantID != "" {
+		return *t.Spec.TenantID
+	}
+	return t.GetName()
+}
+
+//+kubebuilder:object:root=true
+
+// ThanosTenantList contains a list of ThanosTenant
+type ThanosTenantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ThanosTenant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ThanosTenant{}, &ThanosTenantList{})
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ThanosToolsSpec defines the desired state of ThanosTools
+// +kubebuilder:validation:XValidation:rule="has(self.objectStorageConfig) != has(self.objectStorageRef)",message="exactly one of objectStorageConfig or objectStorageRef must be set"
+// +kubebuilder:validation:XValidation:rule="[has(self.mark), has(self.rewrite), has(self.retention)].filter(x, x).size() == 1",message="exactly one of mark, rewrite or retention must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.mark) == has(oldSelf.mark) && (!has(self.mark) || self.mark == oldSelf.mark) && has(self.rewrite) == has(oldSelf.rewrite) && (!has(self.rewrite) || self.rewrite == oldSelf.rewrite) && has(self.retention) == has(oldSelf.retention) && (!has(self.retention) || self.retention == oldSelf.retention)",message="the operation is immutable"
+type ThanosToolsSpec struct {
+	CommonFields `json:",inline"`
+	// Labels are additional labels to add to the Job created for the operation.
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// ObjectStorageConfig is the secret that contains the object storage configuration for the operation.
+	// +kubebuilder:validation:Optional
+	ObjectStorageConfig *ObjectStorageConfig `json:"objectStorageConfig,omitempty"`
+	// ObjectStorageRef references an existing Thanos resource in the same namespace.
+	// The object storage configuration of the referenced resource will be used for the operation.
+	// +kubebuilder:validation:Optional
+	ObjectStorageRef *ObjectStorageReference `json:"objectStorageRef,omitempty"`
+	// Mark adds or removes a marker on the given blocks.
+	// +kubebuilder:validation:Optional
+	Mark *MarkOperation `json:"mark,omitempty"`
+	// Rewrite rewrites the given blocks, deleting series matching the given matchers.
+	// +kubebuilder:validation:Optional
+	Rewrite *RewriteOperation `json:"rewrite,omitempty"`
+	// Retention applies the given retention policies to the blocks in the bucket.
+	// +kubebuilder:validation:Optional
+	Retention *RetentionOperation `json:"retention,omitempty"`
+	// BackoffLimit is the number of retries before the operation is considered failed.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=0
+	// +kubebuilder:validation:Optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// SkipVerification disables the `thanos tools bucket verify` Job which is run after operations that delete data
+	// from the bucket, i.e. retention, rewrites which are not a dry run and deletion marks.
+	// When set, the operation is reported as succeeded as soon as its Job completes.
+	// +kubebuilder:validation:Optional
+	SkipVerification bool `json:"skipVerification,omitempty"`
+	// When a resource is paused, no actions except for deletion
+	// will be performed on the underlying objects.
+	// +kubebuilder:validation:Optional
+	Paused *bool `json:"paused,omitempty"`
+	// Additional configuration for the Thanos components. Allows you to add
+	// additional args, containers, volumes, and volume mounts to Thanos Deployments,
+	// and StatefulSets. Ideal to use for things like sidecars.
+	// +kubebuilder:validation:Optional
+	Additional `json:",inline"`
+}
+
+// ObjectStorageReference is a reference to a Thanos resource which holds an object storage configuration.
+type ObjectStorageReference struct {
+	// Kind of the referenced resource.
+	// +kubebuilder:validation:Enum=ThanosStore;ThanosCompact;ThanosRuler;ThanosReceive
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+	// Name of the referenced resource.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// MarkerType is the type of marker to apply to a block.
+type MarkerType string
+
+const (
+	// DeletionMarker marks a block for deletion.
+	DeletionMarker MarkerType = "deletion-mark.json"
+	// NoCompactMarker excludes a block from compaction.
+	NoCompactMarker MarkerType = "no-compact-mark.json"
+)
+
+// MarkOperation configures the `thanos tools bucket mark` operation.
+type MarkOperation struct {
+	// Marker is the type of marker to apply.
+	// +kubebuilder:validation:Enum=deletion-mark.json;no-compact-mark.json
+	// +kubebuilder:validation:Required
+	Marker MarkerType `json:"marker"`
+	// BlockIDs are the ULIDs of the blocks to mark.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	BlockIDs []string `json:"blockIDs"`
+	// Details is a human readable string explaining why the blocks were marked.
+	// +kubebuilder:validation:Required
+	Details string `json:"details"`
+	// Remove removes the marker from the blocks instead of adding it.
+	// +kubebuilder:validation:Optional
+	Remove bool `json:"remove,omitempty"`
+}
+
+// RewriteOperation configures the `thanos tools bucket rewrite` operation.
+type RewriteOperation struct {
+	// BlockIDs are the ULIDs of the blocks to rewrite.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	BlockIDs []string `json:"blockIDs"`
+	// DeleteMatchers are the series selectors for the series to delete from the blocks.
+	// For example `{__name__="up", job="foo"}`.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	DeleteMatchers []string `json:"deleteMatchers"`
+	// DryRun prints the changes without uploading the rewritten blocks.
+	// +kubebuilder:default=true
+	// +kubebuilder:validation:Optional
+	DryRun *bool `json:"dryRun,omitempty"`
+	// DeleteSourceBlocks marks the source blocks for deletion after a successful rewrite.
+	// Ignored when DryRun is set.
+	// +kubebuilder:validation:Optional
+	DeleteSourceBlocks bool `json:"deleteSourceBlocks,omitempty"`
+}
+
+// RetentionOperation configures the `thanos tools bucket retention` operation.
+// +kubebuilder:validation:MinProperties=1
+type RetentionOperation struct {
+	// Raw is the retention configuration for the raw samples.
+	// +kubebuilder:validation:Optional
+	Raw *Duration `json:"raw,omitempty"`
+	// FiveMinutes is the retention configuration for samples of resolution 1 (5 minutes).
+	// +kubebuilder:validation:Optional
+	FiveMinutes *Duration `json:"fiveMinutes,omitempty"`
+	// OneHour is the retention configuration for samples of resolution 2 (1 hour).
+	// +kubebuilder:validation:Optional
+	OneHour *Duration `json:"oneHour,omitempty"`
+}
+
+// ThanosToolsPhase is the phase of the operation.
+type ThanosToolsPhase string
+
+const (
+	// ThanosToolsPending means the Job for the operation has been created but has not started yet.
+	ThanosToolsPending ThanosToolsPhase = "Pending"
+	// ThanosToolsRunning means the Job for the operation is running.
+	ThanosToolsRunning ThanosToolsPhase = "Running"
+	// ThanosToolsVerifying means the Job for the operation completed and the bucket is being verified.
+	ThanosToolsVerifying ThanosToolsPhase = "Verifying"
+	// ThanosToolsSucceeded means the operation completed successfully.
+	ThanosToolsSucceeded ThanosToolsPhase = "Succeeded"
+	// ThanosToolsFailed means the operation failed.
+	ThanosToolsFailed ThanosToolsPhase = "Failed"
+)
+
+const (
+	// ThanosToolsConditionComplete indicates whether the operation has finished running.
+	// The reason of the condition is set to the final phase of the operation.
+	ThanosToolsConditionComplete = "Complete"
+	// ThanosToolsConditionVerified indicates whether the bucket passed verification after the operation.
+	// It is only set for operations which are verified.
+	ThanosToolsConditionVerified = "Verified"
+)
+
+// ThanosToolsStatus defines the observed state of ThanosTools
+type ThanosToolsStatus struct {
+	// Conditions represent the latest available observations of the state of the operation.
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// Phase is the current phase of the operation.
+	// +kubebuilder:validation:Optional
+	Phase ThanosToolsPhase `json:"phase,omitempty"`
+	// JobName is the name of the Job running the operation.
+	// +kubebuilder:validation:Optional
+	JobName string `json:"jobName,omitempty"`
+	// VerificationJobName is the name of the Job verifying the bucket after the operation.
+	// +kubebuilder:validation:Optional
+	VerificationJobName string `json:"verificationJobName,omitempty"`
+	// StartTime is the time the operation started.
+	// +kubebuilder:validation:Optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the operation finished.
+	// +kubebuilder:validation:Optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:path=thanostools
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Job",type="string",JSONPath=".status.jobName"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ThanosTools is the Schema for the thanostools API.
+// Each ThanosTools resource runs a single one-off `thanos tools bucket` operation as a Job.
+// The spec of the operation is immutable once created.
+type ThanosTools struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ThanosToolsSpec   `json:"spec,omitempty"`
+	Status ThanosToolsStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ThanosToolsList contains a list of ThanosTools
+type ThanosToolsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ThanosTools `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ThanosTools{}, &ThanosToolsList{})
+}
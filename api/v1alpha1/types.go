@@ -1,24 +1,203 @@
 package v1alpha1
 
 import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/utils/ptr"
 )
 
+const (
+	// ConditionBlocked is set on a resource when the rollout of its workloads is blocked
+	// because an image does not satisfy the operator image policy.
+	ConditionBlocked = "Blocked"
+	// ConditionRolledBack is set on a resource when its workloads were rolled back to their last known good state
+	// because a rollout of the current generation failed.
+	ConditionRolledBack = "RolledBack"
+	// ConditionEndpointsHealthy is set on a ThanosQuery to report whether all Store API endpoints
+	// the Querier is connected to are healthy.
+	ConditionEndpointsHealthy = "EndpointsHealthy"
+	// ConditionAvailable is set on a resource to report whether its workloads have the minimum number of replicas available.
+	ConditionAvailable = "Available"
+	// ConditionReconciled is set on a resource to report whether the last reconciliation of its current generation succeeded.
+	ConditionReconciled = "Reconciled"
+	// ConditionDegraded is set on a resource to report whether some replicas of its workloads are not ready,
+	// a rollout exceeded its progress deadline, its pods can not pull images, be scheduled or stay running,
+	// its object storage configuration failed the pre-flight check, or the options of its components are invalid.
+	ConditionDegraded = "Degraded"
+	// ConditionPaused is set on a resource to report whether its reconciliation is paused.
+	ConditionPaused = "Paused"
+	// ConditionVersionAllowed is set on a resource to report whether the Thanos versions it requests
+	// are within the ranges allowed by the operator version policy.
+	ConditionVersionAllowed = "VersionAllowed"
+	// ConditionCrashLooping is set on a resource to report whether containers of its pods are crash looping.
+	// Its message holds the exit code and termination message of the last crash of each crashing container.
+	ConditionCrashLooping = "CrashLooping"
+	// ConditionMissingPermissions is set on a resource when some of its objects were not applied because the operator
+	// lacks the RBAC permissions to apply them. Its message lists the denied verbs and resources.
+	// It is removed once a later probe applies the objects.
+	ConditionMissingPermissions = "MissingPermissions"
+)
+
+const (
+	// StackLabel is the label used to group resources into a stack.
+	// Disruptive rollouts of the workloads of resources within the same stack and namespace are serialized,
+	// so that for example a Querier, its Stores and the Receive ingesters are never restarted at the same time.
+	StackLabel = "monitoring.thanos.io/stack"
+
+	// EndpointsEventAnnotation is set on the EndpointAdded and EndpointRemoved events of a ThanosQuery
+	// and holds the comma separated namespaced names of the StoreAPI Services which were added or removed.
+	EndpointsEventAnnotation = "monitoring.thanos.io/endpoints"
+
+	// DebugPodAnnotation is set on a ThanosStore or ThanosCompact to the name of one of its pods, to attach an ephemeral
+	// debug container running a shell with the Thanos tools and the object storage configuration of the pod.
+	DebugPodAnnotation = "monitoring.thanos.io/debug-pod"
+
+	// RestoreIngesterAnnotation is set on a ThanosReceive to the name of one of its ingester pods, to replace the volume
+	// of the pod with a volume restored from its latest VolumeSnapshot. It is removed once the volume is restored.
+	RestoreIngesterAnnotation = "monitoring.thanos.io/restore-ingester"
+
+	// ReconcileNowAnnotation is set on a Thanos resource to force its immediate reconciliation without changing its spec,
+	// e.g. after fixing an object it depends on out-of-band. Setting it to a new value, such as the current timestamp,
+	// also applies the objects of the resource again which were blocked by the apply retry budget.
+	ReconcileNowAnnotation = "monitoring.thanos.io/reconcile-now"
+
+	// PauseShardAnnotation is set to "true" on the StatefulSet of a Store Gateway shard to pause the reconciliation
+	// of the shard, while the other shards of the ThanosStore stay managed. The resources of a paused shard are neither
+	// updated nor pruned, so that they can be changed by hand, e.g. during an incident.
+	PauseShardAnnotation = "monitoring.thanos.io/paused"
+)
+
 // Duration is a valid time duration that can be parsed by Prometheus model.ParseDuration() function.
 // Supported units: y, w, d, h, m, s, ms
 // Examples: `30s`, `1m`, `1h20m15s`, `15d`
 // +kubebuilder:validation:Pattern:="^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+// +kubebuilder:validation:MaxLength=32
 type Duration string
 
+// TimeOrDuration is either a constant time in RFC3339 format, or a valid time duration relative to the current time
+// that can be parsed by the Prometheus model.ParseDuration() function, optionally prefixed with a minus sign for times in the past.
+// Every valid Duration is a valid TimeOrDuration.
+// Examples: `-2w`, `-1d12h`, `0`, `2024-01-01T00:00:00Z`
+// +kubebuilder:validation:Pattern:="^(0|-?(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?|[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(\\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2}))$"
+type TimeOrDuration string
+
+// EndpointType is the type of endpoint a Store API is attached to the Querier as.
+// regular and strict endpoints are resolved to their members through DNS SRV records of the Service.
+// group and group-strict endpoints are attached as a single gRPC endpoint group through the Service address.
+// Strict endpoints are always kept by the Querier, even if they are unhealthy.
+// +kubebuilder:validation:Enum=regular;strict;group;group-strict
+type EndpointType string
+
+const (
+	RegularEndpointType     EndpointType = "regular"
+	StrictEndpointType      EndpointType = "strict"
+	GroupEndpointType       EndpointType = "group"
+	GroupStrictEndpointType EndpointType = "group-strict"
+)
+
 // ObjectStorageConfig is the secret that contains the object storage configuration.
 // The secret needs to be in the same namespace as the ReceiveHashring object.
 // See https://thanos.io/tip/thanos/storage.md/#supported-clients for relevant documentation.
-type ObjectStorageConfig corev1.SecretKeySelector
+type ObjectStorageConfig struct {
+	corev1.SecretKeySelector `json:",inline"`
+	// WorkloadIdentity projects a service account token into the components accessing the object storage,
+	// for object storage providers which accept OIDC federation tokens.
+	// The path of the token is exposed in the OBJSTORE_TOKEN_FILE environment variable, which the object storage
+	// configuration and additional environment variables can reference as $(OBJSTORE_TOKEN_FILE).
+	// +kubebuilder:validation:Optional
+	WorkloadIdentity *WorkloadIdentity `json:"workloadIdentity,omitempty"`
+}
+
+// WorkloadIdentity configures the projected service account token used to authenticate to the object storage.
+type WorkloadIdentity struct {
+	// Audience is the intended audience of the token, as expected by the identity provider of the object storage.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Audience string `json:"audience"`
+	// ExpirationSeconds is the requested lifetime of the token. The kubelet rotates the token before it expires.
+	// +kubebuilder:validation:Minimum=600
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// ObjectStorage is an object storage configuration which the operator renders into a managed Secret.
+// Credentials are referenced from Secrets and passed to Thanos in environment variables, so they are not copied.
+// Without credentials, they are discovered from the environment, e.g. through IRSA, GKE Workload Identity
+// or Azure Workload Identity, see the serviceAccount field of the component.
+// +kubebuilder:validation:XValidation:rule="[has(self.s3), has(self.gcs), has(self.azure)].filter(x, x).size() == 1",message="exactly one of s3, gcs or azure must be set"
+type ObjectStorage struct {
+	// S3 configures an S3 compatible bucket.
+	// +kubebuilder:validation:Optional
+	S3 *S3ObjectStorage `json:"s3,omitempty"`
+	// GCS configures a Google Cloud Storage bucket, accessed with the application default credentials.
+	// +kubebuilder:validation:Optional
+	GCS *GCSObjectStorage `json:"gcs,omitempty"`
+	// Azure configures an Azure Blob Storage container.
+	// +kubebuilder:validation:Optional
+	Azure *AzureObjectStorage `json:"azure,omitempty"`
+	// WorkloadIdentity projects a service account token into the components accessing the object storage,
+	// for object storage providers which accept OIDC federation tokens.
+	// +kubebuilder:validation:Optional
+	WorkloadIdentity *WorkloadIdentity `json:"workloadIdentity,omitempty"`
+}
+
+// S3ObjectStorage configures an S3 compatible bucket.
+// +kubebuilder:validation:XValidation:rule="has(self.accessKey) == has(self.secretKey)",message="accessKey and secretKey must be set together"
+type S3ObjectStorage struct {
+	// Bucket is the name of the bucket.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+	// Endpoint is the host and optional port of the S3 API, e.g. s3.eu-west-1.amazonaws.com.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Endpoint string `json:"endpoint"`
+	// Region is the region of the bucket.
+	// +kubebuilder:validation:Optional
+	Region string `json:"region,omitempty"`
+	// Insecure connects to the endpoint over plain HTTP.
+	// +kubebuilder:validation:Optional
+	Insecure bool `json:"insecure,omitempty"`
+	// AccessKey references the access key ID of static credentials.
+	// +kubebuilder:validation:Optional
+	AccessKey *corev1.SecretKeySelector `json:"accessKey,omitempty"`
+	// SecretKey references the secret access key of static credentials.
+	// +kubebuilder:validation:Optional
+	SecretKey *corev1.SecretKeySelector `json:"secretKey,omitempty"`
+}
+
+// GCSObjectStorage configures a Google Cloud Storage bucket.
+type GCSObjectStorage struct {
+	// Bucket is the name of the bucket.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+}
+
+// AzureObjectStorage configures an Azure Blob Storage container.
+type AzureObjectStorage struct {
+	// StorageAccount is the name of the storage account.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	StorageAccount string `json:"storageAccount"`
+	// Container is the name of the container.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Container string `json:"container"`
+	// Endpoint is the storage endpoint suffix, e.g. blob.core.chinacloudapi.cn for sovereign clouds.
+	// If not set, the endpoint of the public Azure cloud is used.
+	// +kubebuilder:validation:Optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// StorageAccountKey references the key of the storage account.
+	// +kubebuilder:validation:Optional
+	StorageAccountKey *corev1.SecretKeySelector `json:"storageAccountKey,omitempty"`
+}
 
 // CacheConfig is the configuration for the cache.
-// If both InMemoryCacheConfig and ExternalCacheConfig are specified, the operator will prefer the ExternalCacheConfig.
+// If more than one cache is specified, the operator prefers the ExternalCacheConfig, then the RedisCacheConfig,
+// then the MemcachedCacheConfig and then the InMemoryCacheConfig.
 // +kubebuilder:validation:Optional
 type CacheConfig struct {
 	// InMemoryCacheConfig is the configuration for the in-memory cache.
@@ -27,6 +206,125 @@ type CacheConfig struct {
 	// ExternalCacheConfig is the configuration for the external cache.
 	// +kubebuilder:validation:Optional
 	ExternalCacheConfig *corev1.SecretKeySelector `json:"externalCacheConfig,omitempty"`
+	// RedisCacheConfig is the configuration for a Redis cache.
+	// +kubebuilder:validation:Optional
+	RedisCacheConfig *RedisCacheConfig `json:"redisCacheConfig,omitempty"`
+	// MemcachedCacheConfig is the configuration for a Memcached cache.
+	// +kubebuilder:validation:Optional
+	MemcachedCacheConfig *MemcachedCacheConfig `json:"memcachedCacheConfig,omitempty"`
+}
+
+// RedisCacheConfig is the configuration for a Redis cache.
+// See https://thanos.io/tip/components/store.md/#redis-index-cache
+type RedisCacheConfig struct {
+	// Addresses of the Redis servers in host:port format.
+	// More than one address are the seed nodes of a Redis Cluster, unless MasterName is set.
+	// Required unless Managed is set.
+	// +kubebuilder:validation:Optional
+	Addresses []string `json:"addresses,omitempty"`
+	// MasterName is the name of the master of a Redis Sentinel deployment.
+	// If set, Addresses are the addresses of the Sentinels.
+	// +kubebuilder:validation:Optional
+	MasterName *string `json:"masterName,omitempty"`
+	// Username to authenticate to Redis with ACLs.
+	// +kubebuilder:validation:Optional
+	Username *string `json:"username,omitempty"`
+	// PasswordSecret selects the password to authenticate to Redis in a Secret.
+	// +kubebuilder:validation:Optional
+	PasswordSecret *corev1.SecretKeySelector `json:"passwordSecret,omitempty"`
+	// DB is the database to select after connecting. Redis Clusters only support the database 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	DB *int32 `json:"db,omitempty"`
+	// TLS enables TLS on the connections to Redis.
+	// +kubebuilder:validation:Optional
+	TLS *RedisTLSConfig `json:"tls,omitempty"`
+	// Managed deploys a single Redis instance without persistence for the cache in the namespace of the resource.
+	// It is meant for non-critical caching, for which losing the cache on restarts is acceptable.
+	// Addresses, MasterName, Username, PasswordSecret and TLS must not be set with Managed.
+	// Only supported by spec.indexCacheConfig and spec.cachingBucketConfig of a ThanosStore.
+	// +kubebuilder:validation:Optional
+	Managed *ManagedRedisConfig `json:"managed,omitempty"`
+}
+
+// RedisTLSConfig configures TLS on the connections to Redis.
+type RedisTLSConfig struct {
+	// CertSecret is the name of a Secret holding the client certificate and key in tls.crt and tls.key.
+	// It is only required if Redis requires client certificates.
+	// +kubebuilder:validation:Optional
+	CertSecret *string `json:"certSecret,omitempty"`
+	// CA selects the CA bundle the Redis server certificate is verified against in a Secret.
+	// If not set, the system CAs are used.
+	// +kubebuilder:validation:Optional
+	CA *corev1.SecretKeySelector `json:"ca,omitempty"`
+	// ServerName is the name the Redis server certificate is verified against.
+	// +kubebuilder:validation:Optional
+	ServerName *string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables the verification of the Redis server certificate.
+	// +kubebuilder:validation:Optional
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// ManagedRedisConfig configures a Redis deployed by the operator.
+type ManagedRedisConfig struct {
+	// Image is the Redis container image.
+	// +kubebuilder:default="docker.io/library/redis:7.2-alpine"
+	// +kubebuilder:validation:Optional
+	Image *string `json:"image,omitempty"`
+	// Resources of the Redis container.
+	// +kubebuilder:validation:Optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// MaxMemory is the amount of memory Redis uses for the cache, beyond which the least recently used keys are evicted.
+	// It should be lower than the memory limit of the container.
+	// +kubebuilder:default="256Mi"
+	// +kubebuilder:validation:Optional
+	MaxMemory *StorageSize `json:"maxMemory,omitempty"`
+}
+
+// MemcachedCacheConfig is the configuration for a Memcached cache.
+// See https://thanos.io/tip/components/store.md/#memcached-index-cache
+type MemcachedCacheConfig struct {
+	// Addresses of the Memcached servers in host:port format.
+	// DNS service discovery prefixes, such as dnssrv+, are supported.
+	// Required unless Managed is set.
+	// +kubebuilder:validation:Optional
+	Addresses []string `json:"addresses,omitempty"`
+	// MaxItemSize is the maximum size of an item stored in Memcached.
+	// Larger items are not cached. It must not exceed the maximum item size of the Memcached servers.
+	// If Managed is set, the maximum item size of the managed Memcached is used.
+	// +kubebuilder:validation:Optional
+	MaxItemSize *StorageSize `json:"maxItemSize,omitempty"`
+	// Managed deploys Memcached for the cache in the namespace of the resource.
+	// Addresses and MaxItemSize must not be set with Managed.
+	// Only supported by spec.queryFrontend.queryRangeResponseCacheConfig of a ThanosQuery and by spec.indexCacheConfig
+	// and spec.cachingBucketConfig of a ThanosStore, whose tiers share the managed Memcached instances.
+	// +kubebuilder:validation:Optional
+	Managed *ManagedMemcachedConfig `json:"managed,omitempty"`
+}
+
+// ManagedMemcachedConfig configures a Memcached deployed by the operator.
+type ManagedMemcachedConfig struct {
+	// Image is the Memcached container image.
+	// +kubebuilder:default="docker.io/library/memcached:1.6-alpine"
+	// +kubebuilder:validation:Optional
+	Image *string `json:"image,omitempty"`
+	// Replicas is the number of Memcached instances. Items are distributed across the instances.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Resources of the Memcached container.
+	// +kubebuilder:validation:Optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// MemoryLimit is the amount of memory each Memcached instance uses for items, beyond which the least recently used items are evicted.
+	// It should be lower than the memory limit of the container.
+	// +kubebuilder:default="256Mi"
+	// +kubebuilder:validation:Optional
+	MemoryLimit *StorageSize `json:"memoryLimit,omitempty"`
+	// MaxItemSize is the maximum size of an item stored in Memcached.
+	// +kubebuilder:default="1Mi"
+	// +kubebuilder:validation:Optional
+	MaxItemSize *StorageSize `json:"maxItemSize,omitempty"`
 }
 
 // InMemoryCacheConfig is the configuration for the in-memory cache.
@@ -83,6 +381,28 @@ type CommonFields struct {
 	// ResourceRequirements for the Thanos component container.
 	// +kubebuilder:validation:Optional
 	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+	// ContainerResources sets the resource requirements of individual containers of the Pods, matched by container name.
+	// It applies to all containers, including additional containers and sidecars added by the operator,
+	// so that Pods can be admitted in namespaces enforcing resource quotas.
+	// Requirements set here for the Thanos component container take precedence over ResourceRequirements.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	ContainerResources []ContainerResources `json:"containerResources,omitempty"`
+	// ListenPorts overrides the default ports the Thanos component listens on.
+	// The ports are propagated to the container, its flags and probes, the Service and the discovery of the component.
+	// Ports which are not served by the component are ignored.
+	// +kubebuilder:validation:Optional
+	ListenPorts *ListenPorts `json:"listenPorts,omitempty"`
+	// MetricsPortName is the name of the port of the HTTP server of the Thanos component, which serves its metrics.
+	// It names the container port and the Service port, and is the port scraped by the generated ServiceMonitors.
+	// Set it to the name existing scrape configurations select, such as http-metrics or metrics. Defaults to http.
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:XValidation:rule="self.matches('[a-z]')",message="metricsPortName must contain a letter"
+	// +kubebuilder:validation:XValidation:rule="self != 'grpc'",message="metricsPortName must not be the name of the gRPC port"
+	// +kubebuilder:validation:Optional
+	MetricsPortName *string `json:"metricsPortName,omitempty"`
 	// Log level for Thanos.
 	// +kubebuilder:validation:Enum=debug;info;warn;error
 	// +kubebuilder:validation:Optional
@@ -92,6 +412,137 @@ type CommonFields struct {
 	// +kubebuilder:default:=logfmt
 	// +kubebuilder:validation:Optional
 	LogFormat *string `json:"logFormat,omitempty"`
+	// TerminationMessagePolicy of the Thanos container. FallbackToLogsOnError, the default, uses the last lines of the
+	// container log as termination message when the container fails without writing one, which surfaces the cause of
+	// crashes in the pod status and in the CrashLooping condition. File only reports the termination message file,
+	// e.g. to keep log output out of the pod status.
+	// +kubebuilder:validation:Enum=File;FallbackToLogsOnError
+	// +kubebuilder:validation:Optional
+	TerminationMessagePolicy *corev1.TerminationMessagePolicy `json:"terminationMessagePolicy,omitempty"`
+	// Probes tunes the liveness, readiness and startup probes of the Thanos container.
+	// +kubebuilder:validation:Optional
+	Probes *Probes `json:"probes,omitempty"`
+	// PodSecurityContext overrides fields of the security context of the Pods.
+	// By default, the Pods use the RuntimeDefault seccomp profile, as required by the restricted Pod Security Standard.
+	// Each field which is set replaces the default, e.g. to set the user, group or fsGroup the Pods run with.
+	// +kubebuilder:validation:Optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// SecurityContext overrides fields of the security context of the Thanos container.
+	// By default, the container runs as non-root, without privilege escalation and with all capabilities dropped,
+	// as required by the restricted Pod Security Standard.
+	// Each field which is set replaces the default, e.g. to enable a read-only root filesystem.
+	// +kubebuilder:validation:Optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// ServiceAccount configures the ServiceAccount the Pods run as.
+	// By default, the operator creates a ServiceAccount for each workload.
+	// +kubebuilder:validation:Optional
+	ServiceAccount *ServiceAccount `json:"serviceAccount,omitempty"`
+	// Affinity are the scheduling constraints of the Pods.
+	// Each of node affinity, pod affinity and pod anti-affinity which is set replaces the default set by the operator, if any.
+	// +kubebuilder:validation:Optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Tolerations allow the Pods to be scheduled on nodes with matching taints, e.g. dedicated node pools.
+	// +kubebuilder:validation:Optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// NodeSelector restricts the Pods to nodes with matching labels.
+	// +kubebuilder:validation:Optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// TopologySpreadConstraints control how the Pods are spread across topology domains such as zones.
+	// Constraints without a labelSelector select the Pods of the workload they are applied to, e.g. a single shard.
+	// +kubebuilder:validation:Optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// ServiceAccount configures the ServiceAccount of the Pods of a component.
+// +kubebuilder:validation:XValidation:rule="!has(self.name) || (!has(self.labels) && !has(self.annotations))",message="labels and annotations can only be set on the ServiceAccount created by the operator"
+type ServiceAccount struct {
+	// Name of an existing ServiceAccount the Pods run as, instead of the ServiceAccount created by the operator.
+	// The ServiceAccount must exist in the namespace of the resource.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Optional
+	Name *string `json:"name,omitempty"`
+	// Labels are additional labels of the ServiceAccount created by the operator.
+	// The labels set by the operator take precedence over labels set here.
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are additional annotations of the ServiceAccount created by the operator,
+	// e.g. eks.amazonaws.com/role-arn, iam.gke.io/gcp-service-account or azure.workload.identity/client-id
+	// to federate the identity of the Pods with a cloud provider.
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ListenPorts are the ports a Thanos component listens on.
+type ListenPorts struct {
+	// GRPC is the port of the gRPC server.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:Optional
+	GRPC *int32 `json:"grpc,omitempty"`
+	// HTTP is the port of the HTTP server.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:Optional
+	HTTP *int32 `json:"http,omitempty"`
+}
+
+// Probes tunes the probes of the Thanos container.
+// Settings which are not set keep the defaults of the operator.
+// +kubebuilder:validation:XValidation:rule="!has(self.liveness) || !has(self.liveness.successThreshold) || self.liveness.successThreshold == 1",message="liveness.successThreshold must be 1"
+// +kubebuilder:validation:XValidation:rule="!has(self.startup) || !has(self.startup.successThreshold) || self.startup.successThreshold == 1",message="startup.successThreshold must be 1"
+type Probes struct {
+	// Liveness tunes the liveness probe, which restarts the container when it fails.
+	// +kubebuilder:validation:Optional
+	Liveness *ProbeSettings `json:"liveness,omitempty"`
+	// Readiness tunes the readiness probe, which removes the Pod from the endpoints of its Services when it fails.
+	// +kubebuilder:validation:Optional
+	Readiness *ProbeSettings `json:"readiness,omitempty"`
+	// Startup adds a startup probe, checking the same endpoint as the readiness probe, which holds off the other
+	// probes until it succeeds. Use it for components which are slow to start, such as Store Gateways loading
+	// index headers, instead of delaying the liveness probe.
+	// +kubebuilder:validation:Optional
+	Startup *ProbeSettings `json:"startup,omitempty"`
+	// GRPC probes the readiness, and the startup if enabled, with the native gRPC health checks of Kubernetes
+	// against the gRPC port of the StoreAPI instead of the HTTP server.
+	// The liveness probe keeps checking the HTTP server, as the gRPC health service only reports readiness.
+	// It is ignored for components without a gRPC server, and not supported with gRPC server TLS.
+	// +kubebuilder:validation:Optional
+	GRPC bool `json:"grpc,omitempty"`
+}
+
+// ProbeSettings are the timing and threshold settings of a probe.
+type ProbeSettings struct {
+	// InitialDelaySeconds is the number of seconds after the container has started before the probe is run.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// TimeoutSeconds is the number of seconds after which the probe times out.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// PeriodSeconds is how often, in seconds, the probe is run.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// SuccessThreshold is the number of consecutive successes after a failure for the probe to be considered successful.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+	// FailureThreshold is the number of consecutive failures for the probe to be considered failed.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// ContainerResources are the resource requirements of a single container.
+type ContainerResources struct {
+	// Name of the container.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Resources are the resource requirements of the container.
+	// +kubebuilder:validation:Required
+	Resources corev1.ResourceRequirements `json:"resources"`
 }
 
 // Additional holds additional configuration for the Thanos components.
@@ -110,16 +561,67 @@ type Additional struct {
 	// +kubebuilder:validation:Optional
 	VolumeMounts []corev1.VolumeMount `json:"additionalVolumeMounts,omitempty"`
 	// Additional ports to expose on the Thanos component container in a Deployment or StatefulSet
-	// controlled by the operator.
+	// controlled by the operator. Each port must be named and is also exposed on the Services of the component,
+	// targeting the container port by name, unless an additional Service port with the same name is given.
+	// Ports must not collide with the ports managed by the operator.
 	// +kubebuilder:validation:Optional
 	Ports []corev1.ContainerPort `json:"additionalPorts,omitempty"`
 	// Additional environment variables to add to the Thanos component container in a Deployment or StatefulSet
 	// controlled by the operator.
 	// +kubebuilder:validation:Optional
 	Env []corev1.EnvVar `json:"additionalEnv,omitempty"`
-	// AdditionalServicePorts are additional ports to expose on the Service for the Thanos component.
+	// AdditionalServicePorts are additional ports to expose on the Services for the Thanos component.
+	// Each port must be named. Use these to set the appProtocol or target of an additional container port.
+	// Ports must not collide with the ports managed by the operator.
 	// +kubebuilder:validation:Optional
 	ServicePorts []corev1.ServicePort `json:"additionalServicePorts,omitempty"`
+	// Mounts mount ConfigMaps and Secrets into the Thanos component container in a Deployment or StatefulSet
+	// controlled by the operator. The operator generates the matching Volumes and VolumeMounts.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	Mounts []Mount `json:"additionalMounts,omitempty"`
+}
+
+// Mount mounts a ConfigMap or Secret into the Thanos component container.
+// +kubebuilder:validation:XValidation:rule="has(self.configMap) != has(self.secret)",message="exactly one of configMap or secret must be set"
+type Mount struct {
+	// Name of the mount. It is used as the name of the generated Volume,
+	// so it must not be used by any of the additional volumes.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// ConfigMap is the ConfigMap to mount.
+	// +kubebuilder:validation:Optional
+	ConfigMap *MountSource `json:"configMap,omitempty"`
+	// Secret is the Secret to mount.
+	// +kubebuilder:validation:Optional
+	Secret *MountSource `json:"secret,omitempty"`
+	// MountPath is the absolute path within the container at which the source is mounted.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^/`
+	MountPath string `json:"mountPath"`
+	// SubPath is the path within the source to mount at MountPath instead of its root,
+	// typically a key or item path to mount a single file.
+	// Files mounted with a SubPath are not updated when the source changes.
+	// +kubebuilder:validation:Optional
+	SubPath string `json:"subPath,omitempty"`
+}
+
+// MountSource selects the keys of a ConfigMap or Secret to mount.
+type MountSource struct {
+	// Name of the ConfigMap or Secret. It must be in the same namespace as the resource.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Items projects keys to paths within the mount.
+	// If not set, each key is projected to a file named after the key.
+	// +kubebuilder:validation:Optional
+	Items []corev1.KeyToPath `json:"items,omitempty"`
+	// Optional specifies whether the ConfigMap or Secret may be missing.
+	// +kubebuilder:validation:Optional
+	Optional *bool `json:"optional,omitempty"`
 }
 
 // FeatureGates holds the configuration for behaviour that is behind feature flags in the operator.
@@ -134,6 +636,18 @@ type FeatureGates struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=true
 	PrometheusRuleEnabled *bool `json:"prometheusRuleEnabled,omitempty"`
+	// ReconcileProfiling records the time spent in each phase of every reconciliation, such as discovering related objects,
+	// rendering manifests and applying them per kind, in a ReconcileProfile event on the resource.
+	// Useful to understand why a large resource is slow to converge.
+	// +kubebuilder:validation:Optional
+	ReconcileProfiling *bool `json:"reconcileProfiling,omitempty"`
+	// ObjectStoragePreflight checks the object storage configurations read from Secrets before rolling out the workloads
+	// using them. A missing Secret or key, or a configuration Thanos would reject, such as an unknown type or a missing
+	// bucket, is reported in the Degraded condition and an event, and the workloads are not rolled out until it is fixed.
+	// The bucket is not accessed. This setting is only applicable to the ThanosStore, ThanosCompact, ThanosReceive and
+	// ThanosRuler CRDs, will be ignored for other components.
+	// +kubebuilder:validation:Optional
+	ObjectStoragePreflight *bool `json:"objectStoragePreflight,omitempty"`
 }
 
 // ServiceMonitorConfig is the configuration for the ServiceMonitor.
@@ -147,6 +661,190 @@ type ServiceMonitorConfig struct {
 	Labels map[string]string `json:"labels,omitempty"`
 }
 
+// PodDisruptionConfig configures the PodDisruptionBudget of a component, which limits the number of its pods
+// evicted at once by voluntary disruptions such as node drains.
+// The PodDisruptionBudget is only created for components with more than one replica.
+// +kubebuilder:validation:XValidation:rule="!(has(self.minAvailable) && has(self.maxUnavailable))",message="only one of minAvailable and maxUnavailable may be set"
+type PodDisruptionConfig struct {
+	// MinAvailable is the minimum number of pods that must remain available during a disruption.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	MinAvailable *int32 `json:"minAvailable,omitempty"`
+	// MaxUnavailable is the maximum number of pods that can be unavailable during a disruption.
+	// Defaults to 1 if MinAvailable is not set.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+}
+
+// AutoscalingConfig configures a HorizontalPodAutoscaler scaling the replicas of a component.
+// When set, the replicas of the component are managed by the HorizontalPodAutoscaler and its replicas field is ignored.
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not be greater than maxReplicas"
+type AutoscalingConfig struct {
+	// MinReplicas is the lower limit for the number of replicas.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit for the number of replicas.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetCPUUtilizationPercentage is the target average CPU utilization of the pods,
+	// as a percentage of their requested CPU.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	// TargetMemoryUtilizationPercentage is the target average memory utilization of the pods,
+	// as a percentage of their requested memory.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+	// Metrics are additional metrics to scale on, e.g. the rate of queries exposed through a custom metrics API.
+	// If no metric and no utilization target is set, the pods are scaled on a target average CPU utilization of 80%.
+	// +kubebuilder:validation:Optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+	// Behavior configures the scaling behavior of the HorizontalPodAutoscaler in both up and down directions.
+	// +kubebuilder:validation:Optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// GRPCServerTLSConfig enables TLS on the gRPC server of a component.
+type GRPCServerTLSConfig struct {
+	// CertSecret is the name of a Secret of type kubernetes.io/tls holding the certificate and key of the server,
+	// such as the Secrets issued by cert-manager.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	CertSecret string `json:"certSecret"`
+	// ClientCA selects the CA bundle in a Secret which client certificates are verified against.
+	// If set, clients must present a certificate signed by the CA (mTLS).
+	// +kubebuilder:validation:Optional
+	ClientCA *corev1.SecretKeySelector `json:"clientCA,omitempty"`
+}
+
+// GRPCServerConfig configures the gRPC server of a component.
+type GRPCServerConfig struct {
+	// MaxConnectionAge is the maximum age of the connections to the gRPC server, after which they are gracefully
+	// closed and re-established by the clients, e.g. to balance the connections of Queriers over new replicas
+	// and to redo TLS handshakes with rotated certificates. Defaults to 60m, the default of Thanos.
+	// The maximum sizes of the messages sent and received by the gRPC server are not configurable,
+	// Thanos always allows messages up to the maximum size supported by gRPC.
+	// +kubebuilder:validation:Optional
+	MaxConnectionAge *Duration `json:"maxConnectionAge,omitempty"`
+}
+
+// GRPCClientTLSConfig enables TLS on the connections of a component to gRPC servers.
+type GRPCClientTLSConfig struct {
+	// CertSecret is the name of a Secret of type kubernetes.io/tls holding the client certificate and key
+	// presented to servers which require client certificates (mTLS).
+	// +kubebuilder:validation:Optional
+	CertSecret *string `json:"certSecret,omitempty"`
+	// CA selects the CA bundle in a Secret which server certificates are verified against.
+	// If not set, the system CA bundle of the Thanos image is used.
+	// +kubebuilder:validation:Optional
+	CA *corev1.SecretKeySelector `json:"ca,omitempty"`
+	// ServerName is the name server certificates are verified against.
+	// If not set, the address the servers are dialed with is used.
+	// +kubebuilder:validation:Optional
+	ServerName *string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables the verification of server certificates.
+	// +kubebuilder:validation:Optional
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// BackupConfig configures how the persistent volumes of a component participate in cluster backup workflows,
+// such as Velero backups.
+type BackupConfig struct {
+	// VolumeSnapshotClass is the VolumeSnapshotClass used to snapshot the PersistentVolumeClaims of the component.
+	// +kubebuilder:validation:Optional
+	VolumeSnapshotClass *string `json:"volumeSnapshotClass,omitempty"`
+	// FileSystemBackup backs up the data volume of the pods with file system backup instead of a volume snapshot.
+	// +kubebuilder:validation:Optional
+	FileSystemBackup *bool `json:"fileSystemBackup,omitempty"`
+	// VolumeAnnotations are additional annotations added to the PersistentVolumeClaims of the component,
+	// e.g. to select the volumes of a backup policy.
+	// +kubebuilder:validation:Optional
+	VolumeAnnotations map[string]string `json:"volumeAnnotations,omitempty"`
+	// PodAnnotations are additional annotations added to the pods of the component.
+	// +kubebuilder:validation:Optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+	// PreBackupHook is a command run in the pods before their volumes are backed up,
+	// e.g. to flush data to disk.
+	// +kubebuilder:validation:Optional
+	PreBackupHook *BackupHook `json:"preBackupHook,omitempty"`
+	// PostBackupHook is a command run in the pods after their volumes are backed up.
+	// +kubebuilder:validation:Optional
+	PostBackupHook *BackupHook `json:"postBackupHook,omitempty"`
+}
+
+// BackupHook is a command run in a container of a pod around the backup of its volumes.
+type BackupHook struct {
+	// Container is the container the command runs in.
+	// Defaults to the Thanos container of the pod.
+	// +kubebuilder:validation:Optional
+	Container *string `json:"container,omitempty"`
+	// Command is the command to run.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	Command []string `json:"command"`
+	// OnError defines whether the backup continues or fails if the command fails.
+	// +kubebuilder:validation:Enum=Continue;Fail
+	// +kubebuilder:validation:Optional
+	OnError *string `json:"onError,omitempty"`
+	// Timeout is the time to wait for the command to complete.
+	// +kubebuilder:validation:Optional
+	Timeout *Duration `json:"timeout,omitempty"`
+}
+
+// RollbackSpec configures the rollback of workloads to their last known good state when a rollout fails.
+type RollbackSpec struct {
+	// ProgressDeadlineSeconds is the time a rollout has to make progress before it is considered failed
+	// and the workloads are rolled back to the last known good state.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=600
+	// +kubebuilder:validation:Optional
+	ProgressDeadlineSeconds int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// RequestLoggingConfig configures request logging for the HTTP and gRPC servers of a Thanos component.
+// See https://thanos.io/tip/thanos/logging.md/#request-logging for more details.
+type RequestLoggingConfig struct {
+	// HTTP configures logging of HTTP requests.
+	// +kubebuilder:validation:Optional
+	HTTP *RequestLoggingOptions `json:"http,omitempty"`
+	// GRPC configures logging of gRPC requests.
+	// +kubebuilder:validation:Optional
+	GRPC *RequestLoggingOptions `json:"grpc,omitempty"`
+}
+
+// RequestLoggingOptions configures the level and the phases of a request that are logged.
+type RequestLoggingOptions struct {
+	// Level is the log level used for request logs.
+	// +kubebuilder:validation:Enum=DEBUG;INFO;WARN;ERROR
+	// +kubebuilder:default:=INFO
+	Level string `json:"level,omitempty"`
+	// LogStart enables logging when a request starts.
+	// +kubebuilder:validation:Optional
+	LogStart *bool `json:"logStart,omitempty"`
+	// LogEnd enables logging when a request finishes. Defaults to true.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	LogEnd *bool `json:"logEnd,omitempty"`
+}
+
+// LogForwarding deploys a sidecar forwarding the logs of the Thanos container, such as request logs and slow query logs,
+// to an HTTP endpoint, e.g. to retain an audit trail of queries.
+type LogForwarding struct {
+	// Image of the sidecar. It must run Vector.
+	// If not set, the image configured with the -log-forwarder.image and -log-forwarder.registry flags of the operator is used.
+	// +kubebuilder:validation:Optional
+	Image *string `json:"image,omitempty"`
+	// Endpoint is the URL of the HTTP endpoint the logs are sent to as JSON encoded events.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +kubebuilder:validation:Required
+	Endpoint string `json:"endpoint"`
+}
+
 func (osc *ObjectStorageConfig) ToSecretKeySelector() corev1.SecretKeySelector {
 	return corev1.SecretKeySelector{
 		LocalObjectReference: corev1.LocalObjectReference{Name: osc.Name},
@@ -159,3 +857,16 @@ func (osc *ObjectStorageConfig) ToSecretKeySelector() corev1.SecretKeySelector {
 func (s StorageSize) ToResourceQuantity() resource.Quantity {
 	return resource.MustParse(string(s))
 }
+
+// EffectiveDefaults are the values deployed by the operator for the fields of the CommonFields
+// of a component which are defaulted when they are not set.
+type EffectiveDefaults struct {
+	// Image is the container image of Thanos, without the version.
+	Image string `json:"image"`
+	// Version is the version of Thanos.
+	Version string `json:"version"`
+	// LogLevel is the log level of Thanos.
+	LogLevel string `json:"logLevel"`
+	// LogFormat is the log format of Thanos.
+	LogFormat string `json:"logFormat"`
+}
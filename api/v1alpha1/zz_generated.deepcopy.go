@@ -21,6 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
+	v2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -74,6 +76,13 @@ func (in *Additional) DeepCopyInto(out *Additional) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Mounts != nil {
+		in, out := &in.Mounts, &out.Mounts
+		*out = make([]Mount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Additional.
@@ -86,6 +95,152 @@ func (in *Additional) DeepCopy() *Additional {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalingConfig) DeepCopyInto(out *AutoscalingConfig) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		in, out := &in.TargetMemoryUtilizationPercentage, &out.TargetMemoryUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingConfig.
+func (in *AutoscalingConfig) DeepCopy() *AutoscalingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureObjectStorage) DeepCopyInto(out *AzureObjectStorage) {
+	*out = *in
+	if in.StorageAccountKey != nil {
+		in, out := &in.StorageAccountKey, &out.StorageAccountKey
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureObjectStorage.
+func (in *AzureObjectStorage) DeepCopy() *AzureObjectStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureObjectStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupConfig) DeepCopyInto(out *BackupConfig) {
+	*out = *in
+	if in.VolumeSnapshotClass != nil {
+		in, out := &in.VolumeSnapshotClass, &out.VolumeSnapshotClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.FileSystemBackup != nil {
+		in, out := &in.FileSystemBackup, &out.FileSystemBackup
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VolumeAnnotations != nil {
+		in, out := &in.VolumeAnnotations, &out.VolumeAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PreBackupHook != nil {
+		in, out := &in.PreBackupHook, &out.PreBackupHook
+		*out = new(BackupHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostBackupHook != nil {
+		in, out := &in.PostBackupHook, &out.PostBackupHook
+		*out = new(BackupHook)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupConfig.
+func (in *BackupConfig) DeepCopy() *BackupConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupHook) DeepCopyInto(out *BackupHook) {
+	*out = *in
+	if in.Container != nil {
+		in, out := &in.Container, &out.Container
+		*out = new(string)
+		**out = **in
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OnError != nil {
+		in, out := &in.OnError, &out.OnError
+		*out = new(string)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupHook.
+func (in *BackupHook) DeepCopy() *BackupHook {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BlockConfig) DeepCopyInto(out *BlockConfig) {
 	*out = *in
@@ -121,6 +276,41 @@ func (in *BlockConfig) DeepCopy() *BlockConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockMarker) DeepCopyInto(out *BlockMarker) {
+	*out = *in
+	if in.BlockIDs != nil {
+		in, out := &in.BlockIDs, &out.BlockIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockMarker.
+func (in *BlockMarker) DeepCopy() *BlockMarker {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockMarker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockMarkerStatus) DeepCopyInto(out *BlockMarkerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockMarkerStatus.
+func (in *BlockMarkerStatus) DeepCopy() *BlockMarkerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockMarkerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CacheConfig) DeepCopyInto(out *CacheConfig) {
 	*out = *in
@@ -134,6 +324,16 @@ func (in *CacheConfig) DeepCopyInto(out *CacheConfig) {
 		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RedisCacheConfig != nil {
+		in, out := &in.RedisCacheConfig, &out.RedisCacheConfig
+		*out = new(RedisCacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemcachedCacheConfig != nil {
+		in, out := &in.MemcachedCacheConfig, &out.MemcachedCacheConfig
+		*out = new(MemcachedCacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheConfig.
@@ -146,6 +346,21 @@ func (in *CacheConfig) DeepCopy() *CacheConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoSchedulingSpec) DeepCopyInto(out *CoSchedulingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoSchedulingSpec.
+func (in *CoSchedulingSpec) DeepCopy() *CoSchedulingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CoSchedulingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommonFields) DeepCopyInto(out *CommonFields) {
 	*out = *in
@@ -174,6 +389,23 @@ func (in *CommonFields) DeepCopyInto(out *CommonFields) {
 		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ContainerResources != nil {
+		in, out := &in.ContainerResources, &out.ContainerResources
+		*out = make([]ContainerResources, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ListenPorts != nil {
+		in, out := &in.ListenPorts, &out.ListenPorts
+		*out = new(ListenPorts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricsPortName != nil {
+		in, out := &in.MetricsPortName, &out.MetricsPortName
+		*out = new(string)
+		**out = **in
+	}
 	if in.LogLevel != nil {
 		in, out := &in.LogLevel, &out.LogLevel
 		*out = new(string)
@@ -184,6 +416,57 @@ func (in *CommonFields) DeepCopyInto(out *CommonFields) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.TerminationMessagePolicy != nil {
+		in, out := &in.TerminationMessagePolicy, &out.TerminationMessagePolicy
+		*out = new(corev1.TerminationMessagePolicy)
+		**out = **in
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(Probes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(ServiceAccount)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonFields.
@@ -227,273 +510,391 @@ func (in *CompactConfig) DeepCopy() *CompactConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DownsamplingConfig) DeepCopyInto(out *DownsamplingConfig) {
+func (in *CompactSchedule) DeepCopyInto(out *CompactSchedule) {
 	*out = *in
-	if in.Disable != nil {
-		in, out := &in.Disable, &out.Disable
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Concurrency != nil {
-		in, out := &in.Concurrency, &out.Concurrency
-		*out = new(int32)
-		**out = **in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]CompactWindow, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownsamplingConfig.
-func (in *DownsamplingConfig) DeepCopy() *DownsamplingConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompactSchedule.
+func (in *CompactSchedule) DeepCopy() *CompactSchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(DownsamplingConfig)
+	out := new(CompactSchedule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExternalLabelShardingConfig) DeepCopyInto(out *ExternalLabelShardingConfig) {
+func (in *CompactScheduleStatus) DeepCopyInto(out *CompactScheduleStatus) {
 	*out = *in
-	if in.Values != nil {
-		in, out := &in.Values, &out.Values
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.NextTransition != nil {
+		in, out := &in.NextTransition, &out.NextTransition
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalLabelShardingConfig.
-func (in *ExternalLabelShardingConfig) DeepCopy() *ExternalLabelShardingConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompactScheduleStatus.
+func (in *CompactScheduleStatus) DeepCopy() *CompactScheduleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalLabelShardingConfig)
+	out := new(CompactScheduleStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ExternalLabels) DeepCopyInto(out *ExternalLabels) {
-	{
-		in := &in
-		*out = make(ExternalLabels, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
+func (in *CompactWindow) DeepCopyInto(out *CompactWindow) {
+	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalLabels.
-func (in ExternalLabels) DeepCopy() ExternalLabels {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompactWindow.
+func (in *CompactWindow) DeepCopy() *CompactWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalLabels)
+	out := new(CompactWindow)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FeatureGates) DeepCopyInto(out *FeatureGates) {
+func (in *ContainerResources) DeepCopyInto(out *ContainerResources) {
 	*out = *in
-	if in.ServiceMonitorConfig != nil {
-		in, out := &in.ServiceMonitorConfig, &out.ServiceMonitorConfig
-		*out = new(ServiceMonitorConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PrometheusRuleEnabled != nil {
-		in, out := &in.PrometheusRuleEnabled, &out.PrometheusRuleEnabled
-		*out = new(bool)
-		**out = **in
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerResources.
+func (in *ContainerResources) DeepCopy() *ContainerResources {
+	if in == nil {
+		return nil
 	}
+	out := new(ContainerResources)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureGates.
-func (in *FeatureGates) DeepCopy() *FeatureGates {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomStoreEndpoint) DeepCopyInto(out *CustomStoreEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomStoreEndpoint.
+func (in *CustomStoreEndpoint) DeepCopy() *CustomStoreEndpoint {
 	if in == nil {
 		return nil
 	}
-	out := new(FeatureGates)
+	out := new(CustomStoreEndpoint)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InMemoryCacheConfig) DeepCopyInto(out *InMemoryCacheConfig) {
+func (in *DownsamplingConfig) DeepCopyInto(out *DownsamplingConfig) {
 	*out = *in
-	if in.MaxSize != nil {
-		in, out := &in.MaxSize, &out.MaxSize
-		*out = new(StorageSize)
+	if in.Disable != nil {
+		in, out := &in.Disable, &out.Disable
+		*out = new(bool)
 		**out = **in
 	}
-	if in.MaxItemSize != nil {
-		in, out := &in.MaxItemSize, &out.MaxItemSize
-		*out = new(StorageSize)
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(int32)
 		**out = **in
 	}
+	if in.Worker != nil {
+		in, out := &in.Worker, &out.Worker
+		*out = new(DownsamplingWorker)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InMemoryCacheConfig.
-func (in *InMemoryCacheConfig) DeepCopy() *InMemoryCacheConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownsamplingConfig.
+func (in *DownsamplingConfig) DeepCopy() *DownsamplingConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(InMemoryCacheConfig)
+	out := new(DownsamplingConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngesterHashringSpec) DeepCopyInto(out *IngesterHashringSpec) {
+func (in *DownsamplingWorker) DeepCopyInto(out *DownsamplingWorker) {
 	*out = *in
-	in.CommonFields.DeepCopyInto(&out.CommonFields)
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.ExternalLabels != nil {
-		in, out := &in.ExternalLabels, &out.ExternalLabels
-		*out = make(ExternalLabels, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.StorageSize != nil {
+		in, out := &in.StorageSize, &out.StorageSize
+		*out = new(StorageSize)
+		**out = **in
 	}
-	out.TSDBConfig = in.TSDBConfig
-	if in.ObjectStorageConfig != nil {
-		in, out := &in.ObjectStorageConfig, &out.ObjectStorageConfig
-		*out = new(ObjectStorageConfig)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownsamplingWorker.
+func (in *DownsamplingWorker) DeepCopy() *DownsamplingWorker {
+	if in == nil {
+		return nil
 	}
-	if in.Tenants != nil {
-		in, out := &in.Tenants, &out.Tenants
+	out := new(DownsamplingWorker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EffectiveDefaults) DeepCopyInto(out *EffectiveDefaults) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EffectiveDefaults.
+func (in *EffectiveDefaults) DeepCopy() *EffectiveDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(EffectiveDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointGroup) DeepCopyInto(out *EndpointGroup) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	if in.ResponseTimeout != nil {
+		in, out := &in.ResponseTimeout, &out.ResponseTimeout
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.MaxConcurrentSelect != nil {
+		in, out := &in.MaxConcurrentSelect, &out.MaxConcurrentSelect
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointGroup.
+func (in *EndpointGroup) DeepCopy() *EndpointGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointStatus) DeepCopyInto(out *EndpointStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointStatus.
+func (in *EndpointStatus) DeepCopy() *EndpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointTypeOverride) DeepCopyInto(out *EndpointTypeOverride) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointTypeOverride.
+func (in *EndpointTypeOverride) DeepCopy() *EndpointTypeOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointTypeOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalLabelShardingConfig) DeepCopyInto(out *ExternalLabelShardingConfig) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngesterHashringSpec.
-func (in *IngesterHashringSpec) DeepCopy() *IngesterHashringSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalLabelShardingConfig.
+func (in *ExternalLabelShardingConfig) DeepCopy() *ExternalLabelShardingConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(IngesterHashringSpec)
+	out := new(ExternalLabelShardingConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngesterSpec) DeepCopyInto(out *IngesterSpec) {
-	*out = *in
-	in.DefaultObjectStorageConfig.DeepCopyInto(&out.DefaultObjectStorageConfig)
-	if in.Hashrings != nil {
-		in, out := &in.Hashrings, &out.Hashrings
-		*out = make([]IngesterHashringSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+func (in ExternalLabels) DeepCopyInto(out *ExternalLabels) {
+	{
+		in := &in
+		*out = make(ExternalLabels, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
-	in.Additional.DeepCopyInto(&out.Additional)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngesterSpec.
-func (in *IngesterSpec) DeepCopy() *IngesterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalLabels.
+func (in ExternalLabels) DeepCopy() ExternalLabels {
 	if in == nil {
 		return nil
 	}
-	out := new(IngesterSpec)
+	out := new(ExternalLabels)
 	in.DeepCopyInto(out)
-	return out
+	return *out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectStorageConfig) DeepCopyInto(out *ObjectStorageConfig) {
+func (in *FeatureGates) DeepCopyInto(out *FeatureGates) {
 	*out = *in
-	out.LocalObjectReference = in.LocalObjectReference
-	if in.Optional != nil {
-		in, out := &in.Optional, &out.Optional
+	if in.ServiceMonitorConfig != nil {
+		in, out := &in.ServiceMonitorConfig, &out.ServiceMonitorConfig
+		*out = new(ServiceMonitorConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrometheusRuleEnabled != nil {
+		in, out := &in.PrometheusRuleEnabled, &out.PrometheusRuleEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReconcileProfiling != nil {
+		in, out := &in.ReconcileProfiling, &out.ReconcileProfiling
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ObjectStoragePreflight != nil {
+		in, out := &in.ObjectStoragePreflight, &out.ObjectStoragePreflight
 		*out = new(bool)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageConfig.
-func (in *ObjectStorageConfig) DeepCopy() *ObjectStorageConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureGates.
+func (in *FeatureGates) DeepCopy() *FeatureGates {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectStorageConfig)
+	out := new(FeatureGates)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QueryFrontendSpec) DeepCopyInto(out *QueryFrontendSpec) {
+func (in *GCSObjectStorage) DeepCopyInto(out *GCSObjectStorage) {
 	*out = *in
-	in.CommonFields.DeepCopyInto(&out.CommonFields)
-	if in.QueryLabelSelector != nil {
-		in, out := &in.QueryLabelSelector, &out.QueryLabelSelector
-		*out = new(v1.LabelSelector)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSObjectStorage.
+func (in *GCSObjectStorage) DeepCopy() *GCSObjectStorage {
+	if in == nil {
+		return nil
 	}
-	if in.LogQueriesLongerThan != nil {
-		in, out := &in.LogQueriesLongerThan, &out.LogQueriesLongerThan
-		*out = new(Duration)
+	out := new(GCSObjectStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCClientTLSConfig) DeepCopyInto(out *GRPCClientTLSConfig) {
+	*out = *in
+	if in.CertSecret != nil {
+		in, out := &in.CertSecret, &out.CertSecret
+		*out = new(string)
 		**out = **in
 	}
-	if in.QueryRangeResponseCacheConfig != nil {
-		in, out := &in.QueryRangeResponseCacheConfig, &out.QueryRangeResponseCacheConfig
-		*out = new(CacheConfig)
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.QueryRangeSplitInterval != nil {
-		in, out := &in.QueryRangeSplitInterval, &out.QueryRangeSplitInterval
-		*out = new(Duration)
+	if in.ServerName != nil {
+		in, out := &in.ServerName, &out.ServerName
+		*out = new(string)
 		**out = **in
 	}
-	if in.LabelsSplitInterval != nil {
-		in, out := &in.LabelsSplitInterval, &out.LabelsSplitInterval
-		*out = new(Duration)
+	if in.InsecureSkipVerify != nil {
+		in, out := &in.InsecureSkipVerify, &out.InsecureSkipVerify
+		*out = new(bool)
 		**out = **in
 	}
-	if in.LabelsDefaultTimeRange != nil {
-		in, out := &in.LabelsDefaultTimeRange, &out.LabelsDefaultTimeRange
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCClientTLSConfig.
+func (in *GRPCClientTLSConfig) DeepCopy() *GRPCClientTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCClientTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCServerConfig) DeepCopyInto(out *GRPCServerConfig) {
+	*out = *in
+	if in.MaxConnectionAge != nil {
+		in, out := &in.MaxConnectionAge, &out.MaxConnectionAge
 		*out = new(Duration)
 		**out = **in
 	}
-	in.Additional.DeepCopyInto(&out.Additional)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryFrontendSpec.
-func (in *QueryFrontendSpec) DeepCopy() *QueryFrontendSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCServerConfig.
+func (in *GRPCServerConfig) DeepCopy() *GRPCServerConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(QueryFrontendSpec)
+	out := new(GRPCServerConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RetentionResolutionConfig) DeepCopyInto(out *RetentionResolutionConfig) {
+func (in *GRPCServerTLSConfig) DeepCopyInto(out *GRPCServerTLSConfig) {
 	*out = *in
+	if in.ClientCA != nil {
+		in, out := &in.ClientCA, &out.ClientCA
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionResolutionConfig.
-func (in *RetentionResolutionConfig) DeepCopy() *RetentionResolutionConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCServerTLSConfig.
+func (in *GRPCServerTLSConfig) DeepCopy() *GRPCServerTLSConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(RetentionResolutionConfig)
+	out := new(GRPCServerTLSConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RouterSpec) DeepCopyInto(out *RouterSpec) {
+func (in *GrafanaDatasourceSpec) DeepCopyInto(out *GrafanaDatasourceSpec) {
 	*out = *in
-	in.CommonFields.DeepCopyInto(&out.CommonFields)
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
@@ -501,107 +902,1809 @@ func (in *RouterSpec) DeepCopyInto(out *RouterSpec) {
 			(*out)[key] = val
 		}
 	}
-	if in.ExternalLabels != nil {
-		in, out := &in.ExternalLabels, &out.ExternalLabels
-		*out = make(ExternalLabels, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.TimeInterval != nil {
+		in, out := &in.TimeInterval, &out.TimeInterval
+		*out = new(Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceSpec.
+func (in *GrafanaDatasourceSpec) DeepCopy() *GrafanaDatasourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HashringStatus) DeepCopyInto(out *HashringStatus) {
+	*out = *in
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(EffectiveDefaults)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HashringStatus.
+func (in *HashringStatus) DeepCopy() *HashringStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HashringStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InMemoryCacheConfig) DeepCopyInto(out *InMemoryCacheConfig) {
+	*out = *in
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(StorageSize)
+		**out = **in
+	}
+	if in.MaxItemSize != nil {
+		in, out := &in.MaxItemSize, &out.MaxItemSize
+		*out = new(StorageSize)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InMemoryCacheConfig.
+func (in *InMemoryCacheConfig) DeepCopy() *InMemoryCacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InMemoryCacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngesterHashringSpec) DeepCopyInto(out *IngesterHashringSpec) {
+	*out = *in
+	in.CommonFields.DeepCopyInto(&out.CommonFields)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StoreAPIServiceLabels != nil {
+		in, out := &in.StoreAPIServiceLabels, &out.StoreAPIServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExternalLabels != nil {
+		in, out := &in.ExternalLabels, &out.ExternalLabels
+		*out = make(ExternalLabels, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	out.TSDBConfig = in.TSDBConfig
+	if in.ObjectStorageConfig != nil {
+		in, out := &in.ObjectStorageConfig, &out.ObjectStorageConfig
+		*out = new(ObjectStorageConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngesterHashringSpec.
+func (in *IngesterHashringSpec) DeepCopy() *IngesterHashringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngesterHashringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngesterSnapshotConfig) DeepCopyInto(out *IngesterSnapshotConfig) {
+	*out = *in
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngesterSnapshotConfig.
+func (in *IngesterSnapshotConfig) DeepCopy() *IngesterSnapshotConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IngesterSnapshotConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngesterSpec) DeepCopyInto(out *IngesterSpec) {
+	*out = *in
+	in.DefaultObjectStorageConfig.DeepCopyInto(&out.DefaultObjectStorageConfig)
+	if in.Hashrings != nil {
+		in, out := &in.Hashrings, &out.Hashrings
+		*out = make([]IngesterHashringSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = new(IngesterSnapshotConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPCServer != nil {
+		in, out := &in.GRPCServer, &out.GRPCServer
+		*out = new(GRPCServerConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Additional.DeepCopyInto(&out.Additional)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngesterSpec.
+func (in *IngesterSpec) DeepCopy() *IngesterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngesterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenPorts) DeepCopyInto(out *ListenPorts) {
+	*out = *in
+	if in.GRPC != nil {
+		in, out := &in.GRPC, &out.GRPC
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenPorts.
+func (in *ListenPorts) DeepCopy() *ListenPorts {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenPorts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogForwarding) DeepCopyInto(out *LogForwarding) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogForwarding.
+func (in *LogForwarding) DeepCopy() *LogForwarding {
+	if in == nil {
+		return nil
+	}
+	out := new(LogForwarding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedMemcachedConfig) DeepCopyInto(out *ManagedMemcachedConfig) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryLimit != nil {
+		in, out := &in.MemoryLimit, &out.MemoryLimit
+		*out = new(StorageSize)
+		**out = **in
+	}
+	if in.MaxItemSize != nil {
+		in, out := &in.MaxItemSize, &out.MaxItemSize
+		*out = new(StorageSize)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedMemcachedConfig.
+func (in *ManagedMemcachedConfig) DeepCopy() *ManagedMemcachedConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedMemcachedConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedRedisConfig) DeepCopyInto(out *ManagedRedisConfig) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxMemory != nil {
+		in, out := &in.MaxMemory, &out.MaxMemory
+		*out = new(StorageSize)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedRedisConfig.
+func (in *ManagedRedisConfig) DeepCopy() *ManagedRedisConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedRedisConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MarkOperation) DeepCopyInto(out *MarkOperation) {
+	*out = *in
+	if in.BlockIDs != nil {
+		in, out := &in.BlockIDs, &out.BlockIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MarkOperation.
+func (in *MarkOperation) DeepCopy() *MarkOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(MarkOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemcachedCacheConfig) DeepCopyInto(out *MemcachedCacheConfig) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxItemSize != nil {
+		in, out := &in.MaxItemSize, &out.MaxItemSize
+		*out = new(StorageSize)
+		**out = **in
+	}
+	if in.Managed != nil {
+		in, out := &in.Managed, &out.Managed
+		*out = new(ManagedMemcachedConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemcachedCacheConfig.
+func (in *MemcachedCacheConfig) DeepCopy() *MemcachedCacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MemcachedCacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Mount) DeepCopyInto(out *Mount) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(MountSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(MountSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Mount.
+func (in *Mount) DeepCopy() *Mount {
+	if in == nil {
+		return nil
+	}
+	out := new(Mount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MountSource) DeepCopyInto(out *MountSource) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]corev1.KeyToPath, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Optional != nil {
+		in, out := &in.Optional, &out.Optional
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountSource.
+func (in *MountSource) DeepCopy() *MountSource {
+	if in == nil {
+		return nil
+	}
+	out := new(MountSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStorage) DeepCopyInto(out *ObjectStorage) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3ObjectStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(GCSObjectStorage)
+		**out = **in
+	}
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		*out = new(AzureObjectStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkloadIdentity != nil {
+		in, out := &in.WorkloadIdentity, &out.WorkloadIdentity
+		*out = new(WorkloadIdentity)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorage.
+func (in *ObjectStorage) DeepCopy() *ObjectStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStorageConfig) DeepCopyInto(out *ObjectStorageConfig) {
+	*out = *in
+	in.SecretKeySelector.DeepCopyInto(&out.SecretKeySelector)
+	if in.WorkloadIdentity != nil {
+		in, out := &in.WorkloadIdentity, &out.WorkloadIdentity
+		*out = new(WorkloadIdentity)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageConfig.
+func (in *ObjectStorageConfig) DeepCopy() *ObjectStorageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStorageReference) DeepCopyInto(out *ObjectStorageReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageReference.
+func (in *ObjectStorageReference) DeepCopy() *ObjectStorageReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorageReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionConfig) DeepCopyInto(out *PodDisruptionConfig) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionConfig.
+func (in *PodDisruptionConfig) DeepCopy() *PodDisruptionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeSettings) DeepCopyInto(out *ProbeSettings) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeSettings.
+func (in *ProbeSettings) DeepCopy() *ProbeSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Probes) DeepCopyInto(out *Probes) {
+	*out = *in
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(ProbeSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(ProbeSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(ProbeSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Probes.
+func (in *Probes) DeepCopy() *Probes {
+	if in == nil {
+		return nil
+	}
+	out := new(Probes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryFrontendSpec) DeepCopyInto(out *QueryFrontendSpec) {
+	*out = *in
+	in.CommonFields.DeepCopyInto(&out.CommonFields)
+	if in.QueryLabelSelector != nil {
+		in, out := &in.QueryLabelSelector, &out.QueryLabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DownstreamURL != nil {
+		in, out := &in.DownstreamURL, &out.DownstreamURL
+		*out = new(string)
+		**out = **in
+	}
+	if in.LogQueriesLongerThan != nil {
+		in, out := &in.LogQueriesLongerThan, &out.LogQueriesLongerThan
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.LogForwarding != nil {
+		in, out := &in.LogForwarding, &out.LogForwarding
+		*out = new(LogForwarding)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueryRangeResponseCacheConfig != nil {
+		in, out := &in.QueryRangeResponseCacheConfig, &out.QueryRangeResponseCacheConfig
+		*out = new(CacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueryRangeSplitInterval != nil {
+		in, out := &in.QueryRangeSplitInterval, &out.QueryRangeSplitInterval
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.LabelsSplitInterval != nil {
+		in, out := &in.LabelsSplitInterval, &out.LabelsSplitInterval
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.LabelsDefaultTimeRange != nil {
+		in, out := &in.LabelsDefaultTimeRange, &out.LabelsDefaultTimeRange
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.CoScheduling != nil {
+		in, out := &in.CoScheduling, &out.CoScheduling
+		*out = new(CoSchedulingSpec)
+		**out = **in
+	}
+	if in.PodDisruptionConfig != nil {
+		in, out := &in.PodDisruptionConfig, &out.PodDisruptionConfig
+		*out = new(PodDisruptionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoscalingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueryPool != nil {
+		in, out := &in.QueryPool, &out.QueryPool
+		*out = new(string)
+		**out = **in
+	}
+	in.Additional.DeepCopyInto(&out.Additional)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryFrontendSpec.
+func (in *QueryFrontendSpec) DeepCopy() *QueryFrontendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryFrontendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryFrontendStatus) DeepCopyInto(out *QueryFrontendStatus) {
+	*out = *in
+	if in.CacheHitPercent != nil {
+		in, out := &in.CacheHitPercent, &out.CacheHitPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryFrontendStatus.
+func (in *QueryFrontendStatus) DeepCopy() *QueryFrontendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryFrontendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryPool) DeepCopyInto(out *QueryPool) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.MaxConcurrent != nil {
+		in, out := &in.MaxConcurrent, &out.MaxConcurrent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryPool.
+func (in *QueryPool) DeepCopy() *QueryPool {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReceiveLimits) DeepCopyInto(out *ReceiveLimits) {
+	*out = *in
+	if in.MaxConcurrency != nil {
+		in, out := &in.MaxConcurrency, &out.MaxConcurrency
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MetaMonitoringURL != nil {
+		in, out := &in.MetaMonitoringURL, &out.MetaMonitoringURL
+		*out = new(string)
+		**out = **in
+	}
+	if in.MetaMonitoringLimitQuery != nil {
+		in, out := &in.MetaMonitoringLimitQuery, &out.MetaMonitoringLimitQuery
+		*out = new(string)
+		**out = **in
+	}
+	if in.DefaultLimits != nil {
+		in, out := &in.DefaultLimits, &out.DefaultLimits
+		*out = new(TenantLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]ReceiveTenantLimits, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReceiveLimits.
+func (in *ReceiveLimits) DeepCopy() *ReceiveLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(ReceiveLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReceiveTenantLimits) DeepCopyInto(out *ReceiveTenantLimits) {
+	*out = *in
+	in.Limits.DeepCopyInto(&out.Limits)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReceiveTenantLimits.
+func (in *ReceiveTenantLimits) DeepCopy() *ReceiveTenantLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(ReceiveTenantLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisCacheConfig) DeepCopyInto(out *RedisCacheConfig) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MasterName != nil {
+		in, out := &in.MasterName, &out.MasterName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Username != nil {
+		in, out := &in.Username, &out.Username
+		*out = new(string)
+		**out = **in
+	}
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DB != nil {
+		in, out := &in.DB, &out.DB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(RedisTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Managed != nil {
+		in, out := &in.Managed, &out.Managed
+		*out = new(ManagedRedisConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisCacheConfig.
+func (in *RedisCacheConfig) DeepCopy() *RedisCacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisCacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisTLSConfig) DeepCopyInto(out *RedisTLSConfig) {
+	*out = *in
+	if in.CertSecret != nil {
+		in, out := &in.CertSecret, &out.CertSecret
+		*out = new(string)
+		**out = **in
+	}
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServerName != nil {
+		in, out := &in.ServerName, &out.ServerName
+		*out = new(string)
+		**out = **in
+	}
+	if in.InsecureSkipVerify != nil {
+		in, out := &in.InsecureSkipVerify, &out.InsecureSkipVerify
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisTLSConfig.
+func (in *RedisTLSConfig) DeepCopy() *RedisTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestLoggingConfig) DeepCopyInto(out *RequestLoggingConfig) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(RequestLoggingOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPC != nil {
+		in, out := &in.GRPC, &out.GRPC
+		*out = new(RequestLoggingOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestLoggingConfig.
+func (in *RequestLoggingConfig) DeepCopy() *RequestLoggingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestLoggingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestLoggingOptions) DeepCopyInto(out *RequestLoggingOptions) {
+	*out = *in
+	if in.LogStart != nil {
+		in, out := &in.LogStart, &out.LogStart
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LogEnd != nil {
+		in, out := &in.LogEnd, &out.LogEnd
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestLoggingOptions.
+func (in *RequestLoggingOptions) DeepCopy() *RequestLoggingOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestLoggingOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionOperation) DeepCopyInto(out *RetentionOperation) {
+	*out = *in
+	if in.Raw != nil {
+		in, out := &in.Raw, &out.Raw
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.FiveMinutes != nil {
+		in, out := &in.FiveMinutes, &out.FiveMinutes
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.OneHour != nil {
+		in, out := &in.OneHour, &out.OneHour
+		*out = new(Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionOperation.
+func (in *RetentionOperation) DeepCopy() *RetentionOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionResolutionConfig) DeepCopyInto(out *RetentionResolutionConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionResolutionConfig.
+func (in *RetentionResolutionConfig) DeepCopy() *RetentionResolutionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionResolutionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RewriteOperation) DeepCopyInto(out *RewriteOperation) {
+	*out = *in
+	if in.BlockIDs != nil {
+		in, out := &in.BlockIDs, &out.BlockIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeleteMatchers != nil {
+		in, out := &in.DeleteMatchers, &out.DeleteMatchers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RewriteOperation.
+func (in *RewriteOperation) DeepCopy() *RewriteOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(RewriteOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackSpec) DeepCopyInto(out *RollbackSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackSpec.
+func (in *RollbackSpec) DeepCopy() *RollbackSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterSpec) DeepCopyInto(out *RouterSpec) {
+	*out = *in
+	in.CommonFields.DeepCopyInto(&out.CommonFields)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExternalLabels != nil {
+		in, out := &in.ExternalLabels, &out.ExternalLabels
+		*out = make(ExternalLabels, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(ReceiveLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Additional.DeepCopyInto(&out.Additional)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterSpec.
+func (in *RouterSpec) DeepCopy() *RouterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ObjectStorage) DeepCopyInto(out *S3ObjectStorage) {
+	*out = *in
+	if in.AccessKey != nil {
+		in, out := &in.AccessKey, &out.AccessKey
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKey != nil {
+		in, out := &in.SecretKey, &out.SecretKey
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ObjectStorage.
+func (in *S3ObjectStorage) DeepCopy() *S3ObjectStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ObjectStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccount) DeepCopyInto(out *ServiceAccount) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccount.
+func (in *ServiceAccount) DeepCopy() *ServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceMonitorConfig) DeepCopyInto(out *ServiceMonitorConfig) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMonitorConfig.
+func (in *ServiceMonitorConfig) DeepCopy() *ServiceMonitorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceMonitorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShardingConfig) DeepCopyInto(out *ShardingConfig) {
+	*out = *in
+	if in.ExternalLabelSharding != nil {
+		in, out := &in.ExternalLabelSharding, &out.ExternalLabelSharding
+		*out = make([]ExternalLabelShardingConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShardingConfig.
+func (in *ShardingConfig) DeepCopy() *ShardingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ShardingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShardingStrategy) DeepCopyInto(out *ShardingStrategy) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShardingStrategy.
+func (in *ShardingStrategy) DeepCopy() *ShardingStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(ShardingStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackComponentStatus) DeepCopyInto(out *StackComponentStatus) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackComponentStatus.
+func (in *StackComponentStatus) DeepCopy() *StackComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StackComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackIngressSpec) DeepCopyInto(out *StackIngressSpec) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.TLSSecretName != nil {
+		in, out := &in.TLSSecretName, &out.TLSSecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackIngressSpec.
+func (in *StackIngressSpec) DeepCopy() *StackIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StackIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreShardStatus) DeepCopyInto(out *StoreShardStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreShardStatus.
+func (in *StoreShardStatus) DeepCopy() *StoreShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreTier) DeepCopyInto(out *StoreTier) {
+	*out = *in
+	if in.MinTime != nil {
+		in, out := &in.MinTime, &out.MinTime
+		*out = new(TimeOrDuration)
+		**out = **in
+	}
+	if in.MaxTime != nil {
+		in, out := &in.MaxTime, &out.MaxTime
+		*out = new(TimeOrDuration)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageSize != nil {
+		in, out := &in.StorageSize, &out.StorageSize
+		*out = new(StorageSize)
+		**out = **in
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.IndexCacheConfig != nil {
+		in, out := &in.IndexCacheConfig, &out.IndexCacheConfig
+		*out = new(CacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CachingBucketConfig != nil {
+		in, out := &in.CachingBucketConfig, &out.CachingBucketConfig
+		*out = new(CacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StoreAPIServiceLabels != nil {
+		in, out := &in.StoreAPIServiceLabels, &out.StoreAPIServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreTier.
+func (in *StoreTier) DeepCopy() *StoreTier {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TSDBConfig) DeepCopyInto(out *TSDBConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TSDBConfig.
+func (in *TSDBConfig) DeepCopy() *TSDBConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TSDBConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantIngress) DeepCopyInto(out *TenantIngress) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.TLSSecretName != nil {
+		in, out := &in.TLSSecretName, &out.TLSSecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.BasicAuthSecret != nil {
+		in, out := &in.BasicAuthSecret, &out.BasicAuthSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantIngress.
+func (in *TenantIngress) DeepCopy() *TenantIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantLimits) DeepCopyInto(out *TenantLimits) {
+	*out = *in
+	if in.SizeBytesLimit != nil {
+		in, out := &in.SizeBytesLimit, &out.SizeBytesLimit
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SeriesLimit != nil {
+		in, out := &in.SeriesLimit, &out.SeriesLimit
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SamplesLimit != nil {
+		in, out := &in.SamplesLimit, &out.SamplesLimit
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ActiveSeriesLimit != nil {
+		in, out := &in.ActiveSeriesLimit, &out.ActiveSeriesLimit
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantLimits.
+func (in *TenantLimits) DeepCopy() *TenantLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantPrometheusRemoteWrite) DeepCopyInto(out *TenantPrometheusRemoteWrite) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BasicAuthSecret != nil {
+		in, out := &in.BasicAuthSecret, &out.BasicAuthSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantPrometheusRemoteWrite.
+func (in *TenantPrometheusRemoteWrite) DeepCopy() *TenantPrometheusRemoteWrite {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantPrometheusRemoteWrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosCompact) DeepCopyInto(out *ThanosCompact) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosCompact.
+func (in *ThanosCompact) DeepCopy() *ThanosCompact {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosCompact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ThanosCompact) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosCompactList) DeepCopyInto(out *ThanosCompactList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ThanosCompact, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosCompactList.
+func (in *ThanosCompactList) DeepCopy() *ThanosCompactList {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosCompactList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ThanosCompactList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosCompactSpec) DeepCopyInto(out *ThanosCompactSpec) {
+	*out = *in
+	in.CommonFields.DeepCopyInto(&out.CommonFields)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.ObjectStorageConfig.DeepCopyInto(&out.ObjectStorageConfig)
+	out.RetentionConfig = in.RetentionConfig
+	if in.BlockConfig != nil {
+		in, out := &in.BlockConfig, &out.BlockConfig
+		*out = new(BlockConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ShardingConfig != nil {
+		in, out := &in.ShardingConfig, &out.ShardingConfig
+		*out = new(ShardingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CompactConfig != nil {
+		in, out := &in.CompactConfig, &out.CompactConfig
+		*out = new(CompactConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DownsamplingConfig != nil {
+		in, out := &in.DownsamplingConfig, &out.DownsamplingConfig
+		*out = new(DownsamplingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinTime != nil {
+		in, out := &in.MinTime, &out.MinTime
+		*out = new(TimeOrDuration)
+		**out = **in
+	}
+	if in.MaxTime != nil {
+		in, out := &in.MaxTime, &out.MaxTime
+		*out = new(TimeOrDuration)
+		**out = **in
+	}
+	if in.BlockMarkers != nil {
+		in, out := &in.BlockMarkers, &out.BlockMarkers
+		*out = make([]BlockMarker, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(CompactSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = new(FeatureGates)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Additional.DeepCopyInto(&out.Additional)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosCompactSpec.
+func (in *ThanosCompactSpec) DeepCopy() *ThanosCompactSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosCompactSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosCompactStatus) DeepCopyInto(out *ThanosCompactStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(CompactScheduleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlockMarkers != nil {
+		in, out := &in.BlockMarkers, &out.BlockMarkers
+		*out = make([]BlockMarkerStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(EffectiveDefaults)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosCompactStatus.
+func (in *ThanosCompactStatus) DeepCopy() *ThanosCompactStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosCompactStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosQuery) DeepCopyInto(out *ThanosQuery) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuery.
+func (in *ThanosQuery) DeepCopy() *ThanosQuery {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosQuery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ThanosQuery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosQueryList) DeepCopyInto(out *ThanosQueryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ThanosQuery, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQueryList.
+func (in *ThanosQueryList) DeepCopy() *ThanosQueryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosQueryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ThanosQueryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosQuerySpec) DeepCopyInto(out *ThanosQuerySpec) {
+	*out = *in
+	in.CommonFields.DeepCopyInto(&out.CommonFields)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ReplicaLabels != nil {
+		in, out := &in.ReplicaLabels, &out.ReplicaLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.LookbackDelta != nil {
+		in, out := &in.LookbackDelta, &out.LookbackDelta
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.MaxConcurrent != nil {
+		in, out := &in.MaxConcurrent, &out.MaxConcurrent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodDisruptionConfig != nil {
+		in, out := &in.PodDisruptionConfig, &out.PodDisruptionConfig
+		*out = new(PodDisruptionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoscalingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StoreLabelSelector != nil {
+		in, out := &in.StoreLabelSelector, &out.StoreLabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StoreDiscoveryLabels != nil {
+		in, out := &in.StoreDiscoveryLabels, &out.StoreDiscoveryLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EndpointTypeOverrides != nil {
+		in, out := &in.EndpointTypeOverrides, &out.EndpointTypeOverrides
+		*out = make([]EndpointTypeOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CustomStoreEndpoints != nil {
+		in, out := &in.CustomStoreEndpoints, &out.CustomStoreEndpoints
+		*out = make([]CustomStoreEndpoint, len(*in))
+		copy(*out, *in)
+	}
+	if in.EndpointGroups != nil {
+		in, out := &in.EndpointGroups, &out.EndpointGroups
+		*out = make([]EndpointGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QueryPools != nil {
+		in, out := &in.QueryPools, &out.QueryPools
+		*out = make([]QueryPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RequestLoggingConfig != nil {
+		in, out := &in.RequestLoggingConfig, &out.RequestLoggingConfig
+		*out = new(RequestLoggingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogForwarding != nil {
+		in, out := &in.LogForwarding, &out.LogForwarding
+		*out = new(LogForwarding)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPCServerTLS != nil {
+		in, out := &in.GRPCServerTLS, &out.GRPCServerTLS
+		*out = new(GRPCServerTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPCServer != nil {
+		in, out := &in.GRPCServer, &out.GRPCServer
+		*out = new(GRPCServerConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPCClientTLS != nil {
+		in, out := &in.GRPCClientTLS, &out.GRPCClientTLS
+		*out = new(GRPCClientTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueryFrontend != nil {
+		in, out := &in.QueryFrontend, &out.QueryFrontend
+		*out = new(QueryFrontendSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GrafanaDatasource != nil {
+		in, out := &in.GrafanaDatasource, &out.GrafanaDatasource
+		*out = new(GrafanaDatasourceSpec)
+		(*in).DeepCopyInto(*out)
 	}
-	in.Additional.DeepCopyInto(&out.Additional)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterSpec.
-func (in *RouterSpec) DeepCopy() *RouterSpec {
-	if in == nil {
-		return nil
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(RollbackSpec)
+		**out = **in
 	}
-	out := new(RouterSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceMonitorConfig) DeepCopyInto(out *ServiceMonitorConfig) {
-	*out = *in
-	if in.Enable != nil {
-		in, out := &in.Enable, &out.Enable
+	if in.StackIngress != nil {
+		in, out := &in.StackIngress, &out.StackIngress
+		*out = new(StackIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TimeSplit != nil {
+		in, out := &in.TimeSplit, &out.TimeSplit
+		*out = new(TimeSplitSpec)
+		**out = **in
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
 		*out = new(bool)
 		**out = **in
 	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = new(FeatureGates)
+		(*in).DeepCopyInto(*out)
 	}
+	in.Additional.DeepCopyInto(&out.Additional)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMonitorConfig.
-func (in *ServiceMonitorConfig) DeepCopy() *ServiceMonitorConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuerySpec.
+func (in *ThanosQuerySpec) DeepCopy() *ThanosQuerySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceMonitorConfig)
+	out := new(ThanosQuerySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ShardingConfig) DeepCopyInto(out *ShardingConfig) {
+func (in *ThanosQueryStatus) DeepCopyInto(out *ThanosQueryStatus) {
 	*out = *in
-	if in.ExternalLabelSharding != nil {
-		in, out := &in.ExternalLabelSharding, &out.ExternalLabelSharding
-		*out = make([]ExternalLabelShardingConfig, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShardingConfig.
-func (in *ShardingConfig) DeepCopy() *ShardingConfig {
-	if in == nil {
-		return nil
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]EndpointStatus, len(*in))
+		copy(*out, *in)
 	}
-	out := new(ShardingConfig)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ShardingStrategy) DeepCopyInto(out *ShardingStrategy) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShardingStrategy.
-func (in *ShardingStrategy) DeepCopy() *ShardingStrategy {
-	if in == nil {
-		return nil
+	if in.Stack != nil {
+		in, out := &in.Stack, &out.Stack
+		*out = make([]StackComponentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QueryFrontend != nil {
+		in, out := &in.QueryFrontend, &out.QueryFrontend
+		*out = new(QueryFrontendStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(EffectiveDefaults)
+		**out = **in
 	}
-	out := new(ShardingStrategy)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TSDBConfig) DeepCopyInto(out *TSDBConfig) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TSDBConfig.
-func (in *TSDBConfig) DeepCopy() *TSDBConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQueryStatus.
+func (in *ThanosQueryStatus) DeepCopy() *ThanosQueryStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TSDBConfig)
+	out := new(ThanosQueryStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosCompact) DeepCopyInto(out *ThanosCompact) {
+func (in *ThanosReceive) DeepCopyInto(out *ThanosReceive) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -609,18 +2712,18 @@ func (in *ThanosCompact) DeepCopyInto(out *ThanosCompact) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosCompact.
-func (in *ThanosCompact) DeepCopy() *ThanosCompact {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosReceive.
+func (in *ThanosReceive) DeepCopy() *ThanosReceive {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosCompact)
+	out := new(ThanosReceive)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosCompact) DeepCopyObject() runtime.Object {
+func (in *ThanosReceive) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -628,31 +2731,31 @@ func (in *ThanosCompact) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosCompactList) DeepCopyInto(out *ThanosCompactList) {
+func (in *ThanosReceiveList) DeepCopyInto(out *ThanosReceiveList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ThanosCompact, len(*in))
+		*out = make([]ThanosReceive, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosCompactList.
-func (in *ThanosCompactList) DeepCopy() *ThanosCompactList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosReceiveList.
+func (in *ThanosReceiveList) DeepCopy() *ThanosReceiveList {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosCompactList)
+	out := new(ThanosReceiveList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosCompactList) DeepCopyObject() runtime.Object {
+func (in *ThanosReceiveList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -660,48 +2763,10 @@ func (in *ThanosCompactList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosCompactSpec) DeepCopyInto(out *ThanosCompactSpec) {
+func (in *ThanosReceiveSpec) DeepCopyInto(out *ThanosReceiveSpec) {
 	*out = *in
-	in.CommonFields.DeepCopyInto(&out.CommonFields)
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	in.ObjectStorageConfig.DeepCopyInto(&out.ObjectStorageConfig)
-	out.RetentionConfig = in.RetentionConfig
-	if in.BlockConfig != nil {
-		in, out := &in.BlockConfig, &out.BlockConfig
-		*out = new(BlockConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ShardingConfig != nil {
-		in, out := &in.ShardingConfig, &out.ShardingConfig
-		*out = new(ShardingConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CompactConfig != nil {
-		in, out := &in.CompactConfig, &out.CompactConfig
-		*out = new(CompactConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.DownsamplingConfig != nil {
-		in, out := &in.DownsamplingConfig, &out.DownsamplingConfig
-		*out = new(DownsamplingConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.MinTime != nil {
-		in, out := &in.MinTime, &out.MinTime
-		*out = new(Duration)
-		**out = **in
-	}
-	if in.MaxTime != nil {
-		in, out := &in.MaxTime, &out.MaxTime
-		*out = new(Duration)
-		**out = **in
-	}
+	in.Router.DeepCopyInto(&out.Router)
+	in.Ingester.DeepCopyInto(&out.Ingester)
 	if in.Paused != nil {
 		in, out := &in.Paused, &out.Paused
 		*out = new(bool)
@@ -712,21 +2777,20 @@ func (in *ThanosCompactSpec) DeepCopyInto(out *ThanosCompactSpec) {
 		*out = new(FeatureGates)
 		(*in).DeepCopyInto(*out)
 	}
-	in.Additional.DeepCopyInto(&out.Additional)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosCompactSpec.
-func (in *ThanosCompactSpec) DeepCopy() *ThanosCompactSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosReceiveSpec.
+func (in *ThanosReceiveSpec) DeepCopy() *ThanosReceiveSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosCompactSpec)
+	out := new(ThanosReceiveSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosCompactStatus) DeepCopyInto(out *ThanosCompactStatus) {
+func (in *ThanosReceiveStatus) DeepCopyInto(out *ThanosReceiveStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -735,20 +2799,32 @@ func (in *ThanosCompactStatus) DeepCopyInto(out *ThanosCompactStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Hashrings != nil {
+		in, out := &in.Hashrings, &out.Hashrings
+		*out = make([]HashringStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(EffectiveDefaults)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosCompactStatus.
-func (in *ThanosCompactStatus) DeepCopy() *ThanosCompactStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosReceiveStatus.
+func (in *ThanosReceiveStatus) DeepCopy() *ThanosReceiveStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosCompactStatus)
+	out := new(ThanosReceiveStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosQuery) DeepCopyInto(out *ThanosQuery) {
+func (in *ThanosRuler) DeepCopyInto(out *ThanosRuler) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -756,18 +2832,18 @@ func (in *ThanosQuery) DeepCopyInto(out *ThanosQuery) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuery.
-func (in *ThanosQuery) DeepCopy() *ThanosQuery {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosRuler.
+func (in *ThanosRuler) DeepCopy() *ThanosRuler {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosQuery)
+	out := new(ThanosRuler)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosQuery) DeepCopyObject() runtime.Object {
+func (in *ThanosRuler) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -775,31 +2851,31 @@ func (in *ThanosQuery) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosQueryList) DeepCopyInto(out *ThanosQueryList) {
+func (in *ThanosRulerList) DeepCopyInto(out *ThanosRulerList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ThanosQuery, len(*in))
+		*out = make([]ThanosRuler, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQueryList.
-func (in *ThanosQueryList) DeepCopy() *ThanosQueryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosRulerList.
+func (in *ThanosRulerList) DeepCopy() *ThanosRulerList {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosQueryList)
+	out := new(ThanosRulerList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosQueryList) DeepCopyObject() runtime.Object {
+func (in *ThanosRulerList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -807,7 +2883,7 @@ func (in *ThanosQueryList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosQuerySpec) DeepCopyInto(out *ThanosQuerySpec) {
+func (in *ThanosRulerSpec) DeepCopyInto(out *ThanosRulerSpec) {
 	*out = *in
 	in.CommonFields.DeepCopyInto(&out.CommonFields)
 	if in.Labels != nil {
@@ -817,21 +2893,36 @@ func (in *ThanosQuerySpec) DeepCopyInto(out *ThanosQuerySpec) {
 			(*out)[key] = val
 		}
 	}
-	if in.ReplicaLabels != nil {
-		in, out := &in.ReplicaLabels, &out.ReplicaLabels
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.StoreAPIServiceLabels != nil {
+		in, out := &in.StoreAPIServiceLabels, &out.StoreAPIServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.StoreLabelSelector != nil {
-		in, out := &in.StoreLabelSelector, &out.StoreLabelSelector
+	if in.QueryLabelSelector != nil {
+		in, out := &in.QueryLabelSelector, &out.QueryLabelSelector
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.QueryFrontend != nil {
-		in, out := &in.QueryFrontend, &out.QueryFrontend
-		*out = new(QueryFrontendSpec)
+	in.ObjectStorageConfig.DeepCopyInto(&out.ObjectStorageConfig)
+	if in.RuleConfigSelector != nil {
+		in, out := &in.RuleConfigSelector, &out.RuleConfigSelector
+		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExternalLabels != nil {
+		in, out := &in.ExternalLabels, &out.ExternalLabels
+		*out = make(ExternalLabels, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AlertLabelDrop != nil {
+		in, out := &in.AlertLabelDrop, &out.AlertLabelDrop
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Paused != nil {
 		in, out := &in.Paused, &out.Paused
 		*out = new(bool)
@@ -842,21 +2933,32 @@ func (in *ThanosQuerySpec) DeepCopyInto(out *ThanosQuerySpec) {
 		*out = new(FeatureGates)
 		(*in).DeepCopyInto(*out)
 	}
+	in.PrometheusRuleSelector.DeepCopyInto(&out.PrometheusRuleSelector)
+	if in.RuleNamespaceSelector != nil {
+		in, out := &in.RuleNamespaceSelector, &out.RuleNamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RuleNamespaceDenyList != nil {
+		in, out := &in.RuleNamespaceDenyList, &out.RuleNamespaceDenyList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.Additional.DeepCopyInto(&out.Additional)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuerySpec.
-func (in *ThanosQuerySpec) DeepCopy() *ThanosQuerySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosRulerSpec.
+func (in *ThanosRulerSpec) DeepCopy() *ThanosRulerSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosQuerySpec)
+	out := new(ThanosRulerSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosQueryStatus) DeepCopyInto(out *ThanosQueryStatus) {
+func (in *ThanosRulerStatus) DeepCopyInto(out *ThanosRulerStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -865,20 +2967,25 @@ func (in *ThanosQueryStatus) DeepCopyInto(out *ThanosQueryStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(EffectiveDefaults)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQueryStatus.
-func (in *ThanosQueryStatus) DeepCopy() *ThanosQueryStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosRulerStatus.
+func (in *ThanosRulerStatus) DeepCopy() *ThanosRulerStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosQueryStatus)
+	out := new(ThanosRulerStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosReceive) DeepCopyInto(out *ThanosReceive) {
+func (in *ThanosStore) DeepCopyInto(out *ThanosStore) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -886,18 +2993,18 @@ func (in *ThanosReceive) DeepCopyInto(out *ThanosReceive) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosReceive.
-func (in *ThanosReceive) DeepCopy() *ThanosReceive {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosStore.
+func (in *ThanosStore) DeepCopy() *ThanosStore {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosReceive)
+	out := new(ThanosStore)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosReceive) DeepCopyObject() runtime.Object {
+func (in *ThanosStore) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -905,31 +3012,31 @@ func (in *ThanosReceive) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosReceiveList) DeepCopyInto(out *ThanosReceiveList) {
+func (in *ThanosStoreList) DeepCopyInto(out *ThanosStoreList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ThanosReceive, len(*in))
+		*out = make([]ThanosStore, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosReceiveList.
-func (in *ThanosReceiveList) DeepCopy() *ThanosReceiveList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosStoreList.
+func (in *ThanosStoreList) DeepCopy() *ThanosStoreList {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosReceiveList)
+	out := new(ThanosStoreList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosReceiveList) DeepCopyObject() runtime.Object {
+func (in *ThanosStoreList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -937,10 +3044,110 @@ func (in *ThanosReceiveList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosReceiveSpec) DeepCopyInto(out *ThanosReceiveSpec) {
+func (in *ThanosStoreSpec) DeepCopyInto(out *ThanosStoreSpec) {
 	*out = *in
-	in.Router.DeepCopyInto(&out.Router)
-	in.Ingester.DeepCopyInto(&out.Ingester)
+	in.CommonFields.DeepCopyInto(&out.CommonFields)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StoreAPIServiceLabels != nil {
+		in, out := &in.StoreAPIServiceLabels, &out.StoreAPIServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ObjectStorageConfig != nil {
+		in, out := &in.ObjectStorageConfig, &out.ObjectStorageConfig
+		*out = new(ObjectStorageConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObjectStorage != nil {
+		in, out := &in.ObjectStorage, &out.ObjectStorage
+		*out = new(ObjectStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.VolumeClaimLabels != nil {
+		in, out := &in.VolumeClaimLabels, &out.VolumeClaimLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VolumeClaimAnnotations != nil {
+		in, out := &in.VolumeClaimAnnotations, &out.VolumeClaimAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PersistentVolumeClaimRetentionPolicy != nil {
+		in, out := &in.PersistentVolumeClaimRetentionPolicy, &out.PersistentVolumeClaimRetentionPolicy
+		*out = new(appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy)
+		**out = **in
+	}
+	if in.IndexCacheConfig != nil {
+		in, out := &in.IndexCacheConfig, &out.IndexCacheConfig
+		*out = new(CacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CachingBucketConfig != nil {
+		in, out := &in.CachingBucketConfig, &out.CachingBucketConfig
+		*out = new(CacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ShardingStrategy.DeepCopyInto(&out.ShardingStrategy)
+	if in.PodDisruptionConfig != nil {
+		in, out := &in.PodDisruptionConfig, &out.PodDisruptionConfig
+		*out = new(PodDisruptionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinTime != nil {
+		in, out := &in.MinTime, &out.MinTime
+		*out = new(TimeOrDuration)
+		**out = **in
+	}
+	if in.MaxTime != nil {
+		in, out := &in.MaxTime, &out.MaxTime
+		*out = new(TimeOrDuration)
+		**out = **in
+	}
+	if in.RequestLoggingConfig != nil {
+		in, out := &in.RequestLoggingConfig, &out.RequestLoggingConfig
+		*out = new(RequestLoggingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPCServerTLS != nil {
+		in, out := &in.GRPCServerTLS, &out.GRPCServerTLS
+		*out = new(GRPCServerTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPCServer != nil {
+		in, out := &in.GRPCServer, &out.GRPCServer
+		*out = new(GRPCServerConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tiers != nil {
+		in, out := &in.Tiers, &out.Tiers
+		*out = make([]StoreTier, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Paused != nil {
 		in, out := &in.Paused, &out.Paused
 		*out = new(bool)
@@ -951,20 +3158,21 @@ func (in *ThanosReceiveSpec) DeepCopyInto(out *ThanosReceiveSpec) {
 		*out = new(FeatureGates)
 		(*in).DeepCopyInto(*out)
 	}
+	in.Additional.DeepCopyInto(&out.Additional)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosReceiveSpec.
-func (in *ThanosReceiveSpec) DeepCopy() *ThanosReceiveSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosStoreSpec.
+func (in *ThanosStoreSpec) DeepCopy() *ThanosStoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosReceiveSpec)
+	out := new(ThanosStoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosReceiveStatus) DeepCopyInto(out *ThanosReceiveStatus) {
+func (in *ThanosStoreStatus) DeepCopyInto(out *ThanosStoreStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -973,20 +3181,30 @@ func (in *ThanosReceiveStatus) DeepCopyInto(out *ThanosReceiveStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]StoreShardStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(EffectiveDefaults)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosReceiveStatus.
-func (in *ThanosReceiveStatus) DeepCopy() *ThanosReceiveStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosStoreStatus.
+func (in *ThanosStoreStatus) DeepCopy() *ThanosStoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosReceiveStatus)
+	out := new(ThanosStoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosRuler) DeepCopyInto(out *ThanosRuler) {
+func (in *ThanosTenant) DeepCopyInto(out *ThanosTenant) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -994,18 +3212,18 @@ func (in *ThanosRuler) DeepCopyInto(out *ThanosRuler) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosRuler.
-func (in *ThanosRuler) DeepCopy() *ThanosRuler {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosTenant.
+func (in *ThanosTenant) DeepCopy() *ThanosTenant {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosRuler)
+	out := new(ThanosTenant)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosRuler) DeepCopyObject() runtime.Object {
+func (in *ThanosTenant) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1013,31 +3231,31 @@ func (in *ThanosRuler) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosRulerList) DeepCopyInto(out *ThanosRulerList) {
+func (in *ThanosTenantList) DeepCopyInto(out *ThanosTenantList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ThanosRuler, len(*in))
+		*out = make([]ThanosTenant, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosRulerList.
-func (in *ThanosRulerList) DeepCopy() *ThanosRulerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosTenantList.
+func (in *ThanosTenantList) DeepCopy() *ThanosTenantList {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosRulerList)
+	out := new(ThanosTenantList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosRulerList) DeepCopyObject() runtime.Object {
+func (in *ThanosTenantList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1045,65 +3263,47 @@ func (in *ThanosRulerList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosRulerSpec) DeepCopyInto(out *ThanosRulerSpec) {
+func (in *ThanosTenantSpec) DeepCopyInto(out *ThanosTenantSpec) {
 	*out = *in
-	in.CommonFields.DeepCopyInto(&out.CommonFields)
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.TenantID != nil {
+		in, out := &in.TenantID, &out.TenantID
+		*out = new(string)
+		**out = **in
 	}
-	if in.QueryLabelSelector != nil {
-		in, out := &in.QueryLabelSelector, &out.QueryLabelSelector
-		*out = new(v1.LabelSelector)
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(TenantLimits)
 		(*in).DeepCopyInto(*out)
 	}
-	in.ObjectStorageConfig.DeepCopyInto(&out.ObjectStorageConfig)
-	if in.RuleConfigSelector != nil {
-		in, out := &in.RuleConfigSelector, &out.RuleConfigSelector
-		*out = new(v1.LabelSelector)
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(TenantIngress)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.ExternalLabels != nil {
-		in, out := &in.ExternalLabels, &out.ExternalLabels
-		*out = make(ExternalLabels, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.AlertLabelDrop != nil {
-		in, out := &in.AlertLabelDrop, &out.AlertLabelDrop
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.PrometheusRemoteWrite != nil {
+		in, out := &in.PrometheusRemoteWrite, &out.PrometheusRemoteWrite
+		*out = new(TenantPrometheusRemoteWrite)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Paused != nil {
 		in, out := &in.Paused, &out.Paused
 		*out = new(bool)
 		**out = **in
 	}
-	if in.FeatureGates != nil {
-		in, out := &in.FeatureGates, &out.FeatureGates
-		*out = new(FeatureGates)
-		(*in).DeepCopyInto(*out)
-	}
-	in.PrometheusRuleSelector.DeepCopyInto(&out.PrometheusRuleSelector)
-	in.Additional.DeepCopyInto(&out.Additional)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosRulerSpec.
-func (in *ThanosRulerSpec) DeepCopy() *ThanosRulerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosTenantSpec.
+func (in *ThanosTenantSpec) DeepCopy() *ThanosTenantSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosRulerSpec)
+	out := new(ThanosTenantSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosRulerStatus) DeepCopyInto(out *ThanosRulerStatus) {
+func (in *ThanosTenantStatus) DeepCopyInto(out *ThanosTenantStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1112,20 +3312,25 @@ func (in *ThanosRulerStatus) DeepCopyInto(out *ThanosRulerStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PublishedNamespaces != nil {
+		in, out := &in.PublishedNamespaces, &out.PublishedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosRulerStatus.
-func (in *ThanosRulerStatus) DeepCopy() *ThanosRulerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosTenantStatus.
+func (in *ThanosTenantStatus) DeepCopy() *ThanosTenantStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosRulerStatus)
+	out := new(ThanosTenantStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosStore) DeepCopyInto(out *ThanosStore) {
+func (in *ThanosTools) DeepCopyInto(out *ThanosTools) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1133,18 +3338,18 @@ func (in *ThanosStore) DeepCopyInto(out *ThanosStore) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosStore.
-func (in *ThanosStore) DeepCopy() *ThanosStore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosTools.
+func (in *ThanosTools) DeepCopy() *ThanosTools {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosStore)
+	out := new(ThanosTools)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosStore) DeepCopyObject() runtime.Object {
+func (in *ThanosTools) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1152,31 +3357,31 @@ func (in *ThanosStore) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosStoreList) DeepCopyInto(out *ThanosStoreList) {
+func (in *ThanosToolsList) DeepCopyInto(out *ThanosToolsList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ThanosStore, len(*in))
+		*out = make([]ThanosTools, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosStoreList.
-func (in *ThanosStoreList) DeepCopy() *ThanosStoreList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosToolsList.
+func (in *ThanosToolsList) DeepCopy() *ThanosToolsList {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosStoreList)
+	out := new(ThanosToolsList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ThanosStoreList) DeepCopyObject() runtime.Object {
+func (in *ThanosToolsList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1184,7 +3389,7 @@ func (in *ThanosStoreList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosStoreSpec) DeepCopyInto(out *ThanosStoreSpec) {
+func (in *ThanosToolsSpec) DeepCopyInto(out *ThanosToolsSpec) {
 	*out = *in
 	in.CommonFields.DeepCopyInto(&out.CommonFields)
 	if in.Labels != nil {
@@ -1194,26 +3399,34 @@ func (in *ThanosStoreSpec) DeepCopyInto(out *ThanosStoreSpec) {
 			(*out)[key] = val
 		}
 	}
-	in.ObjectStorageConfig.DeepCopyInto(&out.ObjectStorageConfig)
-	if in.IndexCacheConfig != nil {
-		in, out := &in.IndexCacheConfig, &out.IndexCacheConfig
-		*out = new(CacheConfig)
+	if in.ObjectStorageConfig != nil {
+		in, out := &in.ObjectStorageConfig, &out.ObjectStorageConfig
+		*out = new(ObjectStorageConfig)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.CachingBucketConfig != nil {
-		in, out := &in.CachingBucketConfig, &out.CachingBucketConfig
-		*out = new(CacheConfig)
+	if in.ObjectStorageRef != nil {
+		in, out := &in.ObjectStorageRef, &out.ObjectStorageRef
+		*out = new(ObjectStorageReference)
+		**out = **in
+	}
+	if in.Mark != nil {
+		in, out := &in.Mark, &out.Mark
+		*out = new(MarkOperation)
 		(*in).DeepCopyInto(*out)
 	}
-	out.ShardingStrategy = in.ShardingStrategy
-	if in.MinTime != nil {
-		in, out := &in.MinTime, &out.MinTime
-		*out = new(Duration)
-		**out = **in
+	if in.Rewrite != nil {
+		in, out := &in.Rewrite, &out.Rewrite
+		*out = new(RewriteOperation)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.MaxTime != nil {
-		in, out := &in.MaxTime, &out.MaxTime
-		*out = new(Duration)
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(RetentionOperation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
 		**out = **in
 	}
 	if in.Paused != nil {
@@ -1221,26 +3434,21 @@ func (in *ThanosStoreSpec) DeepCopyInto(out *ThanosStoreSpec) {
 		*out = new(bool)
 		**out = **in
 	}
-	if in.FeatureGates != nil {
-		in, out := &in.FeatureGates, &out.FeatureGates
-		*out = new(FeatureGates)
-		(*in).DeepCopyInto(*out)
-	}
 	in.Additional.DeepCopyInto(&out.Additional)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosStoreSpec.
-func (in *ThanosStoreSpec) DeepCopy() *ThanosStoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosToolsSpec.
+func (in *ThanosToolsSpec) DeepCopy() *ThanosToolsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosStoreSpec)
+	out := new(ThanosToolsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosStoreStatus) DeepCopyInto(out *ThanosStoreStatus) {
+func (in *ThanosToolsStatus) DeepCopyInto(out *ThanosToolsStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1249,14 +3457,57 @@ func (in *ThanosStoreStatus) DeepCopyInto(out *ThanosStoreStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosStoreStatus.
-func (in *ThanosStoreStatus) DeepCopy() *ThanosStoreStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosToolsStatus.
+func (in *ThanosToolsStatus) DeepCopy() *ThanosToolsStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosStoreStatus)
+	out := new(ThanosToolsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeSplitSpec) DeepCopyInto(out *TimeSplitSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeSplitSpec.
+func (in *TimeSplitSpec) DeepCopy() *TimeSplitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeSplitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadIdentity) DeepCopyInto(out *WorkloadIdentity) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadIdentity.
+func (in *WorkloadIdentity) DeepCopy() *WorkloadIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadIdentity)
 	in.DeepCopyInto(out)
 	return out
 }
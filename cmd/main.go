@@ -23,6 +23,10 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"github.com/prometheus/client_golang/prometheus"
@@ -30,21 +34,35 @@ import (
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
 	"github.com/thanos-community/thanos-operator/internal/controller"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestscompact "github.com/thanos-community/thanos-operator/internal/pkg/manifests/compact"
-	manifestquery "github.com/thanos-community/thanos-operator/internal/pkg/manifests/query"
-	manifestreceive "github.com/thanos-community/thanos-operator/internal/pkg/manifests/receive"
-	manifestruler "github.com/thanos-community/thanos-operator/internal/pkg/manifests/ruler"
-	manifestsstore "github.com/thanos-community/thanos-operator/internal/pkg/manifests/store"
+	"github.com/thanos-community/thanos-operator/internal/pkg/imagepolicy"
+	"github.com/thanos-community/thanos-operator/internal/pkg/logsampling"
+	"github.com/thanos-community/thanos-operator/internal/pkg/maintenance"
+	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
+	"github.com/thanos-community/thanos-operator/internal/pkg/uninstall"
+	"github.com/thanos-community/thanos-operator/internal/pkg/versionpolicy"
+	webhookv1alpha1 "github.com/thanos-community/thanos-operator/internal/webhook/v1alpha1"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestscompact "github.com/thanos-community/thanos-operator/pkg/manifests/compact"
+	manifestquery "github.com/thanos-community/thanos-operator/pkg/manifests/query"
+	manifestreceive "github.com/thanos-community/thanos-operator/pkg/manifests/receive"
+	manifestruler "github.com/thanos-community/thanos-operator/pkg/manifests/ruler"
+	manifestsstore "github.com/thanos-community/thanos-operator/pkg/manifests/store"
+	manifeststenant "github.com/thanos-community/thanos-operator/pkg/manifests/tenant"
+	manifeststools "github.com/thanos-community/thanos-operator/pkg/manifests/tools"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -58,6 +76,17 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+// controllerNames are the names of the controllers, which are the names of the components they manage.
+var controllerNames = []string{
+	manifestquery.Name,
+	manifestreceive.Name,
+	manifestsstore.Name,
+	manifestscompact.Name,
+	manifestruler.Name,
+	manifeststools.Name,
+	manifeststenant.Name,
+}
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
@@ -72,9 +101,46 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableWebhooks bool
 
 	var featureGatePrometheusOperator bool
 
+	var imagePolicyResolveDigests bool
+	var imagePolicyCosignKeys string
+
+	var versionPolicyAllowedVersions string
+	var versionPolicyStrict bool
+
+	var applyRetryBudget int
+	var applyWriteLimit int
+	var applyWriteLimitWindow time.Duration
+	var forbiddenProbeInterval time.Duration
+	var logSampleInterval time.Duration
+
+	var maxConcurrentReconciles int
+	var maxConcurrentReconcilesPerController string
+	var rateLimiterBaseDelay time.Duration
+	var rateLimiterMaxDelay time.Duration
+	var resyncPeriod time.Duration
+
+	var maintenanceNamespace string
+	var maintenanceCordonedNodes bool
+
+	var uninstallMode bool
+	var uninstallNamespace string
+	var uninstallSelector string
+	var uninstallRetainVolumes bool
+
+	var labelsPartOf string
+	var labelsManagedBy string
+	var labelsOwnerKey string
+	var labelsExtra string
+
+	var logForwarderImage string
+	var logForwarderRegistry string
+	var logForwarderRequests string
+	var logForwarderLimits string
+
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -84,8 +150,81 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the validating admission webhooks for ThanosQuery and ThanosStore are served. "+
+			"Requires a serving certificate in the webhook server certificate directory.")
 	flag.BoolVar(&featureGatePrometheusOperator, "feature-gate.enable-prometheus-operator-crds", true,
 		"If set, the operator will manage ServiceMonitors for components it deploys, and discover PrometheusRule objects to set on Thanos Ruler, from Prometheus Operator.")
+	flag.BoolVar(&imagePolicyResolveDigests, "image-policy.resolve-digests", false,
+		"If set, image tags of managed workloads are resolved to digests at reconcile time and workloads are pinned to the resolved digest.")
+	flag.StringVar(&imagePolicyCosignKeys, "image-policy.cosign-public-keys", "",
+		"Comma separated list of paths to PEM encoded cosign public keys. "+
+			"If set, images of managed workloads must be signed by one of the keys before they are rolled out. Implies image-policy.resolve-digests.")
+	flag.StringVar(&versionPolicyAllowedVersions, "version-policy.allowed-versions", "",
+		"Semicolon separated list of component=range entries restricting the Thanos versions resources may request per component, "+
+			"e.g. 'query=>=v0.35.0 <v0.38.0;*=>=v0.34.0'. Components are query, query-frontend, store, receive, ruler and compact, "+
+			"or * for all components without a range of their own. Resources requesting other versions are flagged with the VersionAllowed condition.")
+	flag.BoolVar(&versionPolicyStrict, "version-policy.strict", false,
+		"If set, resources requesting a version outside the allowed ranges are not reconciled.")
+	flag.IntVar(&applyRetryBudget, "apply-retry-budget", 5,
+		"Number of consecutive failures to apply an object, e.g. due to an admission webhook denying it, after which the object "+
+			"is not applied again until the spec of the owning resource changes, and the resource is marked as Blocked. Zero disables the budget.")
+	flag.IntVar(&applyWriteLimit, "apply-write-limit", 0,
+		"Maximum number of objects of a single resource created or updated per apply-write-limit.window. Further writes are deferred "+
+			"to the next window, which spreads large changes, e.g. to the arguments of many shards, over time. Zero disables the limit.")
+	flag.DurationVar(&applyWriteLimitWindow, "apply-write-limit.window", time.Minute,
+		"Window over which writes are counted against apply-write-limit.")
+	flag.DurationVar(&forbiddenProbeInterval, "forbidden-probe-interval", 5*time.Minute,
+		"Interval at which objects the operator lacks the RBAC permissions to apply, e.g. ServiceMonitors, are applied again "+
+			"to probe whether the permissions were granted. Until then, the owning resource is marked with the MissingPermissions "+
+			"condition instead of failing. Zero handles missing permissions as any other error.")
+	flag.DurationVar(&logSampleInterval, "log-sample-interval", time.Minute,
+		"Interval at which repetitive messages logged per managed object, e.g. that a resource is configured, are logged at most once. "+
+			"Suppressed occurrences are counted in the next message. Zero disables sampling.")
+	flag.IntVar(&maxConcurrentReconciles, "controller.max-concurrent-reconciles", 1,
+		"Maximum number of resources each controller reconciles concurrently.")
+	flag.StringVar(&maxConcurrentReconcilesPerController, "controller.max-concurrent-reconciles.per-controller", "",
+		"Comma separated list of controller=value entries overriding controller.max-concurrent-reconciles for the named controllers, "+
+			"e.g. 'thanos-store=4,thanos-query=2'. Controllers are "+strings.Join(controllerNames, ", ")+".")
+	flag.DurationVar(&rateLimiterBaseDelay, "controller.rate-limiter.base-delay", 5*time.Millisecond,
+		"Delay after which a resource which failed to reconcile is retried. The delay doubles with each consecutive failure, "+
+			"up to controller.rate-limiter.max-delay.")
+	flag.DurationVar(&rateLimiterMaxDelay, "controller.rate-limiter.max-delay", 1000*time.Second,
+		"Maximum delay after which a resource which failed to reconcile is retried.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Hour,
+		"Interval at which all watched objects are resynced, which reconciles all resources.")
+	flag.StringVar(&maintenanceNamespace, "maintenance.namespace", "",
+		"Namespace of the ConfigMaps labelled "+maintenance.WindowLabel+" declaring maintenance windows, during which disruptive "+
+			"rollouts are deferred. Empty disables maintenance windows.")
+	flag.BoolVar(&maintenanceCordonedNodes, "maintenance.cordoned-nodes", false,
+		"Defer disruptive rollouts while any node of the cluster is cordoned, e.g. while nodes are drained during a platform upgrade.")
+	flag.BoolVar(&uninstallMode, "uninstall", false,
+		"If set, the operator does not start. Instead, Thanos resources are removed along with their generated objects and Events, "+
+			"and finalizers of the operator are removed so that the resources do not wait for the operator. Exits once done.")
+	flag.StringVar(&uninstallNamespace, "uninstall.namespace", "",
+		"Namespace to remove Thanos resources from in uninstall mode. All namespaces if empty.")
+	flag.StringVar(&uninstallSelector, "uninstall.selector", "",
+		"Label selector restricting the Thanos resources removed in uninstall mode.")
+	flag.BoolVar(&uninstallRetainVolumes, "uninstall.retain-volumes", false,
+		"If set, PersistentVolumeClaims of removed StatefulSets are kept in uninstall mode.")
+	flag.StringVar(&labelsPartOf, "labels.part-of", manifests.DefaultPartOfLabel,
+		"Value of the app.kubernetes.io/part-of label set on managed resources and used to select them.")
+	flag.StringVar(&labelsManagedBy, "labels.managed-by", manifests.DefaultManagedByLabel,
+		"Value of the app.kubernetes.io/managed-by label set on managed resources and used to select them.")
+	flag.StringVar(&labelsOwnerKey, "labels.owner-key", manifests.OwnerLabel,
+		"Key of the label identifying the resource that owns a managed resource.")
+	flag.StringVar(&labelsExtra, "labels.extra", "",
+		"Comma separated list of key=value labels added to all managed resources. They are not used to select resources. "+
+			"Management labels are part of the immutable selectors of workloads, so changing them requires recreating existing workloads.")
+	flag.StringVar(&logForwarderImage, "log-forwarder.image", manifests.DefaultLogForwarderImage,
+		"Image of the log forwarding sidecar used by resources which do not set one. It must run Vector.")
+	flag.StringVar(&logForwarderRegistry, "log-forwarder.registry", "",
+		"Registry replacing the registry of the log forwarding sidecar image, e.g. a mirror in air-gapped environments.")
+	flag.StringVar(&logForwarderRequests, "log-forwarder.resources.requests", "",
+		"Comma separated list of resource=quantity resource requests of the log forwarding sidecar, e.g. cpu=10m,memory=64Mi.")
+	flag.StringVar(&logForwarderLimits, "log-forwarder.resources.limits", "",
+		"Comma separated list of resource=quantity resource limits of the log forwarding sidecar. "+
+			"Resources can override the resources of the sidecar with containerResources.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -94,6 +233,59 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	extraLabels, err := labels.ConvertSelectorToLabelsMap(labelsExtra)
+	if err != nil {
+		setupLog.Error(err, "invalid extra labels")
+		os.Exit(1)
+	}
+	if err := manifests.ConfigureManagementLabels(manifests.ManagementLabels{
+		PartOf:     labelsPartOf,
+		ManagedBy:  labelsManagedBy,
+		OwnerLabel: labelsOwnerKey,
+		Extra:      extraLabels,
+	}); err != nil {
+		setupLog.Error(err, "invalid management labels")
+		os.Exit(1)
+	}
+
+	logForwarderRequestList, err := parseResourceList(logForwarderRequests)
+	if err != nil {
+		setupLog.Error(err, "invalid log forwarder resource requests")
+		os.Exit(1)
+	}
+	logForwarderLimitList, err := parseResourceList(logForwarderLimits)
+	if err != nil {
+		setupLog.Error(err, "invalid log forwarder resource limits")
+		os.Exit(1)
+	}
+	if err := manifests.ConfigureLogForwarder(manifests.LogForwarderDefaults{
+		Image:     logForwarderImage,
+		Registry:  logForwarderRegistry,
+		Resources: corev1.ResourceRequirements{Requests: logForwarderRequestList, Limits: logForwarderLimitList},
+	}); err != nil {
+		setupLog.Error(err, "invalid log forwarder configuration")
+		os.Exit(1)
+	}
+
+	if applyWriteLimit > 0 && applyWriteLimitWindow <= 0 {
+		setupLog.Error(fmt.Errorf("window must be positive, got %s", applyWriteLimitWindow), "invalid apply write limit")
+		os.Exit(1)
+	}
+
+	concurrencyOverrides, err := parseMaxConcurrentReconciles(maxConcurrentReconcilesPerController)
+	if err != nil {
+		setupLog.Error(err, "invalid max concurrent reconciles per controller")
+		os.Exit(1)
+	}
+	if rateLimiterBaseDelay <= 0 || rateLimiterMaxDelay < rateLimiterBaseDelay {
+		setupLog.Error(fmt.Errorf("base delay must be positive and at most the max delay, got %s and %s", rateLimiterBaseDelay, rateLimiterMaxDelay), "invalid rate limiter")
+		os.Exit(1)
+	}
+
+	if uninstallMode {
+		os.Exit(runUninstall(uninstallNamespace, uninstallSelector, uninstallRetainVolumes))
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancelation and
@@ -128,6 +320,16 @@ func main() {
 				"/debug/pprof/trace":   http.HandlerFunc(pprof.Trace),
 			},
 		},
+		Cache: cache.Options{
+			SyncPeriod: &resyncPeriod,
+		},
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				// pods are only read to attach debug containers on demand, and secrets are only read to publish the
+				// Prometheus remote write credentials of tenants, do not cache all pods and secrets of the cluster
+				DisableFor: []client.Object{&corev1.Pod{}, &corev1.Secret{}},
+			},
+		},
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
@@ -153,19 +355,68 @@ func main() {
 		versioncollector.NewCollector("thanos_operator"),
 	)
 
+	var cosignKeys []string
+	if imagePolicyCosignKeys != "" {
+		cosignKeys = strings.Split(imagePolicyCosignKeys, ",")
+	}
+	imagePolicy, err := imagepolicy.NewPolicy(imagepolicy.Options{
+		ResolveDigests: imagePolicyResolveDigests,
+		PublicKeyFiles: cosignKeys,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create image policy")
+		os.Exit(1)
+	}
+
+	allowedVersions, err := versionpolicy.ParseRanges(versionPolicyAllowedVersions)
+	if err != nil {
+		setupLog.Error(err, "invalid allowed versions")
+		os.Exit(1)
+	}
+	versionPolicy, err := versionpolicy.NewPolicy(allowedVersions, versionPolicyStrict)
+	if err != nil {
+		setupLog.Error(err, "unable to create version policy")
+		os.Exit(1)
+	}
+
+	maintenanceDetector := maintenance.NewDetector(mgr.GetClient(), maintenance.Options{
+		Namespace:     maintenanceNamespace,
+		CordonedNodes: maintenanceCordonedNodes,
+	})
+
 	prometheus.DefaultRegisterer = ctrlmetrics.Registry
 	baseLogger := ctrl.Log.WithName(manifests.DefaultManagedByLabel)
+	logSampler := logsampling.NewSampler(logSampleInterval)
+	reconcileMetrics := controllermetrics.NewControllerBaseMetrics(ctrlmetrics.Registry)
 
 	buildConfig := func(component string) controller.Config {
+		concurrency := maxConcurrentReconciles
+		if n, ok := concurrencyOverrides[component]; ok {
+			concurrency = n
+		}
 		return controller.Config{
 			FeatureGate: controller.FeatureGate{
 				EnableServiceMonitor:          featureGatePrometheusOperator,
 				EnablePrometheusRuleDiscovery: featureGatePrometheusOperator,
 			},
 			InstrumentationConfig: controller.InstrumentationConfig{
-				Logger:          baseLogger.WithName(component),
-				EventRecorder:   mgr.GetEventRecorderFor(fmt.Sprintf("%s-controller", component)),
-				MetricsRegistry: ctrlmetrics.Registry,
+				Logger:           baseLogger.WithName(component),
+				EventRecorder:    mgr.GetEventRecorderFor(fmt.Sprintf("%s-controller", component)),
+				LogSampler:       logSampler,
+				ReconcileMetrics: reconcileMetrics.ForController(component),
+				MetricsRegistry:  ctrlmetrics.Registry,
+			},
+			ImagePolicy:            imagePolicy,
+			VersionPolicy:          versionPolicy,
+			Maintenance:            maintenanceDetector,
+			ApplyRetryBudget:       applyRetryBudget,
+			WriteLimit:             applyWriteLimit,
+			WriteLimitWindow:       applyWriteLimitWindow,
+			ForbiddenProbeInterval: forbiddenProbeInterval,
+			Controller: controller.ControllerConfig{
+				MaxConcurrentReconciles: concurrency,
+				RateLimiterBaseDelay:    rateLimiterBaseDelay,
+				RateLimiterMaxDelay:     rateLimiterMaxDelay,
 			},
 		}
 	}
@@ -215,6 +466,34 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = controller.NewThanosToolsReconciler(
+		buildConfig(manifeststools.Name),
+		mgr.GetClient(),
+		mgr.GetScheme(),
+	).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ThanosTools")
+		os.Exit(1)
+	}
+
+	if err = controller.NewThanosTenantReconciler(
+		buildConfig(manifeststenant.Name),
+		mgr.GetClient(),
+		mgr.GetScheme(),
+	).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ThanosTenant")
+		os.Exit(1)
+	}
+
+	if enableWebhooks {
+		if err = webhookv1alpha1.SetupThanosQueryWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ThanosQuery")
+			os.Exit(1)
+		}
+		if err = webhookv1alpha1.SetupThanosStoreWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ThanosStore")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -232,3 +511,71 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// parseMaxConcurrentReconciles parses a comma separated list of controller=value entries into the maximum number
+// of concurrent reconciles keyed by controller name.
+func parseMaxConcurrentReconciles(s string) (map[string]int, error) {
+	entries, err := labels.ConvertSelectorToLabelsMap(s)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]int, len(entries))
+	for name, v := range entries {
+		if !slices.Contains(controllerNames, name) {
+			return nil, fmt.Errorf("unknown controller %q, controllers are %s", name, strings.Join(controllerNames, ", "))
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid value %q for controller %s, must be a positive integer", v, name)
+		}
+		values[name] = n
+	}
+	return values, nil
+}
+
+// parseResourceList parses a comma separated list of resource=quantity entries.
+func parseResourceList(s string) (corev1.ResourceList, error) {
+	entries, err := labels.ConvertSelectorToLabelsMap(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	list := make(corev1.ResourceList, len(entries))
+	for name, v := range entries {
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for resource %s: %w", v, name, err)
+		}
+		list[corev1.ResourceName(name)] = q
+	}
+	return list, nil
+}
+
+// runUninstall removes the selected Thanos resources and returns the exit code of the process.
+func runUninstall(namespace, selector string, retainVolumes bool) int {
+	logger := ctrl.Log.WithName("uninstall")
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		logger.Error(err, "invalid selector")
+		return 1
+	}
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		logger.Error(err, "unable to create client")
+		return 1
+	}
+
+	summary, err := uninstall.New(c, logger, uninstall.Options{
+		Namespace:     namespace,
+		Selector:      sel,
+		RetainVolumes: retainVolumes,
+	}).Run(ctrl.SetupSignalHandler())
+	logger.Info("uninstall finished", "resources", summary.Resources, "volumeClaims", summary.VolumeClaims, "events", summary.Events)
+	if err != nil {
+		logger.Error(err, "failed to remove some resources")
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,375 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/handlers"
+	"github.com/thanos-community/thanos-operator/internal/pkg/imagepolicy"
+	"github.com/thanos-community/thanos-operator/internal/pkg/podhealth"
+	"github.com/thanos-community/thanos-operator/internal/pkg/rollback"
+	"github.com/thanos-community/thanos-operator/internal/pkg/versionpolicy"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonImagePolicyViolation     = "ImagePolicyViolation"
+	reasonApplyRetriesExhausted    = "ApplyRetriesExhausted"
+	reasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+	reasonAllEndpointsUp           = "AllEndpointsUp"
+	reasonEndpointsDown            = "EndpointsDown"
+	reasonNoEndpoints              = "NoEndpoints"
+	reasonQueryUnreachable         = "QueryUnreachable"
+	reasonReconcileSucceeded       = "ReconcileSucceeded"
+	reasonReconcileFailed          = "ReconcileFailed"
+	reasonInvalidSpec              = "InvalidSpec"
+	reasonRolloutDeferred          = "RolloutDeferred"
+	reasonApplyThrottled           = "ApplyThrottled"
+	reasonPaused                   = "Paused"
+	reasonNotPaused                = "NotPaused"
+	reasonMinimumReplicasAvailable = "MinimumReplicasAvailable"
+	reasonReplicasUnavailable      = "ReplicasUnavailable"
+	reasonAllReplicasReady         = "AllReplicasReady"
+	reasonWorkloadNotFound         = "WorkloadNotFound"
+	reasonAllShardsReady           = "AllShardsReady"
+	reasonShardsUnavailable        = "ShardsUnavailable"
+	reasonVersionsAllowed          = "VersionsAllowed"
+	reasonVersionNotAllowed        = "VersionNotAllowed"
+	reasonVersionRejected          = "VersionRejected"
+	reasonContainersCrashLooping   = "ContainersCrashLooping"
+	reasonNoCrashLoops             = "NoCrashLoops"
+	reasonInvalidObjectStorage     = "InvalidObjectStorageConfig"
+	reasonPodsHealthy              = "PodsHealthy"
+	reasonForbidden                = "Forbidden"
+	reasonMissingPermissions       = "MissingPermissions"
+)
+
+// errInvalidSpec is wrapped by reconcile errors which are caused by an invalid spec and are not retried.
+var errInvalidSpec = errors.New("invalid spec")
+
+// updateBlockedCondition reflects the outcome of a sync in the Blocked condition of the given resource.
+// The condition is set when the sync was blocked by the image policy or by an object which repeatedly failed to apply,
+// and removed once a sync succeeds.
+// Other sync errors leave the condition untouched. The status is only written if the condition changed.
+func updateBlockedCondition(ctx context.Context, c client.Client, obj client.Object, conditions *[]metav1.Condition, syncErr error) error {
+	var changed bool
+	switch {
+	case syncErr == nil:
+		changed = meta.RemoveStatusCondition(conditions, monitoringthanosiov1alpha1.ConditionBlocked)
+	case errors.Is(syncErr, imagepolicy.ErrBlocked):
+		changed = meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               monitoringthanosiov1alpha1.ConditionBlocked,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonImagePolicyViolation,
+			Message:            syncErr.Error(),
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	case errors.Is(syncErr, handlers.ErrApplyBlocked):
+		changed = meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               monitoringthanosiov1alpha1.ConditionBlocked,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonApplyRetriesExhausted,
+			Message:            syncErr.Error(),
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	}
+
+	if !changed {
+		return nil
+	}
+	return c.Status().Update(ctx, obj)
+}
+
+// updateMissingPermissionsCondition reflects the objects of the given resource the operator was not permitted to apply,
+// as reported by handlers.Handler.MissingPermissions, in the MissingPermissions condition of the resource.
+// The condition is removed once all objects were applied. The status is only written if the condition changed.
+func updateMissingPermissionsCondition(ctx context.Context, c client.Client, obj client.Object, conditions *[]metav1.Condition, permissionsErr error) error {
+	var changed bool
+	if permissionsErr == nil {
+		changed = meta.RemoveStatusCondition(conditions, monitoringthanosiov1alpha1.ConditionMissingPermissions)
+	} else {
+		changed = meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               monitoringthanosiov1alpha1.ConditionMissingPermissions,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonForbidden,
+			Message:            permissionsErr.Error(),
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	}
+
+	if !changed {
+		return nil
+	}
+	return c.Status().Update(ctx, obj)
+}
+
+// checkVersions checks the Thanos versions requested by a resource, keyed by component, against the version policy.
+// The outcome is reflected in the VersionAllowed condition of the resource, which is removed if no policy is configured,
+// and versions which are not allowed are recorded in a warning event. The status is only written if the condition changed.
+// It returns an error wrapping errInvalidSpec if a version is not allowed and the policy is strict,
+// in which case the resource must not be reconciled.
+func checkVersions(ctx context.Context, c client.Client, recorder record.EventRecorder, obj client.Object, conditions *[]metav1.Condition,
+	policy *versionpolicy.Policy, versions map[string][]string) error {
+	var changed bool
+	versionErr := policy.CheckAll(versions)
+	switch {
+	case policy == nil:
+		changed = meta.RemoveStatusCondition(conditions, monitoringthanosiov1alpha1.ConditionVersionAllowed)
+	case versionErr == nil:
+		changed = meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               monitoringthanosiov1alpha1.ConditionVersionAllowed,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonVersionsAllowed,
+			Message:            "All versions are within the allowed ranges",
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	default:
+		reason := reasonVersionNotAllowed
+		if policy.Strict() {
+			reason = reasonVersionRejected
+		}
+		message := strings.ReplaceAll(versionErr.Error(), "\n", "; ")
+		changed = meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               monitoringthanosiov1alpha1.ConditionVersionAllowed,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: obj.GetGeneration(),
+		})
+		recorder.Event(obj, corev1.EventTypeWarning, reason, message)
+	}
+
+	if changed {
+		if err := c.Status().Update(ctx, obj); err != nil {
+			return fmt.Errorf("failed to update version condition: %w", err)
+		}
+	}
+	if versionErr != nil && policy.Strict() {
+		return fmt.Errorf("%w: %w", errInvalidSpec, versionErr)
+	}
+	return nil
+}
+
+// checkOptions validates the options the workloads of a resource are built from, see manifests.Options.Validate.
+// Invalid options are reflected in the Degraded condition of the resource and recorded in a warning event.
+// The status is only written if the condition changed.
+// It returns an error wrapping errInvalidSpec if the options are invalid, in which case the resource must not be reconciled.
+// Valid options only clear a Degraded condition set by this check.
+func checkOptions(ctx context.Context, c client.Client, recorder record.EventRecorder, obj client.Object,
+	conditions *[]metav1.Condition, opts ...manifests.Options) error {
+	var changed bool
+	optionsErr := validateOptions(opts...)
+	if optionsErr == nil {
+		if degraded := meta.FindStatusCondition(*conditions, monitoringthanosiov1alpha1.ConditionDegraded); degraded != nil && degraded.Reason == reasonInvalidSpec {
+			changed = meta.RemoveStatusCondition(conditions, monitoringthanosiov1alpha1.ConditionDegraded)
+		}
+	} else {
+		message := strings.ReplaceAll(optionsErr.Error(), "\n", "; ")
+		changed = meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               monitoringthanosiov1alpha1.ConditionDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonInvalidSpec,
+			Message:            message,
+			ObservedGeneration: obj.GetGeneration(),
+		})
+		recorder.Event(obj, corev1.EventTypeWarning, reasonInvalidSpec, fmt.Sprintf("Invalid options: %s", message))
+	}
+
+	if changed {
+		if err := c.Status().Update(ctx, obj); err != nil {
+			return fmt.Errorf("failed to update options condition: %w", err)
+		}
+	}
+	if optionsErr != nil {
+		return fmt.Errorf("%w: %w", errInvalidSpec, optionsErr)
+	}
+	return nil
+}
+
+// updateDefaultsStatus reports the values deployed for the defaulted fields of the given options in the given
+// status field of a resource. The status is only written if the values changed.
+func updateDefaultsStatus(ctx context.Context, c client.Client, obj client.Object, status **monitoringthanosiov1alpha1.EffectiveDefaults, opts manifests.Options) error {
+	defaults := effectiveDefaultsFromOpts(opts)
+	if equality.Semantic.DeepEqual(*status, defaults) {
+		return nil
+	}
+	*status = defaults
+	return c.Status().Update(ctx, obj)
+}
+
+// isRolledBack returns true if the workloads of the given generation of a resource were rolled back.
+func isRolledBack(conditions []metav1.Condition, generation int64) bool {
+	c := meta.FindStatusCondition(conditions, monitoringthanosiov1alpha1.ConditionRolledBack)
+	return c != nil && c.Status == metav1.ConditionTrue && c.ObservedGeneration == generation
+}
+
+// setRolledBackCondition marks the given generation of a resource as rolled back.
+func setRolledBackCondition(conditions *[]metav1.Condition, generation int64, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ConditionRolledBack,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonProgressDeadlineExceeded,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setPausedCondition reports whether the reconciliation of the given generation of a resource is paused.
+func setPausedCondition(conditions *[]metav1.Condition, generation int64, paused bool) {
+	condition := metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ConditionPaused,
+		Status:             metav1.ConditionFalse,
+		Reason:             reasonNotPaused,
+		Message:            "Reconciliation is not paused",
+		ObservedGeneration: generation,
+	}
+	if paused {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonPaused
+		condition.Message = "Reconciliation is paused"
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// setReconciledCondition reports the outcome of the reconciliation of the given generation of a resource.
+func setReconciledCondition(conditions *[]metav1.Condition, generation int64, paused bool, reconcileErr error) {
+	condition := metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ConditionReconciled,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+	}
+	switch {
+	case paused:
+		condition.Reason = reasonPaused
+		condition.Message = "Reconciliation is paused"
+	case reconcileErr == nil:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonReconcileSucceeded
+		condition.Message = "All resources are reconciled"
+	case errors.Is(reconcileErr, errInvalidSpec):
+		condition.Reason = reasonInvalidSpec
+		condition.Message = reconcileErr.Error()
+	case errors.Is(reconcileErr, handlers.ErrRolloutDeferred):
+		condition.Reason = reasonRolloutDeferred
+		condition.Message = reconcileErr.Error()
+	case errors.Is(reconcileErr, handlers.ErrApplyThrottled):
+		condition.Reason = reasonApplyThrottled
+		condition.Message = reconcileErr.Error()
+	case errors.Is(reconcileErr, handlers.ErrMissingPermissions):
+		condition.Reason = reasonMissingPermissions
+		condition.Message = reconcileErr.Error()
+	case errors.Is(reconcileErr, imagepolicy.ErrBlocked):
+		condition.Reason = reasonImagePolicyViolation
+		condition.Message = reconcileErr.Error()
+	case errors.Is(reconcileErr, handlers.ErrApplyBlocked):
+		condition.Reason = reasonApplyRetriesExhausted
+		condition.Message = reconcileErr.Error()
+	default:
+		condition.Reason = reasonReconcileFailed
+		condition.Message = reconcileErr.Error()
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// setDeploymentConditions reports the Available and Degraded conditions of the given generation of a resource
+// from the status of its Deployment and the problems of its pods. A nil Deployment is reported as unavailable.
+// Pod problems take precedence over the status of the Deployment in the Degraded condition, as they name the cause.
+func setDeploymentConditions(conditions *[]metav1.Condition, generation int64, deployment *appsv1.Deployment, problems []podhealth.Problem) {
+	available := metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ConditionAvailable,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+	}
+	degraded := metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+	}
+
+	if deployment == nil {
+		available.Reason = reasonWorkloadNotFound
+		available.Message = "The Deployment does not exist"
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = reasonWorkloadNotFound
+		degraded.Message = available.Message
+		meta.SetStatusCondition(conditions, available)
+		meta.SetStatusCondition(conditions, degraded)
+		return
+	}
+
+	desired := ptr.Deref(deployment.Spec.Replicas, 1)
+	status := deployment.Status
+	if deploymentAvailable(deployment) {
+		available.Status = metav1.ConditionTrue
+		available.Reason = reasonMinimumReplicasAvailable
+		available.Message = fmt.Sprintf("%d of %d replicas are available", status.AvailableReplicas, desired)
+	} else {
+		available.Reason = reasonReplicasUnavailable
+		available.Message = fmt.Sprintf("%d of %d replicas are available", status.AvailableReplicas, desired)
+	}
+
+	switch {
+	case len(problems) > 0:
+		degraded = podsDegradedCondition(generation, problems)
+	case rollback.HasFailed(deployment):
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = reasonProgressDeadlineExceeded
+		degraded.Message = fmt.Sprintf("Rollout of Deployment %s exceeded its progress deadline", deployment.GetName())
+	case status.ReadyReplicas < desired:
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = reasonReplicasUnavailable
+		degraded.Message = fmt.Sprintf("%d of %d replicas are ready", status.ReadyReplicas, desired)
+	default:
+		degraded.Reason = reasonAllReplicasReady
+		degraded.Message = fmt.Sprintf("All %d replicas are ready", desired)
+	}
+	meta.SetStatusCondition(conditions, available)
+	meta.SetStatusCondition(conditions, degraded)
+}
+
+// deploymentAvailable returns true if the Deployment reports the minimum number of replicas as available.
+func deploymentAvailable(deployment *appsv1.Deployment) bool {
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return deployment.Status.AvailableReplicas > 0
+}
+
+// setShardsAvailableCondition reports the Available condition of the given generation of a resource
+// which is ready only when all of its shards are ready.
+func setShardsAvailableCondition(conditions *[]metav1.Condition, generation int64, shards []monitoringthanosiov1alpha1.StoreShardStatus) {
+	var unavailable []string
+	for _, shard := range shards {
+		if shard.ReadyReplicas < shard.Replicas || shard.Replicas == 0 {
+			unavailable = append(unavailable, fmt.Sprintf("%s (%d/%d ready)", shard.Name, shard.ReadyReplicas, shard.Replicas))
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ConditionAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonAllShardsReady,
+		Message:            fmt.Sprintf("All %d shards are ready", len(shards)),
+		ObservedGeneration: generation,
+	}
+	if len(unavailable) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonShardsUnavailable
+		condition.Message = fmt.Sprintf("%d of %d shards are not ready: %s", len(unavailable), len(shards), strings.Join(unavailable, ", "))
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
@@ -1,18 +1,98 @@
 package controller
 
 import (
+	"time"
+
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/thanos-community/thanos-operator/internal/pkg/imagepolicy"
+	"github.com/thanos-community/thanos-operator/internal/pkg/logsampling"
+	"github.com/thanos-community/thanos-operator/internal/pkg/maintenance"
+	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
+	"github.com/thanos-community/thanos-operator/internal/pkg/versionpolicy"
+
+	"golang.org/x/time/rate"
+
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// rolloutDeferredRequeueInterval is the interval after which a resource whose rollout was deferred is reconciled again.
+const rolloutDeferredRequeueInterval = 30 * time.Second
+
+// objectStoragePreflightRequeueInterval is the interval after which a resource whose object storage configuration
+// failed the pre-flight check is reconciled again, as changes to the Secrets of the configuration are not watched.
+const objectStoragePreflightRequeueInterval = time.Minute
+
+// endpointStatusInterval is the interval at which the health of the endpoints of a Querier is checked.
+const endpointStatusInterval = time.Minute
+
+// restoreRequeueInterval is the interval at which the progress of the restore of an ingester volume is checked.
+const restoreRequeueInterval = 10 * time.Second
+
+// endpointEventWindow is the window over which changes to the endpoints of a Querier are aggregated into events.
+const endpointEventWindow = time.Minute
+
 // Config holds the configuration for all controllers.
 type Config struct {
 	// FeatureGate holds information about enabled features.
 	FeatureGate FeatureGate
 	// InstrumentationConfig contains the common instrumentation configuration for all controllers.
 	InstrumentationConfig InstrumentationConfig
+	// ImagePolicy is applied to the images of managed workloads before they are rolled out.
+	// A nil ImagePolicy leaves images untouched.
+	ImagePolicy *imagepolicy.Policy
+	// VersionPolicy restricts the Thanos versions requested by resources to the ranges allowed per component.
+	// A nil VersionPolicy allows all versions.
+	VersionPolicy *versionpolicy.Policy
+	// Maintenance detects declared cluster maintenance, during which disruptive rollouts are deferred.
+	// A nil Maintenance never defers rollouts.
+	Maintenance *maintenance.Detector
+	// ApplyRetryBudget is the number of consecutive failures to apply an object after which the object is not applied
+	// again until the spec of the owning resource changes, and the resource is marked as Blocked.
+	// Zero disables the budget.
+	ApplyRetryBudget int
+	// WriteLimit is the maximum number of objects of a single resource created or updated per WriteLimitWindow.
+	// Further writes are deferred to the next window. Zero disables the limit.
+	WriteLimit int
+	// WriteLimitWindow is the window over which writes are counted against the WriteLimit.
+	WriteLimitWindow time.Duration
+	// ForbiddenProbeInterval is the interval at which objects the operator lacks the RBAC permissions to apply
+	// are applied again, to probe whether the permissions were granted. Until then, the resource is marked with
+	// the MissingPermissions condition. Zero handles missing permissions as any other error.
+	ForbiddenProbeInterval time.Duration
+	// Controller tunes the throughput of the controller.
+	Controller ControllerConfig
+}
+
+// ControllerConfig tunes the throughput of a controller.
+type ControllerConfig struct {
+	// MaxConcurrentReconciles is the maximum number of resources reconciled concurrently. Defaults to 1.
+	MaxConcurrentReconciles int
+	// RateLimiterBaseDelay is the delay after which a resource which failed to reconcile is retried.
+	// The delay doubles with each consecutive failure, up to RateLimiterMaxDelay.
+	// If either delay is zero, the default rate limiter of controller-runtime is used.
+	RateLimiterBaseDelay time.Duration
+	// RateLimiterMaxDelay is the maximum delay after which a resource which failed to reconcile is retried.
+	RateLimiterMaxDelay time.Duration
+}
+
+// options returns the options of a controller tuned by the ControllerConfig.
+func (c ControllerConfig) options() crcontroller.Options {
+	opts := crcontroller.Options{MaxConcurrentReconciles: c.MaxConcurrentReconciles}
+	if c.RateLimiterBaseDelay > 0 && c.RateLimiterMaxDelay > 0 {
+		opts.RateLimiter = workqueue.NewTypedMaxOfRateLimiter(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](c.RateLimiterBaseDelay, c.RateLimiterMaxDelay),
+			// the overall rate limit of the default rate limiter of controller-runtime
+			&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+		)
+	}
+	return opts
 }
 
 // FeatureGate holds information about enabled features.
@@ -49,6 +129,12 @@ func (fg FeatureGate) ToGVK() []schema.GroupVersionKind {
 type InstrumentationConfig struct {
 	Logger        logr.Logger
 	EventRecorder record.EventRecorder
+	// LogSampler rate limits the messages logged per managed object, which repeat on every reconciliation.
+	// A nil LogSampler logs every message.
+	LogSampler *logsampling.Sampler
+	// ReconcileMetrics observe the duration of the reconciliations of the controller and of their phases.
+	// Nil ReconcileMetrics are not observed.
+	ReconcileMetrics *controllermetrics.ReconcileMetrics
 
 	MetricsRegistry prometheus.Registerer
 }
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/crashes"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxReportedCrashes is the maximum number of crash looping containers described in the CrashLooping condition.
+const maxReportedCrashes = 3
+
+//+kubebuilder:rbac:groups="",resources=pods,verbs=list
+
+// ownedPods returns the pods of the Deployments and StatefulSets controlled by obj.
+// The pods carrying the owner label are narrowed down to those matching the selectors of these workloads,
+// as the owner label only holds the name of obj and is shared by the pods of same-named resources of other kinds.
+func ownedPods(ctx context.Context, c client.Client, obj client.Object) ([]corev1.Pod, error) {
+	var selectors []labels.Selector
+	addSelector := func(workload client.Object, s *metav1.LabelSelector) {
+		if !metav1.IsControlledBy(workload, obj) {
+			return
+		}
+		if selector, err := metav1.LabelSelectorAsSelector(s); err == nil && !selector.Empty() {
+			selectors = append(selectors, selector)
+		}
+	}
+	deployments := &appsv1.DeploymentList{}
+	if err := c.List(ctx, deployments, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		addSelector(&d, d.Spec.Selector)
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := c.List(ctx, statefulSets, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, sts := range statefulSets.Items {
+		addSelector(&sts, sts.Spec.Selector)
+	}
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+
+	list := &corev1.PodList{}
+	if err := c.List(ctx, list, client.InNamespace(obj.GetNamespace()),
+		client.MatchingLabels{manifests.OwnerLabel: manifests.ValidateAndSanitizeNameToValidLabelValue(obj.GetName())}); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	var pods []corev1.Pod
+	for _, pod := range list.Items {
+		for _, selector := range selectors {
+			if selector.Matches(labels.Set(pod.GetLabels())) {
+				pods = append(pods, pod)
+				break
+			}
+		}
+	}
+	return pods, nil
+}
+
+// updateCrashLoopingCondition reports the crash looping containers of the pods of a resource in its CrashLooping condition,
+// together with the exit code and termination message of their last crash. Whenever the reported crashes change,
+// they are also recorded in a warning event. The status is only written if the condition changed.
+func updateCrashLoopingCondition(ctx context.Context, c client.Client, recorder record.EventRecorder, obj client.Object, conditions *[]metav1.Condition) error {
+	pods, err := ownedPods(ctx, c, obj)
+	if err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ConditionCrashLooping,
+		Status:             metav1.ConditionFalse,
+		Reason:             reasonNoCrashLoops,
+		Message:            "No containers are crash looping",
+		ObservedGeneration: obj.GetGeneration(),
+	}
+	found := crashes.Find(pods)
+	if len(found) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonContainersCrashLooping
+		condition.Message = crashes.Summarize(found, maxReportedCrashes)
+	}
+
+	if !meta.SetStatusCondition(conditions, condition) {
+		return nil
+	}
+	if len(found) > 0 {
+		recorder.Event(obj, corev1.EventTypeWarning, reasonContainersCrashLooping, condition.Message)
+	}
+	if err := c.Status().Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to update crash looping condition: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// debugContainerName is the name of the ephemeral container attached to the pod selected by the DebugPodAnnotation.
+	debugContainerName = "thanos-debug"
+	// objStoreEnvVarName is the environment variable holding the object storage configuration of Thanos containers.
+	objStoreEnvVarName = "OBJSTORE_CONFIG"
+)
+
+// attachDebugContainer attaches an ephemeral debug container to the pod of the owner selected by its DebugPodAnnotation.
+// It returns the name of the pod the container was attached to, or an empty string if no pod is selected
+// or the container is already attached.
+func attachDebugContainer(ctx context.Context, c client.Client, owner client.Object) (string, error) {
+	podName := owner.GetAnnotations()[monitoringthanosiov1alpha1.DebugPodAnnotation]
+	if podName == "" {
+		return "", nil
+	}
+
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: owner.GetNamespace(), Name: podName}, pod); err != nil {
+		return "", fmt.Errorf("failed to get pod %s: %w", podName, err)
+	}
+	if pod.GetLabels()[manifests.OwnerLabel] != manifests.ValidateAndSanitizeNameToValidLabelValue(owner.GetName()) {
+		return "", fmt.Errorf("pod %s is not managed by %s", podName, owner.GetName())
+	}
+	for _, ec := range pod.Spec.EphemeralContainers {
+		if ec.Name == debugContainerName {
+			return "", nil
+		}
+	}
+
+	debug, ok := debugContainerFor(pod)
+	if !ok {
+		return "", fmt.Errorf("pod %s has no container with object storage configuration", podName)
+	}
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, debug)
+	if err := c.SubResource("ephemeralcontainers").Update(ctx, pod); err != nil {
+		return "", fmt.Errorf("failed to attach debug container to pod %s: %w", podName, err)
+	}
+	return podName, nil
+}
+
+// debugContainerFor returns an ephemeral container running a shell in the image of the Thanos container of the pod,
+// with the object storage configuration and token path of the container in its environment and the volumes of the container mounted read-only.
+// It returns false if no container of the pod has object storage configuration.
+func debugContainerFor(pod *corev1.Pod) (corev1.EphemeralContainer, bool) {
+	for _, container := range pod.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name != objStoreEnvVarName {
+				continue
+			}
+
+			envs := []corev1.EnvVar{env}
+			for _, e := range container.Env {
+				if e.Name == manifests.ObjStoreTokenEnvVarName {
+					envs = append(envs, e)
+				}
+			}
+
+			mounts := make([]corev1.VolumeMount, 0, len(container.VolumeMounts))
+			for _, m := range container.VolumeMounts {
+				m.ReadOnly = true
+				mounts = append(mounts, m)
+			}
+			return corev1.EphemeralContainer{
+				TargetContainerName: container.Name,
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:            debugContainerName,
+					Image:           container.Image,
+					ImagePullPolicy: container.ImagePullPolicy,
+					Command:         []string{"/bin/sh"},
+					Stdin:           true,
+					TTY:             true,
+					Env:             envs,
+					VolumeMounts:    mounts,
+					SecurityContext: container.SecurityContext,
+				},
+			}, true
+		}
+	}
+	return corev1.EphemeralContainer{}, false
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/podhealth"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxReportedPodProblems is the maximum number of pod problems described in the Degraded condition.
+const maxReportedPodProblems = 3
+
+// podProblems returns the problems keeping the pods of a resource from becoming ready, see ownedPods.
+func podProblems(ctx context.Context, c client.Client, obj client.Object) ([]podhealth.Problem, error) {
+	pods, err := ownedPods(ctx, c, obj)
+	if err != nil {
+		return nil, err
+	}
+	return podhealth.Find(pods), nil
+}
+
+// podsDegradedCondition returns the Degraded condition of the given generation of a resource with pod problems,
+// whose reason is the reason of the first problem, e.g. ImagePullBackOff, Unschedulable or CrashLoopBackOff.
+func podsDegradedCondition(generation int64, problems []podhealth.Problem) metav1.Condition {
+	return metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ConditionDegraded,
+		Status:             metav1.ConditionTrue,
+		Reason:             problems[0].Reason,
+		Message:            podhealth.Summarize(problems, maxReportedPodProblems),
+		ObservedGeneration: generation,
+	}
+}
+
+// setPodsDegradedCondition reports the Degraded condition of the given generation of a resource
+// from the problems of its pods. It returns true if the condition changed.
+func setPodsDegradedCondition(conditions *[]metav1.Condition, generation int64, problems []podhealth.Problem) bool {
+	if len(problems) > 0 {
+		return meta.SetStatusCondition(conditions, podsDegradedCondition(generation, problems))
+	}
+	return meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             reasonPodsHealthy,
+		Message:            "No pods are kept from becoming ready",
+		ObservedGeneration: generation,
+	})
+}
+
+// updateDegradedCondition reports the problems keeping the pods of a resource from becoming ready, such as images
+// which can not be pulled, in its Degraded condition. The status is only written if the condition changed.
+// It is used by controllers which do not otherwise report the Degraded condition.
+func updateDegradedCondition(ctx context.Context, c client.Client, obj client.Object, conditions *[]metav1.Condition) error {
+	problems, err := podProblems(ctx, c, obj)
+	if err != nil {
+		return err
+	}
+	if !setPodsDegradedCondition(conditions, obj.GetGeneration(), problems) {
+		return nil
+	}
+	if err := c.Status().Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to update degraded condition: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// errInvalidObjectStorage is wrapped by the error returned by checkObjectStorage
+// if an object storage configuration failed the pre-flight check.
+var errInvalidObjectStorage = errors.New("invalid object storage configuration")
+
+// objectStoragePreflight returns true if the object storage configurations of a resource are checked before its
+// workloads are rolled out, as enabled by the objectStoragePreflight feature gate.
+func objectStoragePreflight(fg *monitoringthanosiov1alpha1.FeatureGates) bool {
+	return fg != nil && ptr.Deref(fg.ObjectStoragePreflight, false)
+}
+
+// checkObjectStorage checks the object storage configurations read by the workloads of a resource from the given
+// Secret keys, see manifests.ValidateObjectStorageConfig, so that a missing or invalid configuration is reported
+// instead of crash looping pods. The outcome is reflected in the Degraded condition of the resource, and failures
+// are recorded in a warning event. The status is only written if the condition changed.
+// It returns an error wrapping errInvalidObjectStorage if a configuration failed the check, in which case the
+// workloads of the resource must not be rolled out. Disabled checks only clear a Degraded condition they set.
+func checkObjectStorage(ctx context.Context, c client.Client, recorder record.EventRecorder, obj client.Object,
+	conditions *[]metav1.Condition, enabled bool, selectors []corev1.SecretKeySelector) error {
+	var problems []string
+	if enabled {
+		for _, selector := range selectors {
+			if err := checkObjectStorageSecret(ctx, c, obj.GetNamespace(), selector); err != nil {
+				if !errors.Is(err, errInvalidObjectStorage) {
+					return err
+				}
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+
+	var changed bool
+	if len(problems) == 0 {
+		if degraded := meta.FindStatusCondition(*conditions, monitoringthanosiov1alpha1.ConditionDegraded); degraded != nil && degraded.Reason == reasonInvalidObjectStorage {
+			changed = meta.RemoveStatusCondition(conditions, monitoringthanosiov1alpha1.ConditionDegraded)
+		}
+	} else {
+		message := strings.Join(problems, "; ")
+		changed = meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               monitoringthanosiov1alpha1.ConditionDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonInvalidObjectStorage,
+			Message:            message,
+			ObservedGeneration: obj.GetGeneration(),
+		})
+		recorder.Event(obj, corev1.EventTypeWarning, reasonInvalidObjectStorage, message)
+	}
+
+	if changed {
+		if err := c.Status().Update(ctx, obj); err != nil {
+			return fmt.Errorf("failed to update object storage condition: %w", err)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", errInvalidObjectStorage, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// storeObjectStorageSelectors returns the Secret key of the object storage configuration of a ThanosStore,
+// unless the configuration is rendered by the operator from the inline object storage.
+func storeObjectStorageSelectors(store monitoringthanosiov1alpha1.ThanosStore) []corev1.SecretKeySelector {
+	if store.Spec.ObjectStorage != nil || store.Spec.ObjectStorageConfig == nil {
+		return nil
+	}
+	return []corev1.SecretKeySelector{store.Spec.ObjectStorageConfig.ToSecretKeySelector()}
+}
+
+// receiveObjectStorageSelectors returns the Secret keys of the object storage configurations of the ingesters of
+// a ThanosReceive: the default configuration and the configurations overriding it per hashring.
+func receiveObjectStorageSelectors(receive monitoringthanosiov1alpha1.ThanosReceive) []corev1.SecretKeySelector {
+	selectors := []corev1.SecretKeySelector{receive.Spec.Ingester.DefaultObjectStorageConfig.ToSecretKeySelector()}
+	for _, hashring := range receive.Spec.Ingester.Hashrings {
+		if hashring.ObjectStorageConfig != nil {
+			selectors = append(selectors, hashring.ObjectStorageConfig.ToSecretKeySelector())
+		}
+	}
+	return selectors
+}
+
+// checkObjectStorageSecret checks the object storage configuration in the given Secret key.
+// It returns an error wrapping errInvalidObjectStorage if the Secret or key does not exist or the configuration is invalid.
+func checkObjectStorageSecret(ctx context.Context, c client.Client, namespace string, selector corev1.SecretKeySelector) error {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: selector.Name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: Secret %s not found", errInvalidObjectStorage, selector.Name)
+		}
+		return fmt.Errorf("failed to get object storage Secret %s: %w", selector.Name, err)
+	}
+	data, ok := secret.Data[selector.Key]
+	if !ok {
+		return fmt.Errorf("%w: key %s not found in Secret %s", errInvalidObjectStorage, selector.Key, selector.Name)
+	}
+	if err := manifests.ValidateObjectStorageConfig(data); err != nil {
+		return fmt.Errorf("%w: Secret %s key %s: %w", errInvalidObjectStorage, selector.Name, selector.Key, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"context"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
+	"github.com/thanos-community/thanos-operator/internal/pkg/profile"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+)
+
+// reconcileProfile is the timing breakdown of a reconciliation.
+type reconcileProfile struct {
+	*profile.Profile
+	// event records the profile as a ReconcileProfile event on the resource.
+	event bool
+}
+
+// startProfiling returns a context carrying a new reconcile profile. The profile is always observed by the reconcile
+// metrics, and recorded as an event if profiling is enabled by the feature gates of the resource.
+func startProfiling(ctx context.Context, fg *monitoringthanosiov1alpha1.FeatureGates) (context.Context, reconcileProfile) {
+	p := profile.New()
+	return profile.NewContext(ctx, p), reconcileProfile{
+		Profile: p,
+		event:   fg != nil && ptr.Deref(fg.ReconcileProfiling, false),
+	}
+}
+
+// recordProfile observes the timing breakdown of the reconciliation by the reconcile metrics of the controller,
+// and records it as a ReconcileProfile event on the resource if profiling is enabled.
+func recordProfile(recorder record.EventRecorder, metrics *controllermetrics.ReconcileMetrics, obj runtime.Object, p reconcileProfile) {
+	metrics.ObserveReconcile(p.Total(), p.Phases())
+	if p.event {
+		recorder.Event(obj, corev1.EventTypeNormal, "ReconcileProfile", p.String())
+	}
+}
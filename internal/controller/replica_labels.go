@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/querystatus"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestreceive "github.com/thanos-community/thanos-operator/pkg/manifests/receive"
+	manifestruler "github.com/thanos-community/thanos-operator/pkg/manifests/ruler"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultReplicaLabels are the replica labels of a ThanosQuery which does not configure any.
+// They match the replica labels set by Prometheus, ThanosReceive and ThanosRuler.
+var defaultReplicaLabels = []string{"replica", "prometheus_replica", "rule_replica"}
+
+// queryReplicaLabels returns the replica labels configured on the ThanosQuery, or the defaults if none are configured.
+func queryReplicaLabels(query monitoringthanosiov1alpha1.ThanosQuery) []string {
+	if len(query.Spec.ReplicaLabels) == 0 {
+		return defaultReplicaLabels
+	}
+	return query.Spec.ReplicaLabels
+}
+
+// validateReplicaLabels returns an error if a replica label of the ThanosQuery is empty or configured more than once.
+func validateReplicaLabels(query monitoringthanosiov1alpha1.ThanosQuery) error {
+	seen := make(map[string]struct{}, len(query.Spec.ReplicaLabels))
+	for _, l := range query.Spec.ReplicaLabels {
+		if l == "" {
+			return errors.New("replica labels must not be empty")
+		}
+		if _, ok := seen[l]; ok {
+			return fmt.Errorf("replica label %q is configured more than once", l)
+		}
+		seen[l] = struct{}{}
+	}
+	return nil
+}
+
+// unusedReplicaLabels returns the replica labels of the ThanosQuery which are not set on any label set
+// advertised by the healthy endpoints of its Querier. It returns nil if no endpoint is healthy,
+// since the label sets of unhealthy endpoints are not known.
+func unusedReplicaLabels(query monitoringthanosiov1alpha1.ThanosQuery, endpoints []querystatus.Endpoint) []string {
+	used := make(map[string]struct{})
+	var healthy bool
+	for _, e := range endpoints {
+		if !e.Up() {
+			continue
+		}
+		healthy = true
+		for _, ls := range e.LabelSets {
+			for name := range ls {
+				used[name] = struct{}{}
+			}
+		}
+	}
+	if !healthy {
+		return nil
+	}
+
+	var unused []string
+	for _, l := range queryReplicaLabels(query) {
+		if _, ok := used[l]; !ok {
+			unused = append(unused, l)
+		}
+	}
+	return unused
+}
+
+// externalReplicaLabels returns the sorted names of the external labels whose value is expanded per replica,
+// such as replica="$(POD_NAME)". A Querier must treat these as replica labels to deduplicate the series of the replicas.
+func externalReplicaLabels(external map[string]string) []string {
+	var names []string
+	for k, v := range external {
+		if strings.Contains(v, "$(") {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// storeReplicaLabels returns a description of the ThanosReceive hashring or ThanosRuler serving the given StoreAPI Service
+// and the external labels it sets per replica.
+// Services which are not served by a replicated ThanosReceive hashring or ThanosRuler return no labels.
+func storeReplicaLabels(ctx context.Context, c client.Client, svc corev1.Service) (string, []string, error) {
+	owner := svc.GetLabels()[manifests.OwnerLabel]
+	if owner == "" {
+		return "", nil, nil
+	}
+	key := client.ObjectKey{Namespace: svc.GetNamespace(), Name: owner}
+
+	switch svc.GetLabels()[manifests.ComponentLabel] {
+	case manifestreceive.IngestComponentName:
+		receive := &monitoringthanosiov1alpha1.ThanosReceive{}
+		if err := c.Get(ctx, key, receive); err != nil {
+			return "", nil, client.IgnoreNotFound(err)
+		}
+		if receive.Spec.Router.ReplicationFactor <= 1 {
+			return "", nil, nil
+		}
+		for _, hashring := range receive.Spec.Ingester.Hashrings {
+			if ReceiveIngesterNameFromParent(receive.GetName(), hashring.Name) == svc.GetName() {
+				return fmt.Sprintf("ThanosReceive %s hashring %s", receive.GetName(), hashring.Name), externalReplicaLabels(hashring.ExternalLabels), nil
+			}
+		}
+	case manifestruler.ComponentName:
+		ruler := &monitoringthanosiov1alpha1.ThanosRuler{}
+		if err := c.Get(ctx, key, ruler); err != nil {
+			return "", nil, client.IgnoreNotFound(err)
+		}
+		if ruler.Spec.Replicas <= 1 {
+			return "", nil, nil
+		}
+		return fmt.Sprintf("ThanosRuler %s", ruler.GetName()), externalReplicaLabels(ruler.Spec.ExternalLabels), nil
+	}
+	return "", nil, nil
+}
+
+// replicaLabelMismatches returns a message for each discovered ThanosReceive hashring or ThanosRuler which sets
+// per replica external labels that are not configured as replica labels of the ThanosQuery.
+// Such series are not deduplicated by the Querier.
+func replicaLabelMismatches(ctx context.Context, c client.Client, query monitoringthanosiov1alpha1.ThanosQuery, services []corev1.Service) ([]string, error) {
+	var mismatches []string
+	for _, svc := range services {
+		source, replicaLabels, err := storeReplicaLabels(ctx, c, svc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get replica labels of service %s: %w", svc.GetName(), err)
+		}
+
+		var missing []string
+		for _, l := range replicaLabels {
+			if !slices.Contains(queryReplicaLabels(query), l) {
+				missing = append(missing, l)
+			}
+		}
+		if len(missing) > 0 {
+			mismatches = append(mismatches, fmt.Sprintf("%s sets replica labels [%s]", source, strings.Join(missing, ", ")))
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches, nil
+}
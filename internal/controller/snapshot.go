@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/snapshot"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestreceive "github.com/thanos-community/thanos-operator/pkg/manifests/receive"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultIngesterSnapshotInterval = "2h"
+	defaultIngesterSnapshotRetain   = 2
+)
+
+// syncIngesterSnapshots takes the due VolumeSnapshots of the volumes of the ready ingesters of the ThanosReceive
+// and prunes the snapshots beyond the retention.
+// It returns the time until the next snapshot is due, or zero if snapshots are not configured.
+func syncIngesterSnapshots(ctx context.Context, c client.Client, scheme *runtime.Scheme, receiver *monitoringthanosiov1alpha1.ThanosReceive) (time.Duration, error) {
+	cfg := receiver.Spec.Ingester.Snapshots
+	if cfg == nil {
+		return 0, nil
+	}
+	interval, err := model.ParseDuration(cmp.Or(string(cfg.Interval), defaultIngesterSnapshotInterval))
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid snapshot interval %q", cfg.Interval)
+	}
+	retain := int(cmp.Or(cfg.Retain, defaultIngesterSnapshotRetain))
+
+	byClaim, err := listIngesterSnapshots(ctx, c, receiver)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	next := now.Add(time.Duration(interval))
+	for _, hashring := range receiver.Spec.Ingester.Hashrings {
+		sts := ReceiveIngesterNameFromParent(receiver.GetName(), hashring.Name)
+		for i := range hashring.Replicas {
+			pod := fmt.Sprintf("%s-%d", sts, i)
+			// volumes of ingesters which are not ready may be lost or inconsistent
+			ready, err := isPodReady(ctx, c, receiver.GetNamespace(), pod)
+			if err != nil {
+				return 0, err
+			}
+			if !ready {
+				continue
+			}
+
+			claim := manifestreceive.IngesterVolumeClaimName(sts, i)
+			due, prune, claimNext := snapshot.Plan(byClaim[claim], now, time.Duration(interval), retain)
+			if due {
+				vs := snapshot.New(claim, receiver.GetNamespace(), ptr.Deref(cfg.VolumeSnapshotClassName, ""), ingesterSnapshotLabels(receiver), now)
+				if err := ctrl.SetControllerReference(receiver, vs, scheme); err != nil {
+					return 0, err
+				}
+				if err := c.Create(ctx, vs); err != nil && !apierrors.IsAlreadyExists(err) {
+					return 0, fmt.Errorf("failed to create snapshot of volume %s: %w", claim, err)
+				}
+			}
+			for _, name := range prune {
+				vs := &unstructured.Unstructured{}
+				vs.SetGroupVersionKind(snapshot.GroupVersionKind)
+				vs.SetNamespace(receiver.GetNamespace())
+				vs.SetName(name)
+				if err := c.Delete(ctx, vs); err != nil && !apierrors.IsNotFound(err) {
+					return 0, fmt.Errorf("failed to delete snapshot %s: %w", name, err)
+				}
+			}
+			if claimNext.Before(next) {
+				next = claimNext
+			}
+		}
+	}
+	return time.Until(next), nil
+}
+
+// restoreIngester replaces the volume of the ingester pod selected by the RestoreIngesterAnnotation of the ThanosReceive
+// with a volume restored from its latest VolumeSnapshot which is ready to use.
+// The StatefulSet of the pod is deleted while orphaning its pods, so that it does not recreate the pod with an empty volume,
+// then the pod and its PersistentVolumeClaim are deleted and the claim is recreated from the snapshot.
+// Once the claim is restored, the annotation is removed and the StatefulSet can be recreated, which adopts
+// the remaining pods and recreates the pod with the restored claim.
+// It returns the name of the StatefulSet while the restore is in progress, which must not be applied,
+// and true once the claim is restored.
+func restoreIngester(ctx context.Context, c client.Client, receiver *monitoringthanosiov1alpha1.ThanosReceive) (string, bool, error) {
+	pod := receiver.GetAnnotations()[monitoringthanosiov1alpha1.RestoreIngesterAnnotation]
+	if pod == "" {
+		return "", false, nil
+	}
+
+	sts, ordinal, hashring, ok := ingesterOfPod(receiver, pod)
+	if !ok {
+		return "", false, fmt.Errorf("pod %s is not an ingester of %s", pod, receiver.GetName())
+	}
+	claim := manifestreceive.IngesterVolumeClaimName(sts, ordinal)
+
+	byClaim, err := listIngesterSnapshots(ctx, c, receiver)
+	if err != nil {
+		return sts, false, err
+	}
+	latest, ok := snapshot.LatestReady(byClaim[claim])
+	if !ok {
+		return "", false, fmt.Errorf("no snapshot of volume %s is ready to use", claim)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err = c.Get(ctx, client.ObjectKey{Namespace: receiver.GetNamespace(), Name: claim}, pvc)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return sts, false, fmt.Errorf("failed to get volume %s: %w", claim, err)
+	}
+	exists := err == nil
+	if exists && pvc.GetDeletionTimestamp().IsZero() && pvc.GetAnnotations()[snapshot.RestoredFromAnnotation] == latest.Name {
+		patch := client.MergeFrom(receiver.DeepCopy())
+		annotations := receiver.GetAnnotations()
+		delete(annotations, monitoringthanosiov1alpha1.RestoreIngesterAnnotation)
+		receiver.SetAnnotations(annotations)
+		if err := c.Patch(ctx, receiver, patch); err != nil {
+			return sts, false, fmt.Errorf("failed to remove restore annotation: %w", err)
+		}
+		return "", true, nil
+	}
+
+	// the StatefulSet must be gone before the pod is deleted, otherwise it recreates the pod and an empty volume
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: sts, Namespace: receiver.GetNamespace()}}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(statefulSet), statefulSet); err == nil {
+		if err := c.Delete(ctx, statefulSet, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !apierrors.IsNotFound(err) {
+			return sts, false, fmt.Errorf("failed to delete StatefulSet %s: %w", sts, err)
+		}
+		return sts, false, nil
+	} else if !apierrors.IsNotFound(err) {
+		return sts, false, fmt.Errorf("failed to get StatefulSet %s: %w", sts, err)
+	}
+
+	if exists && pvc.GetDeletionTimestamp().IsZero() {
+		if err := c.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
+			return sts, false, fmt.Errorf("failed to delete volume %s: %w", claim, err)
+		}
+	}
+	// the claim is protected from deletion as long as the pod using it exists
+	if err := c.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pod, Namespace: receiver.GetNamespace()}}); err != nil && !apierrors.IsNotFound(err) {
+		return sts, false, fmt.Errorf("failed to delete pod %s: %w", pod, err)
+	}
+	if exists {
+		return sts, false, nil
+	}
+
+	size, err := resource.ParseQuantity(string(hashring.StorageSize))
+	if err != nil {
+		return sts, false, fmt.Errorf("invalid storage size of hashring %s: %w", hashring.Name, err)
+	}
+	restored := snapshot.RestoredClaim(claim, receiver.GetNamespace(), latest, size, ingesterSnapshotLabels(receiver))
+	if err := c.Create(ctx, restored); err != nil && !apierrors.IsAlreadyExists(err) {
+		return sts, false, fmt.Errorf("failed to restore volume %s: %w", claim, err)
+	}
+	return sts, false, nil
+}
+
+// ingesterOfPod returns the StatefulSet, ordinal and hashring of the given ingester pod of the ThanosReceive.
+func ingesterOfPod(receiver *monitoringthanosiov1alpha1.ThanosReceive, pod string) (string, int32, monitoringthanosiov1alpha1.IngesterHashringSpec, bool) {
+	for _, hashring := range receiver.Spec.Ingester.Hashrings {
+		sts := ReceiveIngesterNameFromParent(receiver.GetName(), hashring.Name)
+		suffix, ok := strings.CutPrefix(pod, sts+"-")
+		if !ok {
+			continue
+		}
+		ordinal, err := strconv.ParseInt(suffix, 10, 32)
+		if err != nil || ordinal < 0 || int32(ordinal) >= hashring.Replicas {
+			continue
+		}
+		return sts, int32(ordinal), hashring, true
+	}
+	return "", 0, monitoringthanosiov1alpha1.IngesterHashringSpec{}, false
+}
+
+// listIngesterSnapshots returns the VolumeSnapshots of the ingester volumes of the ThanosReceive, keyed by claim name.
+func listIngesterSnapshots(ctx context.Context, c client.Client, receiver *monitoringthanosiov1alpha1.ThanosReceive) (map[string][]snapshot.Snapshot, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(snapshot.ListGroupVersionKind)
+	if err := c.List(ctx, list, client.InNamespace(receiver.GetNamespace()), client.MatchingLabels(ingesterSnapshotLabels(receiver))); err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	byClaim := make(map[string][]snapshot.Snapshot)
+	for _, item := range list.Items {
+		s := snapshot.FromUnstructured(item)
+		byClaim[s.Claim] = append(byClaim[s.Claim], s)
+	}
+	return byClaim, nil
+}
+
+// ingesterSnapshotLabels returns the labels of the VolumeSnapshots of the ingester volumes of the ThanosReceive.
+func ingesterSnapshotLabels(receiver *monitoringthanosiov1alpha1.ThanosReceive) map[string]string {
+	return manifests.GetLabelSelectorForOwner(manifestreceive.IngesterOptions{
+		Options: manifests.Options{Owner: receiver.GetName()},
+	}).(client.MatchingLabels)
+}
+
+func isPodReady(ctx context.Context, c client.Client, namespace, name string) (bool, error) {
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get pod %s: %w", name, err)
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue, nil
+		}
+	}
+	return false, nil
+}
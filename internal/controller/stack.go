@@ -0,0 +1,340 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/prometheus/common/model"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/handlers"
+	"github.com/thanos-community/thanos-operator/internal/pkg/profile"
+	"github.com/thanos-community/thanos-operator/internal/pkg/rollback"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestcompact "github.com/thanos-community/thanos-operator/pkg/manifests/compact"
+	manifestquery "github.com/thanos-community/thanos-operator/pkg/manifests/query"
+	manifestqueryfrontend "github.com/thanos-community/thanos-operator/pkg/manifests/queryfrontend"
+	manifestreceive "github.com/thanos-community/thanos-operator/pkg/manifests/receive"
+	manifestruler "github.com/thanos-community/thanos-operator/pkg/manifests/ruler"
+	manifeststore "github.com/thanos-community/thanos-operator/pkg/manifests/store"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	rulerRoutePrefixBase  = "/ruler"
+	bucketRoutePrefixBase = "/bucket"
+)
+
+// upgradeOrder is the order in which the components of a stack are upgraded to a new Thanos version.
+// Following the upstream recommendation, the components serving the Store API are upgraded before the Queriers,
+// which are upgraded before the components querying them.
+var upgradeOrder = map[string]int{
+	versionPolicyStore:         0,
+	versionPolicyReceive:       0,
+	versionPolicyCompact:       0,
+	versionPolicyQuery:         1,
+	versionPolicyQueryFrontend: 2,
+	versionPolicyRuler:         2,
+}
+
+// workloadComponents maps the name label of the workloads of a stack to their component.
+var workloadComponents = map[string]string{
+	manifeststore.Name:         versionPolicyStore,
+	manifestreceive.Name:       versionPolicyReceive,
+	manifestcompact.Name:       versionPolicyCompact,
+	manifestquery.Name:         versionPolicyQuery,
+	manifestqueryfrontend.Name: versionPolicyQueryFrontend,
+	manifestruler.Name:         versionPolicyRuler,
+}
+
+// componentUpgrade is the upgrade progress of a component of a member of a stack.
+type componentUpgrade struct {
+	monitoringthanosiov1alpha1.StackComponentStatus
+	// running are the Thanos versions deployed by the existing workloads of the component.
+	running []string
+}
+
+// rulerRoutePrefix returns the path under which the UI of the ThanosRuler is served on the stack Ingress.
+func rulerRoutePrefix(name string) string {
+	return fmt.Sprintf("%s/%s", rulerRoutePrefixBase, name)
+}
+
+// compactRoutePrefix returns the path under which the bucket UI of the ThanosCompact, or its shard, is served on the stack Ingress.
+func compactRoutePrefix(opts manifestcompact.Options) string {
+	prefix := fmt.Sprintf("%s/%s", bucketRoutePrefixBase, opts.Owner)
+	if opts.ShardName != nil && opts.ShardIndex != nil {
+		prefix = fmt.Sprintf("%s/%s-%d", prefix, *opts.ShardName, *opts.ShardIndex)
+	}
+	return prefix
+}
+
+// stackIngressEnabled returns true if a ThanosQuery in the stack of the given object exposes the stack on an Ingress.
+// Objects which do not belong to a stack always return false.
+func stackIngressEnabled(ctx context.Context, c client.Client, obj client.Object) (bool, error) {
+	defer profile.FromContext(ctx).Start("discovery")()
+	stack := obj.GetLabels()[monitoringthanosiov1alpha1.StackLabel]
+	if stack == "" {
+		return false, nil
+	}
+
+	queries := &monitoringthanosiov1alpha1.ThanosQueryList{}
+	if err := c.List(ctx, queries, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{monitoringthanosiov1alpha1.StackLabel: stack}); err != nil {
+		return false, fmt.Errorf("failed to list ThanosQuery resources in stack %s: %w", stack, err)
+	}
+	for _, query := range queries.Items {
+		if query.Spec.StackIngress != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// stackTimeSplit returns the lowest and highest time split boundaries configured by the ThanosQueries in the stack
+// of the given object. Objects which do not belong to a stack, or stacks without a time split, return false.
+func stackTimeSplit(ctx context.Context, c client.Client, obj client.Object) (lowest, highest model.Duration, ok bool, err error) {
+	defer profile.FromContext(ctx).Start("discovery")()
+	stack := obj.GetLabels()[monitoringthanosiov1alpha1.StackLabel]
+	if stack == "" {
+		return 0, 0, false, nil
+	}
+
+	queries := &monitoringthanosiov1alpha1.ThanosQueryList{}
+	if err := c.List(ctx, queries, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{monitoringthanosiov1alpha1.StackLabel: stack}); err != nil {
+		return 0, 0, false, fmt.Errorf("failed to list ThanosQuery resources in stack %s: %w", stack, err)
+	}
+	for _, query := range queries.Items {
+		if query.Spec.TimeSplit == nil {
+			continue
+		}
+		boundary, err := model.ParseDuration(string(query.Spec.TimeSplit.Boundary))
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("invalid time split boundary of ThanosQuery %s: %w", query.GetName(), err)
+		}
+		if !ok || boundary < lowest {
+			lowest = boundary
+		}
+		if !ok || boundary > highest {
+			highest = boundary
+		}
+		ok = true
+	}
+	return lowest, highest, ok, nil
+}
+
+// stackUpgradeProgress returns the upgrade progress of each component of the members of the stack
+// the given object belongs to, in upgrade order. Objects which do not belong to a stack return nil.
+func stackUpgradeProgress(ctx context.Context, c client.Client, obj client.Object) ([]componentUpgrade, error) {
+	defer profile.FromContext(ctx).Start("discovery")()
+	stack := obj.GetLabels()[monitoringthanosiov1alpha1.StackLabel]
+	if stack == "" {
+		return nil, nil
+	}
+
+	type member struct {
+		kind     string
+		obj      client.Object
+		versions map[string][]string
+	}
+	var members []member
+	listOpts := []client.ListOption{client.InNamespace(obj.GetNamespace()), client.MatchingLabels{monitoringthanosiov1alpha1.StackLabel: stack}}
+
+	stores := &monitoringthanosiov1alpha1.ThanosStoreList{}
+	if err := c.List(ctx, stores, listOpts...); err != nil {
+		return nil, fmt.Errorf("failed to list ThanosStore resources in stack %s: %w", stack, err)
+	}
+	for i := range stores.Items {
+		members = append(members, member{kind: "ThanosStore", obj: &stores.Items[i], versions: storeVersions(stores.Items[i])})
+	}
+	receives := &monitoringthanosiov1alpha1.ThanosReceiveList{}
+	if err := c.List(ctx, receives, listOpts...); err != nil {
+		return nil, fmt.Errorf("failed to list ThanosReceive resources in stack %s: %w", stack, err)
+	}
+	for i := range receives.Items {
+		members = append(members, member{kind: "ThanosReceive", obj: &receives.Items[i], versions: receiveVersions(receives.Items[i])})
+	}
+	compacts := &monitoringthanosiov1alpha1.ThanosCompactList{}
+	if err := c.List(ctx, compacts, listOpts...); err != nil {
+		return nil, fmt.Errorf("failed to list ThanosCompact resources in stack %s: %w", stack, err)
+	}
+	for i := range compacts.Items {
+		members = append(members, member{kind: "ThanosCompact", obj: &compacts.Items[i], versions: compactVersions(compacts.Items[i])})
+	}
+	queries := &monitoringthanosiov1alpha1.ThanosQueryList{}
+	if err := c.List(ctx, queries, listOpts...); err != nil {
+		return nil, fmt.Errorf("failed to list ThanosQuery resources in stack %s: %w", stack, err)
+	}
+	for i := range queries.Items {
+		members = append(members, member{kind: "ThanosQuery", obj: &queries.Items[i], versions: queryVersions(queries.Items[i])})
+	}
+	rulers := &monitoringthanosiov1alpha1.ThanosRulerList{}
+	if err := c.List(ctx, rulers, listOpts...); err != nil {
+		return nil, fmt.Errorf("failed to list ThanosRuler resources in stack %s: %w", stack, err)
+	}
+	for i := range rulers.Items {
+		members = append(members, member{kind: "ThanosRuler", obj: &rulers.Items[i], versions: rulerVersions(rulers.Items[i])})
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := c.List(ctx, deployments, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := c.List(ctx, statefulSets, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	var workloads []client.Object
+	for i := range deployments.Items {
+		workloads = append(workloads, &deployments.Items[i])
+	}
+	for i := range statefulSets.Items {
+		workloads = append(workloads, &statefulSets.Items[i])
+	}
+
+	var progress []componentUpgrade
+	for _, m := range members {
+		for component, versions := range m.versions {
+			requested := sortedUnique(versions)
+			upgrade := componentUpgrade{StackComponentStatus: monitoringthanosiov1alpha1.StackComponentStatus{
+				Kind:      m.kind,
+				Name:      m.obj.GetName(),
+				Component: component,
+				Versions:  requested,
+			}}
+
+			rolledOut := true
+			for _, w := range workloads {
+				if !metav1.IsControlledBy(w, m.obj) || workloadComponents[w.GetLabels()[manifests.NameLabel]] != component {
+					continue
+				}
+				upgrade.running = append(upgrade.running, w.GetAnnotations()[manifests.ThanosVersionAnnotation])
+				rolledOut = rolledOut && isWorkloadRolledOut(w)
+			}
+			upgrade.running = sortedUnique(upgrade.running)
+
+			switch {
+			case !slices.Equal(upgrade.running, requested):
+				upgrade.Phase = monitoringthanosiov1alpha1.StackUpgradePending
+			case !rolledOut:
+				upgrade.Phase = monitoringthanosiov1alpha1.StackUpgradeUpgrading
+			default:
+				upgrade.Phase = monitoringthanosiov1alpha1.StackUpgradeUpgraded
+			}
+			progress = append(progress, upgrade)
+		}
+	}
+
+	sort.Slice(progress, func(i, j int) bool {
+		a, b := progress[i], progress[j]
+		return cmp.Or(
+			cmp.Compare(upgradeOrder[a.Component], upgradeOrder[b.Component]),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Component, b.Component),
+		) < 0
+	})
+	return progress, nil
+}
+
+// deferredUpgrade returns an error wrapping handlers.ErrRolloutDeferred if the given component of a member of a stack
+// is upgraded to a version which a component earlier in the upgrade order requests too, but has not completed its upgrade to.
+// Components without workloads yet are being created rather than upgraded, and are never deferred.
+func deferredUpgrade(progress []componentUpgrade, kind, name, component string) error {
+	i := slices.IndexFunc(progress, func(u componentUpgrade) bool {
+		return u.Kind == kind && u.Name == name && u.Component == component
+	})
+	if i < 0 || len(progress[i].running) == 0 {
+		return nil
+	}
+
+	upgrade := progress[i]
+	for _, version := range upgrade.Versions {
+		if slices.Contains(upgrade.running, version) {
+			continue
+		}
+		for _, earlier := range progress {
+			if upgradeOrder[earlier.Component] >= upgradeOrder[component] ||
+				earlier.Phase == monitoringthanosiov1alpha1.StackUpgradeUpgraded || !slices.Contains(earlier.Versions, version) {
+				continue
+			}
+			return fmt.Errorf("%w: upgrade of %s of %s/%s to %s is waiting for %s of %s/%s to be upgraded",
+				handlers.ErrRolloutDeferred, component, kind, name, version, earlier.Component, earlier.Kind, earlier.Name)
+		}
+	}
+	return nil
+}
+
+// stackComponentStatuses returns the status of each component in the given upgrade progress.
+func stackComponentStatuses(progress []componentUpgrade) []monitoringthanosiov1alpha1.StackComponentStatus {
+	if len(progress) == 0 {
+		return nil
+	}
+	statuses := make([]monitoringthanosiov1alpha1.StackComponentStatus, 0, len(progress))
+	for _, u := range progress {
+		statuses = append(statuses, u.StackComponentStatus)
+	}
+	return statuses
+}
+
+// sortedUnique returns a sorted copy of the given strings without duplicates.
+func sortedUnique(in []string) []string {
+	out := slices.Clone(in)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// isWorkloadRolledOut returns true if all replicas of the Deployment or StatefulSet run its current pod template and are ready.
+func isWorkloadRolledOut(obj client.Object) bool {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		return rollback.IsRolledOut(w)
+	case *appsv1.StatefulSet:
+		return handlers.IsStatefulSetRolledOut(w)
+	default:
+		return true
+	}
+}
+
+// enqueueForStack returns an EventHandler that enqueues a request for each resource of the type of the given list
+// which belongs to the same stack as the object that triggered the event.
+func enqueueForStack(c client.Client, list client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		stack := obj.GetLabels()[monitoringthanosiov1alpha1.StackLabel]
+		if stack == "" {
+			return nil
+		}
+
+		l := list.DeepCopyObject().(client.ObjectList)
+		if err := c.List(ctx, l, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{monitoringthanosiov1alpha1.StackLabel: stack}); err != nil {
+			log.FromContext(ctx).Error(err, "failed to list members of stack", "stack", stack)
+			return nil
+		}
+		items, err := meta.ExtractList(l)
+		if err != nil {
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(items))
+		for _, item := range items {
+			member, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: member.GetNamespace(), Name: member.GetName()},
+			})
+		}
+		return requests
+	})
+}
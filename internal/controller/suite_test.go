@@ -156,6 +156,20 @@ var _ = BeforeSuite(func() {
 	).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = NewThanosToolsReconciler(
+		buildConfig("tools"),
+		k8sManager.GetClient(),
+		k8sManager.GetScheme(),
+	).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = NewThanosTenantReconciler(
+		buildConfig("tenant"),
+		k8sManager.GetClient(),
+		k8sManager.GetScheme(),
+	).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	go func() {
 		err = k8sManager.Start(ctx)
 		Expect(err).ToNot(HaveOccurred())
@@ -18,7 +18,10 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/go-logr/logr"
 
@@ -26,11 +29,18 @@ import (
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
 	"github.com/thanos-community/thanos-operator/internal/pkg/handlers"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestcompact "github.com/thanos-community/thanos-operator/internal/pkg/manifests/compact"
 	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
-
+	"github.com/thanos-community/thanos-operator/internal/pkg/profile"
+	"github.com/thanos-community/thanos-operator/internal/pkg/schedule"
+	"github.com/thanos-community/thanos-operator/internal/pkg/versionpolicy"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestcompact "github.com/thanos-community/thanos-operator/pkg/manifests/compact"
+	manifeststools "github.com/thanos-community/thanos-operator/pkg/manifests/tools"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -46,16 +56,23 @@ type ThanosCompactReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	logger   logr.Logger
-	metrics  controllermetrics.ThanosCompactMetrics
-	recorder record.EventRecorder
+	logger           logr.Logger
+	metrics          controllermetrics.ThanosCompactMetrics
+	reconcileMetrics *controllermetrics.ReconcileMetrics
+	recorder         record.EventRecorder
 
-	handler *handlers.Handler
+	handler          *handlers.Handler
+	versionPolicy    *versionpolicy.Policy
+	controllerConfig ControllerConfig
 }
 
 //+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanoscompacts,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanoscompacts/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanoscompacts/finalizers,verbs=update
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get
+//+kubebuilder:rbac:groups="",resources=pods/ephemeralcontainers,verbs=update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -78,6 +95,10 @@ func (r *ThanosCompactReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		r.recorder.Event(compact, corev1.EventTypeWarning, "GetFailed", "Failed to get ThanosCompact resource")
 		return ctrl.Result{}, err
 	}
+	start := time.Now()
+	defer func() { r.reconcileMetrics.ObserveApply(r.handler.LogApplySummary(compact, start)) }()
+	ctx, prof := startProfiling(ctx, compact.Spec.FeatureGates)
+	defer recordProfile(r.recorder, r.reconcileMetrics, compact, prof)
 
 	if compact.Spec.Paused != nil && *compact.Spec.Paused {
 		r.logger.Info("reconciliation is paused for ThanosCompact resource")
@@ -85,30 +106,175 @@ func (r *ThanosCompactReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, nil
 	}
 
-	err = r.syncResources(ctx, *compact)
+	if err := validateAdditional(compact.Spec.Additional); err != nil {
+		r.logger.Error(err, "invalid additional configuration for ThanosCompact")
+		r.recorder.Event(compact, corev1.EventTypeWarning, "InvalidSpec", fmt.Sprintf("Invalid additional configuration: %v", err))
+		return ctrl.Result{}, nil
+	}
+
+	var scheduleState *schedule.State
+	if compact.Spec.Schedule != nil {
+		windows, loc, err := compactScheduleToWindows(*compact.Spec.Schedule)
+		if err != nil {
+			r.logger.Error(err, "invalid schedule for ThanosCompact")
+			r.recorder.Event(compact, corev1.EventTypeWarning, "InvalidSchedule", fmt.Sprintf("Invalid schedule: %v", err))
+			return ctrl.Result{}, nil
+		}
+		state := schedule.Evaluate(windows, time.Now().In(loc))
+		scheduleState = &state
+	}
+
+	if err := checkOptions(ctx, r.Client, r.recorder, compact, &compact.Status.Conditions, compactV1Alpha1ToOptions(*compact).Options); err != nil {
+		r.logger.Error(err, "invalid options for ThanosCompact")
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := checkVersions(ctx, r.Client, r.recorder, compact, &compact.Status.Conditions, r.versionPolicy, compactVersions(*compact)); err != nil {
+		r.logger.Error(err, "failed to check versions of ThanosCompact")
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := checkObjectStorage(ctx, r.Client, r.recorder, compact, &compact.Status.Conditions,
+		objectStoragePreflight(compact.Spec.FeatureGates), []corev1.SecretKeySelector{compact.Spec.ObjectStorageConfig.ToSecretKeySelector()}); err != nil {
+		r.logger.Error(err, "failed to check object storage of ThanosCompact")
+		if errors.Is(err, errInvalidObjectStorage) {
+			return ctrl.Result{RequeueAfter: objectStoragePreflightRequeueInterval}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	err = r.syncResources(ctx, *compact, scheduleState != nil && !scheduleState.Active)
+	if blockedErr := r.handler.ApplyBlocked(compact); blockedErr != nil {
+		err = blockedErr
+	}
+	requeueAfter, throttledErr := r.handler.ApplyThrottled(compact)
+	if err == nil && throttledErr != nil {
+		err = throttledErr
+	}
+	probeAfter, permissionsErr := r.handler.MissingPermissions(compact)
+	if statusErr := updateBlockedCondition(ctx, r.Client, compact, &compact.Status.Conditions, err); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update blocked condition")
+	}
+	if statusErr := updateMissingPermissionsCondition(ctx, r.Client, compact, &compact.Status.Conditions, permissionsErr); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update missing permissions condition")
+	}
+	if errors.Is(err, handlers.ErrRolloutDeferred) {
+		r.recorder.Event(compact, corev1.EventTypeNormal, "RolloutDeferred", err.Error())
+		return ctrl.Result{RequeueAfter: rolloutDeferredRequeueInterval}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyThrottled) {
+		r.recorder.Event(compact, corev1.EventTypeNormal, "ApplyThrottled", err.Error())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyBlocked) {
+		// retrying will not help until the spec or the reconcile-now annotation changes, which triggers a new reconciliation
+		r.recorder.Event(compact, corev1.EventTypeWarning, "ApplyBlocked", err.Error())
+		return ctrl.Result{}, nil
+	}
 	if err != nil {
 		r.recorder.Event(compact, corev1.EventTypeWarning, "SyncFailed", fmt.Sprintf("Failed to sync resources: %v", err))
 		return ctrl.Result{}, err
 	}
 
+	if err := r.handler.CompleteRollout(ctx, compact); err != nil {
+		r.logger.Error(err, "failed to complete rollout")
+		return ctrl.Result{}, err
+	}
+
+	if permissionsErr != nil {
+		// the objects are applied again at the next probe, retrying sooner would only be denied again
+		r.recorder.Event(compact, corev1.EventTypeWarning, "MissingPermissions", permissionsErr.Error())
+		return ctrl.Result{RequeueAfter: probeAfter}, nil
+	}
+
+	if err := updateCrashLoopingCondition(ctx, r.Client, r.recorder, compact, &compact.Status.Conditions); err != nil {
+		r.logger.Error(err, "failed to update crash looping condition")
+	}
+	if err := updateDegradedCondition(ctx, r.Client, compact, &compact.Status.Conditions); err != nil {
+		r.logger.Error(err, "failed to update degraded condition")
+	}
+	if err := updateDefaultsStatus(ctx, r.Client, compact, &compact.Status.Defaults, compactV1Alpha1ToOptions(*compact).Options); err != nil {
+		r.logger.Error(err, "failed to update defaults status")
+	}
+
+	if pod, err := attachDebugContainer(ctx, r.Client, compact); err != nil {
+		r.logger.Error(err, "failed to attach debug container")
+		r.recorder.Event(compact, corev1.EventTypeWarning, "DebugContainerFailed", fmt.Sprintf("Failed to attach debug container: %v", err))
+	} else if pod != "" {
+		r.recorder.Event(compact, corev1.EventTypeNormal, "DebugContainerAttached", fmt.Sprintf("Attached debug container %s to pod %s", debugContainerName, pod))
+	}
+
+	if err := r.updateBlockMarkerStatus(ctx, compact); err != nil {
+		r.logger.Error(err, "failed to update block marker status")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateScheduleStatus(ctx, compact, scheduleState); err != nil {
+		r.logger.Error(err, "failed to update schedule status")
+		return ctrl.Result{}, err
+	}
+	if scheduleState != nil && !scheduleState.NextTransition.IsZero() {
+		return ctrl.Result{RequeueAfter: time.Until(scheduleState.NextTransition)}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
+// updateScheduleStatus reports the given state of the compaction schedule in the status of the ThanosCompact.
+func (r *ThanosCompactReconciler) updateScheduleStatus(ctx context.Context, compact *monitoringthanosiov1alpha1.ThanosCompact, state *schedule.State) error {
+	var status *monitoringthanosiov1alpha1.CompactScheduleStatus
+	if state != nil {
+		status = &monitoringthanosiov1alpha1.CompactScheduleStatus{Active: state.Active}
+		if !state.NextTransition.IsZero() {
+			status.NextTransition = ptr.To(metav1.NewTime(state.NextTransition))
+		}
+	}
+	if equality.Semantic.DeepEqual(compact.Status.Schedule, status) {
+		return nil
+	}
+
+	if status != nil && (compact.Status.Schedule == nil || compact.Status.Schedule.Active != status.Active) {
+		reason, msg := "CompactionWindowClosed", "Compaction window closed, scaling Compactor to zero"
+		if status.Active {
+			reason, msg = "CompactionWindowOpened", "Compaction window opened, scaling Compactor up"
+		}
+		r.recorder.Event(compact, corev1.EventTypeNormal, reason, msg)
+	}
+
+	compact.Status.Schedule = status
+	return r.Status().Update(ctx, compact)
+}
+
 // NewThanosCompactReconciler returns a reconciler for ThanosCompact resources.
 func NewThanosCompactReconciler(conf Config, client client.Client, scheme *runtime.Scheme) *ThanosCompactReconciler {
 	handler := handlers.NewHandler(client, scheme, conf.InstrumentationConfig.Logger)
+	handler.SetLogSampler(conf.InstrumentationConfig.LogSampler)
 	featureGates := conf.FeatureGate.ToGVK()
 	if len(featureGates) > 0 {
 		handler.SetFeatureGates(featureGates)
 	}
+	handler.SetImagePolicy(conf.ImagePolicy)
+	handler.SetMaintenance(conf.Maintenance)
+	handler.SetApplyRetryBudget(conf.ApplyRetryBudget)
+	handler.SetWriteLimit(conf.WriteLimit, conf.WriteLimitWindow)
+	handler.SetForbiddenProbeInterval(conf.ForbiddenProbeInterval)
 
 	return &ThanosCompactReconciler{
-		Client:   client,
-		Scheme:   scheme,
-		logger:   conf.InstrumentationConfig.Logger,
-		metrics:  controllermetrics.NewThanosCompactMetrics(conf.InstrumentationConfig.MetricsRegistry),
-		recorder: conf.InstrumentationConfig.EventRecorder,
-		handler:  handler,
+		Client:           client,
+		Scheme:           scheme,
+		logger:           conf.InstrumentationConfig.Logger,
+		metrics:          controllermetrics.NewThanosCompactMetrics(conf.InstrumentationConfig.MetricsRegistry),
+		reconcileMetrics: conf.InstrumentationConfig.ReconcileMetrics,
+		recorder:         conf.InstrumentationConfig.EventRecorder,
+		handler:          handler,
+		controllerConfig: conf.Controller,
+		versionPolicy:    conf.VersionPolicy,
 	}
 }
 
@@ -116,12 +282,22 @@ func NewThanosCompactReconciler(conf Config, client client.Client, scheme *runti
 func (r *ThanosCompactReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&monitoringthanosiov1alpha1.ThanosCompact{}).
+		WithOptions(r.controllerConfig.options()).
+		Owns(&appsv1.StatefulSet{}).
+		Owns(&batchv1.Job{}).
+		Watches(
+			&monitoringthanosiov1alpha1.ThanosQuery{},
+			enqueueForStack(r.Client, &monitoringthanosiov1alpha1.ThanosCompactList{}),
+		).
 		Complete(r)
 }
 
-func (r *ThanosCompactReconciler) syncResources(ctx context.Context, compact monitoringthanosiov1alpha1.ThanosCompact) error {
+func (r *ThanosCompactReconciler) syncResources(ctx context.Context, compact monitoringthanosiov1alpha1.ThanosCompact, suspended bool) error {
 	var errCount int
-	options := r.specToOptions(compact)
+	options, err := r.specToOptions(ctx, compact, suspended)
+	if err != nil {
+		return err
+	}
 
 	// for compactor, we want to make sure we clean up any resources that are no longer needed first
 	// as we don't want multiple compactor instances potentially compacting the same data.
@@ -137,13 +313,26 @@ func (r *ThanosCompactReconciler) syncResources(ctx context.Context, compact mon
 
 	// now we can create what we expect to be built based on the spec
 	for _, opt := range options {
-		errCount += r.handler.CreateOrUpdate(ctx, compact.GetNamespace(), &compact, opt.Build())
+		stop := profile.FromContext(ctx).Start("render")
+		objs := opt.Build()
+		stop()
+		if err := r.handler.ApplyImagePolicy(ctx, objs); err != nil {
+			return err
+		}
+		if err := r.handler.CoordinateRollout(ctx, &compact, objs); err != nil {
+			return err
+		}
+		errCount += r.handler.CreateOrUpdate(ctx, compact.GetNamespace(), &compact, objs)
 	}
 
 	if errCount > 0 {
 		return fmt.Errorf("failed to create or update %d resources for compact or compact shard(s)", errCount)
 	}
 
+	if errCount = r.syncBlockMarkers(ctx, compact); errCount > 0 {
+		return fmt.Errorf("failed to create or update %d resources for block markers", errCount)
+	}
+
 	if !manifests.HasServiceMonitorEnabled(compact.Spec.FeatureGates) {
 		objs := make([]client.Object, len(expectResources))
 		for i, resource := range expectResources {
@@ -157,6 +346,113 @@ func (r *ThanosCompactReconciler) syncResources(ctx context.Context, compact mon
 	return nil
 }
 
+// syncBlockMarkers creates a Job for each confirmed block marker and deletes the Jobs of the markers removed from the spec.
+// Jobs are never updated once created, so each marker is applied exactly once.
+func (r *ThanosCompactReconciler) syncBlockMarkers(ctx context.Context, compact monitoringthanosiov1alpha1.ThanosCompact) int {
+	var errCount int
+	expect := make([]string, 0, len(compact.Spec.BlockMarkers))
+	for _, marker := range compact.Spec.BlockMarkers {
+		expect = append(expect, BlockMarkerJobNameFromParent(compact.GetName(), marker.Name))
+		if !marker.Confirm {
+			continue
+		}
+
+		opts := compactBlockMarkerToToolsOptions(compact, marker)
+		errCount += r.handler.CreateOrUpdate(ctx, compact.GetNamespace(), &compact, opts.Build())
+	}
+	return errCount + r.pruneBlockMarkers(ctx, compact, expect)
+}
+
+// pruneBlockMarkers deletes the Jobs and ServiceAccounts of the block markers of the ThanosCompact which are not expected.
+// Jobs of same-named ThanosTools resources carry the same labels, so only the objects controlled by the ThanosCompact are deleted.
+func (r *ThanosCompactReconciler) pruneBlockMarkers(ctx context.Context, compact monitoringthanosiov1alpha1.ThanosCompact, expect []string) int {
+	var errCount int
+	listOpts := []client.ListOption{
+		manifests.GetLabelSelectorForOwner(manifeststools.Options{Options: manifests.Options{Owner: compact.GetName()}}),
+		client.InNamespace(compact.GetNamespace()),
+	}
+
+	var stale []client.Object
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, listOpts...); err != nil {
+		r.logger.Error(err, "failed to list block marker jobs")
+		errCount++
+	}
+	for _, job := range jobs.Items {
+		if metav1.IsControlledBy(&job, &compact) && !slices.Contains(expect, job.GetName()) {
+			stale = append(stale, &job)
+		}
+	}
+	accounts := &corev1.ServiceAccountList{}
+	if err := r.List(ctx, accounts, listOpts...); err != nil {
+		r.logger.Error(err, "failed to list block marker service accounts")
+		errCount++
+	}
+	for _, sa := range accounts.Items {
+		if metav1.IsControlledBy(&sa, &compact) && !slices.Contains(expect, sa.GetName()) {
+			stale = append(stale, &sa)
+		}
+	}
+
+	for _, obj := range stale {
+		// Jobs orphan their pods by default
+		if err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			r.logger.Error(err, "failed to delete block marker resource", "name", obj.GetName())
+			errCount++
+		}
+	}
+	return errCount
+}
+
+// updateBlockMarkerStatus reports the state of the Jobs applying the block markers in the status of the ThanosCompact.
+// Markers awaiting confirmation are recorded in an event when they are added, and the outcome of their Jobs once they finish.
+func (r *ThanosCompactReconciler) updateBlockMarkerStatus(ctx context.Context, compact *monitoringthanosiov1alpha1.ThanosCompact) error {
+	previous := make(map[string]monitoringthanosiov1alpha1.BlockMarkerStatus, len(compact.Status.BlockMarkers))
+	for _, status := range compact.Status.BlockMarkers {
+		previous[status.Name] = status
+	}
+
+	var statuses []monitoringthanosiov1alpha1.BlockMarkerStatus
+	for _, marker := range compact.Spec.BlockMarkers {
+		status := monitoringthanosiov1alpha1.BlockMarkerStatus{Name: marker.Name, Message: "Block marker is awaiting confirmation"}
+		if marker.Confirm {
+			name := BlockMarkerJobNameFromParent(compact.GetName(), marker.Name)
+			job := &batchv1.Job{}
+			err := r.Get(ctx, client.ObjectKey{Namespace: compact.GetNamespace(), Name: name}, job)
+			switch {
+			case apierrors.IsNotFound(err):
+				status.Phase = monitoringthanosiov1alpha1.ThanosToolsPending
+				status.Message = fmt.Sprintf("Job %s has not been created yet", name)
+			case err != nil:
+				return fmt.Errorf("failed to get job %s: %w", name, err)
+			default:
+				status.JobName = name
+				status.Phase, status.Message = jobPhase(job)
+			}
+		}
+
+		prev, seen := previous[marker.Name]
+		switch {
+		case !seen && !marker.Confirm:
+			r.recorder.Event(compact, corev1.EventTypeNormal, "BlockMarkerPendingConfirmation",
+				fmt.Sprintf("Block marker %s for %d block(s) is awaiting confirmation", marker.Name, len(marker.BlockIDs)))
+		case prev.Phase != status.Phase && status.Phase == monitoringthanosiov1alpha1.ThanosToolsSucceeded:
+			r.recorder.Event(compact, corev1.EventTypeNormal, "BlockMarkerApplied",
+				fmt.Sprintf("Block marker %s applied to %d block(s)", marker.Name, len(marker.BlockIDs)))
+		case prev.Phase != status.Phase && status.Phase == monitoringthanosiov1alpha1.ThanosToolsFailed:
+			r.recorder.Event(compact, corev1.EventTypeWarning, "BlockMarkerFailed",
+				fmt.Sprintf("Block marker %s failed: %s", marker.Name, status.Message))
+		}
+		statuses = append(statuses, status)
+	}
+
+	if equality.Semantic.DeepEqual(compact.Status.BlockMarkers, statuses) {
+		return nil
+	}
+	compact.Status.BlockMarkers = statuses
+	return r.Status().Update(ctx, compact)
+}
+
 func (r *ThanosCompactReconciler) pruneOrphanedResources(ctx context.Context, ns, owner string, expectShards []string) int {
 	listOpt := manifests.GetLabelSelectorForOwner(manifestcompact.Options{Options: manifests.Options{Owner: owner}})
 	listOpts := []client.ListOption{listOpt, client.InNamespace(ns)}
@@ -165,26 +461,23 @@ func (r *ThanosCompactReconciler) pruneOrphanedResources(ctx context.Context, ns
 	return pruner.Prune(ctx, expectShards, listOpts...)
 }
 
-func (r *ThanosCompactReconciler) specToOptions(compact monitoringthanosiov1alpha1.ThanosCompact) []manifests.Buildable {
-	if compact.Spec.ShardingConfig == nil || compact.Spec.ShardingConfig.ExternalLabelSharding == nil {
-		return []manifests.Buildable{compactV1Alpha1ToOptions(compact)}
+func (r *ThanosCompactReconciler) specToOptions(ctx context.Context, compact monitoringthanosiov1alpha1.ThanosCompact, suspended bool) ([]manifests.Buildable, error) {
+	ingressEnabled, err := stackIngressEnabled(ctx, r.Client, &compact)
+	if err != nil {
+		return nil, err
 	}
 
 	var buildable []manifests.Buildable
-	for _, shard := range compact.Spec.ShardingConfig.ExternalLabelSharding {
-		for i, v := range shard.Values {
-			opts := compactV1Alpha1ToOptions(compact)
-			opts.ShardName = ptr.To(shard.ShardName)
-			opts.ShardIndex = ptr.To(i)
-			opts.RelabelConfigs = manifests.RelabelConfigs{
-				{
-					Action:      "keep",
-					SourceLabel: shard.Label,
-					Regex:       v,
-				},
-			}
-			buildable = append(buildable, opts)
+	for _, opts := range compactV1Alpha1ToShardOptions(compact) {
+		if ingressEnabled {
+			opts.RoutePrefix = compactRoutePrefix(opts)
 		}
+		opts.Suspended = suspended
+		buildable = append(buildable, opts)
+	}
+	if opts := compactV1Alpha1ToDownsamplerOptions(compact); opts != nil {
+		opts.Suspended = suspended
+		buildable = append(buildable, *opts)
 	}
-	return buildable
+	return buildable, nil
 }
@@ -19,18 +19,21 @@ package controller
 import (
 	"context"
 	"os"
+	"slices"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests/compact"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests/compact"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
@@ -118,10 +121,12 @@ config:
 					},
 					StorageSize: "1Gi",
 					ObjectStorageConfig: monitoringthanosiov1alpha1.ObjectStorageConfig{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: "thanos-objstore",
+						SecretKeySelector: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: "thanos-objstore",
+							},
+							Key: "thanos.yaml",
 						},
-						Key: "thanos.yaml",
 					},
 					Additional: monitoringthanosiov1alpha1.Additional{
 						Containers: []corev1.Container{
@@ -184,6 +189,63 @@ config:
 				}, time.Second*10, time.Second*2).Should(BeTrue())
 			})
 
+			By("configuring the ServiceAccount of the compactors", func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.ServiceAccount = &monitoringthanosiov1alpha1.ServiceAccount{
+					Annotations: map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/thanos"},
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				Eventually(func() bool {
+					sa := &corev1.ServiceAccount{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: shardOne, Namespace: ns}, sa); err != nil {
+						return false
+					}
+					return sa.GetAnnotations()["eks.amazonaws.com/role-arn"] == "arn:aws:iam::123456789012:role/thanos"
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.ServiceAccount = &monitoringthanosiov1alpha1.ServiceAccount{Name: ptr.To("existing")}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				Eventually(func() bool {
+					statefulSet := &appsv1.StatefulSet{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: shardOne, Namespace: ns}, statefulSet); err != nil {
+						return false
+					}
+					return statefulSet.Spec.Template.Spec.ServiceAccountName == "existing"
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.ServiceAccount = nil
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+			})
+
+			By("running downsampling in a separate worker", func() {
+				downsampler := compact.Options{Options: manifests.Options{Owner: resourceName}, Downsampler: true}.GetGeneratedResourceName()
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.DownsamplingConfig = &monitoringthanosiov1alpha1.DownsamplingConfig{
+					Concurrency: ptr.To(int32(4)),
+					Worker:      &monitoringthanosiov1alpha1.DownsamplingWorker{},
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				Eventually(func() bool {
+					return utils.VerifyStatefulSetArgs(k8sClient, downsampler, ns, 0, "--downsample.concurrency=4") &&
+						utils.VerifyStatefulSetArgs(k8sClient, shardOne, ns, 0, "--downsampling.disable") &&
+						utils.VerifyStatefulSetArgs(k8sClient, shardTwo, ns, 0, "--downsampling.disable")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.DownsamplingConfig = nil
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				Eventually(func() bool {
+					return utils.VerifyStatefulSetExists(k8sClient, downsampler, ns)
+				}, time.Second*10, time.Second*2).Should(BeFalse())
+			})
+
 			By("removing service monitor when disabled", func() {
 				for _, shard := range []string{shardOne, shardTwo} {
 					Expect(utils.VerifyServiceMonitorExists(k8sClient, shard, ns)).To(BeTrue())
@@ -206,6 +268,64 @@ config:
 				}, time.Minute*1, time.Second*10).Should(BeFalse())
 			})
 
+			By("running a job for confirmed block markers only", func() {
+				resource.Spec.BlockMarkers = []monitoringthanosiov1alpha1.BlockMarker{
+					{
+						Name:     "confirmed",
+						Marker:   monitoringthanosiov1alpha1.DeletionMarker,
+						BlockIDs: []string{"01JBS8GGM7BEZRPEAQ1J39DKQ4"},
+						Details:  "corrupted block",
+						Confirm:  true,
+					},
+					{
+						Name:     "unconfirmed",
+						Marker:   monitoringthanosiov1alpha1.NoCompactMarker,
+						BlockIDs: []string{"01JBS8GGM7BEZRPEAQ1J39DKQ5"},
+						Details:  "halts compaction",
+					},
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				confirmed := BlockMarkerJobNameFromParent(resourceName, "confirmed")
+				EventuallyWithOffset(1, func() bool {
+					job := &batchv1.Job{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: confirmed, Namespace: ns}, job); err != nil {
+						return false
+					}
+					args := job.Spec.Template.Spec.Containers[0].Args
+					return slices.Contains(args, "--marker=deletion-mark.json") &&
+						slices.Contains(args, "--id=01JBS8GGM7BEZRPEAQ1J39DKQ4")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				unconfirmed := BlockMarkerJobNameFromParent(resourceName, "unconfirmed")
+				ConsistentlyWithOffset(1, func() bool {
+					job := &batchv1.Job{}
+					return k8sClient.Get(ctx, types.NamespacedName{Name: unconfirmed, Namespace: ns}, job) == nil
+				}, time.Second*5, time.Second*1).Should(BeFalse())
+
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					statuses := resource.Status.BlockMarkers
+					return len(statuses) == 2 &&
+						statuses[0].Name == "confirmed" && statuses[0].JobName == confirmed && statuses[0].Phase != "" &&
+						statuses[1].Name == "unconfirmed" && statuses[1].JobName == "" && statuses[1].Phase == ""
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("deleting the job of a removed block marker", func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.BlockMarkers = resource.Spec.BlockMarkers[1:]
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				confirmed := BlockMarkerJobNameFromParent(resourceName, "confirmed")
+				EventuallyWithOffset(1, func() bool {
+					job := &batchv1.Job{}
+					return apierrors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: confirmed, Namespace: ns}, job))
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
 			By("ensuring old shards are cleaned up", func() {
 				resource.Spec.ShardingConfig = nil
 				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
@@ -225,6 +345,44 @@ config:
 					return verifier.Verify(k8sClient, name, ns)
 				}, time.Second*10, time.Second*2).Should(BeTrue())
 			})
+
+			By("scaling to zero outside of the compaction window", func() {
+				name := compact.Options{Options: manifests.Options{Owner: resourceName}}.GetGeneratedResourceName()
+				replicas := func() int32 {
+					sts := &appsv1.StatefulSet{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, sts); err != nil {
+						return -1
+					}
+					return *sts.Spec.Replicas
+				}
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.Schedule = &monitoringthanosiov1alpha1.CompactSchedule{
+					Windows: []monitoringthanosiov1alpha1.CompactWindow{
+						{Start: "0 0 29 2 *", Duration: "1m"},
+					},
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					return replicas() == 0 &&
+						resource.Status.Schedule != nil &&
+						!resource.Status.Schedule.Active &&
+						resource.Status.Schedule.NextTransition != nil
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				resource.Spec.Schedule = nil
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					return replicas() == 1 && resource.Status.Schedule == nil
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
 		})
 	})
 })
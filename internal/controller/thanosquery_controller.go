@@ -18,33 +18,58 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/endpointevents"
 	"github.com/thanos-community/thanos-operator/internal/pkg/handlers"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestquery "github.com/thanos-community/thanos-operator/internal/pkg/manifests/query"
-	manifestsstore "github.com/thanos-community/thanos-operator/internal/pkg/manifests/store"
 	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
+	"github.com/thanos-community/thanos-operator/internal/pkg/profile"
+	"github.com/thanos-community/thanos-operator/internal/pkg/querystatus"
+	"github.com/thanos-community/thanos-operator/internal/pkg/rollback"
+	"github.com/thanos-community/thanos-operator/internal/pkg/versionpolicy"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestcompact "github.com/thanos-community/thanos-operator/pkg/manifests/compact"
+	manifestsmemcached "github.com/thanos-community/thanos-operator/pkg/manifests/memcached"
+	manifestquery "github.com/thanos-community/thanos-operator/pkg/manifests/query"
+	manifestqueryfrontend "github.com/thanos-community/thanos-operator/pkg/manifests/queryfrontend"
+	manifestruler "github.com/thanos-community/thanos-operator/pkg/manifests/ruler"
+	manifestsstore "github.com/thanos-community/thanos-operator/pkg/manifests/store"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -55,38 +80,60 @@ type ThanosQueryReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	logger   logr.Logger
-	metrics  controllermetrics.ThanosQueryMetrics
-	recorder record.EventRecorder
+	logger           logr.Logger
+	metrics          controllermetrics.ThanosQueryMetrics
+	reconcileMetrics *controllermetrics.ReconcileMetrics
+	recorder         record.EventRecorder
 
-	handler *handlers.Handler
+	handler          *handlers.Handler
+	versionPolicy    *versionpolicy.Policy
+	queryStatus      *querystatus.Client
+	endpointEvents   *endpointevents.Tracker
+	controllerConfig ControllerConfig
 }
 
 // NewThanosQueryReconciler returns a reconciler for ThanosQuery resources.
 func NewThanosQueryReconciler(conf Config, client client.Client, scheme *runtime.Scheme) *ThanosQueryReconciler {
 	handler := handlers.NewHandler(client, scheme, conf.InstrumentationConfig.Logger)
+	handler.SetLogSampler(conf.InstrumentationConfig.LogSampler)
 	featureGates := conf.FeatureGate.ToGVK()
 	if len(featureGates) > 0 {
 		handler.SetFeatureGates(featureGates)
 	}
+	handler.SetImagePolicy(conf.ImagePolicy)
+	handler.SetMaintenance(conf.Maintenance)
+	handler.SetApplyRetryBudget(conf.ApplyRetryBudget)
+	handler.SetWriteLimit(conf.WriteLimit, conf.WriteLimitWindow)
+	handler.SetForbiddenProbeInterval(conf.ForbiddenProbeInterval)
 
 	return &ThanosQueryReconciler{
-		Client:   client,
-		Scheme:   scheme,
-		logger:   conf.InstrumentationConfig.Logger,
-		metrics:  controllermetrics.NewThanosQueryMetrics(conf.InstrumentationConfig.MetricsRegistry),
-		recorder: conf.InstrumentationConfig.EventRecorder,
-		handler:  handler,
+		Client:           client,
+		Scheme:           scheme,
+		logger:           conf.InstrumentationConfig.Logger,
+		metrics:          controllermetrics.NewThanosQueryMetrics(conf.InstrumentationConfig.MetricsRegistry),
+		reconcileMetrics: conf.InstrumentationConfig.ReconcileMetrics,
+		recorder:         conf.InstrumentationConfig.EventRecorder,
+		handler:          handler,
+		controllerConfig: conf.Controller,
+		versionPolicy:    conf.VersionPolicy,
+		queryStatus:      querystatus.NewClient(&http.Client{Timeout: 10 * time.Second}),
+		endpointEvents:   endpointevents.NewTracker(endpointEventWindow),
 	}
 }
 
 //+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosqueries,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosqueries/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosqueries/finalizers,verbs=update
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services;configmaps;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosstores;thanosreceives;thanoscompacts;thanosrulers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -99,12 +146,21 @@ func (r *ThanosQueryReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			r.logger.Info("thanos query resource not found. ignoring since object may be deleted")
+			r.endpointEvents.Forget(req.String())
+			r.deleteMetrics(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		r.logger.Error(err, "failed to get ThanosQuery")
 		r.recorder.Event(query, corev1.EventTypeWarning, "GetFailed", "Failed to get ThanosQuery resource")
 		return ctrl.Result{}, err
 	}
+	start := time.Now()
+	defer func() { r.reconcileMetrics.ObserveApply(r.handler.LogApplySummary(query, start)) }()
+	ctx, prof := startProfiling(ctx, query.Spec.FeatureGates)
+	defer recordProfile(r.recorder, r.reconcileMetrics, query, prof)
+
+	var reconcileErr error
+	defer func() { r.updateStatus(ctx, query, reconcileErr) }()
 
 	if query.Spec.Paused != nil && *query.Spec.Paused {
 		r.logger.Info("reconciliation is paused for ThanosQuery resource")
@@ -112,68 +168,719 @@ func (r *ThanosQueryReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
-	err = r.syncResources(ctx, *query)
+	additional := []monitoringthanosiov1alpha1.Additional{query.Spec.Additional}
+	if query.Spec.QueryFrontend != nil {
+		additional = append(additional, query.Spec.QueryFrontend.Additional)
+	}
+	if err := validateAdditional(additional...); err != nil {
+		r.logger.Error(err, "invalid additional configuration for ThanosQuery")
+		r.recorder.Event(query, corev1.EventTypeWarning, "InvalidSpec", fmt.Sprintf("Invalid additional configuration: %v", err))
+		reconcileErr = fmt.Errorf("%w: %w", errInvalidSpec, err)
+		return ctrl.Result{}, nil
+	}
+	if err := validateReplicaLabels(*query); err != nil {
+		r.logger.Error(err, "invalid replica labels for ThanosQuery")
+		r.recorder.Event(query, corev1.EventTypeWarning, "InvalidSpec", fmt.Sprintf("Invalid replica labels: %v", err))
+		reconcileErr = fmt.Errorf("%w: %w", errInvalidSpec, err)
+		return ctrl.Result{}, nil
+	}
+	if err := validateQueryFrontendLimits(*query); err != nil {
+		r.logger.Error(err, "invalid query frontend limits for ThanosQuery")
+		r.recorder.Event(query, corev1.EventTypeWarning, "InvalidSpec", fmt.Sprintf("Invalid query frontend limits: %v", err))
+		reconcileErr = fmt.Errorf("%w: %w", errInvalidSpec, err)
+		return ctrl.Result{}, nil
+	}
+
+	if err := validateQueryPools(*query); err != nil {
+		r.logger.Error(err, "invalid query pools for ThanosQuery")
+		r.recorder.Event(query, corev1.EventTypeWarning, "InvalidSpec", fmt.Sprintf("Invalid query pools: %v", err))
+		reconcileErr = fmt.Errorf("%w: %w", errInvalidSpec, err)
+		return ctrl.Result{}, nil
+	}
+
+	opts := []manifests.Options{queryV1Alpha1ToOptions(*query).Options}
+	if query.Spec.QueryFrontend != nil {
+		opts = append(opts, queryV1Alpha1ToQueryFrontEndOptions(*query).Options)
+	}
+	if err := checkOptions(ctx, r.Client, r.recorder, query, &query.Status.Conditions, opts...); err != nil {
+		r.logger.Error(err, "invalid options for ThanosQuery")
+		reconcileErr = err
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := checkVersions(ctx, r.Client, r.recorder, query, &query.Status.Conditions, r.versionPolicy, queryVersions(*query)); err != nil {
+		r.logger.Error(err, "failed to check versions of ThanosQuery")
+		reconcileErr = err
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	err = r.syncResources(ctx, query)
+	reconcileErr = err
+	if blockedErr := r.handler.ApplyBlocked(query); blockedErr != nil {
+		err = blockedErr
+		reconcileErr = err
+	}
+	requeueAfter, throttledErr := r.handler.ApplyThrottled(query)
+	if err == nil && throttledErr != nil {
+		err = throttledErr
+		reconcileErr = err
+	}
+	probeAfter, permissionsErr := r.handler.MissingPermissions(query)
+	if statusErr := updateBlockedCondition(ctx, r.Client, query, &query.Status.Conditions, err); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update blocked condition")
+	}
+	if statusErr := updateMissingPermissionsCondition(ctx, r.Client, query, &query.Status.Conditions, permissionsErr); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update missing permissions condition")
+	}
+	if errors.Is(err, handlers.ErrRolloutDeferred) {
+		r.recorder.Event(query, corev1.EventTypeNormal, "RolloutDeferred", err.Error())
+		return ctrl.Result{RequeueAfter: rolloutDeferredRequeueInterval}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyThrottled) {
+		r.recorder.Event(query, corev1.EventTypeNormal, "ApplyThrottled", err.Error())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyBlocked) {
+		// retrying will not help until the spec or the reconcile-now annotation changes, which triggers a new reconciliation
+		r.recorder.Event(query, corev1.EventTypeWarning, "ApplyBlocked", err.Error())
+		return ctrl.Result{}, nil
+	}
 	if err != nil {
 		r.recorder.Event(query, corev1.EventTypeWarning, "SyncFailed", fmt.Sprintf("Failed to sync resources: %v", err))
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	if err := r.handler.CompleteRollout(ctx, query); err != nil {
+		r.logger.Error(err, "failed to complete rollout")
+		reconcileErr = err
+		return ctrl.Result{}, err
+	}
+
+	if permissionsErr != nil {
+		// the objects are applied again at the next probe, retrying sooner would only be denied again
+		r.recorder.Event(query, corev1.EventTypeWarning, "MissingPermissions", permissionsErr.Error())
+		reconcileErr = permissionsErr
+		return ctrl.Result{RequeueAfter: probeAfter}, nil
+	}
+
+	if err := updateCrashLoopingCondition(ctx, r.Client, r.recorder, query, &query.Status.Conditions); err != nil {
+		r.logger.Error(err, "failed to update crash looping condition")
+	}
+
+	if changes, ok := r.endpointEvents.Flush(req.String()); ok {
+		r.recordEndpointChanges(query, changes)
+	}
+
+	if err := r.updateEndpointStatus(ctx, query); err != nil {
+		r.logger.Error(err, "failed to update endpoint status")
+		reconcileErr = err
+		return ctrl.Result{}, err
+	}
+	// the statistics are informational, failing to sample them does not fail the reconciliation
+	r.updateFrontendStatus(ctx, query)
+	// the endpoints of the Querier and the statistics of the Query Frontend are sampled on each requeue
+	return ctrl.Result{RequeueAfter: endpointStatusInterval}, nil
+}
+
+// updateStatus reports the observed generation, the effective defaults, the replicas of the Querier Deployment and the
+// Available, Degraded, Reconciled and Paused conditions in the status of the ThanosQuery, given the outcome of the reconciliation.
+// The status is only written if it changed.
+func (r *ThanosQueryReconciler) updateStatus(ctx context.Context, query *monitoringthanosiov1alpha1.ThanosQuery, reconcileErr error) {
+	previous := query.Status.DeepCopy()
+	generation := query.GetGeneration()
+	paused := ptr.Deref(query.Spec.Paused, false)
+
+	deployment := &appsv1.Deployment{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: query.GetNamespace(), Name: QueryNameFromParent(query.GetName())}, deployment)
+	switch {
+	case apierrors.IsNotFound(err):
+		deployment = nil
+	case err != nil:
+		r.logger.Error(err, "failed to get Deployment for status")
+		return
+	}
+
+	progress, err := stackUpgradeProgress(ctx, r.Client, query)
+	if err != nil {
+		r.logger.Error(err, "failed to get upgrade progress of stack for status")
+		return
+	}
+	problems, err := podProblems(ctx, r.Client, query)
+	if err != nil {
+		r.logger.Error(err, "failed to get pod problems for status")
+		return
+	}
+
+	query.Status.ObservedGeneration = generation
+	query.Status.Stack = stackComponentStatuses(progress)
+	query.Status.Defaults = effectiveDefaultsFromOpts(queryV1Alpha1ToOptions(*query).Options)
+	query.Status.Replicas, query.Status.ReadyReplicas, query.Status.UpdatedReplicas, query.Status.AvailableReplicas = 0, 0, 0, 0
+	if deployment != nil {
+		query.Status.Replicas = deployment.Status.Replicas
+		query.Status.ReadyReplicas = deployment.Status.ReadyReplicas
+		query.Status.UpdatedReplicas = deployment.Status.UpdatedReplicas
+		query.Status.AvailableReplicas = deployment.Status.AvailableReplicas
+	}
+	setDeploymentConditions(&query.Status.Conditions, generation, deployment, problems)
+	setReconciledCondition(&query.Status.Conditions, generation, paused, reconcileErr)
+	setPausedCondition(&query.Status.Conditions, generation, paused)
+
+	if equality.Semantic.DeepEqual(previous, &query.Status) {
+		return
+	}
+	if err := r.Status().Update(ctx, query); err != nil {
+		r.logger.Error(err, "failed to update status")
+	}
+}
+
+// updateEndpointStatus reports the health of the Store API endpoints of the Querier in the status of the ThanosQuery.
+// The status is only written if it changed.
+func (r *ThanosQueryReconciler) updateEndpointStatus(ctx context.Context, query *monitoringthanosiov1alpha1.ThanosQuery) error {
+	var (
+		changed   bool
+		endpoints []monitoringthanosiov1alpha1.EndpointStatus
+	)
+
+	if hasExternalDownstream(*query) {
+		changed = meta.RemoveStatusCondition(&query.Status.Conditions, monitoringthanosiov1alpha1.ConditionEndpointsHealthy)
+	} else {
+		condition := metav1.Condition{
+			Type:               monitoringthanosiov1alpha1.ConditionEndpointsHealthy,
+			ObservedGeneration: query.GetGeneration(),
+		}
+
+		baseURL := queryURL(*query)
+		found, err := r.queryStatus.Endpoints(ctx, baseURL)
+		if err != nil {
+			condition.Status = metav1.ConditionUnknown
+			condition.Reason = reasonQueryUnreachable
+			condition.Message = fmt.Sprintf("Failed to get endpoint status from the Querier: %v", err)
+			// keep the last observed endpoints rather than reporting them as gone
+			endpoints = query.Status.Endpoints
+		} else {
+			var down []string
+			for _, e := range found {
+				endpoints = append(endpoints, monitoringthanosiov1alpha1.EndpointStatus{
+					Name:      e.Name,
+					Type:      e.Type,
+					Up:        e.Up(),
+					LastError: e.LastError,
+				})
+				if !e.Up() {
+					down = append(down, e.Name)
+				}
+			}
+
+			switch {
+			case len(found) == 0:
+				condition.Status = metav1.ConditionFalse
+				condition.Reason = reasonNoEndpoints
+				condition.Message = "The Querier is not connected to any endpoint"
+			case len(down) > 0:
+				condition.Status = metav1.ConditionFalse
+				condition.Reason = reasonEndpointsDown
+				condition.Message = fmt.Sprintf("%d of %d endpoints are down: %s", len(down), len(found), strings.Join(down, ", "))
+			default:
+				condition.Status = metav1.ConditionTrue
+				condition.Reason = reasonAllEndpointsUp
+				condition.Message = fmt.Sprintf("All %d endpoints are up", len(found))
+			}
+
+			if unused := unusedReplicaLabels(*query, found); len(unused) > 0 {
+				r.recorder.Event(query, corev1.EventTypeWarning, "ReplicaLabelUnused",
+					fmt.Sprintf("Replica labels are not set on any endpoint of the Querier: %s", strings.Join(unused, ", ")))
+			}
+		}
+		changed = meta.SetStatusCondition(&query.Status.Conditions, condition)
+	}
+
+	if !equality.Semantic.DeepEqual(query.Status.Endpoints, endpoints) {
+		query.Status.Endpoints = endpoints
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return r.Status().Update(ctx, query)
 }
 
-func (r *ThanosQueryReconciler) syncResources(ctx context.Context, query monitoringthanosiov1alpha1.ThanosQuery) error {
+// updateFrontendStatus samples the statistics of the ready instances of the Query Frontend and reports their sum
+// in the status and the metrics of the ThanosQuery. The status is only written if it changed.
+func (r *ThanosQueryReconciler) updateFrontendStatus(ctx context.Context, query *monitoringthanosiov1alpha1.ThanosQuery) {
+	labels := prometheus.Labels{"resource": query.GetName(), "namespace": query.GetNamespace()}
+	var status *monitoringthanosiov1alpha1.QueryFrontendStatus
+	if query.Spec.QueryFrontend == nil {
+		r.metrics.FrontendCacheHitRatio.Delete(labels)
+		r.metrics.FrontendInflightRequests.Delete(labels)
+	} else {
+		opts := queryV1Alpha1ToQueryFrontEndOptions(*query)
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(query.GetNamespace()), client.MatchingLabels(opts.GetSelectorLabels())); err != nil {
+			r.logger.Error(err, "failed to list Query Frontend pods for status")
+			return
+		}
+
+		var (
+			stats   querystatus.FrontendStats
+			sampled int32
+		)
+		for _, pod := range pods.Items {
+			if pod.Status.PodIP == "" || !isReady(pod) {
+				continue
+			}
+			podStats, err := r.queryStatus.FrontendStats(ctx, fmt.Sprintf("http://%s", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(opts.GetHTTPPort(manifestqueryfrontend.HTTPPort))))))
+			if err != nil {
+				r.logger.V(1).Info("failed to sample Query Frontend statistics", "pod", pod.GetName(), "error", err.Error())
+				continue
+			}
+			stats = stats.Add(podStats)
+			sampled++
+		}
+
+		if sampled > 0 {
+			status = &monitoringthanosiov1alpha1.QueryFrontendStatus{
+				SampledReplicas:  sampled,
+				InflightRequests: int64(stats.InflightRequests),
+				Queries:          int64(stats.Queries),
+			}
+			if ratio, ok := stats.CacheHitRatio(); ok {
+				status.CacheHitPercent = ptr.To(int32(math.Round(ratio * 100)))
+				r.metrics.FrontendCacheHitRatio.With(labels).Set(ratio)
+			}
+			r.metrics.FrontendInflightRequests.With(labels).Set(stats.InflightRequests)
+		}
+	}
+
+	if equality.Semantic.DeepEqual(query.Status.QueryFrontend, status) {
+		return
+	}
+	query.Status.QueryFrontend = status
+	if err := r.Status().Update(ctx, query); err != nil {
+		r.logger.Error(err, "failed to update query frontend status")
+	}
+}
+
+// isReady returns true if the pod is ready.
+func isReady(pod corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (r *ThanosQueryReconciler) syncResources(ctx context.Context, query *monitoringthanosiov1alpha1.ThanosQuery) error {
 	var objs []client.Object
 
-	querierObjs, err := r.buildQuery(ctx, query)
+	progress, err := stackUpgradeProgress(ctx, r.Client, query)
 	if err != nil {
 		return err
 	}
+	if !hasExternalDownstream(*query) {
+		if err := deferredUpgrade(progress, "ThanosQuery", query.GetName(), versionPolicyQuery); err != nil {
+			return err
+		}
+	}
+	frontendDeferred := deferredUpgrade(progress, "ThanosQuery", query.GetName(), versionPolicyQueryFrontend)
+
+	if hasExternalDownstream(*query) {
+		// the frontend is a standalone caching layer for an external Query API, so we clean up any querier we own
+		if errCount := r.handler.DeleteResource(ctx, r.querierResources(*query)); errCount > 0 {
+			return fmt.Errorf("failed to delete %d resources for the querier", errCount)
+		}
+	} else {
+		querierObjs, err := r.buildQuery(ctx, *query)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, querierObjs...)
+	}
 
-	objs = append(objs, querierObjs...)
+	if err := r.syncManagedMemcached(ctx, *query); err != nil {
+		return err
+	}
 
 	if query.Spec.QueryFrontend != nil {
-		r.recorder.Event(&query, corev1.EventTypeNormal, "BuildingQueryFrontend", "Building Query Frontend resources")
-		frontendObjs := r.buildQueryFrontend(query)
+		r.recorder.Event(query, corev1.EventTypeNormal, "BuildingQueryFrontend", "Building Query Frontend resources")
+		frontendObjs := r.buildQueryFrontend(*query)
+		if frontendDeferred != nil {
+			// the frontend keeps running its current version until the Queriers of the stack are upgraded
+			frontendObjs = slices.DeleteFunc(frontendObjs, func(obj client.Object) bool {
+				_, ok := obj.(*appsv1.Deployment)
+				return ok
+			})
+		}
 		objs = append(objs, frontendObjs...)
+	} else {
+		// the Query Frontend may have been removed from the spec, so we clean up any Query Frontend we own
+		if errCount := r.handler.DeleteResource(ctx, r.queryFrontendResources(*query)); errCount > 0 {
+			return fmt.Errorf("failed to delete %d resources for the query frontend", errCount)
+		}
+	}
+
+	if query.Spec.GrafanaDatasource != nil {
+		objs = append(objs, r.buildGrafanaDatasource(*query))
+	} else {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: GrafanaDatasourceNameFromParent(query.GetName()), Namespace: query.GetNamespace()}}
+		if errCount := r.handler.DeleteResource(ctx, []client.Object{cm}); errCount > 0 {
+			return fmt.Errorf("failed to delete the grafana datasource")
+		}
+	}
+
+	if query.Spec.StackIngress != nil {
+		ingress, err := r.buildStackIngress(ctx, *query)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, ingress)
+	} else {
+		ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: QueryNameFromParent(query.GetName()), Namespace: query.GetNamespace()}}
+		if errCount := r.handler.DeleteResource(ctx, []client.Object{ingress}); errCount > 0 {
+			return fmt.Errorf("failed to delete the stack ingress")
+		}
+	}
+
+	if err := r.handler.ApplyImagePolicy(ctx, objs); err != nil {
+		return err
+	}
+
+	if query.Spec.Rollback != nil {
+		lkg, err := r.applyLastKnownGood(ctx, query, objs)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, lkg)
+	} else {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: LastKnownGoodNameFromParent(query.GetName()), Namespace: query.GetNamespace()}}
+		if errCount := r.handler.DeleteResource(ctx, []client.Object{cm}); errCount > 0 {
+			return fmt.Errorf("failed to delete the last known good state")
+		}
+		if meta.RemoveStatusCondition(&query.Status.Conditions, monitoringthanosiov1alpha1.ConditionRolledBack) {
+			if err := r.Status().Update(ctx, query); err != nil {
+				return fmt.Errorf("failed to update status: %w", err)
+			}
+		}
+	}
+
+	if err := r.handler.CoordinateRollout(ctx, query, objs); err != nil {
+		return err
 	}
 
 	var errCount int
-	if errCount := r.handler.CreateOrUpdate(ctx, query.GetNamespace(), &query, objs); errCount > 0 {
+	if errCount := r.handler.CreateOrUpdate(ctx, query.GetNamespace(), query, objs); errCount > 0 {
 		return fmt.Errorf("failed to create or update %d resources for the querier and query frontend", errCount)
 	}
 
+	var expectGroups, expectPools []string
+	if !hasExternalDownstream(*query) {
+		expectGroups = endpointGroupResourceNames(*query)
+		expectPools = queryPoolResourceNames(*query)
+	}
+	if errCount = r.pruneQueriers(ctx, *query, manifests.QueryEndpointGroupLabel, expectGroups); errCount > 0 {
+		return fmt.Errorf("failed to prune %d orphaned resources for the endpoint group querier(s)", errCount)
+	}
+	if errCount = r.pruneQueriers(ctx, *query, manifests.QueryPoolLabel, expectPools); errCount > 0 {
+		return fmt.Errorf("failed to prune %d orphaned resources for the query pool(s)", errCount)
+	}
+	if errCount = r.handler.DeleteResource(ctx, unneededQueryPodDisruptionBudgets(*query)); errCount > 0 {
+		return fmt.Errorf("failed to delete %d PodDisruptionBudgets for the querier and query frontend", errCount)
+	}
+	if errCount = r.handler.DeleteResource(ctx, unneededQueryHorizontalPodAutoscalers(*query)); errCount > 0 {
+		return fmt.Errorf("failed to delete %d HorizontalPodAutoscalers for the querier and query frontend", errCount)
+	}
+	if errCount = r.handler.DeleteResource(ctx, unneededQueryLogForwardingConfigMaps(*query)); errCount > 0 {
+		return fmt.Errorf("failed to delete %d log forwarding ConfigMaps for the querier and query frontend", errCount)
+	}
+
 	if !manifests.HasServiceMonitorEnabled(query.Spec.FeatureGates) {
-		if errCount = r.handler.DeleteResource(ctx, []client.Object{&monitoringv1.ServiceMonitor{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      manifestquery.Options{Options: manifests.Options{Owner: query.GetName()}}.GetGeneratedResourceName(),
-				Namespace: query.GetNamespace(),
-			},
-		},
-		}); errCount > 0 {
+		svcMonNames := append([]string{QueryNameFromParent(query.GetName()), QueryFrontendNameFromParent(query.GetName())}, expectGroups...)
+		svcMonNames = append(svcMonNames, expectPools...)
+		svcMons := make([]client.Object, len(svcMonNames))
+		for i, name := range svcMonNames {
+			svcMons[i] = &monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: query.GetNamespace()}}
+		}
+		if errCount = r.handler.DeleteResource(ctx, svcMons); errCount > 0 {
 			return fmt.Errorf("failed to delete %d resources for the querier and query frontend", errCount)
 		}
 	}
 
+	if frontendDeferred != nil {
+		// release the rollout lease of the stack once the Queriers are rolled out, as they may be what the frontend waits for
+		if err := r.handler.CompleteRollout(ctx, query); err != nil {
+			return err
+		}
+		return frontendDeferred
+	}
+	return nil
+}
+
+// syncManagedMemcached creates or updates the Memcached instances managed for the caches of the ThanosQuery
+// and deletes those that are no longer managed.
+func (r *ThanosQueryReconciler) syncManagedMemcached(ctx context.Context, query monitoringthanosiov1alpha1.ThanosQuery) error {
+	memcachedOpts := queryManagedMemcachedOptions(query)
+	expect := make([]string, len(memcachedOpts))
+	for i, opt := range memcachedOpts {
+		expect[i] = opt.GetGeneratedResourceName()
+		objs := opt.Build()
+		if err := r.handler.ApplyImagePolicy(ctx, objs); err != nil {
+			return err
+		}
+		if errCount := r.handler.CreateOrUpdate(ctx, query.GetNamespace(), &query, objs); errCount > 0 {
+			return fmt.Errorf("failed to create or update %d resources for managed memcached", errCount)
+		}
+	}
+
+	listOpts := []client.ListOption{
+		manifests.GetLabelSelectorForOwner(manifestsmemcached.Options{Owner: query.GetName()}),
+		client.InNamespace(query.GetNamespace()),
+	}
+	if errCount := r.handler.NewResourcePruner().WithStatefulSet().WithService().Prune(ctx, expect, listOpts...); errCount > 0 {
+		return fmt.Errorf("failed to prune %d resources for managed memcached", errCount)
+	}
 	return nil
 }
 
+// pruneQueriers deletes the resources of the endpoint group or query pool Queriers of the ThanosQuery,
+// identified by the given label, which are not expected.
+// Their log forwarding ConfigMaps are expected as long as log forwarding is enabled.
+func (r *ThanosQueryReconciler) pruneQueriers(ctx context.Context, query monitoringthanosiov1alpha1.ThanosQuery, label string, expect []string) int {
+	listOpts := []client.ListOption{
+		client.MatchingLabels{
+			manifests.NameLabel:  manifestquery.Name,
+			manifests.OwnerLabel: manifests.ValidateAndSanitizeNameToValidLabelValue(query.GetName()),
+		},
+		client.HasLabels{label},
+		client.InNamespace(query.GetNamespace()),
+	}
+
+	if query.Spec.LogForwarding != nil {
+		configMaps := make([]string, 0, len(expect))
+		for _, name := range expect {
+			configMaps = append(configMaps, manifests.LogForwardingConfigMapName(name))
+		}
+		expect = slices.Concat(expect, configMaps)
+	}
+
+	pruner := r.handler.NewResourcePruner().WithServiceAccount().WithService().WithDeployment().WithPodDisruptionBudget().WithServiceMonitor().WithConfigMap()
+	return pruner.Prune(ctx, expect, listOpts...)
+}
+
+// hasExternalDownstream returns true if the Query Frontend is configured to forward requests to an external Query API.
+func hasExternalDownstream(query monitoringthanosiov1alpha1.ThanosQuery) bool {
+	return query.Spec.QueryFrontend != nil && query.Spec.QueryFrontend.DownstreamURL != nil
+}
+
+// applyLastKnownGood records the Deployments of the ThanosQuery which are fully rolled out as the last known good state.
+// If the rollout of the current generation exceeded its progress deadline, the Deployments in objs are restored to
+// the last known good state and the RolledBack condition is set, until the ThanosQuery is updated.
+// It returns the ConfigMap persisting the last known good state.
+func (r *ThanosQueryReconciler) applyLastKnownGood(ctx context.Context, query *monitoringthanosiov1alpha1.ThanosQuery, objs []client.Object) (client.Object, error) {
+	name := LastKnownGoodNameFromParent(query.GetName())
+	lkg := rollback.LastKnownGood{}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: query.GetNamespace(), Name: name}, cm); err == nil {
+		if lkg, err = rollback.FromConfigMap(cm); err != nil {
+			return nil, err
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get last known good state: %w", err)
+	}
+
+	var failed []string
+	for _, obj := range objs {
+		deployment, ok := obj.(*appsv1.Deployment)
+		if !ok {
+			continue
+		}
+		deployment.Spec.ProgressDeadlineSeconds = ptr.To(query.Spec.Rollback.ProgressDeadlineSeconds)
+
+		current := &appsv1.Deployment{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: query.GetNamespace(), Name: deployment.GetName()}, current); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get deployment %s: %w", deployment.GetName(), err)
+		}
+
+		lkg.Record(current)
+		if rollback.HasFailed(current) {
+			failed = append(failed, current.GetName())
+		}
+	}
+
+	if isRolledBack(query.Status.Conditions, query.GetGeneration()) {
+		lkg.Restore(objs)
+	} else if len(failed) > 0 {
+		if restored := lkg.Restore(objs); len(restored) > 0 {
+			msg := fmt.Sprintf("Rollout of %s exceeded its progress deadline, rolled back %s to the last known good state",
+				strings.Join(failed, ", "), strings.Join(restored, ", "))
+			setRolledBackCondition(&query.Status.Conditions, query.GetGeneration(), msg)
+			if err := r.Status().Update(ctx, query); err != nil {
+				return nil, fmt.Errorf("failed to update status: %w", err)
+			}
+			r.recorder.Event(query, corev1.EventTypeWarning, "RolledBack", msg)
+		}
+	} else if meta.RemoveStatusCondition(&query.Status.Conditions, monitoringthanosiov1alpha1.ConditionRolledBack) {
+		if err := r.Status().Update(ctx, query); err != nil {
+			return nil, fmt.Errorf("failed to update status: %w", err)
+		}
+	}
+
+	labels := manifestquery.GetLabels(queryV1Alpha1ToOptions(*query))
+	return lkg.ConfigMap(name, query.GetNamespace(), labels)
+}
+
+// querierResources returns the objects that may have been created for the querier of the given ThanosQuery.
+func (r *ThanosQueryReconciler) querierResources(query monitoringthanosiov1alpha1.ThanosQuery) []client.Object {
+	objMeta := metav1.ObjectMeta{Name: QueryNameFromParent(query.GetName()), Namespace: query.GetNamespace()}
+	return []client.Object{
+		&appsv1.Deployment{ObjectMeta: objMeta},
+		&corev1.Service{ObjectMeta: objMeta},
+		&corev1.ServiceAccount{ObjectMeta: objMeta},
+		&policyv1.PodDisruptionBudget{ObjectMeta: objMeta},
+		&monitoringv1.ServiceMonitor{ObjectMeta: objMeta},
+	}
+}
+
+// queryFrontendResources returns the resources of the Query Frontend of the ThanosQuery.
+// Its PodDisruptionBudget and HorizontalPodAutoscaler are deleted with the other unneeded ones.
+func (r *ThanosQueryReconciler) queryFrontendResources(query monitoringthanosiov1alpha1.ThanosQuery) []client.Object {
+	objMeta := metav1.ObjectMeta{Name: QueryFrontendNameFromParent(query.GetName()), Namespace: query.GetNamespace()}
+	return []client.Object{
+		&appsv1.Deployment{ObjectMeta: objMeta},
+		&corev1.Service{ObjectMeta: objMeta},
+		&corev1.ServiceAccount{ObjectMeta: objMeta},
+		&monitoringv1.ServiceMonitor{ObjectMeta: objMeta},
+	}
+}
+
 func (r *ThanosQueryReconciler) buildQuery(ctx context.Context, query monitoringthanosiov1alpha1.ThanosQuery) ([]client.Object, error) {
-	endpoints, err := r.getStoreAPIServiceEndpoints(ctx, query)
+	endpoints, grouped, err := r.getStoreAPIServiceEndpoints(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	r.endpointEvents.Observe(client.ObjectKeyFromObject(&query).String(), endpointServiceNames(endpoints, grouped))
+	r.setEndpointsConfigured(query, endpoints, grouped)
+
+	defer profile.FromContext(ctx).Start("render")()
+	var groupObjs []client.Object
+	for _, group := range query.Spec.EndpointGroups {
+		groupOpts := queryEndpointGroupToOptions(query, group)
+		groupOpts.Endpoints = grouped[group.Name]
+		groupObjs = append(groupObjs, groupOpts.Build()...)
+
+		etype := manifests.RegularLabel
+		if group.Strict {
+			etype = manifests.StrictLabel
+		}
+		endpoints = append(endpoints, manifestquery.Endpoint{
+			ServiceName: groupOpts.GetGeneratedResourceName(),
+			Port:        groupOpts.GetGRPCPort(manifestquery.GRPCPort),
+			Namespace:   query.GetNamespace(),
+			Type:        etype,
+		})
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].ServiceName < endpoints[j].ServiceName
+	})
 
 	opts := queryV1Alpha1ToOptions(query)
 	opts.Endpoints = endpoints
+	objs := append(opts.Build(), groupObjs...)
+
+	for _, pool := range query.Spec.QueryPools {
+		poolOpts := queryPoolToOptions(query, pool)
+		poolOpts.Endpoints = endpoints
+		objs = append(objs, poolOpts.Build()...)
+	}
+	return objs, nil
+}
 
-	return opts.Build(), nil
+// endpointTypes are the types of the endpoints of a Querier, reported by the EndpointsConfigured metric.
+var endpointTypes = []manifests.EndpointType{
+	manifests.RegularLabel,
+	manifests.StrictLabel,
+	manifests.GroupLabel,
+	manifests.GroupStrictLabel,
+}
+
+// setEndpointsConfigured sets the number of StoreAPI endpoints discovered for the ThanosQuery per endpoint type.
+// Types without endpoints are set to zero, so that the series of the ThanosQuery are stable.
+func (r *ThanosQueryReconciler) setEndpointsConfigured(query monitoringthanosiov1alpha1.ThanosQuery, endpoints []manifestquery.Endpoint, grouped map[string][]manifestquery.Endpoint) {
+	counts := make(map[manifests.EndpointType]int, len(endpointTypes))
+	for _, ep := range endpoints {
+		counts[ep.Type]++
+	}
+	for _, eps := range grouped {
+		for _, ep := range eps {
+			counts[ep.Type]++
+		}
+	}
+	for _, etype := range endpointTypes {
+		r.metrics.EndpointsConfigured.WithLabelValues(string(etype), query.GetName(), query.GetNamespace()).Set(float64(counts[etype]))
+	}
+}
+
+// deleteMetrics deletes the series of the ThanosQuery with the given name, once it was deleted.
+func (r *ThanosQueryReconciler) deleteMetrics(name types.NamespacedName) {
+	labels := prometheus.Labels{"resource": name.Name, "namespace": name.Namespace}
+	r.metrics.EndpointsConfigured.DeletePartialMatch(labels)
+	r.metrics.FrontendCacheHitRatio.Delete(labels)
+	r.metrics.FrontendInflightRequests.Delete(labels)
+}
+
+// endpointServiceNames returns the namespaced names of the StoreAPI Services of the given endpoints.
+func endpointServiceNames(endpoints []manifestquery.Endpoint, grouped map[string][]manifestquery.Endpoint) []string {
+	var names []string
+	for _, ep := range endpoints {
+		names = append(names, types.NamespacedName{Namespace: ep.Namespace, Name: ep.ServiceName}.String())
+	}
+	for _, eps := range grouped {
+		for _, ep := range eps {
+			names = append(names, types.NamespacedName{Namespace: ep.Namespace, Name: ep.ServiceName}.String())
+		}
+	}
+	return names
+}
+
+// recordEndpointChanges records an EndpointAdded and an EndpointRemoved event for the endpoints added to and removed
+// from the ThanosQuery over the last window. The namespaced names of the StoreAPI Services are also set
+// in the EndpointsEventAnnotation of the events.
+func (r *ThanosQueryReconciler) recordEndpointChanges(query *monitoringthanosiov1alpha1.ThanosQuery, changes endpointevents.Changes) {
+	if len(changes.Added) > 0 {
+		r.recorder.AnnotatedEventf(query, map[string]string{monitoringthanosiov1alpha1.EndpointsEventAnnotation: strings.Join(changes.Added, ",")},
+			corev1.EventTypeNormal, "EndpointAdded", "Added endpoints for Services %s", strings.Join(changes.Added, ", "))
+	}
+	if len(changes.Removed) > 0 {
+		r.recorder.AnnotatedEventf(query, map[string]string{monitoringthanosiov1alpha1.EndpointsEventAnnotation: strings.Join(changes.Removed, ",")},
+			corev1.EventTypeNormal, "EndpointRemoved", "Removed endpoints for Services %s", strings.Join(changes.Removed, ", "))
+	}
 }
 
 // getStoreAPIServiceEndpoints returns the list of endpoints for the StoreAPI services that match the ThanosQuery storeLabelSelector.
-func (r *ThanosQueryReconciler) getStoreAPIServiceEndpoints(ctx context.Context, query monitoringthanosiov1alpha1.ThanosQuery) ([]manifestquery.Endpoint, error) {
-	labelSelector, err := manifests.BuildLabelSelectorFrom(query.Spec.StoreLabelSelector, requiredStoreServiceLabels)
+// Endpoints of services matching an endpoint group are returned separately, keyed by the name of the group.
+func (r *ThanosQueryReconciler) getStoreAPIServiceEndpoints(ctx context.Context, query monitoringthanosiov1alpha1.ThanosQuery) ([]manifestquery.Endpoint, map[string][]manifestquery.Endpoint, error) {
+	defer profile.FromContext(ctx).Start("discovery")()
+	requiredLabels := storeDiscoveryLabels(query)
+	labelSelector, err := manifests.BuildLabelSelectorFrom(query.Spec.StoreLabelSelector, requiredLabels)
+	if err != nil {
+		return nil, nil, err
+	}
+	overrides, err := endpointTypeOverrides(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	groups, err := endpointGroups(query)
 	if err != nil {
-		return []manifestquery.Endpoint{}, err
+		return nil, nil, err
 	}
 	services := &corev1.ServiceList{}
 	listOpts := []client.ListOption{
@@ -181,18 +888,28 @@ func (r *ThanosQueryReconciler) getStoreAPIServiceEndpoints(ctx context.Context,
 		client.InNamespace(query.Namespace),
 	}
 	if err := r.List(ctx, services, listOpts...); err != nil {
-		return []manifestquery.Endpoint{}, err
+		return nil, nil, err
 	}
 
 	if len(services.Items) == 0 {
-		r.recorder.Event(&query, corev1.EventTypeWarning, "NoEndpointsFound", "No StoreAPI services found")
-		return []manifestquery.Endpoint{}, nil
+		if len(query.Spec.CustomStoreEndpoints) == 0 {
+			r.recorder.Event(&query, corev1.EventTypeWarning, "NoEndpointsFound", "No StoreAPI services found")
+		}
+		return []manifestquery.Endpoint{}, nil, nil
+	}
+
+	if mismatches, err := replicaLabelMismatches(ctx, r.Client, query, services.Items); err != nil {
+		r.logger.Error(err, "failed to check replica labels of StoreAPI services")
+	} else if len(mismatches) > 0 {
+		r.recorder.Event(&query, corev1.EventTypeWarning, "ReplicaLabelMismatch",
+			fmt.Sprintf("Series will not be deduplicated, replica labels are not configured on the Querier: %s", strings.Join(mismatches, "; ")))
 	}
 
-	endpoints := make([]manifestquery.Endpoint, len(services.Items))
-	for i, svc := range services.Items {
+	endpoints := make([]manifestquery.Endpoint, 0, len(services.Items))
+	grouped := make(map[string][]manifestquery.Endpoint)
+	for _, svc := range services.Items {
 
-		port, ok := manifests.IsGrpcServiceWithLabels(&svc, requiredStoreServiceLabels)
+		port, ok := manifests.IsGrpcServiceWithLabels(&svc, requiredLabels)
 		if !ok {
 			r.logger.Error(fmt.Errorf(
 				"service %s/%s is missing required gRPC port", svc.GetNamespace(), svc.GetName()),
@@ -202,52 +919,149 @@ func (r *ThanosQueryReconciler) getStoreAPIServiceEndpoints(ctx context.Context,
 		}
 
 		etype := r.getServiceTypeFromLabel(svc.ObjectMeta)
+		for _, o := range overrides {
+			if o.selector.Matches(labels.Set(svc.GetLabels())) {
+				etype = o.etype
+				break
+			}
+		}
 
-		endpoints[i] = manifestquery.Endpoint{
+		endpoint := manifestquery.Endpoint{
 			ServiceName: svc.GetName(),
 			Port:        port,
 			Namespace:   svc.GetNamespace(),
 			Type:        etype,
 		}
-		r.metrics.EndpointsConfigured.WithLabelValues(string(etype), query.GetName(), query.GetNamespace()).Inc()
+
+		group := matchEndpointGroup(groups, svc.GetLabels())
+		if group == "" {
+			endpoints = append(endpoints, endpoint)
+			continue
+		}
+		grouped[group] = append(grouped[group], endpoint)
 	}
 
 	sort.Slice(endpoints, func(i, j int) bool {
 		return endpoints[i].ServiceName < endpoints[j].ServiceName
 	})
-	return endpoints, nil
+	for _, eps := range grouped {
+		sort.Slice(eps, func(i, j int) bool {
+			return eps[i].ServiceName < eps[j].ServiceName
+		})
+	}
+	return endpoints, grouped, nil
 }
 
 func (r *ThanosQueryReconciler) buildQueryFrontend(query monitoringthanosiov1alpha1.ThanosQuery) []client.Object {
 	return queryV1Alpha1ToQueryFrontEndOptions(query).Build()
 }
 
+// buildGrafanaDatasource builds the Grafana datasource provisioning ConfigMap for the ThanosQuery.
+func (r *ThanosQueryReconciler) buildGrafanaDatasource(query monitoringthanosiov1alpha1.ThanosQuery) client.Object {
+	opts := queryV1Alpha1ToOptions(query)
+	labels := manifests.MergeLabels(query.Spec.GrafanaDatasource.Labels, manifestquery.GetLabels(opts))
+	return manifests.BuildGrafanaDatasource(GrafanaDatasourceNameFromParent(query.GetName()), query.GetNamespace(), labels, queryV1Alpha1ToGrafanaDatasourceOptions(query))
+}
+
+// buildStackIngress returns the Ingress exposing the UI of the ThanosQuery at the root path,
+// and the UIs of the ThanosRulers and ThanosCompacts in its stack under their route prefixes.
+func (r *ThanosQueryReconciler) buildStackIngress(ctx context.Context, query monitoringthanosiov1alpha1.ThanosQuery) (client.Object, error) {
+	root := manifests.IngressPath{
+		Path:        "/",
+		ServiceName: QueryNameFromParent(query.GetName()),
+		PortName:    queryV1Alpha1ToOptions(query).GetHTTPPortName(manifestquery.HTTPPortName),
+	}
+	if query.Spec.QueryFrontend != nil {
+		root = manifests.IngressPath{
+			Path:        "/",
+			ServiceName: QueryFrontendNameFromParent(query.GetName()),
+			PortName:    queryV1Alpha1ToQueryFrontEndOptions(query).GetHTTPPortName(manifestqueryfrontend.HTTPPortName),
+		}
+	}
+
+	var paths []manifests.IngressPath
+	if stack := query.GetLabels()[monitoringthanosiov1alpha1.StackLabel]; stack != "" {
+		listOpts := []client.ListOption{client.InNamespace(query.GetNamespace()), client.MatchingLabels{monitoringthanosiov1alpha1.StackLabel: stack}}
+
+		rulers := &monitoringthanosiov1alpha1.ThanosRulerList{}
+		if err := r.List(ctx, rulers, listOpts...); err != nil {
+			return nil, fmt.Errorf("failed to list ThanosRuler resources in stack %s: %w", stack, err)
+		}
+		for _, ruler := range rulers.Items {
+			paths = append(paths, manifests.IngressPath{
+				Path:        rulerRoutePrefix(ruler.GetName()),
+				ServiceName: RulerNameFromParent(ruler.GetName()),
+				PortName:    rulerV1Alpha1ToOptions(ruler).GetHTTPPortName(manifestruler.HTTPPortName),
+			})
+		}
+
+		compacts := &monitoringthanosiov1alpha1.ThanosCompactList{}
+		if err := r.List(ctx, compacts, listOpts...); err != nil {
+			return nil, fmt.Errorf("failed to list ThanosCompact resources in stack %s: %w", stack, err)
+		}
+		for _, compact := range compacts.Items {
+			for _, opts := range compactV1Alpha1ToShardOptions(compact) {
+				paths = append(paths, manifests.IngressPath{
+					Path:        compactRoutePrefix(opts),
+					ServiceName: opts.GetGeneratedResourceName(),
+					PortName:    opts.GetHTTPPortName(manifestcompact.HTTPPortName),
+				})
+			}
+		}
+	}
+	// keep the order stable to avoid needless updates of the Ingress
+	sort.Slice(paths, func(i, j int) bool { return paths[i].Path < paths[j].Path })
+
+	spec := query.Spec.StackIngress
+	labels := manifestquery.GetLabels(queryV1Alpha1ToOptions(query))
+	return manifests.BuildIngress(QueryNameFromParent(query.GetName()), query.GetNamespace(), labels, manifests.IngressOptions{
+		Host:             spec.Host,
+		IngressClassName: spec.IngressClassName,
+		TLSSecretName:    spec.TLSSecretName,
+		Annotations:      spec.Annotations,
+		Paths:            append(paths, root),
+	}), nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ThanosQueryReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	servicePredicate, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
-		MatchLabels: requiredStoreServiceLabels,
-	})
-	if err != nil {
-		return err
+	// the discovery labels of StoreAPI Services are configurable per ThanosQuery,
+	// so any gRPC Service is a candidate which is matched against each ThanosQuery in queriesForService
+	servicePredicate := predicate.NewPredicateFuncs(isGrpcService)
+	// a Service which stops being a StoreAPI Service must trigger a rebuild of the endpoints, just like a deletion
+	servicePredicate.UpdateFunc = func(e event.UpdateEvent) bool {
+		return isGrpcService(e.ObjectOld) || isGrpcService(e.ObjectNew)
 	}
 
 	withLabelChangedPredicate := predicate.And(servicePredicate, predicate.LabelChangedPredicate{})
 	withGenerationChangePredicate := predicate.And(servicePredicate, predicate.GenerationChangedPredicate{}, servicePredicate)
 	withPredicate := predicate.Or(withLabelChangedPredicate, withGenerationChangePredicate)
 
-	err = ctrl.NewControllerManagedBy(mgr).
+	err := ctrl.NewControllerManagedBy(mgr).
 		For(&monitoringthanosiov1alpha1.ThanosQuery{}).
+		WithOptions(r.controllerConfig.options()).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.StatefulSet{}).
 		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
 		Owns(&monitoringv1.ServiceMonitor{}).
+		Owns(&networkingv1.Ingress{}).
 		Watches(
 			&corev1.Service{},
 			r.enqueueForService(),
 			builder.WithPredicates(withPredicate),
 		).
+		Watches(
+			&monitoringthanosiov1alpha1.ThanosRuler{},
+			enqueueForStack(r.Client, &monitoringthanosiov1alpha1.ThanosQueryList{}),
+		).
+		Watches(
+			&monitoringthanosiov1alpha1.ThanosCompact{},
+			enqueueForStack(r.Client, &monitoringthanosiov1alpha1.ThanosQueryList{}),
+		).
 		Complete(r)
 
 	// if servicemonitor CRD exists in the cluster, watch for changes to ServiceMonitor resources
@@ -261,51 +1075,90 @@ func (r *ThanosQueryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 // enqueueForService returns an EventHandler that will enqueue a request for the ThanosQuery instances
 // that matches the Service.
+// When a StoreAPI Service is deleted, or its labels change so that it is no longer selected, the ThanosQuery
+// instances which selected it are enqueued as well.
 func (r *ThanosQueryReconciler) enqueueForService() handler.EventHandler {
-	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
-		if !r.isQueueableStoreService(obj) {
-			return []reconcile.Request{}
-		}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.enqueueQueries(q, r.queriesForService(ctx, e.Object))
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			current := r.queriesForService(ctx, e.ObjectNew)
+			r.enqueueQueries(q, current)
 
-		listOpts := []client.ListOption{
-			client.InNamespace(obj.GetNamespace()),
-		}
+			for _, query := range r.queriesForService(ctx, e.ObjectOld) {
+				if !slices.ContainsFunc(current, func(c monitoringthanosiov1alpha1.ThanosQuery) bool { return c.GetName() == query.GetName() }) {
+					r.enqueueQueries(q, []monitoringthanosiov1alpha1.ThanosQuery{query})
+				}
+			}
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.enqueueQueries(q, r.queriesForService(ctx, e.Object))
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.enqueueQueries(q, r.queriesForService(ctx, e.Object))
+		},
+	}
+}
+
+// queriesForService returns the ThanosQuery instances whose store discovery labels and store label selector match the Service.
+func (r *ThanosQueryReconciler) queriesForService(ctx context.Context, obj client.Object) []monitoringthanosiov1alpha1.ThanosQuery {
+	if !isGrpcService(obj) {
+		return nil
+	}
+
+	listOpts := []client.ListOption{
+		client.InNamespace(obj.GetNamespace()),
+	}
+
+	queriers := &monitoringthanosiov1alpha1.ThanosQueryList{}
+	err := r.List(ctx, queriers, listOpts...)
+	if err != nil {
+		return nil
+	}
 
-		queriers := &monitoringthanosiov1alpha1.ThanosQueryList{}
-		err := r.List(ctx, queriers, listOpts...)
+	var queries []monitoringthanosiov1alpha1.ThanosQuery
+	for _, query := range queriers.Items {
+		selector, err := manifests.BuildLabelSelectorFrom(query.Spec.StoreLabelSelector, storeDiscoveryLabels(query))
 		if err != nil {
-			return []reconcile.Request{}
+			r.logger.Error(err, "failed to build label selector from store label selector", "query", query.GetName())
+			continue
 		}
 
-		requests := []reconcile.Request{}
-		for _, query := range queriers.Items {
-			selector, err := manifests.BuildLabelSelectorFrom(query.Spec.StoreLabelSelector, requiredStoreServiceLabels)
-			if err != nil {
-				r.logger.Error(err, "failed to build label selector from store label selector", "query", query.GetName())
-				continue
-			}
-
-			if selector.Matches(labels.Set(obj.GetLabels())) {
-				requests = append(requests, reconcile.Request{
-					NamespacedName: types.NamespacedName{
-						Name:      query.GetName(),
-						Namespace: query.GetNamespace(),
-					},
-				})
-			}
+		if selector.Matches(labels.Set(obj.GetLabels())) {
+			queries = append(queries, query)
 		}
+	}
+	return queries
+}
 
-		r.metrics.ServiceWatchesReconciliationsTotal.Add(float64(len(requests)))
-		return requests
-	})
+// enqueueQueries adds a request for each of the given ThanosQuery instances to the queue.
+func (r *ThanosQueryReconciler) enqueueQueries(q workqueue.TypedRateLimitingInterface[reconcile.Request], queries []monitoringthanosiov1alpha1.ThanosQuery) {
+	for _, query := range queries {
+		q.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      query.GetName(),
+				Namespace: query.GetNamespace(),
+			},
+		})
+	}
+	r.metrics.ServiceWatchesReconciliationsTotal.Add(float64(len(queries)))
 }
 
-// isQueueableStoreService returns true if the Service is a StoreAPI service that is part of a 'thanos' and has a gRPC port.
-func (r *ThanosQueryReconciler) isQueueableStoreService(obj client.Object) bool {
-	_, ok := manifests.IsGrpcServiceWithLabels(obj, requiredStoreServiceLabels)
+// isGrpcService returns true if the object is a Service with a gRPC port.
+func isGrpcService(obj client.Object) bool {
+	_, ok := manifests.IsGrpcServiceWithLabels(obj, nil)
 	return ok
 }
 
+// storeDiscoveryLabels returns the labels a Service must carry to be discovered as a StoreAPI by the ThanosQuery.
+func storeDiscoveryLabels(query monitoringthanosiov1alpha1.ThanosQuery) map[string]string {
+	if len(query.Spec.StoreDiscoveryLabels) > 0 {
+		return query.Spec.StoreDiscoveryLabels
+	}
+	return requiredStoreServiceLabels
+}
+
 func (r *ThanosQueryReconciler) getServiceTypeFromLabel(objMeta metav1.ObjectMeta) manifests.EndpointType {
 	etype := manifests.RegularLabel
 
@@ -319,4 +1172,138 @@ func (r *ThanosQueryReconciler) getServiceTypeFromLabel(objMeta metav1.ObjectMet
 	return etype
 }
 
+// endpointTypeOverride is a parsed v1alpha1.EndpointTypeOverride.
+type endpointTypeOverride struct {
+	selector labels.Selector
+	etype    manifests.EndpointType
+}
+
+// endpointTypeOverrides parses the endpoint type overrides of the ThanosQuery in order.
+func endpointTypeOverrides(query monitoringthanosiov1alpha1.ThanosQuery) ([]endpointTypeOverride, error) {
+	overrides := make([]endpointTypeOverride, 0, len(query.Spec.EndpointTypeOverrides))
+	for i, o := range query.Spec.EndpointTypeOverrides {
+		selector, err := metav1.LabelSelectorAsSelector(&o.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector in endpoint type override %d: %w", i, err)
+		}
+		etype := toManifestEndpointType(o.Type)
+		if etype == "" {
+			return nil, fmt.Errorf("invalid type %q in endpoint type override %d", o.Type, i)
+		}
+		overrides = append(overrides, endpointTypeOverride{selector: selector, etype: etype})
+	}
+	return overrides, nil
+}
+
+// endpointGroup is a parsed v1alpha1.EndpointGroup.
+type endpointGroup struct {
+	name     string
+	selector labels.Selector
+}
+
+// endpointGroups parses the endpoint groups of the ThanosQuery in order.
+func endpointGroups(query monitoringthanosiov1alpha1.ThanosQuery) ([]endpointGroup, error) {
+	groups := make([]endpointGroup, 0, len(query.Spec.EndpointGroups))
+	for _, g := range query.Spec.EndpointGroups {
+		selector, err := metav1.LabelSelectorAsSelector(&g.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector in endpoint group %s: %w", g.Name, err)
+		}
+		groups = append(groups, endpointGroup{name: g.Name, selector: selector})
+	}
+	return groups, nil
+}
+
+// matchEndpointGroup returns the name of the first endpoint group matching the given Service labels,
+// or an empty string if none matches.
+func matchEndpointGroup(groups []endpointGroup, svcLabels map[string]string) string {
+	for _, g := range groups {
+		if g.selector.Matches(labels.Set(svcLabels)) {
+			return g.name
+		}
+	}
+	return ""
+}
+
+// endpointGroupResourceNames returns the names of the resources of the endpoint group Queriers of the ThanosQuery.
+func endpointGroupResourceNames(query monitoringthanosiov1alpha1.ThanosQuery) []string {
+	names := make([]string, 0, len(query.Spec.EndpointGroups))
+	for _, group := range query.Spec.EndpointGroups {
+		names = append(names, queryEndpointGroupToOptions(query, group).GetGeneratedResourceName())
+	}
+	return names
+}
+
+// unneededQueryPodDisruptionBudgets returns the PodDisruptionBudgets of the Queriers and Query Frontend of the ThanosQuery
+// which are not expected, because the component is not deployed or has a single replica.
+// The PodDisruptionBudgets of removed query pools are pruned with the other resources of the pools.
+func unneededQueryPodDisruptionBudgets(query monitoringthanosiov1alpha1.ThanosQuery) []client.Object {
+	var names []string
+	if hasExternalDownstream(query) || queryV1Alpha1ToOptions(query).PodDisruptionConfig == nil {
+		names = append(names, QueryNameFromParent(query.GetName()))
+	}
+	if !hasExternalDownstream(query) {
+		for _, pool := range query.Spec.QueryPools {
+			if opts := queryPoolToOptions(query, pool); opts.PodDisruptionConfig == nil {
+				names = append(names, opts.GetGeneratedResourceName())
+			}
+		}
+	}
+	if query.Spec.QueryFrontend == nil || queryV1Alpha1ToQueryFrontEndOptions(query).PodDisruptionConfig == nil {
+		names = append(names, QueryFrontendNameFromParent(query.GetName()))
+	}
+
+	pdbs := make([]client.Object, len(names))
+	for i, name := range names {
+		pdbs[i] = &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: query.GetNamespace()}}
+	}
+	return pdbs
+}
+
+// unneededQueryHorizontalPodAutoscalers returns the HorizontalPodAutoscalers of the Querier and Query Frontend of the ThanosQuery
+// which are not expected, because the component is not deployed or not autoscaled.
+func unneededQueryHorizontalPodAutoscalers(query monitoringthanosiov1alpha1.ThanosQuery) []client.Object {
+	var names []string
+	if hasExternalDownstream(query) || query.Spec.Autoscaling == nil {
+		names = append(names, QueryNameFromParent(query.GetName()))
+	}
+	if query.Spec.QueryFrontend == nil || query.Spec.QueryFrontend.Autoscaling == nil {
+		names = append(names, QueryFrontendNameFromParent(query.GetName()))
+	}
+
+	hpas := make([]client.Object, len(names))
+	for i, name := range names {
+		hpas[i] = &autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: query.GetNamespace()}}
+	}
+	return hpas
+}
+
+// queryPoolResourceNames returns the names of the resources of the query pool Queriers of the ThanosQuery.
+// unneededQueryLogForwardingConfigMaps returns the log forwarding ConfigMaps of the Querier and Query Frontend of the ThanosQuery
+// which are not expected, because the component is not deployed or does not forward its logs.
+// The ConfigMaps of endpoint groups and query pools are pruned with the other resources of the groups and pools.
+func unneededQueryLogForwardingConfigMaps(query monitoringthanosiov1alpha1.ThanosQuery) []client.Object {
+	var names []string
+	if hasExternalDownstream(query) || query.Spec.LogForwarding == nil {
+		names = append(names, manifests.LogForwardingConfigMapName(QueryNameFromParent(query.GetName())))
+	}
+	if query.Spec.QueryFrontend == nil || query.Spec.QueryFrontend.LogForwarding == nil {
+		names = append(names, manifests.LogForwardingConfigMapName(QueryFrontendNameFromParent(query.GetName())))
+	}
+
+	cms := make([]client.Object, len(names))
+	for i, name := range names {
+		cms[i] = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: query.GetNamespace()}}
+	}
+	return cms
+}
+
+func queryPoolResourceNames(query monitoringthanosiov1alpha1.ThanosQuery) []string {
+	names := make([]string, 0, len(query.Spec.QueryPools))
+	for _, pool := range query.Spec.QueryPools {
+		names = append(names, queryPoolToOptions(query, pool).GetGeneratedResourceName())
+	}
+	return names
+}
+
 var requiredStoreServiceLabels = manifestsstore.GetRequiredStoreServiceLabel()
@@ -20,23 +20,31 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestquery "github.com/thanos-community/thanos-operator/internal/pkg/manifests/query"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests/receive"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestquery "github.com/thanos-community/thanos-operator/pkg/manifests/query"
+	"github.com/thanos-community/thanos-operator/pkg/manifests/receive"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("ThanosQuery Controller", Ordered, func() {
@@ -192,6 +200,168 @@ var _ = Describe("ThanosQuery Controller", Ordered, func() {
 				}, time.Minute*1, time.Second*10).Should(Succeed())
 			})
 
+			By("configuring the query limits of the querier", func() {
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--query.timeout=15m") &&
+						utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--query.lookback-delta=5m") &&
+						utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--query.max-concurrent=20")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				resource.Spec.Timeout = ptr.To(monitoringthanosiov1alpha1.Duration("30m"))
+				resource.Spec.LookbackDelta = ptr.To(monitoringthanosiov1alpha1.Duration("10m"))
+				resource.Spec.MaxConcurrent = ptr.To(int32(50))
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--query.timeout=30m") &&
+						utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--query.lookback-delta=10m") &&
+						utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--query.max-concurrent=50")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("attaching custom store endpoints", func() {
+				resource.Spec.CustomStoreEndpoints = []monitoringthanosiov1alpha1.CustomStoreEndpoint{
+					{Address: "thanos-sidecar.example.com:10901", Type: monitoringthanosiov1alpha1.StrictEndpointType},
+					{Address: "thanos-query.example.com:443", Type: monitoringthanosiov1alpha1.GroupEndpointType},
+				}
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--endpoint-strict=thanos-sidecar.example.com:10901") &&
+						utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--endpoint-group=thanos-query.example.com:443")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				invalid := resource.DeepCopy()
+				Expect(k8sClient.Get(ctx, typeNamespacedName, invalid)).Should(Succeed())
+				invalid.Spec.CustomStoreEndpoints = []monitoringthanosiov1alpha1.CustomStoreEndpoint{
+					{Address: "dnssrv+_grpc._tcp.thanos.example.com", Type: monitoringthanosiov1alpha1.GroupEndpointType},
+				}
+				Expect(k8sClient.Update(ctx, invalid)).ShouldNot(Succeed())
+
+				resource.Spec.CustomStoreEndpoints = nil
+				updateQuerySpec(ctx, resource)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--endpoint-group=thanos-query.example.com:443")
+				}, time.Second*10, time.Second*2).Should(BeFalse())
+			})
+
+			By("overriding the endpoint type of selected services", func() {
+				resource.Spec.EndpointTypeOverrides = []monitoringthanosiov1alpha1.EndpointTypeOverride{
+					{
+						Selector: metav1.LabelSelector{MatchLabels: map[string]string{string(manifests.StrictLabel): manifests.DefaultStoreAPIValue}},
+						Type:     monitoringthanosiov1alpha1.GroupStrictEndpointType,
+					},
+				}
+				updateQuerySpec(ctx, resource)
+
+				expectArg := fmt.Sprintf("--endpoint-group-strict=%s.%s.svc.cluster.local:%d", receiveSvcName, ns, receive.GRPCPort)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, expectArg)
+				}, time.Minute*1, time.Second*10).Should(BeTrue())
+
+				resource.Spec.EndpointTypeOverrides = nil
+				updateQuerySpec(ctx, resource)
+
+				expectArg = fmt.Sprintf("--endpoint-strict=dnssrv+_%s._tcp.%s.%s.svc.cluster.local", receive.GRPCPortName, receiveSvcName, ns)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, expectArg)
+				}, time.Minute*1, time.Second*10).Should(BeTrue())
+			})
+
+			By("removing the endpoint of a deleted service", func() {
+				svc := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "store-to-remove",
+						Namespace: ns,
+						Labels:    manifests.MergeLabels(requiredStoreServiceLabels, nil),
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{receivePort},
+					},
+				}
+				delete(svc.Labels, string(manifests.StrictLabel))
+				Expect(k8sClient.Create(context.Background(), svc)).Should(Succeed())
+
+				expectArg := fmt.Sprintf("--endpoint=dnssrv+_%s._tcp.%s.%s.svc.cluster.local", receive.GRPCPortName, svc.GetName(), ns)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, expectArg)
+				}, time.Minute*1, time.Second*10).Should(BeTrue())
+
+				Expect(k8sClient.Delete(context.Background(), svc)).Should(Succeed())
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, expectArg)
+				}, time.Minute*1, time.Second*10).Should(BeFalse())
+			})
+
+			By("routing selected services through an endpoint group querier", func() {
+				timeout := monitoringthanosiov1alpha1.Duration("2m")
+				resource.Spec.EndpointGroups = []monitoringthanosiov1alpha1.EndpointGroup{
+					{
+						Name:            "federated",
+						Selector:        metav1.LabelSelector{MatchLabels: map[string]string{string(manifests.StrictLabel): manifests.DefaultStoreAPIValue}},
+						Replicas:        1,
+						ResponseTimeout: &timeout,
+						Strict:          true,
+					},
+				}
+				updateQuerySpec(ctx, resource)
+
+				groupName := name + "-federated"
+				expectArg := fmt.Sprintf("--endpoint-strict=dnssrv+_%s._tcp.%s.%s.svc.cluster.local", manifestquery.GRPCPortName, groupName, ns)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, expectArg)
+				}, time.Minute*1, time.Second*10).Should(BeTrue())
+
+				receiveArg := fmt.Sprintf("--endpoint-strict=dnssrv+_%s._tcp.%s.%s.svc.cluster.local", receive.GRPCPortName, receiveSvcName, ns)
+				Expect(utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, receiveArg)).To(BeFalse())
+				Expect(utils.VerifyDeploymentArgs(k8sClient, groupName, ns, 0, receiveArg)).To(BeTrue())
+				Expect(utils.VerifyDeploymentArgs(k8sClient, groupName, ns, 0, "--store.response-timeout=2m")).To(BeTrue())
+
+				resource.Spec.EndpointGroups = nil
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentExists(k8sClient, groupName, ns) || utils.VerifyServiceExists(k8sClient, groupName, ns)
+				}, time.Minute*1, time.Second*10).Should(BeFalse())
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, receiveArg)
+				}, time.Minute*1, time.Second*10).Should(BeTrue())
+			})
+
+			By("discovering stores with custom discovery labels", func() {
+				discoveryLabels := map[string]string{"example.com/query-layer": "tenant-a"}
+				svc := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "store-of-tenant-a",
+						Namespace: ns,
+						Labels:    discoveryLabels,
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{receivePort},
+					},
+				}
+				Expect(k8sClient.Create(context.Background(), svc)).Should(Succeed())
+
+				resource.Spec.StoreDiscoveryLabels = discoveryLabels
+				updateQuerySpec(ctx, resource)
+
+				expectArg := fmt.Sprintf("--endpoint=dnssrv+_%s._tcp.%s.%s.svc.cluster.local", receive.GRPCPortName, svc.GetName(), ns)
+				receiveArg := fmt.Sprintf("--endpoint-strict=dnssrv+_%s._tcp.%s.%s.svc.cluster.local", receive.GRPCPortName, receiveSvcName, ns)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, expectArg) &&
+						!utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, receiveArg)
+				}, time.Minute*1, time.Second*10).Should(BeTrue())
+
+				resource.Spec.StoreDiscoveryLabels = nil
+				updateQuerySpec(ctx, resource)
+				Expect(k8sClient.Delete(context.Background(), svc)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, receiveArg) &&
+						!utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, expectArg)
+				}, time.Minute*1, time.Second*10).Should(BeTrue())
+			})
+
 			By("setting up the thanos query with query frontend", func() {
 				oneh := monitoringthanosiov1alpha1.Duration("1h")
 				thirtym := monitoringthanosiov1alpha1.Duration("30m")
@@ -212,7 +382,7 @@ var _ = Describe("ThanosQuery Controller", Ordered, func() {
 					LabelsMaxRetries:        5,
 				}
 
-				Expect(k8sClient.Update(context.Background(), resource)).Should(Succeed())
+				updateQuerySpec(ctx, resource)
 				verifier := utils.Verifier{}.WithDeployment().WithService().WithServiceAccount()
 				EventuallyWithOffset(1, func() bool {
 					return verifier.Verify(k8sClient, QueryFrontendNameFromParent(resourceName), ns)
@@ -246,6 +416,99 @@ config:
 				}, time.Minute, time.Second*10).Should(Succeed())
 			})
 
+			By("co-scheduling the query frontend with the querier", func() {
+				resource.Spec.QueryFrontend.CoScheduling = &monitoringthanosiov1alpha1.CoSchedulingSpec{}
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					deployment := &appsv1.Deployment{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: QueryFrontendNameFromParent(resourceName), Namespace: ns}, deployment); err != nil {
+						return false
+					}
+					affinity := deployment.Spec.Template.Spec.Affinity
+					if affinity == nil || affinity.PodAffinity == nil || len(affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 {
+						return false
+					}
+					term := affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+					return term.TopologyKey == corev1.LabelTopologyZone &&
+						term.LabelSelector.MatchLabels[manifests.InstanceLabel] == name
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+			})
+
+			By("forwarding the logs of the query frontend", func() {
+				frontend := QueryFrontendNameFromParent(resourceName)
+				configMap := manifests.LogForwardingConfigMapName(frontend)
+				resource.Spec.QueryFrontend.LogForwarding = &monitoringthanosiov1alpha1.LogForwarding{
+					Endpoint: "https://audit.example.com/logs",
+				}
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					deployment := &appsv1.Deployment{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: frontend, Namespace: ns}, deployment); err != nil {
+						return false
+					}
+					containers := deployment.Spec.Template.Spec.Containers
+					return utils.VerifyConfigMapExists(k8sClient, configMap, ns) &&
+						len(containers) == 2 && containers[1].Name == manifests.LogForwarderContainerName
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				resource.Spec.QueryFrontend.LogForwarding = nil
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyConfigMapExists(k8sClient, configMap, ns)
+				}, time.Second*30, time.Second*2).Should(BeFalse())
+			})
+
+			By("deploying a managed memcached for the response cache", func() {
+				resource.Spec.QueryFrontend.QueryRangeResponseCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					MemcachedCacheConfig: &monitoringthanosiov1alpha1.MemcachedCacheConfig{
+						Managed: &monitoringthanosiov1alpha1.ManagedMemcachedConfig{Replicas: ptr.To(int32(2))},
+					},
+				}
+				updateQuerySpec(ctx, resource)
+
+				memcachedName := fmt.Sprintf("memcached-%s-response-cache", resourceName)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetReplicas(k8sClient, 2, memcachedName, ns) &&
+						utils.VerifyServiceExists(k8sClient, memcachedName, ns)
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					deployment := &appsv1.Deployment{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: QueryFrontendNameFromParent(resourceName), Namespace: ns}, deployment); err != nil {
+						return false
+					}
+					args := strings.Join(deployment.Spec.Template.Spec.Containers[0].Args, " ")
+					return strings.Contains(args, fmt.Sprintf("dnssrv+_client._tcp.%s.%s.svc", memcachedName, ns))
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				resource.Spec.QueryFrontend.QueryRangeResponseCacheConfig = nil
+				updateQuerySpec(ctx, resource)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetExists(k8sClient, memcachedName, ns)
+				}, time.Second*30, time.Second*2).Should(BeFalse())
+			})
+
+			By("rejecting impossible query frontend limits", func() {
+				invalid := resource.DeepCopy()
+				Expect(k8sClient.Get(ctx, typeNamespacedName, invalid)).Should(Succeed())
+				invalid.Spec.QueryFrontend.LabelsSplitInterval = ptr.To(monitoringthanosiov1alpha1.Duration("48h"))
+				invalid.Spec.QueryFrontend.LabelsDefaultTimeRange = ptr.To(monitoringthanosiov1alpha1.Duration("24h"))
+				Expect(k8sClient.Update(ctx, invalid)).ShouldNot(Succeed())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, invalid)).Should(Succeed())
+				invalid.Spec.QueryFrontend.LogQueriesLongerThan = ptr.To(monitoringthanosiov1alpha1.Duration("1h"))
+				Expect(k8sClient.Update(ctx, invalid)).ShouldNot(Succeed())
+
+				// day units are not understood by the validation rules of the CRD, the controller rejects them instead
+				invalid.Spec.QueryFrontend.LogQueriesLongerThan = nil
+				invalid.Spec.QueryFrontend.LabelsSplitInterval = ptr.To(monitoringthanosiov1alpha1.Duration("2d"))
+				invalid.Spec.QueryFrontend.LabelsDefaultTimeRange = ptr.To(monitoringthanosiov1alpha1.Duration("1d"))
+				Expect(validateQueryFrontendLimits(*invalid)).ShouldNot(Succeed())
+			})
+
 			By("verifying query frontend is linked to query service", func() {
 				EventuallyWithOffset(1, func() error {
 					expectedArg := fmt.Sprintf("--query-frontend.downstream-url=http://%s.%s.svc.cluster.local:9090", name, ns)
@@ -256,6 +519,332 @@ config:
 				}, time.Second*30, time.Second*10).Should(Succeed())
 			})
 
+			By("routing the query frontend to a query pool", func() {
+				resource.Spec.QueryPools = []monitoringthanosiov1alpha1.QueryPool{
+					{Name: "interactive", Replicas: 1, MaxConcurrent: ptr.To(int32(5))},
+					{Name: "rule-evaluation", Replicas: 1, Timeout: ptr.To(monitoringthanosiov1alpha1.Duration("30m"))},
+				}
+				resource.Spec.QueryFrontend.QueryPool = ptr.To("interactive")
+				updateQuerySpec(ctx, resource)
+
+				interactive := name + "-interactive"
+				ruleEvaluation := name + "-rule-evaluation"
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, interactive, ns, 0, "--query.max-concurrent=5") &&
+						utils.VerifyDeploymentArgs(k8sClient, ruleEvaluation, ns, 0, "--query.timeout=30m") &&
+						utils.VerifyServiceExists(k8sClient, interactive, ns)
+				}, time.Minute*1, time.Second*10).Should(BeTrue())
+
+				svc := &corev1.Service{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ruleEvaluation, Namespace: ns}, svc)).Should(Succeed())
+				Expect(svc.GetLabels()).To(HaveKeyWithValue(manifests.QueryPoolLabel, "rule-evaluation"))
+
+				expectedArg := fmt.Sprintf("--query-frontend.downstream-url=http://%s.%s.svc.cluster.local:9090", interactive, ns)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, QueryFrontendNameFromParent(resourceName), ns, 0, expectedArg)
+				}, time.Minute*1, time.Second*10).Should(BeTrue())
+
+				invalid := resource.DeepCopy()
+				invalid.Spec.QueryFrontend.QueryPool = ptr.To("missing")
+				Expect(validateQueryPools(*invalid)).ShouldNot(Succeed())
+
+				resource.Spec.QueryPools = nil
+				resource.Spec.QueryFrontend.QueryPool = nil
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentExists(k8sClient, interactive, ns) || utils.VerifyServiceExists(k8sClient, ruleEvaluation, ns)
+				}, time.Minute*1, time.Second*10).Should(BeFalse())
+			})
+
+			By("pointing the query frontend at an external downstream", func() {
+				resource.Spec.QueryFrontend.DownstreamURL = ptr.To("https://thanos-query.example.com")
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() error {
+					expectedArg := "--query-frontend.downstream-url=https://thanos-query.example.com"
+					if !utils.VerifyDeploymentArgs(k8sClient, QueryFrontendNameFromParent(resourceName), ns, 0, expectedArg) {
+						return fmt.Errorf("expected arg %q not found", expectedArg)
+					}
+					return nil
+				}, time.Second*30, time.Second*10).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentExists(k8sClient, name, ns)
+				}, time.Second*30, time.Second*2).Should(BeFalse())
+
+				resource.Spec.QueryFrontend.DownstreamURL = nil
+				updateQuerySpec(ctx, resource)
+
+				verifier := utils.Verifier{}.WithDeployment().WithService().WithServiceAccount().WithServiceMonitor()
+				EventuallyWithOffset(1, func() bool {
+					return verifier.Verify(k8sClient, name, ns)
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+			})
+
+			By("generating a grafana datasource targeting the query frontend", func() {
+				resource.Spec.GrafanaDatasource = &monitoringthanosiov1alpha1.GrafanaDatasourceSpec{
+					Labels: map[string]string{"grafana_datasource": "1"},
+				}
+				updateQuerySpec(ctx, resource)
+
+				dsName := GrafanaDatasourceNameFromParent(resourceName)
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					if err := k8sClient.Get(context.Background(), types.NamespacedName{Name: dsName, Namespace: ns}, cm); err != nil {
+						return false
+					}
+					expectURL := fmt.Sprintf("url: http://%s.%s.svc.cluster.local:9090", QueryFrontendNameFromParent(resourceName), ns)
+					return cm.Labels["grafana_datasource"] == "1" &&
+						strings.Contains(cm.Data[manifests.GrafanaDatasourceKey], expectURL)
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				resource.Spec.GrafanaDatasource = nil
+				updateQuerySpec(ctx, resource)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyConfigMapExists(k8sClient, dsName, ns)
+				}, time.Second*30, time.Second*2).Should(BeFalse())
+			})
+
+			By("exposing the query frontend on the stack ingress", func() {
+				resource.Spec.StackIngress = &monitoringthanosiov1alpha1.StackIngressSpec{
+					Host: "thanos.example.com",
+				}
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					ingress := &networkingv1.Ingress{}
+					if err := k8sClient.Get(context.Background(), types.NamespacedName{Name: name, Namespace: ns}, ingress); err != nil {
+						return false
+					}
+					rule := ingress.Spec.Rules[0]
+					return rule.Host == "thanos.example.com" &&
+						len(rule.HTTP.Paths) == 1 &&
+						rule.HTTP.Paths[0].Path == "/" &&
+						rule.HTTP.Paths[0].Backend.Service.Name == QueryFrontendNameFromParent(resourceName)
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				resource.Spec.StackIngress = nil
+				updateQuerySpec(ctx, resource)
+				EventuallyWithOffset(1, func() bool {
+					ingress := &networkingv1.Ingress{}
+					err := k8sClient.Get(context.Background(), types.NamespacedName{Name: name, Namespace: ns}, ingress)
+					return apierrors.IsNotFound(err)
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+			})
+
+			By("autoscaling the querier with a horizontal pod autoscaler", func() {
+				resource.Spec.Autoscaling = &monitoringthanosiov1alpha1.AutoscalingConfig{
+					MinReplicas:                    ptr.To(int32(2)),
+					MaxReplicas:                    5,
+					TargetCPUUtilizationPercentage: ptr.To(int32(70)),
+				}
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, hpa); err != nil {
+						return false
+					}
+					return hpa.Spec.ScaleTargetRef.Name == name &&
+						ptr.Deref(hpa.Spec.MinReplicas, 0) == 2 && hpa.Spec.MaxReplicas == 5 &&
+						len(hpa.Spec.Metrics) == 1 && ptr.Deref(hpa.Spec.Metrics[0].Resource.Target.AverageUtilization, 0) == 70
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				// the replicas set by the autoscaler are left untouched
+				EventuallyWithOffset(1, func() error {
+					deployment := &appsv1.Deployment{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, deployment); err != nil {
+						return err
+					}
+					deployment.Spec.Replicas = ptr.To(int32(4))
+					return k8sClient.Update(ctx, deployment)
+				}, time.Second*10, time.Second).Should(Succeed())
+				resource.Spec.LogLevel = ptr.To("debug")
+				updateQuerySpec(ctx, resource)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--log.level=debug")
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+				ConsistentlyWithOffset(1, func() int32 {
+					deployment := &appsv1.Deployment{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, deployment); err != nil {
+						return 0
+					}
+					return ptr.Deref(deployment.Spec.Replicas, 0)
+				}, time.Second*5, time.Second).Should(Equal(int32(4)))
+
+				resource.Spec.LogLevel = nil
+				resource.Spec.Autoscaling = nil
+				updateQuerySpec(ctx, resource)
+				EventuallyWithOffset(1, func() bool {
+					hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, hpa); !apierrors.IsNotFound(err) {
+						return false
+					}
+					deployment := &appsv1.Deployment{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, deployment); err != nil {
+						return false
+					}
+					return ptr.Deref(deployment.Spec.Replicas, 0) == resource.Spec.Replicas
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+			})
+
+			By("reporting the upgrade progress of the stack", func() {
+				setStackLabel := func(stack string) {
+					EventuallyWithOffset(1, func() error {
+						if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+							return err
+						}
+						labels := resource.GetLabels()
+						if labels == nil {
+							labels = make(map[string]string)
+						}
+						labels[monitoringthanosiov1alpha1.StackLabel] = stack
+						if stack == "" {
+							delete(labels, monitoringthanosiov1alpha1.StackLabel)
+						}
+						resource.SetLabels(labels)
+						return k8sClient.Update(ctx, resource)
+					}, time.Second*10, time.Second).Should(Succeed())
+				}
+				setStackLabel("upgrade")
+
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					stack := resource.Status.Stack
+					// no pods become ready in the test environment, so the workloads never complete their rollout
+					return len(stack) == 2 &&
+						stack[0].Component == "query" && stack[1].Component == "query-frontend" &&
+						stack[0].Phase == monitoringthanosiov1alpha1.StackUpgradeUpgrading &&
+						stack[1].Phase == monitoringthanosiov1alpha1.StackUpgradeUpgrading &&
+						slices.Equal(stack[0].Versions, []string{manifests.DefaultThanosVersion})
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				setStackLabel("")
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					return len(resource.Status.Stack) == 0
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+			})
+
+			By("rolling back to the last known good state when a rollout fails", func() {
+				resource.Spec.Rollback = &monitoringthanosiov1alpha1.RollbackSpec{ProgressDeadlineSeconds: 60}
+				updateQuerySpec(ctx, resource)
+
+				EventuallyWithOffset(1, func() bool {
+					deployment := &appsv1.Deployment{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, deployment); err != nil {
+						return false
+					}
+					return ptr.Deref(deployment.Spec.ProgressDeadlineSeconds, 0) == 60
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				// there is no deployment controller in the test environment, so we fake the rollout status
+				setDeploymentStatus := func(status func(*appsv1.Deployment) appsv1.DeploymentStatus) {
+					EventuallyWithOffset(1, func() error {
+						deployment := &appsv1.Deployment{}
+						if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, deployment); err != nil {
+							return err
+						}
+						deployment.Status = status(deployment)
+						return k8sClient.Status().Update(ctx, deployment)
+					}, time.Second*30, time.Second*2).Should(Succeed())
+				}
+
+				setDeploymentStatus(func(d *appsv1.Deployment) appsv1.DeploymentStatus {
+					replicas := ptr.Deref(d.Spec.Replicas, 1)
+					return appsv1.DeploymentStatus{
+						ObservedGeneration: d.GetGeneration(),
+						Replicas:           replicas,
+						UpdatedReplicas:    replicas,
+						ReadyReplicas:      replicas,
+						AvailableReplicas:  replicas,
+					}
+				})
+
+				lkgName := LastKnownGoodNameFromParent(resourceName)
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: lkgName, Namespace: ns}, cm); err != nil {
+						return false
+					}
+					_, ok := cm.Data[name]
+					return ok
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.LogLevel = ptr.To("debug")
+				updateQuerySpec(ctx, resource)
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--log.level=debug")
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				setDeploymentStatus(func(d *appsv1.Deployment) appsv1.DeploymentStatus {
+					return appsv1.DeploymentStatus{
+						ObservedGeneration: d.GetGeneration(),
+						Conditions: []appsv1.DeploymentCondition{
+							{
+								Type:   appsv1.DeploymentProgressing,
+								Status: corev1.ConditionFalse,
+								Reason: "ProgressDeadlineExceeded",
+							},
+						},
+					}
+				})
+
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					return isRolledBack(resource.Status.Conditions, resource.GetGeneration()) &&
+						!utils.VerifyDeploymentArgs(k8sClient, name, ns, 0, "--log.level=debug")
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+
+				resource.Spec.LogLevel = nil
+				resource.Spec.Rollback = nil
+				updateQuerySpec(ctx, resource)
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					return !utils.VerifyConfigMapExists(k8sClient, lkgName, ns) &&
+						meta.FindStatusCondition(resource.Status.Conditions, monitoringthanosiov1alpha1.ConditionRolledBack) == nil
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+			})
+
+			By("reporting endpoint health in status", func() {
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					// the querier is not running in the test environment, so its endpoints cannot be observed
+					c := meta.FindStatusCondition(resource.Status.Conditions, monitoringthanosiov1alpha1.ConditionEndpointsHealthy)
+					return c != nil && c.Status == metav1.ConditionUnknown && c.Reason == reasonQueryUnreachable
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+			})
+
+			By("reporting reconcile status and observed generation", func() {
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					if resource.Status.ObservedGeneration != resource.GetGeneration() {
+						return false
+					}
+					reconciled := meta.FindStatusCondition(resource.Status.Conditions, monitoringthanosiov1alpha1.ConditionReconciled)
+					paused := meta.FindStatusCondition(resource.Status.Conditions, monitoringthanosiov1alpha1.ConditionPaused)
+					// no pods become ready in the test environment
+					degraded := meta.FindStatusCondition(resource.Status.Conditions, monitoringthanosiov1alpha1.ConditionDegraded)
+					return reconciled != nil && reconciled.Status == metav1.ConditionTrue &&
+						paused != nil && paused.Status == metav1.ConditionFalse &&
+						degraded != nil && degraded.Status == metav1.ConditionTrue
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+			})
+
 			By("removing service monitor when disabled", func() {
 				Expect(utils.VerifyServiceMonitorExists(k8sClient, name, ns)).To(BeTrue())
 				resource.Spec.FeatureGates = &monitoringthanosiov1alpha1.FeatureGates{
@@ -263,18 +852,34 @@ config:
 						Enable: ptr.To(false),
 					},
 				}
-				Expect(k8sClient.Update(context.Background(), resource)).Should(Succeed())
+				updateQuerySpec(ctx, resource)
 
 				Eventually(func() bool {
 					return utils.VerifyServiceMonitorExists(k8sClient, name, ns)
 				}, time.Minute*1, time.Second*10).Should(BeFalse())
 			})
 
+			By("removing the query frontend resources when the query frontend is removed", func() {
+				frontend := QueryFrontendNameFromParent(resourceName)
+				verifier := utils.Verifier{}.WithDeployment().WithService().WithServiceAccount()
+				Expect(verifier.Verify(k8sClient, frontend, ns)).To(BeTrue())
+
+				resource.Spec.QueryFrontend = nil
+				updateQuerySpec(ctx, resource)
+
+				Eventually(func() bool {
+					return utils.VerifyDeploymentExists(k8sClient, frontend, ns) ||
+						utils.VerifyServiceExists(k8sClient, frontend, ns) ||
+						utils.VerifyServiceAccountExists(k8sClient, frontend, ns)
+				}, time.Minute*1, time.Second*2).Should(BeFalse())
+				Expect(utils.VerifyDeploymentExists(k8sClient, name, ns)).To(BeTrue())
+			})
+
 			By("checking paused state", func() {
 				isPaused := true
 				resource.Spec.Paused = &isPaused
 
-				Expect(k8sClient.Update(context.Background(), resource)).Should(Succeed())
+				updateQuerySpec(ctx, resource)
 				labels := requiredStoreServiceLabels
 				labels[string(manifests.StrictLabel)] = manifests.DefaultStoreAPIValue
 				svcPaused := &corev1.Service{
@@ -306,7 +911,28 @@ config:
 
 					return nil
 				}, time.Second*10, time.Second*10).Should(Succeed())
+
+				Eventually(func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					c := meta.FindStatusCondition(resource.Status.Conditions, monitoringthanosiov1alpha1.ConditionPaused)
+					return c != nil && c.Status == metav1.ConditionTrue
+				}, time.Second*30, time.Second*2).Should(BeTrue())
 			})
 		})
 	})
 })
+
+// updateQuerySpec updates the spec of the ThanosQuery to the one of the given object,
+// retrying on conflicts with status updates made by the controller.
+func updateQuerySpec(ctx context.Context, query *monitoringthanosiov1alpha1.ThanosQuery) {
+	spec := query.Spec.DeepCopy()
+	EventuallyWithOffset(1, func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(query), query); err != nil {
+			return err
+		}
+		query.Spec = *spec
+		return k8sClient.Update(ctx, query)
+	}, time.Second*10, time.Second).Should(Succeed())
+}
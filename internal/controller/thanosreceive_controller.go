@@ -19,21 +19,28 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/go-logr/logr"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus/common/model"
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
 	"github.com/thanos-community/thanos-operator/internal/pkg/handlers"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestreceive "github.com/thanos-community/thanos-operator/internal/pkg/manifests/receive"
 	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
+	"github.com/thanos-community/thanos-operator/internal/pkg/profile"
 	"github.com/thanos-community/thanos-operator/internal/pkg/receive"
+	"github.com/thanos-community/thanos-operator/internal/pkg/versionpolicy"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestreceive "github.com/thanos-community/thanos-operator/pkg/manifests/receive"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -58,28 +65,40 @@ type ThanosReceiveReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	logger   logr.Logger
-	metrics  controllermetrics.ThanosReceiveMetrics
-	recorder record.EventRecorder
+	logger           logr.Logger
+	metrics          controllermetrics.ThanosReceiveMetrics
+	reconcileMetrics *controllermetrics.ReconcileMetrics
+	recorder         record.EventRecorder
 
-	handler *handlers.Handler
+	handler          *handlers.Handler
+	versionPolicy    *versionpolicy.Policy
+	controllerConfig ControllerConfig
 }
 
 // NewThanosReceiveReconciler returns a reconciler for ThanosReceive resources.
 func NewThanosReceiveReconciler(conf Config, client client.Client, scheme *runtime.Scheme) *ThanosReceiveReconciler {
 	handler := handlers.NewHandler(client, scheme, conf.InstrumentationConfig.Logger)
+	handler.SetLogSampler(conf.InstrumentationConfig.LogSampler)
 	featureGates := conf.FeatureGate.ToGVK()
 	if len(featureGates) > 0 {
 		handler.SetFeatureGates(featureGates)
 	}
+	handler.SetImagePolicy(conf.ImagePolicy)
+	handler.SetMaintenance(conf.Maintenance)
+	handler.SetApplyRetryBudget(conf.ApplyRetryBudget)
+	handler.SetWriteLimit(conf.WriteLimit, conf.WriteLimitWindow)
+	handler.SetForbiddenProbeInterval(conf.ForbiddenProbeInterval)
 
 	return &ThanosReceiveReconciler{
-		Client:   client,
-		Scheme:   scheme,
-		logger:   conf.InstrumentationConfig.Logger,
-		metrics:  controllermetrics.NewThanosReceiveMetrics(conf.InstrumentationConfig.MetricsRegistry),
-		recorder: conf.InstrumentationConfig.EventRecorder,
-		handler:  handler,
+		Client:           client,
+		Scheme:           scheme,
+		logger:           conf.InstrumentationConfig.Logger,
+		metrics:          controllermetrics.NewThanosReceiveMetrics(conf.InstrumentationConfig.MetricsRegistry),
+		reconcileMetrics: conf.InstrumentationConfig.ReconcileMetrics,
+		recorder:         conf.InstrumentationConfig.EventRecorder,
+		handler:          handler,
+		controllerConfig: conf.Controller,
+		versionPolicy:    conf.VersionPolicy,
 	}
 }
 
@@ -100,6 +119,10 @@ func (r *ThanosReceiveReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		r.recorder.Event(receiver, corev1.EventTypeWarning, "GetFailed", "Failed to get ThanosReceive resource")
 		return ctrl.Result{}, err
 	}
+	start := time.Now()
+	defer func() { r.reconcileMetrics.ObserveApply(r.handler.LogApplySummary(receiver, start)) }()
+	ctx, prof := startProfiling(ctx, receiver.Spec.FeatureGates)
+	defer recordProfile(r.recorder, r.reconcileMetrics, receiver, prof)
 
 	if receiver.Spec.Paused != nil && *receiver.Spec.Paused {
 		r.logger.Info("receiver is paused")
@@ -113,21 +136,127 @@ func (r *ThanosReceiveReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return r.handleDeletionTimestamp(receiver)
 	}
 
-	err = r.syncResources(ctx, *receiver)
+	if err := validateAdditional(receiver.Spec.Router.Additional, receiver.Spec.Ingester.Additional); err != nil {
+		r.logger.Error(err, "invalid additional configuration for ThanosReceive")
+		r.recorder.Event(receiver, corev1.EventTypeWarning, "InvalidSpec", fmt.Sprintf("Invalid additional configuration: %v", err))
+		return ctrl.Result{}, nil
+	}
+
+	opts := []manifests.Options{receiverV1Alpha1ToRouterOptions(*receiver).Options}
+	for _, hashring := range receiver.Spec.Ingester.Hashrings {
+		opts = append(opts, receiverV1Alpha1ToIngesterOptions(*receiver, hashring).Options)
+	}
+	if err := checkOptions(ctx, r.Client, r.recorder, receiver, &receiver.Status.Conditions, opts...); err != nil {
+		r.logger.Error(err, "invalid options for ThanosReceive")
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := checkVersions(ctx, r.Client, r.recorder, receiver, &receiver.Status.Conditions, r.versionPolicy, receiveVersions(*receiver)); err != nil {
+		r.logger.Error(err, "failed to check versions of ThanosReceive")
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := checkObjectStorage(ctx, r.Client, r.recorder, receiver, &receiver.Status.Conditions,
+		objectStoragePreflight(receiver.Spec.FeatureGates), receiveObjectStorageSelectors(*receiver)); err != nil {
+		r.logger.Error(err, "failed to check object storage of ThanosReceive")
+		if errors.Is(err, errInvalidObjectStorage) {
+			return ctrl.Result{RequeueAfter: objectStoragePreflightRequeueInterval}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	restorePod := receiver.GetAnnotations()[monitoringthanosiov1alpha1.RestoreIngesterAnnotation]
+	restoring, restored, err := restoreIngester(ctx, r.Client, receiver)
+	if err != nil {
+		r.logger.Error(err, "failed to restore ingester", "pod", restorePod)
+		r.recorder.Event(receiver, corev1.EventTypeWarning, "RestoreFailed", fmt.Sprintf("Failed to restore ingester %s: %v", restorePod, err))
+	} else if restored {
+		r.recorder.Event(receiver, corev1.EventTypeNormal, "IngesterRestored", fmt.Sprintf("Restored the volume of ingester %s from its latest snapshot", restorePod))
+	}
+
+	err = r.syncResources(ctx, *receiver, restoring)
+	if blockedErr := r.handler.ApplyBlocked(receiver); blockedErr != nil {
+		err = blockedErr
+	}
+	requeueAfter, throttledErr := r.handler.ApplyThrottled(receiver)
+	if err == nil && throttledErr != nil {
+		err = throttledErr
+	}
+	probeAfter, permissionsErr := r.handler.MissingPermissions(receiver)
+	if statusErr := updateBlockedCondition(ctx, r.Client, receiver, &receiver.Status.Conditions, err); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update blocked condition")
+	}
+	if statusErr := updateMissingPermissionsCondition(ctx, r.Client, receiver, &receiver.Status.Conditions, permissionsErr); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update missing permissions condition")
+	}
+	if statusErr := r.updateHashringStatus(ctx, receiver); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update hashring status")
+	}
+	if errors.Is(err, handlers.ErrRolloutDeferred) {
+		r.recorder.Event(receiver, corev1.EventTypeNormal, "RolloutDeferred", err.Error())
+		return ctrl.Result{RequeueAfter: rolloutDeferredRequeueInterval}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyThrottled) {
+		r.recorder.Event(receiver, corev1.EventTypeNormal, "ApplyThrottled", err.Error())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyBlocked) {
+		// retrying will not help until the spec or the reconcile-now annotation changes, which triggers a new reconciliation
+		r.recorder.Event(receiver, corev1.EventTypeWarning, "ApplyBlocked", err.Error())
+		return ctrl.Result{}, nil
+	}
 	if err != nil {
 		r.recorder.Event(receiver, corev1.EventTypeWarning, "SyncFailed", fmt.Sprintf("Failed to sync resources: %v", err))
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	if err := r.handler.CompleteRollout(ctx, receiver); err != nil {
+		r.logger.Error(err, "failed to complete rollout")
+		return ctrl.Result{}, err
+	}
+
+	if permissionsErr != nil {
+		// the objects are applied again at the next probe, retrying sooner would only be denied again
+		r.recorder.Event(receiver, corev1.EventTypeWarning, "MissingPermissions", permissionsErr.Error())
+		return ctrl.Result{RequeueAfter: probeAfter}, nil
+	}
+
+	if err := updateCrashLoopingCondition(ctx, r.Client, r.recorder, receiver, &receiver.Status.Conditions); err != nil {
+		r.logger.Error(err, "failed to update crash looping condition")
+	}
+	if err := updateDegradedCondition(ctx, r.Client, receiver, &receiver.Status.Conditions); err != nil {
+		r.logger.Error(err, "failed to update degraded condition")
+	}
+
+	nextSnapshot, err := syncIngesterSnapshots(ctx, r.Client, r.Scheme, receiver)
+	if err != nil {
+		r.logger.Error(err, "failed to sync ingester snapshots")
+		r.recorder.Event(receiver, corev1.EventTypeWarning, "SnapshotFailed", fmt.Sprintf("Failed to sync ingester snapshots: %v", err))
+	}
+
+	if restoring != "" {
+		return ctrl.Result{RequeueAfter: restoreRequeueInterval}, nil
+	}
+	return ctrl.Result{RequeueAfter: nextSnapshot}, nil
 }
 
 // +kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosreceives,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosreceives/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosreceives/finalizers,verbs=update
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
 // +kubebuilder:rbac:groups=apps,resources=statefulsets;deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services;configmaps;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="discovery.k8s.io",resources=endpointslices,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanostenants,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;delete
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;create;delete
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ThanosReceiveReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -154,6 +283,7 @@ func (r *ThanosReceiveReconciler) buildController(bld builder.Builder) error {
 
 	bld.
 		For(&monitoringthanosiov1alpha1.ThanosReceive{}).
+		WithOptions(r.controllerConfig.options()).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&corev1.Service{}).
@@ -163,6 +293,15 @@ func (r *ThanosReceiveReconciler) buildController(bld builder.Builder) error {
 			&discoveryv1.EndpointSlice{},
 			r.enqueueForEndpointSlice(r.Client),
 			builder.WithPredicates(predicate.GenerationChangedPredicate{}, endpointSlicePredicate),
+		).
+		Watches(
+			&monitoringthanosiov1alpha1.ThanosTenant{},
+			r.enqueueForTenant(),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&monitoringthanosiov1alpha1.ThanosQuery{},
+			enqueueForStack(r.Client, &monitoringthanosiov1alpha1.ThanosReceiveList{}),
 		)
 
 	return bld.Complete(r)
@@ -170,14 +309,33 @@ func (r *ThanosReceiveReconciler) buildController(bld builder.Builder) error {
 
 // syncResources syncs the resources for the ThanosReceive resource.
 // It creates or updates the resources for the hashrings and the router.
-func (r *ThanosReceiveReconciler) syncResources(ctx context.Context, receiver monitoringthanosiov1alpha1.ThanosReceive) error {
+// The StatefulSet named restoring, if any, is not applied while the volume of one of its ingesters is being restored.
+func (r *ThanosReceiveReconciler) syncResources(ctx context.Context, receiver monitoringthanosiov1alpha1.ThanosReceive, restoring string) error {
 	var errCount int
 
-	ingestOpts := r.specToIngestOptions(receiver)
+	ingestOpts, err := r.specToIngestOptions(ctx, receiver)
+	if err != nil {
+		return err
+	}
 	expectIngesters := make([]string, len(ingestOpts))
 	for i, opt := range ingestOpts {
 		expectIngesters[i] = opt.GetGeneratedResourceName()
-		errCount += r.handler.CreateOrUpdate(ctx, receiver.GetNamespace(), &receiver, opt.Build())
+		stop := profile.FromContext(ctx).Start("render")
+		objs := opt.Build()
+		stop()
+		objs = slices.DeleteFunc(objs, func(obj client.Object) bool {
+			_, ok := obj.(*appsv1.StatefulSet)
+			return ok && obj.GetName() == restoring
+		})
+		if err := r.handler.ApplyImagePolicy(ctx, objs); err != nil {
+			return err
+		}
+		if err := r.handler.CoordinateRollout(ctx, &receiver, objs); err != nil {
+			return err
+		}
+		// claims are updated before the apply, since the immutable claim templates of existing StatefulSets are not updated
+		errCount += r.handler.UpdateVolumeClaimMetadata(ctx, receiver.GetNamespace(), objs)
+		errCount += r.handler.CreateOrUpdate(ctx, receiver.GetNamespace(), &receiver, objs)
 	}
 
 	if errCount > 0 {
@@ -194,9 +352,22 @@ func (r *ThanosReceiveReconciler) syncResources(ctx context.Context, receiver mo
 	if err != nil {
 		return fmt.Errorf("failed to build hashring config: %w", err)
 	}
-	routerOpts := r.specToRouterOptions(receiver, string(hashringConfig))
+	limitsConfig, err := r.buildLimitsConfig(ctx, receiver)
+	if err != nil {
+		return fmt.Errorf("failed to build limits config: %w", err)
+	}
+	routerOpts := r.specToRouterOptions(receiver, string(hashringConfig), limitsConfig)
 
-	if errs := r.handler.CreateOrUpdate(ctx, receiver.GetNamespace(), &receiver, routerOpts.Build()); errs > 0 {
+	stop := profile.FromContext(ctx).Start("render")
+	routerObjs := routerOpts.Build()
+	stop()
+	if err := r.handler.ApplyImagePolicy(ctx, routerObjs); err != nil {
+		return err
+	}
+	if err := r.handler.CoordinateRollout(ctx, &receiver, routerObjs); err != nil {
+		return err
+	}
+	if errs := r.handler.CreateOrUpdate(ctx, receiver.GetNamespace(), &receiver, routerObjs); errs > 0 {
 		return fmt.Errorf("failed to create or update %d resources for the receive router", errs)
 	}
 
@@ -213,22 +384,59 @@ func (r *ThanosReceiveReconciler) syncResources(ctx context.Context, receiver mo
 	return nil
 }
 
-func (r *ThanosReceiveReconciler) specToIngestOptions(receiver monitoringthanosiov1alpha1.ThanosReceive) []manifests.Buildable {
+func (r *ThanosReceiveReconciler) specToIngestOptions(ctx context.Context, receiver monitoringthanosiov1alpha1.ThanosReceive) ([]manifests.Buildable, error) {
+	_, boundary, split, err := stackTimeSplit(ctx, r.Client, &receiver)
+	if err != nil {
+		return nil, err
+	}
+
 	opts := make([]manifests.Buildable, len(receiver.Spec.Ingester.Hashrings))
 	for i, v := range receiver.Spec.Ingester.Hashrings {
 		opt := receiverV1Alpha1ToIngesterOptions(receiver, v)
 		opt.HashringName = v.Name
+		// the ingesters must retain data until the store gateways of the stack serve it
+		if retention, err := model.ParseDuration(opt.Retention); split && (err != nil || retention < boundary) {
+			opt.Retention = boundary.String()
+		}
 		opts[i] = opt
 	}
-	return opts
+	return opts, nil
 }
 
-func (r *ThanosReceiveReconciler) specToRouterOptions(receiver monitoringthanosiov1alpha1.ThanosReceive, hashringConfig string) manifests.Buildable {
+func (r *ThanosReceiveReconciler) specToRouterOptions(receiver monitoringthanosiov1alpha1.ThanosReceive, hashringConfig, limitsConfig string) manifests.Buildable {
 	opts := receiverV1Alpha1ToRouterOptions(receiver)
 	opts.HashringConfig = hashringConfig
+	opts.LimitsConfig = limitsConfig
 	return opts
 }
 
+// buildLimitsConfig builds the limits configuration for the router from the limits of the ThanosReceive resource
+// and of the ThanosTenant resources that reference it.
+func (r *ThanosReceiveReconciler) buildLimitsConfig(ctx context.Context, receiver monitoringthanosiov1alpha1.ThanosReceive) (string, error) {
+	defer profile.FromContext(ctx).Start("discovery")()
+	tenants, err := r.getTenants(ctx, receiver)
+	if err != nil {
+		return "", err
+	}
+	return manifestreceive.BuildLimitsConfigFrom(receiveLimitsV1Alpha1ToLimitsConfig(receiver, tenants))
+}
+
+// getTenants returns the ThanosTenant resources that reference the ThanosReceive resource.
+func (r *ThanosReceiveReconciler) getTenants(ctx context.Context, receiver monitoringthanosiov1alpha1.ThanosReceive) ([]monitoringthanosiov1alpha1.ThanosTenant, error) {
+	tenantList := &monitoringthanosiov1alpha1.ThanosTenantList{}
+	if err := r.List(ctx, tenantList, client.InNamespace(receiver.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list tenants for resource %s: %w", receiver.GetName(), err)
+	}
+
+	var tenants []monitoringthanosiov1alpha1.ThanosTenant
+	for _, tenant := range tenantList.Items {
+		if tenant.Spec.ReceiveName == receiver.GetName() && tenant.GetDeletionTimestamp().IsZero() {
+			tenants = append(tenants, tenant)
+		}
+	}
+	return tenants, nil
+}
+
 func (r *ThanosReceiveReconciler) pruneOrphanedResources(ctx context.Context, ns, owner string, expectShards []string) int {
 	listOpt := manifests.GetLabelSelectorForOwner(manifestreceive.IngesterOptions{Options: manifests.Options{Owner: owner}})
 	listOpts := []client.ListOption{listOpt, client.InNamespace(ns)}
@@ -239,6 +447,7 @@ func (r *ThanosReceiveReconciler) pruneOrphanedResources(ctx context.Context, ns
 
 // buildHashringConfig builds the hashring configuration for the ThanosReceive resource.
 func (r *ThanosReceiveReconciler) buildHashringConfig(ctx context.Context, receiver monitoringthanosiov1alpha1.ThanosReceive) ([]byte, error) {
+	defer profile.FromContext(ctx).Start("discovery")()
 	cm := &corev1.ConfigMap{}
 	name := ReceiveRouterNameFromParent(receiver.GetName())
 	err := r.Client.Get(ctx, client.ObjectKey{Namespace: receiver.GetNamespace(), Name: name}, cm)
@@ -289,6 +498,64 @@ func (r *ThanosReceiveReconciler) buildHashringConfig(ctx context.Context, recei
 	return b, nil
 }
 
+// updateHashringStatus reports the hashrings configured in the routers, as read from the hashring ConfigMap,
+// in the status of the ThanosReceive, together with the values deployed for the defaulted fields of the routers
+// and of the ingesters of each hashring. The status is only written if it changed.
+func (r *ThanosReceiveReconciler) updateHashringStatus(ctx context.Context, receiver *monitoringthanosiov1alpha1.ThanosReceive) error {
+	cm := &corev1.ConfigMap{}
+	name := ReceiveRouterNameFromParent(receiver.GetName())
+	if err := r.Get(ctx, client.ObjectKey{Namespace: receiver.GetNamespace(), Name: name}, cm); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get config map for resource %s: %w", name, err)
+	}
+
+	var hashrings receive.Hashrings
+	config := cm.Data[manifestreceive.HashringConfigKey]
+	if config != "" {
+		if err := json.Unmarshal([]byte(config), &hashrings); err != nil {
+			return fmt.Errorf("failed to unmarshal hashring config from ConfigMap: %w", err)
+		}
+	}
+
+	status := make([]monitoringthanosiov1alpha1.HashringStatus, 0, len(hashrings))
+	for _, hashring := range hashrings {
+		// the empty placeholder config of the routers has no named hashring
+		if hashring.Name == "" {
+			continue
+		}
+		members := make([]string, len(hashring.Endpoints))
+		for i, ep := range hashring.Endpoints {
+			members[i] = ep.Address
+		}
+		hashringStatus := monitoringthanosiov1alpha1.HashringStatus{
+			Name:              hashring.Name,
+			Tenants:           hashring.Tenants,
+			TenantMatcherType: string(hashring.TenantMatcherType),
+			Members:           members,
+		}
+		for _, spec := range receiver.Spec.Ingester.Hashrings {
+			if spec.Name == hashring.Name {
+				hashringStatus.Defaults = effectiveDefaultsFromOpts(receiverV1Alpha1ToIngesterOptions(*receiver, spec).Options)
+			}
+		}
+		status = append(status, hashringStatus)
+	}
+	var hash string
+	if len(status) > 0 {
+		hash = receive.Hash([]byte(config))
+	}
+
+	defaults := effectiveDefaultsFromOpts(receiverV1Alpha1ToRouterOptions(*receiver).Options)
+
+	if receiver.Status.HashringsHash == hash && equality.Semantic.DeepEqual(receiver.Status.Hashrings, status) &&
+		equality.Semantic.DeepEqual(receiver.Status.Defaults, defaults) {
+		return nil
+	}
+	receiver.Status.HashringsHash = hash
+	receiver.Status.Hashrings = status
+	receiver.Status.Defaults = defaults
+	return r.Status().Update(ctx, receiver)
+}
+
 func (r *ThanosReceiveReconciler) handleDeletionTimestamp(receiveHashring *monitoringthanosiov1alpha1.ThanosReceive) (ctrl.Result, error) {
 	if controllerutil.ContainsFinalizer(receiveHashring, receiveFinalizer) {
 		r.logger.Info("performing Finalizer Operations for ThanosReceiveHashring before delete CR")
@@ -329,3 +596,23 @@ func (r *ThanosReceiveReconciler) enqueueForEndpointSlice(c client.Client) handl
 		}
 	})
 }
+
+// enqueueForTenant enqueues requests for the ThanosReceive resource referenced by a ThanosTenant.
+func (r *ThanosReceiveReconciler) enqueueForTenant() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		tenant, ok := obj.(*monitoringthanosiov1alpha1.ThanosTenant)
+		if !ok {
+			return nil
+		}
+
+		r.metrics.TenantWatchesReconciliationsTotal.Inc()
+		return []reconcile.Request{
+			{
+				NamespacedName: types.NamespacedName{
+					Namespace: tenant.GetNamespace(),
+					Name:      tenant.Spec.ReceiveName,
+				},
+			},
+		}
+	})
+}
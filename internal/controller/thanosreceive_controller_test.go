@@ -20,18 +20,20 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests/receive"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests/receive"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -122,8 +124,10 @@ config:
 					},
 					Ingester: monitoringthanosiov1alpha1.IngesterSpec{
 						DefaultObjectStorageConfig: monitoringthanosiov1alpha1.ObjectStorageConfig{
-							LocalObjectReference: corev1.LocalObjectReference{Name: "test-secret"},
-							Key:                  "test-key",
+							SecretKeySelector: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "test-secret"},
+								Key:                  "test-key",
+							},
 						},
 						Hashrings: []monitoringthanosiov1alpha1.IngesterHashringSpec{
 							{
@@ -186,8 +190,10 @@ config:
 					},
 					Ingester: monitoringthanosiov1alpha1.IngesterSpec{
 						DefaultObjectStorageConfig: monitoringthanosiov1alpha1.ObjectStorageConfig{
-							LocalObjectReference: corev1.LocalObjectReference{Name: "test-secret"},
-							Key:                  "test-key",
+							SecretKeySelector: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "test-secret"},
+								Key:                  "test-key",
+							},
 						},
 						Hashrings: []monitoringthanosiov1alpha1.IngesterHashringSpec{
 							{
@@ -344,6 +350,37 @@ config:
 				}, time.Minute*1, time.Second*1).Should(BeTrue())
 			})
 
+			By("reporting the hashrings configured in the routers in the status", func() {
+				svcName := ingesterName
+				Eventually(func() []monitoringthanosiov1alpha1.HashringStatus {
+					updated := &monitoringthanosiov1alpha1.ThanosReceive{}
+					if err := k8sClient.Get(context.Background(), typeNamespacedName, updated); err != nil || updated.Status.HashringsHash == "" {
+						return nil
+					}
+					return updated.Status.Hashrings
+				}, time.Minute*1, time.Second*1).Should(Equal([]monitoringthanosiov1alpha1.HashringStatus{{
+					Name:              "test-hashring",
+					Tenants:           []string{"test-tenant"},
+					TenantMatcherType: "exact",
+					Members: []string{
+						fmt.Sprintf("some-hostname-b.%s.treceive.svc.cluster.local:10901", svcName),
+						fmt.Sprintf("some-hostname-c.%s.treceive.svc.cluster.local:10901", svcName),
+						fmt.Sprintf("some-hostname.%s.treceive.svc.cluster.local:10901", svcName),
+					},
+				}}))
+			})
+
+			By("creating a quorum preserving pod disruption budget for ingesters", func() {
+				Eventually(func() bool {
+					pdb := &policyv1.PodDisruptionBudget{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: ingesterName, Namespace: ns}, pdb); err != nil {
+						return false
+					}
+					return pdb.Spec.MaxUnavailable != nil &&
+						pdb.Spec.MaxUnavailable.IntVal == receive.QuorumMaxUnavailable(resource.Spec.Router.ReplicationFactor)
+				}, time.Second*10, time.Second*1).Should(BeTrue())
+			})
+
 			By("creating the additional container for ingesters", func() {
 				Eventually(func() bool {
 					return utils.VerifyStatefulSetArgs(
@@ -411,6 +448,30 @@ config:
 				}, time.Second*10, time.Second*1).Should(BeTrue())
 			})
 
+			By("rendering the limits of the router into the limits config", func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.Router.Limits = &monitoringthanosiov1alpha1.ReceiveLimits{
+					MaxConcurrency: ptr.To(int32(30)),
+					Tenants: []monitoringthanosiov1alpha1.ReceiveTenantLimits{
+						{Tenant: "team-b", Limits: monitoringthanosiov1alpha1.TenantLimits{SamplesLimit: ptr.To(int64(5000))}},
+					},
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: routerName, Namespace: ns}, cm); err != nil {
+						return false
+					}
+					return strings.Contains(cm.Data[receive.LimitsConfigKey], "max_concurrency: 30") &&
+						strings.Contains(cm.Data[receive.LimitsConfigKey], "samples_limit: 5000")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, routerName, ns, 0, "--receive.limits-config-file=var/lib/thanos-receive/limits.yaml")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
 			By("checking paused state", func() {
 				resource.Spec.Paused = ptr.To(true)
 				resource.Spec.Router.CommonFields.LogLevel = ptr.To("debug")
@@ -18,16 +18,22 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"time"
 
 	"github.com/go-logr/logr"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
 	"github.com/thanos-community/thanos-operator/internal/pkg/handlers"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestruler "github.com/thanos-community/thanos-operator/internal/pkg/manifests/ruler"
 	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
+	"github.com/thanos-community/thanos-operator/internal/pkg/profile"
+	"github.com/thanos-community/thanos-operator/internal/pkg/versionpolicy"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestruler "github.com/thanos-community/thanos-operator/pkg/manifests/ruler"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -53,38 +59,54 @@ type ThanosRulerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	logger   logr.Logger
-	metrics  controllermetrics.ThanosRulerMetrics
-	recorder record.EventRecorder
+	logger           logr.Logger
+	metrics          controllermetrics.ThanosRulerMetrics
+	reconcileMetrics *controllermetrics.ReconcileMetrics
+	recorder         record.EventRecorder
 
-	handler *handlers.Handler
+	handler          *handlers.Handler
+	versionPolicy    *versionpolicy.Policy
+	controllerConfig ControllerConfig
 }
 
 // NewThanosRulerReconciler returns a reconciler for ThanosRuler resources.
 func NewThanosRulerReconciler(conf Config, client client.Client, scheme *runtime.Scheme) *ThanosRulerReconciler {
 	handler := handlers.NewHandler(client, scheme, conf.InstrumentationConfig.Logger)
+	handler.SetLogSampler(conf.InstrumentationConfig.LogSampler)
 	featureGates := conf.FeatureGate.ToGVK()
 	if len(featureGates) > 0 {
 		handler.SetFeatureGates(featureGates)
 	}
+	handler.SetImagePolicy(conf.ImagePolicy)
+	handler.SetMaintenance(conf.Maintenance)
+	handler.SetApplyRetryBudget(conf.ApplyRetryBudget)
+	handler.SetWriteLimit(conf.WriteLimit, conf.WriteLimitWindow)
+	handler.SetForbiddenProbeInterval(conf.ForbiddenProbeInterval)
 
 	return &ThanosRulerReconciler{
-		Client:   client,
-		Scheme:   scheme,
-		logger:   conf.InstrumentationConfig.Logger,
-		metrics:  controllermetrics.NewThanosRulerMetrics(conf.InstrumentationConfig.MetricsRegistry),
-		recorder: conf.InstrumentationConfig.EventRecorder,
-		handler:  handler,
+		Client:           client,
+		Scheme:           scheme,
+		logger:           conf.InstrumentationConfig.Logger,
+		metrics:          controllermetrics.NewThanosRulerMetrics(conf.InstrumentationConfig.MetricsRegistry),
+		reconcileMetrics: conf.InstrumentationConfig.ReconcileMetrics,
+		recorder:         conf.InstrumentationConfig.EventRecorder,
+		handler:          handler,
+		controllerConfig: conf.Controller,
+		versionPolicy:    conf.VersionPolicy,
 	}
 }
 
 // +kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosrulers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosrulers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosrulers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services;configmaps;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosstores;thanosreceives;thanoscompacts;thanosqueries,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -103,6 +125,10 @@ func (r *ThanosRulerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		r.recorder.Event(ruler, corev1.EventTypeWarning, "GetFailed", "Failed to get ThanosRuler resource")
 		return ctrl.Result{}, err
 	}
+	start := time.Now()
+	defer func() { r.reconcileMetrics.ObserveApply(r.handler.LogApplySummary(ruler, start)) }()
+	ctx, prof := startProfiling(ctx, ruler.Spec.FeatureGates)
+	defer recordProfile(r.recorder, r.reconcileMetrics, ruler, prof)
 
 	if ruler.Spec.Paused != nil && *ruler.Spec.Paused {
 		r.logger.Info("reconciliation is paused for ThanosRuler resource")
@@ -110,12 +136,91 @@ func (r *ThanosRulerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
+	if err := validateAdditional(ruler.Spec.Additional); err != nil {
+		r.logger.Error(err, "invalid additional configuration for ThanosRuler")
+		r.recorder.Event(ruler, corev1.EventTypeWarning, "InvalidSpec", fmt.Sprintf("Invalid additional configuration: %v", err))
+		return ctrl.Result{}, nil
+	}
+
+	if err := checkOptions(ctx, r.Client, r.recorder, ruler, &ruler.Status.Conditions, rulerV1Alpha1ToOptions(*ruler).Options); err != nil {
+		r.logger.Error(err, "invalid options for ThanosRuler")
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := checkVersions(ctx, r.Client, r.recorder, ruler, &ruler.Status.Conditions, r.versionPolicy, rulerVersions(*ruler)); err != nil {
+		r.logger.Error(err, "failed to check versions of ThanosRuler")
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := checkObjectStorage(ctx, r.Client, r.recorder, ruler, &ruler.Status.Conditions,
+		objectStoragePreflight(ruler.Spec.FeatureGates), []corev1.SecretKeySelector{ruler.Spec.ObjectStorageConfig.ToSecretKeySelector()}); err != nil {
+		r.logger.Error(err, "failed to check object storage of ThanosRuler")
+		if errors.Is(err, errInvalidObjectStorage) {
+			return ctrl.Result{RequeueAfter: objectStoragePreflightRequeueInterval}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
 	err = r.syncResources(ctx, *ruler)
+	if blockedErr := r.handler.ApplyBlocked(ruler); blockedErr != nil {
+		err = blockedErr
+	}
+	requeueAfter, throttledErr := r.handler.ApplyThrottled(ruler)
+	if err == nil && throttledErr != nil {
+		err = throttledErr
+	}
+	probeAfter, permissionsErr := r.handler.MissingPermissions(ruler)
+	if statusErr := updateBlockedCondition(ctx, r.Client, ruler, &ruler.Status.Conditions, err); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update blocked condition")
+	}
+	if statusErr := updateMissingPermissionsCondition(ctx, r.Client, ruler, &ruler.Status.Conditions, permissionsErr); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update missing permissions condition")
+	}
+	if errors.Is(err, handlers.ErrRolloutDeferred) {
+		r.recorder.Event(ruler, corev1.EventTypeNormal, "RolloutDeferred", err.Error())
+		return ctrl.Result{RequeueAfter: rolloutDeferredRequeueInterval}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyThrottled) {
+		r.recorder.Event(ruler, corev1.EventTypeNormal, "ApplyThrottled", err.Error())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyBlocked) {
+		// retrying will not help until the spec or the reconcile-now annotation changes, which triggers a new reconciliation
+		r.recorder.Event(ruler, corev1.EventTypeWarning, "ApplyBlocked", err.Error())
+		return ctrl.Result{}, nil
+	}
 	if err != nil {
 		r.recorder.Event(ruler, corev1.EventTypeWarning, "SyncFailed", fmt.Sprintf("Failed to sync resources: %v", err))
 		return ctrl.Result{}, err
 	}
 
+	if err := r.handler.CompleteRollout(ctx, ruler); err != nil {
+		r.logger.Error(err, "failed to complete rollout")
+		return ctrl.Result{}, err
+	}
+
+	if permissionsErr != nil {
+		// the objects are applied again at the next probe, retrying sooner would only be denied again
+		r.recorder.Event(ruler, corev1.EventTypeWarning, "MissingPermissions", permissionsErr.Error())
+		return ctrl.Result{RequeueAfter: probeAfter}, nil
+	}
+
+	if err := updateCrashLoopingCondition(ctx, r.Client, r.recorder, ruler, &ruler.Status.Conditions); err != nil {
+		r.logger.Error(err, "failed to update crash looping condition")
+	}
+	if err := updateDegradedCondition(ctx, r.Client, ruler, &ruler.Status.Conditions); err != nil {
+		r.logger.Error(err, "failed to update degraded condition")
+	}
+	if err := updateDefaultsStatus(ctx, r.Client, ruler, &ruler.Status.Defaults, rulerV1Alpha1ToOptions(*ruler).Options); err != nil {
+		r.logger.Error(err, "failed to update defaults status")
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -129,6 +234,20 @@ func (r *ThanosRulerReconciler) syncResources(ctx context.Context, ruler monitor
 
 	objs = append(objs, desiredObjs...)
 
+	progress, err := stackUpgradeProgress(ctx, r.Client, &ruler)
+	if err != nil {
+		return err
+	}
+	if err := deferredUpgrade(progress, "ThanosRuler", ruler.GetName(), versionPolicyRuler); err != nil {
+		return err
+	}
+
+	if err := r.handler.ApplyImagePolicy(ctx, objs); err != nil {
+		return err
+	}
+	if err := r.handler.CoordinateRollout(ctx, &ruler, objs); err != nil {
+		return err
+	}
 	if errCount := r.handler.CreateOrUpdate(ctx, ruler.GetNamespace(), &ruler, objs); errCount > 0 {
 		return fmt.Errorf("failed to create or update %d resources for the ruler", errCount)
 	}
@@ -157,7 +276,12 @@ func (r *ThanosRulerReconciler) buildRuler(ctx context.Context, ruler monitoring
 		return []client.Object{}, fmt.Errorf("no query API services found")
 	}
 
-	ruleFiles, err := r.getRuleConfigMaps(ctx, ruler)
+	namespaces, err := r.getRuleNamespaces(ctx, ruler)
+	if err != nil {
+		return []client.Object{}, err
+	}
+
+	ruleFiles, err := r.getRuleConfigMaps(ctx, ruler, namespaces)
 	if err != nil {
 		return []client.Object{}, err
 	}
@@ -165,7 +289,7 @@ func (r *ThanosRulerReconciler) buildRuler(ctx context.Context, ruler monitoring
 
 	promRuleConfigMaps := []corev1.ConfigMapKeySelector{}
 	if manifests.HasPrometheusRuleEnabled(ruler.Spec.FeatureGates) {
-		promRuleConfigMaps, err = r.getPrometheusRuleConfigMaps(ctx, ruler)
+		promRuleConfigMaps, err = r.getPrometheusRuleConfigMaps(ctx, ruler, namespaces)
 		if err != nil {
 			return []client.Object{}, err
 		}
@@ -186,17 +310,31 @@ func (r *ThanosRulerReconciler) buildRuler(ctx context.Context, ruler monitoring
 		ruleFiles = append(ruleFiles, rf)
 	}
 
+	if err := r.pruneFederatedRuleConfigMaps(ctx, ruler, ruleFiles); err != nil {
+		return []client.Object{}, err
+	}
+
 	r.logger.Info("total rule files to configure", "count", len(ruleFiles), "ruler", ruler.Name)
 	r.metrics.RuleFilesConfigured.WithLabelValues(ruler.GetName(), ruler.GetNamespace()).Set(float64(len(ruleFiles)))
 
+	ingressEnabled, err := stackIngressEnabled(ctx, r.Client, &ruler)
+	if err != nil {
+		return []client.Object{}, err
+	}
+
 	opts := rulerV1Alpha1ToOptions(ruler)
 	opts.Endpoints = endpoints
 	opts.RuleFiles = ruleFiles
+	if ingressEnabled {
+		opts.RoutePrefix = rulerRoutePrefix(ruler.GetName())
+	}
 
+	defer profile.FromContext(ctx).Start("render")()
 	return opts.Build(), nil
 }
 
-// getStoreAPIServiceEndpoints returns the list of endpoints for the QueryAPI services that match the ThanosRuler queryLabelSelector.
+// getQueryAPIServiceEndpoints returns the list of endpoints for the QueryAPI services that match the ThanosRuler queryLabelSelector,
+// sorted by the name of the service.
 func (r *ThanosRulerReconciler) getQueryAPIServiceEndpoints(ctx context.Context, ruler monitoringthanosiov1alpha1.ThanosRuler) ([]manifestruler.Endpoint, error) {
 	labelSelector, err := manifests.BuildLabelSelectorFrom(ruler.Spec.QueryLabelSelector, requiredQueryServiceLabels)
 	if err != nil {
@@ -215,60 +353,147 @@ func (r *ThanosRulerReconciler) getQueryAPIServiceEndpoints(ctx context.Context,
 		return []manifestruler.Endpoint{}, nil
 	}
 
-	endpoints := make([]manifestruler.Endpoint, len(services.Items))
-	for i, svc := range services.Items {
+	endpoints := make([]manifestruler.Endpoint, 0, len(services.Items))
+	for _, svc := range services.Items {
 		port, ok := manifests.IsGrpcServiceWithLabels(&svc, requiredQueryServiceLabels)
 		if !ok {
 			r.logger.Info("service is not a gRPC service", "service", svc.GetName())
 			continue
 		}
 
-		endpoints[i] = manifestruler.Endpoint{
-			Port:        port,
-			ServiceName: svc.GetName(),
-			Namespace:   svc.GetNamespace(),
-		}
+		endpoints = append(endpoints, manifestruler.Endpoint{
+			Port:         port,
+			ServiceName:  svc.GetName(),
+			Namespace:    svc.GetNamespace(),
+			HTTPPortName: manifests.GetHTTPPortName(&svc),
+		})
 	}
+	// the order of the services listed is not stable, which would reorder the arguments of the Ruler
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].ServiceName < endpoints[j].ServiceName
+	})
 
 	r.metrics.EndpointsConfigured.WithLabelValues(ruler.GetName(), ruler.GetNamespace()).Set(float64(len(endpoints)))
 
 	return endpoints, nil
 }
 
+// getRuleNamespaces returns the namespaces from which rules are gathered for the ThanosRuler.
+// The namespace of the ThanosRuler is always included and returned first.
+func (r *ThanosRulerReconciler) getRuleNamespaces(ctx context.Context, ruler monitoringthanosiov1alpha1.ThanosRuler) ([]string, error) {
+	namespaces := []string{ruler.Namespace}
+	if ruler.Spec.RuleNamespaceSelector == nil {
+		return namespaces, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ruler.Spec.RuleNamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rule namespace selector: %w", err)
+	}
+
+	nsList := &corev1.NamespaceList{}
+	if err := r.List(ctx, nsList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	for _, ns := range nsList.Items {
+		if ns.Name == ruler.Namespace || slices.Contains(ruler.Spec.RuleNamespaceDenyList, ns.Name) {
+			continue
+		}
+		namespaces = append(namespaces, ns.Name)
+	}
+	sort.Strings(namespaces[1:])
+	return namespaces, nil
+}
+
+// selectsRuleNamespace returns true if the ThanosRuler gathers rules from the given namespace.
+func selectsRuleNamespace(ruler monitoringthanosiov1alpha1.ThanosRuler, ns *corev1.Namespace) bool {
+	if ns.Name == ruler.Namespace {
+		return true
+	}
+	if ruler.Spec.RuleNamespaceSelector == nil || slices.Contains(ruler.Spec.RuleNamespaceDenyList, ns.Name) {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ruler.Spec.RuleNamespaceSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(ns.GetLabels()))
+}
+
+// federatedRuleConfigMap returns a ConfigMap in the namespace of the ThanosRuler holding rules gathered from another namespace.
+func federatedRuleConfigMap(ruler monitoringthanosiov1alpha1.ThanosRuler, name, sourceNamespace, content string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ruler.Namespace,
+			Labels: map[string]string{
+				manifests.OwnerLabel:               manifests.ValidateAndSanitizeNameToValidLabelValue(ruler.Name),
+				manifests.RuleSourceNamespaceLabel: sourceNamespace,
+			},
+		},
+		Data: map[string]string{
+			name + ".yaml": content,
+		},
+	}
+}
+
 // getRuleConfigMaps returns the list of ruler configmaps of rule files to set on ThanosRuler.
-func (r *ThanosRulerReconciler) getRuleConfigMaps(ctx context.Context, ruler monitoringthanosiov1alpha1.ThanosRuler) ([]corev1.ConfigMapKeySelector, error) {
+// Rule ConfigMaps found in namespaces other than the one of the ThanosRuler are copied into its namespace.
+func (r *ThanosRulerReconciler) getRuleConfigMaps(ctx context.Context, ruler monitoringthanosiov1alpha1.ThanosRuler, namespaces []string) ([]corev1.ConfigMapKeySelector, error) {
+	defer profile.FromContext(ctx).Start("discovery")()
 	labelSelector, err := manifests.BuildLabelSelectorFrom(ruler.Spec.RuleConfigSelector, requiredRuleConfigMapLabels)
 	if err != nil {
 		return nil, err
 	}
 
-	opts := []client.ListOption{client.MatchingLabelsSelector{Selector: labelSelector}, client.InNamespace(ruler.Namespace)}
-	cfgmaps := &corev1.ConfigMapList{}
-	if err := r.List(ctx, cfgmaps, opts...); err != nil {
-		return []corev1.ConfigMapKeySelector{}, err
+	var cfgmaps []corev1.ConfigMap
+	for _, ns := range namespaces {
+		opts := []client.ListOption{client.MatchingLabelsSelector{Selector: labelSelector}, client.InNamespace(ns)}
+		list := &corev1.ConfigMapList{}
+		if err := r.List(ctx, list, opts...); err != nil {
+			return []corev1.ConfigMapKeySelector{}, err
+		}
+		cfgmaps = append(cfgmaps, list.Items...)
 	}
 
-	if len(cfgmaps.Items) == 0 {
+	if len(cfgmaps) == 0 {
 		r.recorder.Event(&ruler, corev1.EventTypeWarning, "NoRuleConfigsFound", "No rule ConfigMaps found")
 		return []corev1.ConfigMapKeySelector{}, nil
 	}
 
 	r.logger.Info("processing rule config maps",
-		"found", len(cfgmaps.Items),
+		"found", len(cfgmaps),
 		"ruler", ruler.Name,
-		"namespace", ruler.Namespace)
+		"namespaces", len(namespaces))
 
-	ruleFiles := make([]corev1.ConfigMapKeySelector, 0, len(cfgmaps.Items))
-	for _, cfgmap := range cfgmaps.Items {
+	ruleFiles := make([]corev1.ConfigMapKeySelector, 0, len(cfgmaps))
+	objs := []client.Object{}
+	for _, cfgmap := range cfgmaps {
 		if cfgmap.Data == nil || len(cfgmap.Data) != 1 {
 			r.logger.Info("skipping invalid config map",
 				"name", cfgmap.Name,
+				"namespace", cfgmap.Namespace,
 				"dataKeys", len(cfgmap.Data),
 				"ruler", ruler.Name)
 			continue
 		}
 
-		for key := range cfgmap.Data {
+		for key, content := range cfgmap.Data {
+			if cfgmap.Namespace != ruler.Namespace {
+				cmName := manifests.SanitizeName(fmt.Sprintf("%s-rulefile-%s-%s", ruler.Name, cfgmap.Namespace, cfgmap.Name))
+				objs = append(objs, federatedRuleConfigMap(ruler, cmName, cfgmap.Namespace, content))
+				ruleFiles = append(ruleFiles, corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cmName,
+					},
+					Key:      cmName + ".yaml",
+					Optional: ptr.To(true),
+				})
+				continue
+			}
+
 			ruleFiles = append(ruleFiles, corev1.ConfigMapKeySelector{
 				LocalObjectReference: corev1.LocalObjectReference{
 					Name: cfgmap.GetName(),
@@ -279,11 +504,16 @@ func (r *ThanosRulerReconciler) getRuleConfigMaps(ctx context.Context, ruler mon
 		}
 	}
 
+	if errCount := r.handler.CreateOrUpdate(ctx, ruler.GetNamespace(), &ruler, objs); errCount > 0 {
+		return nil, fmt.Errorf("failed to create or update %d ConfigMaps from rule ConfigMaps in other namespaces", errCount)
+	}
+
 	return ruleFiles, nil
 }
 
 // getPrometheusRuleConfigMaps returns the list of ruler configmaps of rule files to set on ThanosRuler.
-func (r *ThanosRulerReconciler) getPrometheusRuleConfigMaps(ctx context.Context, ruler monitoringthanosiov1alpha1.ThanosRuler) ([]corev1.ConfigMapKeySelector, error) {
+func (r *ThanosRulerReconciler) getPrometheusRuleConfigMaps(ctx context.Context, ruler monitoringthanosiov1alpha1.ThanosRuler, namespaces []string) ([]corev1.ConfigMapKeySelector, error) {
+	defer profile.FromContext(ctx).Start("discovery")()
 	if ruler.Spec.PrometheusRuleSelector.MatchLabels == nil {
 		r.logger.Info("no prometheus rule selector specified, skipping", "ruler", ruler.Name)
 		return []corev1.ConfigMapKeySelector{}, nil
@@ -294,54 +524,56 @@ func (r *ThanosRulerReconciler) getPrometheusRuleConfigMaps(ctx context.Context,
 		return nil, fmt.Errorf("failed to build PrometheusRule label selector: %w", err)
 	}
 
-	promRules := &monitoringv1.PrometheusRuleList{}
-	if err := r.List(ctx, promRules,
-		client.InNamespace(ruler.Namespace),
-		client.MatchingLabelsSelector{Selector: labelSelector},
-	); err != nil {
-		return nil, err
+	var promRules []*monitoringv1.PrometheusRule
+	for _, ns := range namespaces {
+		list := &monitoringv1.PrometheusRuleList{}
+		if err := r.List(ctx, list,
+			client.InNamespace(ns),
+			client.MatchingLabelsSelector{Selector: labelSelector},
+		); err != nil {
+			return nil, err
+		}
+		promRules = append(promRules, list.Items...)
 	}
 
-	if len(promRules.Items) == 0 {
+	if len(promRules) == 0 {
 		return []corev1.ConfigMapKeySelector{}, nil
 	}
 
 	r.logger.Info("processing prometheus rules",
-		"found", len(promRules.Items),
+		"found", len(promRules),
 		"ruler", ruler.Name,
-		"namespace", ruler.Namespace)
+		"namespaces", len(namespaces))
 
 	ruleFiles := []corev1.ConfigMapKeySelector{}
 	objs := []client.Object{}
-	for _, rule := range promRules.Items {
-		cmName := manifests.SanitizeName(fmt.Sprintf("%s-promrule-%s", ruler.Name, rule.Name))
-		objs = append(objs, &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      cmName,
-				Namespace: ruler.Namespace,
-				Labels: map[string]string{
-					manifests.DefaultRuleConfigLabel: manifests.DefaultRuleConfigValue,
-				},
-				OwnerReferences: []metav1.OwnerReference{
-					{
-						APIVersion: ruler.APIVersion,
-						Kind:       ruler.Kind,
-						Name:       ruler.Name,
-						UID:        ruler.UID,
-						Controller: ptr.To(true),
+	for _, rule := range promRules {
+		var cm *corev1.ConfigMap
+		if rule.Namespace != ruler.Namespace {
+			cmName := manifests.SanitizeName(fmt.Sprintf("%s-promrule-%s-%s", ruler.Name, rule.Namespace, rule.Name))
+			cm = federatedRuleConfigMap(ruler, cmName, rule.Namespace, manifestruler.GenerateRuleFileContent(rule.Spec.Groups))
+		} else {
+			cmName := manifests.SanitizeName(fmt.Sprintf("%s-promrule-%s", ruler.Name, rule.Name))
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cmName,
+					Namespace: ruler.Namespace,
+					Labels: map[string]string{
+						manifests.DefaultRuleConfigLabel: manifests.DefaultRuleConfigValue,
 					},
 				},
-			},
-			Data: map[string]string{
-				cmName + ".yaml": manifestruler.GenerateRuleFileContent(rule.Spec.Groups),
-			},
-		})
+				Data: map[string]string{
+					cmName + ".yaml": manifestruler.GenerateRuleFileContent(rule.Spec.Groups),
+				},
+			}
+		}
+		objs = append(objs, cm)
 
 		ruleFiles = append(ruleFiles, corev1.ConfigMapKeySelector{
 			LocalObjectReference: corev1.LocalObjectReference{
-				Name: cmName,
+				Name: cm.Name,
 			},
-			Key:      cmName + ".yaml",
+			Key:      cm.Name + ".yaml",
 			Optional: ptr.To(true),
 		})
 	}
@@ -353,6 +585,31 @@ func (r *ThanosRulerReconciler) getPrometheusRuleConfigMaps(ctx context.Context,
 	return ruleFiles, nil
 }
 
+// pruneFederatedRuleConfigMaps deletes the ConfigMaps holding rules copied from other namespaces
+// which are no longer part of the given rule files.
+func (r *ThanosRulerReconciler) pruneFederatedRuleConfigMaps(ctx context.Context, ruler monitoringthanosiov1alpha1.ThanosRuler, ruleFiles []corev1.ConfigMapKeySelector) error {
+	cfgmaps := &corev1.ConfigMapList{}
+	if err := r.List(ctx, cfgmaps,
+		client.InNamespace(ruler.Namespace),
+		client.MatchingLabels{manifests.OwnerLabel: manifests.ValidateAndSanitizeNameToValidLabelValue(ruler.Name)},
+		client.HasLabels{manifests.RuleSourceNamespaceLabel},
+	); err != nil {
+		return err
+	}
+
+	var objs []client.Object
+	for i, cfgmap := range cfgmaps.Items {
+		if !slices.ContainsFunc(ruleFiles, func(rf corev1.ConfigMapKeySelector) bool { return rf.Name == cfgmap.Name }) {
+			objs = append(objs, &cfgmaps.Items[i])
+		}
+	}
+
+	if errCount := r.handler.DeleteResource(ctx, objs); errCount > 0 {
+		return fmt.Errorf("failed to delete %d ConfigMaps of rules no longer gathered from other namespaces", errCount)
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ThanosRulerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	serviceLabelPredicate, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
@@ -375,6 +632,7 @@ func (r *ThanosRulerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	bldr := ctrl.NewControllerManagedBy(mgr).
 		For(&monitoringthanosiov1alpha1.ThanosRuler{}).
+		WithOptions(r.controllerConfig.options()).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&corev1.Service{}).
@@ -391,6 +649,15 @@ func (r *ThanosRulerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&corev1.ConfigMap{},
 			r.enqueueForConfigMap(),
 			builder.WithPredicates(predicate.GenerationChangedPredicate{}, configMapPredicate),
+		).
+		Watches(
+			&corev1.Namespace{},
+			r.enqueueForNamespace(),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
+		Watches(
+			&monitoringthanosiov1alpha1.ThanosQuery{},
+			enqueueForStack(r.Client, &monitoringthanosiov1alpha1.ThanosRulerList{}),
 		)
 
 	if !r.handler.IsFeatureGated(&monitoringv1.PrometheusRule{}) {
@@ -458,19 +725,13 @@ func (r *ThanosRulerReconciler) enqueueForConfigMap() handler.EventHandler {
 			return []reconcile.Request{}
 		}
 
-		rulers := &monitoringthanosiov1alpha1.ThanosRulerList{}
-		err := r.List(
-			ctx,
-			rulers,
-			[]client.ListOption{
-				client.InNamespace(obj.GetNamespace()),
-			}...)
+		rulers, err := r.rulersForRuleNamespace(ctx, obj.GetNamespace())
 		if err != nil {
 			return []reconcile.Request{}
 		}
 
 		requests := []reconcile.Request{}
-		for _, ruler := range rulers.Items {
+		for _, ruler := range rulers {
 			selector, err := manifests.BuildLabelSelectorFrom(ruler.Spec.RuleConfigSelector, requiredRuleConfigMapLabels)
 			if err != nil {
 				r.logger.Error(err, "failed to build label selector from ruler rule config selector", "ruler", ruler.GetName())
@@ -509,14 +770,13 @@ var requiredRuleConfigMapLabels = map[string]string{
 // Add this new function to handle PrometheusRule events
 func (r *ThanosRulerReconciler) enqueueForPrometheusRule() handler.EventHandler {
 	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
-		rulers := &monitoringthanosiov1alpha1.ThanosRulerList{}
-		err := r.List(ctx, rulers, client.InNamespace(obj.GetNamespace()))
+		rulers, err := r.rulersForRuleNamespace(ctx, obj.GetNamespace())
 		if err != nil {
 			return []reconcile.Request{}
 		}
 
 		requests := []reconcile.Request{}
-		for _, ruler := range rulers.Items {
+		for _, ruler := range rulers {
 			selector, err := manifests.BuildLabelSelectorFrom(&ruler.Spec.PrometheusRuleSelector, nil)
 			if err != nil {
 				r.logger.Error(err, "failed to build label selector from ruler PrometheusRule selector",
@@ -538,3 +798,54 @@ func (r *ThanosRulerReconciler) enqueueForPrometheusRule() handler.EventHandler
 		return requests
 	})
 }
+
+// enqueueForNamespace returns an EventHandler that will enqueue a request for the ThanosRuler instances
+// gathering rules from other namespaces, so that they re-evaluate their RuleNamespaceSelector.
+func (r *ThanosRulerReconciler) enqueueForNamespace() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		rulers := &monitoringthanosiov1alpha1.ThanosRulerList{}
+		if err := r.List(ctx, rulers); err != nil {
+			return []reconcile.Request{}
+		}
+
+		requests := []reconcile.Request{}
+		for _, ruler := range rulers.Items {
+			if ruler.Spec.RuleNamespaceSelector == nil {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      ruler.GetName(),
+					Namespace: ruler.GetNamespace(),
+				},
+			})
+		}
+		return requests
+	})
+}
+
+// rulersForRuleNamespace returns the ThanosRuler instances gathering rules from the given namespace.
+func (r *ThanosRulerReconciler) rulersForRuleNamespace(ctx context.Context, namespace string) ([]monitoringthanosiov1alpha1.ThanosRuler, error) {
+	rulers := &monitoringthanosiov1alpha1.ThanosRulerList{}
+	if err := r.List(ctx, rulers); err != nil {
+		return nil, err
+	}
+
+	var ns *corev1.Namespace
+	var selected []monitoringthanosiov1alpha1.ThanosRuler
+	for _, ruler := range rulers.Items {
+		if ruler.Namespace != namespace && ruler.Spec.RuleNamespaceSelector == nil {
+			continue
+		}
+		if ns == nil {
+			ns = &corev1.Namespace{}
+			if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+				return nil, err
+			}
+		}
+		if selectsRuleNamespace(ruler, ns) {
+			selected = append(selected, ruler)
+		}
+	}
+	return selected, nil
+}
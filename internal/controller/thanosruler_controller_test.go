@@ -28,7 +28,7 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	corev1 "k8s.io/api/core/v1"
@@ -102,10 +102,12 @@ config:
 					CommonFields: monitoringthanosiov1alpha1.CommonFields{},
 					StorageSize:  "1Gi",
 					ObjectStorageConfig: monitoringthanosiov1alpha1.ObjectStorageConfig{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: "thanos-objstore",
+						SecretKeySelector: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: "thanos-objstore",
+							},
+							Key: "thanos.yaml",
 						},
-						Key: "thanos.yaml",
 					},
 					PrometheusRuleSelector: metav1.LabelSelector{
 						MatchLabels: map[string]string{
@@ -224,6 +226,55 @@ config:
 				}, time.Minute, time.Second*2).Should(BeTrue())
 			})
 
+			By("gathering rules from selected tenant namespaces", func() {
+				tenantNs := &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "test-ruler-tenant",
+						Labels: map[string]string{"rules": "federated"},
+					},
+				}
+				Expect(k8sClient.Create(context.Background(), tenantNs)).Should(Succeed())
+
+				cfgmap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "tenant-rules",
+						Namespace: tenantNs.Name,
+						Labels:    requiredRuleConfigMapLabels,
+					},
+					Data: map[string]string{
+						"tenant-rules.yaml": `groups:
+- name: tenant
+  rules:
+  - record: job:up:sum
+    expr: sum by (job) (up)
+`,
+					},
+				}
+				Expect(k8sClient.Create(context.Background(), cfgmap)).Should(Succeed())
+
+				updatedResource := &monitoringthanosiov1alpha1.ThanosRuler{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.RuleNamespaceSelector = &metav1.LabelSelector{
+					MatchLabels: map[string]string{"rules": "federated"},
+				}
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				copyName := resourceName + "-rulefile-" + tenantNs.Name + "-" + cfgmap.Name
+				EventuallyWithOffset(1, func() bool {
+					arg := "--rule-file=/etc/thanos/rules/" + copyName + ".yaml"
+					return utils.VerifyConfigMapExists(k8sClient, copyName, ns) &&
+						utils.VerifyStatefulSetArgs(k8sClient, RulerNameFromParent(resourceName), ns, 0, arg)
+				}, time.Minute, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.RuleNamespaceDenyList = []string{tenantNs.Name}
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyConfigMapExists(k8sClient, copyName, ns)
+				}, time.Minute, time.Second*2).Should(BeFalse())
+			})
+
 			By("removing service monitor when disabled", func() {
 				Expect(utils.VerifyServiceMonitorExists(k8sClient, RulerNameFromParent(resourceName), ns)).To(BeTrue())
 
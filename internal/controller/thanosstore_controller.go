@@ -18,19 +18,30 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus/common/model"
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
 	"github.com/thanos-community/thanos-operator/internal/pkg/handlers"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestsstore "github.com/thanos-community/thanos-operator/internal/pkg/manifests/store"
 	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
+	"github.com/thanos-community/thanos-operator/internal/pkg/profile"
+	"github.com/thanos-community/thanos-operator/internal/pkg/versionpolicy"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestsmemcached "github.com/thanos-community/thanos-operator/pkg/manifests/memcached"
+	manifestsredis "github.com/thanos-community/thanos-operator/pkg/manifests/redis"
+	manifestsstore "github.com/thanos-community/thanos-operator/pkg/manifests/store"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -46,36 +57,56 @@ type ThanosStoreReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	logger   logr.Logger
-	metrics  controllermetrics.ThanosStoreMetrics
-	recorder record.EventRecorder
+	logger           logr.Logger
+	metrics          controllermetrics.ThanosStoreMetrics
+	reconcileMetrics *controllermetrics.ReconcileMetrics
+	recorder         record.EventRecorder
 
-	handler *handlers.Handler
+	handler          *handlers.Handler
+	versionPolicy    *versionpolicy.Policy
+	controllerConfig ControllerConfig
 }
 
 // NewThanosStoreReconciler returns a reconciler for ThanosStore resources.
 func NewThanosStoreReconciler(conf Config, client client.Client, scheme *runtime.Scheme) *ThanosStoreReconciler {
 	handler := handlers.NewHandler(client, scheme, conf.InstrumentationConfig.Logger)
+	handler.SetLogSampler(conf.InstrumentationConfig.LogSampler)
 	featureGates := conf.FeatureGate.ToGVK()
 	if len(featureGates) > 0 {
 		handler.SetFeatureGates(featureGates)
 	}
+	handler.SetImagePolicy(conf.ImagePolicy)
+	handler.SetMaintenance(conf.Maintenance)
+	handler.SetApplyRetryBudget(conf.ApplyRetryBudget)
+	handler.SetWriteLimit(conf.WriteLimit, conf.WriteLimitWindow)
+	handler.SetForbiddenProbeInterval(conf.ForbiddenProbeInterval)
 
 	return &ThanosStoreReconciler{
-		Client:   client,
-		Scheme:   scheme,
-		logger:   conf.InstrumentationConfig.Logger,
-		metrics:  controllermetrics.NewThanosStoreMetrics(conf.InstrumentationConfig.MetricsRegistry),
-		recorder: conf.InstrumentationConfig.EventRecorder,
-		handler:  handler,
+		Client:           client,
+		Scheme:           scheme,
+		logger:           conf.InstrumentationConfig.Logger,
+		metrics:          controllermetrics.NewThanosStoreMetrics(conf.InstrumentationConfig.MetricsRegistry),
+		reconcileMetrics: conf.InstrumentationConfig.ReconcileMetrics,
+		recorder:         conf.InstrumentationConfig.EventRecorder,
+		handler:          handler,
+		controllerConfig: conf.Controller,
+		versionPolicy:    conf.VersionPolicy,
 	}
 }
 
 //+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosstores,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosstores/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosstores/finalizers,verbs=update
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services;configmaps;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods/ephemeralcontainers,verbs=update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -94,6 +125,13 @@ func (r *ThanosStoreReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		r.recorder.Event(store, corev1.EventTypeWarning, "GetFailed", "Failed to get ThanosStore resource")
 		return ctrl.Result{}, err
 	}
+	start := time.Now()
+	defer func() { r.reconcileMetrics.ObserveApply(r.handler.LogApplySummary(store, start)) }()
+	ctx, prof := startProfiling(ctx, store.Spec.FeatureGates)
+	defer recordProfile(r.recorder, r.reconcileMetrics, store, prof)
+
+	var reconcileErr error
+	defer func() { r.updateStatus(ctx, store, reconcileErr) }()
 
 	if store.Spec.Paused != nil {
 		if *store.Spec.Paused {
@@ -103,34 +141,184 @@ func (r *ThanosStoreReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	if err := validateAdditional(store.Spec.Additional); err != nil {
+		r.logger.Error(err, "invalid additional configuration for ThanosStore")
+		r.recorder.Event(store, corev1.EventTypeWarning, "InvalidSpec", fmt.Sprintf("Invalid additional configuration: %v", err))
+		reconcileErr = fmt.Errorf("%w: %w", errInvalidSpec, err)
+		return ctrl.Result{}, nil
+	}
+
+	if err := checkOptions(ctx, r.Client, r.recorder, store, &store.Status.Conditions, storeV1Alpha1ToOptions(*store).Options); err != nil {
+		r.logger.Error(err, "invalid options for ThanosStore")
+		reconcileErr = err
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := checkVersions(ctx, r.Client, r.recorder, store, &store.Status.Conditions, r.versionPolicy, storeVersions(*store)); err != nil {
+		r.logger.Error(err, "failed to check versions of ThanosStore")
+		reconcileErr = err
+		if errors.Is(err, errInvalidSpec) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := checkObjectStorage(ctx, r.Client, r.recorder, store, &store.Status.Conditions,
+		objectStoragePreflight(store.Spec.FeatureGates), storeObjectStorageSelectors(*store)); err != nil {
+		r.logger.Error(err, "failed to check object storage of ThanosStore")
+		reconcileErr = err
+		if errors.Is(err, errInvalidObjectStorage) {
+			return ctrl.Result{RequeueAfter: objectStoragePreflightRequeueInterval}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
 	err = r.syncResources(ctx, *store)
+	if blockedErr := r.handler.ApplyBlocked(store); blockedErr != nil {
+		err = blockedErr
+	}
+	requeueAfter, throttledErr := r.handler.ApplyThrottled(store)
+	if err == nil && throttledErr != nil {
+		err = throttledErr
+	}
+	probeAfter, permissionsErr := r.handler.MissingPermissions(store)
+	reconcileErr = err
+	if statusErr := updateBlockedCondition(ctx, r.Client, store, &store.Status.Conditions, err); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update blocked condition")
+	}
+	if statusErr := updateMissingPermissionsCondition(ctx, r.Client, store, &store.Status.Conditions, permissionsErr); statusErr != nil {
+		r.logger.Error(statusErr, "failed to update missing permissions condition")
+	}
+	if errors.Is(err, handlers.ErrRolloutDeferred) {
+		r.recorder.Event(store, corev1.EventTypeNormal, "RolloutDeferred", err.Error())
+		return ctrl.Result{RequeueAfter: rolloutDeferredRequeueInterval}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyThrottled) {
+		r.recorder.Event(store, corev1.EventTypeNormal, "ApplyThrottled", err.Error())
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if errors.Is(err, handlers.ErrApplyBlocked) {
+		// retrying will not help until the spec or the reconcile-now annotation changes, which triggers a new reconciliation
+		r.recorder.Event(store, corev1.EventTypeWarning, "ApplyBlocked", err.Error())
+		return ctrl.Result{}, nil
+	}
 	if err != nil {
 		r.recorder.Event(store, corev1.EventTypeWarning, "SyncFailed", fmt.Sprintf("Failed to sync resources: %v", err))
 		return ctrl.Result{}, err
 	}
 
+	if err := r.handler.CompleteRollout(ctx, store); err != nil {
+		r.logger.Error(err, "failed to complete rollout")
+		reconcileErr = err
+		return ctrl.Result{}, err
+	}
+
+	if permissionsErr != nil {
+		// the objects are applied again at the next probe, retrying sooner would only be denied again
+		r.recorder.Event(store, corev1.EventTypeWarning, "MissingPermissions", permissionsErr.Error())
+		reconcileErr = permissionsErr
+		return ctrl.Result{RequeueAfter: probeAfter}, nil
+	}
+
+	if err := updateCrashLoopingCondition(ctx, r.Client, r.recorder, store, &store.Status.Conditions); err != nil {
+		r.logger.Error(err, "failed to update crash looping condition")
+	}
+
+	if pod, err := attachDebugContainer(ctx, r.Client, store); err != nil {
+		r.logger.Error(err, "failed to attach debug container")
+		r.recorder.Event(store, corev1.EventTypeWarning, "DebugContainerFailed", fmt.Sprintf("Failed to attach debug container: %v", err))
+	} else if pod != "" {
+		r.recorder.Event(store, corev1.EventTypeNormal, "DebugContainerAttached", fmt.Sprintf("Attached debug container %s to pod %s", debugContainerName, pod))
+	}
+
 	return ctrl.Result{}, nil
 }
 
 func (r *ThanosStoreReconciler) syncResources(ctx context.Context, store monitoringthanosiov1alpha1.ThanosStore) error {
 	var errCount int
-	opts := r.specToOptions(store)
+	opts, err := r.specToOptions(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	if err := r.syncObjectStorageSecret(ctx, store); err != nil {
+		return err
+	}
+
+	if err := r.syncManagedRedis(ctx, store); err != nil {
+		return err
+	}
+
+	if err := r.syncManagedMemcached(ctx, store); err != nil {
+		return err
+	}
+
+	paused, err := r.pausedShards(ctx, store)
+	if err != nil {
+		return err
+	}
+	if len(paused) > 0 {
+		r.recorder.Event(&store, corev1.EventTypeNormal, "ShardsPaused", fmt.Sprintf("Reconciliation is paused for shard(s) %s", strings.Join(paused, ", ")))
+	}
 
 	expectShards := make([]string, len(opts))
 	for i, opt := range opts {
 		expectShards[i] = opt.GetGeneratedResourceName()
-		errCount += r.handler.CreateOrUpdate(ctx, store.GetNamespace(), &store, opt.Build())
+		if slices.Contains(paused, expectShards[i]) {
+			continue
+		}
+		stop := profile.FromContext(ctx).Start("render")
+		objs := opt.Build()
+		stop()
+		if err := r.handler.ApplyImagePolicy(ctx, objs); err != nil {
+			return err
+		}
+		if err := r.handler.CoordinateRollout(ctx, &store, objs); err != nil {
+			return err
+		}
+		// claim templates of existing StatefulSets are immutable, so StatefulSets requesting more storage are recreated
+		// after their claims were expanded, and claims are updated before the apply
+		if err := r.handler.ResizeVolumeClaims(ctx, store.GetNamespace(), objs); err != nil {
+			return err
+		}
+		errCount += r.handler.UpdateVolumeClaimMetadata(ctx, store.GetNamespace(), objs)
+		errCount += r.handler.CreateOrUpdate(ctx, store.GetNamespace(), &store, objs)
 	}
 
 	if errCount > 0 {
 		return fmt.Errorf("failed to create or update %d resources for store or store shard(s)", errCount)
 	}
 
-	// prune the store resources that are no longer needed/have changed
-	errCount = r.pruneOrphanedResources(ctx, store.GetNamespace(), store.GetName(), expectShards)
+	// prune the store resources that are no longer needed/have changed, keeping paused shards even if they are no longer needed
+	errCount = r.pruneOrphanedResources(ctx, store.GetNamespace(), store.GetName(), slices.Concat(expectShards, paused))
 	if errCount > 0 {
 		return fmt.Errorf("failed to prune %d orphaned resources for store shard(s)", errCount)
 	}
+	expectShards = slices.DeleteFunc(expectShards, func(shard string) bool { return slices.Contains(paused, shard) })
+	// shards with a single replica have no PodDisruptionBudget
+	if storeV1Alpha1ToOptions(store).PodDisruptionConfig == nil {
+		pdbs := make([]client.Object, len(expectShards))
+		for i, shard := range expectShards {
+			pdbs[i] = &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: shard, Namespace: store.GetNamespace()}}
+		}
+		if errCount = r.handler.DeleteResource(ctx, pdbs); errCount > 0 {
+			return fmt.Errorf("failed to delete %d PodDisruptionBudgets for store shard(s)", errCount)
+		}
+	}
+
+	// shards without a block selector relabel configuration, such as the shards of the time sharding strategy, have no ConfigMap
+	var configMaps []client.Object
+	for _, opt := range opts {
+		if storeOpts, ok := opt.(manifestsstore.Options); ok && len(storeOpts.RelabelConfigs) == 0 && slices.Contains(expectShards, opt.GetGeneratedResourceName()) {
+			configMaps = append(configMaps, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: opt.GetGeneratedResourceName(), Namespace: store.GetNamespace()}})
+		}
+	}
+	if errCount = r.handler.DeleteResource(ctx, configMaps); errCount > 0 {
+		return fmt.Errorf("failed to delete %d relabel ConfigMaps for store shard(s)", errCount)
+	}
 
 	if !manifests.HasServiceMonitorEnabled(store.Spec.FeatureGates) {
 		objs := make([]client.Object, len(expectShards))
@@ -145,40 +333,351 @@ func (r *ThanosStoreReconciler) syncResources(ctx context.Context, store monitor
 	return nil
 }
 
-func (r *ThanosStoreReconciler) specToOptions(store monitoringthanosiov1alpha1.ThanosStore) []manifests.Buildable {
-	// no sharding strategy, or sharding strategy with 1 shard, return a single store
-	if store.Spec.ShardingStrategy.Shards == 0 || store.Spec.ShardingStrategy.Shards == 1 {
-		return []manifests.Buildable{storeV1Alpha1ToOptions(store)}
+// pausedShards returns the names of the StatefulSets of the ThanosStore whose reconciliation is paused
+// with the PauseShardAnnotation.
+func (r *ThanosStoreReconciler) pausedShards(ctx context.Context, store monitoringthanosiov1alpha1.ThanosStore) ([]string, error) {
+	list := &appsv1.StatefulSetList{}
+	listOpt := manifests.GetLabelSelectorForOwner(manifestsstore.Options{Options: manifests.Options{Owner: store.GetName()}})
+	if err := r.List(ctx, list, listOpt, client.InNamespace(store.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list StatefulSets of store shard(s): %w", err)
+	}
+	var paused []string
+	for _, sts := range list.Items {
+		if isShardPaused(&sts) {
+			paused = append(paused, sts.GetName())
+		}
+	}
+	return paused, nil
+}
+
+// isShardPaused returns true if the reconciliation of the shard of the StatefulSet is paused.
+func isShardPaused(sts *appsv1.StatefulSet) bool {
+	return sts.GetAnnotations()[monitoringthanosiov1alpha1.PauseShardAnnotation] == "true"
+}
+
+// syncObjectStorageSecret creates or updates the Secret holding the inline object storage configuration of the ThanosStore,
+// or deletes it if the object storage configuration is referenced instead.
+func (r *ThanosStoreReconciler) syncObjectStorageSecret(ctx context.Context, store monitoringthanosiov1alpha1.ThanosStore) error {
+	name := StoreObjectStorageNameFromParent(store.GetName())
+	if store.Spec.ObjectStorage == nil {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: store.GetNamespace()}}
+		if errCount := r.handler.DeleteResource(ctx, []client.Object{secret}); errCount > 0 {
+			return fmt.Errorf("failed to delete object storage secret %s", name)
+		}
+		return nil
+	}
+
+	labels := manifestsstore.GetLabels(manifestsstore.Options{Options: manifests.Options{Owner: store.GetName(), Labels: store.Spec.Labels}})
+	secret := manifests.BuildObjectStorageSecret(name, store.GetNamespace(), labels, objectStorageToOpts(*store.Spec.ObjectStorage))
+	if errCount := r.handler.CreateOrUpdate(ctx, store.GetNamespace(), &store, []client.Object{secret}); errCount > 0 {
+		return fmt.Errorf("failed to create or update object storage secret %s", name)
+	}
+	return nil
+}
+
+// syncManagedRedis creates or updates the Redis instances managed for the caches of the ThanosStore
+// and deletes those that are no longer managed.
+func (r *ThanosStoreReconciler) syncManagedRedis(ctx context.Context, store monitoringthanosiov1alpha1.ThanosStore) error {
+	redisOpts := storeManagedRedisOptions(store)
+	expect := make([]string, len(redisOpts))
+	for i, opt := range redisOpts {
+		expect[i] = opt.GetGeneratedResourceName()
+		objs := opt.Build()
+		if err := r.handler.ApplyImagePolicy(ctx, objs); err != nil {
+			return err
+		}
+		if errCount := r.handler.CreateOrUpdate(ctx, store.GetNamespace(), &store, objs); errCount > 0 {
+			return fmt.Errorf("failed to create or update %d resources for managed redis", errCount)
+		}
+	}
+
+	listOpts := []client.ListOption{
+		manifests.GetLabelSelectorForOwner(manifestsredis.Options{Owner: store.GetName()}),
+		client.InNamespace(store.GetNamespace()),
+	}
+	if errCount := r.handler.NewResourcePruner().WithDeployment().WithService().Prune(ctx, expect, listOpts...); errCount > 0 {
+		return fmt.Errorf("failed to prune %d resources for managed redis", errCount)
+	}
+	return nil
+}
+
+// syncManagedMemcached creates or updates the Memcached instances managed for the caches of the ThanosStore
+// and deletes those that are no longer managed.
+func (r *ThanosStoreReconciler) syncManagedMemcached(ctx context.Context, store monitoringthanosiov1alpha1.ThanosStore) error {
+	memcachedOpts := storeManagedMemcachedOptions(store)
+	expect := make([]string, len(memcachedOpts))
+	for i, opt := range memcachedOpts {
+		expect[i] = opt.GetGeneratedResourceName()
+		objs := opt.Build()
+		if err := r.handler.ApplyImagePolicy(ctx, objs); err != nil {
+			return err
+		}
+		if errCount := r.handler.CreateOrUpdate(ctx, store.GetNamespace(), &store, objs); errCount > 0 {
+			return fmt.Errorf("failed to create or update %d resources for managed memcached", errCount)
+		}
+	}
+
+	listOpts := []client.ListOption{
+		manifests.GetLabelSelectorForOwner(manifestsmemcached.Options{Owner: store.GetName()}),
+		client.InNamespace(store.GetNamespace()),
+	}
+	if errCount := r.handler.NewResourcePruner().WithStatefulSet().WithService().Prune(ctx, expect, listOpts...); errCount > 0 {
+		return fmt.Errorf("failed to prune %d resources for managed memcached", errCount)
+	}
+	return nil
+}
+
+// updateStatus reports the observed generation, the effective defaults, the readiness of each shard and the Available,
+// Degraded, Reconciled and Paused conditions in the status of the ThanosStore, given the outcome of the reconciliation.
+// The status is only written if it changed.
+func (r *ThanosStoreReconciler) updateStatus(ctx context.Context, store *monitoringthanosiov1alpha1.ThanosStore, reconcileErr error) {
+	previous := store.Status.DeepCopy()
+	generation := store.GetGeneration()
+	paused := ptr.Deref(store.Spec.Paused, false)
+
+	opts, err := r.specToOptions(ctx, *store)
+	if err != nil {
+		r.logger.Error(err, "failed to get shards for status")
+		return
+	}
+	problems, err := podProblems(ctx, r.Client, store)
+	if err != nil {
+		r.logger.Error(err, "failed to get pod problems for status")
+		return
+	}
+	shards := make([]monitoringthanosiov1alpha1.StoreShardStatus, 0, len(opts))
+	rolledOut := true
+	for _, opt := range opts {
+		shard := monitoringthanosiov1alpha1.StoreShardStatus{Name: opt.GetGeneratedResourceName()}
+		sts := &appsv1.StatefulSet{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: store.GetNamespace(), Name: shard.Name}, sts)
+		switch {
+		case apierrors.IsNotFound(err):
+			rolledOut = false
+		case err != nil:
+			r.logger.Error(err, "failed to get StatefulSet for status", "shard", shard.Name)
+			return
+		default:
+			shard.Replicas = ptr.Deref(sts.Spec.Replicas, 1)
+			shard.ReadyReplicas = sts.Status.ReadyReplicas
+			shard.Paused = isShardPaused(sts)
+			rolledOut = rolledOut && !shard.Paused && sts.Status.ObservedGeneration >= sts.GetGeneration() &&
+				sts.Status.UpdatedReplicas == shard.Replicas && sts.Status.ReadyReplicas == shard.Replicas
+		}
+		shards = append(shards, shard)
 	}
 
-	shardCount := int(store.Spec.ShardingStrategy.Shards)
-	buildables := make([]manifests.Buildable, shardCount)
-	for i := range store.Spec.ShardingStrategy.Shards {
-		storeShardOpts := storeV1Alpha1ToOptions(store)
+	store.Status.ObservedGeneration = generation
+	store.Status.Shards = shards
+	store.Status.Defaults = effectiveDefaultsFromOpts(storeV1Alpha1ToOptions(*store).Options)
+	// the blocks are only redistributed across the shards once all shards serve the blocks of both shard counts
+	if reconcileErr == nil && !paused && rolledOut {
+		store.Status.BlockShards = int32(storeBlockShards(*store))
+	}
+	setShardsAvailableCondition(&store.Status.Conditions, generation, shards)
+	// the Degraded condition reports the failed object storage pre-flight check until the configuration is fixed
+	if !errors.Is(reconcileErr, errInvalidObjectStorage) {
+		setPodsDegradedCondition(&store.Status.Conditions, generation, problems)
+	}
+	setReconciledCondition(&store.Status.Conditions, generation, paused, reconcileErr)
+	setPausedCondition(&store.Status.Conditions, generation, paused)
+
+	if equality.Semantic.DeepEqual(previous, &store.Status) {
+		return
+	}
+	if err := r.Status().Update(ctx, store); err != nil {
+		r.logger.Error(err, "failed to update status")
+	}
+}
+
+func (r *ThanosStoreReconciler) specToOptions(ctx context.Context, store monitoringthanosiov1alpha1.ThanosStore) ([]manifests.Buildable, error) {
+	boundary, _, split, err := stackTimeSplit(ctx, r.Client, &store)
+	if err != nil {
+		return nil, err
+	}
+	withTimeSplit := func(opts manifestsstore.Options) manifestsstore.Options {
+		// only data older than the boundary is served, recent data is served by the receivers of the stack
+		if split && (opts.Max == "" || opts.Max == "0") {
+			opts.Max = manifests.Duration("-" + boundary.String())
+		}
+		return opts
+	}
+
+	if len(store.Spec.Tiers) == 0 {
+		return r.shardOptions(store, withTimeSplit(storeV1Alpha1ToOptions(store)))
+	}
+
+	var buildables []manifests.Buildable
+	for _, tier := range store.Spec.Tiers {
+		shards, err := r.shardOptions(store, withTimeSplit(storeTierV1Alpha1ToOptions(store, tier)))
+		if err != nil {
+			return nil, err
+		}
+		buildables = append(buildables, shards...)
+	}
+	return buildables, nil
+}
+
+// shardOptions splits the provided options into a set of options per shard, according to the sharding strategy.
+func (r *ThanosStoreReconciler) shardOptions(store monitoringthanosiov1alpha1.ThanosStore, opts manifestsstore.Options) ([]manifests.Buildable, error) {
+	if store.Spec.ShardingStrategy.Type == monitoringthanosiov1alpha1.Time && store.Spec.ShardingStrategy.Shards > 1 {
+		shardCount := int(store.Spec.ShardingStrategy.Shards)
+		interval := defaultTimeShardInterval
+		if store.Spec.ShardingStrategy.Interval != nil {
+			parsed, err := model.ParseDuration(string(*store.Spec.ShardingStrategy.Interval))
+			if err != nil {
+				return nil, fmt.Errorf("invalid sharding interval: %w", err)
+			}
+			interval = parsed
+		}
+		windows, err := timeShardWindows(opts.Min, opts.Max, interval, shardCount)
+		if err != nil {
+			return nil, err
+		}
+		buildables := make([]manifests.Buildable, shardCount)
+		for i, window := range windows {
+			storeShardOpts := opts
+			storeShardOpts.Min = window.min
+			storeShardOpts.Max = window.max
+			storeShardOpts.ShardIndex = ptr.To(int32(i))
+			buildables[i] = storeShardOpts
+		}
+		return buildables, nil
+	}
+
+	shards, previous := storeBlockShards(store), int(store.Status.BlockShards)
+	if previous == 0 || previous == shards {
+		return blockShardOptions(opts, shards, 0), nil
+	}
+	// while the shard count changes, the shards serve the blocks assigned to them by both shard counts,
+	// and the shards of the previous count which are no longer needed keep serving their blocks
+	buildables := blockShardOptions(opts, shards, previous)
+	for _, old := range blockShardOptions(opts, previous, 0) {
+		if !slices.ContainsFunc(buildables, func(b manifests.Buildable) bool {
+			return b.GetGeneratedResourceName() == old.GetGeneratedResourceName()
+		}) {
+			buildables = append(buildables, old)
+		}
+	}
+	return buildables, nil
+}
+
+// storeBlockShards returns the number of shards the blocks of the ThanosStore are distributed across,
+// or zero if the ThanosStore does not use the block sharding strategy.
+func storeBlockShards(store monitoringthanosiov1alpha1.ThanosStore) int {
+	if store.Spec.ShardingStrategy.Type == monitoringthanosiov1alpha1.Time && store.Spec.ShardingStrategy.Shards > 1 {
+		return 0
+	}
+	return max(int(store.Spec.ShardingStrategy.Shards), 1)
+}
+
+// blockShardOptions returns the options of each shard with the block sharding strategy, which hashmods the block ID.
+// If previous is greater than one, each shard additionally keeps the blocks assigned to it by the previous shard count.
+// A single shard serves all blocks.
+func blockShardOptions(opts manifestsstore.Options, shards, previous int) []manifests.Buildable {
+	if shards == 1 {
+		return []manifests.Buildable{opts}
+	}
+
+	buildables := make([]manifests.Buildable, shards)
+	for i := range shards {
+		storeShardOpts := opts
 		storeShardOpts.RelabelConfigs = manifests.RelabelConfigs{
 			{
 				Action:      "hashmod",
 				SourceLabel: "__block_id",
 				TargetLabel: "shard",
-				Modulus:     shardCount,
-			},
-			{
-				Action:      "keep",
-				SourceLabel: "shard",
-				Regex:       fmt.Sprintf("%d", i),
+				Modulus:     shards,
 			},
 		}
-		storeShardOpts.ShardIndex = ptr.To(i)
+		if previous > 1 && i < previous {
+			storeShardOpts.RelabelConfigs = append(storeShardOpts.RelabelConfigs,
+				manifests.RelabelConfig{
+					Action:      "hashmod",
+					SourceLabel: "__block_id",
+					TargetLabel: "previous_shard",
+					Modulus:     previous,
+				},
+				manifests.RelabelConfig{
+					Action:      "replace",
+					SourceLabel: "previous_shard",
+					TargetLabel: "shard",
+					Regex:       fmt.Sprintf("(%d)", i),
+				},
+			)
+		}
+		storeShardOpts.RelabelConfigs = append(storeShardOpts.RelabelConfigs, manifests.RelabelConfig{
+			Action:      "keep",
+			SourceLabel: "shard",
+			Regex:       fmt.Sprintf("%d", i),
+		})
+		storeShardOpts.ShardIndex = ptr.To(int32(i))
 		buildables[i] = storeShardOpts
 	}
 	return buildables
 }
 
+// defaultTimeShardInterval is the length of the time window of each shard with the time sharding strategy.
+const defaultTimeShardInterval = model.Duration(7 * 24 * time.Hour)
+
+// timeShardWindow is the time range served by a shard with the time sharding strategy.
+type timeShardWindow struct {
+	min, max manifests.Duration
+}
+
+// timeShardWindows returns the time windows of the shards with the time sharding strategy, most recent first.
+// The windows are consecutive multiples of interval before maxTime, which is either empty, a duration relative
+// to the current time or an RFC3339 time. The window of the last shard extends to minTime.
+// Only the flags of the shards are derived from the spec, so the windows are stable across reconciles.
+func timeShardWindows(minTime, maxTime manifests.Duration, interval model.Duration, shards int) ([]timeShardWindow, error) {
+	var boundary func(k int) manifests.Duration
+	switch s := string(maxTime); {
+	case s == "" || s == "0":
+		boundary = func(k int) manifests.Duration { return relativeTime(-time.Duration(k) * time.Duration(interval)) }
+	default:
+		if d, err := model.ParseDuration(strings.TrimPrefix(s, "-")); err == nil {
+			offset := time.Duration(d)
+			if strings.HasPrefix(s, "-") {
+				offset = -offset
+			}
+			boundary = func(k int) manifests.Duration { return relativeTime(offset - time.Duration(k)*time.Duration(interval)) }
+			break
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum time %q: must be an RFC3339 time or a duration", s)
+		}
+		boundary = func(k int) manifests.Duration {
+			return manifests.Duration(t.Add(-time.Duration(k) * time.Duration(interval)).UTC().Format(time.RFC3339))
+		}
+	}
+
+	windows := make([]timeShardWindow, shards)
+	for i := range windows {
+		windows[i] = timeShardWindow{min: boundary(i + 1), max: boundary(i)}
+	}
+	windows[0].max = maxTime
+	windows[shards-1].min = minTime
+	return windows, nil
+}
+
+// relativeTime formats a duration relative to the current time as accepted by the time range flags of Thanos.
+func relativeTime(d time.Duration) manifests.Duration {
+	switch {
+	case d == 0:
+		return "0"
+	case d < 0:
+		return manifests.Duration("-" + model.Duration(-d).String())
+	default:
+		return manifests.Duration(model.Duration(d).String())
+	}
+}
+
 func (r *ThanosStoreReconciler) pruneOrphanedResources(ctx context.Context, ns, owner string, expectShards []string) int {
 	listOpt := manifests.GetLabelSelectorForOwner(manifestsstore.Options{Options: manifests.Options{Owner: owner}})
 	listOpts := []client.ListOption{listOpt, client.InNamespace(ns)}
 
-	pruner := r.handler.NewResourcePruner().WithServiceAccount().WithService().WithStatefulSet().WithPodDisruptionBudget().WithServiceMonitor()
+	pruner := r.handler.NewResourcePruner().WithServiceAccount().WithService().WithStatefulSet().WithConfigMap().WithPodDisruptionBudget().WithServiceMonitor()
 	return pruner.Prune(ctx, expectShards, listOpts...)
 }
 
@@ -186,11 +685,18 @@ func (r *ThanosStoreReconciler) pruneOrphanedResources(ctx context.Context, ns,
 func (r *ThanosStoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	err := ctrl.NewControllerManagedBy(mgr).
 		For(&monitoringthanosiov1alpha1.ThanosStore{}).
+		WithOptions(r.controllerConfig.options()).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
 		Owns(&monitoringv1.ServiceMonitor{}).
+		Watches(
+			&monitoringthanosiov1alpha1.ThanosQuery{},
+			enqueueForStack(r.Client, &monitoringthanosiov1alpha1.ThanosStoreList{}),
+		).
 		Complete(r)
 
 	if err != nil {
@@ -18,20 +18,31 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestsstore "github.com/thanos-community/thanos-operator/pkg/manifests/store"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("ThanosStore Controller", Ordered, func() {
@@ -105,11 +116,13 @@ config:
 						ShardReplicas: 2,
 					},
 					StorageSize: "1Gi",
-					ObjectStorageConfig: monitoringthanosiov1alpha1.ObjectStorageConfig{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: "thanos-objstore",
+					ObjectStorageConfig: &monitoringthanosiov1alpha1.ObjectStorageConfig{
+						SecretKeySelector: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: "thanos-objstore",
+							},
+							Key: "thanos.yaml",
 						},
-						Key: "thanos.yaml",
 					},
 					Additional: monitoringthanosiov1alpha1.Additional{
 						Containers: []corev1.Container{
@@ -140,18 +153,87 @@ config:
 
 			By("setting correct sharding arg on thanos store", func() {
 				EventuallyWithOffset(1, func() bool {
-					args := `--selector.relabel-config=
-- action: hashmod
+					return utils.VerifyStatefulSetArgs(k8sClient, firstShard, ns, 0,
+						"--selector.relabel-config-file=/etc/thanos/relabel/relabel-config.yaml")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: firstShard, Namespace: ns}, cm); err != nil {
+						return false
+					}
+					return cm.Data[manifestsstore.RelabelConfigKey] == `- action: hashmod
   source_labels: ["__block_id"]
   target_label: shard
   modulus: 3
 - action: keep
   source_labels: ["shard"]
-  regex: 0`
-					return utils.VerifyStatefulSetArgs(k8sClient, firstShard, ns, 0, args)
+  regex: 0
+`
 				}, time.Second*10, time.Second*2).Should(BeTrue())
 			})
 
+			By("keeping the blocks of the previous shard count while the shard count changes", func() {
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Status.BlockShards = 2
+				Expect(k8sClient.Status().Update(ctx, updatedResource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: secondShard, Namespace: ns}, cm); err != nil {
+						return false
+					}
+					return strings.Contains(cm.Data[manifestsstore.RelabelConfigKey], `- action: replace
+  source_labels: ["previous_shard"]
+  target_label: shard
+  regex: (1)`)
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Status.BlockShards = 0
+				Expect(k8sClient.Status().Update(ctx, updatedResource)).Should(Succeed())
+			})
+
+			By("splitting the shards into time windows with the time sharding strategy", func() {
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.ShardingStrategy.Type = monitoringthanosiov1alpha1.Time
+				updatedResource.Spec.ShardingStrategy.Interval = ptr.To(monitoringthanosiov1alpha1.Duration("1w"))
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetArgs(k8sClient, firstShard, ns, 0, "--min-time=-1w") &&
+						utils.VerifyStatefulSetArgs(k8sClient, secondShard, ns, 0, "--min-time=-2w") &&
+						utils.VerifyStatefulSetArgs(k8sClient, secondShard, ns, 0, "--max-time=-1w") &&
+						utils.VerifyStatefulSetArgs(k8sClient, thirdShard, ns, 0, "--max-time=-2w")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.ShardingStrategy.Type = monitoringthanosiov1alpha1.Block
+				updatedResource.Spec.ShardingStrategy.Interval = nil
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+			})
+
+			By("reporting the readiness of each shard in status", func() {
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, resource); err != nil {
+						return false
+					}
+					if resource.Status.ObservedGeneration != resource.GetGeneration() || len(resource.Status.Shards) != 3 {
+						return false
+					}
+					for i, shard := range []string{firstShard, secondShard, thirdShard} {
+						if resource.Status.Shards[i].Name != shard || resource.Status.Shards[i].Replicas != 2 {
+							return false
+						}
+					}
+					// no pods become ready in the test environment
+					c := meta.FindStatusCondition(resource.Status.Conditions, monitoringthanosiov1alpha1.ConditionAvailable)
+					return c != nil && c.Status == metav1.ConditionFalse && c.Reason == reasonShardsUnavailable
+				}, time.Second*30, time.Second*2).Should(BeTrue())
+			})
+
 			By("checking additional container", func() {
 				EventuallyWithOffset(1, func() bool {
 					statefulSet := &appsv1.StatefulSet{}
@@ -215,6 +297,130 @@ config:
 				}, time.Second*10, time.Second*2).Should(BeTrue())
 			})
 
+			By("configuring the pod disruption budget of each shard", func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.PodDisruptionConfig = &monitoringthanosiov1alpha1.PodDisruptionConfig{MinAvailable: ptr.To(int32(1))}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				for _, shard := range []string{firstShard, secondShard, thirdShard} {
+					EventuallyWithOffset(1, func() bool {
+						pdb := &policyv1.PodDisruptionBudget{}
+						if err := k8sClient.Get(ctx, types.NamespacedName{Name: shard, Namespace: ns}, pdb); err != nil {
+							return false
+						}
+						return pdb.Spec.MaxUnavailable == nil && pdb.Spec.MinAvailable != nil && pdb.Spec.MinAvailable.IntVal == 1
+					}, time.Second*10, time.Second*2).Should(BeTrue())
+				}
+
+				invalid := resource.DeepCopy()
+				invalid.Spec.PodDisruptionConfig.MaxUnavailable = ptr.To(int32(1))
+				Expect(k8sClient.Update(ctx, invalid)).ShouldNot(Succeed())
+			})
+
+			By("annotating the volumes of each shard for backups", func() {
+				// claims are created by the StatefulSet controller, which does not run in the test environment
+				pvc := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: "data-" + firstShard + "-0", Namespace: ns},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: apiresource.MustParse("1Gi")},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pvc)).Should(Succeed())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.Backup = &monitoringthanosiov1alpha1.BackupConfig{
+					VolumeSnapshotClass: ptr.To("csi-snapclass"),
+					PreBackupHook: &monitoringthanosiov1alpha1.BackupHook{
+						Command: []string{"/bin/sh", "-c", "sync"},
+					},
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc); err != nil {
+						return false
+					}
+					return pvc.Annotations[manifests.VeleroVolumeSnapshotClassAnnotation] == "csi-snapclass"
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					sts := &appsv1.StatefulSet{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: firstShard, Namespace: ns}, sts); err != nil {
+						return false
+					}
+					return sts.Spec.Template.Annotations["pre.hook.backup.velero.io/command"] == `["/bin/sh","-c","sync"]`
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("deleting the volumes of each shard on scale down and labelling existing volumes", func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.VolumeClaimLabels = map[string]string{"team": "observability"}
+				resource.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+					WhenScaled: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					sts := &appsv1.StatefulSet{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: firstShard, Namespace: ns}, sts); err != nil {
+						return false
+					}
+					policy := sts.Spec.PersistentVolumeClaimRetentionPolicy
+					return policy != nil && policy.WhenScaled == appsv1.DeletePersistentVolumeClaimRetentionPolicyType &&
+						policy.WhenDeleted == appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					pvc := &corev1.PersistentVolumeClaim{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: "data-" + firstShard + "-0", Namespace: ns}, pvc); err != nil {
+						return false
+					}
+					return pvc.Labels["team"] == "observability"
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("spreading the Store Gateways of each shard across zones", func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.NodeSelector = map[string]string{"node-pool": "thanos"}
+				resource.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+				resource.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+					MaxSkew:           1,
+					TopologyKey:       "topology.kubernetes.io/zone",
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+				}}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					sts := &appsv1.StatefulSet{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: secondShard, Namespace: ns}, sts); err != nil {
+						return false
+					}
+					spec := sts.Spec.Template.Spec
+					return spec.NodeSelector["node-pool"] == "thanos" && len(spec.Tolerations) == 1 &&
+						len(spec.TopologySpreadConstraints) == 1 &&
+						reflect.DeepEqual(spec.TopologySpreadConstraints[0].LabelSelector, sts.Spec.Selector)
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("serving the StoreAPI with mTLS", func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.GRPCServerTLS = &monitoringthanosiov1alpha1.GRPCServerTLSConfig{
+					CertSecret: "store-tls",
+					ClientCA: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "store-tls"},
+						Key:                  "ca.crt",
+					},
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetArgs(k8sClient, firstShard, ns, 0, "--grpc-server-tls-client-ca=/etc/thanos/tls/grpc-server-tls/ca/ca.crt")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
 			By("ensuring old shards are cleaned up", func() {
 				resource.Spec.ShardingStrategy.Shards = 1
 				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
@@ -256,6 +462,341 @@ config:
 
 			})
 
+			By("creating a statefulset per tier", func() {
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.Tiers = []monitoringthanosiov1alpha1.StoreTier{
+					{
+						Name:    "hot",
+						MaxTime: ptr.To(monitoringthanosiov1alpha1.TimeOrDuration("0")),
+					},
+					{
+						Name:        "cold",
+						StorageSize: ptr.To(monitoringthanosiov1alpha1.StorageSize("2Gi")),
+					},
+				}
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				verifier := utils.Verifier{}.WithStatefulSet().WithService().WithServiceAccount()
+				for _, tier := range []string{"hot", "cold"} {
+					name := StoreTierNameFromParent(resourceName, tier, nil)
+					EventuallyWithOffset(1, func() bool {
+						return verifier.Verify(k8sClient, name, ns)
+					}, time.Second*10, time.Second*2).Should(BeTrue())
+				}
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetArgs(k8sClient, StoreTierNameFromParent(resourceName, "hot", nil), ns, 0, "--max-time=0")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetExists(k8sClient, StoreNameFromParent(resourceName, nil), ns)
+				}, time.Second*10, time.Second*2).Should(BeFalse())
+			})
+
+			By("skipping the updates of a paused shard", func() {
+				hot := StoreTierNameFromParent(resourceName, "hot", nil)
+				cold := StoreTierNameFromParent(resourceName, "cold", nil)
+				sts := &appsv1.StatefulSet{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: hot, Namespace: ns}, sts)).Should(Succeed())
+				sts.Annotations = manifests.MergeLabels(sts.Annotations, map[string]string{monitoringthanosiov1alpha1.PauseShardAnnotation: "true"})
+				Expect(k8sClient.Update(ctx, sts)).Should(Succeed())
+
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.CommonFields.LogLevel = ptr.To("warn")
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetArgs(k8sClient, cold, ns, 0, "--log.level=warn")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+				Consistently(func() bool {
+					return utils.VerifyStatefulSetArgs(k8sClient, hot, ns, 0, "--log.level=warn")
+				}, time.Second*5, time.Second).Should(BeFalse())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				Expect(updatedResource.Status.Shards).Should(ContainElement(monitoringthanosiov1alpha1.StoreShardStatus{
+					Name: hot, Replicas: ptr.Deref(sts.Spec.Replicas, 1), ReadyReplicas: sts.Status.ReadyReplicas, Paused: true,
+				}))
+
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: hot, Namespace: ns}, sts)).Should(Succeed())
+				delete(sts.Annotations, monitoringthanosiov1alpha1.PauseShardAnnotation)
+				Expect(k8sClient.Update(ctx, sts)).Should(Succeed())
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetArgs(k8sClient, hot, ns, 0, "--log.level=warn")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("capping the max time at the time split boundary of the stack", func() {
+				query := &monitoringthanosiov1alpha1.ThanosQuery{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "stack-query",
+						Namespace: ns,
+						Labels:    map[string]string{monitoringthanosiov1alpha1.StackLabel: "time-split"},
+					},
+					Spec: monitoringthanosiov1alpha1.ThanosQuerySpec{
+						TimeSplit: &monitoringthanosiov1alpha1.TimeSplitSpec{Boundary: "2d"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, query)).Should(Succeed())
+
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.SetLabels(map[string]string{monitoringthanosiov1alpha1.StackLabel: "time-split"})
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetArgs(k8sClient, StoreTierNameFromParent(resourceName, "hot", nil), ns, 0, "--max-time=-2d")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Delete(ctx, query)).Should(Succeed())
+			})
+
+			By("attaching a debug container to the annotated pod", func() {
+				// the pod belongs to the first shard, so that its crashes are reported below
+				statefulSet := &appsv1.StatefulSet{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: firstShard, Namespace: ns}, statefulSet)).Should(Succeed())
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "store-pod",
+						Namespace: ns,
+						Labels:    manifests.MergeLabels(statefulSet.Spec.Selector.MatchLabels, map[string]string{manifests.OwnerLabel: resourceName}),
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:  "thanos-store",
+							Image: "quay.io/thanos/thanos:v0.35.1",
+							Env: []corev1.EnvVar{{
+								Name: "OBJSTORE_CONFIG",
+								ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "thanos-objstore"},
+									Key:                  "thanos.yaml",
+								}},
+							}},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pod)).Should(Succeed())
+
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.SetAnnotations(map[string]string{monitoringthanosiov1alpha1.DebugPodAnnotation: pod.GetName()})
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), pod); err != nil {
+						return false
+					}
+					return len(pod.Spec.EphemeralContainers) == 1 &&
+						pod.Spec.EphemeralContainers[0].TargetContainerName == "thanos-store" &&
+						pod.Spec.EphemeralContainers[0].Env[0].Name == "OBJSTORE_CONFIG"
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("reporting crash looping containers with their termination message", func() {
+				pod := &corev1.Pod{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "store-pod", Namespace: ns}, pod)).Should(Succeed())
+				pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+					Name:         "thanos-store",
+					Image:        "quay.io/thanos/thanos:v0.35.1",
+					RestartCount: 3,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error", Message: "invalid bucket config"},
+					},
+				}}
+				Expect(k8sClient.Status().Update(ctx, pod)).Should(Succeed())
+
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.TerminationMessagePolicy = ptr.To(corev1.TerminationMessageReadFile)
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, updatedResource); err != nil {
+						return false
+					}
+					c := meta.FindStatusCondition(updatedResource.Status.Conditions, monitoringthanosiov1alpha1.ConditionCrashLooping)
+					return c != nil && c.Status == metav1.ConditionTrue && c.Reason == reasonContainersCrashLooping &&
+						strings.Contains(c.Message, "invalid bucket config")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					statefulSet := &appsv1.StatefulSet{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: firstShard, Namespace: ns}, statefulSet); err != nil {
+						return false
+					}
+					return statefulSet.Spec.Template.Spec.Containers[0].TerminationMessagePolicy == corev1.TerminationMessageReadFile
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("rendering an inline object storage configuration into a managed Secret", func() {
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				objStoreConfig := updatedResource.Spec.ObjectStorageConfig
+				updatedResource.Spec.ObjectStorageConfig = nil
+				updatedResource.Spec.ObjectStorage = &monitoringthanosiov1alpha1.ObjectStorage{
+					S3: &monitoringthanosiov1alpha1.S3ObjectStorage{
+						Bucket:    "thanos",
+						Endpoint:  "s3.eu-west-1.amazonaws.com",
+						AccessKey: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s3"}, Key: "access-key"},
+						SecretKey: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s3"}, Key: "secret-key"},
+					},
+				}
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				secretName := StoreObjectStorageNameFromParent(resourceName)
+				Eventually(func() bool {
+					secret := &corev1.Secret{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: ns}, secret); err != nil {
+						return false
+					}
+					return strings.Contains(string(secret.Data[manifests.ObjectStorageConfigKey]), "access_key: $(OBJSTORE_ACCESS_KEY)")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Eventually(func() bool {
+					statefulSet := &appsv1.StatefulSet{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: firstShard, Namespace: ns}, statefulSet); err != nil {
+						return false
+					}
+					var objStore, accessKey bool
+					for _, env := range statefulSet.Spec.Template.Spec.Containers[0].Env {
+						switch {
+						case env.Name == "OBJSTORE_CONFIG" && env.ValueFrom.SecretKeyRef.Name == secretName:
+							objStore = true
+						case env.Name == "OBJSTORE_ACCESS_KEY" && env.ValueFrom.SecretKeyRef.Name == "s3":
+							accessKey = true
+						}
+					}
+					return objStore && accessKey
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.ObjectStorage = nil
+				updatedResource.Spec.ObjectStorageConfig = objStoreConfig
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				Eventually(func() bool {
+					return utils.VerifySecretExists(k8sClient, secretName, ns)
+				}, time.Second*10, time.Second*2).Should(BeFalse())
+			})
+
+			By("reporting an invalid object storage configuration before rolling out", func() {
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				objStoreConfig := updatedResource.Spec.ObjectStorageConfig.DeepCopy()
+				if updatedResource.Spec.FeatureGates == nil {
+					updatedResource.Spec.FeatureGates = &monitoringthanosiov1alpha1.FeatureGates{}
+				}
+				updatedResource.Spec.FeatureGates.ObjectStoragePreflight = ptr.To(true)
+				updatedResource.Spec.ObjectStorageConfig.Key = "missing.yaml"
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				Eventually(func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, updatedResource); err != nil {
+						return false
+					}
+					degraded := meta.FindStatusCondition(updatedResource.Status.Conditions, monitoringthanosiov1alpha1.ConditionDegraded)
+					return degraded != nil && degraded.Status == metav1.ConditionTrue && degraded.Reason == "InvalidObjectStorageConfig" &&
+						strings.Contains(degraded.Message, "key missing.yaml not found in Secret thanos-objstore")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				updatedResource.Spec.ObjectStorageConfig = objStoreConfig
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				Eventually(func() bool {
+					if err := k8sClient.Get(ctx, typeNamespacedName, updatedResource); err != nil {
+						return false
+					}
+					degraded := meta.FindStatusCondition(updatedResource.Status.Conditions, monitoringthanosiov1alpha1.ConditionDegraded)
+					return degraded == nil || degraded.Reason != "InvalidObjectStorageConfig"
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("tuning the probes of the Store Gateways", func() {
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.GRPCServerTLS = nil
+				updatedResource.Spec.Probes = &monitoringthanosiov1alpha1.Probes{
+					Startup: &monitoringthanosiov1alpha1.ProbeSettings{FailureThreshold: ptr.To(int32(60))},
+					GRPC:    true,
+				}
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					statefulSet := &appsv1.StatefulSet{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: firstShard, Namespace: ns}, statefulSet); err != nil {
+						return false
+					}
+					c := statefulSet.Spec.Template.Spec.Containers[0]
+					return c.ReadinessProbe.GRPC != nil && c.ReadinessProbe.GRPC.Port == 10901 &&
+						c.StartupProbe != nil && c.StartupProbe.GRPC != nil && c.StartupProbe.FailureThreshold == 60 &&
+						c.LivenessProbe.HTTPGet != nil
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("deploying a managed redis for the index cache", func() {
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.IndexCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					RedisCacheConfig: &monitoringthanosiov1alpha1.RedisCacheConfig{
+						Managed: &monitoringthanosiov1alpha1.ManagedRedisConfig{},
+					},
+				}
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				redisName := "redis-" + resourceName + "-index-cache"
+				EventuallyWithOffset(1, func() error {
+					return k8sClient.Get(ctx, types.NamespacedName{Name: redisName, Namespace: ns}, &appsv1.Deployment{})
+				}, time.Second*10, time.Second*2).Should(Succeed())
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetArgs(k8sClient, firstShard, ns, 0,
+						fmt.Sprintf("--index-cache.config=type: REDIS\nconfig:\n  addr: %s.%s.svc:6379\n", redisName, ns))
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.IndexCacheConfig = nil
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+				EventuallyWithOffset(1, func() bool {
+					err := k8sClient.Get(ctx, types.NamespacedName{Name: redisName, Namespace: ns}, &appsv1.Deployment{})
+					return apierrors.IsNotFound(err)
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("deploying a managed memcached for the caching bucket", func() {
+				updatedResource := &monitoringthanosiov1alpha1.ThanosStore{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.CachingBucketConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					MemcachedCacheConfig: &monitoringthanosiov1alpha1.MemcachedCacheConfig{
+						Managed: &monitoringthanosiov1alpha1.ManagedMemcachedConfig{Replicas: ptr.To(int32(2))},
+					},
+				}
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+
+				memcachedName := "memcached-" + resourceName + "-caching-bucket"
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetReplicas(k8sClient, 2, memcachedName, ns) &&
+						utils.VerifyServiceExists(k8sClient, memcachedName, ns)
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+				EventuallyWithOffset(1, func() bool {
+					statefulSet := &appsv1.StatefulSet{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: firstShard, Namespace: ns}, statefulSet); err != nil {
+						return false
+					}
+					args := strings.Join(statefulSet.Spec.Template.Spec.Containers[0].Args, " ")
+					return strings.Contains(args, fmt.Sprintf("dnssrv+_client._tcp.%s.%s.svc", memcachedName, ns))
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, updatedResource)).Should(Succeed())
+				updatedResource.Spec.CachingBucketConfig = nil
+				Expect(k8sClient.Update(ctx, updatedResource)).Should(Succeed())
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyStatefulSetExists(k8sClient, memcachedName, ns)
+				}, time.Second*10, time.Second*2).Should(BeFalse())
+			})
+
 			By("checking paused state", func() {
 				resource := &monitoringthanosiov1alpha1.ThanosStore{}
 				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
@@ -0,0 +1,378 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/handlers"
+	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
+	manifeststenant "github.com/thanos-community/thanos-operator/pkg/manifests/tenant"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	tenantFinalizer = "monitoring.thanos.io/tenant-finalizer"
+)
+
+// ThanosTenantReconciler reconciles a ThanosTenant object
+type ThanosTenantReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	logger           logr.Logger
+	metrics          controllermetrics.ThanosTenantMetrics
+	reconcileMetrics *controllermetrics.ReconcileMetrics
+	recorder         record.EventRecorder
+
+	handler          *handlers.Handler
+	controllerConfig ControllerConfig
+}
+
+//+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanostenants,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanostenants/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanostenants/finalizers,verbs=update
+//+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosreceives,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For ThanosTenant, this exposes the remote write endpoint of the referenced ThanosReceive
+// for the tenant and publishes the remote write configuration to the namespace of the tenant.
+// The limits of the tenant are applied by the ThanosReceive controller.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.17.3/pkg/reconcile
+func (r *ThanosTenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	tenant := &monitoringthanosiov1alpha1.ThanosTenant{}
+	err := r.Get(ctx, req.NamespacedName, tenant)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.logger.Info("thanos tenant resource not found. ignoring since object may be deleted")
+			return ctrl.Result{}, nil
+		}
+		r.logger.Error(err, "failed to get ThanosTenant")
+		r.recorder.Event(tenant, corev1.EventTypeWarning, "GetFailed", "Failed to get ThanosTenant resource")
+		return ctrl.Result{}, err
+	}
+	start := time.Now()
+	defer func() { r.reconcileMetrics.ObserveApply(r.handler.LogApplySummary(tenant, start)) }()
+	ctx, prof := startProfiling(ctx, nil)
+	defer recordProfile(r.recorder, r.reconcileMetrics, tenant, prof)
+
+	// handle object being deleted - inferred from the existence of DeletionTimestamp
+	if !tenant.GetDeletionTimestamp().IsZero() {
+		return r.handleDeletionTimestamp(ctx, tenant)
+	}
+
+	if tenant.Spec.Paused != nil && *tenant.Spec.Paused {
+		r.logger.Info("reconciliation is paused for ThanosTenant resource")
+		r.recorder.Event(tenant, corev1.EventTypeNormal, "Paused", "Reconciliation is paused for ThanosTenant resource")
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(tenant, tenantFinalizer) {
+		controllerutil.AddFinalizer(tenant, tenantFinalizer)
+		if err := r.Update(ctx, tenant); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+		}
+	}
+
+	err = r.syncResources(ctx, *tenant)
+	if err != nil {
+		r.recorder.Event(tenant, corev1.EventTypeWarning, "SyncFailed", fmt.Sprintf("Failed to sync resources: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// NewThanosTenantReconciler returns a reconciler for ThanosTenant resources.
+func NewThanosTenantReconciler(conf Config, client client.Client, scheme *runtime.Scheme) *ThanosTenantReconciler {
+	handler := handlers.NewHandler(client, scheme, conf.InstrumentationConfig.Logger)
+	handler.SetLogSampler(conf.InstrumentationConfig.LogSampler)
+
+	return &ThanosTenantReconciler{
+		Client:           client,
+		Scheme:           scheme,
+		logger:           conf.InstrumentationConfig.Logger,
+		metrics:          controllermetrics.NewThanosTenantMetrics(conf.InstrumentationConfig.MetricsRegistry),
+		reconcileMetrics: conf.InstrumentationConfig.ReconcileMetrics,
+		recorder:         conf.InstrumentationConfig.EventRecorder,
+		handler:          handler,
+		controllerConfig: conf.Controller,
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ThanosTenantReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monitoringthanosiov1alpha1.ThanosTenant{}).
+		WithOptions(r.controllerConfig.options()).
+		Owns(&networkingv1.Ingress{}).
+		Watches(&corev1.Namespace{}, r.enqueueForNamespace()).
+		// only the metadata of Secrets is watched, so that their data is not cached
+		WatchesMetadata(&corev1.Secret{}, r.enqueueForCredentialsSecret()).
+		Complete(r)
+}
+
+// enqueueForCredentialsSecret returns an EventHandler that will enqueue a request for the ThanosTenant instances
+// referencing the Secret for the credentials of their Prometheus remote write Secret, so that rotated credentials are re-published.
+func (r *ThanosTenantReconciler) enqueueForCredentialsSecret() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		tenants := &monitoringthanosiov1alpha1.ThanosTenantList{}
+		if err := r.List(ctx, tenants, client.InNamespace(obj.GetNamespace())); err != nil {
+			return []reconcile.Request{}
+		}
+
+		requests := []reconcile.Request{}
+		for _, tenant := range tenants.Items {
+			spec := tenant.Spec.PrometheusRemoteWrite
+			if spec == nil {
+				continue
+			}
+			if (spec.BasicAuthSecret != nil && spec.BasicAuthSecret.Name == obj.GetName()) ||
+				(spec.CA != nil && spec.CA.Name == obj.GetName()) {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      tenant.GetName(),
+						Namespace: tenant.GetNamespace(),
+					},
+				})
+			}
+		}
+		return requests
+	})
+}
+
+// enqueueForNamespace returns an EventHandler that will enqueue a request for the ThanosTenant instances
+// publishing their Prometheus remote write Secret to selected namespaces, so that they re-evaluate their selector.
+func (r *ThanosTenantReconciler) enqueueForNamespace() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		tenants := &monitoringthanosiov1alpha1.ThanosTenantList{}
+		if err := r.List(ctx, tenants); err != nil {
+			return []reconcile.Request{}
+		}
+
+		requests := []reconcile.Request{}
+		for _, tenant := range tenants.Items {
+			if tenant.Spec.PrometheusRemoteWrite == nil || tenant.Spec.PrometheusRemoteWrite.NamespaceSelector == nil {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      tenant.GetName(),
+					Namespace: tenant.GetNamespace(),
+				},
+			})
+		}
+		return requests
+	})
+}
+
+func (r *ThanosTenantReconciler) syncResources(ctx context.Context, tenant monitoringthanosiov1alpha1.ThanosTenant) error {
+	receive := &monitoringthanosiov1alpha1.ThanosReceive{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: tenant.GetNamespace(), Name: tenant.Spec.ReceiveName}, receive); err != nil {
+		return fmt.Errorf("failed to get referenced ThanosReceive %s: %w", tenant.Spec.ReceiveName, err)
+	}
+
+	opts := tenantV1Alpha1ToOptions(tenant)
+	if errCount := r.handler.CreateOrUpdate(ctx, tenant.GetNamespace(), &tenant, opts.Build()); errCount > 0 {
+		return fmt.Errorf("failed to create or update %d resources for tenant", errCount)
+	}
+
+	if opts.Ingress == nil {
+		ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: opts.GetGeneratedResourceName(), Namespace: tenant.GetNamespace()}}
+		if errCount := r.handler.DeleteResource(ctx, []client.Object{ingress}); errCount > 0 {
+			return fmt.Errorf("failed to delete Ingress for tenant")
+		}
+	}
+
+	// the remote write configuration lives in the namespace of the tenant and can not be owned by the ThanosTenant
+	// so we manage it directly and clean it up via the finalizer
+	if err := r.handler.Apply(ctx, manifeststenant.NewRemoteWriteConfigMap(opts)); err != nil {
+		return fmt.Errorf("failed to publish remote write configuration to namespace %s: %w", opts.TargetNamespace, err)
+	}
+
+	published, err := r.publishPrometheusRemoteWrite(ctx, tenant, opts)
+	if err != nil {
+		return err
+	}
+
+	return r.updateStatus(ctx, tenant, opts, published)
+}
+
+// publishPrometheusRemoteWrite publishes the Prometheus remote write Secret of the tenant to the target namespace
+// and the selected namespaces, and deletes it from the namespaces which are no longer selected.
+// It returns the namespaces the Secret is published to.
+func (r *ThanosTenantReconciler) publishPrometheusRemoteWrite(ctx context.Context, tenant monitoringthanosiov1alpha1.ThanosTenant, opts manifeststenant.Options) ([]string, error) {
+	var namespaces []string
+	if spec := tenant.Spec.PrometheusRemoteWrite; spec != nil {
+		var err error
+		if namespaces, err = r.getPublishNamespaces(ctx, tenant); err != nil {
+			return nil, err
+		}
+		creds, err := r.getPrometheusRemoteWriteCredentials(ctx, tenant)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range namespaces {
+			if err := r.handler.Apply(ctx, manifeststenant.NewPrometheusRemoteWriteSecret(opts, ns, creds)); err != nil {
+				return nil, fmt.Errorf("failed to publish Prometheus remote write Secret to namespace %s: %w", ns, err)
+			}
+		}
+	}
+
+	// the Secrets live in other namespaces and can not be owned by the ThanosTenant, so they are pruned by their labels
+	return namespaces, r.deletePrometheusRemoteWriteSecrets(ctx, opts, namespaces)
+}
+
+// getPublishNamespaces returns the sorted target namespace and the namespaces selected by the NamespaceSelector
+// of the Prometheus remote write configuration of the tenant.
+func (r *ThanosTenantReconciler) getPublishNamespaces(ctx context.Context, tenant monitoringthanosiov1alpha1.ThanosTenant) ([]string, error) {
+	namespaces := []string{tenant.Spec.TargetNamespace}
+	if tenant.Spec.PrometheusRemoteWrite.NamespaceSelector == nil {
+		return namespaces, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(tenant.Spec.PrometheusRemoteWrite.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+	list := &corev1.NamespaceList{}
+	if err := r.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	for _, ns := range list.Items {
+		if ns.GetDeletionTimestamp().IsZero() && !slices.Contains(namespaces, ns.GetName()) {
+			namespaces = append(namespaces, ns.GetName())
+		}
+	}
+	slices.Sort(namespaces)
+	return namespaces, nil
+}
+
+// getPrometheusRemoteWriteCredentials reads the credentials published with the Prometheus remote write Secret
+// from the Secrets in the namespace of the tenant.
+func (r *ThanosTenantReconciler) getPrometheusRemoteWriteCredentials(ctx context.Context, tenant monitoringthanosiov1alpha1.ThanosTenant) (manifeststenant.PrometheusRemoteWriteCredentials, error) {
+	var creds manifeststenant.PrometheusRemoteWriteCredentials
+	spec := tenant.Spec.PrometheusRemoteWrite
+	if spec.BasicAuthSecret != nil {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: tenant.GetNamespace(), Name: spec.BasicAuthSecret.Name}, secret); err != nil {
+			return creds, fmt.Errorf("failed to get basic auth Secret %s: %w", spec.BasicAuthSecret.Name, err)
+		}
+		creds.Username = secret.Data[manifeststenant.PrometheusRemoteWriteUsernameKey]
+		creds.Password = secret.Data[manifeststenant.PrometheusRemoteWritePasswordKey]
+		if creds.Username == nil || creds.Password == nil {
+			return creds, fmt.Errorf("basic auth Secret %s must hold the keys %s and %s", spec.BasicAuthSecret.Name,
+				manifeststenant.PrometheusRemoteWriteUsernameKey, manifeststenant.PrometheusRemoteWritePasswordKey)
+		}
+	}
+	if spec.CA != nil {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: tenant.GetNamespace(), Name: spec.CA.Name}, secret); err != nil {
+			return creds, fmt.Errorf("failed to get CA Secret %s: %w", spec.CA.Name, err)
+		}
+		if creds.CA = secret.Data[spec.CA.Key]; creds.CA == nil {
+			return creds, fmt.Errorf("CA Secret %s does not hold the key %s", spec.CA.Name, spec.CA.Key)
+		}
+	}
+	return creds, nil
+}
+
+// deletePrometheusRemoteWriteSecrets deletes the Prometheus remote write Secrets of the tenant
+// from all namespaces but the given ones.
+func (r *ThanosTenantReconciler) deletePrometheusRemoteWriteSecrets(ctx context.Context, opts manifeststenant.Options, keep []string) error {
+	list := &corev1.SecretList{}
+	if err := r.List(ctx, list, client.MatchingLabels(opts.GetPrometheusRemoteWriteSelectorLabels())); err != nil {
+		return fmt.Errorf("failed to list Prometheus remote write Secrets: %w", err)
+	}
+	var stale []client.Object
+	for _, secret := range list.Items {
+		if !slices.Contains(keep, secret.GetNamespace()) {
+			stale = append(stale, &secret)
+		}
+	}
+	if errCount := r.handler.DeleteResource(ctx, stale); errCount > 0 {
+		return fmt.Errorf("failed to delete %d Prometheus remote write Secrets", errCount)
+	}
+	return nil
+}
+
+// updateStatus reflects the onboarding state of the tenant in the status of the ThanosTenant resource.
+func (r *ThanosTenantReconciler) updateStatus(ctx context.Context, tenant monitoringthanosiov1alpha1.ThanosTenant, opts manifeststenant.Options, published []string) error {
+	tenant.Status.TenantID = opts.TenantID
+	tenant.Status.RemoteWriteURL = opts.GetRemoteWriteURL()
+	tenant.Status.PublishedNamespaces = published
+	meta.SetStatusCondition(&tenant.Status.Conditions, metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ThanosTenantConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Onboarded",
+		Message:            fmt.Sprintf("Remote write configuration published to namespace %s", opts.TargetNamespace),
+		ObservedGeneration: tenant.GetGeneration(),
+	})
+
+	if err := r.Status().Update(ctx, &tenant); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	return nil
+}
+
+func (r *ThanosTenantReconciler) handleDeletionTimestamp(ctx context.Context, tenant *monitoringthanosiov1alpha1.ThanosTenant) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(tenant, tenantFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	r.logger.Info("performing Finalizer Operations for ThanosTenant before delete CR")
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: TenantNameFromParent(tenant.GetName()), Namespace: tenant.Spec.TargetNamespace}}
+	if errCount := r.handler.DeleteResource(ctx, []client.Object{cm}); errCount > 0 {
+		return ctrl.Result{}, fmt.Errorf("failed to delete remote write configuration from namespace %s", tenant.Spec.TargetNamespace)
+	}
+	if err := r.deletePrometheusRemoteWriteSecrets(ctx, tenantV1Alpha1ToOptions(*tenant), nil); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	controllerutil.RemoveFinalizer(tenant, tenantFinalizer)
+	if err := r.Update(ctx, tenant); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
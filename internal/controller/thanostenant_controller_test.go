@@ -0,0 +1,239 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/pkg/manifests/receive"
+	"github.com/thanos-community/thanos-operator/pkg/manifests/tenant"
+	"github.com/thanos-community/thanos-operator/test/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("ThanosTenant Controller", Ordered, func() {
+	Context("When reconciling a resource", func() {
+		const (
+			ns           = "thanos-tenant-test"
+			targetNS     = "thanos-tenant-team-a"
+			publishNS    = "thanos-tenant-team-a-apps"
+			resourceName = "team-a"
+			receiveName  = "test-tenant-receive"
+		)
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: ns,
+		}
+
+		name := TenantNameFromParent(resourceName)
+		routerName := ReceiveRouterNameFromParent(receiveName)
+
+		BeforeAll(func() {
+			By("creating the namespaces")
+			for _, n := range []string{ns, targetNS} {
+				Expect(k8sClient.Create(ctx, &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: n,
+					},
+				})).Should(Succeed())
+			}
+
+			By("creating a receive resource for the tenant to write to")
+			Expect(k8sClient.Create(ctx, &monitoringthanosiov1alpha1.ThanosReceive{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      receiveName,
+					Namespace: ns,
+				},
+				Spec: monitoringthanosiov1alpha1.ThanosReceiveSpec{
+					Router: monitoringthanosiov1alpha1.RouterSpec{
+						Replicas:          1,
+						ReplicationFactor: 1,
+					},
+					Ingester: monitoringthanosiov1alpha1.IngesterSpec{
+						DefaultObjectStorageConfig: monitoringthanosiov1alpha1.ObjectStorageConfig{
+							SecretKeySelector: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "test-secret"},
+								Key:                  "test-key",
+							},
+						},
+						Hashrings: []monitoringthanosiov1alpha1.IngesterHashringSpec{
+							{
+								Name:        "default",
+								StorageSize: "100Mi",
+								Replicas:    1,
+							},
+						},
+					},
+				},
+			})).Should(Succeed())
+		})
+
+		It("should reconcile correctly", func() {
+			if os.Getenv("EXCLUDE_TENANT") == skipValue {
+				Skip("Skipping ThanosTenant controller tests")
+			}
+
+			resource := &monitoringthanosiov1alpha1.ThanosTenant{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: ns,
+				},
+				Spec: monitoringthanosiov1alpha1.ThanosTenantSpec{
+					ReceiveName:     receiveName,
+					TargetNamespace: targetNS,
+					Limits: &monitoringthanosiov1alpha1.TenantLimits{
+						SeriesLimit: ptr.To(int64(1000)),
+					},
+				},
+			}
+
+			By("publishing the remote write configuration to the target namespace", func() {
+				Expect(k8sClient.Create(ctx, resource)).Should(Succeed())
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: targetNS}, cm); err != nil {
+						return false
+					}
+					return strings.Contains(cm.Data[tenant.RemoteWriteConfigKey], "THANOS-TENANT: team-a") &&
+						strings.Contains(cm.Data[tenant.RemoteWriteConfigKey], routerName)
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("reporting the remote write url in the status", func() {
+				EventuallyWithOffset(1, func() bool {
+					t := &monitoringthanosiov1alpha1.ThanosTenant{}
+					if err := k8sClient.Get(ctx, typeNamespacedName, t); err != nil {
+						return false
+					}
+					return t.Status.TenantID == resourceName && strings.Contains(t.Status.RemoteWriteURL, routerName)
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("configuring the tenant limits on the receive router", func() {
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: routerName, Namespace: ns}, cm); err != nil {
+						return false
+					}
+					return strings.Contains(cm.Data[receive.LimitsConfigKey], "team-a") &&
+						strings.Contains(cm.Data[receive.LimitsConfigKey], "series_limit: 1000")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyDeploymentArgs(k8sClient, routerName, ns, 0, "--receive.limits-config-file=var/lib/thanos-receive/limits.yaml")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("creating an ingress for the tenant", func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.Ingress = &monitoringthanosiov1alpha1.TenantIngress{
+					Host:            "receive.example.com",
+					Path:            "/team-a/api/v1/receive",
+					BasicAuthSecret: &corev1.LocalObjectReference{Name: "team-a-auth"},
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					ingress := &networkingv1.Ingress{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, ingress); err != nil {
+						return false
+					}
+					return ingress.Spec.Rules[0].Host == "receive.example.com" &&
+						ingress.Annotations["nginx.ingress.kubernetes.io/auth-secret"] == "team-a-auth"
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: targetNS}, cm); err != nil {
+						return false
+					}
+					return strings.Contains(cm.Data[tenant.RemoteWriteConfigKey], "http://receive.example.com/team-a/api/v1/receive")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("publishing the prometheus remote write secret to the selected namespaces", func() {
+				Expect(k8sClient.Create(ctx, &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   publishNS,
+						Labels: map[string]string{"team": "a"},
+					},
+				})).Should(Succeed())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).Should(Succeed())
+				resource.Spec.PrometheusRemoteWrite = &monitoringthanosiov1alpha1.TenantPrometheusRemoteWrite{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				}
+				Expect(k8sClient.Update(ctx, resource)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					for _, n := range []string{targetNS, publishNS} {
+						secret := &corev1.Secret{}
+						if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: n}, secret); err != nil {
+							return false
+						}
+						if !strings.Contains(string(secret.Data[tenant.RemoteWriteConfigKey]), "THANOS-TENANT: team-a") {
+							return false
+						}
+					}
+					return true
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					t := &monitoringthanosiov1alpha1.ThanosTenant{}
+					if err := k8sClient.Get(ctx, typeNamespacedName, t); err != nil {
+						return false
+					}
+					return slices.Equal(t.Status.PublishedNamespaces, []string{targetNS, publishNS})
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("removing the published configuration when the tenant is deleted", func() {
+				Expect(k8sClient.Delete(ctx, resource)).Should(Succeed())
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: targetNS}, cm)
+					return apierrors.IsNotFound(err)
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					cm := &corev1.ConfigMap{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: routerName, Namespace: ns}, cm); err != nil {
+						return false
+					}
+					return cm.Data[receive.LimitsConfigKey] == ""
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+		})
+	})
+})
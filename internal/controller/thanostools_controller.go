@@ -0,0 +1,327 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/handlers"
+	controllermetrics "github.com/thanos-community/thanos-operator/internal/pkg/metrics"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ThanosToolsReconciler reconciles a ThanosTools object
+type ThanosToolsReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	logger           logr.Logger
+	metrics          controllermetrics.ThanosToolsMetrics
+	reconcileMetrics *controllermetrics.ReconcileMetrics
+	recorder         record.EventRecorder
+
+	handler          *handlers.Handler
+	controllerConfig ControllerConfig
+}
+
+//+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanostools,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanostools/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanostools/finalizers,verbs=update
+//+kubebuilder:rbac:groups=monitoring.thanos.io,resources=thanosstores;thanoscompacts;thanosrulers;thanosreceives,verbs=get;list;watch
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For ThanosTools, this creates a single Job running the requested operation
+// and reflects the state of the Job in the status of the resource.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.17.3/pkg/reconcile
+func (r *ThanosToolsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	tools := &monitoringthanosiov1alpha1.ThanosTools{}
+	err := r.Get(ctx, req.NamespacedName, tools)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.logger.Info("thanos tools resource not found. ignoring since object may be deleted")
+			return ctrl.Result{}, nil
+		}
+		r.logger.Error(err, "failed to get ThanosTools")
+		r.recorder.Event(tools, corev1.EventTypeWarning, "GetFailed", "Failed to get ThanosTools resource")
+		return ctrl.Result{}, err
+	}
+	start := time.Now()
+	defer func() { r.reconcileMetrics.ObserveApply(r.handler.LogApplySummary(tools, start)) }()
+	ctx, prof := startProfiling(ctx, nil)
+	defer recordProfile(r.recorder, r.reconcileMetrics, tools, prof)
+
+	if tools.Spec.Paused != nil && *tools.Spec.Paused {
+		r.logger.Info("reconciliation is paused for ThanosTools resource")
+		r.recorder.Event(tools, corev1.EventTypeNormal, "Paused", "Reconciliation is paused for ThanosTools resource")
+		return ctrl.Result{}, nil
+	}
+
+	err = r.syncResources(ctx, *tools)
+	if err != nil {
+		r.recorder.Event(tools, corev1.EventTypeWarning, "SyncFailed", fmt.Sprintf("Failed to sync resources: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// NewThanosToolsReconciler returns a reconciler for ThanosTools resources.
+func NewThanosToolsReconciler(conf Config, client client.Client, scheme *runtime.Scheme) *ThanosToolsReconciler {
+	handler := handlers.NewHandler(client, scheme, conf.InstrumentationConfig.Logger)
+	handler.SetLogSampler(conf.InstrumentationConfig.LogSampler)
+
+	return &ThanosToolsReconciler{
+		Client:           client,
+		Scheme:           scheme,
+		logger:           conf.InstrumentationConfig.Logger,
+		metrics:          controllermetrics.NewThanosToolsMetrics(conf.InstrumentationConfig.MetricsRegistry),
+		reconcileMetrics: conf.InstrumentationConfig.ReconcileMetrics,
+		recorder:         conf.InstrumentationConfig.EventRecorder,
+		handler:          handler,
+		controllerConfig: conf.Controller,
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ThanosToolsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monitoringthanosiov1alpha1.ThanosTools{}).
+		WithOptions(r.controllerConfig.options()).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
+
+func (r *ThanosToolsReconciler) syncResources(ctx context.Context, tools monitoringthanosiov1alpha1.ThanosTools) error {
+	// operations are one-off, so once we have observed a final phase we never recreate the Job
+	if tools.Status.Phase == monitoringthanosiov1alpha1.ThanosToolsSucceeded ||
+		tools.Status.Phase == monitoringthanosiov1alpha1.ThanosToolsFailed {
+		return nil
+	}
+
+	objStore, err := r.getObjectStorageConfig(ctx, tools)
+	if err != nil {
+		return err
+	}
+
+	opts := toolsV1Alpha1ToOptions(tools, objStore)
+	if errCount := r.handler.CreateOrUpdate(ctx, tools.GetNamespace(), &tools, opts.Build()); errCount > 0 {
+		return fmt.Errorf("failed to create or update %d resources for tools", errCount)
+	}
+
+	job, err := r.getJob(ctx, tools, opts.GetGeneratedResourceName())
+	if err != nil || job == nil {
+		return err
+	}
+
+	// operations deleting data from the bucket only succeed once the bucket passes verification
+	var verifyJob *batchv1.Job
+	if phase, _ := jobPhase(job); phase == monitoringthanosiov1alpha1.ThanosToolsSucceeded && requiresVerification(tools) {
+		verifyOpts := toolsV1Alpha1ToVerifyOptions(tools, objStore)
+		if errCount := r.handler.CreateOrUpdate(ctx, tools.GetNamespace(), &tools, verifyOpts.Build()); errCount > 0 {
+			return fmt.Errorf("failed to create or update %d resources for tools verification", errCount)
+		}
+
+		verifyJob, err = r.getJob(ctx, tools, verifyOpts.GetGeneratedResourceName())
+		if err != nil || verifyJob == nil {
+			return err
+		}
+	}
+
+	return r.updateStatus(ctx, tools, job, verifyJob)
+}
+
+// getJob returns the Job of the operation with the given name, or nil if it is not in the cache yet, e.g. right after
+// it was created. The Job is owned by the ThanosTools, so its creation triggers another reconciliation.
+func (r *ThanosToolsReconciler) getJob(ctx context.Context, tools monitoringthanosiov1alpha1.ThanosTools, name string) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: tools.GetNamespace(), Name: name}, job); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get job %s: %w", name, err)
+	}
+	return job, nil
+}
+
+// requiresVerification returns whether the operation deletes data from the bucket, and is therefore followed by
+// a Job verifying the bucket unless verification is skipped.
+func requiresVerification(tools monitoringthanosiov1alpha1.ThanosTools) bool {
+	if tools.Spec.SkipVerification {
+		return false
+	}
+
+	switch {
+	case tools.Spec.Retention != nil:
+		return true
+	case tools.Spec.Rewrite != nil:
+		return tools.Spec.Rewrite.DryRun != nil && !*tools.Spec.Rewrite.DryRun
+	case tools.Spec.Mark != nil:
+		return tools.Spec.Mark.Marker == monitoringthanosiov1alpha1.DeletionMarker && !tools.Spec.Mark.Remove
+	}
+	return false
+}
+
+// getObjectStorageConfig returns the object storage configuration for the operation.
+// If the configuration is not set directly, it is resolved from the referenced resource.
+func (r *ThanosToolsReconciler) getObjectStorageConfig(ctx context.Context, tools monitoringthanosiov1alpha1.ThanosTools) (monitoringthanosiov1alpha1.ObjectStorageConfig, error) {
+	if tools.Spec.ObjectStorageConfig != nil {
+		return *tools.Spec.ObjectStorageConfig, nil
+	}
+
+	ref := tools.Spec.ObjectStorageRef
+	if ref == nil {
+		return monitoringthanosiov1alpha1.ObjectStorageConfig{}, fmt.Errorf("no object storage configuration set")
+	}
+
+	key := client.ObjectKey{Namespace: tools.GetNamespace(), Name: ref.Name}
+	switch ref.Kind {
+	case "ThanosStore":
+		store := &monitoringthanosiov1alpha1.ThanosStore{}
+		if err := r.Get(ctx, key, store); err != nil {
+			return monitoringthanosiov1alpha1.ObjectStorageConfig{}, fmt.Errorf("failed to get referenced %s %s: %w", ref.Kind, ref.Name, err)
+		}
+		if store.Spec.ObjectStorageConfig == nil {
+			return monitoringthanosiov1alpha1.ObjectStorageConfig{}, fmt.Errorf("referenced %s %s has an inline object storage configuration, which cannot be referenced", ref.Kind, ref.Name)
+		}
+		return *store.Spec.ObjectStorageConfig, nil
+	case "ThanosCompact":
+		compact := &monitoringthanosiov1alpha1.ThanosCompact{}
+		if err := r.Get(ctx, key, compact); err != nil {
+			return monitoringthanosiov1alpha1.ObjectStorageConfig{}, fmt.Errorf("failed to get referenced %s %s: %w", ref.Kind, ref.Name, err)
+		}
+		return compact.Spec.ObjectStorageConfig, nil
+	case "ThanosRuler":
+		ruler := &monitoringthanosiov1alpha1.ThanosRuler{}
+		if err := r.Get(ctx, key, ruler); err != nil {
+			return monitoringthanosiov1alpha1.ObjectStorageConfig{}, fmt.Errorf("failed to get referenced %s %s: %w", ref.Kind, ref.Name, err)
+		}
+		return ruler.Spec.ObjectStorageConfig, nil
+	case "ThanosReceive":
+		receive := &monitoringthanosiov1alpha1.ThanosReceive{}
+		if err := r.Get(ctx, key, receive); err != nil {
+			return monitoringthanosiov1alpha1.ObjectStorageConfig{}, fmt.Errorf("failed to get referenced %s %s: %w", ref.Kind, ref.Name, err)
+		}
+		return receive.Spec.Ingester.DefaultObjectStorageConfig, nil
+	default:
+		return monitoringthanosiov1alpha1.ObjectStorageConfig{}, fmt.Errorf("unsupported object storage reference kind %s", ref.Kind)
+	}
+}
+
+// updateStatus reflects the state of the Job running the operation, and of the Job verifying the bucket
+// afterwards if any, in the status of the ThanosTools resource.
+func (r *ThanosToolsReconciler) updateStatus(ctx context.Context, tools monitoringthanosiov1alpha1.ThanosTools, job, verifyJob *batchv1.Job) error {
+	phase, message := jobPhase(job)
+	previous := tools.Status.Phase
+
+	tools.Status.JobName = job.GetName()
+	tools.Status.StartTime = job.Status.StartTime
+	tools.Status.CompletionTime = job.Status.CompletionTime
+
+	if verifyJob != nil {
+		verifyPhase, verifyMessage := jobPhase(verifyJob)
+		tools.Status.VerificationJobName = verifyJob.GetName()
+		tools.Status.CompletionTime = verifyJob.Status.CompletionTime
+
+		verified := metav1.ConditionFalse
+		switch verifyPhase {
+		case monitoringthanosiov1alpha1.ThanosToolsSucceeded:
+			verified = metav1.ConditionTrue
+			message = fmt.Sprintf("%s, bucket verified by Job %s", message, verifyJob.GetName())
+		case monitoringthanosiov1alpha1.ThanosToolsFailed:
+			phase = monitoringthanosiov1alpha1.ThanosToolsFailed
+			message = fmt.Sprintf("%s, but bucket verification failed: %s", message, verifyMessage)
+		default:
+			phase = monitoringthanosiov1alpha1.ThanosToolsVerifying
+			message = fmt.Sprintf("%s, verifying bucket: %s", message, verifyMessage)
+		}
+
+		meta.SetStatusCondition(&tools.Status.Conditions, metav1.Condition{
+			Type:               monitoringthanosiov1alpha1.ThanosToolsConditionVerified,
+			Status:             verified,
+			Reason:             string(verifyPhase),
+			Message:            verifyMessage,
+			ObservedGeneration: tools.GetGeneration(),
+		})
+	}
+	tools.Status.Phase = phase
+
+	completed := metav1.ConditionFalse
+	if phase == monitoringthanosiov1alpha1.ThanosToolsSucceeded || phase == monitoringthanosiov1alpha1.ThanosToolsFailed {
+		completed = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&tools.Status.Conditions, metav1.Condition{
+		Type:               monitoringthanosiov1alpha1.ThanosToolsConditionComplete,
+		Status:             completed,
+		Reason:             string(phase),
+		Message:            message,
+		ObservedGeneration: tools.GetGeneration(),
+	})
+
+	if err := r.Status().Update(ctx, &tools); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
+	if previous != phase {
+		switch phase {
+		case monitoringthanosiov1alpha1.ThanosToolsSucceeded:
+			r.recorder.Event(&tools, corev1.EventTypeNormal, "Succeeded", message)
+		case monitoringthanosiov1alpha1.ThanosToolsFailed:
+			r.recorder.Event(&tools, corev1.EventTypeWarning, "Failed", message)
+		}
+	}
+	return nil
+}
+
+// jobPhase returns the phase of the operation along with a human readable message based on the state of the Job.
+func jobPhase(job *batchv1.Job) (monitoringthanosiov1alpha1.ThanosToolsPhase, string) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return monitoringthanosiov1alpha1.ThanosToolsSucceeded, fmt.Sprintf("Job %s completed successfully", job.GetName())
+		case batchv1.JobFailed:
+			return monitoringthanosiov1alpha1.ThanosToolsFailed, fmt.Sprintf("Job %s failed: %s", job.GetName(), c.Message)
+		}
+	}
+
+	if job.Status.Active > 0 {
+		return monitoringthanosiov1alpha1.ThanosToolsRunning, fmt.Sprintf("Job %s is running", job.GetName())
+	}
+	return monitoringthanosiov1alpha1.ThanosToolsPending, fmt.Sprintf("Job %s is pending", job.GetName())
+}
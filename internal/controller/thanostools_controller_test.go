@@ -0,0 +1,279 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"slices"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/test/utils"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("ThanosTools Controller", Ordered, func() {
+	Context("When reconciling a resource", func() {
+		const (
+			ns           = "thanos-tools-test"
+			resourceName = "test-tools-resource"
+			compactName  = "test-tools-compact"
+		)
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: ns,
+		}
+
+		jobName := ToolsNameFromParent(resourceName)
+
+		BeforeAll(func() {
+			By("creating the namespace and objstore secret")
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: ns,
+				},
+			})).Should(Succeed())
+
+			Expect(k8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "thanos-objstore",
+					Namespace: ns,
+				},
+				StringData: map[string]string{
+					"thanos.yaml": `type: S3
+config:
+  bucket: test
+  endpoint: http://localhost:9000
+  access_key: Cheesecake
+  secret_key: supersecret
+  http_config:
+    insecure_skip_verify: false
+`,
+				},
+			})).Should(Succeed())
+
+			By("creating a compact resource to reference the objstore from")
+			Expect(k8sClient.Create(ctx, &monitoringthanosiov1alpha1.ThanosCompact{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      compactName,
+					Namespace: ns,
+				},
+				Spec: monitoringthanosiov1alpha1.ThanosCompactSpec{
+					StorageSize: "1Gi",
+					ObjectStorageConfig: monitoringthanosiov1alpha1.ObjectStorageConfig{
+						SecretKeySelector: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: "thanos-objstore",
+							},
+							Key: "thanos.yaml",
+						},
+					},
+					Paused: ptr.To(true),
+				},
+			})).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &monitoringthanosiov1alpha1.ThanosTools{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance ThanosTools")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should reconcile correctly", func() {
+			if os.Getenv("EXCLUDE_TOOLS") == skipValue {
+				Skip("Skipping ThanosTools controller tests")
+			}
+
+			resource := &monitoringthanosiov1alpha1.ThanosTools{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: ns,
+				},
+				Spec: monitoringthanosiov1alpha1.ThanosToolsSpec{
+					ObjectStorageRef: &monitoringthanosiov1alpha1.ObjectStorageReference{
+						Kind: "ThanosCompact",
+						Name: compactName,
+					},
+					Mark: &monitoringthanosiov1alpha1.MarkOperation{
+						Marker:   monitoringthanosiov1alpha1.NoCompactMarker,
+						BlockIDs: []string{"01JBS8GGM7BEZRPEAQ1J39DKQ4"},
+						Details:  "halts compaction",
+					},
+				},
+			}
+
+			By("rejecting a resource with more than one operation", func() {
+				invalid := resource.DeepCopy()
+				invalid.Spec.Retention = &monitoringthanosiov1alpha1.RetentionOperation{
+					Raw: ptr.To(monitoringthanosiov1alpha1.Duration("30d")),
+				}
+				Expect(k8sClient.Create(ctx, invalid)).ShouldNot(Succeed())
+			})
+
+			By("creating a job bound to the referenced objstore", func() {
+				Expect(k8sClient.Create(ctx, resource)).Should(Succeed())
+				EventuallyWithOffset(1, func() bool {
+					return utils.VerifyServiceAccountExists(k8sClient, jobName, ns)
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				EventuallyWithOffset(1, func() bool {
+					job := &batchv1.Job{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: jobName, Namespace: ns}, job); err != nil {
+						return false
+					}
+					c := job.Spec.Template.Spec.Containers[0]
+					return c.Env[0].ValueFrom.SecretKeyRef.Name == "thanos-objstore" &&
+						slices.Contains(c.Args, "--marker=no-compact-mark.json") &&
+						slices.Contains(c.Args, "--id=01JBS8GGM7BEZRPEAQ1J39DKQ4")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("reporting the job in the status", func() {
+				EventuallyWithOffset(1, func() bool {
+					tools := &monitoringthanosiov1alpha1.ThanosTools{}
+					if err := k8sClient.Get(ctx, typeNamespacedName, tools); err != nil {
+						return false
+					}
+					return tools.Status.JobName == jobName &&
+						tools.Status.Phase == monitoringthanosiov1alpha1.ThanosToolsPending
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("rejecting a change of the operation", func() {
+				invalid := &monitoringthanosiov1alpha1.ThanosTools{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, invalid)).Should(Succeed())
+				invalid.Spec.Mark = nil
+				invalid.Spec.Retention = &monitoringthanosiov1alpha1.RetentionOperation{
+					Raw: ptr.To(monitoringthanosiov1alpha1.Duration("30d")),
+				}
+				Expect(k8sClient.Update(ctx, invalid)).ShouldNot(Succeed())
+			})
+
+			By("reporting success once the job completes", func() {
+				job := &batchv1.Job{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: jobName, Namespace: ns}, job)).Should(Succeed())
+
+				now := metav1.Now()
+				job.Status.StartTime = &now
+				job.Status.CompletionTime = &now
+				job.Status.Succeeded = 1
+				job.Status.Conditions = []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobComplete,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: now,
+					},
+				}
+				Expect(k8sClient.Status().Update(ctx, job)).Should(Succeed())
+
+				EventuallyWithOffset(1, func() bool {
+					tools := &monitoringthanosiov1alpha1.ThanosTools{}
+					if err := k8sClient.Get(ctx, typeNamespacedName, tools); err != nil {
+						return false
+					}
+					return tools.Status.Phase == monitoringthanosiov1alpha1.ThanosToolsSucceeded &&
+						tools.Status.CompletionTime != nil &&
+						tools.Status.VerificationJobName == ""
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+			})
+
+			By("verifying the bucket after deleting blocks", func() {
+				deletion := &monitoringthanosiov1alpha1.ThanosTools{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-tools-deletion",
+						Namespace: ns,
+					},
+					Spec: monitoringthanosiov1alpha1.ThanosToolsSpec{
+						ObjectStorageRef: resource.Spec.ObjectStorageRef,
+						Mark: &monitoringthanosiov1alpha1.MarkOperation{
+							Marker:   monitoringthanosiov1alpha1.DeletionMarker,
+							BlockIDs: []string{"01JBS8GGM7BEZRPEAQ1J39DKQ4"},
+							Details:  "bad block",
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, deletion)).Should(Succeed())
+				deletionName := types.NamespacedName{Name: deletion.GetName(), Namespace: ns}
+
+				completeJob := func(name string) {
+					EventuallyWithOffset(1, func() error {
+						job := &batchv1.Job{}
+						if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, job); err != nil {
+							return err
+						}
+						now := metav1.Now()
+						job.Status.StartTime = &now
+						job.Status.CompletionTime = &now
+						job.Status.Succeeded = 1
+						job.Status.Conditions = []batchv1.JobCondition{
+							{
+								Type:               batchv1.JobComplete,
+								Status:             corev1.ConditionTrue,
+								LastTransitionTime: now,
+							},
+						}
+						return k8sClient.Status().Update(ctx, job)
+					}, time.Second*10, time.Second*2).Should(Succeed())
+				}
+
+				completeJob(ToolsNameFromParent(deletion.GetName()))
+				verifyJobName := ToolsVerificationNameFromParent(deletion.GetName())
+				EventuallyWithOffset(1, func() bool {
+					tools := &monitoringthanosiov1alpha1.ThanosTools{}
+					if err := k8sClient.Get(ctx, deletionName, tools); err != nil {
+						return false
+					}
+					job := &batchv1.Job{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: verifyJobName, Namespace: ns}, job); err != nil {
+						return false
+					}
+					return tools.Status.Phase == monitoringthanosiov1alpha1.ThanosToolsVerifying &&
+						tools.Status.VerificationJobName == verifyJobName &&
+						slices.Contains(job.Spec.Template.Spec.Containers[0].Args, "verify")
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				completeJob(verifyJobName)
+				EventuallyWithOffset(1, func() bool {
+					tools := &monitoringthanosiov1alpha1.ThanosTools{}
+					if err := k8sClient.Get(ctx, deletionName, tools); err != nil {
+						return false
+					}
+					return tools.Status.Phase == monitoringthanosiov1alpha1.ThanosToolsSucceeded &&
+						meta.IsStatusConditionTrue(tools.Status.Conditions, monitoringthanosiov1alpha1.ThanosToolsConditionVerified)
+				}, time.Second*10, time.Second*2).Should(BeTrue())
+
+				Expect(k8sClient.Delete(ctx, deletion)).Should(Succeed())
+			})
+		})
+	})
+})
@@ -1,31 +1,118 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+
 	"github.com/thanos-community/thanos-operator/api/v1alpha1"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestscompact "github.com/thanos-community/thanos-operator/internal/pkg/manifests/compact"
-	manifestquery "github.com/thanos-community/thanos-operator/internal/pkg/manifests/query"
-	manifestqueryfrontend "github.com/thanos-community/thanos-operator/internal/pkg/manifests/queryfrontend"
-	manifestreceive "github.com/thanos-community/thanos-operator/internal/pkg/manifests/receive"
-	manifestruler "github.com/thanos-community/thanos-operator/internal/pkg/manifests/ruler"
-	manifestsstore "github.com/thanos-community/thanos-operator/internal/pkg/manifests/store"
+	"github.com/thanos-community/thanos-operator/internal/pkg/schedule"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestscompact "github.com/thanos-community/thanos-operator/pkg/manifests/compact"
+	manifestsmemcached "github.com/thanos-community/thanos-operator/pkg/manifests/memcached"
+	manifestquery "github.com/thanos-community/thanos-operator/pkg/manifests/query"
+	manifestqueryfrontend "github.com/thanos-community/thanos-operator/pkg/manifests/queryfrontend"
+	manifestreceive "github.com/thanos-community/thanos-operator/pkg/manifests/receive"
+	manifestsredis "github.com/thanos-community/thanos-operator/pkg/manifests/redis"
+	manifestruler "github.com/thanos-community/thanos-operator/pkg/manifests/ruler"
+	manifestsstore "github.com/thanos-community/thanos-operator/pkg/manifests/store"
+	manifeststenant "github.com/thanos-community/thanos-operator/pkg/manifests/tenant"
+	manifeststools "github.com/thanos-community/thanos-operator/pkg/manifests/tools"
 
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// Defaults of the query limits of a ThanosQuery, matching the defaults of the CRD.
+const (
+	defaultQueryTimeout       v1alpha1.Duration = "15m"
+	defaultQueryLookbackDelta v1alpha1.Duration = "5m"
+	defaultQueryMaxConcurrent int32             = 20
+)
+
 func queryV1Alpha1ToOptions(in v1alpha1.ThanosQuery) manifestquery.Options {
 	labels := manifests.MergeLabels(in.GetLabels(), in.Spec.Labels)
 	opts := commonToOpts(&in, in.Spec.Replicas, labels, in.GetAnnotations(), in.Spec.CommonFields, in.Spec.FeatureGates, in.Spec.Additional)
+	opts.PodDisruptionConfig = podDisruptionConfigToOpts(in.Spec.PodDisruptionConfig, maxReplicas(in.Spec.Replicas, in.Spec.Autoscaling))
+	opts.Autoscaling = autoscalingConfigToOpts(in.Spec.Autoscaling)
+	opts.GRPCServerTLS = grpcServerTLSToOpts(in.Spec.GRPCServerTLS)
+	opts.GRPCServer = grpcServerToOpts(in.Spec.GRPCServer)
+	opts.GRPCClientTLS = grpcClientTLSToOpts(in.Spec.GRPCClientTLS)
+	opts.LogForwarding = logForwardingToOpts(in.Spec.LogForwarding)
 	return manifestquery.Options{
 		Options:       opts,
-		ReplicaLabels: in.Spec.ReplicaLabels,
-		Timeout:       "15m",
-		LookbackDelta: "5m",
-		MaxConcurrent: 20,
+		ReplicaLabels: queryReplicaLabels(in),
+		Timeout:       string(ptr.Deref(in.Spec.Timeout, defaultQueryTimeout)),
+		LookbackDelta: string(ptr.Deref(in.Spec.LookbackDelta, defaultQueryLookbackDelta)),
+		MaxConcurrent: int(ptr.Deref(in.Spec.MaxConcurrent, defaultQueryMaxConcurrent)),
+
+		CustomEndpoints: toManifestCustomEndpoints(in.Spec.CustomStoreEndpoints),
+
+		RequestLoggingConfig: toManifestRequestLoggingConfig(in.Spec.RequestLoggingConfig),
+	}
+}
+
+// queryEndpointGroupToOptions transforms an endpoint group of a v1alpha1.ThanosQuery to the build Options of its Querier.
+// The Querier of the group inherits the configuration of the Querier of the ThanosQuery.
+func queryEndpointGroupToOptions(in v1alpha1.ThanosQuery, group v1alpha1.EndpointGroup) manifestquery.Options {
+	opts := queryV1Alpha1ToOptions(in)
+	opts.Replicas = group.Replicas
+	opts.Autoscaling = nil
+	opts.EndpointGroup = group.Name
+	opts.StoreResponseTimeout = manifests.Duration(manifests.OptionalToString(group.ResponseTimeout))
+	opts.MaxConcurrentSelect = group.MaxConcurrentSelect
+	// custom endpoints are attached to the Querier of the ThanosQuery only
+	opts.CustomEndpoints = nil
+	return opts
+}
+
+// queryPoolToOptions transforms a query pool of a v1alpha1.ThanosQuery to the build Options of its Queriers.
+// The Queriers of the pool inherit the configuration of the Querier of the ThanosQuery.
+func queryPoolToOptions(in v1alpha1.ThanosQuery, pool v1alpha1.QueryPool) manifestquery.Options {
+	opts := queryV1Alpha1ToOptions(in)
+	opts.Replicas = pool.Replicas
+	opts.PodDisruptionConfig = podDisruptionConfigToOpts(in.Spec.PodDisruptionConfig, pool.Replicas)
+	opts.Autoscaling = nil
+	opts.Pool = pool.Name
+	if pool.Timeout != nil {
+		opts.Timeout = string(*pool.Timeout)
+	}
+	if pool.MaxConcurrent != nil {
+		opts.MaxConcurrent = int(*pool.MaxConcurrent)
 	}
+	return opts
+}
+
+// queryFrontendDownstreamOptions returns the build Options of the Querier the Query Frontend of a ThanosQuery forwards to,
+// which is the Querier of the ThanosQuery or of the query pool selected by the Query Frontend.
+func queryFrontendDownstreamOptions(in v1alpha1.ThanosQuery) manifestquery.Options {
+	if name := ptr.Deref(in.Spec.QueryFrontend.QueryPool, ""); name != "" {
+		for _, pool := range in.Spec.QueryPools {
+			if pool.Name == name {
+				return queryPoolToOptions(in, pool)
+			}
+		}
+	}
+	return queryV1Alpha1ToOptions(in)
+}
+
+// queryHTTPPort returns the port of the HTTP server of the Thanos Query component.
+func queryHTTPPort(in v1alpha1.ThanosQuery) int32 {
+	return manifests.Options{ListenPorts: listenPortsToOpts(in.Spec.ListenPorts)}.GetHTTPPort(manifestquery.HTTPPort)
+}
+
+// queryURL returns the in-cluster URL of the HTTP API of the Thanos Query component.
+func queryURL(in v1alpha1.ThanosQuery) string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", QueryNameFromParent(in.GetName()), in.GetNamespace(), queryHTTPPort(in))
 }
 
 // QueryNameFromParent returns the name of the Thanos Query component.
@@ -39,22 +126,84 @@ func queryV1Alpha1ToQueryFrontEndOptions(in v1alpha1.ThanosQuery) manifestqueryf
 
 	frontend := in.Spec.QueryFrontend
 	opts := commonToOpts(&in, frontend.Replicas, labels, in.GetAnnotations(), frontend.CommonFields, in.Spec.FeatureGates, frontend.Additional)
+	opts.PodDisruptionConfig = podDisruptionConfigToOpts(frontend.PodDisruptionConfig, maxReplicas(frontend.Replicas, frontend.Autoscaling))
+	opts.Autoscaling = autoscalingConfigToOpts(frontend.Autoscaling)
+	opts.LogForwarding = logForwardingToOpts(frontend.LogForwarding)
 
 	return manifestqueryfrontend.Options{
 		Options:                opts,
-		QueryService:           QueryNameFromParent(in.GetName()),
-		QueryPort:              manifestquery.HTTPPort,
+		QueryService:           queryFrontendDownstreamOptions(in).GetGeneratedResourceName(),
+		QueryPort:              queryHTTPPort(in),
+		DownstreamURL:          manifests.OptionalToString(frontend.DownstreamURL),
 		LogQueriesLongerThan:   manifests.Duration(manifests.OptionalToString(frontend.LogQueriesLongerThan)),
 		CompressResponses:      frontend.CompressResponses,
-		ResponseCacheConfig:    toManifestCacheConfig(frontend.QueryRangeResponseCacheConfig),
+		ResponseCacheConfig:    queryFrontendCacheConfig(in),
 		RangeSplitInterval:     manifests.Duration(manifests.OptionalToString(frontend.QueryRangeSplitInterval)),
 		LabelsSplitInterval:    manifests.Duration(manifests.OptionalToString(frontend.LabelsSplitInterval)),
 		RangeMaxRetries:        frontend.QueryRangeMaxRetries,
 		LabelsMaxRetries:       frontend.LabelsMaxRetries,
 		LabelsDefaultTimeRange: manifests.Duration(manifests.OptionalToString(frontend.LabelsDefaultTimeRange)),
+		QueryAffinity:          queryFrontendQueryAffinity(in),
 	}
 }
 
+// queryFrontendQueryAffinity returns the preferred pod affinity of the Query Frontend of a ThanosQuery towards its Queriers,
+// or nil if co-scheduling is not configured or the Query Frontend forwards to an external downstream.
+func queryFrontendQueryAffinity(in v1alpha1.ThanosQuery) *manifestqueryfrontend.QueryAffinity {
+	cs := in.Spec.QueryFrontend.CoScheduling
+	if cs == nil || hasExternalDownstream(in) {
+		return nil
+	}
+	affinity := &manifestqueryfrontend.QueryAffinity{
+		QuerySelector: queryFrontendDownstreamOptions(in).GetSelectorLabels(),
+		TopologyKey:   cs.TopologyKey,
+		Weight:        cs.Weight,
+	}
+	if affinity.TopologyKey == "" {
+		affinity.TopologyKey = corev1.LabelTopologyZone
+	}
+	if affinity.Weight == 0 {
+		affinity.Weight = 50
+	}
+	return affinity
+}
+
+// queryV1Alpha1ToGrafanaDatasourceOptions returns the Grafana datasource options for the ThanosQuery.
+// The datasource targets the Query Frontend if configured, otherwise the Querier.
+func queryV1Alpha1ToGrafanaDatasourceOptions(in v1alpha1.ThanosQuery) manifests.GrafanaDatasourceOptions {
+	spec := in.Spec.GrafanaDatasource
+	url := queryURL(in)
+	if in.Spec.QueryFrontend != nil {
+		port := manifests.Options{ListenPorts: listenPortsToOpts(in.Spec.QueryFrontend.ListenPorts)}.GetHTTPPort(manifestqueryfrontend.HTTPPort)
+		url = fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", QueryFrontendNameFromParent(in.GetName()), in.GetNamespace(), port)
+	}
+
+	name := in.GetName()
+	if spec.Name != nil {
+		name = *spec.Name
+	}
+
+	opts := manifests.GrafanaDatasourceOptions{
+		DatasourceName: name,
+		URL:            url,
+		IsDefault:      spec.IsDefault,
+	}
+	if spec.TimeInterval != nil {
+		opts.TimeInterval = ptr.To(manifests.Duration(*spec.TimeInterval))
+	}
+	return opts
+}
+
+// GrafanaDatasourceNameFromParent returns the name of the Grafana datasource ConfigMap for a ThanosQuery.
+func GrafanaDatasourceNameFromParent(resourceName string) string {
+	return manifests.ValidateAndSanitizeResourceName(fmt.Sprintf("%s-grafana-datasource", QueryNameFromParent(resourceName)))
+}
+
+// LastKnownGoodNameFromParent returns the name of the ConfigMap holding the last known good state of the workloads of a resource.
+func LastKnownGoodNameFromParent(resourceName string) string {
+	return manifests.ValidateAndSanitizeResourceName(fmt.Sprintf("%s-last-known-good", resourceName))
+}
+
 // QueryFrontendNameFromParent returns the name of the Thanos Query Frontend component.
 func QueryFrontendNameFromParent(resourceName string) string {
 	return manifestqueryfrontend.Options{Options: manifests.Options{Owner: resourceName}}.GetGeneratedResourceName()
@@ -63,15 +212,17 @@ func QueryFrontendNameFromParent(resourceName string) string {
 func rulerV1Alpha1ToOptions(in v1alpha1.ThanosRuler) manifestruler.Options {
 	labels := manifests.MergeLabels(in.GetLabels(), in.Spec.Labels)
 	opts := commonToOpts(&in, in.Spec.Replicas, labels, in.GetAnnotations(), in.Spec.CommonFields, in.Spec.FeatureGates, in.Spec.Additional)
+	opts.ObjStoreTokenProjection = toManifestTokenProjection(in.Spec.ObjectStorageConfig.WorkloadIdentity)
 	return manifestruler.Options{
-		Options:            opts,
-		ObjStoreSecret:     in.Spec.ObjectStorageConfig.ToSecretKeySelector(),
-		Retention:          manifests.Duration(in.Spec.Retention),
-		AlertmanagerURL:    in.Spec.AlertmanagerURL,
-		ExternalLabels:     in.Spec.ExternalLabels,
-		AlertLabelDrop:     in.Spec.AlertLabelDrop,
-		StorageSize:        resource.MustParse(in.Spec.StorageSize),
-		EvaluationInterval: manifests.Duration(in.Spec.EvaluationInterval),
+		Options:               opts,
+		ObjStoreSecret:        in.Spec.ObjectStorageConfig.ToSecretKeySelector(),
+		Retention:             manifests.Duration(in.Spec.Retention),
+		AlertmanagerURL:       in.Spec.AlertmanagerURL,
+		ExternalLabels:        in.Spec.ExternalLabels,
+		AlertLabelDrop:        in.Spec.AlertLabelDrop,
+		StorageSize:           resource.MustParse(in.Spec.StorageSize),
+		EvaluationInterval:    manifests.Duration(in.Spec.EvaluationInterval),
+		StoreAPIServiceLabels: in.Spec.StoreAPIServiceLabels,
 	}
 }
 
@@ -84,20 +235,29 @@ func receiverV1Alpha1ToIngesterOptions(in v1alpha1.ThanosReceive, spec v1alpha1.
 	labels := manifests.MergeLabels(in.GetLabels(), spec.Labels)
 	common := spec.CommonFields
 	additional := in.Spec.Ingester.Additional
-	secret := in.Spec.Ingester.DefaultObjectStorageConfig.ToSecretKeySelector()
+	objStore := in.Spec.Ingester.DefaultObjectStorageConfig
 	if spec.ObjectStorageConfig != nil {
-		secret = spec.ObjectStorageConfig.ToSecretKeySelector()
+		objStore = *spec.ObjectStorageConfig
 	}
 
 	opts := commonToOpts(&in, spec.Replicas, labels, in.GetAnnotations(), common, in.Spec.FeatureGates, additional)
+	opts.ObjStoreTokenProjection = toManifestTokenProjection(objStore.WorkloadIdentity)
+	opts.GRPCServer = grpcServerToOpts(in.Spec.Ingester.GRPCServer)
+	// voluntary disruptions of the ingesters must never break write quorum
+	if opts.PodDisruptionConfig != nil {
+		opts.PodDisruptionConfig.MaxUnavailable = ptr.To(manifestreceive.QuorumMaxUnavailable(in.Spec.Router.ReplicationFactor))
+	}
 	return manifestreceive.IngesterOptions{
 		Options:        opts,
-		ObjStoreSecret: secret,
+		ObjStoreSecret: objStore.ToSecretKeySelector(),
 		TSDBOpts: manifestreceive.TSDBOpts{
 			Retention: string(spec.TSDBConfig.Retention),
 		},
-		StorageSize:    resource.MustParse(string(spec.StorageSize)),
-		ExternalLabels: spec.ExternalLabels,
+		StorageSize:           resource.MustParse(string(spec.StorageSize)),
+		ExternalLabels:        spec.ExternalLabels,
+		StoreAPIServiceLabels: spec.StoreAPIServiceLabels,
+		EndpointType:          toManifestEndpointType(spec.EndpointType),
+		Backup:                toManifestBackupOptions(in.Spec.Ingester.Backup),
 	}
 }
 
@@ -113,6 +273,53 @@ func receiverV1Alpha1ToRouterOptions(in v1alpha1.ThanosReceive) manifestreceive.
 	}
 }
 
+// storeObjectStorageSecret returns the Secret holding the object storage configuration of the ThanosStore,
+// which is the managed Secret for an inline configuration, and the workload identity of the configuration.
+func storeObjectStorageSecret(in v1alpha1.ThanosStore) (corev1.SecretKeySelector, *v1alpha1.WorkloadIdentity) {
+	if in.Spec.ObjectStorage != nil {
+		return corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: StoreObjectStorageNameFromParent(in.GetName())},
+			Key:                  manifests.ObjectStorageConfigKey,
+		}, in.Spec.ObjectStorage.WorkloadIdentity
+	}
+	if in.Spec.ObjectStorageConfig == nil {
+		return corev1.SecretKeySelector{}, nil
+	}
+	return in.Spec.ObjectStorageConfig.ToSecretKeySelector(), in.Spec.ObjectStorageConfig.WorkloadIdentity
+}
+
+// objectStorageToOpts returns the ObjectStorageOptions rendering an inline object storage configuration.
+func objectStorageToOpts(in v1alpha1.ObjectStorage) manifests.ObjectStorageOptions {
+	var opts manifests.ObjectStorageOptions
+	if in.S3 != nil {
+		opts.S3 = &manifests.S3Options{
+			Bucket:    in.S3.Bucket,
+			Endpoint:  in.S3.Endpoint,
+			Region:    in.S3.Region,
+			Insecure:  in.S3.Insecure,
+			AccessKey: in.S3.AccessKey,
+			SecretKey: in.S3.SecretKey,
+		}
+	}
+	if in.GCS != nil {
+		opts.GCS = &manifests.GCSOptions{Bucket: in.GCS.Bucket}
+	}
+	if in.Azure != nil {
+		opts.Azure = &manifests.AzureOptions{
+			StorageAccount:    in.Azure.StorageAccount,
+			Container:         in.Azure.Container,
+			Endpoint:          in.Azure.Endpoint,
+			StorageAccountKey: in.Azure.StorageAccountKey,
+		}
+	}
+	return opts
+}
+
+// StoreObjectStorageNameFromParent returns the name of the Secret holding the inline object storage configuration of a ThanosStore.
+func StoreObjectStorageNameFromParent(resourceName string) string {
+	return manifests.ValidateAndSanitizeResourceName(fmt.Sprintf("%s-objstore", StoreNameFromParent(resourceName, nil)))
+}
+
 // ReceiveIngesterNameFromParent returns the name of the Thanos Receive Ingester component.
 func ReceiveIngesterNameFromParent(resourceName, hashringName string) string {
 	return manifestreceive.IngesterOptions{Options: manifests.Options{Owner: resourceName}, HashringName: hashringName}.GetGeneratedResourceName()
@@ -126,28 +333,216 @@ func ReceiveRouterNameFromParent(resourceName string) string {
 func storeV1Alpha1ToOptions(in v1alpha1.ThanosStore) manifestsstore.Options {
 	labels := manifests.MergeLabels(in.GetLabels(), in.Spec.Labels)
 	opts := commonToOpts(&in, in.Spec.ShardingStrategy.ShardReplicas, labels, in.GetAnnotations(), in.Spec.CommonFields, in.Spec.FeatureGates, in.Spec.Additional)
+	opts.PodDisruptionConfig = podDisruptionConfigToOpts(in.Spec.PodDisruptionConfig, in.Spec.ShardingStrategy.ShardReplicas)
+	objStoreSecret, workloadIdentity := storeObjectStorageSecret(in)
+	opts.ObjStoreTokenProjection = toManifestTokenProjection(workloadIdentity)
+	if in.Spec.ObjectStorage != nil {
+		opts.Additional.Env = slices.Concat(objectStorageToOpts(*in.Spec.ObjectStorage).EnvVars(), opts.Additional.Env)
+	}
+	opts.GRPCServerTLS = grpcServerTLSToOpts(in.Spec.GRPCServerTLS)
+	opts.GRPCServer = grpcServerToOpts(in.Spec.GRPCServer)
 	return manifestsstore.Options{
-		ObjStoreSecret:           in.Spec.ObjectStorageConfig.ToSecretKeySelector(),
-		IndexCacheConfig:         toManifestCacheConfig(in.Spec.IndexCacheConfig),
-		CachingBucketConfig:      toManifestCacheConfig(in.Spec.CachingBucketConfig),
-		Min:                      manifests.Duration(manifests.OptionalToString(in.Spec.MinTime)),
-		Max:                      manifests.Duration(manifests.OptionalToString(in.Spec.MaxTime)),
-		IgnoreDeletionMarksDelay: manifests.Duration(in.Spec.IgnoreDeletionMarksDelay),
-		StorageSize:              resource.MustParse(string(in.Spec.StorageSize)),
-		Options:                  opts,
+		ObjStoreSecret:             objStoreSecret,
+		IndexCacheConfig:           storeCacheConfig(in, storeIndexCacheName, in.Spec.IndexCacheConfig),
+		CachingBucketConfig:        storeCacheConfig(in, storeCachingBucketName, in.Spec.CachingBucketConfig),
+		Min:                        manifests.Duration(manifests.OptionalToString(in.Spec.MinTime)),
+		Max:                        manifests.Duration(manifests.OptionalToString(in.Spec.MaxTime)),
+		IgnoreDeletionMarksDelay:   manifests.Duration(in.Spec.IgnoreDeletionMarksDelay),
+		StorageSize:                resource.MustParse(string(in.Spec.StorageSize)),
+		RequestLoggingConfig:       toManifestRequestLoggingConfig(in.Spec.RequestLoggingConfig),
+		StoreAPIServiceLabels:      in.Spec.StoreAPIServiceLabels,
+		EndpointType:               toManifestEndpointType(in.Spec.EndpointType),
+		Backup:                     toManifestBackupOptions(in.Spec.Backup),
+		StorageClassName:           in.Spec.StorageClassName,
+		VolumeClaimLabels:          in.Spec.VolumeClaimLabels,
+		VolumeClaimAnnotations:     in.Spec.VolumeClaimAnnotations,
+		VolumeClaimRetentionPolicy: in.Spec.PersistentVolumeClaimRetentionPolicy,
+		Options:                    opts,
+	}
+}
+
+// Names of the caches of a ThanosStore, distinguishing the Redis and Memcached instances managed for them.
+const (
+	storeIndexCacheName    = "index-cache"
+	storeCachingBucketName = "caching-bucket"
+)
+
+// storeCacheConfig returns the cache config of the named cache of the ThanosStore,
+// pointing a cache served by a managed Redis or Memcached to its Service.
+func storeCacheConfig(in v1alpha1.ThanosStore, name string, config *v1alpha1.CacheConfig) manifests.CacheConfig {
+	out := toManifestCacheConfig(config)
+	if managed := managedRedis(config); managed != nil {
+		out.Redis.Addresses = []string{managedRedisToOptions(&in, name, managed).GetAddress()}
+	}
+	if managed := managedMemcached(config); managed != nil {
+		opts := managedMemcachedToOptions(&in, name, managed)
+		out.Memcached = &manifests.MemcachedCacheConfig{
+			Addresses:   []string{opts.GetAddress()},
+			MaxItemSize: opts.MaxItemSize,
+		}
+	}
+	return out
+}
+
+// storeManagedRedisOptions returns the options of the Redis instances managed for the caches of the ThanosStore.
+// Tiers share the managed Redis instances of the ThanosStore.
+func storeManagedRedisOptions(in v1alpha1.ThanosStore) []manifestsredis.Options {
+	var opts []manifestsredis.Options
+	for name, config := range map[string]*v1alpha1.CacheConfig{
+		storeIndexCacheName:    in.Spec.IndexCacheConfig,
+		storeCachingBucketName: in.Spec.CachingBucketConfig,
+	} {
+		if managed := managedRedis(config); managed != nil {
+			opts = append(opts, managedRedisToOptions(&in, name, managed))
+		}
+	}
+	sort.Slice(opts, func(i, j int) bool { return opts[i].Cache < opts[j].Cache })
+	return opts
+}
+
+// storeManagedMemcachedOptions returns the options of the Memcached instances managed for the caches of the ThanosStore.
+// Tiers share the managed Memcached instances of the ThanosStore.
+func storeManagedMemcachedOptions(in v1alpha1.ThanosStore) []manifestsmemcached.Options {
+	var opts []manifestsmemcached.Options
+	for name, config := range map[string]*v1alpha1.CacheConfig{
+		storeIndexCacheName:    in.Spec.IndexCacheConfig,
+		storeCachingBucketName: in.Spec.CachingBucketConfig,
+	} {
+		if managed := managedMemcached(config); managed != nil {
+			opts = append(opts, managedMemcachedToOptions(&in, name, managed))
+		}
+	}
+	sort.Slice(opts, func(i, j int) bool { return opts[i].Cache < opts[j].Cache })
+	return opts
+}
+
+// managedRedis returns the configuration of the managed Redis serving the cache,
+// or nil if the cache is not served by a managed Redis.
+func managedRedis(config *v1alpha1.CacheConfig) *v1alpha1.ManagedRedisConfig {
+	if config == nil || config.ExternalCacheConfig != nil || config.RedisCacheConfig == nil {
+		return nil
+	}
+	return config.RedisCacheConfig.Managed
+}
+
+func managedRedisToOptions(in client.Object, cache string, managed *v1alpha1.ManagedRedisConfig) manifestsredis.Options {
+	opts := manifestsredis.Options{
+		Owner:     in.GetName(),
+		Cache:     cache,
+		Namespace: in.GetNamespace(),
+		Labels:    in.GetLabels(),
+		Image:     ptr.Deref(managed.Image, ""),
+		Resources: managed.Resources,
+	}
+	if managed.MaxMemory != nil {
+		maxMemory := managed.MaxMemory.ToResourceQuantity()
+		opts.MaxMemory = maxMemory.Value()
+	}
+	return opts
+}
+
+// queryFrontendResponseCacheName is the name of the response cache of the Query Frontend of a ThanosQuery,
+// distinguishing the Memcached managed for it.
+const queryFrontendResponseCacheName = "response-cache"
+
+// queryFrontendCacheConfig returns the response cache config of the Query Frontend of the ThanosQuery,
+// pointing a cache served by a managed Memcached to the Service of the Memcached.
+func queryFrontendCacheConfig(in v1alpha1.ThanosQuery) manifests.CacheConfig {
+	config := in.Spec.QueryFrontend.QueryRangeResponseCacheConfig
+	out := toManifestCacheConfig(config)
+	if managed := managedMemcached(config); managed != nil {
+		opts := managedMemcachedToOptions(&in, queryFrontendResponseCacheName, managed)
+		out.Memcached = &manifests.MemcachedCacheConfig{
+			Addresses:   []string{opts.GetAddress()},
+			MaxItemSize: opts.MaxItemSize,
+		}
+	}
+	return out
+}
+
+// queryManagedMemcachedOptions returns the options of the Memcached instances managed for the caches of the ThanosQuery.
+func queryManagedMemcachedOptions(in v1alpha1.ThanosQuery) []manifestsmemcached.Options {
+	if in.Spec.QueryFrontend == nil {
+		return nil
+	}
+	managed := managedMemcached(in.Spec.QueryFrontend.QueryRangeResponseCacheConfig)
+	if managed == nil {
+		return nil
+	}
+	return []manifestsmemcached.Options{managedMemcachedToOptions(&in, queryFrontendResponseCacheName, managed)}
+}
+
+// managedMemcached returns the configuration of the managed Memcached serving the cache,
+// or nil if the cache is not served by a managed Memcached.
+func managedMemcached(config *v1alpha1.CacheConfig) *v1alpha1.ManagedMemcachedConfig {
+	if config == nil || config.ExternalCacheConfig != nil || config.RedisCacheConfig != nil || config.MemcachedCacheConfig == nil {
+		return nil
+	}
+	return config.MemcachedCacheConfig.Managed
+}
+
+func managedMemcachedToOptions(in client.Object, cache string, managed *v1alpha1.ManagedMemcachedConfig) manifestsmemcached.Options {
+	opts := manifestsmemcached.Options{
+		Owner:     in.GetName(),
+		Cache:     cache,
+		Namespace: in.GetNamespace(),
+		Labels:    in.GetLabels(),
+		Image:     ptr.Deref(managed.Image, ""),
+		Replicas:  ptr.Deref(managed.Replicas, 1),
+		Resources: managed.Resources,
+	}
+	if managed.MemoryLimit != nil {
+		memoryLimit := managed.MemoryLimit.ToResourceQuantity()
+		opts.MemoryLimit = memoryLimit.Value()
+	}
+	if managed.MaxItemSize != nil {
+		maxItemSize := managed.MaxItemSize.ToResourceQuantity()
+		opts.MaxItemSize = maxItemSize.Value()
+	}
+	return opts
+}
+
+// storeTierV1Alpha1ToOptions returns the options for a single tier of a ThanosStore.
+// Fields that are not set on the tier are inherited from the ThanosStore spec.
+func storeTierV1Alpha1ToOptions(in v1alpha1.ThanosStore, tier v1alpha1.StoreTier) manifestsstore.Options {
+	opts := storeV1Alpha1ToOptions(in)
+	opts.Tier = tier.Name
+	opts.Min = manifests.Duration(manifests.OptionalToString(tier.MinTime))
+	opts.Max = manifests.Duration(manifests.OptionalToString(tier.MaxTime))
+
+	if tier.Resources != nil {
+		opts.ResourceRequirements = tier.Resources
+	}
+	if tier.StorageSize != nil {
+		opts.StorageSize = tier.StorageSize.ToResourceQuantity()
+	}
+	if tier.StorageClassName != nil {
+		opts.StorageClassName = tier.StorageClassName
+	}
+	if tier.IndexCacheConfig != nil {
+		opts.IndexCacheConfig = toManifestCacheConfig(tier.IndexCacheConfig)
+	}
+	if tier.CachingBucketConfig != nil {
+		opts.CachingBucketConfig = toManifestCacheConfig(tier.CachingBucketConfig)
+	}
+	if tier.StoreAPIServiceLabels != nil {
+		opts.StoreAPIServiceLabels = manifests.MergeLabels(opts.StoreAPIServiceLabels, tier.StoreAPIServiceLabels)
 	}
+	return opts
 }
 
 func compactV1Alpha1ToOptions(in v1alpha1.ThanosCompact) manifestscompact.Options {
 	labels := manifests.MergeLabels(in.GetLabels(), in.Spec.Labels)
 	opts := commonToOpts(&in, 1, labels, in.GetAnnotations(), in.Spec.CommonFields, in.Spec.FeatureGates, in.Spec.Additional)
+	opts.ObjStoreTokenProjection = toManifestTokenProjection(in.Spec.ObjectStorageConfig.WorkloadIdentity)
 
 	downsamplingConfig := func() *manifestscompact.DownsamplingOptions {
 		if in.Spec.DownsamplingConfig == nil {
 			return nil
 		}
 
-		disable := in.Spec.DownsamplingConfig.Disable != nil && *in.Spec.DownsamplingConfig.Disable
+		// the blocks are downsampled by the worker instead, if any
+		disable := ptr.Deref(in.Spec.DownsamplingConfig.Disable, false) || in.Spec.DownsamplingConfig.Worker != nil
 
 		return &manifestscompact.DownsamplingOptions{
 			Disable:     disable,
@@ -188,21 +583,338 @@ func compactV1Alpha1ToOptions(in v1alpha1.ThanosCompact) manifestscompact.Option
 		BlockConfig:    blockDiscovery(),
 		Compaction:     compaction(),
 		Downsampling:   downsamplingConfig(),
+		Min:            optionalDuration(in.Spec.MinTime),
+		Max:            optionalDuration(in.Spec.MaxTime),
 		StorageSize:    in.Spec.StorageSize.ToResourceQuantity(),
 		ObjStoreSecret: in.Spec.ObjectStorageConfig.ToSecretKeySelector(),
 	}
 }
 
+// compactV1Alpha1ToDownsamplerOptions returns the options for the downsampling worker of the ThanosCompact,
+// or nil if the blocks are downsampled by the Compactors.
+func compactV1Alpha1ToDownsamplerOptions(in v1alpha1.ThanosCompact) *manifestscompact.Options {
+	if in.Spec.DownsamplingConfig == nil || in.Spec.DownsamplingConfig.Worker == nil {
+		return nil
+	}
+	worker := in.Spec.DownsamplingConfig.Worker
+	opts := compactV1Alpha1ToOptions(in)
+	opts.Downsampler = true
+	opts.Downsampling = &manifestscompact.DownsamplingOptions{Concurrency: in.Spec.DownsamplingConfig.Concurrency}
+	if worker.ResourceRequirements != nil {
+		opts.ResourceRequirements = worker.ResourceRequirements
+		// the container resources of the Compactors would otherwise take precedence
+		opts.ContainerResources = maps.Clone(opts.ContainerResources)
+		delete(opts.ContainerResources, manifestscompact.Name)
+	}
+	if worker.StorageSize != nil {
+		opts.StorageSize = worker.StorageSize.ToResourceQuantity()
+	}
+	return &opts
+}
+
+// compactV1Alpha1ToShardOptions returns the options for each shard of the ThanosCompact.
+// If no sharding is configured, a single set of options is returned.
+func compactV1Alpha1ToShardOptions(in v1alpha1.ThanosCompact) []manifestscompact.Options {
+	if in.Spec.ShardingConfig == nil || in.Spec.ShardingConfig.ExternalLabelSharding == nil {
+		return []manifestscompact.Options{compactV1Alpha1ToOptions(in)}
+	}
+
+	var shards []manifestscompact.Options
+	for _, shard := range in.Spec.ShardingConfig.ExternalLabelSharding {
+		for i, v := range shard.Values {
+			opts := compactV1Alpha1ToOptions(in)
+			opts.ShardName = ptr.To(shard.ShardName)
+			opts.ShardIndex = ptr.To(i)
+			opts.RelabelConfigs = manifests.RelabelConfigs{
+				{
+					Action:      "keep",
+					SourceLabel: shard.Label,
+					Regex:       v,
+				},
+			}
+			shards = append(shards, opts)
+		}
+	}
+	return shards
+}
+
+// compactScheduleToWindows returns the windows of the compaction schedule and the location they are evaluated in.
+func compactScheduleToWindows(in v1alpha1.CompactSchedule) ([]schedule.Window, *time.Location, error) {
+	loc := time.UTC
+	if in.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(in.TimeZone); err != nil {
+			return nil, nil, fmt.Errorf("invalid time zone %q: %w", in.TimeZone, err)
+		}
+	}
+
+	windows := make([]schedule.Window, 0, len(in.Windows))
+	for _, w := range in.Windows {
+		start, err := schedule.ParseCron(w.Start)
+		if err != nil {
+			return nil, nil, err
+		}
+		duration, err := model.ParseDuration(string(w.Duration))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid window duration %q: %w", w.Duration, err)
+		}
+		windows = append(windows, schedule.Window{Start: start, Duration: time.Duration(duration)})
+	}
+	return windows, loc, nil
+}
+
+// compactBlockMarkerToToolsOptions returns the options for the Job applying a block marker
+// using the object storage configuration of the ThanosCompact.
+func compactBlockMarkerToToolsOptions(in v1alpha1.ThanosCompact, marker v1alpha1.BlockMarker) manifeststools.Options {
+	labels := manifests.MergeLabels(in.GetLabels(), in.Spec.Labels)
+	opts := commonToOpts(&in, 1, labels, in.GetAnnotations(), in.Spec.CommonFields, nil, v1alpha1.Additional{})
+	opts.ObjStoreTokenProjection = toManifestTokenProjection(in.Spec.ObjectStorageConfig.WorkloadIdentity)
+	return manifeststools.Options{
+		Options:        opts,
+		NamePrefix:     manifestscompact.Name,
+		OperationName:  blockMarkerOperationName(marker.Name),
+		ObjStoreSecret: in.Spec.ObjectStorageConfig.ToSecretKeySelector(),
+		BackoffLimit:   ptr.To(int32(0)),
+		Mark: &manifeststools.MarkOptions{
+			Marker:   string(marker.Marker),
+			BlockIDs: marker.BlockIDs,
+			Details:  marker.Details,
+		},
+	}
+}
+
 // CompactNameFromParent returns the name of the Thanos Compact component.
 func CompactNameFromParent(resourceName string) string {
 	return manifestscompact.Options{Options: manifests.Options{Owner: resourceName}}.GetGeneratedResourceName()
 }
 
+// blockMarkerOperationName returns the operation name of the Job applying the given block marker.
+func blockMarkerOperationName(marker string) string {
+	return "mark-" + marker
+}
+
+// BlockMarkerJobNameFromParent returns the name of the Job applying the given block marker of a Thanos Compact.
+func BlockMarkerJobNameFromParent(resourceName, marker string) string {
+	return manifeststools.Options{
+		Options:       manifests.Options{Owner: resourceName},
+		NamePrefix:    manifestscompact.Name,
+		OperationName: blockMarkerOperationName(marker),
+	}.GetGeneratedResourceName()
+}
+
 // StoreNameFromParent returns the name of the Thanos Store component.
 func StoreNameFromParent(resourceName string, index *int32) string {
 	return manifestsstore.Options{Options: manifests.Options{Owner: resourceName}, ShardIndex: index}.GetGeneratedResourceName()
 }
 
+// StoreTierNameFromParent returns the name of the Thanos Store component for a given tier.
+func StoreTierNameFromParent(resourceName, tier string, index *int32) string {
+	return manifestsstore.Options{Options: manifests.Options{Owner: resourceName}, Tier: tier, ShardIndex: index}.GetGeneratedResourceName()
+}
+
+// toolsV1Alpha1ToOptions transforms a v1alpha1.ThanosTools to build Options.
+// The object storage configuration is resolved by the caller since it may be sourced from another resource.
+func toolsV1Alpha1ToOptions(in v1alpha1.ThanosTools, objStore v1alpha1.ObjectStorageConfig) manifeststools.Options {
+	labels := manifests.MergeLabels(in.GetLabels(), in.Spec.Labels)
+	opts := commonToOpts(&in, 1, labels, in.GetAnnotations(), in.Spec.CommonFields, nil, in.Spec.Additional)
+	opts.ObjStoreTokenProjection = toManifestTokenProjection(objStore.WorkloadIdentity)
+
+	mark := func() *manifeststools.MarkOptions {
+		if in.Spec.Mark == nil {
+			return nil
+		}
+		return &manifeststools.MarkOptions{
+			Marker:   string(in.Spec.Mark.Marker),
+			BlockIDs: in.Spec.Mark.BlockIDs,
+			Details:  in.Spec.Mark.Details,
+			Remove:   in.Spec.Mark.Remove,
+		}
+	}
+
+	rewrite := func() *manifeststools.RewriteOptions {
+		if in.Spec.Rewrite == nil {
+			return nil
+		}
+		return &manifeststools.RewriteOptions{
+			BlockIDs:           in.Spec.Rewrite.BlockIDs,
+			DeleteMatchers:     in.Spec.Rewrite.DeleteMatchers,
+			DryRun:             in.Spec.Rewrite.DryRun == nil || *in.Spec.Rewrite.DryRun,
+			DeleteSourceBlocks: in.Spec.Rewrite.DeleteSourceBlocks,
+		}
+	}
+
+	retention := func() *manifeststools.RetentionOptions {
+		if in.Spec.Retention == nil {
+			return nil
+		}
+		toDuration := func(d *v1alpha1.Duration) *manifests.Duration {
+			if d == nil {
+				return nil
+			}
+			return ptr.To(manifests.Duration(*d))
+		}
+		return &manifeststools.RetentionOptions{
+			Raw:         toDuration(in.Spec.Retention.Raw),
+			FiveMinutes: toDuration(in.Spec.Retention.FiveMinutes),
+			OneHour:     toDuration(in.Spec.Retention.OneHour),
+		}
+	}
+
+	return manifeststools.Options{
+		Options:        opts,
+		ObjStoreSecret: objStore.ToSecretKeySelector(),
+		BackoffLimit:   in.Spec.BackoffLimit,
+		Mark:           mark(),
+		Rewrite:        rewrite(),
+		Retention:      retention(),
+	}
+}
+
+// toolsVerifyNamePrefix is the prefix of the name of the Job verifying the bucket after a Thanos Tools operation.
+// The names of the Jobs of Thanos Tools operations start with manifeststools.Name, so they do not collide.
+const toolsVerifyNamePrefix = "thanos-verify"
+
+// toolsV1Alpha1ToVerifyOptions returns the options of the Job verifying the bucket after the operation.
+func toolsV1Alpha1ToVerifyOptions(in v1alpha1.ThanosTools, objStore v1alpha1.ObjectStorageConfig) manifeststools.Options {
+	opts := toolsV1Alpha1ToOptions(in, objStore)
+	opts.NamePrefix = toolsVerifyNamePrefix
+	opts.Mark, opts.Rewrite, opts.Retention = nil, nil, nil
+	opts.Verify = true
+	return opts
+}
+
+// ToolsNameFromParent returns the name of the Thanos Tools Job.
+func ToolsNameFromParent(resourceName string) string {
+	return manifeststools.Options{Options: manifests.Options{Owner: resourceName}}.GetGeneratedResourceName()
+}
+
+// ToolsVerificationNameFromParent returns the name of the Job verifying the bucket after the Thanos Tools operation.
+func ToolsVerificationNameFromParent(resourceName string) string {
+	return manifeststools.Options{Options: manifests.Options{Owner: resourceName}, NamePrefix: toolsVerifyNamePrefix}.GetGeneratedResourceName()
+}
+
+func tenantV1Alpha1ToOptions(in v1alpha1.ThanosTenant) manifeststenant.Options {
+	opts := manifeststenant.Options{
+		Options: manifests.Options{
+			Owner:       in.GetName(),
+			Namespace:   in.GetNamespace(),
+			Labels:      in.GetLabels(),
+			Annotations: in.GetAnnotations(),
+		},
+		TenantID:        in.GetTenantID(),
+		RouterService:   ReceiveRouterNameFromParent(in.Spec.ReceiveName),
+		TargetNamespace: in.Spec.TargetNamespace,
+	}
+
+	if ing := in.Spec.Ingress; ing != nil {
+		opts.Ingress = &manifeststenant.IngressOptions{
+			Host:             ing.Host,
+			Path:             ing.Path,
+			IngressClassName: ing.IngressClassName,
+			TLSSecretName:    ing.TLSSecretName,
+			Annotations:      ing.Annotations,
+		}
+		if ing.BasicAuthSecret != nil {
+			opts.Ingress.BasicAuthSecret = ing.BasicAuthSecret.Name
+		}
+	}
+	return opts
+}
+
+// receiveLimitsV1Alpha1ToLimitsConfig returns the router limits for the given receiver and tenants.
+// The limits of a ThanosTenant take precedence over the limits of the same tenant in the receiver spec.
+// Tenants without limits are skipped.
+func receiveLimitsV1Alpha1ToLimitsConfig(receiver v1alpha1.ThanosReceive, tenants []v1alpha1.ThanosTenant) manifestreceive.LimitsConfig {
+	conf := manifestreceive.LimitsConfig{Tenants: make(map[string]manifestreceive.TenantWriteLimits)}
+	if in := receiver.Spec.Router.Limits; in != nil {
+		conf.MaxConcurrency = in.MaxConcurrency
+		conf.MetaMonitoringURL = ptr.Deref(in.MetaMonitoringURL, "")
+		conf.MetaMonitoringLimitQuery = ptr.Deref(in.MetaMonitoringLimitQuery, "")
+		if in.DefaultLimits != nil {
+			conf.Default = ptr.To(tenantLimitsV1Alpha1ToLimits(*in.DefaultLimits))
+		}
+		for _, t := range in.Tenants {
+			conf.Tenants[t.Tenant] = tenantLimitsV1Alpha1ToLimits(t.Limits)
+		}
+	}
+	for _, t := range tenants {
+		if t.Spec.Limits == nil {
+			continue
+		}
+		conf.Tenants[t.GetTenantID()] = tenantLimitsV1Alpha1ToLimits(*t.Spec.Limits)
+	}
+	return conf
+}
+
+func tenantLimitsV1Alpha1ToLimits(in v1alpha1.TenantLimits) manifestreceive.TenantWriteLimits {
+	limits := manifestreceive.TenantWriteLimits{HeadSeriesLimit: in.ActiveSeriesLimit}
+	if in.SizeBytesLimit != nil || in.SeriesLimit != nil || in.SamplesLimit != nil {
+		limits.Request = &manifestreceive.TenantLimits{
+			SizeBytesLimit: in.SizeBytesLimit,
+			SeriesLimit:    in.SeriesLimit,
+			SamplesLimit:   in.SamplesLimit,
+		}
+	}
+	return limits
+}
+
+// TenantNameFromParent returns the name of the Thanos Tenant resources.
+func TenantNameFromParent(resourceName string) string {
+	return manifeststenant.Options{Options: manifests.Options{Owner: resourceName}}.GetGeneratedResourceName()
+}
+
+// Component names of the version policy.
+const (
+	versionPolicyQuery         = "query"
+	versionPolicyQueryFrontend = "query-frontend"
+	versionPolicyStore         = "store"
+	versionPolicyReceive       = "receive"
+	versionPolicyRuler         = "ruler"
+	versionPolicyCompact       = "compact"
+)
+
+// thanosVersion returns the Thanos version deployed for the given common fields.
+func thanosVersion(common v1alpha1.CommonFields) string {
+	return manifests.Options{Version: common.Version}.GetVersion()
+}
+
+// queryVersions returns the Thanos versions requested by a ThanosQuery, keyed by version policy component.
+func queryVersions(in v1alpha1.ThanosQuery) map[string][]string {
+	versions := make(map[string][]string)
+	if !hasExternalDownstream(in) {
+		versions[versionPolicyQuery] = []string{thanosVersion(in.Spec.CommonFields)}
+	}
+	if in.Spec.QueryFrontend != nil {
+		versions[versionPolicyQueryFrontend] = []string{thanosVersion(in.Spec.QueryFrontend.CommonFields)}
+	}
+	return versions
+}
+
+// storeVersions returns the Thanos versions requested by a ThanosStore, keyed by version policy component.
+func storeVersions(in v1alpha1.ThanosStore) map[string][]string {
+	return map[string][]string{versionPolicyStore: {thanosVersion(in.Spec.CommonFields)}}
+}
+
+// receiveVersions returns the Thanos versions requested by the router and ingesters of a ThanosReceive,
+// keyed by version policy component.
+func receiveVersions(in v1alpha1.ThanosReceive) map[string][]string {
+	versions := []string{thanosVersion(in.Spec.Router.CommonFields)}
+	for _, hashring := range in.Spec.Ingester.Hashrings {
+		versions = append(versions, thanosVersion(hashring.CommonFields))
+	}
+	return map[string][]string{versionPolicyReceive: versions}
+}
+
+// rulerVersions returns the Thanos versions requested by a ThanosRuler, keyed by version policy component.
+func rulerVersions(in v1alpha1.ThanosRuler) map[string][]string {
+	return map[string][]string{versionPolicyRuler: {thanosVersion(in.Spec.CommonFields)}}
+}
+
+// compactVersions returns the Thanos versions requested by a ThanosCompact, keyed by version policy component.
+func compactVersions(in v1alpha1.ThanosCompact) map[string][]string {
+	return map[string][]string{versionPolicyCompact: {thanosVersion(in.Spec.CommonFields)}}
+}
+
 func commonToOpts(
 	owner client.Object,
 	replicas int32,
@@ -212,19 +924,92 @@ func commonToOpts(
 	featureGates *v1alpha1.FeatureGates,
 	additional v1alpha1.Additional) manifests.Options {
 
-	return manifests.Options{
-		Owner:                owner.GetName(),
-		Namespace:            owner.GetNamespace(),
-		Replicas:             replicas,
-		Labels:               labels,
-		Annotations:          annotations,
-		Image:                common.Image,
-		ResourceRequirements: common.ResourceRequirements,
-		LogLevel:             common.LogLevel,
-		LogFormat:            common.LogFormat,
-		Additional:           additionalToOpts(additional),
-		ServiceMonitorConfig: serviceMonitorConfigToOpts(featureGates, labels),
-		PodDisruptionConfig:  getPodDisruptionBudget(replicas),
+	opts := manifests.Options{
+		Owner:                    owner.GetName(),
+		Namespace:                owner.GetNamespace(),
+		Replicas:                 replicas,
+		Labels:                   labels,
+		Annotations:              annotations,
+		Image:                    common.Image,
+		Version:                  common.Version,
+		ResourceRequirements:     common.ResourceRequirements,
+		ContainerResources:       containerResourcesToOpts(common.ContainerResources),
+		LogLevel:                 common.LogLevel,
+		LogFormat:                common.LogFormat,
+		Additional:               additionalToOpts(additional),
+		ListenPorts:              listenPortsToOpts(common.ListenPorts),
+		HTTPPortName:             ptr.Deref(common.MetricsPortName, ""),
+		ServiceMonitorConfig:     serviceMonitorConfigToOpts(featureGates, labels),
+		PodDisruptionConfig:      getPodDisruptionBudget(replicas),
+		Scheduling:               schedulingToOpts(common),
+		TerminationMessagePolicy: ptr.Deref(common.TerminationMessagePolicy, ""),
+		Probes:                   probesToOpts(common.Probes),
+		PodSecurityContext:       common.PodSecurityContext,
+		SecurityContext:          common.SecurityContext,
+		ServiceAccount:           serviceAccountToOpts(common.ServiceAccount),
+	}
+	return opts.ApplyDefaults()
+}
+
+// effectiveDefaultsFromOpts returns the values deployed for the defaulted fields of the given options.
+func effectiveDefaultsFromOpts(opts manifests.Options) *v1alpha1.EffectiveDefaults {
+	opts = opts.ApplyDefaults()
+	return &v1alpha1.EffectiveDefaults{
+		Image:     *opts.Image,
+		Version:   *opts.Version,
+		LogLevel:  *opts.LogLevel,
+		LogFormat: *opts.LogFormat,
+	}
+}
+
+// serviceAccountToOpts returns the ServiceAccountOptions of the component, or nil if the ServiceAccount is not configured.
+func serviceAccountToOpts(in *v1alpha1.ServiceAccount) *manifests.ServiceAccountOptions {
+	if in == nil {
+		return nil
+	}
+	return &manifests.ServiceAccountOptions{
+		Name:        ptr.Deref(in.Name, ""),
+		Labels:      in.Labels,
+		Annotations: in.Annotations,
+	}
+}
+
+// probesToOpts returns the ProbeOptions of the component, or nil if the probes are not tuned.
+func probesToOpts(in *v1alpha1.Probes) *manifests.ProbeOptions {
+	if in == nil {
+		return nil
+	}
+	return &manifests.ProbeOptions{
+		Liveness:  probeSettingsToOpts(in.Liveness),
+		Readiness: probeSettingsToOpts(in.Readiness),
+		Startup:   probeSettingsToOpts(in.Startup),
+		GRPC:      in.GRPC,
+	}
+}
+
+func probeSettingsToOpts(in *v1alpha1.ProbeSettings) *manifests.ProbeSettings {
+	if in == nil {
+		return nil
+	}
+	return &manifests.ProbeSettings{
+		InitialDelaySeconds: in.InitialDelaySeconds,
+		TimeoutSeconds:      in.TimeoutSeconds,
+		PeriodSeconds:       in.PeriodSeconds,
+		SuccessThreshold:    in.SuccessThreshold,
+		FailureThreshold:    in.FailureThreshold,
+	}
+}
+
+// schedulingToOpts returns the SchedulingOptions of the component, or nil if no scheduling constraint is set.
+func schedulingToOpts(in v1alpha1.CommonFields) *manifests.SchedulingOptions {
+	if in.Affinity == nil && len(in.Tolerations) == 0 && len(in.NodeSelector) == 0 && len(in.TopologySpreadConstraints) == 0 {
+		return nil
+	}
+	return &manifests.SchedulingOptions{
+		Affinity:                  in.Affinity,
+		Tolerations:               in.Tolerations,
+		NodeSelector:              in.NodeSelector,
+		TopologySpreadConstraints: in.TopologySpreadConstraints,
 	}
 }
 
@@ -236,6 +1021,84 @@ func getPodDisruptionBudget(replicas int32) *manifests.PodDisruptionBudgetOption
 	return nil
 }
 
+// podDisruptionConfigToOpts returns the PodDisruptionBudgetOptions for the given configuration
+// if replicas is greater than 1 or nil otherwise.
+func podDisruptionConfigToOpts(in *v1alpha1.PodDisruptionConfig, replicas int32) *manifests.PodDisruptionBudgetOptions {
+	opts := getPodDisruptionBudget(replicas)
+	if opts == nil || in == nil {
+		return opts
+	}
+	opts.MinAvailable = in.MinAvailable
+	opts.MaxUnavailable = in.MaxUnavailable
+	return opts
+}
+
+// maxReplicas returns the maximum number of replicas of a component, which is the upper limit of its autoscaling if enabled.
+func maxReplicas(replicas int32, autoscaling *v1alpha1.AutoscalingConfig) int32 {
+	if autoscaling != nil {
+		return autoscaling.MaxReplicas
+	}
+	return replicas
+}
+
+// autoscalingConfigToOpts returns the HorizontalPodAutoscalerOptions for the given configuration,
+// or nil if autoscaling is not configured.
+func autoscalingConfigToOpts(in *v1alpha1.AutoscalingConfig) *manifests.HorizontalPodAutoscalerOptions {
+	if in == nil {
+		return nil
+	}
+
+	var metrics []autoscalingv2.MetricSpec
+	if in.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, resourceUtilizationMetric(corev1.ResourceCPU, *in.TargetCPUUtilizationPercentage))
+	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, resourceUtilizationMetric(corev1.ResourceMemory, *in.TargetMemoryUtilizationPercentage))
+	}
+	metrics = append(metrics, in.Metrics...)
+
+	return &manifests.HorizontalPodAutoscalerOptions{
+		MinReplicas: in.MinReplicas,
+		MaxReplicas: in.MaxReplicas,
+		Metrics:     metrics,
+		Behavior:    in.Behavior,
+	}
+}
+
+func resourceUtilizationMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: ptr.To(utilization),
+			},
+		},
+	}
+}
+
+func listenPortsToOpts(in *v1alpha1.ListenPorts) *manifests.ListenPortOptions {
+	if in == nil {
+		return nil
+	}
+	return &manifests.ListenPortOptions{
+		GRPC: in.GRPC,
+		HTTP: in.HTTP,
+	}
+}
+
+func containerResourcesToOpts(in []v1alpha1.ContainerResources) map[string]corev1.ResourceRequirements {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]corev1.ResourceRequirements, len(in))
+	for _, c := range in {
+		out[c.Name] = c.Resources
+	}
+	return out
+}
+
 func additionalToOpts(in v1alpha1.Additional) manifests.Additional {
 	return manifests.Additional{
 		Args:         in.Args,
@@ -245,7 +1108,111 @@ func additionalToOpts(in v1alpha1.Additional) manifests.Additional {
 		Ports:        in.Ports,
 		Env:          in.Env,
 		ServicePorts: in.ServicePorts,
+		Mounts:       mountsToOpts(in.Mounts),
+	}
+}
+
+func mountsToOpts(in []v1alpha1.Mount) []manifests.Mount {
+	if len(in) == 0 {
+		return nil
+	}
+
+	toSource := func(from *v1alpha1.MountSource) *manifests.MountSource {
+		if from == nil {
+			return nil
+		}
+		return &manifests.MountSource{
+			Name:     from.Name,
+			Items:    from.Items,
+			Optional: from.Optional,
+		}
+	}
+
+	out := make([]manifests.Mount, 0, len(in))
+	for _, m := range in {
+		out = append(out, manifests.Mount{
+			Name:      m.Name,
+			ConfigMap: toSource(m.ConfigMap),
+			Secret:    toSource(m.Secret),
+			MountPath: m.MountPath,
+			SubPath:   m.SubPath,
+		})
 	}
+	return out
+}
+
+// validateAdditional validates the additional configuration of the components of a resource.
+func validateAdditional(in ...v1alpha1.Additional) error {
+	var errs []error
+	for _, additional := range in {
+		errs = append(errs, additionalToOpts(additional).Validate())
+	}
+	return errors.Join(errs...)
+}
+
+// validateOptions validates the options of the components of a resource, see manifests.Options.Validate.
+func validateOptions(opts ...manifests.Options) error {
+	var errs []error
+	for _, o := range opts {
+		errs = append(errs, o.Validate())
+	}
+	return errors.Join(errs...)
+}
+
+// validateQueryFrontendLimits validates the limits of the Query Frontend of a ThanosQuery against each other
+// and against the limits of its Querier. It complements the validation rules of the CRD,
+// which cannot compare durations using day, week or year units.
+func validateQueryFrontendLimits(in v1alpha1.ThanosQuery) error {
+	frontend := in.Spec.QueryFrontend
+	if frontend == nil {
+		return nil
+	}
+	parse := func(d *v1alpha1.Duration, def v1alpha1.Duration) (model.Duration, error) {
+		return model.ParseDuration(string(ptr.Deref(d, def)))
+	}
+
+	var errs []error
+	logLongerThan, err := parse(frontend.LogQueriesLongerThan, "0")
+	errs = append(errs, err)
+	timeout, err := parse(in.Spec.Timeout, defaultQueryTimeout)
+	errs = append(errs, err)
+	labelsSplit, err := parse(frontend.LabelsSplitInterval, "0")
+	errs = append(errs, err)
+	labelsRange, err := parse(frontend.LabelsDefaultTimeRange, "0")
+	errs = append(errs, err)
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if logLongerThan > 0 && logLongerThan >= timeout {
+		errs = append(errs, fmt.Errorf("queryFrontend.logQueriesLongerThan %s must be less than timeout %s", logLongerThan, timeout))
+	}
+	if labelsSplit > 0 && labelsRange > 0 && labelsSplit > labelsRange {
+		errs = append(errs, fmt.Errorf("queryFrontend.labelsSplitInterval %s must not be larger than labelsDefaultTimeRange %s", labelsSplit, labelsRange))
+	}
+	return errors.Join(errs...)
+}
+
+// validateQueryPools validates that the names of the query pools of a ThanosQuery do not collide with the names
+// of its endpoint groups, which share the name of their resources, and that the query pool of the Query Frontend exists.
+func validateQueryPools(in v1alpha1.ThanosQuery) error {
+	pools := make(map[string]struct{}, len(in.Spec.QueryPools))
+	for _, pool := range in.Spec.QueryPools {
+		pools[pool.Name] = struct{}{}
+	}
+
+	var errs []error
+	for _, group := range in.Spec.EndpointGroups {
+		if _, ok := pools[group.Name]; ok {
+			errs = append(errs, fmt.Errorf("query pool %s has the same name as an endpoint group", group.Name))
+		}
+	}
+	if in.Spec.QueryFrontend != nil && in.Spec.QueryFrontend.QueryPool != nil {
+		if _, ok := pools[*in.Spec.QueryFrontend.QueryPool]; !ok {
+			errs = append(errs, fmt.Errorf("queryFrontend.queryPool %s does not exist", *in.Spec.QueryFrontend.QueryPool))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func serviceMonitorConfigToOpts(in *v1alpha1.FeatureGates, labels map[string]string) manifests.ServiceMonitorConfig {
@@ -261,7 +1228,7 @@ func serviceMonitorConfigToOpts(in *v1alpha1.FeatureGates, labels map[string]str
 
 	sm := in.ServiceMonitorConfig
 	return manifests.ServiceMonitorConfig{
-		Enabled: *sm.Enable,
+		Enabled: ptr.Deref(sm.Enable, true),
 		Labels:  manifests.MergeLabels(sm.Labels, labels),
 	}
 }
@@ -281,7 +1248,24 @@ func toManifestCacheConfig(config *v1alpha1.CacheConfig) manifests.CacheConfig {
 		}
 	}
 
-	// if there is no external cache config, try to build the in-memory cache config
+	if config.RedisCacheConfig != nil {
+		return manifests.CacheConfig{
+			Redis: toManifestRedisCacheConfig(config.RedisCacheConfig),
+		}
+	}
+
+	if config.MemcachedCacheConfig != nil {
+		out := &manifests.MemcachedCacheConfig{Addresses: config.MemcachedCacheConfig.Addresses}
+		if config.MemcachedCacheConfig.MaxItemSize != nil {
+			maxItemSize := config.MemcachedCacheConfig.MaxItemSize.ToResourceQuantity()
+			out.MaxItemSize = maxItemSize.Value()
+		}
+		return manifests.CacheConfig{
+			Memcached: out,
+		}
+	}
+
+	// if there is no external, Redis or Memcached cache config, try to build the in-memory cache config
 	var toInMemoryCacheConfig *manifests.InMemoryCacheConfig
 	if config.InMemoryCacheConfig != nil {
 		var maxSize, maxItemSize string
@@ -303,3 +1287,174 @@ func toManifestCacheConfig(config *v1alpha1.CacheConfig) manifests.CacheConfig {
 		FromSecret:          nil,
 	}
 }
+
+// toManifestRedisCacheConfig returns the Redis cache config for the given configuration.
+// The addresses of a managed Redis are not known here, see storeCacheConfig.
+func toManifestRedisCacheConfig(config *v1alpha1.RedisCacheConfig) *manifests.RedisCacheConfig {
+	var tls *manifests.TLSOptions
+	if config.TLS != nil {
+		tls = &manifests.TLSOptions{
+			CertSecret:         ptr.Deref(config.TLS.CertSecret, ""),
+			CA:                 config.TLS.CA,
+			ServerName:         ptr.Deref(config.TLS.ServerName, ""),
+			InsecureSkipVerify: ptr.Deref(config.TLS.InsecureSkipVerify, false),
+		}
+	}
+	return &manifests.RedisCacheConfig{
+		Addresses:  config.Addresses,
+		MasterName: ptr.Deref(config.MasterName, ""),
+		Username:   ptr.Deref(config.Username, ""),
+		Password:   config.PasswordSecret,
+		DB:         ptr.Deref(config.DB, 0),
+		TLS:        tls,
+	}
+}
+
+func toManifestRequestLoggingConfig(config *v1alpha1.RequestLoggingConfig) *manifests.RequestLoggingConfig {
+	if config == nil {
+		return nil
+	}
+
+	toOptions := func(from *v1alpha1.RequestLoggingOptions) *manifests.RequestLoggingOptions {
+		if from == nil {
+			return nil
+		}
+		return &manifests.RequestLoggingOptions{
+			Level:    from.Level,
+			LogStart: ptr.Deref(from.LogStart, false),
+			LogEnd:   ptr.Deref(from.LogEnd, true),
+		}
+	}
+
+	return &manifests.RequestLoggingConfig{
+		HTTP: toOptions(config.HTTP),
+		GRPC: toOptions(config.GRPC),
+	}
+}
+
+func logForwardingToOpts(in *v1alpha1.LogForwarding) *manifests.LogForwardingOptions {
+	if in == nil {
+		return nil
+	}
+	return &manifests.LogForwardingOptions{
+		Image:    ptr.Deref(in.Image, ""),
+		Endpoint: in.Endpoint,
+	}
+}
+
+// toManifestEndpointType returns the endpoint label for the given endpoint type.
+// An empty string is returned if the type is not set.
+func toManifestEndpointType(etype v1alpha1.EndpointType) manifests.EndpointType {
+	switch etype {
+	case v1alpha1.RegularEndpointType:
+		return manifests.RegularLabel
+	case v1alpha1.StrictEndpointType:
+		return manifests.StrictLabel
+	case v1alpha1.GroupEndpointType:
+		return manifests.GroupLabel
+	case v1alpha1.GroupStrictEndpointType:
+		return manifests.GroupStrictLabel
+	default:
+		return ""
+	}
+}
+
+// toManifestCustomEndpoints returns the custom StoreAPI endpoints of a ThanosQuery, sorted by address.
+func toManifestCustomEndpoints(in []v1alpha1.CustomStoreEndpoint) []manifestquery.CustomEndpoint {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]manifestquery.CustomEndpoint, 0, len(in))
+	for _, ep := range in {
+		etype := toManifestEndpointType(ep.Type)
+		if etype == "" {
+			etype = manifests.RegularLabel
+		}
+		out = append(out, manifestquery.CustomEndpoint{Address: ep.Address, Type: etype})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Address < out[j].Address
+	})
+	return out
+}
+
+// toManifestTokenProjection returns the projection of the service account token for the workload identity, if any.
+func toManifestTokenProjection(in *v1alpha1.WorkloadIdentity) *manifests.TokenProjection {
+	if in == nil {
+		return nil
+	}
+	return &manifests.TokenProjection{
+		Audience:          in.Audience,
+		ExpirationSeconds: ptr.Deref(in.ExpirationSeconds, manifests.DefaultTokenExpirationSeconds),
+	}
+}
+
+// grpcServerTLSToOpts returns the TLSOptions of a gRPC server, or nil if TLS is not configured.
+func grpcServerTLSToOpts(in *v1alpha1.GRPCServerTLSConfig) *manifests.TLSOptions {
+	if in == nil {
+		return nil
+	}
+	return &manifests.TLSOptions{
+		CertSecret: in.CertSecret,
+		CA:         in.ClientCA,
+	}
+}
+
+// grpcServerToOpts returns the GRPCServerOptions of a gRPC server, or nil if it is not configured.
+func grpcServerToOpts(in *v1alpha1.GRPCServerConfig) *manifests.GRPCServerOptions {
+	if in == nil {
+		return nil
+	}
+	return &manifests.GRPCServerOptions{
+		MaxConnectionAge: manifests.Duration(manifests.OptionalToString(in.MaxConnectionAge)),
+	}
+}
+
+// grpcClientTLSToOpts returns the TLSOptions of a gRPC client, or nil if TLS is not configured.
+func grpcClientTLSToOpts(in *v1alpha1.GRPCClientTLSConfig) *manifests.TLSOptions {
+	if in == nil {
+		return nil
+	}
+	return &manifests.TLSOptions{
+		CertSecret:         ptr.Deref(in.CertSecret, ""),
+		CA:                 in.CA,
+		ServerName:         ptr.Deref(in.ServerName, ""),
+		InsecureSkipVerify: ptr.Deref(in.InsecureSkipVerify, false),
+	}
+}
+
+// toManifestBackupOptions returns the backup options for the given configuration, or nil if backups are not configured.
+func toManifestBackupOptions(in *v1alpha1.BackupConfig) *manifests.BackupOptions {
+	if in == nil {
+		return nil
+	}
+	return &manifests.BackupOptions{
+		VolumeSnapshotClass: ptr.Deref(in.VolumeSnapshotClass, ""),
+		FileSystemBackup:    ptr.Deref(in.FileSystemBackup, false),
+		VolumeAnnotations:   in.VolumeAnnotations,
+		PodAnnotations:      in.PodAnnotations,
+		PreBackupHook:       toManifestBackupHook(in.PreBackupHook),
+		PostBackupHook:      toManifestBackupHook(in.PostBackupHook),
+	}
+}
+
+func toManifestBackupHook(in *v1alpha1.BackupHook) *manifests.BackupHook {
+	if in == nil {
+		return nil
+	}
+	return &manifests.BackupHook{
+		Container: ptr.Deref(in.Container, ""),
+		Command:   in.Command,
+		OnError:   ptr.Deref(in.OnError, ""),
+		Timeout:   manifests.Duration(ptr.Deref(in.Timeout, "")),
+	}
+}
+
+// optionalDuration converts an optional time or duration to its manifest representation.
+// Nil is returned if the value is not set.
+func optionalDuration(in *v1alpha1.TimeOrDuration) *manifests.Duration {
+	if in == nil {
+		return nil
+	}
+	return ptr.To(manifests.Duration(*in))
+}
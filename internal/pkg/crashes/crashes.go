@@ -0,0 +1,102 @@
+// Package crashes finds crash looping containers of pods and describes the cause of their last crash,
+// so that it can be surfaced on the resource owning the pods.
+package crashes
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// reasonCrashLoopBackOff is the reason of the waiting state of a container the kubelet backs off restarting.
+	reasonCrashLoopBackOff = "CrashLoopBackOff"
+	// maxMessageLength is the maximum length of the termination message included in the description of a crash.
+	// With FallbackToLogsOnError the message holds up to 2048 bytes of logs, most of which is not needed to identify the cause.
+	maxMessageLength = 512
+)
+
+// Crash is the last crash of a crash looping container.
+type Crash struct {
+	// Pod is the name of the pod of the container.
+	Pod string
+	// Container is the name of the container.
+	Container string
+	// ExitCode is the exit code of the last terminated instance of the container.
+	ExitCode int32
+	// Reason is the reason of the termination, e.g. Error or OOMKilled.
+	Reason string
+	// Message is the termination message of the container, truncated to maxMessageLength.
+	Message string
+	// Restarts is the number of restarts of the container.
+	Restarts int32
+}
+
+// String describes the crash in a single line.
+func (c Crash) String() string {
+	s := fmt.Sprintf("container %s of pod %s exited with code %d", c.Container, c.Pod, c.ExitCode)
+	if c.Reason != "" {
+		s += fmt.Sprintf(" (%s)", c.Reason)
+	}
+	s += fmt.Sprintf(" after %d restarts", c.Restarts)
+	if c.Message != "" {
+		s += ": " + c.Message
+	}
+	return s
+}
+
+// Find returns the crash looping containers and init containers of the pods, in the order of the pods.
+// A container is crash looping if the kubelet backs off restarting it after it failed.
+func Find(pods []corev1.Pod) []Crash {
+	var crashes []Crash
+	for _, pod := range pods {
+		for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+			for _, status := range statuses {
+				waiting := status.State.Waiting
+				terminated := status.LastTerminationState.Terminated
+				if waiting == nil || waiting.Reason != reasonCrashLoopBackOff || terminated == nil {
+					continue
+				}
+				crashes = append(crashes, Crash{
+					Pod:       pod.GetName(),
+					Container: status.Name,
+					ExitCode:  terminated.ExitCode,
+					Reason:    terminated.Reason,
+					Message:   truncate(terminated.Message),
+					Restarts:  status.RestartCount,
+				})
+			}
+		}
+	}
+	return crashes
+}
+
+// Summarize describes at most limit crashes, one per line, followed by the number of crashes omitted.
+func Summarize(crashes []Crash, limit int) string {
+	lines := make([]string, 0, min(len(crashes), limit)+1)
+	for i, c := range crashes {
+		if i == limit {
+			lines = append(lines, fmt.Sprintf("and %d more crash looping containers", len(crashes)-limit))
+			break
+		}
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate trims the termination message and keeps its last maxMessageLength bytes,
+// which hold the most recent log lines with FallbackToLogsOnError.
+func truncate(message string) string {
+	message = strings.TrimSpace(message)
+	if len(message) <= maxMessageLength {
+		return message
+	}
+	message = message[len(message)-maxMessageLength:]
+	// do not start in the middle of a multi-byte character
+	for len(message) > 0 && !utf8.RuneStart(message[0]) {
+		message = message[1:]
+	}
+	return "..." + message
+}
@@ -0,0 +1,90 @@
+package crashes
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func crashLooping(name, message string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name:         name,
+		RestartCount: 4,
+		State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: reasonCrashLoopBackOff},
+		},
+		LastTerminationState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error", Message: message},
+		},
+	}
+}
+
+func TestFind(t *testing.T) {
+	recovered := corev1.ContainerStatus{
+		Name:         "thanos",
+		RestartCount: 1,
+		State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		LastTerminationState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"},
+		},
+	}
+	pulling := corev1.ContainerStatus{
+		Name:  "sidecar",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+	}
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "thanos-store-0"},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{crashLooping("init", "")},
+				ContainerStatuses:     []corev1.ContainerStatus{recovered, pulling},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "thanos-store-1"},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{crashLooping("thanos", "\nlevel=error msg=\"parse flags\"\n")},
+			},
+		},
+	}
+
+	crashes := Find(pods)
+	if len(crashes) != 2 {
+		t.Fatalf("expected 2 crashes, got %d: %v", len(crashes), crashes)
+	}
+	if crashes[0].Pod != "thanos-store-0" || crashes[0].Container != "init" {
+		t.Errorf("expected crash of init container, got %v", crashes[0])
+	}
+	want := `container thanos of pod thanos-store-1 exited with code 1 (Error) after 4 restarts: level=error msg="parse flags"`
+	if got := crashes[1].String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	crashes := []Crash{{Pod: "a", Container: "thanos"}, {Pod: "b", Container: "thanos"}, {Pod: "c", Container: "thanos"}}
+
+	if got := strings.Split(Summarize(crashes, 3), "\n"); len(got) != 3 {
+		t.Errorf("expected all crashes to be described, got %v", got)
+	}
+	got := strings.Split(Summarize(crashes, 2), "\n")
+	if len(got) != 3 || got[2] != "and 1 more crash looping containers" {
+		t.Errorf("expected the omitted crash to be counted, got %v", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("é", maxMessageLength) + "last line"
+	got := truncate(long)
+	if !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "last line") {
+		t.Errorf("expected the end of the message to be kept, got %q", got)
+	}
+	if len(got) > maxMessageLength+len("...") {
+		t.Errorf("expected at most %d bytes, got %d", maxMessageLength+len("..."), len(got))
+	}
+	if !strings.HasPrefix(strings.TrimPrefix(got, "..."), "é") {
+		t.Errorf("expected the message not to start within a character, got %q", got[:8])
+	}
+}
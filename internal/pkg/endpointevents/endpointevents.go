@@ -0,0 +1,131 @@
+// Package endpointevents tracks changes to the set of endpoints of Queriers and aggregates them over a window,
+// so that a burst of topology changes is reported once rather than per change.
+package endpointevents
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Changes are the endpoints added to and removed from a Querier over a window.
+type Changes struct {
+	// Added are the endpoints that were added, sorted by name.
+	Added []string
+	// Removed are the endpoints that were removed, sorted by name.
+	Removed []string
+}
+
+// Empty returns true if no endpoint was added or removed.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0
+}
+
+// Tracker aggregates the changes to the endpoints of Queriers over a window.
+// It is safe for concurrent use.
+type Tracker struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*state
+}
+
+type state struct {
+	endpoints map[string]struct{}
+	added     map[string]struct{}
+	removed   map[string]struct{}
+	// since is the time the first pending change was observed.
+	since time.Time
+}
+
+// NewTracker returns a Tracker which aggregates changes over the given window.
+func NewTracker(window time.Duration) *Tracker {
+	return &Tracker{
+		window: window,
+		now:    time.Now,
+		states: make(map[string]*state),
+	}
+}
+
+// Observe records the current endpoints of the Querier identified by key.
+// The first observation of a key establishes the baseline and is not reported as a change.
+// An endpoint which is added and removed again within a window is not reported.
+func (t *Tracker) Observe(key string, endpoints []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[string]struct{}, len(endpoints))
+	for _, e := range endpoints {
+		current[e] = struct{}{}
+	}
+
+	s, ok := t.states[key]
+	if !ok {
+		t.states[key] = &state{endpoints: current, added: map[string]struct{}{}, removed: map[string]struct{}{}}
+		return
+	}
+
+	changed := false
+	for e := range current {
+		if _, ok := s.endpoints[e]; ok {
+			continue
+		}
+		changed = true
+		if _, ok := s.removed[e]; ok {
+			delete(s.removed, e)
+			continue
+		}
+		s.added[e] = struct{}{}
+	}
+	for e := range s.endpoints {
+		if _, ok := current[e]; ok {
+			continue
+		}
+		changed = true
+		if _, ok := s.added[e]; ok {
+			delete(s.added, e)
+			continue
+		}
+		s.removed[e] = struct{}{}
+	}
+	if changed && s.since.IsZero() {
+		s.since = t.now()
+	}
+	s.endpoints = current
+}
+
+// Flush returns the changes to the endpoints of the Querier identified by key once the window
+// since the first pending change has elapsed, and resets them.
+// It returns false if there are no pending changes or the window has not elapsed yet.
+func (t *Tracker) Flush(key string) (Changes, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.states[key]
+	if !ok || s.since.IsZero() || t.now().Sub(s.since) < t.window {
+		return Changes{}, false
+	}
+
+	changes := Changes{Added: sortedKeys(s.added), Removed: sortedKeys(s.removed)}
+	s.added = map[string]struct{}{}
+	s.removed = map[string]struct{}{}
+	s.since = time.Time{}
+	return changes, !changes.Empty()
+}
+
+// Forget drops the state of the Querier identified by key.
+func (t *Tracker) Forget(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.states, key)
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+package endpointevents
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker(time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	tracker.Observe("ns/query", []string{"ns/a", "ns/b"})
+	if _, ok := tracker.Flush("ns/query"); ok {
+		t.Fatal("expected the first observation to establish the baseline")
+	}
+
+	tracker.Observe("ns/query", []string{"ns/a", "ns/c"})
+	now = now.Add(30 * time.Second)
+	tracker.Observe("ns/query", []string{"ns/a", "ns/c", "ns/d"})
+	if _, ok := tracker.Flush("ns/query"); ok {
+		t.Fatal("expected changes to be held back until the window elapsed")
+	}
+
+	// d is added and removed within the window, b is removed and c is added
+	tracker.Observe("ns/query", []string{"ns/a", "ns/c"})
+	now = now.Add(30 * time.Second)
+	changes, ok := tracker.Flush("ns/query")
+	if !ok {
+		t.Fatal("expected changes once the window elapsed")
+	}
+	expect := Changes{Added: []string{"ns/c"}, Removed: []string{"ns/b"}}
+	if !reflect.DeepEqual(changes, expect) {
+		t.Errorf("expected %v, got %v", expect, changes)
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok := tracker.Flush("ns/query"); ok {
+		t.Error("expected no changes after flushing")
+	}
+
+	// a change which is reverted within the window is not reported
+	tracker.Observe("ns/query", []string{"ns/a"})
+	tracker.Observe("ns/query", []string{"ns/a", "ns/c"})
+	now = now.Add(time.Hour)
+	if changes, ok := tracker.Flush("ns/query"); ok {
+		t.Errorf("expected reverted changes to not be reported, got %v", changes)
+	}
+
+	tracker.Forget("ns/query")
+	tracker.Observe("ns/query", []string{"ns/e"})
+	now = now.Add(time.Hour)
+	if _, ok := tracker.Flush("ns/query"); ok {
+		t.Error("expected the first observation after forgetting to establish the baseline")
+	}
+}
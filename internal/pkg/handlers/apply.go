@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// FieldManager is the field manager used to server-side apply managed objects.
+	FieldManager = "thanos-operator"
+
+	// AppliedHashAnnotation is set on managed objects and holds a hash of the object as rendered by the operator.
+	// Objects whose hash is unchanged are not applied again.
+	AppliedHashAnnotation = "monitoring.thanos.io/applied-hash"
+)
+
+// legacyFieldManagers are the field managers of the updates made before objects were server-side applied.
+// Requests without a field manager are recorded with the name of the binary, which is manager.
+var legacyFieldManagers = sets.New("manager", FieldManager)
+
+// Apply server-side applies the given object, which is not owned by the resources reconciled with the handler,
+// with FieldManager as field manager. Unlike CreateOrUpdate, it neither sets an owner reference nor is subject to
+// the write limit and apply retry budget.
+func (h *Handler) Apply(ctx context.Context, obj client.Object) error {
+	if err := h.setAppliedHash(obj); err != nil {
+		return err
+	}
+	existing, err := h.getExisting(ctx, obj)
+	if err != nil || isApplied(existing, obj) {
+		return err
+	}
+	_, err = h.apply(ctx, h.client, existing, obj)
+	return err
+}
+
+// setAppliedHash sets the kind and the AppliedHashAnnotation of obj to a hash of obj.
+func (h *handler) setAppliedHash(obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, h.scheme)
+	if err != nil {
+		return fmt.Errorf("failed to get kind of resource: %w", err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	// fields populated by the API server are not part of the rendered object
+	rendered := obj.DeepCopyObject().(client.Object)
+	rendered.SetUID("")
+	rendered.SetResourceVersion("")
+	rendered.SetGeneration(0)
+	rendered.SetCreationTimestamp(metav1.Time{})
+	rendered.SetManagedFields(nil)
+	annotations := rendered.GetAnnotations()
+	delete(annotations, AppliedHashAnnotation)
+	b, err := json.Marshal(rendered)
+	if err != nil {
+		return fmt.Errorf("failed to hash resource: %w", err)
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AppliedHashAnnotation] = fmt.Sprintf("%x", sha256.Sum256(b))[:16]
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// isApplied returns true if the existing object was applied from an object with the same AppliedHashAnnotation as obj.
+// Comparing the objects themselves would not detect unchanged objects, as the API server defaults fields which
+// are not set on obj.
+func isApplied(existing, obj client.Object) bool {
+	return existing != nil && existing.GetDeletionTimestamp() == nil &&
+		existing.GetAnnotations()[AppliedHashAnnotation] == obj.GetAnnotations()[AppliedHashAnnotation]
+}
+
+// getExisting returns the existing object with the name and namespace of obj, or nil if it does not exist.
+func (h *handler) getExisting(ctx context.Context, obj client.Object) (client.Object, error) {
+	existing := obj.DeepCopyObject().(client.Object)
+	if err := h.client.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return existing, nil
+}
+
+// apply server-side applies obj, so that the operator only owns the fields set on obj and leaves fields set by others,
+// such as the replicas of an autoscaled Deployment or fields defaulted by the API server, untouched.
+// Immutable fields are retained from the existing object, see manifests.RetainImmutableFields, and fields previously
+// owned by client-side updates of the operator are transferred to FieldManager first, so that fields which are
+// no longer set are removed.
+// If fields set on obj are owned by another field manager, the conflict is logged and ownership is forced.
+func (h *handler) apply(ctx context.Context, c client.Client, existing, obj client.Object) (controllerutil.OperationResult, error) {
+	op := controllerutil.OperationResultCreated
+	if existing != nil {
+		manifests.RetainImmutableFields(existing, obj)
+		if err := h.upgradeManagedFields(ctx, c, existing); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		op = controllerutil.OperationResultUpdated
+	}
+
+	err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager))
+	if apierrors.IsConflict(err) {
+		loggerForObj(h.logger, obj).Info("fields of resource are owned by another field manager, forcing ownership", "conflict", err.Error())
+		err = c.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+	}
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if existing != nil && existing.GetResourceVersion() == obj.GetResourceVersion() {
+		return controllerutil.OperationResultNone, nil
+	}
+	return op, nil
+}
+
+// upgradeManagedFields transfers the fields of the existing object owned by client-side updates of the operator
+// to FieldManager. It is a no-op once the fields were transferred.
+func (h *handler) upgradeManagedFields(ctx context.Context, c client.Client, existing client.Object) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(existing, legacyFieldManagers, FieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+	if err := c.Patch(ctx, existing, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+		return fmt.Errorf("failed to upgrade managed fields of resource: %w", err)
+	}
+	return nil
+}
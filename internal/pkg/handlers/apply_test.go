@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestHandler_CreateOrUpdateAppliesWithFieldManager(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	var patches []*client.PatchOptions
+	conflict := true
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patchOpts := (&client.PatchOptions{}).ApplyOptions(opts)
+			patches = append(patches, patchOpts)
+			if conflict && (patchOpts.Force == nil || !*patchOpts.Force) {
+				return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(),
+					errors.New(`Apply failed with 1 conflict: conflict with "kubectl-edit": .data.key`))
+			}
+			return emulateApply(ctx, c, obj, patch, opts...)
+		},
+	}).Build()
+	h := &Handler{
+		handler: &handler{
+			client: c,
+			scheme: scheme.Scheme,
+			logger: logr.New(log.NullLogSink{}),
+		},
+	}
+
+	owner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "uid"}}
+	configMap := func(value string) []client.Object {
+		return []client.Object{&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: namespace},
+			Data:       map[string]string{"key": value},
+		}}
+	}
+
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, configMap("a")); errCount != 0 {
+		t.Fatalf("expected no errors, got %d", errCount)
+	}
+	if len(patches) != 2 {
+		t.Fatalf("expected conflicting apply to be retried forcing ownership, got %d patches", len(patches))
+	}
+	for _, p := range patches {
+		if p.FieldManager != FieldManager {
+			t.Errorf("expected field manager %s, got %s", FieldManager, p.FieldManager)
+		}
+	}
+
+	patches = nil
+	conflict = false
+	h.CreateOrUpdate(ctx, namespace, owner, configMap("a"))
+	if len(patches) != 0 {
+		t.Errorf("expected unchanged object to not be applied, got %d patches", len(patches))
+	}
+
+	h.CreateOrUpdate(ctx, namespace, owner, configMap("b"))
+	if len(patches) != 1 || patches[0].Force != nil {
+		t.Errorf("expected changed object to be applied once without forcing ownership, got %v", patches)
+	}
+
+	// an object applied by forcing ownership counts as a single write against the write limit of the owner
+	patches = nil
+	conflict = true
+	h.SetWriteLimit(1, time.Hour)
+	h.CreateOrUpdate(ctx, namespace, owner, configMap("c"))
+	if len(patches) != 2 {
+		t.Errorf("expected conflicting apply to be retried forcing ownership within the write limit, got %d patches", len(patches))
+	}
+	patches = nil
+	h.CreateOrUpdate(ctx, namespace, owner, configMap("d"))
+	if len(patches) != 0 {
+		t.Errorf("expected apply to be deferred once the write limit is reached, got %d patches", len(patches))
+	}
+	if _, err := h.ApplyThrottled(owner); !errors.Is(err, ErrApplyThrottled) {
+		t.Errorf("expected ErrApplyThrottled, got %v", err)
+	}
+}
+
+func TestHandler_CreateOrUpdateSkipsAppliedObjects(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	var patches int
+	c := interceptor.NewClient(newDefaultingApplyClient(), interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patches++
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	})
+	h := NewHandler(c, scheme.Scheme, logr.New(log.NullLogSink{}))
+
+	owner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "uid"}}
+	service := func(port int32) []client.Object {
+		return []client.Object{&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: namespace},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: port}}},
+		}}
+	}
+
+	h.CreateOrUpdate(ctx, namespace, owner, service(8080))
+	if patches != 1 || h.LogApplySummary(owner, time.Now()) {
+		t.Fatalf("expected service to be created, got %d patches", patches)
+	}
+
+	// the protocol defaulted by the API server does not cause the unchanged service to be applied again
+	patches = 0
+	h.CreateOrUpdate(ctx, namespace, owner, service(8080))
+	if patches != 0 {
+		t.Errorf("expected unchanged service to not be applied, got %d patches", patches)
+	}
+	if !h.LogApplySummary(owner, time.Now()) {
+		t.Error("expected reconciliation without requests to be a no-op")
+	}
+
+	h.CreateOrUpdate(ctx, namespace, owner, service(9090))
+	if patches != 1 || h.LogApplySummary(owner, time.Now()) {
+		t.Errorf("expected changed service to be applied, got %d patches", patches)
+	}
+	got := &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "svc"}, got); err != nil || got.Spec.Ports[0].Port != 9090 {
+		t.Errorf("expected service to be updated, got %v", err)
+	}
+}
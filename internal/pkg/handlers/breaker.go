@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thanos-community/thanos-operator/api/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrApplyBlocked is returned when an object failed to apply repeatedly and is not applied again
+// until the generation or the reconcile request of its owner changes.
+var ErrApplyBlocked = errors.New("apply blocked after repeated failures")
+
+// applyFailure tracks the consecutive failures to apply an object for a generation and reconcile request of its owner.
+type applyFailure struct {
+	generation       int64
+	reconcileRequest string
+	count            int
+	object           string
+	lastErr          string
+}
+
+// SetApplyRetryBudget sets the number of consecutive failures to apply an object after which the handler
+// stops applying it, until the generation of its owner changes or its ReconcileNowAnnotation is set to a new value.
+// A budget of zero, the default, disables the circuit breaker.
+func (h *Handler) SetApplyRetryBudget(budget int) {
+	h.applyRetryBudget = budget
+}
+
+// ApplyBlocked returns an error wrapping ErrApplyBlocked if an object of the given owner exhausted the retry budget
+// for the current generation of the owner. The error includes the last error returned when applying the object,
+// such as the denial message of an admission webhook.
+func (h *Handler) ApplyBlocked(owner client.Object) error {
+	h.applyMu.Lock()
+	defer h.applyMu.Unlock()
+
+	prefix := applyFailureKeyPrefix(owner)
+	keys := make([]string, 0, len(h.applyFailures))
+	for key := range h.applyFailures {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f := h.applyFailures[key]
+		if h.exhausted(owner, f) {
+			return fmt.Errorf("%w: %s failed to apply %d consecutive times, not retrying until the spec or the %s annotation changes: %s",
+				ErrApplyBlocked, f.object, f.count, v1alpha1.ReconcileNowAnnotation, f.lastErr)
+		}
+	}
+	return nil
+}
+
+// isApplyBlocked returns true if the object exhausted the retry budget for the current generation of its owner.
+func (h *handler) isApplyBlocked(owner, obj client.Object) bool {
+	h.applyMu.Lock()
+	defer h.applyMu.Unlock()
+	return h.exhausted(owner, h.applyFailures[applyFailureKey(owner, obj)])
+}
+
+// recordApply records the outcome of applying the object.
+// A success resets the failures of the object, as does a failure for a new generation or reconcile request of the owner.
+func (h *handler) recordApply(owner, obj client.Object, err error) {
+	if h.applyRetryBudget <= 0 {
+		return
+	}
+
+	h.applyMu.Lock()
+	defer h.applyMu.Unlock()
+
+	key := applyFailureKey(owner, obj)
+	if err == nil {
+		delete(h.applyFailures, key)
+		return
+	}
+	if h.applyFailures == nil {
+		h.applyFailures = make(map[string]*applyFailure)
+	}
+
+	f, ok := h.applyFailures[key]
+	if !ok || !f.matches(owner) {
+		f = &applyFailure{
+			generation:       owner.GetGeneration(),
+			reconcileRequest: reconcileRequest(owner),
+			object:           fmt.Sprintf("%s %s", obj.GetObjectKind().GroupVersionKind().Kind, client.ObjectKeyFromObject(obj)),
+		}
+		h.applyFailures[key] = f
+	}
+	f.count++
+	f.lastErr = err.Error()
+}
+
+func (h *handler) exhausted(owner client.Object, f *applyFailure) bool {
+	return h.applyRetryBudget > 0 && f != nil && f.matches(owner) && f.count >= h.applyRetryBudget
+}
+
+// matches returns true if the failures were recorded for the current generation and reconcile request of the owner.
+func (f *applyFailure) matches(owner client.Object) bool {
+	return f.generation == owner.GetGeneration() && f.reconcileRequest == reconcileRequest(owner)
+}
+
+// reconcileRequest returns the value of the ReconcileNowAnnotation of the owner.
+func reconcileRequest(owner client.Object) string {
+	return owner.GetAnnotations()[v1alpha1.ReconcileNowAnnotation]
+}
+
+func applyFailureKeyPrefix(owner client.Object) string {
+	return fmt.Sprintf("%s/", owner.GetUID())
+}
+
+func applyFailureKey(owner, obj client.Object) string {
+	return fmt.Sprintf("%s%s/%s", applyFailureKeyPrefix(owner), obj.GetObjectKind().GroupVersionKind().Kind, client.ObjectKeyFromObject(obj))
+}
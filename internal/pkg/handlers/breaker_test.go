@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/thanos-community/thanos-operator/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestHandler_ApplyRetryBudget(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	deny := true
+	var creates int
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			creates++
+			if deny {
+				return errors.New(`admission webhook "policy.example.com" denied the request: services are not allowed`)
+			}
+			return emulateApply(ctx, c, obj, patch, opts...)
+		},
+	}).Build()
+
+	h := NewHandler(c, scheme.Scheme, logr.New(log.NullLogSink{}))
+	h.SetApplyRetryBudget(2)
+
+	owner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "uid", Generation: 1}}
+	svc := func() []client.Object {
+		return []client.Object{&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: namespace},
+		}}
+	}
+
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, svc()); errCount != 1 {
+		t.Fatalf("expected 1 error, got %d", errCount)
+	}
+	if err := h.ApplyBlocked(owner); err != nil {
+		t.Fatalf("expected apply to not be blocked before the budget is exhausted, got %v", err)
+	}
+
+	h.CreateOrUpdate(ctx, namespace, owner, svc())
+	err := h.ApplyBlocked(owner)
+	if !errors.Is(err, ErrApplyBlocked) {
+		t.Fatalf("expected apply to be blocked once the budget is exhausted, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "services are not allowed") || !strings.Contains(err.Error(), "Service test/svc") {
+		t.Errorf("expected error to name the object and the denial, got %v", err)
+	}
+
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, svc()); errCount != 1 || creates != 2 {
+		t.Errorf("expected blocked object to be skipped and counted as error, got %d errors and %d creates", errCount, creates)
+	}
+
+	// a new reconcile request of the owner resumes applying the object, and blocks it again after the budget
+	owner.Annotations = map[string]string{v1alpha1.ReconcileNowAnnotation: "2024-01-01T00:00:00Z"}
+	if err := h.ApplyBlocked(owner); err != nil {
+		t.Fatalf("expected apply to resume for a new reconcile request, got %v", err)
+	}
+	h.CreateOrUpdate(ctx, namespace, owner, svc())
+	h.CreateOrUpdate(ctx, namespace, owner, svc())
+	if err := h.ApplyBlocked(owner); !errors.Is(err, ErrApplyBlocked) || creates != 4 {
+		t.Fatalf("expected apply to be blocked again after %d creates, got %v", creates, err)
+	}
+
+	// a new generation of the owner resumes applying the object
+	deny = false
+	owner.Generation = 2
+	if err := h.ApplyBlocked(owner); err != nil {
+		t.Fatalf("expected apply to resume for a new generation, got %v", err)
+	}
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, svc()); errCount != 0 || creates != 5 {
+		t.Errorf("expected object to be applied, got %d errors and %d creates", errCount, creates)
+	}
+	if len(h.applyFailures) != 0 {
+		t.Errorf("expected failures to be reset after a successful apply, got %v", h.applyFailures)
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrMissingPermissions is returned when objects of an owner were not applied because the operator
+// lacks the RBAC permissions to apply them. They are applied again once a later probe succeeds.
+var ErrMissingPermissions = errors.New("missing permissions")
+
+// rbacDenial matches the message of an error returned by the API server when the RBAC authorizer denies a request,
+// e.g. `... is forbidden: User "system:serviceaccount:ns:sa" cannot patch resource "servicemonitors" in API group
+// "monitoring.coreos.com" in the namespace "ns"`. Other forbidden errors, such as exceeded quotas or denials of
+// admission webhooks, do not match and are handled as any other error.
+var rbacDenial = regexp.MustCompile(`cannot (\S+) resource "([^"]+)" in API group "([^"]*)"(?: in the namespace "([^"]+)")?`)
+
+// forbiddenObject tracks an object of an owner which could not be applied because of missing permissions.
+type forbiddenObject struct {
+	object     string
+	permission string
+	probeAt    time.Time
+}
+
+// SetForbiddenProbeInterval sets the interval at which objects which could not be applied because of missing
+// RBAC permissions are applied again, to probe whether the permissions were granted.
+// Until then, the objects are skipped, instead of failing the reconciliation, and reported by MissingPermissions.
+// An interval of zero, the default, handles missing permissions as any other error.
+func (h *Handler) SetForbiddenProbeInterval(interval time.Duration) {
+	h.forbiddenProbeInterval = interval
+}
+
+// MissingPermissions returns an error wrapping ErrMissingPermissions, listing the verbs and resources the operator
+// was denied, if objects of the given owner could not be applied because of missing permissions,
+// along with the time until the next probe, after which the owner should be reconciled again.
+func (h *Handler) MissingPermissions(owner client.Object) (time.Duration, error) {
+	h.forbiddenMu.Lock()
+	defer h.forbiddenMu.Unlock()
+
+	prefix := applyFailureKeyPrefix(owner)
+	var permissions, objects []string
+	var probeAt time.Time
+	for key, f := range h.forbidden {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		// objects which were not probed for a whole interval are no longer applied, e.g. because they were feature gated
+		if time.Since(f.probeAt) > h.forbiddenProbeInterval {
+			delete(h.forbidden, key)
+			continue
+		}
+		permissions = append(permissions, f.permission)
+		objects = append(objects, f.object)
+		if probeAt.IsZero() || f.probeAt.Before(probeAt) {
+			probeAt = f.probeAt
+		}
+	}
+	if len(objects) == 0 {
+		return 0, nil
+	}
+	slices.Sort(permissions)
+	permissions = slices.Compact(permissions)
+	slices.Sort(objects)
+	return max(time.Until(probeAt), time.Second),
+		fmt.Errorf("%w: the operator is not permitted to %s, %d object(s) not applied: %s",
+			ErrMissingPermissions, strings.Join(permissions, "; "), len(objects), strings.Join(objects, ", "))
+}
+
+// isForbidden returns true if the object could not be applied because of missing permissions
+// and is not due to be probed yet.
+func (h *handler) isForbidden(owner, obj client.Object) bool {
+	h.forbiddenMu.Lock()
+	defer h.forbiddenMu.Unlock()
+	f, ok := h.forbidden[applyFailureKey(owner, obj)]
+	return ok && time.Now().Before(f.probeAt)
+}
+
+// recordForbidden records the outcome of applying the object. It returns true if the error was caused by missing
+// RBAC permissions, in which case the object is not applied again until the next probe.
+// Any other outcome forgets that the object could not be applied.
+func (h *handler) recordForbidden(owner, obj client.Object, err error) bool {
+	if h.forbiddenProbeInterval <= 0 {
+		return false
+	}
+
+	h.forbiddenMu.Lock()
+	defer h.forbiddenMu.Unlock()
+
+	key := applyFailureKey(owner, obj)
+	permission, ok := missingPermission(err)
+	if !ok {
+		delete(h.forbidden, key)
+		return false
+	}
+	if h.forbidden == nil {
+		h.forbidden = make(map[string]*forbiddenObject)
+	}
+	h.forbidden[key] = &forbiddenObject{
+		object:     fmt.Sprintf("%s %s", obj.GetObjectKind().GroupVersionKind().Kind, client.ObjectKeyFromObject(obj)),
+		permission: permission,
+		probeAt:    time.Now().Add(h.forbiddenProbeInterval),
+	}
+	return true
+}
+
+// missingPermission returns the permission denied by the RBAC authorizer, e.g.
+// "patch servicemonitors.monitoring.coreos.com in namespace ns", if err is such a denial.
+func missingPermission(err error) (string, bool) {
+	if !apierrors.IsForbidden(err) {
+		return "", false
+	}
+	m := rbacDenial.FindStringSubmatch(err.Error())
+	if m == nil {
+		return "", false
+	}
+	verb, resource, group, namespace := m[1], m[2], m[3], m[4]
+	if group != "" {
+		resource += "." + group
+	}
+	if namespace == "" {
+		return fmt.Sprintf("%s %s", verb, resource), true
+	}
+	return fmt.Sprintf("%s %s in namespace %s", verb, resource, namespace), true
+}
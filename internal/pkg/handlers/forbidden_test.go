@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestHandler_MissingPermissions(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	var denied error
+	var patches int
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patches++
+			if denied != nil {
+				return denied
+			}
+			return emulateApply(ctx, c, obj, patch, opts...)
+		},
+	}).Build()
+	h := NewHandler(c, scheme.Scheme, logr.New(log.NullLogSink{}))
+	h.SetApplyRetryBudget(1)
+	h.SetForbiddenProbeInterval(time.Hour)
+
+	owner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "uid", Generation: 1}}
+	objs := func() []client.Object {
+		return []client.Object{&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: namespace},
+		}}
+	}
+
+	denied = apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "cm",
+		errors.New(`User "system:serviceaccount:ops:operator" cannot patch resource "configmaps" in API group "" in the namespace "test"`))
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, objs()); errCount != 0 {
+		t.Fatalf("expected missing permissions to not be counted as errors, got %d", errCount)
+	}
+	requeueAfter, err := h.MissingPermissions(owner)
+	if !errors.Is(err, ErrMissingPermissions) {
+		t.Fatalf("expected ErrMissingPermissions, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "patch configmaps in namespace test") {
+		t.Errorf("expected the error to name the denied permission, got %q", err.Error())
+	}
+	if requeueAfter <= 0 || requeueAfter > time.Hour {
+		t.Errorf("expected to requeue at the next probe, got %s", requeueAfter)
+	}
+	if err := h.ApplyBlocked(owner); err != nil {
+		t.Errorf("expected missing permissions to not count against the retry budget, got %v", err)
+	}
+
+	// the object is not applied again until the next probe
+	h.CreateOrUpdate(ctx, namespace, owner, objs())
+	if patches != 1 {
+		t.Errorf("expected the object to not be applied before the next probe, got %d patches", patches)
+	}
+
+	// once the permissions are granted, the probe succeeds
+	denied = nil
+	for _, f := range h.forbidden {
+		f.probeAt = time.Now()
+	}
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, objs()); errCount != 0 {
+		t.Fatalf("expected the probe to succeed, got %d errors", errCount)
+	}
+	if _, err := h.MissingPermissions(owner); err != nil {
+		t.Errorf("expected no missing permissions after the probe succeeded, got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "cm"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("expected the object to be applied by the probe, got %v", err)
+	}
+}
+
+func TestHandler_MissingPermissionsDisabled(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+			return apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "cm",
+				errors.New(`User "system:serviceaccount:ops:operator" cannot patch resource "configmaps" in API group "" in the namespace "test"`))
+		},
+	}).Build()
+	h := NewHandler(c, scheme.Scheme, logr.New(log.NullLogSink{}))
+
+	owner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "uid", Generation: 1}}
+	cm := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: namespace},
+	}
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, []client.Object{cm}); errCount != 1 {
+		t.Errorf("expected missing permissions to be counted as an error without a probe interval, got %d", errCount)
+	}
+	if _, err := h.MissingPermissions(owner); err != nil {
+		t.Errorf("expected no missing permissions without a probe interval, got %v", err)
+	}
+}
+
+func TestMissingPermission(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "namespaced resource",
+			err: apierrors.NewForbidden(schema.GroupResource{Group: "monitoring.coreos.com", Resource: "servicemonitors"}, "sm",
+				errors.New(`User "system:serviceaccount:ops:operator" cannot patch resource "servicemonitors" in API group "monitoring.coreos.com" in the namespace "test"`)),
+			want: "patch servicemonitors.monitoring.coreos.com in namespace test",
+		},
+		{
+			name: "cluster scoped resource",
+			err: apierrors.NewForbidden(schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}, "role",
+				errors.New(`User "system:serviceaccount:ops:operator" cannot create resource "clusterroles" in API group "rbac.authorization.k8s.io" at the cluster scope`)),
+			want: "create clusterroles.rbac.authorization.k8s.io",
+		},
+		{
+			name: "exceeded quota",
+			err: apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "cm",
+				errors.New("exceeded quota: quota, requested: count/configmaps=1, used: count/configmaps=10, limited: count/configmaps=10")),
+		},
+		{
+			name: "not forbidden",
+			err:  apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "cm"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := missingPermission(tt.err)
+			if ok != (tt.want != "") || got != tt.want {
+				t.Errorf("missingPermission() = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
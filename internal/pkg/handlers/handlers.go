@@ -2,25 +2,34 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/internal/pkg/imagepolicy"
+	"github.com/thanos-community/thanos-operator/internal/pkg/logsampling"
+	"github.com/thanos-community/thanos-operator/internal/pkg/maintenance"
+	"github.com/thanos-community/thanos-operator/internal/pkg/profile"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	policyv1 "k8s.io/api/policy/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/strings/slices"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 type Handler struct {
@@ -32,7 +41,26 @@ type handler struct {
 	scheme *runtime.Scheme
 	logger logr.Logger
 
-	gatedGVK []schema.GroupVersionKind
+	gatedGVK    []schema.GroupVersionKind
+	imagePolicy *imagepolicy.Policy
+	maintenance *maintenance.Detector
+
+	applyRetryBudget int
+	applyMu          sync.Mutex
+	applyFailures    map[string]*applyFailure
+
+	logSampler     *logsampling.Sampler
+	summaryMu      sync.Mutex
+	applySummaries map[types.UID]*applySummary
+
+	writeLimit  int
+	writeWindow time.Duration
+	writesMu    sync.Mutex
+	writes      map[types.UID]*writeWindow
+
+	forbiddenProbeInterval time.Duration
+	forbiddenMu            sync.Mutex
+	forbidden              map[string]*forbiddenObject
 }
 
 // resourcePruner creates an object that prunes resources in the Kubernetes cluster.
@@ -58,39 +86,126 @@ func (h *Handler) SetFeatureGates(gvk []schema.GroupVersionKind) {
 	h.gatedGVK = gvk
 }
 
-// CreateOrUpdate creates or updates the given objects in the Kubernetes cluster.
+// SetImagePolicy sets the image policy for the handler.
+// Images of workloads passed to ApplyImagePolicy will be resolved and verified against the policy.
+func (h *Handler) SetImagePolicy(policy *imagepolicy.Policy) {
+	h.imagePolicy = policy
+}
+
+// ApplyImagePolicy resolves and verifies the container images of the given workloads in place.
+// It is a no-op if no image policy is set.
+// The returned error wraps imagepolicy.ErrBlocked if an image does not satisfy the policy.
+func (h *Handler) ApplyImagePolicy(ctx context.Context, objs []client.Object) error {
+	defer profile.FromContext(ctx).Start("image-policy")()
+	return h.imagePolicy.Apply(ctx, objs)
+}
+
+// CreateOrUpdate server-side applies the given objects to the Kubernetes cluster with FieldManager as field manager,
+// so that the operator only owns the fields it sets. Existing objects which would not change are not written.
 // It sets the owner reference of each object to the given owner.
-// It logs the operation and any errors encountered.
+// Objects with colliding ports, see manifests.ValidatePorts, are not applied and are counted as errors.
+// Objects which exhausted the apply retry budget for the current generation of the owner are skipped and counted as errors.
+// Objects which would exceed the write limit of the owner are skipped and reported by ApplyThrottled.
+// Objects which the operator lacks the RBAC permissions to apply are skipped until the next probe and reported by
+// MissingPermissions, if a probe interval is set, see SetForbiddenProbeInterval.
+// Objects whose immutable fields changed, see manifests.ImmutableFieldsChanged, are deleted and recreated.
+// It logs the operation and any errors encountered, rate limited by the log sampler,
+// and records the outcomes for the summary logged by LogApplySummary.
 // It returns the number of errors encountered.
 func (h *Handler) CreateOrUpdate(ctx context.Context, namespace string, owner client.Object, objs []client.Object) int {
 	var errCount int
 	for _, obj := range objs {
 		logger := loggerForObj(h.logger, obj)
 		if h.IsFeatureGated(obj) {
-			logger.V(1).Info("resource is feature gated, skipping")
+			h.sampledInfo(logger, obj, "resource is feature gated, skipping")
+			h.recordSummary(owner, recordSkipped)
 			continue
 		}
 
 		if manifests.IsNamespacedResource(obj) {
 			obj.SetNamespace(namespace)
 			if err := ctrl.SetControllerReference(owner, obj, h.scheme); err != nil {
-				logger.Error(err, "failed to set controller owner reference to resource")
+				h.sampledError(logger, obj, err, "failed to set controller owner reference to resource")
+				h.recordSummary(owner, recordFailed)
 				errCount++
 				continue
 			}
 		}
 
+		if err := manifests.ValidatePorts(obj); err != nil {
+			h.sampledError(logger, obj, err, "resource has colliding ports, skipping")
+			h.recordSummary(owner, recordFailed)
+			errCount++
+			continue
+		}
+
+		if h.isForbidden(owner, obj) {
+			h.sampledInfo(logger, obj, "operator is missing permissions to apply resource, skipping until the next probe")
+			h.recordSummary(owner, recordSkipped)
+			continue
+		}
+
+		if h.isApplyBlocked(owner, obj) {
+			h.sampledInfo(logger, obj, "resource failed to apply repeatedly, skipping until the owner changes")
+			h.recordSummary(owner, recordFailed)
+			errCount++
+			continue
+		}
+
+		manifests.AddManagementLabels(obj)
+		if err := h.setAppliedHash(obj); err != nil {
+			h.sampledError(logger, obj, err, "failed to create or update resource")
+			h.recordSummary(owner, recordFailed)
+			errCount++
+			continue
+		}
 		desired := obj.DeepCopyObject().(client.Object)
-		mutateFn := manifests.MutateFuncFor(obj, desired)
 
-		op, err := ctrl.CreateOrUpdate(ctx, h.client, obj, mutateFn)
+		stop := profile.FromContext(ctx).Start("apply/" + obj.GetObjectKind().GroupVersionKind().Kind)
+		op := controllerutil.OperationResultNone
+		sent := true
+		existing, err := h.getExisting(ctx, obj)
+		switch {
+		case err != nil:
+		case isApplied(existing, obj):
+			sent = false
+		case existing != nil && manifests.ImmutableFieldsChanged(existing, desired):
+			var recreated bool
+			recreated, err = h.recreate(ctx, h.writeClientFor(owner), existing, desired)
+			if err == nil && !recreated {
+				stop()
+				h.sampledInfo(logger, obj, "resource is being deleted to be recreated, deferring")
+				h.recordSummary(owner, recordSkipped)
+				continue
+			}
+			op = controllerutil.OperationResultCreated
+		default:
+			op, err = h.apply(ctx, h.writeClientFor(owner), existing, obj)
+			if err == nil && op != controllerutil.OperationResultNone {
+				h.countWrite(owner)
+			}
+		}
+		stop()
+		if errors.Is(err, ErrApplyThrottled) {
+			h.sampledInfo(logger, obj, "resource write limit reached, deferring")
+			h.recordSummary(owner, recordSkipped)
+			continue
+		}
+		if h.recordForbidden(owner, obj, err) {
+			h.sampledError(logger, obj, err, "operator is missing permissions to apply resource, skipping until the next probe")
+			h.recordSummary(owner, recordFailed)
+			continue
+		}
+		h.recordApply(owner, obj, err)
 
 		if err != nil {
-			logger.Error(err, "failed to create or update resource")
+			h.sampledError(logger, obj, err, "failed to create or update resource")
+			h.recordSummary(owner, recordFailed)
 			errCount++
 			continue
 		}
-		logger.V(1).Info("resource configured", "operation", op)
+		h.sampledInfo(logger, obj, "resource configured", "operation", op)
+		h.recordSummary(owner, recordOperation(op, sent))
 	}
 	return errCount
 }
@@ -121,7 +236,7 @@ func (h *Handler) DeleteResource(ctx context.Context, objs []client.Object) int
 
 		err := h.client.Get(ctx, client.ObjectKeyFromObject(obj), obj)
 		if err != nil {
-			if errors.IsNotFound(err) {
+			if apierrors.IsNotFound(err) {
 				continue
 			}
 
@@ -143,7 +258,7 @@ func (h *Handler) DeleteResource(ctx context.Context, objs []client.Object) int
 // If the item does not exist, it does not return an error.
 func (h *handler) deleteResource(ctx context.Context, obj client.Object) error {
 	logger := loggerForObj(h.logger, obj)
-	if err := h.client.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+	if err := h.client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
 		logger.Error(err, "failed to delete resource")
 		return err
 	}
@@ -223,6 +338,7 @@ func (r *resourcePruner) WithPodDisruptionBudget() *resourcePruner {
 // It logs the operation and any errors encountered.
 // It returns the number of errors encountered.
 func (r *resourcePruner) Prune(ctx context.Context, keepResourceNames []string, listOpts ...client.ListOption) int {
+	defer profile.FromContext(ctx).Start("prune")()
 	var errCount int
 	deleteOrphanedResources := func(obj client.Object) error {
 		if !slices.Contains(keepResourceNames, obj.GetName()) {
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"maps"
 	"slices"
 	"testing"
 
@@ -11,12 +13,16 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -34,11 +40,59 @@ func (fc *fakeClientWithError) List(ctx context.Context, objs client.ObjectList,
 	return fc.Client.List(ctx, objs)
 }
 
-func (fc *fakeClientWithError) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+func (fc *fakeClientWithError) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
 	if fc.shouldError {
 		return fmt.Errorf("error")
 	}
-	return fc.Client.Update(ctx, obj)
+	return fc.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// newApplyClient returns a fake client holding the given objects, which emulates server-side apply, see emulateApply.
+func newApplyClient(objs ...client.Object) client.WithWatch {
+	return fake.NewClientBuilder().WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{Patch: emulateApply}).Build()
+}
+
+// newDefaultingApplyClient returns a fake client like newApplyClient, which also defaults the protocol of Service ports
+// like the API server. Applying an unchanged Service which does not set the protocol leaves it unchanged.
+func newDefaultingApplyClient(objs ...client.Object) client.WithWatch {
+	return fake.NewClientBuilder().WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			svc, ok := obj.(*corev1.Service)
+			if !ok || patch.Type() != types.ApplyPatchType {
+				return emulateApply(ctx, c, obj, patch, opts...)
+			}
+			for i := range svc.Spec.Ports {
+				svc.Spec.Ports[i].Protocol = cmp.Or(svc.Spec.Ports[i].Protocol, corev1.ProtocolTCP)
+			}
+			existing := &corev1.Service{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(svc), existing); err == nil &&
+				equality.Semantic.DeepEqual(existing.Spec, svc.Spec) &&
+				maps.Equal(existing.GetLabels(), svc.GetLabels()) &&
+				maps.Equal(existing.GetAnnotations(), svc.GetAnnotations()) {
+				existing.DeepCopyInto(svc)
+				return nil
+			}
+			return emulateApply(ctx, c, obj, patch, opts...)
+		},
+	}).Build()
+}
+
+// emulateApply emulates server-side apply, which the fake client does not support, by creating the object
+// or replacing the existing object.
+func emulateApply(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	existing := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return c.Create(ctx, obj)
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	obj.SetCreationTimestamp(existing.GetCreationTimestamp())
+	return c.Update(ctx, obj)
 }
 
 func TestHandler_CreateOrUpdate(t *testing.T) {
@@ -49,27 +103,31 @@ func TestHandler_CreateOrUpdate(t *testing.T) {
 	)
 
 	owner := &appsv1.StatefulSet{}
-	runTimeSts := &appsv1.StatefulSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-	}
-
-	baseObjects := []client.Object{
-		runTimeSts,
-		&corev1.Service{
+	runTimeSts := func() *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      name,
 				Namespace: namespace,
 			},
-		},
+		}
+	}
+
+	baseObjects := func() []client.Object {
+		return []client.Object{
+			runTimeSts(),
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+			},
+		}
 	}
 
 	for _, tc := range []struct {
 		name           string
 		h              func() *Handler
-		objs           []client.Object
+		objs           func() []client.Object
 		expectErrCount int
 	}{
 		{
@@ -77,7 +135,7 @@ func TestHandler_CreateOrUpdate(t *testing.T) {
 			h: func() *Handler {
 				return &Handler{
 					handler: &handler{
-						client: fake.NewFakeClient(),
+						client: newApplyClient(),
 						scheme: scheme.Scheme,
 						logger: logr.New(log.NullLogSink{}),
 					},
@@ -88,16 +146,18 @@ func TestHandler_CreateOrUpdate(t *testing.T) {
 		{
 			name: "test error on update returns correct error count",
 			h: func() *Handler {
-				return &Handler{
+				c := &fakeClientWithError{Client: newApplyClient()}
+				h := &Handler{
 					handler: &handler{
-						client: &fakeClientWithError{
-							Client:      fake.NewFakeClient(runTimeSts),
-							shouldError: true,
-						},
+						client: c,
 						scheme: scheme.Scheme,
 						logger: logr.New(log.NullLogSink{}),
 					},
 				}
+				// the StatefulSet was applied before and is unchanged
+				h.CreateOrUpdate(ctx, namespace, owner, []client.Object{runTimeSts()})
+				c.shouldError = true
+				return h
 			},
 			objs:           baseObjects,
 			expectErrCount: 1,
@@ -105,7 +165,7 @@ func TestHandler_CreateOrUpdate(t *testing.T) {
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			h := tc.h()
-			errCount := h.CreateOrUpdate(ctx, namespace, owner, tc.objs)
+			errCount := h.CreateOrUpdate(ctx, namespace, owner, tc.objs())
 			if errCount != tc.expectErrCount {
 				t.Errorf("expected %d errors, got %d", tc.expectErrCount, errCount)
 			}
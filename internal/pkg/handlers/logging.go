@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/thanos-community/thanos-operator/internal/pkg/logsampling"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// applySummary counts the outcomes of applying the objects of an owner.
+type applySummary struct {
+	created, updated, reapplied, unchanged, skipped, failed int
+}
+
+// SetLogSampler sets the sampler used to rate limit the messages logged per object, which repeat on every reconciliation.
+// A nil sampler, the default, logs every message.
+func (h *Handler) SetLogSampler(sampler *logsampling.Sampler) {
+	h.logSampler = sampler
+}
+
+// LogApplySummary logs a single structured message summarizing the outcome of applying the objects of the given owner
+// since the last summary, along with the time elapsed since start. It logs nothing if no object was applied.
+// Controllers call it once per reconciliation.
+// It returns true if objects were applied and no request was sent for any of them, as they were unchanged since they were
+// last applied, skipped, or failed before being sent, i.e. the reconciliation was a no-op.
+func (h *Handler) LogApplySummary(owner client.Object, start time.Time) bool {
+	h.summaryMu.Lock()
+	s, ok := h.applySummaries[owner.GetUID()]
+	delete(h.applySummaries, owner.GetUID())
+	h.summaryMu.Unlock()
+	if !ok {
+		return false
+	}
+
+	// objects returned by the client do not have their TypeMeta set
+	var kind string
+	if gvk, err := apiutil.GVKForObject(owner, h.scheme); err == nil {
+		kind = gvk.Kind
+	}
+	h.logger.Info("reconcile summary",
+		"kind", kind,
+		"name", owner.GetName(),
+		"namespace", owner.GetNamespace(),
+		"created", s.created,
+		"updated", s.updated,
+		"reapplied", s.reapplied,
+		"unchanged", s.unchanged,
+		"skipped", s.skipped,
+		"failed", s.failed,
+		"duration", time.Since(start).String(),
+	)
+	return s.created == 0 && s.updated == 0 && s.reapplied == 0 && s.failed == 0
+}
+
+// recordSummary records the outcome of applying an object of the given owner.
+func (h *handler) recordSummary(owner client.Object, record func(s *applySummary)) {
+	h.summaryMu.Lock()
+	defer h.summaryMu.Unlock()
+
+	if h.applySummaries == nil {
+		h.applySummaries = make(map[types.UID]*applySummary)
+	}
+	s, ok := h.applySummaries[owner.GetUID()]
+	if !ok {
+		s = &applySummary{}
+		h.applySummaries[owner.GetUID()] = s
+	}
+	record(s)
+}
+
+// recordOperation records the operation of an object which was applied if sent is true, and was unchanged since
+// it was last applied otherwise. Objects applied without being changed are recorded as reapplied.
+func recordOperation(op controllerutil.OperationResult, sent bool) func(s *applySummary) {
+	return func(s *applySummary) {
+		switch {
+		case !sent:
+			s.unchanged++
+		case op == controllerutil.OperationResultCreated:
+			s.created++
+		case op == controllerutil.OperationResultNone:
+			s.reapplied++
+		default:
+			s.updated++
+		}
+	}
+}
+
+func recordSkipped(s *applySummary) { s.skipped++ }
+
+func recordFailed(s *applySummary) { s.failed++ }
+
+// sampledInfo logs the message for the object at V(1), unless the sampler suppresses it.
+// The number of occurrences suppressed since the message was last logged is added to the message.
+func (h *handler) sampledInfo(logger logr.Logger, obj client.Object, msg string, keysAndValues ...any) {
+	if ok, suppressed := h.logSampler.Allow(sampleKey(obj, msg, keysAndValues...)); ok {
+		logger.V(1).Info(msg, withSuppressed(keysAndValues, suppressed)...)
+	}
+}
+
+// sampledError logs the error for the object, unless the sampler suppresses it.
+// The number of occurrences suppressed since the message was last logged is added to the message.
+func (h *handler) sampledError(logger logr.Logger, obj client.Object, err error, msg string) {
+	if ok, suppressed := h.logSampler.Allow(sampleKey(obj, msg)); ok {
+		logger.Error(err, msg, withSuppressed(nil, suppressed)...)
+	}
+}
+
+func sampleKey(obj client.Object, msg string, keysAndValues ...any) string {
+	return fmt.Sprintf("%s/%s/%s/%s%v", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), msg, keysAndValues)
+}
+
+func withSuppressed(keysAndValues []any, suppressed int) []any {
+	if suppressed == 0 {
+		return keysAndValues
+	}
+	return append(keysAndValues, "suppressed", suppressed)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+
+	"github.com/thanos-community/thanos-operator/internal/pkg/logsampling"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestHandler_LogSamplingAndSummary(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{Verbosity: 1})
+
+	h := NewHandler(newApplyClient(), scheme.Scheme, logger)
+	h.SetLogSampler(logsampling.NewSampler(time.Hour))
+
+	owner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "uid"}}
+	objs := func() []client.Object {
+		return []client.Object{
+			&corev1.Service{TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: namespace}},
+			&corev1.Service{TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: namespace}},
+		}
+	}
+
+	for range 3 {
+		if errCount := h.CreateOrUpdate(ctx, namespace, owner, objs()); errCount != 0 {
+			t.Fatalf("expected no errors, got %d", errCount)
+		}
+	}
+
+	var configured int
+	for _, l := range lines {
+		if strings.Contains(l, `"resource configured"`) {
+			configured++
+		}
+	}
+	// created once, then unchanged twice, which is logged once per object before being suppressed
+	if configured != 4 {
+		t.Errorf("expected 4 resource configured messages, got %d: %v", configured, lines)
+	}
+
+	lines = nil
+	if h.LogApplySummary(owner, time.Now()) {
+		t.Error("expected reconciliations which created objects to not be a no-op")
+	}
+	if len(lines) != 1 {
+		t.Fatalf("expected a single summary message, got %v", lines)
+	}
+	for _, kv := range []string{`"kind"="StatefulSet"`, `"created"=2`, `"unchanged"=4`, `"failed"=0`} {
+		if !strings.Contains(lines[0], kv) {
+			t.Errorf("expected summary to contain %s, got %s", kv, lines[0])
+		}
+	}
+
+	lines = nil
+	if h.LogApplySummary(owner, time.Now()) {
+		t.Error("expected reconciliations without applied objects to not be a no-op")
+	}
+	if len(lines) != 0 {
+		t.Errorf("expected no summary without applied objects, got %v", lines)
+	}
+
+	h.CreateOrUpdate(ctx, namespace, owner, objs())
+	if !h.LogApplySummary(owner, time.Now()) {
+		t.Error("expected reconciliations which left all objects unchanged to be a no-op")
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thanos-community/thanos-operator/internal/pkg/maintenance"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// SetMaintenance sets the detector of declared cluster maintenance for the handler.
+// Disruptive rollouts coordinated by CoordinateRollout are deferred while maintenance is detected.
+func (h *Handler) SetMaintenance(detector *maintenance.Detector) {
+	h.maintenance = detector
+}
+
+// deferForMaintenance returns an error wrapping ErrRolloutDeferred if maintenance deferring rollouts
+// of the kind of the owner is detected.
+func (h *Handler) deferForMaintenance(ctx context.Context, owner client.Object) error {
+	if h.maintenance == nil {
+		return nil
+	}
+
+	gvk, err := apiutil.GVKForObject(owner, h.scheme)
+	if err != nil {
+		return fmt.Errorf("failed to get kind of owner: %w", err)
+	}
+	reason, err := h.maintenance.Active(ctx, gvk.Kind, time.Now())
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return fmt.Errorf("%w: %s/%s is waiting for cluster maintenance to end: %s", ErrRolloutDeferred, gvk.Kind, owner.GetName(), reason)
+	}
+	return nil
+}
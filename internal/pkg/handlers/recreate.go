@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recreate deletes the existing object, which cannot be updated to the desired object because of immutable fields,
+// see manifests.ImmutableFieldsChanged, and creates the desired object in its place.
+// Workloads whose selector is unchanged are deleted orphaning their pods, so that the recreated workload adopts them.
+// Otherwise, the dependents of the object are deleted in the background.
+// The delete and create count as a single write against the write limit of the owner.
+// It returns false if the existing object is still being deleted. Its deletion triggers another reconciliation
+// of the owner, which creates the object.
+func (h *handler) recreate(ctx context.Context, c client.Client, existing, desired client.Object) (bool, error) {
+	// the write is counted with the delete, as a throttled create would leave the object deleted until the next window
+	tc, throttled := c.(*throttledClient)
+	if throttled {
+		c = tc.Client
+	}
+
+	if existing.GetDeletionTimestamp() == nil {
+		if throttled && !h.writeAllowed(tc.owner) {
+			return false, ErrApplyThrottled
+		}
+		policy := recreatePropagationPolicy(existing, desired)
+		if err := c.Delete(ctx, existing, client.PropagationPolicy(policy), client.Preconditions{UID: ptr.To(existing.GetUID())}); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("failed to delete resource to recreate it: %w", err)
+		}
+		if throttled {
+			h.countWrite(tc.owner)
+		}
+		loggerForObj(h.logger, existing).Info("deleted resource to recreate it with changed immutable fields", "propagationPolicy", policy)
+	}
+
+	obj := desired.DeepCopyObject().(client.Object)
+	obj.SetResourceVersion("")
+	if err := c.Create(ctx, obj, client.FieldOwner(FieldManager)); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// recreatePropagationPolicy returns the propagation policy used to delete the existing object before it is recreated.
+func recreatePropagationPolicy(existing, desired client.Object) metav1.DeletionPropagation {
+	switch e := existing.(type) {
+	case *appsv1.StatefulSet:
+		if d, ok := desired.(*appsv1.StatefulSet); ok && equality.Semantic.DeepEqual(e.Spec.Selector, d.Spec.Selector) {
+			return metav1.DeletePropagationOrphan
+		}
+	case *appsv1.Deployment:
+		if d, ok := desired.(*appsv1.Deployment); ok && equality.Semantic.DeepEqual(e.Spec.Selector, d.Spec.Selector) {
+			return metav1.DeletePropagationOrphan
+		}
+	}
+	return metav1.DeletePropagationBackground
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// recordingClient records the options of the last delete, and optionally rejects patches as invalid.
+type recordingClient struct {
+	client.Client
+	rejectPatches bool
+	deleteOpts    *client.DeleteOptions
+}
+
+func (c *recordingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if c.rejectPatches {
+		return apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "StatefulSet"}, obj.GetName(), field.ErrorList{
+			field.Invalid(field.NewPath("spec", "template", "metadata", "labels"), nil, "`selector` does not match template `labels`"),
+		})
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *recordingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.deleteOpts = (&client.DeleteOptions{}).ApplyOptions(opts)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func TestHandler_CreateOrUpdateRecreates(t *testing.T) {
+	ctx := context.Background()
+	const (
+		namespace = "test"
+		name      = "test"
+	)
+
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "owner-uid"}}
+	sts := func(app, storageClass string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "data"},
+						Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: ptr.To(storageClass)},
+					},
+				},
+			},
+		}
+	}
+	existing := func(app, storageClass string) *appsv1.StatefulSet {
+		s := sts(app, storageClass)
+		s.SetUID("existing-uid")
+		s.SetCreationTimestamp(metav1.Now())
+		return s
+	}
+
+	for _, tc := range []struct {
+		name          string
+		existing      *appsv1.StatefulSet
+		desired       *appsv1.StatefulSet
+		rejectPatches bool
+		expectApp     string
+		expectPolicy  metav1.DeletionPropagation
+	}{
+		{
+			name:         "recreates statefulset with changed selector deleting its pods",
+			existing:     existing("old", "standard"),
+			desired:      sts("new", "standard"),
+			expectApp:    "new",
+			expectPolicy: metav1.DeletePropagationBackground,
+		},
+		{
+			name:          "recreates statefulset without patching it",
+			existing:      existing("old", "standard"),
+			desired:       sts("new", "standard"),
+			rejectPatches: true,
+			expectApp:     "new",
+			expectPolicy:  metav1.DeletePropagationBackground,
+		},
+		{
+			name:         "recreates statefulset with changed storage class orphaning its pods",
+			existing:     existing("old", "standard"),
+			desired:      sts("old", "fast"),
+			expectApp:    "old",
+			expectPolicy: metav1.DeletePropagationOrphan,
+		},
+		{
+			name:      "keeps unchanged statefulset",
+			existing:  existing("old", "standard"),
+			desired:   sts("old", "standard"),
+			expectApp: "old",
+		},
+		{
+			name: "defers while statefulset is being deleted",
+			existing: func() *appsv1.StatefulSet {
+				s := existing("old", "standard")
+				s.SetFinalizers([]string{"orphan"})
+				s.SetDeletionTimestamp(&metav1.Time{Time: metav1.Now().Time})
+				return s
+			}(),
+			desired:   sts("new", "standard"),
+			expectApp: "old",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &recordingClient{Client: newApplyClient(tc.existing), rejectPatches: tc.rejectPatches}
+			h := &Handler{
+				handler: &handler{
+					client: c,
+					scheme: scheme.Scheme,
+					logger: logr.New(log.NullLogSink{}),
+				},
+			}
+
+			if errCount := h.CreateOrUpdate(ctx, namespace, owner, []client.Object{tc.desired}); errCount != 0 {
+				t.Fatalf("expected no errors, got %d", errCount)
+			}
+
+			got := &appsv1.StatefulSet{}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, got); err != nil {
+				t.Fatalf("failed to get statefulset: %v", err)
+			}
+			if app := got.Spec.Selector.MatchLabels["app"]; app != tc.expectApp {
+				t.Errorf("expected selector app=%s, got app=%s", tc.expectApp, app)
+			}
+
+			if tc.expectPolicy == "" {
+				if c.deleteOpts != nil {
+					t.Errorf("expected statefulset to not be deleted")
+				}
+				return
+			}
+			if c.deleteOpts == nil || c.deleteOpts.PropagationPolicy == nil || *c.deleteOpts.PropagationPolicy != tc.expectPolicy {
+				t.Errorf("expected statefulset to be deleted with propagation policy %s, got %v", tc.expectPolicy, c.deleteOpts)
+			}
+		})
+	}
+}
+
+func TestHandler_CreateOrUpdateRecreateThrottled(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "owner-uid"}}
+	sts := func(app string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace},
+			Spec:       appsv1.StatefulSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}},
+		}
+	}
+	existing := sts("old")
+	existing.SetUID("existing-uid")
+	existing.SetCreationTimestamp(metav1.Now())
+
+	c := &recordingClient{Client: newApplyClient(existing)}
+	h := NewHandler(c, scheme.Scheme, logr.New(log.NullLogSink{}))
+	h.SetWriteLimit(1, time.Hour)
+	// another object of the owner was written in the current window
+	h.countWrite(owner)
+
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, []client.Object{sts("new")}); errCount != 0 {
+		t.Fatalf("expected deferred writes to not be counted as errors, got %d", errCount)
+	}
+	if c.deleteOpts != nil {
+		t.Error("expected statefulset to not be deleted once the write limit is reached")
+	}
+	got := &appsv1.StatefulSet{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(existing), got); err != nil || got.Spec.Selector.MatchLabels["app"] != "old" {
+		t.Errorf("expected existing statefulset to be kept, got %v", err)
+	}
+
+	// in a new window, the statefulset is deleted and created with a single write
+	h.writes[owner.GetUID()].start = time.Now().Add(-time.Hour)
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, []client.Object{sts("new")}); errCount != 0 {
+		t.Fatalf("expected no errors, got %d", errCount)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(existing), got); err != nil || got.Spec.Selector.MatchLabels["app"] != "new" {
+		t.Errorf("expected statefulset to be recreated, got %v", err)
+	}
+	if _, err := h.ApplyThrottled(owner); err != nil {
+		t.Errorf("expected the recreate to fit into the write limit, got %v", err)
+	}
+}
+
+func TestRecreatePropagationPolicy(t *testing.T) {
+	sts := func(app string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}}}
+	}
+
+	if got := recreatePropagationPolicy(sts("a"), sts("a")); got != metav1.DeletePropagationOrphan {
+		t.Errorf("expected pods of statefulset with unchanged selector to be orphaned, got %s", got)
+	}
+	if got := recreatePropagationPolicy(sts("a"), sts("b")); got != metav1.DeletePropagationBackground {
+		t.Errorf("expected pods of statefulset with changed selector to be deleted, got %s", got)
+	}
+	if got := recreatePropagationPolicy(&corev1.Service{}, &corev1.Service{}); got != metav1.DeletePropagationBackground {
+		t.Errorf("expected service to be deleted in the background, got %s", got)
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/profile"
+	"github.com/thanos-community/thanos-operator/internal/pkg/rollback"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// PodTemplateHashAnnotation is set on managed workloads and holds a hash of the desired pod template.
+	// It is used to detect disruptive changes to the workload.
+	PodTemplateHashAnnotation = "monitoring.thanos.io/pod-template-hash"
+
+	// RolloutLeaseDuration is the time a member of a stack can hold the rollout lease.
+	// Once expired, other members of the stack may start their rollout, even if the holder did not complete its rollout.
+	RolloutLeaseDuration = 10 * time.Minute
+)
+
+// ErrRolloutDeferred is returned when a disruptive rollout must wait for another member of its stack to complete its rollout
+// or for cluster maintenance to end, or when a StatefulSet must wait for its deletion to complete before it is recreated.
+var ErrRolloutDeferred = errors.New("rollout deferred")
+
+// CoordinateRollout serializes disruptive rollouts across the members of the stack the owner belongs to.
+// A rollout is disruptive if the pod template of an existing Deployment or StatefulSet in objs changes.
+// Before rolling out, the owner must acquire the rollout lease of its stack, which is released by CompleteRollout.
+// It returns an error wrapping ErrRolloutDeferred if another member of the stack holds the lease,
+// or while cluster maintenance is detected, see SetMaintenance.
+// Owners which do not belong to a stack, identified by v1alpha1.StackLabel, are only deferred for maintenance.
+func (h *Handler) CoordinateRollout(ctx context.Context, owner client.Object, objs []client.Object) error {
+	defer profile.FromContext(ctx).Start("coordinate-rollout")()
+	var disruptive []string
+	for _, obj := range objs {
+		template := podTemplateFor(obj)
+		if template == nil {
+			continue
+		}
+
+		hash, err := hashPodTemplate(template)
+		if err != nil {
+			return err
+		}
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[PodTemplateHashAnnotation] = hash
+		obj.SetAnnotations(annotations)
+
+		current := obj.DeepCopyObject().(client.Object)
+		if err := h.client.Get(ctx, client.ObjectKey{Namespace: owner.GetNamespace(), Name: obj.GetName()}, current); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %s: %w", obj.GetName(), err)
+		}
+		if current.GetAnnotations()[PodTemplateHashAnnotation] != hash {
+			disruptive = append(disruptive, obj.GetName())
+		}
+	}
+
+	if len(disruptive) == 0 {
+		return nil
+	}
+	if err := h.deferForMaintenance(ctx, owner); err != nil {
+		return err
+	}
+
+	stack := owner.GetLabels()[v1alpha1.StackLabel]
+	if stack == "" {
+		return nil
+	}
+
+	holder, err := h.rolloutHolder(owner)
+	if err != nil {
+		return err
+	}
+	lease := &coordinationv1.Lease{}
+	key := client.ObjectKey{Namespace: owner.GetNamespace(), Name: rolloutLeaseName(stack)}
+	if err := h.client.Get(ctx, key, lease); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get rollout lease: %w", err)
+		}
+		lease = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+		acquireLease(lease, holder)
+		if err := h.client.Create(ctx, lease); err != nil {
+			return fmt.Errorf("failed to create rollout lease: %w", err)
+		}
+		return nil
+	}
+
+	current := ptr.Deref(lease.Spec.HolderIdentity, "")
+	if current == holder {
+		return nil
+	}
+	if current != "" && !leaseExpired(lease) {
+		return fmt.Errorf("%w: %s is waiting for %s to complete its rollout", ErrRolloutDeferred, holder, current)
+	}
+
+	acquireLease(lease, holder)
+	if err := h.client.Update(ctx, lease); err != nil {
+		return fmt.Errorf("failed to acquire rollout lease: %w", err)
+	}
+	return nil
+}
+
+// CompleteRollout releases the rollout lease of the stack the owner belongs to,
+// once all Deployments and StatefulSets controlled by the owner are rolled out.
+func (h *Handler) CompleteRollout(ctx context.Context, owner client.Object) error {
+	stack := owner.GetLabels()[v1alpha1.StackLabel]
+	if stack == "" {
+		return nil
+	}
+
+	holder, err := h.rolloutHolder(owner)
+	if err != nil {
+		return err
+	}
+	lease := &coordinationv1.Lease{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: owner.GetNamespace(), Name: rolloutLeaseName(stack)}, lease); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if ptr.Deref(lease.Spec.HolderIdentity, "") != holder {
+		return nil
+	}
+
+	rolledOut, err := h.isRolledOut(ctx, owner)
+	if err != nil || !rolledOut {
+		return err
+	}
+
+	lease.Spec.HolderIdentity = nil
+	lease.Spec.AcquireTime = nil
+	if err := h.client.Update(ctx, lease); err != nil {
+		return fmt.Errorf("failed to release rollout lease: %w", err)
+	}
+	return nil
+}
+
+// isRolledOut returns true if all Deployments and StatefulSets controlled by the owner are rolled out.
+func (h *Handler) isRolledOut(ctx context.Context, owner client.Object) (bool, error) {
+	deployments := &appsv1.DeploymentList{}
+	if err := h.client.List(ctx, deployments, client.InNamespace(owner.GetNamespace())); err != nil {
+		return false, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		if metav1.IsControlledBy(&deployments.Items[i], owner) && !rollback.IsRolledOut(&deployments.Items[i]) {
+			return false, nil
+		}
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := h.client.List(ctx, statefulSets, client.InNamespace(owner.GetNamespace())); err != nil {
+		return false, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		if metav1.IsControlledBy(&statefulSets.Items[i], owner) && !IsStatefulSetRolledOut(&statefulSets.Items[i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (h *Handler) rolloutHolder(owner client.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(owner, h.scheme)
+	if err != nil {
+		return "", fmt.Errorf("failed to get kind of owner: %w", err)
+	}
+	return fmt.Sprintf("%s/%s", gvk.Kind, owner.GetName()), nil
+}
+
+func rolloutLeaseName(stack string) string {
+	return manifests.ValidateAndSanitizeResourceName(fmt.Sprintf("thanos-stack-%s-rollout", stack))
+}
+
+func acquireLease(lease *coordinationv1.Lease, holder string) {
+	now := metav1.NowMicro()
+	lease.Spec.HolderIdentity = ptr.To(holder)
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(RolloutLeaseDuration.Seconds()))
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+}
+
+func leaseExpired(lease *coordinationv1.Lease) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return time.Now().After(expiry)
+}
+
+func podTemplateFor(obj client.Object) *corev1.PodTemplateSpec {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Template
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template
+	default:
+		return nil
+	}
+}
+
+func hashPodTemplate(template *corev1.PodTemplateSpec) (string, error) {
+	b, err := json.Marshal(template)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash pod template: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:16], nil
+}
+
+// IsStatefulSetRolledOut returns true if all replicas of the StatefulSet run its current revision and are ready.
+func IsStatefulSetRolledOut(sts *appsv1.StatefulSet) bool {
+	if sts.Status.ObservedGeneration < sts.GetGeneration() {
+		return false
+	}
+
+	replicas := ptr.Deref(sts.Spec.Replicas, 1)
+	return sts.Status.UpdatedReplicas == replicas &&
+		sts.Status.ReadyReplicas == replicas &&
+		sts.Status.CurrentRevision == sts.Status.UpdateRevision
+}
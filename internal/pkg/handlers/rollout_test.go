@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/internal/pkg/maintenance"
+
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestHandler_CoordinateRollout(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	newOwner := func(name string, labels map[string]string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				UID:       types.UID("uid-" + name),
+				Labels:    labels,
+			},
+		}
+	}
+	stack := map[string]string{v1alpha1.StackLabel: "a"}
+	query := newOwner("query", stack)
+	store := newOwner("store", stack)
+	standalone := newOwner("standalone", nil)
+
+	newDeployment := func(name, image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: map[string]string{PodTemplateHashAnnotation: "outdated"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(int32(1)),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "thanos", Image: image}}},
+				},
+			},
+		}
+	}
+
+	queryLive := newDeployment("query", "thanos:v1")
+	queryLive.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "StatefulSet", Name: query.GetName(), UID: query.GetUID(), Controller: ptr.To(true)},
+	}
+	c := fake.NewClientBuilder().
+		WithObjects(queryLive, newDeployment("store", "thanos:v1"), newDeployment("standalone", "thanos:v1")).
+		WithStatusSubresource(&appsv1.Deployment{}).
+		Build()
+	h := &Handler{
+		handler: &handler{
+			client: c,
+			scheme: scheme.Scheme,
+			logger: logr.New(log.NullLogSink{}),
+		},
+	}
+
+	holder := func() string {
+		lease := &coordinationv1.Lease{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: rolloutLeaseName("a")}, lease); err != nil {
+			t.Fatal(err)
+		}
+		return ptr.Deref(lease.Spec.HolderIdentity, "")
+	}
+
+	desiredQuery := newDeployment("query", "thanos:v2")
+	if err := h.CoordinateRollout(ctx, query, []client.Object{desiredQuery}); err != nil {
+		t.Fatalf("expected first disruptive rollout in the stack to proceed, got %v", err)
+	}
+	if holder() != "StatefulSet/query" {
+		t.Errorf("expected query to hold the rollout lease, got %q", holder())
+	}
+	if desiredQuery.GetAnnotations()[PodTemplateHashAnnotation] == "outdated" {
+		t.Error("expected pod template hash annotation to be set on the desired workload")
+	}
+
+	err := h.CoordinateRollout(ctx, store, []client.Object{newDeployment("store", "thanos:v2")})
+	if !errors.Is(err, ErrRolloutDeferred) {
+		t.Fatalf("expected concurrent disruptive rollout in the stack to be deferred, got %v", err)
+	}
+
+	if err := h.CoordinateRollout(ctx, store, []client.Object{newDeployment("store-new-shard", "thanos:v2")}); err != nil {
+		t.Errorf("expected creating a workload to not be deferred, got %v", err)
+	}
+	if err := h.CoordinateRollout(ctx, standalone, []client.Object{newDeployment("standalone", "thanos:v2")}); err != nil {
+		t.Errorf("expected rollout outside of a stack to not be deferred, got %v", err)
+	}
+
+	if err := h.CompleteRollout(ctx, query); err != nil {
+		t.Fatal(err)
+	}
+	if holder() != "StatefulSet/query" {
+		t.Errorf("expected lease to be held until the rollout completes, got %q", holder())
+	}
+
+	queryLive.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	if err := c.Status().Update(ctx, queryLive); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.CompleteRollout(ctx, query); err != nil {
+		t.Fatal(err)
+	}
+	if holder() != "" {
+		t.Errorf("expected lease to be released once the rollout completes, got %q", holder())
+	}
+
+	if err := h.CoordinateRollout(ctx, store, []client.Object{newDeployment("store", "thanos:v2")}); err != nil {
+		t.Errorf("expected deferred rollout to proceed once the lease is released, got %v", err)
+	}
+	if holder() != "StatefulSet/store" {
+		t.Errorf("expected store to hold the rollout lease, got %q", holder())
+	}
+}
+
+func TestHandler_CoordinateRolloutDuringMaintenance(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	owner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: namespace, UID: "uid-standalone"}}
+	newDeployment := func(image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "standalone",
+				Namespace:   namespace,
+				Annotations: map[string]string{PodTemplateHashAnnotation: "outdated"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "thanos", Image: image}}},
+				},
+			},
+		}
+	}
+	window := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "upgrade", Namespace: "maintenance", Labels: map[string]string{maintenance.WindowLabel: "true"}},
+		Data: map[string]string{
+			maintenance.StartKey: time.Now().Add(-time.Hour).Format(time.RFC3339),
+			maintenance.EndKey:   time.Now().Add(time.Hour).Format(time.RFC3339),
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(newDeployment("thanos:v1"), window).Build()
+	h := &Handler{
+		handler: &handler{
+			client: c,
+			scheme: scheme.Scheme,
+			logger: logr.New(log.NullLogSink{}),
+		},
+	}
+	h.SetMaintenance(maintenance.NewDetector(c, maintenance.Options{Namespace: "maintenance"}))
+
+	err := h.CoordinateRollout(ctx, owner, []client.Object{newDeployment("thanos:v2")})
+	if !errors.Is(err, ErrRolloutDeferred) {
+		t.Fatalf("expected disruptive rollout to be deferred during maintenance, got %v", err)
+	}
+
+	window.Data[maintenance.ComponentsKey] = "ThanosReceive"
+	if err := c.Update(ctx, window); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.CoordinateRollout(ctx, owner, []client.Object{newDeployment("thanos:v2")}); err != nil {
+		t.Errorf("expected rollout of a kind not covered by the window to proceed, got %v", err)
+	}
+}
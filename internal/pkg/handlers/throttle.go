@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrApplyThrottled is returned when objects of an owner were not created or updated because the owner
+// reached the write limit of the current window. They are applied once the window ends.
+var ErrApplyThrottled = errors.New("apply throttled")
+
+// writeWindow counts the objects of an owner written and deferred in the current window.
+type writeWindow struct {
+	start    time.Time
+	writes   int
+	deferred int
+}
+
+// SetWriteLimit sets the maximum number of objects of a single owner created or updated per window.
+// Further writes are deferred until the window ends, which spreads large changes, such as an update of the arguments
+// of many shards, over time. Only objects which were changed by the write are counted.
+// A limit of zero, the default, disables the write limit.
+func (h *Handler) SetWriteLimit(limit int, window time.Duration) {
+	h.writeLimit = limit
+	h.writeWindow = window
+}
+
+// ApplyThrottled returns an error wrapping ErrApplyThrottled if writes of objects of the given owner were deferred
+// since the last call, along with the time until the current window ends, after which the owner should be reconciled again.
+// Controllers call it once per reconciliation.
+func (h *Handler) ApplyThrottled(owner client.Object) (time.Duration, error) {
+	h.writesMu.Lock()
+	defer h.writesMu.Unlock()
+
+	w, ok := h.writes[owner.GetUID()]
+	if !ok || w.deferred == 0 {
+		return 0, nil
+	}
+	deferred := w.deferred
+	w.deferred = 0
+	return max(time.Until(w.start.Add(h.writeWindow)), time.Second),
+		fmt.Errorf("%w: %d object(s) not applied after reaching the limit of %d writes per %s", ErrApplyThrottled, deferred, h.writeLimit, h.writeWindow)
+}
+
+// writeAllowed returns true if the owner did not reach the write limit of the current window.
+// Otherwise, the write is counted as deferred.
+func (h *handler) writeAllowed(owner client.Object) bool {
+	h.writesMu.Lock()
+	defer h.writesMu.Unlock()
+
+	w := h.currentWindow(owner)
+	if w.writes >= h.writeLimit {
+		w.deferred++
+		return false
+	}
+	return true
+}
+
+// countWrite counts an object of the owner written in the current window.
+// Only objects which were actually changed are counted, so that unchanged objects applied on every reconciliation
+// do not use up the limit, which would defer the objects applied after them indefinitely.
+func (h *handler) countWrite(owner client.Object) {
+	if h.writeLimit <= 0 {
+		return
+	}
+	h.writesMu.Lock()
+	defer h.writesMu.Unlock()
+
+	h.currentWindow(owner).writes++
+}
+
+// currentWindow returns the write window of the owner, starting a new one if the last one ended.
+// It must be called with writesMu held.
+func (h *handler) currentWindow(owner client.Object) *writeWindow {
+	if h.writes == nil {
+		h.writes = make(map[types.UID]*writeWindow)
+	}
+	now := time.Now()
+	w, ok := h.writes[owner.GetUID()]
+	if !ok || now.Sub(w.start) >= h.writeWindow {
+		w = &writeWindow{start: now}
+		h.writes[owner.GetUID()] = w
+	}
+	return w
+}
+
+// writeClientFor returns the client used to create and update the objects of the owner,
+// which enforces the write limit if one is set.
+func (h *handler) writeClientFor(owner client.Object) client.Client {
+	if h.writeLimit <= 0 {
+		return h.client
+	}
+	return &throttledClient{Client: h.client, handler: h, owner: owner}
+}
+
+// throttledClient returns an error wrapping ErrApplyThrottled instead of creating, updating or patching objects
+// once the owner reached the write limit of the current window.
+type throttledClient struct {
+	client.Client
+	handler *handler
+	owner   client.Object
+}
+
+func (c *throttledClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if !c.handler.writeAllowed(c.owner) {
+		return ErrApplyThrottled
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *throttledClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if !c.handler.writeAllowed(c.owner) {
+		return ErrApplyThrottled
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *throttledClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if !c.handler.writeAllowed(c.owner) {
+		return ErrApplyThrottled
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestHandler_WriteLimit(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	c := newApplyClient()
+	h := NewHandler(c, scheme.Scheme, logr.New(log.NullLogSink{}))
+	h.SetApplyRetryBudget(1)
+	h.SetWriteLimit(2, time.Hour)
+
+	owner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "uid", Generation: 1}}
+	configMaps := func(value string) []client.Object {
+		objs := make([]client.Object, 3)
+		for i := range objs {
+			objs[i] = &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cm-%d", i), Namespace: namespace},
+				Data:       map[string]string{"key": value},
+			}
+		}
+		return objs
+	}
+
+	if errCount := h.CreateOrUpdate(ctx, namespace, owner, configMaps("a")); errCount != 0 {
+		t.Fatalf("expected deferred writes to not be counted as errors, got %d", errCount)
+	}
+	requeueAfter, err := h.ApplyThrottled(owner)
+	if !errors.Is(err, ErrApplyThrottled) {
+		t.Fatalf("expected ErrApplyThrottled, got %v", err)
+	}
+	if requeueAfter <= 0 || requeueAfter > time.Hour {
+		t.Errorf("expected to requeue once the window ends, got %s", requeueAfter)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "cm-2"}, &corev1.ConfigMap{}); err == nil {
+		t.Error("expected the write exceeding the limit to be deferred")
+	}
+	if err := h.ApplyBlocked(owner); err != nil {
+		t.Errorf("expected deferred writes to not count against the retry budget, got %v", err)
+	}
+	if _, err := h.ApplyThrottled(owner); err != nil {
+		t.Errorf("expected deferred writes to be reported once, got %v", err)
+	}
+
+	// a new window starts, in which unchanged objects are not counted
+	h.writes[owner.GetUID()].start = time.Now().Add(-time.Hour)
+	h.CreateOrUpdate(ctx, namespace, owner, configMaps("a"))
+	if _, err := h.ApplyThrottled(owner); err != nil {
+		t.Errorf("expected no deferred writes in a new window, got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "cm-2"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("expected the deferred write to be applied in a new window, got %v", err)
+	}
+
+	h.CreateOrUpdate(ctx, namespace, owner, configMaps("b"))
+	if _, err := h.ApplyThrottled(owner); err == nil || !strings.Contains(err.Error(), "2 object(s)") {
+		t.Errorf("expected 2 deferred writes, got %v", err)
+	}
+}
+
+func TestHandler_WriteLimitConverges(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	c := newDefaultingApplyClient()
+	h := NewHandler(c, scheme.Scheme, logr.New(log.NullLogSink{}))
+	h.SetWriteLimit(2, time.Hour)
+
+	owner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: namespace, UID: "uid"}}
+	services := func() []client.Object {
+		objs := make([]client.Object, 5)
+		for i := range objs {
+			objs[i] = &corev1.Service{
+				TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("svc-%d", i), Namespace: namespace},
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+			}
+		}
+		return objs
+	}
+
+	for window := 0; window < 3; window++ {
+		h.CreateOrUpdate(ctx, namespace, owner, services())
+		h.ApplyThrottled(owner)
+		h.writes[owner.GetUID()].start = time.Now().Add(-time.Hour)
+	}
+	list := &corev1.ServiceList{}
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 5 {
+		t.Errorf("expected all services to be applied within 3 windows, got %d", len(list.Items))
+	}
+
+	h.CreateOrUpdate(ctx, namespace, owner, services())
+	if _, err := h.ApplyThrottled(owner); err != nil {
+		t.Errorf("expected unchanged services to not count against the write limit, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// UpdateVolumeClaimMetadata adds the labels and annotations of the volume claim templates of the given StatefulSets
+// to the PersistentVolumeClaims already created for their replicas.
+// Volume claim templates are immutable, so claims of existing StatefulSets would otherwise never receive
+// labels or annotations added after their creation. Labels and annotations removed from a template are not removed
+// from the claims. Claims which do not exist yet are skipped. It returns the number of errors encountered.
+func (h *Handler) UpdateVolumeClaimMetadata(ctx context.Context, namespace string, objs []client.Object) int {
+	var errCount int
+	for _, obj := range objs {
+		sts, ok := obj.(*appsv1.StatefulSet)
+		if !ok {
+			continue
+		}
+		for _, tpl := range sts.Spec.VolumeClaimTemplates {
+			if len(tpl.GetLabels()) == 0 && len(tpl.GetAnnotations()) == 0 {
+				continue
+			}
+			for i := range ptr.Deref(sts.Spec.Replicas, 1) {
+				name := fmt.Sprintf("%s-%s-%d", tpl.GetName(), sts.GetName(), i)
+				if err := h.updateVolumeClaimMetadata(ctx, namespace, name, tpl.GetLabels(), tpl.GetAnnotations()); err != nil {
+					h.logger.Error(err, "failed to update metadata of persistent volume claim", "name", name, "namespace", namespace)
+					errCount++
+				}
+			}
+		}
+	}
+	return errCount
+}
+
+func (h *handler) updateVolumeClaimMetadata(ctx context.Context, namespace, name string, labels, annotations map[string]string) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	updatedLabels, labelsChanged := mergeInto(pvc.GetLabels(), labels)
+	updatedAnnotations, annotationsChanged := mergeInto(pvc.GetAnnotations(), annotations)
+	if !labelsChanged && !annotationsChanged {
+		return nil
+	}
+	pvc.SetLabels(updatedLabels)
+	pvc.SetAnnotations(updatedAnnotations)
+	return h.client.Update(ctx, pvc)
+}
+
+// mergeInto returns a copy of current with the entries of add, and whether it differs from current.
+func mergeInto(current, add map[string]string) (map[string]string, bool) {
+	if len(add) == 0 {
+		return current, false
+	}
+	updated := maps.Clone(current)
+	if updated == nil {
+		updated = make(map[string]string, len(add))
+	}
+	maps.Copy(updated, add)
+	return updated, !maps.Equal(current, updated)
+}
+
+// ResizeVolumeClaims grows the PersistentVolumeClaims of the given StatefulSets to the storage requested by their
+// volume claim templates. Volume claim templates are immutable, so a StatefulSet requesting more storage than the
+// existing one cannot be updated. Instead, all claims created from the grown templates are expanded, and the existing
+// StatefulSet is deleted, orphaning its pods, so that the next apply recreates it with the new templates and adopts the pods.
+// Claims are only expanded if the StorageClasses of all of them allow volume expansion, and volumes are never shrunk;
+// otherwise an error is returned and the StatefulSet is left untouched.
+// It returns an error wrapping ErrRolloutDeferred if a StatefulSet is being deleted to be recreated,
+// in which case the StatefulSet must be applied once its deletion completed.
+func (h *Handler) ResizeVolumeClaims(ctx context.Context, namespace string, objs []client.Object) error {
+	for _, obj := range objs {
+		desired, ok := obj.(*appsv1.StatefulSet)
+		if !ok {
+			continue
+		}
+		existing := &appsv1.StatefulSet{}
+		if err := h.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: desired.GetName()}, existing); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get StatefulSet %s: %w", desired.GetName(), err)
+		}
+		if existing.GetDeletionTimestamp() != nil {
+			return fmt.Errorf("%w: StatefulSet %s is being recreated to expand its volume claims", ErrRolloutDeferred, existing.GetName())
+		}
+
+		grown, err := grownVolumeClaimTemplates(existing, desired)
+		if err != nil {
+			return err
+		}
+		if len(grown) == 0 {
+			continue
+		}
+		if err := h.expandVolumeClaims(ctx, existing, grown); err != nil {
+			return err
+		}
+		if err := h.client.Delete(ctx, existing, client.PropagationPolicy(metav1.DeletePropagationOrphan)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete StatefulSet %s to expand its volume claims: %w", existing.GetName(), err)
+		}
+		h.logger.Info("deleted StatefulSet to recreate it with expanded volume claims", "name", existing.GetName(), "namespace", namespace)
+		return fmt.Errorf("%w: StatefulSet %s is being recreated to expand its volume claims", ErrRolloutDeferred, existing.GetName())
+	}
+	return nil
+}
+
+// grownVolumeClaimTemplates returns the storage requested by the volume claim templates of desired, keyed by template name,
+// which request more storage than the templates of existing. It returns an error if a template requests less storage.
+func grownVolumeClaimTemplates(existing, desired *appsv1.StatefulSet) (map[string]resource.Quantity, error) {
+	grown := make(map[string]resource.Quantity)
+	for _, tpl := range desired.Spec.VolumeClaimTemplates {
+		for _, current := range existing.Spec.VolumeClaimTemplates {
+			if current.GetName() != tpl.GetName() {
+				continue
+			}
+			want := tpl.Spec.Resources.Requests[corev1.ResourceStorage]
+			have := current.Spec.Resources.Requests[corev1.ResourceStorage]
+			switch want.Cmp(have) {
+			case 1:
+				grown[tpl.GetName()] = want
+			case -1:
+				return nil, fmt.Errorf("volume claim template %s of StatefulSet %s cannot shrink from %s to %s",
+					tpl.GetName(), existing.GetName(), have.String(), want.String())
+			}
+		}
+	}
+	return grown, nil
+}
+
+// expandVolumeClaims requests the given storage for the claims created from the volume claim templates of the StatefulSet,
+// including claims of replicas which were scaled down. The StorageClasses of all claims are checked before any claim is expanded.
+func (h *handler) expandVolumeClaims(ctx context.Context, sts *appsv1.StatefulSet, sizes map[string]resource.Quantity) error {
+	list := &corev1.PersistentVolumeClaimList{}
+	if err := h.client.List(ctx, list, client.InNamespace(sts.GetNamespace())); err != nil {
+		return fmt.Errorf("failed to list persistent volume claims: %w", err)
+	}
+
+	var claims []*corev1.PersistentVolumeClaim
+	for i := range list.Items {
+		pvc := &list.Items[i]
+		template, ok := volumeClaimTemplateOf(pvc.GetName(), sts)
+		if !ok {
+			continue
+		}
+		size, ok := sizes[template]
+		if !ok {
+			continue
+		}
+		if current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; current.Cmp(size) >= 0 {
+			continue
+		}
+		if err := h.checkVolumeExpansion(ctx, pvc); err != nil {
+			return err
+		}
+		claims = append(claims, pvc)
+	}
+
+	for _, pvc := range claims {
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		template, _ := volumeClaimTemplateOf(pvc.GetName(), sts)
+		size := sizes[template]
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		if err := h.client.Update(ctx, pvc); err != nil {
+			return fmt.Errorf("failed to expand persistent volume claim %s: %w", pvc.GetName(), err)
+		}
+		h.logger.Info("expanded persistent volume claim", "name", pvc.GetName(), "namespace", pvc.GetNamespace(), "size", size.String())
+	}
+	return nil
+}
+
+// checkVolumeExpansion returns an error if the StorageClass of the claim does not allow volume expansion.
+func (h *handler) checkVolumeExpansion(ctx context.Context, pvc *corev1.PersistentVolumeClaim) error {
+	name := ptr.Deref(pvc.Spec.StorageClassName, "")
+	if name == "" {
+		return fmt.Errorf("persistent volume claim %s has no StorageClass and cannot be expanded", pvc.GetName())
+	}
+	class := &storagev1.StorageClass{}
+	if err := h.client.Get(ctx, client.ObjectKey{Name: name}, class); err != nil {
+		return fmt.Errorf("failed to get StorageClass %s of persistent volume claim %s: %w", name, pvc.GetName(), err)
+	}
+	if !ptr.Deref(class.AllowVolumeExpansion, false) {
+		return fmt.Errorf("StorageClass %s of persistent volume claim %s does not allow volume expansion", name, pvc.GetName())
+	}
+	return nil
+}
+
+// volumeClaimTemplateOf returns the name of the volume claim template of the StatefulSet the claim was created from.
+// Such claims are named <template>-<statefulset>-<ordinal>.
+func volumeClaimTemplateOf(claim string, sts *appsv1.StatefulSet) (string, bool) {
+	for _, tpl := range sts.Spec.VolumeClaimTemplates {
+		ordinal, ok := strings.CutPrefix(claim, tpl.GetName()+"-"+sts.GetName()+"-")
+		if ok && ordinal != "" && strings.Trim(ordinal, "0123456789") == "" {
+			return tpl.GetName(), true
+		}
+	}
+	return "", false
+}
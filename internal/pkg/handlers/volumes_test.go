@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHandler_UpdateVolumeClaimMetadata(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	existing := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "data-store-0",
+			Namespace:   namespace,
+			Annotations: map[string]string{"keep": "me"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(existing).Build()
+	h := NewHandler(c, scheme.Scheme, logr.Discard())
+
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "store", Namespace: namespace},
+		Spec: appsv1.StatefulSetSpec{
+			// the claim of the second replica does not exist yet
+			Replicas: ptr.To(int32(2)),
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "data",
+						Labels:      map[string]string{"team": "observability"},
+						Annotations: map[string]string{"velero.io/csi-volumesnapshot-class": "snapclass"},
+					},
+				},
+			},
+		},
+	}
+	if errCount := h.UpdateVolumeClaimMetadata(ctx, namespace, []client.Object{sts, &corev1.Service{}}); errCount != 0 {
+		t.Fatalf("expected no errors, got %d", errCount)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(existing), pvc); err != nil {
+		t.Fatal(err)
+	}
+	if pvc.Annotations["keep"] != "me" || pvc.Annotations["velero.io/csi-volumesnapshot-class"] != "snapclass" {
+		t.Errorf("unexpected annotations %v", pvc.Annotations)
+	}
+	if pvc.Labels["team"] != "observability" {
+		t.Errorf("unexpected labels %v", pvc.Labels)
+	}
+}
+
+func TestHandler_ResizeVolumeClaims(t *testing.T) {
+	ctx := context.Background()
+	const namespace = "test"
+
+	newStatefulSet := func(size string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "store", Namespace: namespace},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: ptr.To(int32(2)),
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "data"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+						},
+					},
+				}},
+			},
+		}
+	}
+	newClaim := func(name, class string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: ptr.To(class),
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		}
+	}
+	classes := []client.Object{
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: ptr.To(true)},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fixed"}},
+	}
+	storageOf := func(c client.Client, name string) string {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pvc); err != nil {
+			t.Fatal(err)
+		}
+		q := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		return q.String()
+	}
+
+	t.Run("expands claims and recreates the statefulset", func(t *testing.T) {
+		// the claim of the third replica was retained on scale down, the claim of another statefulset is unrelated
+		objs := append([]client.Object{newStatefulSet("1Gi"), newClaim("data-store-0", "expandable"), newClaim("data-store-1", "expandable"),
+			newClaim("data-store-2", "expandable"), newClaim("data-store-other-0", "expandable")}, classes...)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+		h := NewHandler(c, scheme.Scheme, logr.Discard())
+
+		err := h.ResizeVolumeClaims(ctx, namespace, []client.Object{newStatefulSet("2Gi"), &corev1.Service{}})
+		if !errors.Is(err, ErrRolloutDeferred) {
+			t.Fatalf("expected rollout to be deferred, got %v", err)
+		}
+		for _, name := range []string{"data-store-0", "data-store-1", "data-store-2"} {
+			if got := storageOf(c, name); got != "2Gi" {
+				t.Errorf("expected claim %s to be expanded to 2Gi, got %s", name, got)
+			}
+		}
+		if got := storageOf(c, "data-store-other-0"); got != "1Gi" {
+			t.Errorf("expected unrelated claim to be left untouched, got %s", got)
+		}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "store"}, &appsv1.StatefulSet{}); !apierrors.IsNotFound(err) {
+			t.Errorf("expected statefulset to be deleted, got %v", err)
+		}
+
+		// once deleted, the statefulset is left to the apply
+		if err := h.ResizeVolumeClaims(ctx, namespace, []client.Object{newStatefulSet("2Gi")}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		class   string
+		size    string
+		wantErr string
+	}{
+		{name: "unchanged size", class: "fixed", size: "1Gi"},
+		{name: "class without expansion", class: "fixed", size: "2Gi", wantErr: "does not allow volume expansion"},
+		{name: "missing class", class: "missing", size: "2Gi", wantErr: "failed to get StorageClass"},
+		{name: "shrinking size", class: "expandable", size: "500Mi", wantErr: "cannot shrink"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			objs := append([]client.Object{newStatefulSet("1Gi"), newClaim("data-store-0", tc.class)}, classes...)
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+			h := NewHandler(c, scheme.Scheme, logr.Discard())
+
+			err := h.ResizeVolumeClaims(ctx, namespace, []client.Object{newStatefulSet(tc.size)})
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+			}
+			if got := storageOf(c, "data-store-0"); got != "1Gi" {
+				t.Errorf("expected claim to be left untouched, got %s", got)
+			}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "store"}, &appsv1.StatefulSet{}); err != nil {
+				t.Errorf("expected statefulset to be kept, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package imagepolicy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+const (
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	cosignSignatureTagSuffix  = ".sig"
+)
+
+// ParsePublicKey parses a PEM encoded ECDSA public key as generated by `cosign generate-key-pair`.
+func ParsePublicKey(data []byte) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM public key")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T, only ECDSA keys are supported", key)
+	}
+	return ecKey, nil
+}
+
+type signatureManifest struct {
+	Layers []struct {
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// verifySignature verifies that the image with the given digest has a cosign signature
+// made by one of the given keys. Signatures are looked up using the cosign tag convention.
+func verifySignature(ctx context.Context, rc registryClient, ref reference, digest string, keys []*ecdsa.PublicKey) error {
+	sigTag := strings.Replace(digest, ":", "-", 1) + cosignSignatureTagSuffix
+	raw, err := rc.getManifest(ctx, ref, sigTag)
+	if err != nil {
+		return fmt.Errorf("no signature found: %w", err)
+	}
+
+	var manifest signatureManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return fmt.Errorf("failed to decode signature manifest: %w", err)
+	}
+
+	for _, layer := range manifest.Layers {
+		sig, err := base64.StdEncoding.DecodeString(layer.Annotations[cosignSignatureAnnotation])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+
+		payload, err := rc.getBlob(ctx, ref, layer.Digest)
+		if err != nil {
+			continue
+		}
+
+		var p simpleSigningPayload
+		if err := json.Unmarshal(payload, &p); err != nil || p.Critical.Image.DockerManifestDigest != digest {
+			continue
+		}
+
+		hash := sha256.Sum256(payload)
+		for _, key := range keys {
+			if ecdsa.VerifyASN1(key, hash[:], sig) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no valid signature found for digest %s", digest)
+}
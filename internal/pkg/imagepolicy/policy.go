@@ -0,0 +1,181 @@
+// Package imagepolicy implements the operator level policy applied to container images
+// before they are rolled out to managed workloads.
+// Image tags can be resolved to digests, pinning workloads to the exact image that was observed at reconcile time,
+// and the resolved images can optionally be required to carry a cosign signature made by one of a set of trusted keys.
+package imagepolicy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultCacheTTL is the default duration for which a resolved image is cached.
+	DefaultCacheTTL = 5 * time.Minute
+
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// ErrBlocked is returned when an image does not satisfy the policy and must not be rolled out.
+var ErrBlocked = errors.New("image blocked by policy")
+
+// Options for the image policy.
+type Options struct {
+	// ResolveDigests resolves image tags to digests at reconcile time.
+	ResolveDigests bool
+	// PublicKeyFiles is a list of paths to PEM encoded cosign public keys.
+	// If set, images must carry a signature made by one of the keys. Implies ResolveDigests.
+	PublicKeyFiles []string
+	// CacheTTL is the duration for which a resolved image is cached. Defaults to DefaultCacheTTL.
+	CacheTTL time.Duration
+	// HTTPClient is the client used to talk to registries. Defaults to a client with a request timeout.
+	HTTPClient *http.Client
+}
+
+// Policy resolves and verifies container images according to its options.
+// A nil Policy is valid and leaves images untouched.
+type Policy struct {
+	registry registryClient
+	keys     []*ecdsa.PublicKey
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	image   string
+	expires time.Time
+}
+
+// NewPolicy creates a new Policy from the given options.
+// It returns a nil Policy if neither digest resolution nor signature verification is enabled.
+func NewPolicy(opts Options) (*Policy, error) {
+	if !opts.ResolveDigests && len(opts.PublicKeyFiles) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]*ecdsa.PublicKey, 0, len(opts.PublicKeyFiles))
+	for _, f := range opts.PublicKeyFiles {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key %s: %w", f, err)
+		}
+		key, err := ParsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", f, err)
+		}
+		keys = append(keys, key)
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultRequestTimeout}
+	}
+	ttl := opts.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	return &Policy{
+		registry: registryClient{client: httpClient},
+		keys:     keys,
+		ttl:      ttl,
+		cache:    make(map[string]cacheEntry),
+	}, nil
+}
+
+// Resolve returns the image pinned to the digest it currently points to.
+// If public keys are configured, the image must be signed by one of them.
+// Errors caused by the image not satisfying the policy wrap ErrBlocked.
+func (p *Policy) Resolve(ctx context.Context, image string) (string, error) {
+	if p == nil {
+		return image, nil
+	}
+
+	if resolved, ok := p.fromCache(image); ok {
+		return resolved, nil
+	}
+
+	ref, err := parseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBlocked, err)
+	}
+
+	digest, err := p.registry.getDigest(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to resolve digest for image %s: %v", ErrBlocked, image, err)
+	}
+
+	if len(p.keys) > 0 {
+		if err := verifySignature(ctx, p.registry, ref, digest, p.keys); err != nil {
+			return "", fmt.Errorf("%w: signature verification failed for image %s: %v", ErrBlocked, image, err)
+		}
+	}
+
+	resolved := ref.withDigest(digest)
+	p.toCache(image, resolved)
+	return resolved, nil
+}
+
+// Apply resolves the images of all containers in the pod templates of the given workloads in place.
+// Objects which are not workloads are left untouched.
+func (p *Policy) Apply(ctx context.Context, objs []client.Object) error {
+	if p == nil {
+		return nil
+	}
+
+	for _, obj := range objs {
+		var spec *corev1.PodSpec
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			spec = &o.Spec.Template.Spec
+		case *appsv1.StatefulSet:
+			spec = &o.Spec.Template.Spec
+		case *batchv1.Job:
+			spec = &o.Spec.Template.Spec
+		default:
+			continue
+		}
+
+		for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+			for i := range containers {
+				resolved, err := p.Resolve(ctx, containers[i].Image)
+				if err != nil {
+					return err
+				}
+				containers[i].Image = resolved
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Policy) fromCache(image string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.cache[image]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.image, true
+}
+
+func (p *Policy) toCache(image, resolved string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cache[image] = cacheEntry{image: resolved, expires: time.Now().Add(p.ttl)}
+}
@@ -0,0 +1,329 @@
+package imagepolicy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testRepository = "thanos/thanos"
+	testTag        = "v0.37.2"
+)
+
+var testManifest = []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+
+func TestParseReference(t *testing.T) {
+	for _, tc := range []struct {
+		image      string
+		registry   string
+		repository string
+		tag        string
+		digest     string
+		name       string
+	}{
+		{
+			image:      "busybox",
+			registry:   defaultRegistryHost,
+			repository: "library/busybox",
+			tag:        defaultTag,
+			name:       "busybox",
+		},
+		{
+			image:      "quay.io/thanos/thanos:v0.37.2",
+			registry:   "quay.io",
+			repository: "thanos/thanos",
+			tag:        "v0.37.2",
+			name:       "quay.io/thanos/thanos",
+		},
+		{
+			image:      "localhost:5000/thanos:main",
+			registry:   "localhost:5000",
+			repository: "thanos",
+			tag:        "main",
+			name:       "localhost:5000/thanos",
+		},
+		{
+			image:      "thanos/thanos@sha256:abc",
+			registry:   defaultRegistryHost,
+			repository: "thanos/thanos",
+			digest:     "sha256:abc",
+			name:       "thanos/thanos",
+		},
+	} {
+		t.Run(tc.image, func(t *testing.T) {
+			ref, err := parseReference(tc.image)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ref.registry != tc.registry || ref.repository != tc.repository || ref.tag != tc.tag ||
+				ref.digest != tc.digest || ref.name != tc.name {
+				t.Errorf("unexpected reference %+v", ref)
+			}
+		})
+	}
+
+	if _, err := parseReference("thanos@md5:abc"); err == nil {
+		t.Error("expected error for unsupported digest")
+	}
+}
+
+// fakeRegistry serves a single image, optionally signed, and requires anonymous bearer tokens.
+type fakeRegistry struct {
+	server    *httptest.Server
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	headCount int
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	t.Helper()
+	r := &fakeRegistry{
+		manifests: map[string][]byte{testTag: testManifest},
+		blobs:     map[string][]byte{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("scope") != fmt.Sprintf("repository:%s:pull", testRepository) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"anonymous"}`))
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, r.server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		prefix := "/v2/" + testRepository + "/"
+		path := strings.TrimPrefix(req.URL.Path, prefix)
+		switch {
+		case strings.HasPrefix(path, "manifests/"):
+			m, ok := r.manifests[strings.TrimPrefix(path, "manifests/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set(digestHeader, digestOf(m))
+			if req.Method == http.MethodHead {
+				r.headCount++
+				return
+			}
+			_, _ = w.Write(m)
+		case strings.HasPrefix(path, "blobs/"):
+			b, ok := r.blobs[strings.TrimPrefix(path, "blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(b)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	r.server = httptest.NewTLSServer(mux)
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *fakeRegistry) image() string {
+	return fmt.Sprintf("%s/%s:%s", strings.TrimPrefix(r.server.URL, "https://"), testRepository, testTag)
+}
+
+// sign adds a cosign signature for the given digest made with the given key.
+func (r *fakeRegistry) sign(t *testing.T, key *ecdsa.PrivateKey, digest string) {
+	t.Helper()
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"%s"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`, testRepository, digest))
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payloadDigest := digestOf(payload)
+	r.blobs[payloadDigest] = payload
+
+	manifest, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"layers": []map[string]any{
+			{
+				"mediaType": "application/vnd.dev.cosign.simplesigning.v1+json",
+				"digest":    payloadDigest,
+				"annotations": map[string]string{
+					cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.manifests[strings.Replace(digest, ":", "-", 1)+cosignSignatureTagSuffix] = manifest
+}
+
+func writePublicKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := filepath.Join(t.TempDir(), "cosign.pub")
+	if err := os.WriteFile(f, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestNewPolicyDisabled(t *testing.T) {
+	p, err := NewPolicy(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != nil {
+		t.Fatal("expected nil policy when nothing is enabled")
+	}
+
+	image, err := p.Resolve(context.Background(), "quay.io/thanos/thanos:v0.37.2")
+	if err != nil || image != "quay.io/thanos/thanos:v0.37.2" {
+		t.Errorf("expected nil policy to leave image untouched, got %s %v", image, err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	registry := newFakeRegistry(t)
+	p, err := NewPolicy(Options{ResolveDigests: true, HTTPClient: registry.server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := registry.image()
+	expect := strings.TrimSuffix(image, ":"+testTag) + "@" + digestOf(testManifest)
+	for i := 0; i < 2; i++ {
+		resolved, err := p.Resolve(context.Background(), image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resolved != expect {
+			t.Errorf("expected %s, got %s", expect, resolved)
+		}
+	}
+	if registry.headCount != 1 {
+		t.Errorf("expected resolved image to be cached, registry was queried %d times", registry.headCount)
+	}
+
+	_, err = p.Resolve(context.Background(), strings.Replace(image, testTag, "missing", 1))
+	if !errors.Is(err, ErrBlocked) {
+		t.Errorf("expected unknown tag to be blocked, got %v", err)
+	}
+}
+
+func TestResolveVerifiesSignature(t *testing.T) {
+	trusted := generateKey(t)
+	untrusted := generateKey(t)
+	digest := digestOf(testManifest)
+
+	for _, tc := range []struct {
+		name    string
+		signer  *ecdsa.PrivateKey
+		digest  string
+		blocked bool
+	}{
+		{
+			name:    "unsigned image is blocked",
+			blocked: true,
+		},
+		{
+			name:   "image signed with trusted key",
+			signer: trusted,
+			digest: digest,
+		},
+		{
+			name:    "image signed with untrusted key is blocked",
+			signer:  untrusted,
+			digest:  digest,
+			blocked: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := newFakeRegistry(t)
+			if tc.signer != nil {
+				registry.sign(t, tc.signer, tc.digest)
+			}
+
+			p, err := NewPolicy(Options{
+				PublicKeyFiles: []string{writePublicKey(t, trusted)},
+				HTTPClient:     registry.server.Client(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = p.Resolve(context.Background(), registry.image())
+			if tc.blocked != errors.Is(err, ErrBlocked) {
+				t.Errorf("expected blocked %v, got error %v", tc.blocked, err)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	registry := newFakeRegistry(t)
+	p, err := NewPolicy(Options{ResolveDigests: true, HTTPClient: registry.server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := registry.image()
+	deployment := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "init", Image: image}},
+					Containers:     []corev1.Container{{Name: "thanos", Image: image}},
+				},
+			},
+		},
+	}
+	cm := &corev1.ConfigMap{}
+
+	if err := p.Apply(context.Background(), []client.Object{deployment, cm}); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := strings.TrimSuffix(image, ":"+testTag) + "@" + digestOf(testManifest)
+	spec := deployment.Spec.Template.Spec
+	if spec.InitContainers[0].Image != expect || spec.Containers[0].Image != expect {
+		t.Errorf("expected images to be pinned to %s, got %s and %s", expect, spec.InitContainers[0].Image, spec.Containers[0].Image)
+	}
+}
@@ -0,0 +1,75 @@
+package imagepolicy
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultRegistry     = "docker.io"
+	defaultRegistryHost = "registry-1.docker.io"
+	defaultTag          = "latest"
+)
+
+// reference is a parsed container image reference.
+type reference struct {
+	// name is the image name as written, without tag or digest.
+	name string
+	// registry is the host of the registry serving the image.
+	registry string
+	// repository is the path of the repository within the registry.
+	repository string
+	tag        string
+	digest     string
+}
+
+// parseReference parses an image reference of the form [registry/]repository[:tag][@digest].
+func parseReference(image string) (reference, error) {
+	if image == "" {
+		return reference{}, fmt.Errorf("empty image reference")
+	}
+
+	var ref reference
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.digest = name[i+1:]
+		name = name[:i]
+		if !strings.HasPrefix(ref.digest, "sha256:") {
+			return reference{}, fmt.Errorf("unsupported digest in image reference %s", image)
+		}
+	}
+
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.tag = name[i+1:]
+		name = name[:i]
+	}
+	if ref.tag == "" && ref.digest == "" {
+		ref.tag = defaultTag
+	}
+	ref.name = name
+
+	registry, repository := defaultRegistry, name
+	if i := strings.Index(name, "/"); i >= 0 {
+		if host := name[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry, repository = host, name[i+1:]
+		}
+	}
+	if registry == defaultRegistry {
+		registry = defaultRegistryHost
+		if !strings.Contains(repository, "/") {
+			repository = "library/" + repository
+		}
+	}
+	if repository == "" {
+		return reference{}, fmt.Errorf("invalid image reference %s", image)
+	}
+
+	ref.registry = registry
+	ref.repository = repository
+	return ref, nil
+}
+
+// withDigest returns the image reference pinned to the given digest.
+func (r reference) withDigest(digest string) string {
+	return fmt.Sprintf("%s@%s", r.name, digest)
+}
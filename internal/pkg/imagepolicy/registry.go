@@ -0,0 +1,199 @@
+package imagepolicy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	digestHeader = "Docker-Content-Digest"
+
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// maxBodySize limits the size of manifests and signature payloads read from a registry.
+	maxBodySize = 4 << 20
+)
+
+var manifestMediaTypes = strings.Join([]string{
+	mediaTypeOCIIndex,
+	mediaTypeOCIManifest,
+	mediaTypeDockerManifestList,
+	mediaTypeDockerManifest,
+}, ",")
+
+// registryClient is a minimal client for the OCI distribution API.
+// It only supports anonymous access, using bearer tokens when the registry requests them.
+type registryClient struct {
+	client *http.Client
+}
+
+// getDigest returns the digest of the manifest the given reference points to.
+func (rc registryClient) getDigest(ctx context.Context, ref reference) (string, error) {
+	if ref.digest != "" {
+		return ref.digest, nil
+	}
+
+	u := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.registry, ref.repository, ref.tag)
+	resp, err := rc.do(ctx, http.MethodHead, u, manifestMediaTypes, ref.repository)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if digest := resp.Header.Get(digestHeader); digest != "" {
+		return digest, nil
+	}
+
+	// not all registries return the digest header on HEAD requests, so fall back to hashing the manifest
+	body, err := rc.get(ctx, u, manifestMediaTypes, ref.repository)
+	if err != nil {
+		return "", err
+	}
+	return digestOf(body), nil
+}
+
+// getManifest returns the raw manifest for the given tag or digest in the repository of the reference.
+func (rc registryClient) getManifest(ctx context.Context, ref reference, tagOrDigest string) ([]byte, error) {
+	u := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.registry, ref.repository, tagOrDigest)
+	return rc.get(ctx, u, manifestMediaTypes, ref.repository)
+}
+
+// getBlob returns the blob with the given digest in the repository of the reference.
+// The content of the blob is verified against the digest.
+func (rc registryClient) getBlob(ctx context.Context, ref reference, digest string) ([]byte, error) {
+	u := fmt.Sprintf("https://%s/v2/%s/blobs/%s", ref.registry, ref.repository, digest)
+	body, err := rc.get(ctx, u, "", ref.repository)
+	if err != nil {
+		return nil, err
+	}
+	if got := digestOf(body); got != digest {
+		return nil, fmt.Errorf("blob digest mismatch, expected %s got %s", digest, got)
+	}
+	return body, nil
+}
+
+func (rc registryClient) get(ctx context.Context, u, accept, repository string) ([]byte, error) {
+	resp, err := rc.do(ctx, http.MethodGet, u, accept, repository)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+}
+
+// do performs the request, retrying once with a bearer token if the registry asks for one.
+func (rc registryClient) do(ctx context.Context, method, u, accept, repository string) (*http.Response, error) {
+	resp, err := rc.doWithToken(ctx, method, u, accept, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		token, err := rc.fetchToken(ctx, challenge, repository)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = rc.doWithToken(ctx, method, u, accept, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d for %s %s", resp.StatusCode, method, u)
+	}
+	return resp, nil
+}
+
+func (rc registryClient) doWithToken(ctx context.Context, method, u, accept, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return rc.client.Do(req)
+}
+
+// fetchToken requests an anonymous pull token from the realm in the given Bearer challenge.
+func (rc registryClient) fetchToken(ctx context.Context, challenge, repository string) (string, error) {
+	params, ok := parseBearerChallenge(challenge)
+	if !ok || params["realm"] == "" {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	q := url.Values{}
+	if service := params["service"]; service != "" {
+		q.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", repository)
+	}
+	q.Set("scope", scope)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d fetching registry token", resp.StatusCode)
+	}
+
+	var tr struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxBodySize)).Decode(&tr); err != nil {
+		return "", fmt.Errorf("failed to decode registry token: %w", err)
+	}
+	if tr.Token != "" {
+		return tr.Token, nil
+	}
+	return tr.AccessToken, nil
+}
+
+// parseBearerChallenge parses the parameters of a WWW-Authenticate Bearer challenge.
+func parseBearerChallenge(challenge string) (map[string]string, bool) {
+	scheme, rest, ok := strings.Cut(challenge, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for _, part := range strings.Split(rest, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		params[strings.ToLower(k)] = strings.Trim(v, `"`)
+	}
+	return params, true
+}
+
+func digestOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+// Package logsampling rate limits repetitive log messages, such as per object messages which are logged
+// on every reconciliation of hundreds of objects, so that operator logs stay usable at fleet scale.
+package logsampling
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampler allows a message identified by a key to be logged once per interval.
+// Occurrences within the interval are suppressed and counted.
+// It is safe for concurrent use. A nil Sampler allows every message.
+type Sampler struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	last       time.Time
+	suppressed int
+}
+
+// NewSampler returns a Sampler which allows each message once per interval.
+// An interval of zero or less allows every message.
+func NewSampler(interval time.Duration) *Sampler {
+	return &Sampler{
+		interval: interval,
+		now:      time.Now,
+		entries:  make(map[string]*entry),
+	}
+}
+
+// Allow reports whether the message identified by key should be logged, and the number of occurrences
+// suppressed since it was last logged.
+func (s *Sampler) Allow(key string) (bool, int) {
+	if s == nil || s.interval <= 0 {
+		return true, 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e, ok := s.entries[key]
+	if !ok {
+		s.entries[key] = &entry{last: now}
+		s.gc(now)
+		return true, 0
+	}
+	if now.Sub(e.last) < s.interval {
+		e.suppressed++
+		return false, 0
+	}
+
+	suppressed := e.suppressed
+	e.last = now
+	e.suppressed = 0
+	return true, suppressed
+}
+
+// gc drops the entries of messages which have not been logged for more than two intervals,
+// so that messages of deleted objects do not accumulate.
+func (s *Sampler) gc(now time.Time) {
+	for key, e := range s.entries {
+		if now.Sub(e.last) > 2*s.interval {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package logsampling
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	now := time.Now()
+	s := NewSampler(time.Minute)
+	s.now = func() time.Time { return now }
+
+	if ok, _ := s.Allow("a"); !ok {
+		t.Fatal("expected the first occurrence to be allowed")
+	}
+	if ok, _ := s.Allow("b"); !ok {
+		t.Fatal("expected the first occurrence of another key to be allowed")
+	}
+
+	now = now.Add(30 * time.Second)
+	for range 3 {
+		if ok, _ := s.Allow("a"); ok {
+			t.Fatal("expected occurrences within the interval to be suppressed")
+		}
+	}
+
+	now = now.Add(30 * time.Second)
+	ok, suppressed := s.Allow("a")
+	if !ok || suppressed != 3 {
+		t.Fatalf("expected occurrence after the interval to be allowed with 3 suppressed, got %t and %d", ok, suppressed)
+	}
+	if ok, _ := s.Allow("a"); ok {
+		t.Fatal("expected the interval to restart")
+	}
+
+	// b has not been seen for more than two intervals and is dropped when a new key is seen
+	now = now.Add(2 * time.Minute)
+	s.Allow("c")
+	if _, ok := s.entries["b"]; ok {
+		t.Error("expected stale entries to be dropped")
+	}
+}
+
+func TestSamplerDisabled(t *testing.T) {
+	var nilSampler *Sampler
+	for _, s := range []*Sampler{nilSampler, NewSampler(0)} {
+		for range 2 {
+			if ok, _ := s.Allow("a"); !ok {
+				t.Fatal("expected every occurrence to be allowed")
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package maintenance detects declared cluster maintenance, during which disruptive rollouts of managed workloads
+// are deferred so they do not compound the disruption caused by platform upgrades.
+// Maintenance is declared by ConfigMaps labelled with WindowLabel, each naming a window, and optionally
+// by nodes being cordoned, which is how node drains are signalled.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// WindowLabel marks a ConfigMap declaring a maintenance window.
+	WindowLabel = "monitoring.thanos.io/maintenance-window"
+
+	// StartKey is the key of the window ConfigMap holding the RFC 3339 time at which the window opens.
+	StartKey = "start"
+	// EndKey is the key of the window ConfigMap holding the RFC 3339 time at which the window closes.
+	EndKey = "end"
+	// ComponentsKey is the optional key of the window ConfigMap holding a comma separated list of kinds,
+	// e.g. ThanosReceive,ThanosStore, whose rollouts are deferred. If not set, rollouts of all kinds are deferred.
+	ComponentsKey = "components"
+)
+
+// Options for the maintenance detector.
+type Options struct {
+	// Namespace is the namespace of the ConfigMaps declaring maintenance windows.
+	// If empty, maintenance windows are not read.
+	Namespace string
+	// CordonedNodes declares maintenance while any node of the cluster is cordoned.
+	CordonedNodes bool
+}
+
+// Window is a declared maintenance window.
+type Window struct {
+	// Name is the name of the ConfigMap declaring the window.
+	Name       string
+	Start, End time.Time
+	// Components are the kinds whose rollouts are deferred. Empty means all kinds.
+	Components []string
+}
+
+// Covers returns true if the window is open at now and applies to the given kind.
+func (w Window) Covers(kind string, now time.Time) bool {
+	if now.Before(w.Start) || !now.Before(w.End) {
+		return false
+	}
+	return len(w.Components) == 0 || slices.Contains(w.Components, kind)
+}
+
+// ParseWindow parses the maintenance window declared by the given ConfigMap.
+func ParseWindow(cm *corev1.ConfigMap) (Window, error) {
+	w := Window{Name: cm.GetName()}
+	var err error
+	if w.Start, err = time.Parse(time.RFC3339, cm.Data[StartKey]); err != nil {
+		return Window{}, fmt.Errorf("invalid maintenance window %s: invalid %s: %w", cm.GetName(), StartKey, err)
+	}
+	if w.End, err = time.Parse(time.RFC3339, cm.Data[EndKey]); err != nil {
+		return Window{}, fmt.Errorf("invalid maintenance window %s: invalid %s: %w", cm.GetName(), EndKey, err)
+	}
+	if !w.End.After(w.Start) {
+		return Window{}, fmt.Errorf("invalid maintenance window %s: %s must be after %s", cm.GetName(), EndKey, StartKey)
+	}
+	for _, c := range strings.Split(cm.Data[ComponentsKey], ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			w.Components = append(w.Components, c)
+		}
+	}
+	return w, nil
+}
+
+// Detector detects declared cluster maintenance.
+// A nil Detector never detects maintenance.
+type Detector struct {
+	reader        client.Reader
+	namespace     string
+	cordonedNodes bool
+}
+
+// NewDetector creates a new Detector reading maintenance signals through the given reader.
+// It returns a nil Detector if no maintenance signal is enabled.
+func NewDetector(reader client.Reader, opts Options) *Detector {
+	if opts.Namespace == "" && !opts.CordonedNodes {
+		return nil
+	}
+	return &Detector{
+		reader:        reader,
+		namespace:     opts.Namespace,
+		cordonedNodes: opts.CordonedNodes,
+	}
+}
+
+// Active returns a description of the maintenance deferring rollouts of the given kind at now,
+// or an empty string if there is none.
+// An invalid window ConfigMap is returned as an error, so that rollouts are not started while maintenance
+// may have been declared.
+func (d *Detector) Active(ctx context.Context, kind string, now time.Time) (string, error) {
+	if d == nil {
+		return "", nil
+	}
+
+	if d.namespace != "" {
+		cms := &corev1.ConfigMapList{}
+		if err := d.reader.List(ctx, cms, client.InNamespace(d.namespace), client.HasLabels{WindowLabel}); err != nil {
+			return "", fmt.Errorf("failed to list maintenance windows: %w", err)
+		}
+		for i := range cms.Items {
+			w, err := ParseWindow(&cms.Items[i])
+			if err != nil {
+				return "", err
+			}
+			if w.Covers(kind, now) {
+				return fmt.Sprintf("maintenance window %s is open until %s", w.Name, w.End.Format(time.RFC3339)), nil
+			}
+		}
+	}
+
+	if d.cordonedNodes {
+		nodes := &corev1.NodeList{}
+		if err := d.reader.List(ctx, nodes); err != nil {
+			return "", fmt.Errorf("failed to list nodes: %w", err)
+		}
+		for _, n := range nodes.Items {
+			if n.Spec.Unschedulable {
+				return fmt.Sprintf("node %s is cordoned", n.GetName()), nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,94 @@
+package maintenance
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newWindow(name, start, end, components string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "maintenance", Labels: map[string]string{WindowLabel: "true"}},
+		Data:       map[string]string{StartKey: start, EndKey: end},
+	}
+	if components != "" {
+		cm.Data[ComponentsKey] = components
+	}
+	return cm
+}
+
+func TestParseWindow(t *testing.T) {
+	w, err := ParseWindow(newWindow("upgrade", "2024-06-01T02:00:00Z", "2024-06-01T04:00:00Z", "ThanosReceive, ThanosStore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		kind   string
+		now    string
+		covers bool
+	}{
+		{kind: "ThanosReceive", now: "2024-06-01T02:00:00Z", covers: true},
+		{kind: "ThanosStore", now: "2024-06-01T03:59:59Z", covers: true},
+		{kind: "ThanosStore", now: "2024-06-01T04:00:00Z", covers: false},
+		{kind: "ThanosReceive", now: "2024-06-01T01:59:59Z", covers: false},
+		{kind: "ThanosQuery", now: "2024-06-01T03:00:00Z", covers: false},
+	} {
+		now, _ := time.Parse(time.RFC3339, tc.now)
+		if got := w.Covers(tc.kind, now); got != tc.covers {
+			t.Errorf("expected window to cover %s at %s to be %t, got %t", tc.kind, tc.now, tc.covers, got)
+		}
+	}
+
+	for _, cm := range []*corev1.ConfigMap{
+		newWindow("no-start", "", "2024-06-01T04:00:00Z", ""),
+		newWindow("bad-end", "2024-06-01T02:00:00Z", "tomorrow", ""),
+		newWindow("reversed", "2024-06-01T04:00:00Z", "2024-06-01T02:00:00Z", ""),
+	} {
+		if _, err := ParseWindow(cm); err == nil {
+			t.Errorf("expected error for window %s", cm.GetName())
+		}
+	}
+}
+
+func TestDetectorActive(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC)
+
+	if NewDetector(fake.NewFakeClient(), Options{}) != nil {
+		t.Fatal("expected nil detector without maintenance signals")
+	}
+	var nilDetector *Detector
+	if reason, err := nilDetector.Active(ctx, "ThanosQuery", now); err != nil || reason != "" {
+		t.Errorf("expected nil detector to detect no maintenance, got %q, %v", reason, err)
+	}
+
+	closed := newWindow("closed", "2024-05-01T02:00:00Z", "2024-05-01T04:00:00Z", "")
+	open := newWindow("open", "2024-06-01T02:00:00Z", "2024-06-01T04:00:00Z", "ThanosReceive")
+	otherNamespace := newWindow("other", "2024-06-01T02:00:00Z", "2024-06-01T04:00:00Z", "")
+	otherNamespace.Namespace = "default"
+	cordoned := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}, Spec: corev1.NodeSpec{Unschedulable: true}}
+
+	d := NewDetector(fake.NewFakeClient(closed, open, otherNamespace, cordoned), Options{Namespace: "maintenance"})
+	if reason, err := d.Active(ctx, "ThanosReceive", now); err != nil || !strings.Contains(reason, "maintenance window open") {
+		t.Errorf("expected open window to be detected, got %q, %v", reason, err)
+	}
+	if reason, err := d.Active(ctx, "ThanosQuery", now); err != nil || reason != "" {
+		t.Errorf("expected no maintenance for a kind not covered by the window, got %q, %v", reason, err)
+	}
+
+	d = NewDetector(fake.NewFakeClient(cordoned), Options{CordonedNodes: true})
+	if reason, err := d.Active(ctx, "ThanosQuery", now); err != nil || !strings.Contains(reason, "node node-a is cordoned") {
+		t.Errorf("expected cordoned node to be detected, got %q, %v", reason, err)
+	}
+
+	d = NewDetector(fake.NewFakeClient(newWindow("invalid", "", "", "")), Options{Namespace: "maintenance"})
+	if _, err := d.Active(ctx, "ThanosQuery", now); err == nil {
+		t.Error("expected error for invalid window")
+	}
+}
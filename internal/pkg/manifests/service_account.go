@@ -1,26 +0,0 @@
-package manifests
-
-import (
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/utils/ptr"
-
-	"sigs.k8s.io/controller-runtime/pkg/client"
-)
-
-// BuildServiceAccount returns a new ServiceAccount from Options.
-func BuildServiceAccount(name, namespace string, labels, annotations map[string]string) client.Object {
-	return &corev1.ServiceAccount{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "ServiceAccount",
-			APIVersion: corev1.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        name,
-			Namespace:   namespace,
-			Labels:      labels,
-			Annotations: annotations,
-		},
-		AutomountServiceAccountToken: ptr.To(true),
-	}
-}
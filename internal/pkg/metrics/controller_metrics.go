@@ -1,19 +1,41 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// ControllerBaseMetrics are the metrics shared by all controllers, labelled by controller.
+// They complement the metrics of controller-runtime, such as controller_runtime_reconcile_total
+// and workqueue_depth, with the time spent in the phases of a reconciliation and the reconciliations
+// which did not write any object.
+type ControllerBaseMetrics struct {
+	ReconcileDuration *prometheus.HistogramVec
+	PhaseDuration     *prometheus.HistogramVec
+	NoopReconciles    *prometheus.CounterVec
+}
+
+// ReconcileMetrics are the ControllerBaseMetrics of a single controller.
+type ReconcileMetrics struct {
+	ReconcileDuration prometheus.Observer
+	PhaseDuration     prometheus.ObserverVec
+	NoopReconciles    prometheus.Counter
+}
+
 type ThanosQueryMetrics struct {
 	EndpointsConfigured                        *prometheus.GaugeVec
 	ServiceWatchesReconciliationsTotal         prometheus.Counter
 	FrontendServiceWatchesReconciliationsTotal prometheus.Counter
+	FrontendCacheHitRatio                      *prometheus.GaugeVec
+	FrontendInflightRequests                   *prometheus.GaugeVec
 }
 
 type ThanosReceiveMetrics struct {
 	HashringsConfigured                 *prometheus.GaugeVec
 	EndpointWatchesReconciliationsTotal prometheus.Counter
+	TenantWatchesReconciliationsTotal   prometheus.Counter
 	HashringHash                        *prometheus.GaugeVec
 }
 
@@ -30,11 +52,73 @@ type ThanosStoreMetrics struct {
 type ThanosCompactMetrics struct {
 }
 
+type ThanosToolsMetrics struct {
+}
+
+type ThanosTenantMetrics struct {
+}
+
+// NewControllerBaseMetrics registers the ControllerBaseMetrics with the registry.
+// They must be registered once and shared by all controllers, see ForController.
+func NewControllerBaseMetrics(reg prometheus.Registerer) *ControllerBaseMetrics {
+	buckets := prometheus.ExponentialBuckets(0.005, 2, 14)
+	return &ControllerBaseMetrics{
+		ReconcileDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "thanos_operator_reconcile_duration_seconds",
+			Help:    "Duration of the reconciliations of resources, by controller",
+			Buckets: buckets,
+		}, []string{"controller"}),
+		PhaseDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "thanos_operator_reconcile_phase_duration_seconds",
+			Help:    "Time spent per phase of the reconciliations of resources, e.g. discovery, render or apply/StatefulSet, by controller",
+			Buckets: buckets,
+		}, []string{"controller", "phase"}),
+		NoopReconciles: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "thanos_operator_noop_reconciles_total",
+			Help: "Total number of reconciliations of resources which sent no request to create or update any object, by controller",
+		}, []string{"controller"}),
+	}
+}
+
+// ForController returns the metrics of the named controller. It returns nil for nil ControllerBaseMetrics.
+func (m *ControllerBaseMetrics) ForController(name string) *ReconcileMetrics {
+	if m == nil {
+		return nil
+	}
+	labels := prometheus.Labels{"controller": name}
+	return &ReconcileMetrics{
+		ReconcileDuration: m.ReconcileDuration.With(labels),
+		PhaseDuration:     m.PhaseDuration.MustCurryWith(labels),
+		NoopReconciles:    m.NoopReconciles.With(labels),
+	}
+}
+
+// ObserveReconcile observes the duration of a reconciliation and the time spent per phase.
+// It is a no-op for nil ReconcileMetrics.
+func (m *ReconcileMetrics) ObserveReconcile(total time.Duration, phases map[string]time.Duration) {
+	if m == nil {
+		return
+	}
+	m.ReconcileDuration.Observe(total.Seconds())
+	for phase, d := range phases {
+		m.PhaseDuration.WithLabelValues(phase).Observe(d.Seconds())
+	}
+}
+
+// ObserveApply counts the reconciliation as a no-op if noop is true, see handlers.Handler.LogApplySummary.
+// It is a no-op for nil ReconcileMetrics.
+func (m *ReconcileMetrics) ObserveApply(noop bool) {
+	if m == nil || !noop {
+		return
+	}
+	m.NoopReconciles.Inc()
+}
+
 func NewThanosQueryMetrics(reg prometheus.Registerer) ThanosQueryMetrics {
 	return ThanosQueryMetrics{
 		EndpointsConfigured: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
 			Name: "thanos_operator_query_endpoints_configured",
-			Help: "Number of configured endpoints for ThanosQuery resources",
+			Help: "Number of StoreAPI endpoints discovered for ThanosQuery resources, by endpoint type",
 		}, []string{"type", "resource", "namespace"}),
 		ServiceWatchesReconciliationsTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "thanos_operator_query_service_event_reconciliations_total",
@@ -44,6 +128,14 @@ func NewThanosQueryMetrics(reg prometheus.Registerer) ThanosQueryMetrics {
 			Name: "thanos_operator_query_frontend_service_event_reconciliations_total",
 			Help: "Total number of reconciliations for ThanosQueryFrontend resources due to Service events",
 		}),
+		FrontendCacheHitRatio: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "thanos_operator_query_frontend_cache_hit_ratio",
+			Help: "Ratio of the response cache lookups which were hits, sampled from the Query Frontend of ThanosQuery resources",
+		}, []string{"resource", "namespace"}),
+		FrontendInflightRequests: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "thanos_operator_query_frontend_inflight_requests",
+			Help: "Number of requests being served, sampled from the Query Frontend of ThanosQuery resources",
+		}, []string{"resource", "namespace"}),
 	}
 }
 
@@ -61,6 +153,10 @@ func NewThanosReceiveMetrics(reg prometheus.Registerer) ThanosReceiveMetrics {
 			Name: "thanos_operator_receive_endpoint_event_reconciliations_total",
 			Help: "Total number of reconciliations for ThanosReceive resources due to EndpointSlice events",
 		}),
+		TenantWatchesReconciliationsTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "thanos_operator_receive_tenant_event_reconciliations_total",
+			Help: "Total number of reconciliations for ThanosReceive resources due to ThanosTenant events",
+		}),
 	}
 }
 
@@ -92,3 +188,11 @@ func NewThanosStoreMetrics(reg prometheus.Registerer) ThanosStoreMetrics {
 func NewThanosCompactMetrics(reg prometheus.Registerer) ThanosCompactMetrics {
 	return ThanosCompactMetrics{}
 }
+
+func NewThanosToolsMetrics(reg prometheus.Registerer) ThanosToolsMetrics {
+	return ThanosToolsMetrics{}
+}
+
+func NewThanosTenantMetrics(reg prometheus.Registerer) ThanosTenantMetrics {
+	return ThanosTenantMetrics{}
+}
@@ -0,0 +1,105 @@
+// Package podhealth finds the problems which keep pods from becoming ready, such as images which can not be pulled,
+// pods which can not be scheduled and crash looping containers, so that they can be surfaced on the resource
+// owning the pods instead of only as unready replicas of its workloads.
+package podhealth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ReasonImagePullBackOff is the reason of a problem of a container whose image can not be pulled.
+	ReasonImagePullBackOff = "ImagePullBackOff"
+	// ReasonUnschedulable is the reason of a problem of a pod which can not be scheduled.
+	ReasonUnschedulable = "Unschedulable"
+	// ReasonCrashLoopBackOff is the reason of a problem of a container the kubelet backs off restarting.
+	ReasonCrashLoopBackOff = "CrashLoopBackOff"
+)
+
+// imagePullReasons are the reasons of the waiting state of a container whose image can not be pulled.
+var imagePullReasons = []string{"ImagePullBackOff", "ErrImagePull", "InvalidImageName", "ErrImageNeverPull"}
+
+// Problem keeps a pod from becoming ready.
+type Problem struct {
+	// Reason is one of ReasonImagePullBackOff, ReasonUnschedulable or ReasonCrashLoopBackOff.
+	Reason string
+	// Pod is the name of the pod.
+	Pod string
+	// Container is the name of the container, if the problem is specific to a container.
+	Container string
+	// Message describes the problem, e.g. the message of the scheduler or the kubelet.
+	Message string
+}
+
+// String describes the problem in a single line.
+func (p Problem) String() string {
+	s := fmt.Sprintf("pod %s", p.Pod)
+	if p.Container != "" {
+		s = fmt.Sprintf("container %s of pod %s", p.Container, p.Pod)
+	}
+	s += fmt.Sprintf(": %s", p.Reason)
+	if p.Message != "" {
+		s += ": " + p.Message
+	}
+	return s
+}
+
+// Find returns the problems of the pods, in the order of the pods.
+// A pod which can not be scheduled has no other problems.
+func Find(pods []corev1.Pod) []Problem {
+	var problems []Problem
+	for _, pod := range pods {
+		if pod.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if c := podCondition(pod, corev1.PodScheduled); c != nil && c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable {
+			problems = append(problems, Problem{Reason: ReasonUnschedulable, Pod: pod.GetName(), Message: c.Message})
+			continue
+		}
+		for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+			for _, status := range statuses {
+				waiting := status.State.Waiting
+				if waiting == nil {
+					continue
+				}
+				problem := Problem{Pod: pod.GetName(), Container: status.Name, Message: waiting.Message}
+				switch {
+				case slices.Contains(imagePullReasons, waiting.Reason):
+					problem.Reason = ReasonImagePullBackOff
+				case waiting.Reason == ReasonCrashLoopBackOff:
+					problem.Reason = ReasonCrashLoopBackOff
+				default:
+					continue
+				}
+				problems = append(problems, problem)
+			}
+		}
+	}
+	return problems
+}
+
+// Summarize describes at most limit problems, one per line, followed by the number of problems omitted.
+func Summarize(problems []Problem, limit int) string {
+	lines := make([]string, 0, min(len(problems), limit)+1)
+	for i, p := range problems {
+		if i == limit {
+			lines = append(lines, fmt.Sprintf("and %d more problems", len(problems)-limit))
+			break
+		}
+		lines = append(lines, p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func podCondition(pod corev1.Pod, conditionType corev1.PodConditionType) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == conditionType {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
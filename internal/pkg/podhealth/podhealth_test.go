@@ -0,0 +1,93 @@
+package podhealth
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func waiting(name, reason, message string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name:  name,
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: message}},
+	}
+}
+
+func TestFind(t *testing.T) {
+	now := metav1.Now()
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "thanos-store-0"},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{waiting("init", "ErrImagePull", "manifest unknown")},
+				ContainerStatuses: []corev1.ContainerStatus{
+					waiting("thanos", "PodInitializing", ""),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "thanos-store-1"},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/3 nodes are available: 3 Insufficient memory.",
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "thanos-store-2"},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					waiting("thanos", ReasonCrashLoopBackOff, "back-off 5m0s restarting failed container"),
+					{Name: "sidecar", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				},
+			},
+		},
+		{
+			// pods being deleted are not reported
+			ObjectMeta: metav1.ObjectMeta{Name: "thanos-store-3", DeletionTimestamp: &now, Finalizers: []string{"test"}},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{waiting("thanos", ReasonCrashLoopBackOff, "")},
+			},
+		},
+	}
+
+	expect := []Problem{
+		{Reason: ReasonImagePullBackOff, Pod: "thanos-store-0", Container: "init", Message: "manifest unknown"},
+		{Reason: ReasonUnschedulable, Pod: "thanos-store-1", Message: "0/3 nodes are available: 3 Insufficient memory."},
+		{Reason: ReasonCrashLoopBackOff, Pod: "thanos-store-2", Container: "thanos", Message: "back-off 5m0s restarting failed container"},
+	}
+	got := Find(pods)
+	if len(got) != len(expect) {
+		t.Fatalf("expected %d problems, got %d: %v", len(expect), len(got), got)
+	}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Errorf("problem %d: expected %+v, got %+v", i, expect[i], got[i])
+		}
+	}
+
+	if problems := Find([]corev1.Pod{{Status: corev1.PodStatus{Phase: corev1.PodRunning}}}); len(problems) != 0 {
+		t.Errorf("expected no problems for a running pod, got %v", problems)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	problems := []Problem{
+		{Reason: ReasonUnschedulable, Pod: "a", Message: "no nodes"},
+		{Reason: ReasonImagePullBackOff, Pod: "b", Container: "thanos"},
+		{Reason: ReasonCrashLoopBackOff, Pod: "c", Container: "thanos"},
+	}
+	expect := strings.Join([]string{
+		"pod a: Unschedulable: no nodes",
+		"container thanos of pod b: ImagePullBackOff",
+		"and 1 more problems",
+	}, "\n")
+	if got := Summarize(problems, 2); got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+}
@@ -0,0 +1,55 @@
+package profile
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProfile(t *testing.T) {
+	now := time.Now()
+	p := New()
+	p.start = now
+	p.now = func() time.Time { return now }
+
+	stop := p.Start("discovery")
+	now = now.Add(12 * time.Millisecond)
+	stop()
+
+	p.Add("apply/Service", 10*time.Millisecond)
+	p.Add("render", 3*time.Millisecond)
+	p.Add("apply/Service", 20*time.Millisecond)
+	now = now.Add(40 * time.Millisecond)
+
+	expect := "discovery=12ms apply/Service=30ms render=3ms total=52ms"
+	if got := p.String(); got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+
+	phases := p.Phases()
+	if len(phases) != 3 || phases["apply/Service"] != 30*time.Millisecond {
+		t.Errorf("expected the time spent per phase, got %v", phases)
+	}
+	if p.Total() != 52*time.Millisecond {
+		t.Errorf("expected a total of 52ms, got %s", p.Total())
+	}
+}
+
+func TestProfileContext(t *testing.T) {
+	ctx := context.Background()
+	p := FromContext(ctx)
+	if p != nil {
+		t.Fatal("expected no profile without profiling")
+	}
+	// a nil profile must be usable
+	p.Start("discovery")()
+	p.Add("render", time.Second)
+	if p.String() != "" || p.Phases() != nil || p.Total() != 0 {
+		t.Error("expected nil profile to be empty")
+	}
+
+	p = New()
+	if FromContext(NewContext(ctx, p)) != p {
+		t.Error("expected profile to be carried by the context")
+	}
+}
@@ -0,0 +1,105 @@
+package querystatus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	metricsPath = "/metrics"
+
+	// metrics of the response cache and the HTTP API of the Query Frontend.
+	cacheFetchedKeysMetric = "cortex_cache_fetched_keys"
+	cacheHitsMetric        = "cortex_cache_hits"
+	inflightMetric         = "http_inflight_requests"
+	queriesMetric          = "thanos_query_frontend_queries_total"
+)
+
+// FrontendStats are the statistics of one or more Query Frontend instances, summed across the instances.
+type FrontendStats struct {
+	// CacheFetchedKeys is the number of keys looked up in the response cache.
+	CacheFetchedKeys float64
+	// CacheHits is the number of keys found in the response cache.
+	CacheHits float64
+	// InflightRequests is the number of requests being served.
+	InflightRequests float64
+	// Queries is the number of queries received.
+	Queries float64
+}
+
+// Add returns the sum of the statistics.
+func (s FrontendStats) Add(o FrontendStats) FrontendStats {
+	return FrontendStats{
+		CacheFetchedKeys: s.CacheFetchedKeys + o.CacheFetchedKeys,
+		CacheHits:        s.CacheHits + o.CacheHits,
+		InflightRequests: s.InflightRequests + o.InflightRequests,
+		Queries:          s.Queries + o.Queries,
+	}
+}
+
+// CacheHitRatio returns the ratio of the keys found in the response cache, or false if no key was looked up.
+func (s FrontendStats) CacheHitRatio() (float64, bool) {
+	if s.CacheFetchedKeys <= 0 {
+		return 0, false
+	}
+	return s.CacheHits / s.CacheFetchedKeys, true
+}
+
+// FrontendStats returns the statistics of the Query Frontend serving its HTTP API at baseURL,
+// read from its metrics endpoint. Counters are cumulative since the start of the Query Frontend.
+func (c *Client) FrontendStats(ctx context.Context, baseURL string) (FrontendStats, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+metricsPath, nil)
+	if err != nil {
+		return FrontendStats{}, err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return FrontendStats{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return FrontendStats{}, fmt.Errorf("unexpected metrics response with status code %d", resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return FrontendStats{}, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	return FrontendStats{
+		CacheFetchedKeys: sumMetric(families, cacheFetchedKeysMetric),
+		CacheHits:        sumMetric(families, cacheHitsMetric),
+		InflightRequests: sumMetric(families, inflightMetric),
+		Queries:          sumMetric(families, queriesMetric),
+	}, nil
+}
+
+// sumMetric returns the sum of the counter or gauge samples of the metric,
+// which may be exposed with or without the _total suffix of counters.
+func sumMetric(families map[string]*dto.MetricFamily, name string) float64 {
+	var sum float64
+	for _, n := range []string{strings.TrimSuffix(name, "_total"), strings.TrimSuffix(name, "_total") + "_total"} {
+		mf, ok := families[n]
+		if !ok {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			switch {
+			case m.GetCounter() != nil:
+				sum += m.GetCounter().GetValue()
+			case m.GetGauge() != nil:
+				sum += m.GetGauge().GetValue()
+			case m.GetUntyped() != nil:
+				sum += m.GetUntyped().GetValue()
+			}
+		}
+	}
+	return sum
+}
@@ -0,0 +1,98 @@
+// Package querystatus reads the state of the Store API endpoints a Thanos Querier is connected to
+// from the /api/v1/stores endpoint of its HTTP API, and the statistics of a Query Frontend from its metrics.
+package querystatus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	storesPath = "/api/v1/stores"
+
+	// maxBodySize limits the size of the response read from the Querier.
+	maxBodySize = 4 << 20
+)
+
+// Endpoint is the state of a Store API endpoint as seen by the Querier.
+type Endpoint struct {
+	// Name is the address of the endpoint.
+	Name string
+	// Type is the component type the endpoint advertises, e.g. store, sidecar or receive.
+	Type string
+	// LastError is the error of the last health check of the endpoint, if any.
+	LastError string
+	// LabelSets are the external label sets advertised by the endpoint.
+	LabelSets []map[string]string
+}
+
+// Up returns true if the last health check of the endpoint succeeded.
+func (e Endpoint) Up() bool {
+	return e.LastError == ""
+}
+
+// Client reads the endpoint status from Thanos Queriers.
+type Client struct {
+	client *http.Client
+}
+
+// NewClient returns a Client using the given HTTP client.
+func NewClient(client *http.Client) *Client {
+	return &Client{client: client}
+}
+
+type storesResponse struct {
+	Status string                     `json:"status"`
+	Error  string                     `json:"error"`
+	Data   map[string][]storeResponse `json:"data"`
+}
+
+type storeResponse struct {
+	Name      string              `json:"name"`
+	LastError *string             `json:"lastError"`
+	LabelSets []map[string]string `json:"labelSets"`
+}
+
+// Endpoints returns the endpoints known to the Querier serving its HTTP API at baseURL, sorted by type and name.
+func (c *Client) Endpoints(ctx context.Context, baseURL string) ([]Endpoint, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+storesPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var sr storesResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxBodySize)).Decode(&sr); err != nil {
+		return nil, fmt.Errorf("failed to decode stores response with status code %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || sr.Status != "success" {
+		return nil, fmt.Errorf("unexpected stores response with status code %d: %s", resp.StatusCode, sr.Error)
+	}
+
+	var endpoints []Endpoint
+	for typ, stores := range sr.Data {
+		for _, s := range stores {
+			e := Endpoint{Name: s.Name, Type: typ, LabelSets: s.LabelSets}
+			if s.LastError != nil {
+				e.LastError = *s.LastError
+			}
+			endpoints = append(endpoints, e)
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Type != endpoints[j].Type {
+			return endpoints[i].Type < endpoints[j].Type
+		}
+		return endpoints[i].Name < endpoints[j].Name
+	})
+	return endpoints, nil
+}
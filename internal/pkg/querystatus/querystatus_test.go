@@ -0,0 +1,98 @@
+package querystatus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestEndpoints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != storesPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+  "status": "success",
+  "data": {
+    "store": [
+      {"name": "10.0.0.2:10901", "lastCheck": "2024-01-01T00:00:00Z", "lastError": "rpc error: connection refused", "labelSets": []},
+      {"name": "10.0.0.1:10901", "lastCheck": "2024-01-01T00:00:00Z", "lastError": null, "labelSets": []}
+    ],
+    "receive": [
+      {"name": "10.0.0.3:10901", "lastCheck": "2024-01-01T00:00:00Z", "lastError": null, "labelSets": [{"replica": "receive-0", "tenant_id": "a"}]}
+    ]
+  }
+}`))
+	}))
+	defer srv.Close()
+
+	endpoints, err := NewClient(srv.Client()).Endpoints(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []Endpoint{
+		{Name: "10.0.0.3:10901", Type: "receive", LabelSets: []map[string]string{{"replica": "receive-0", "tenant_id": "a"}}},
+		{Name: "10.0.0.1:10901", Type: "store", LabelSets: []map[string]string{}},
+		{Name: "10.0.0.2:10901", Type: "store", LastError: "rpc error: connection refused", LabelSets: []map[string]string{}},
+	}
+	if !reflect.DeepEqual(endpoints, expect) {
+		t.Errorf("expected %v, got %v", expect, endpoints)
+	}
+	if !endpoints[0].Up() || endpoints[2].Up() {
+		t.Errorf("unexpected endpoint health %v", endpoints)
+	}
+}
+
+func TestEndpointsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"status": "error", "error": "not ready"}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.Client()).Endpoints(context.Background(), srv.URL); err == nil {
+		t.Error("expected error for unsuccessful response")
+	}
+}
+
+func TestFrontendStats(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != metricsPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`# TYPE cortex_cache_fetched_keys counter
+cortex_cache_fetched_keys{name="frontend.memcache"} 200
+# TYPE cortex_cache_hits counter
+cortex_cache_hits{name="frontend.memcache"} 150
+# TYPE http_inflight_requests gauge
+http_inflight_requests{handler="query-range",method="get"} 3
+http_inflight_requests{handler="labels",method="get"} 1
+# TYPE thanos_query_frontend_queries_total counter
+thanos_query_frontend_queries_total{op="query_range"} 40
+thanos_query_frontend_queries_total{op="labels"} 2
+`))
+	}))
+	defer srv.Close()
+
+	stats, err := NewClient(srv.Client()).FrontendStats(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := FrontendStats{CacheFetchedKeys: 200, CacheHits: 150, InflightRequests: 4, Queries: 42}
+	if stats != expect {
+		t.Errorf("expected %v, got %v", expect, stats)
+	}
+
+	stats = stats.Add(FrontendStats{CacheFetchedKeys: 100, CacheHits: 0})
+	if ratio, ok := stats.CacheHitRatio(); !ok || ratio != 0.5 {
+		t.Errorf("expected a cache hit ratio of 0.5, got %v", ratio)
+	}
+	if _, ok := (FrontendStats{}).CacheHitRatio(); ok {
+		t.Error("expected no cache hit ratio without cache lookups")
+	}
+}
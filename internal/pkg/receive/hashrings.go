@@ -10,7 +10,7 @@ import (
 
 	"github.com/prometheus/prometheus/model/labels"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests/receive"
+	"github.com/thanos-community/thanos-operator/pkg/manifests/receive"
 
 	discoveryv1 "k8s.io/api/discovery/v1"
 )
@@ -120,11 +120,19 @@ type EndpointConverter func(eps discoveryv1.EndpointSlice, ep discoveryv1.Endpoi
 
 // DefaultEndpointConverter is the default EndpointConverter that converts an EndpointSlice to an Endpoint.
 // It uses the service name and namespace from the EndpointSlice to construct the address.
+// The port is the gRPC port of the EndpointSlice, falling back to the default gRPC port if it is not found.
 func DefaultEndpointConverter(eps discoveryv1.EndpointSlice, ep discoveryv1.Endpoint) Endpoint {
 	svcName := eps.Labels[discoveryv1.LabelServiceName]
 	ns := eps.GetNamespace()
+	port := int32(GRPCPort)
+	for _, p := range eps.Ports {
+		if p.Name != nil && *p.Name == receive.GRPCPortName && p.Port != nil {
+			port = *p.Port
+			break
+		}
+	}
 	return Endpoint{
-		Address: fmt.Sprintf("%s.%s.%s.svc.cluster.local:%d", *ep.Hostname, svcName, ns, GRPCPort),
+		Address: fmt.Sprintf("%s.%s.%s.svc.cluster.local:%d", *ep.Hostname, svcName, ns, port),
 	}
 }
 
@@ -241,3 +249,9 @@ func HashAsMetricValue(data []byte) float64 {
 	copy(bytes, smallSum)
 	return float64(binary.LittleEndian.Uint64(bytes))
 }
+
+// Hash returns a short hexadecimal hash of the given data.
+func Hash(data []byte) string {
+	sum := md5.Sum(data)
+	return fmt.Sprintf("%x", sum[0:6])
+}
@@ -44,6 +44,15 @@ func TestDefaultEndpointConverter(t *testing.T) {
 	if result != expected {
 		t.Errorf("expected %v, got %v", expected, result)
 	}
+
+	eps.Ports = []discoveryv1.EndpointPort{
+		{Name: ptr.To("http"), Port: ptr.To(int32(8080))},
+		{Name: ptr.To("grpc"), Port: ptr.To(int32(20901))},
+	}
+	expected.Address = "test-host.test-service.default.svc.cluster.local:20901"
+	if result := DefaultEndpointConverter(eps, ep); result != expected {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
 }
 
 func TestFilterEndpointReady(t *testing.T) {
@@ -0,0 +1,118 @@
+// Package rollback tracks the last known good state of Deployments managed by the operator
+// and provides the means to restore it when a rollout fails.
+package rollback
+
+import (
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reasonProgressDeadlineExceeded is the reason set by the Deployment controller on the Progressing condition
+// when a rollout did not make progress within the progress deadline.
+const reasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// LastKnownGood holds the pod templates of Deployments that were last successfully rolled out, keyed by Deployment name.
+type LastKnownGood map[string]corev1.PodTemplateSpec
+
+// FromConfigMap reads the last known good state from the given ConfigMap.
+func FromConfigMap(cm *corev1.ConfigMap) (LastKnownGood, error) {
+	lkg := make(LastKnownGood, len(cm.Data))
+	for name, data := range cm.Data {
+		var tpl corev1.PodTemplateSpec
+		if err := json.Unmarshal([]byte(data), &tpl); err != nil {
+			return nil, fmt.Errorf("failed to decode last known good state of %s: %w", name, err)
+		}
+		lkg[name] = tpl
+	}
+	return lkg, nil
+}
+
+// ConfigMap returns a ConfigMap that persists the last known good state.
+func (lkg LastKnownGood) ConfigMap(name, namespace string, labels map[string]string) (*corev1.ConfigMap, error) {
+	data := make(map[string]string, len(lkg))
+	for deployment, tpl := range lkg {
+		b, err := json.Marshal(tpl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode last known good state of %s: %w", deployment, err)
+		}
+		data[deployment] = string(b)
+	}
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Data: data,
+	}, nil
+}
+
+// Record stores the pod template of the given Deployment if it is fully rolled out.
+// It returns true if the state changed.
+func (lkg LastKnownGood) Record(deployment *appsv1.Deployment) bool {
+	if !IsRolledOut(deployment) {
+		return false
+	}
+
+	if current, ok := lkg[deployment.GetName()]; ok && equality.Semantic.DeepEqual(current, deployment.Spec.Template) {
+		return false
+	}
+	lkg[deployment.GetName()] = *deployment.Spec.Template.DeepCopy()
+	return true
+}
+
+// Restore replaces the pod templates of the Deployments in objs with their last known good state.
+// Deployments without a last known good state are left untouched.
+// It returns the names of the Deployments that were restored.
+func (lkg LastKnownGood) Restore(objs []client.Object) []string {
+	var restored []string
+	for _, obj := range objs {
+		deployment, ok := obj.(*appsv1.Deployment)
+		if !ok {
+			continue
+		}
+		tpl, ok := lkg[deployment.GetName()]
+		if !ok {
+			continue
+		}
+		deployment.Spec.Template = *tpl.DeepCopy()
+		restored = append(restored, deployment.GetName())
+	}
+	return restored
+}
+
+// IsRolledOut returns true if all replicas of the Deployment run the current pod template and are available.
+func IsRolledOut(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.GetGeneration() {
+		return false
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.Replicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas
+}
+
+// HasFailed returns true if the rollout of the Deployment exceeded its progress deadline.
+func HasFailed(deployment *appsv1.Deployment) bool {
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing {
+			return c.Status == corev1.ConditionFalse && c.Reason == reasonProgressDeadlineExceeded
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package rollback
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newDeployment(image string, rolledOut bool) *appsv1.Deployment {
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "thanos-query",
+			Generation: 2,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(2)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "query", Image: image}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+		},
+	}
+	if !rolledOut {
+		d.Status.UpdatedReplicas = 1
+		d.Status.Replicas = 3
+	}
+	return d
+}
+
+func TestIsRolledOut(t *testing.T) {
+	if !IsRolledOut(newDeployment("thanos:v1", true)) {
+		t.Error("expected deployment to be rolled out")
+	}
+	if IsRolledOut(newDeployment("thanos:v1", false)) {
+		t.Error("expected deployment with outdated replicas to not be rolled out")
+	}
+
+	d := newDeployment("thanos:v1", true)
+	d.Generation = 3
+	if IsRolledOut(d) {
+		t.Error("expected deployment with unobserved generation to not be rolled out")
+	}
+}
+
+func TestHasFailed(t *testing.T) {
+	d := newDeployment("thanos:v2", false)
+	if HasFailed(d) {
+		t.Error("expected deployment without conditions to not have failed")
+	}
+
+	d.Status.Conditions = []appsv1.DeploymentCondition{
+		{
+			Type:   appsv1.DeploymentProgressing,
+			Status: corev1.ConditionFalse,
+			Reason: reasonProgressDeadlineExceeded,
+		},
+	}
+	if !HasFailed(d) {
+		t.Error("expected deployment which exceeded its progress deadline to have failed")
+	}
+}
+
+func TestRecordAndRestore(t *testing.T) {
+	lkg := LastKnownGood{}
+	if lkg.Record(newDeployment("thanos:v2", false)) {
+		t.Error("expected deployment which is not rolled out to not be recorded")
+	}
+	if !lkg.Record(newDeployment("thanos:v1", true)) {
+		t.Fatal("expected rolled out deployment to be recorded")
+	}
+	if lkg.Record(newDeployment("thanos:v1", true)) {
+		t.Error("expected recording the same state twice to be a no-op")
+	}
+
+	cm, err := lkg.ConfigMap("lkg", "ns", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restoredLKG, err := FromConfigMap(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	desired := newDeployment("thanos:v2", false)
+	other := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	restored := restoredLKG.Restore([]client.Object{desired, other, &corev1.ConfigMap{}})
+	if len(restored) != 1 || restored[0] != "thanos-query" {
+		t.Errorf("expected only thanos-query to be restored, got %v", restored)
+	}
+	if desired.Spec.Template.Spec.Containers[0].Image != "thanos:v1" {
+		t.Errorf("expected image to be restored to thanos:v1, got %s", desired.Spec.Template.Spec.Containers[0].Image)
+	}
+}
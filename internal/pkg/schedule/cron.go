@@ -0,0 +1,167 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchDays bounds the search for the next activation of a cron expression.
+// It covers expressions such as "0 0 29 2 *" which only match in leap years.
+const maxSearchDays = 366 * 8
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12}
+	dowField    = field{name: "day of week", min: 0, max: 7}
+)
+
+// Cron is a parsed standard five field cron expression.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record whether the day fields are unrestricted,
+	// since a day matches either day field when both are restricted.
+	domAny, dowAny bool
+}
+
+// ParseCron parses a standard five field cron expression: minute, hour, day of month, month and day of week.
+// Each field supports '*', single values, ranges 'a-b', steps '*/n' and 'a-b/n', and comma separated lists thereof.
+// Day of week 0 and 7 both denote Sunday.
+func ParseCron(expr string) (Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Cron{}, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	var c Cron
+	var err error
+	if c.minute, err = parseField(fields[0], minuteField); err != nil {
+		return Cron{}, err
+	}
+	if c.hour, err = parseField(fields[1], hourField); err != nil {
+		return Cron{}, err
+	}
+	if c.dom, err = parseField(fields[2], domField); err != nil {
+		return Cron{}, err
+	}
+	if c.month, err = parseField(fields[3], monthField); err != nil {
+		return Cron{}, err
+	}
+	if c.dow, err = parseField(fields[4], dowField); err != nil {
+		return Cron{}, err
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return c, nil
+}
+
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepStr)
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepStr, f.name)
+			}
+			step = s
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			lo, hi, _ := strings.Cut(rng, "-")
+			var err error
+			if start, err = parseValue(lo, f); err != nil {
+				return 0, err
+			}
+			if end, err = parseValue(hi, f); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q in %s field", rng, f.name)
+			}
+		default:
+			v, err := parseValue(rng, f)
+			if err != nil {
+				return 0, err
+			}
+			start = v
+			end = v
+			if hasStep {
+				end = f.max
+			}
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field, must be between %d and %d", s, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time strictly after t matching the expression, in the location of t.
+// It returns the zero time if no such time exists within the search horizon.
+func (c Cron) Next(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, t.Location())
+
+	for i := 0; i < maxSearchDays; i++ {
+		if c.matchesDay(t) {
+			for h := t.Hour(); h < 24; h++ {
+				if c.hour&(1<<uint(h)) == 0 {
+					continue
+				}
+				m := 0
+				if h == t.Hour() {
+					m = t.Minute()
+				}
+				for ; m < 60; m++ {
+					if c.minute&(1<<uint(m)) != 0 {
+						return time.Date(t.Year(), t.Month(), t.Day(), h, m, 0, 0, t.Location())
+					}
+				}
+			}
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+func (c Cron) matchesDay(t time.Time) bool {
+	if c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
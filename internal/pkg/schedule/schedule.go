@@ -0,0 +1,49 @@
+// Package schedule evaluates recurring time windows defined by cron expressions.
+// It is used to restrict when managed workloads run, for example to keep heavy compaction to off-peak hours.
+package schedule
+
+import (
+	"time"
+)
+
+// Window is a recurring time window opening at each activation of Start and lasting for Duration.
+type Window struct {
+	Start    Cron
+	Duration time.Duration
+}
+
+// State is the state of a set of windows at a point in time.
+type State struct {
+	// Active is true if any window is open.
+	Active bool
+	// NextTransition is the time at which the state is next expected to change.
+	// It is the zero time if no window is ever expected to open.
+	NextTransition time.Time
+}
+
+// Evaluate returns the state of the given windows at now.
+// While a window is open, NextTransition is the time it closes. Otherwise, it is the time the next window opens.
+func Evaluate(windows []Window, now time.Time) State {
+	var state State
+	for _, w := range windows {
+		// the latest activation which may still be open is the first one after now - Duration
+		start := w.Start.Next(now.Add(-w.Duration))
+		if start.IsZero() {
+			continue
+		}
+
+		if !start.After(now) {
+			end := start.Add(w.Duration)
+			if !state.Active || end.After(state.NextTransition) {
+				state.NextTransition = end
+			}
+			state.Active = true
+			continue
+		}
+
+		if !state.Active && (state.NextTransition.IsZero() || start.Before(state.NextTransition)) {
+			state.NextTransition = start
+		}
+	}
+	return state
+}
@@ -0,0 +1,116 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParseCron(t *testing.T, expr string) Cron {
+	t.Helper()
+	c, err := ParseCron(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 22, 30, 0, 0, time.UTC) // a Wednesday
+
+	for _, tc := range []struct {
+		expr   string
+		expect time.Time
+	}{
+		{expr: "* * * * *", expect: time.Date(2024, time.January, 31, 22, 31, 0, 0, time.UTC)},
+		{expr: "30 22 * * *", expect: time.Date(2024, time.February, 1, 22, 30, 0, 0, time.UTC)},
+		{expr: "0 2 * * *", expect: time.Date(2024, time.February, 1, 2, 0, 0, 0, time.UTC)},
+		{expr: "*/20 23 * * *", expect: time.Date(2024, time.January, 31, 23, 0, 0, 0, time.UTC)},
+		{expr: "0 1 * * 6,7", expect: time.Date(2024, time.February, 3, 1, 0, 0, 0, time.UTC)},
+		{expr: "0 0 1-7 * 1", expect: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", expect: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "15 3 * 3 *", expect: time.Date(2024, time.March, 1, 3, 15, 0, 0, time.UTC)},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			if got := mustParseCron(t, tc.expr).Next(from); !got.Equal(tc.expect) {
+				t.Errorf("expected %s, got %s", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	// nightly window from 01:00 to 05:00
+	nightly := Window{Start: mustParseCron(t, "0 1 * * *"), Duration: 4 * time.Hour}
+	// weekend window from Saturday 12:00 for 24 hours
+	weekend := Window{Start: mustParseCron(t, "0 12 * * 6"), Duration: 24 * time.Hour}
+
+	for _, tc := range []struct {
+		name    string
+		windows []Window
+		now     time.Time
+		expect  State
+	}{
+		{
+			name:    "before window opens",
+			windows: []Window{nightly},
+			now:     time.Date(2024, time.January, 3, 0, 30, 0, 0, time.UTC),
+			expect:  State{NextTransition: time.Date(2024, time.January, 3, 1, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "window opening now",
+			windows: []Window{nightly},
+			now:     time.Date(2024, time.January, 3, 1, 0, 0, 0, time.UTC),
+			expect:  State{Active: true, NextTransition: time.Date(2024, time.January, 3, 5, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "inside window",
+			windows: []Window{nightly},
+			now:     time.Date(2024, time.January, 3, 4, 59, 0, 0, time.UTC),
+			expect:  State{Active: true, NextTransition: time.Date(2024, time.January, 3, 5, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "window closing now",
+			windows: []Window{nightly},
+			now:     time.Date(2024, time.January, 3, 5, 0, 0, 0, time.UTC),
+			expect:  State{NextTransition: time.Date(2024, time.January, 4, 1, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "overlapping windows close at the latest end",
+			windows: []Window{nightly, weekend},
+			now:     time.Date(2024, time.January, 7, 2, 0, 0, 0, time.UTC),
+			expect:  State{Active: true, NextTransition: time.Date(2024, time.January, 7, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "next window of several",
+			windows: []Window{weekend, nightly},
+			now:     time.Date(2024, time.January, 6, 6, 0, 0, 0, time.UTC),
+			expect:  State{NextTransition: time.Date(2024, time.January, 6, 12, 0, 0, 0, time.UTC)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Evaluate(tc.windows, tc.now)
+			if got.Active != tc.expect.Active || !got.NextTransition.Equal(tc.expect.NextTransition) {
+				t.Errorf("expected %+v, got %+v", tc.expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Package snapshot manages the VolumeSnapshots of persistent volumes of the operator workloads,
+// which are taken periodically and allow to restore a volume lost before its data was uploaded to object storage.
+// VolumeSnapshots are handled as unstructured objects, since their API is installed by the CSI snapshot controller
+// and may not be available in every cluster.
+package snapshot
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// ClaimLabel is set on VolumeSnapshots and holds the name of the PersistentVolumeClaim they were taken from.
+	ClaimLabel = "monitoring.thanos.io/volume-claim"
+	// RestoredFromAnnotation is set on PersistentVolumeClaims restored from a VolumeSnapshot and holds its name.
+	RestoredFromAnnotation = "monitoring.thanos.io/restored-from-snapshot"
+
+	nameTimeFormat = "20060102150405"
+)
+
+var (
+	// GroupVersionKind is the kind of VolumeSnapshots.
+	GroupVersionKind = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+	// ListGroupVersionKind is the kind of lists of VolumeSnapshots.
+	ListGroupVersionKind = GroupVersionKind.GroupVersion().WithKind("VolumeSnapshotList")
+)
+
+// Snapshot is a VolumeSnapshot of a PersistentVolumeClaim.
+type Snapshot struct {
+	Name       string
+	Claim      string
+	Created    time.Time
+	ReadyToUse bool
+}
+
+// FromUnstructured returns the Snapshot of the given VolumeSnapshot.
+func FromUnstructured(u unstructured.Unstructured) Snapshot {
+	ready, _, _ := unstructured.NestedBool(u.Object, "status", "readyToUse")
+	return Snapshot{
+		Name:       u.GetName(),
+		Claim:      u.GetLabels()[ClaimLabel],
+		Created:    u.GetCreationTimestamp().Time,
+		ReadyToUse: ready,
+	}
+}
+
+// New returns a VolumeSnapshot of the given PersistentVolumeClaim, named after the claim and the given time.
+// If class is empty, the default VolumeSnapshotClass of the cluster is used.
+func New(claim, namespace, class string, labels map[string]string, now time.Time) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(GroupVersionKind)
+	u.SetName(fmt.Sprintf("%s-%s", claim, now.UTC().Format(nameTimeFormat)))
+	u.SetNamespace(namespace)
+
+	snapshotLabels := make(map[string]string, len(labels)+1)
+	maps.Copy(snapshotLabels, labels)
+	snapshotLabels[ClaimLabel] = claim
+	u.SetLabels(snapshotLabels)
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"persistentVolumeClaimName": claim},
+	}
+	if class != "" {
+		spec["volumeSnapshotClassName"] = class
+	}
+	u.Object["spec"] = spec
+	return u
+}
+
+// Plan decides on the snapshots of a single PersistentVolumeClaim, given its existing snapshots.
+// A snapshot is due if no snapshot was taken within the interval. The most recent retain snapshots are kept
+// and older ones are pruned, except for the most recent snapshot which is ready to use.
+// It returns whether a snapshot is due, the names of the snapshots to prune and the time the next snapshot is due.
+func Plan(snapshots []Snapshot, now time.Time, interval time.Duration, retain int) (bool, []string, time.Time) {
+	sorted := sortByCreation(snapshots)
+
+	due := len(sorted) == 0 || !now.Before(sorted[0].Created.Add(interval))
+	next := now.Add(interval)
+	if !due {
+		next = sorted[0].Created.Add(interval)
+	}
+	if due {
+		// the snapshot about to be taken counts towards the retained snapshots
+		retain--
+	}
+
+	latestReady, hasReady := LatestReady(sorted)
+	var prune []string
+	for i, s := range sorted {
+		if i < retain || (hasReady && s.Name == latestReady.Name) {
+			continue
+		}
+		prune = append(prune, s.Name)
+	}
+	return due, prune, next
+}
+
+// LatestReady returns the most recent snapshot which is ready to use.
+func LatestReady(snapshots []Snapshot) (Snapshot, bool) {
+	for _, s := range sortByCreation(snapshots) {
+		if s.ReadyToUse {
+			return s, true
+		}
+	}
+	return Snapshot{}, false
+}
+
+// RestoredClaim returns a PersistentVolumeClaim of the given size populated from the given snapshot.
+func RestoredClaim(name, namespace string, from Snapshot, size resource.Quantity, labels map[string]string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: map[string]string{RestoredFromAnnotation: from.Name},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To(GroupVersionKind.Group),
+				Kind:     GroupVersionKind.Kind,
+				Name:     from.Name,
+			},
+		},
+	}
+}
+
+// sortByCreation returns a copy of the snapshots sorted from the most to the least recent.
+func sortByCreation(snapshots []Snapshot) []Snapshot {
+	sorted := make([]Snapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Created.After(sorted[j].Created)
+	})
+	return sorted
+}
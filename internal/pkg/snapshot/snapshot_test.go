@@ -0,0 +1,97 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNew(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	u := New("data-ingester-0", "ns", "csi-snapclass", map[string]string{"app": "thanos"}, now)
+	u.SetCreationTimestamp(metav1.NewTime(now))
+	if u.GetName() != "data-ingester-0-20240501123000" {
+		t.Errorf("unexpected name %s", u.GetName())
+	}
+	if class := u.Object["spec"].(map[string]interface{})["volumeSnapshotClassName"]; class != "csi-snapclass" {
+		t.Errorf("unexpected class %v", class)
+	}
+
+	s := FromUnstructured(*u)
+	if s.Name != u.GetName() || s.Claim != "data-ingester-0" || !s.Created.Equal(now) || s.ReadyToUse {
+		t.Errorf("unexpected snapshot %v", s)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := func(name string, age time.Duration, ready bool) Snapshot {
+		return Snapshot{Name: name, Created: now.Add(-age), ReadyToUse: ready}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		snapshots []Snapshot
+		due       bool
+		prune     []string
+		next      time.Time
+	}{
+		{
+			name: "no snapshots",
+			due:  true,
+			next: now.Add(time.Hour),
+		},
+		{
+			name:      "recent snapshot",
+			snapshots: []Snapshot{snapshot("a", 20*time.Minute, true)},
+			next:      now.Add(40 * time.Minute),
+		},
+		{
+			name: "prune beyond retention",
+			snapshots: []Snapshot{
+				snapshot("c", 3*time.Hour, true),
+				snapshot("a", time.Hour, true),
+				snapshot("b", 2*time.Hour, true),
+			},
+			due:   true,
+			prune: []string{"b", "c"},
+			next:  now.Add(time.Hour),
+		},
+		{
+			name: "keep latest ready snapshot",
+			snapshots: []Snapshot{
+				snapshot("a", 90*time.Minute, false),
+				snapshot("b", 3*time.Hour, true),
+				snapshot("c", 5*time.Hour, true),
+			},
+			due:   true,
+			prune: []string{"c"},
+			next:  now.Add(time.Hour),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			due, prune, next := Plan(tc.snapshots, now, time.Hour, 2)
+			if due != tc.due || !reflect.DeepEqual(prune, tc.prune) || !next.Equal(tc.next) {
+				t.Errorf("expected %v %v %v, got %v %v %v", tc.due, tc.prune, tc.next, due, prune, next)
+			}
+		})
+	}
+}
+
+func TestLatestReady(t *testing.T) {
+	now := time.Now()
+	snapshots := []Snapshot{
+		{Name: "old", Created: now.Add(-2 * time.Hour), ReadyToUse: true},
+		{Name: "new", Created: now, ReadyToUse: false},
+		{Name: "mid", Created: now.Add(-time.Hour), ReadyToUse: true},
+	}
+	s, ok := LatestReady(snapshots)
+	if !ok || s.Name != "mid" {
+		t.Errorf("expected snapshot mid, got %v", s)
+	}
+	if _, ok := LatestReady(snapshots[1:2]); ok {
+		t.Error("expected no snapshot ready to use")
+	}
+}
@@ -0,0 +1,292 @@
+// Package uninstall removes Thanos resources together with the objects generated for them,
+// so that the operator can be removed without stranding resources behind its finalizers.
+// It does not rely on the operator running: finalizers are removed by the uninstaller and the
+// clean up they would otherwise perform is done directly.
+package uninstall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifeststenant "github.com/thanos-community/thanos-operator/pkg/manifests/tenant"
+)
+
+//+kubebuilder:rbac:groups="",resources=events,verbs=list;delete
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=patch
+
+// finalizerPrefix is the prefix of the finalizers the operator adds to resources.
+var finalizerPrefix = monitoringthanosiov1alpha1.GroupVersion.Group + "/"
+
+// Options select the resources to remove.
+type Options struct {
+	// Namespace restricts the removal to a single namespace. All namespaces are considered if empty.
+	Namespace string
+	// Selector restricts the removal to resources with matching labels. All resources are removed if nil.
+	Selector labels.Selector
+	// RetainVolumes keeps the PersistentVolumeClaims of removed StatefulSets.
+	// The claims are detached from the StatefulSets, so that they survive the deletion regardless of
+	// the PersistentVolumeClaim retention policy of the StatefulSets.
+	RetainVolumes bool
+}
+
+// Summary counts the objects removed by an uninstallation.
+type Summary struct {
+	// Resources is the number of Thanos resources deleted.
+	Resources int
+	// VolumeClaims is the number of PersistentVolumeClaims deleted, or retained if RetainVolumes is set.
+	VolumeClaims int
+	// Events is the number of Events deleted.
+	Events int
+}
+
+// newLists returns lists for the kinds of Thanos resources in the order they are removed.
+// Resources reading from others are removed first, so that no remaining component is left
+// pointing at removed ones: tenants configure receivers and queriers read from all other components.
+func newLists() []client.ObjectList {
+	return []client.ObjectList{
+		&monitoringthanosiov1alpha1.ThanosTenantList{},
+		&monitoringthanosiov1alpha1.ThanosQueryList{},
+		&monitoringthanosiov1alpha1.ThanosRulerList{},
+		&monitoringthanosiov1alpha1.ThanosCompactList{},
+		&monitoringthanosiov1alpha1.ThanosStoreList{},
+		&monitoringthanosiov1alpha1.ThanosReceiveList{},
+		&monitoringthanosiov1alpha1.ThanosToolsList{},
+	}
+}
+
+// Uninstaller removes Thanos resources and the objects generated for them.
+type Uninstaller struct {
+	client client.Client
+	logger logr.Logger
+	opts   Options
+}
+
+// New returns an Uninstaller removing the resources selected by opts.
+func New(c client.Client, logger logr.Logger, opts Options) *Uninstaller {
+	return &Uninstaller{client: c, logger: logger, opts: opts}
+}
+
+// Run removes the selected resources. For each resource it handles the PersistentVolumeClaims of its StatefulSets,
+// deletes objects the operator would delete when finalizing it, removes the finalizers of the operator,
+// deletes the resource and deletes its Events. Objects owned by the resource are removed by the garbage collector.
+// Failures do not stop the removal of other resources; they are joined in the returned error.
+func (u *Uninstaller) Run(ctx context.Context) (Summary, error) {
+	var summary Summary
+	var errs []error
+	listOpts := []client.ListOption{client.InNamespace(u.opts.Namespace)}
+	if u.opts.Selector != nil {
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: u.opts.Selector})
+	}
+
+	for _, list := range newLists() {
+		if err := u.client.List(ctx, list, listOpts...); err != nil {
+			errs = append(errs, fmt.Errorf("failed to list %T: %w", list, err))
+			continue
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+			if err := u.remove(ctx, obj, &summary); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove %T %s/%s: %w", obj, obj.GetNamespace(), obj.GetName(), err))
+			}
+		}
+	}
+	return summary, errors.Join(errs...)
+}
+
+func (u *Uninstaller) remove(ctx context.Context, obj client.Object, summary *Summary) error {
+	logger := u.logger.WithValues("kind", fmt.Sprintf("%T", obj), "name", obj.GetName(), "namespace", obj.GetNamespace())
+
+	claims, err := u.handleVolumeClaims(ctx, obj)
+	summary.VolumeClaims += claims
+	if err != nil {
+		return err
+	}
+
+	if tenant, ok := obj.(*monitoringthanosiov1alpha1.ThanosTenant); ok {
+		opts := manifeststenant.Options{Options: manifests.Options{Owner: tenant.GetName(), Namespace: tenant.GetNamespace()}}
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: opts.GetGeneratedResourceName(), Namespace: tenant.Spec.TargetNamespace}}
+		if err := u.client.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete remote write configuration: %w", err)
+		}
+		// Prometheus remote write Secrets are published to any selected namespace
+		// and are told apart from the Secrets of same-named tenants by the namespace of the tenant
+		secrets := &corev1.SecretList{}
+		if err := u.client.List(ctx, secrets, client.MatchingLabels(opts.GetPrometheusRemoteWriteSelectorLabels())); err != nil {
+			return fmt.Errorf("failed to list Prometheus remote write Secrets: %w", err)
+		}
+		for _, secret := range secrets.Items {
+			if err := u.client.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete Prometheus remote write Secret: %w", err)
+			}
+		}
+	}
+
+	if err := u.removeFinalizers(ctx, obj); err != nil {
+		return err
+	}
+	if err := u.client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	summary.Resources++
+
+	events, err := u.deleteEvents(ctx, obj)
+	summary.Events += events
+	if err != nil {
+		return err
+	}
+	logger.Info("removed resource", "volumeClaims", claims, "events", events, "retainVolumes", u.opts.RetainVolumes)
+	return nil
+}
+
+// handleVolumeClaims deletes or retains the PersistentVolumeClaims of the StatefulSets controlled by obj.
+// It returns the number of claims deleted or retained.
+func (u *Uninstaller) handleVolumeClaims(ctx context.Context, obj client.Object) (int, error) {
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := u.client.List(ctx, statefulSets, client.InNamespace(obj.GetNamespace())); err != nil {
+		return 0, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := u.client.List(ctx, claims, client.InNamespace(obj.GetNamespace())); err != nil {
+		return 0, fmt.Errorf("failed to list persistent volume claims: %w", err)
+	}
+
+	var count int
+	for i := range statefulSets.Items {
+		sts := &statefulSets.Items[i]
+		if !metav1.IsControlledBy(sts, obj) {
+			continue
+		}
+		if u.opts.RetainVolumes {
+			if err := u.retainVolumeClaims(ctx, sts); err != nil {
+				return count, err
+			}
+		}
+		for j := range claims.Items {
+			pvc := &claims.Items[j]
+			if !isClaimOf(pvc, sts) {
+				continue
+			}
+			if u.opts.RetainVolumes {
+				if err := u.detachVolumeClaim(ctx, pvc, sts); err != nil {
+					return count, err
+				}
+			} else if err := u.client.Delete(ctx, pvc); client.IgnoreNotFound(err) != nil {
+				return count, fmt.Errorf("failed to delete persistent volume claim %s: %w", pvc.GetName(), err)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// retainVolumeClaims sets the PersistentVolumeClaim retention policy of the StatefulSet to Retain,
+// so that the StatefulSet controller does not delete the claims when the StatefulSet is deleted.
+func (u *Uninstaller) retainVolumeClaims(ctx context.Context, sts *appsv1.StatefulSet) error {
+	retain := &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+	if p := sts.Spec.PersistentVolumeClaimRetentionPolicy; p != nil && *p == *retain {
+		return nil
+	}
+	patch := client.MergeFrom(sts.DeepCopy())
+	sts.Spec.PersistentVolumeClaimRetentionPolicy = retain
+	if err := u.client.Patch(ctx, sts, patch); err != nil {
+		return fmt.Errorf("failed to retain persistent volume claims of statefulset %s: %w", sts.GetName(), err)
+	}
+	return nil
+}
+
+// detachVolumeClaim removes the owner reference to the StatefulSet from the claim,
+// which the StatefulSet controller adds if the claims are deleted along with the StatefulSet.
+func (u *Uninstaller) detachVolumeClaim(ctx context.Context, pvc *corev1.PersistentVolumeClaim, sts *appsv1.StatefulSet) error {
+	refs := pvc.GetOwnerReferences()
+	kept := make([]metav1.OwnerReference, 0, len(refs))
+	for _, ref := range refs {
+		if ref.UID != sts.GetUID() {
+			kept = append(kept, ref)
+		}
+	}
+	if len(kept) == len(refs) {
+		return nil
+	}
+	patch := client.MergeFrom(pvc.DeepCopy())
+	pvc.SetOwnerReferences(kept)
+	if err := u.client.Patch(ctx, pvc, patch); err != nil {
+		return fmt.Errorf("failed to detach persistent volume claim %s: %w", pvc.GetName(), err)
+	}
+	return nil
+}
+
+// isClaimOf reports whether the claim was created from a volume claim template of the StatefulSet.
+// Such claims are named <template>-<statefulset>-<ordinal>.
+func isClaimOf(pvc *corev1.PersistentVolumeClaim, sts *appsv1.StatefulSet) bool {
+	for _, tpl := range sts.Spec.VolumeClaimTemplates {
+		ordinal, ok := strings.CutPrefix(pvc.GetName(), tpl.GetName()+"-"+sts.GetName()+"-")
+		if ok && ordinal != "" && strings.Trim(ordinal, "0123456789") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFinalizers removes the finalizers of the operator from obj, so that its deletion does not
+// wait for the operator. Finalizers of other controllers are kept.
+func (u *Uninstaller) removeFinalizers(ctx context.Context, obj client.Object) error {
+	finalizers := obj.GetFinalizers()
+	kept := make([]string, 0, len(finalizers))
+	for _, f := range finalizers {
+		if !strings.HasPrefix(f, finalizerPrefix) {
+			kept = append(kept, f)
+		}
+	}
+	if len(kept) == len(finalizers) {
+		return nil
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	obj.SetFinalizers(kept)
+	if err := u.client.Patch(ctx, obj, patch); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to remove finalizers: %w", err)
+	}
+	return nil
+}
+
+// deleteEvents deletes the Events recorded for obj. It returns the number of Events deleted.
+func (u *Uninstaller) deleteEvents(ctx context.Context, obj client.Object) (int, error) {
+	events := &corev1.EventList{}
+	if err := u.client.List(ctx, events, client.InNamespace(obj.GetNamespace())); err != nil {
+		return 0, fmt.Errorf("failed to list events: %w", err)
+	}
+	var count int
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.InvolvedObject.UID != obj.GetUID() {
+			continue
+		}
+		if err := u.client.Delete(ctx, event); client.IgnoreNotFound(err) != nil {
+			return count, fmt.Errorf("failed to delete event %s: %w", event.GetName(), err)
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,203 @@
+package uninstall
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifeststenant "github.com/thanos-community/thanos-operator/pkg/manifests/tenant"
+)
+
+const ns = "monitoring"
+
+func newClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := monitoringthanosiov1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+// newStore returns a ThanosStore with a StatefulSet of two replicas, their claims and an Event.
+func newStore(name, uid string, storeLabels map[string]string) []client.Object {
+	store := &monitoringthanosiov1alpha1.ThanosStore{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, UID: types.UID(uid), Labels: storeLabels},
+	}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-shard-0",
+			Namespace: ns,
+			UID:       types.UID(uid + "-sts"),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: monitoringthanosiov1alpha1.GroupVersion.String(),
+				Kind:       "ThanosStore",
+				Name:       name,
+				UID:        types.UID(uid),
+				Controller: ptr.To(true),
+			}},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+		},
+	}
+	objs := []client.Object{store, sts}
+	for _, claim := range []string{"data-" + sts.Name + "-0", "data-" + sts.Name + "-1"} {
+		objs = append(objs, &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      claim,
+				Namespace: ns,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "StatefulSet",
+					Name:       sts.Name,
+					UID:        sts.UID,
+				}},
+			},
+		})
+	}
+	objs = append(objs, &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name + ".event", Namespace: ns},
+		InvolvedObject: corev1.ObjectReference{Kind: "ThanosStore", Name: name, Namespace: ns, UID: types.UID(uid)},
+	})
+	return objs
+}
+
+func TestUninstall(t *testing.T) {
+	ctx := context.Background()
+	tenant := &monitoringthanosiov1alpha1.ThanosTenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "tenant",
+			Namespace:  ns,
+			UID:        "tenant-uid",
+			Finalizers: []string{"monitoring.thanos.io/tenant-finalizer", "example.com/other"},
+		},
+		Spec: monitoringthanosiov1alpha1.ThanosTenantSpec{TargetNamespace: "receive"},
+	}
+	remoteWrite := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "thanos-tenant-tenant", Namespace: "receive"}}
+	prometheusRemoteWrite := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      "thanos-tenant-tenant",
+		Namespace: "team-a",
+		Labels:    manifeststenant.Options{Options: manifests.Options{Owner: "tenant", Namespace: ns}}.GetPrometheusRemoteWriteSelectorLabels(),
+	}}
+	// published by a same-named tenant in a namespace which is not uninstalled
+	otherPrometheusRemoteWrite := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      "thanos-tenant-tenant",
+		Namespace: "team-b",
+		Labels:    manifeststenant.Options{Options: manifests.Options{Owner: "tenant", Namespace: "other"}}.GetPrometheusRemoteWriteSelectorLabels(),
+	}}
+	unrelatedClaim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-other-0", Namespace: ns}}
+
+	objs := append(newStore("store", "store-uid", nil), tenant, remoteWrite, prometheusRemoteWrite, otherPrometheusRemoteWrite, unrelatedClaim)
+	c := newClient(t, objs...)
+
+	summary, err := New(c, logr.Discard(), Options{Namespace: ns}).Run(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != (Summary{Resources: 2, VolumeClaims: 2, Events: 1}) {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: "store"}, &monitoringthanosiov1alpha1.ThanosStore{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected store to be deleted, got %v", err)
+	}
+	for _, claim := range []string{"data-store-shard-0-0", "data-store-shard-0-1"} {
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: claim}, &corev1.PersistentVolumeClaim{}); !apierrors.IsNotFound(err) {
+			t.Errorf("expected claim %s to be deleted, got %v", claim, err)
+		}
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(unrelatedClaim), &corev1.PersistentVolumeClaim{}); err != nil {
+		t.Errorf("expected unrelated claim to be kept, got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(remoteWrite), &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected remote write configuration to be deleted, got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(prometheusRemoteWrite), &corev1.Secret{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected Prometheus remote write Secret to be deleted, got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(otherPrometheusRemoteWrite), &corev1.Secret{}); err != nil {
+		t.Errorf("expected Prometheus remote write Secret of another tenant to be kept, got %v", err)
+	}
+
+	// The finalizer of another controller keeps the tenant around, but the finalizer of the operator is removed.
+	got := &monitoringthanosiov1alpha1.ThanosTenant{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(tenant), got); err != nil {
+		t.Fatalf("failed to get tenant: %v", err)
+	}
+	if got.DeletionTimestamp == nil {
+		t.Error("expected tenant to be marked for deletion")
+	}
+	if len(got.Finalizers) != 1 || got.Finalizers[0] != "example.com/other" {
+		t.Errorf("unexpected finalizers: %v", got.Finalizers)
+	}
+}
+
+func TestUninstallRetainVolumes(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t, newStore("store", "store-uid", nil)...)
+
+	summary, err := New(c, logr.Discard(), Options{Namespace: ns, RetainVolumes: true}).Run(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.VolumeClaims != 2 {
+		t.Fatalf("expected 2 retained claims, got %d", summary.VolumeClaims)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: "store-shard-0"}, sts); err != nil {
+		t.Fatalf("failed to get statefulset: %v", err)
+	}
+	policy := sts.Spec.PersistentVolumeClaimRetentionPolicy
+	if policy == nil || policy.WhenDeleted != appsv1.RetainPersistentVolumeClaimRetentionPolicyType {
+		t.Errorf("expected claims to be retained on deletion, got %v", policy)
+	}
+	for _, claim := range []string{"data-store-shard-0-0", "data-store-shard-0-1"} {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: claim}, pvc); err != nil {
+			t.Fatalf("expected claim %s to be kept, got %v", claim, err)
+		}
+		if len(pvc.OwnerReferences) != 0 {
+			t.Errorf("expected claim %s to be detached from the statefulset, got %v", claim, pvc.OwnerReferences)
+		}
+	}
+}
+
+func TestUninstallSelector(t *testing.T) {
+	ctx := context.Background()
+	objs := append(newStore("selected", "selected-uid", map[string]string{"team": "a"}),
+		newStore("other", "other-uid", map[string]string{"team": "b"})...)
+	c := newClient(t, objs...)
+
+	summary, err := New(c, logr.Discard(), Options{Selector: labels.SelectorFromSet(labels.Set{"team": "a"})}).Run(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != (Summary{Resources: 1, VolumeClaims: 2, Events: 1}) {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: "other"}, &monitoringthanosiov1alpha1.ThanosStore{}); err != nil {
+		t.Errorf("expected unselected store to be kept, got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: "data-other-shard-0-0"}, &corev1.PersistentVolumeClaim{}); err != nil {
+		t.Errorf("expected claim of unselected store to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+// Package versionpolicy implements the operator level policy restricting the Thanos versions deployed per component
+// to the ranges the operator was tested with, protecting fleets from accidentally deploying incompatible versions.
+package versionpolicy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AnyComponent is the component name of a range which applies to all components without a range of their own.
+const AnyComponent = "*"
+
+// ErrNotAllowed is returned when a version is outside the allowed range of its component.
+var ErrNotAllowed = errors.New("version not allowed by policy")
+
+// Policy holds the allowed version range per component.
+// A nil Policy allows all versions.
+type Policy struct {
+	ranges map[string]Range
+	strict bool
+}
+
+// NewPolicy creates a new Policy from the given ranges, keyed by component name.
+// If strict is set, resources requesting a version outside the allowed range of a component are rejected,
+// otherwise they are only flagged.
+// It returns a nil Policy if no ranges are given.
+func NewPolicy(ranges map[string]string, strict bool) (*Policy, error) {
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+
+	p := &Policy{ranges: make(map[string]Range, len(ranges)), strict: strict}
+	for component, s := range ranges {
+		r, err := ParseRange(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range for component %s: %w", component, err)
+		}
+		p.ranges[component] = r
+	}
+	return p, nil
+}
+
+// ParseRanges parses a semicolon separated list of component=range entries,
+// e.g. "query=>=v0.35.0 <v0.38.0;*=>=v0.34.0", into ranges keyed by component name.
+func ParseRanges(s string) (map[string]string, error) {
+	ranges := make(map[string]string)
+	for _, entry := range strings.Split(s, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		component, r, ok := strings.Cut(entry, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" || strings.ContainsAny(component, "<>!= ") {
+			return nil, fmt.Errorf("invalid entry %q, expected component=range", entry)
+		}
+		if _, exists := ranges[component]; exists {
+			return nil, fmt.Errorf("duplicate range for component %s", component)
+		}
+		ranges[component] = r
+	}
+	return ranges, nil
+}
+
+// Strict returns true if resources requesting a version outside the allowed range are rejected.
+func (p *Policy) Strict() bool {
+	return p != nil && p.strict
+}
+
+// Check returns an error wrapping ErrNotAllowed if the version is outside the allowed range of the component,
+// or is not a semantic version while a range applies.
+func (p *Policy) Check(component, version string) error {
+	if p == nil {
+		return nil
+	}
+	r, ok := p.ranges[component]
+	if !ok {
+		if r, ok = p.ranges[AnyComponent]; !ok {
+			return nil
+		}
+	}
+
+	v, err := parseVersion(version)
+	if err != nil {
+		return fmt.Errorf("%w: %s version %s: %v", ErrNotAllowed, component, version, err)
+	}
+	if !r.contains(v) {
+		return fmt.Errorf("%w: %s version %s is outside the allowed range %s", ErrNotAllowed, component, version, r)
+	}
+	return nil
+}
+
+// CheckAll checks the versions requested for each component and returns the errors of all components,
+// in the order of their names.
+func (p *Policy) CheckAll(versions map[string][]string) error {
+	components := make([]string, 0, len(versions))
+	for c := range versions {
+		components = append(components, c)
+	}
+	sort.Strings(components)
+
+	var errs []error
+	for _, c := range components {
+		seen := make(map[string]bool, len(versions[c]))
+		for _, v := range versions[c] {
+			if !seen[v] {
+				seen[v] = true
+				errs = append(errs, p.Check(c, v))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Range is a set of constraints which a version must all satisfy, e.g. ">=v0.35.0 <v0.38.0".
+type Range struct {
+	raw         string
+	constraints []constraint
+}
+
+type constraint struct {
+	op      string
+	version version
+}
+
+// ParseRange parses a space separated list of constraints, each made of one of the operators =, !=, >, >=, <, <=
+// followed by a version. A version without operator must match exactly.
+func ParseRange(s string) (Range, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return Range{}, fmt.Errorf("empty version range")
+	}
+
+	r := Range{raw: strings.Join(fields, " ")}
+	for _, f := range fields {
+		op := "="
+		for _, candidate := range []string{">=", "<=", "!=", ">", "<", "="} {
+			if strings.HasPrefix(f, candidate) {
+				op = candidate
+				f = strings.TrimPrefix(f, candidate)
+				break
+			}
+		}
+		v, err := parseVersion(f)
+		if err != nil {
+			return Range{}, err
+		}
+		r.constraints = append(r.constraints, constraint{op: op, version: v})
+	}
+	return r, nil
+}
+
+func (r Range) String() string {
+	return r.raw
+}
+
+func (r Range) contains(v version) bool {
+	for _, c := range r.constraints {
+		cmp := v.compare(c.version)
+		var ok bool
+		switch c.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// version is a semantic version of the form [v]MAJOR.MINOR.PATCH[-PRERELEASE].
+// Build metadata is ignored.
+type version struct {
+	parts      [3]int
+	prerelease string
+}
+
+func parseVersion(s string) (version, error) {
+	raw := s
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+
+	var v version
+	if i := strings.Index(s, "-"); i >= 0 {
+		v.prerelease = s[i+1:]
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return version{}, fmt.Errorf("%q is not a semantic version", raw)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return version{}, fmt.Errorf("%q is not a semantic version", raw)
+		}
+		v.parts[i] = n
+	}
+	return v, nil
+}
+
+// compare returns -1, 0 or 1 if v is lower than, equal to or greater than o.
+// A pre-release is lower than the release of the same version. Pre-releases are compared lexically.
+func (v version) compare(o version) int {
+	for i := range v.parts {
+		if v.parts[i] != o.parts[i] {
+			if v.parts[i] < o.parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.prerelease == o.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case o.prerelease == "":
+		return -1
+	case v.prerelease < o.prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,94 @@
+package versionpolicy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	for _, s := range []string{"", ">=", ">=v0.35", "~v0.35.0", ">=v0.35.0 <latest"} {
+		if _, err := ParseRange(s); err == nil {
+			t.Errorf("expected error for range %q", s)
+		}
+	}
+	r, err := ParseRange(">=v0.35.0   <v0.38.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.String() != ">=v0.35.0 <v0.38.0" {
+		t.Errorf("unexpected range %s", r)
+	}
+}
+
+func TestParseRanges(t *testing.T) {
+	ranges, err := ParseRanges("query=>=v0.35.0 <v0.38.0; *=>=v0.34.0;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 2 || ranges["query"] != ">=v0.35.0 <v0.38.0" || ranges[AnyComponent] != ">=v0.34.0" {
+		t.Errorf("unexpected ranges %v", ranges)
+	}
+	for _, s := range []string{">=v0.35.0", "query=v0.35.0;query=v0.36.0"} {
+		if _, err := ParseRanges(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	p, err := NewPolicy(map[string]string{
+		"query":      ">=v0.35.0 <v0.38.0",
+		"compact":    "v0.36.1",
+		AnyComponent: ">=0.34.0",
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		component string
+		version   string
+		allowed   bool
+	}{
+		{component: "query", version: "v0.35.0", allowed: true},
+		{component: "query", version: "v0.37.2", allowed: true},
+		{component: "query", version: "v0.38.0", allowed: false},
+		{component: "query", version: "v0.38.0-rc.0", allowed: true},
+		{component: "query", version: "v0.35.0-rc.1", allowed: false},
+		{component: "query", version: "v0.34.1", allowed: false},
+		{component: "query", version: "latest", allowed: false},
+		{component: "compact", version: "v0.36.1", allowed: true},
+		{component: "compact", version: "v0.36.1+build", allowed: true},
+		{component: "compact", version: "v0.36.2", allowed: false},
+		{component: "store", version: "v0.34.0", allowed: true},
+		{component: "store", version: "v0.33.0", allowed: false},
+	} {
+		err := p.Check(tc.component, tc.version)
+		if tc.allowed && err != nil {
+			t.Errorf("expected %s %s to be allowed, got %v", tc.component, tc.version, err)
+		}
+		if !tc.allowed && !errors.Is(err, ErrNotAllowed) {
+			t.Errorf("expected %s %s to not be allowed, got %v", tc.component, tc.version, err)
+		}
+	}
+
+	if err := p.CheckAll(map[string][]string{"query": {"v0.36.0", "v0.36.0"}}); err != nil {
+		t.Errorf("expected versions to be allowed, got %v", err)
+	}
+	if err := p.CheckAll(map[string][]string{"query": {"v0.36.0"}, "compact": {"v0.35.0"}, "store": {"v0.30.0"}}); err == nil {
+		t.Error("expected errors for compact and store")
+	}
+}
+
+func TestNilPolicy(t *testing.T) {
+	p, err := NewPolicy(nil, true)
+	if err != nil || p != nil {
+		t.Fatalf("expected nil policy without ranges, got %v, %v", p, err)
+	}
+	if err := p.Check("query", "main"); err != nil {
+		t.Errorf("expected nil policy to allow all versions, got %v", err)
+	}
+	if p.Strict() {
+		t.Error("expected nil policy to not be strict")
+	}
+}
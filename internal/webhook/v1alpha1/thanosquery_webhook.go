@@ -0,0 +1,107 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+)
+
+// SetupThanosQueryWebhookWithManager registers the validating webhook for ThanosQuery in the manager.
+func SetupThanosQueryWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&monitoringthanosiov1alpha1.ThanosQuery{}).
+		WithValidator(&ThanosQueryCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-monitoring-thanos-io-v1alpha1-thanosquery,mutating=false,failurePolicy=fail,sideEffects=None,groups=monitoring.thanos.io,resources=thanosqueries,verbs=create;update,versions=v1alpha1,name=vthanosquery-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ThanosQueryCustomValidator validates the durations, split intervals and replica counts of a ThanosQuery.
+type ThanosQueryCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ThanosQueryCustomValidator{}
+
+// ValidateCreate validates a ThanosQuery upon creation.
+func (v *ThanosQueryCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	query, ok := obj.(*monitoringthanosiov1alpha1.ThanosQuery)
+	if !ok {
+		return nil, fmt.Errorf("expected a ThanosQuery object but got %T", obj)
+	}
+	return validateThanosQuery(query)
+}
+
+// ValidateUpdate validates a ThanosQuery upon update.
+func (v *ThanosQueryCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	query, ok := newObj.(*monitoringthanosiov1alpha1.ThanosQuery)
+	if !ok {
+		return nil, fmt.Errorf("expected a ThanosQuery object for the newObj but got %T", newObj)
+	}
+	return validateThanosQuery(query)
+}
+
+// ValidateDelete does not validate deletions of ThanosQuery.
+func (v *ThanosQueryCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateThanosQuery(query *monitoringthanosiov1alpha1.ThanosQuery) (admission.Warnings, error) {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+	var warnings admission.Warnings
+
+	if query.Spec.Autoscaling == nil {
+		errs = append(errs, validateReplicas(spec.Child("replicas"), query.Spec.Replicas)...)
+		if w := disruptionWarning(spec.Child("podDisruptionConfig"), query.Spec.PodDisruptionConfig, query.Spec.Replicas); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+	errs = append(errs, validatePositiveDuration(spec.Child("timeout"), query.Spec.Timeout)...)
+	timeout := parseDuration(spec.Child("timeout"), query.Spec.Timeout, new(field.ErrorList))
+	parseDuration(spec.Child("lookbackDelta"), query.Spec.LookbackDelta, &errs)
+	errs = append(errs, validateGRPCServer(spec.Child("grpcServer"), query.Spec.GRPCServer)...)
+	errs = append(errs, validateGRPCProbes(spec.Child("probes"), query.Spec.Probes, query.Spec.GRPCServerTLS)...)
+
+	for i, group := range query.Spec.EndpointGroups {
+		path := spec.Child("endpointGroups").Index(i)
+		errs = append(errs, validateReplicas(path.Child("replicas"), group.Replicas)...)
+		errs = append(errs, validatePositiveDuration(path.Child("responseTimeout"), group.ResponseTimeout)...)
+	}
+	for i, pool := range query.Spec.QueryPools {
+		path := spec.Child("queryPools").Index(i)
+		errs = append(errs, validateReplicas(path.Child("replicas"), pool.Replicas)...)
+		errs = append(errs, validatePositiveDuration(path.Child("timeout"), pool.Timeout)...)
+	}
+
+	if frontend := query.Spec.QueryFrontend; frontend != nil {
+		path := spec.Child("queryFrontend")
+		if frontend.Autoscaling == nil {
+			errs = append(errs, validateReplicas(path.Child("replicas"), frontend.Replicas)...)
+		}
+		errs = append(errs, validateCacheConfig(path.Child("queryRangeResponseCacheConfig"), frontend.QueryRangeResponseCacheConfig, managedMemcachedCache)...)
+		parseDuration(path.Child("queryRangeSplitInterval"), frontend.QueryRangeSplitInterval, &errs)
+
+		logLongerThan := parseDuration(path.Child("logQueriesLongerThan"), frontend.LogQueriesLongerThan, &errs)
+		if logLongerThan > 0 && timeout > 0 && logLongerThan >= timeout {
+			errs = append(errs, field.Invalid(path.Child("logQueriesLongerThan"), *frontend.LogQueriesLongerThan,
+				fmt.Sprintf("must be less than timeout %s", timeout)))
+		}
+		labelsSplit := parseDuration(path.Child("labelsSplitInterval"), frontend.LabelsSplitInterval, &errs)
+		labelsRange := parseDuration(path.Child("labelsDefaultTimeRange"), frontend.LabelsDefaultTimeRange, &errs)
+		if labelsSplit > 0 && labelsRange > 0 && labelsSplit > labelsRange {
+			errs = append(errs, field.Invalid(path.Child("labelsSplitInterval"), *frontend.LabelsSplitInterval,
+				fmt.Sprintf("must not be larger than labelsDefaultTimeRange %s", labelsRange)))
+		}
+	}
+
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(monitoringthanosiov1alpha1.GroupVersion.WithKind("ThanosQuery").GroupKind(), query.Name, errs)
+}
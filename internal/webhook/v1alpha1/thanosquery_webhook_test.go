@@ -0,0 +1,178 @@
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+)
+
+func TestThanosQueryCustomValidator(t *testing.T) {
+	duration := func(d string) *monitoringthanosiov1alpha1.Duration {
+		return ptr.To(monitoringthanosiov1alpha1.Duration(d))
+	}
+	valid := func() *monitoringthanosiov1alpha1.ThanosQuery {
+		return &monitoringthanosiov1alpha1.ThanosQuery{
+			Spec: monitoringthanosiov1alpha1.ThanosQuerySpec{
+				Replicas: 2,
+				Timeout:  duration("15m"),
+				QueryFrontend: &monitoringthanosiov1alpha1.QueryFrontendSpec{
+					Replicas:               1,
+					LogQueriesLongerThan:   duration("10s"),
+					LabelsSplitInterval:    duration("1d"),
+					LabelsDefaultTimeRange: duration("2w"),
+				},
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		mutate  func(*monitoringthanosiov1alpha1.ThanosQuery)
+		wantErr string
+		warns   bool
+	}{
+		{
+			name:   "valid",
+			mutate: func(*monitoringthanosiov1alpha1.ThanosQuery) {},
+		},
+		{
+			name:    "zero timeout",
+			mutate:  func(q *monitoringthanosiov1alpha1.ThanosQuery) { q.Spec.Timeout = duration("0s") },
+			wantErr: "spec.timeout",
+		},
+		{
+			name:    "invalid lookback delta",
+			mutate:  func(q *monitoringthanosiov1alpha1.ThanosQuery) { q.Spec.LookbackDelta = duration("5x") },
+			wantErr: "spec.lookbackDelta",
+		},
+		{
+			name: "no replicas",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.Replicas = 0
+			},
+			wantErr: "spec.replicas",
+		},
+		{
+			name: "no replicas with autoscaling",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.Replicas = 0
+				q.Spec.Autoscaling = &monitoringthanosiov1alpha1.AutoscalingConfig{MaxReplicas: 3}
+			},
+		},
+		{
+			name: "logging queries longer than the timeout",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.QueryFrontend.LogQueriesLongerThan = duration("1h")
+			},
+			wantErr: "spec.queryFrontend.logQueriesLongerThan",
+		},
+		{
+			name: "labels split interval larger than the default time range",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.QueryFrontend.LabelsSplitInterval = duration("3w")
+			},
+			wantErr: "spec.queryFrontend.labelsSplitInterval",
+		},
+		{
+			name: "cache item larger than the cache",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.QueryFrontend.QueryRangeResponseCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					InMemoryCacheConfig: &monitoringthanosiov1alpha1.InMemoryCacheConfig{
+						MaxSize:     ptr.To(monitoringthanosiov1alpha1.StorageSize("1Mi")),
+						MaxItemSize: ptr.To(monitoringthanosiov1alpha1.StorageSize("2Mi")),
+					},
+				}
+			},
+			wantErr: "spec.queryFrontend.queryRangeResponseCacheConfig.inMemoryCacheConfig.maxItemSize",
+		},
+		{
+			name: "memcached cache",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.QueryFrontend.QueryRangeResponseCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					MemcachedCacheConfig: &monitoringthanosiov1alpha1.MemcachedCacheConfig{
+						Addresses: []string{"memcached-0:11211", "dnssrv+_client._tcp.memcached.monitoring.svc"},
+					},
+				}
+			},
+		},
+		{
+			name: "memcached cache address without port",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.QueryFrontend.QueryRangeResponseCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					MemcachedCacheConfig: &monitoringthanosiov1alpha1.MemcachedCacheConfig{Addresses: []string{"dns+memcached"}},
+				}
+			},
+			wantErr: "spec.queryFrontend.queryRangeResponseCacheConfig.memcachedCacheConfig.addresses[0]",
+		},
+		{
+			name: "managed memcached cache",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.QueryFrontend.QueryRangeResponseCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					MemcachedCacheConfig: &monitoringthanosiov1alpha1.MemcachedCacheConfig{
+						Managed: &monitoringthanosiov1alpha1.ManagedMemcachedConfig{
+							Replicas:    ptr.To(int32(3)),
+							MemoryLimit: ptr.To(monitoringthanosiov1alpha1.StorageSize("512Mi")),
+							MaxItemSize: ptr.To(monitoringthanosiov1alpha1.StorageSize("2Mi")),
+						},
+					},
+				}
+			},
+		},
+		{
+			name: "managed memcached item larger than the memory",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.QueryFrontend.QueryRangeResponseCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					MemcachedCacheConfig: &monitoringthanosiov1alpha1.MemcachedCacheConfig{
+						Managed: &monitoringthanosiov1alpha1.ManagedMemcachedConfig{
+							MemoryLimit: ptr.To(monitoringthanosiov1alpha1.StorageSize("1Mi")),
+							MaxItemSize: ptr.To(monitoringthanosiov1alpha1.StorageSize("2Mi")),
+						},
+					},
+				}
+			},
+			wantErr: "spec.queryFrontend.queryRangeResponseCacheConfig.memcachedCacheConfig.managed.maxItemSize",
+		},
+		{
+			name: "managed redis cache",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.QueryFrontend.QueryRangeResponseCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					RedisCacheConfig: &monitoringthanosiov1alpha1.RedisCacheConfig{Managed: &monitoringthanosiov1alpha1.ManagedRedisConfig{}},
+				}
+			},
+			wantErr: "spec.queryFrontend.queryRangeResponseCacheConfig.redisCacheConfig.managed",
+		},
+		{
+			name: "query pool without replicas",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.QueryPools = []monitoringthanosiov1alpha1.QueryPool{{Name: "heavy"}}
+			},
+			wantErr: "spec.queryPools[0].replicas",
+		},
+		{
+			name: "disruption budget blocking drains",
+			mutate: func(q *monitoringthanosiov1alpha1.ThanosQuery) {
+				q.Spec.PodDisruptionConfig = &monitoringthanosiov1alpha1.PodDisruptionConfig{MinAvailable: ptr.To(int32(2))}
+			},
+			warns: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			query := valid()
+			tc.mutate(query)
+			warnings, err := (&ThanosQueryCustomValidator{}).ValidateCreate(context.Background(), query)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("expected error for %s, got %v", tc.wantErr, err)
+			}
+			if tc.warns != (len(warnings) > 0) {
+				t.Errorf("unexpected warnings %v", warnings)
+			}
+		})
+	}
+}
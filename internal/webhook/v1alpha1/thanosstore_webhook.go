@@ -0,0 +1,187 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+)
+
+// SetupThanosStoreWebhookWithManager registers the validating webhook for ThanosStore in the manager.
+// Secrets are read through the API reader, so that the webhook does not cache all Secrets of the cluster.
+func SetupThanosStoreWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&monitoringthanosiov1alpha1.ThanosStore{}).
+		WithValidator(&ThanosStoreCustomValidator{Reader: mgr.GetAPIReader()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-monitoring-thanos-io-v1alpha1-thanosstore,mutating=false,failurePolicy=fail,sideEffects=None,groups=monitoring.thanos.io,resources=thanosstores,verbs=create;update,versions=v1alpha1,name=vthanosstore-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// ThanosStoreCustomValidator validates the durations, storage and cache sizes, shard counts
+// and object storage Secret reference of a ThanosStore.
+type ThanosStoreCustomValidator struct {
+	// Reader reads the object storage Secret. The Secret is not checked if it is nil.
+	Reader client.Reader
+}
+
+var _ webhook.CustomValidator = &ThanosStoreCustomValidator{}
+
+// ValidateCreate validates a ThanosStore upon creation.
+func (v *ThanosStoreCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	store, ok := obj.(*monitoringthanosiov1alpha1.ThanosStore)
+	if !ok {
+		return nil, fmt.Errorf("expected a ThanosStore object but got %T", obj)
+	}
+	return v.validate(ctx, store, nil)
+}
+
+// ValidateUpdate validates a ThanosStore upon update.
+func (v *ThanosStoreCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	store, ok := newObj.(*monitoringthanosiov1alpha1.ThanosStore)
+	if !ok {
+		return nil, fmt.Errorf("expected a ThanosStore object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*monitoringthanosiov1alpha1.ThanosStore)
+	if !ok {
+		return nil, fmt.Errorf("expected a ThanosStore object for the oldObj but got %T", oldObj)
+	}
+	return v.validate(ctx, store, old)
+}
+
+// ValidateDelete does not validate deletions of ThanosStore.
+func (v *ThanosStoreCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate validates the ThanosStore. The previous version of the ThanosStore is nil upon creation.
+func (v *ThanosStoreCustomValidator) validate(ctx context.Context, store, old *monitoringthanosiov1alpha1.ThanosStore) (admission.Warnings, error) {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+	var warnings admission.Warnings
+
+	errs = append(errs, validateReplicas(spec.Child("shardingStrategy", "shards"), store.Spec.ShardingStrategy.Shards)...)
+	errs = append(errs, validateReplicas(spec.Child("shardingStrategy", "shardReplicas"), store.Spec.ShardingStrategy.ShardReplicas)...)
+	errs = append(errs, validatePositiveDuration(spec.Child("shardingStrategy", "interval"), store.Spec.ShardingStrategy.Interval)...)
+	if w := disruptionWarning(spec.Child("podDisruptionConfig"), store.Spec.PodDisruptionConfig, store.Spec.ShardingStrategy.ShardReplicas); w != "" {
+		warnings = append(warnings, w)
+	}
+
+	parseStorageSize(spec.Child("storageSize"), &store.Spec.StorageSize, &errs)
+	parseDuration(spec.Child("ignoreDeletionMarksDelay"), &store.Spec.IgnoreDeletionMarksDelay, &errs)
+	errs = append(errs, validateTimeOrDuration(spec.Child("minTime"), store.Spec.MinTime)...)
+	errs = append(errs, validateTimeOrDuration(spec.Child("maxTime"), store.Spec.MaxTime)...)
+	errs = append(errs, validateCacheConfig(spec.Child("indexCacheConfig"), store.Spec.IndexCacheConfig, managedRedisCache|managedMemcachedCache)...)
+	errs = append(errs, validateCacheConfig(spec.Child("cachingBucketConfig"), store.Spec.CachingBucketConfig, managedRedisCache|managedMemcachedCache)...)
+	errs = append(errs, validateGRPCProbes(spec.Child("probes"), store.Spec.Probes, store.Spec.GRPCServerTLS)...)
+	errs = append(errs, validateGRPCServer(spec.Child("grpcServer"), store.Spec.GRPCServer)...)
+
+	for i, tier := range store.Spec.Tiers {
+		path := spec.Child("tiers").Index(i)
+		parseStorageSize(path.Child("storageSize"), tier.StorageSize, &errs)
+		errs = append(errs, validateTimeOrDuration(path.Child("minTime"), tier.MinTime)...)
+		errs = append(errs, validateTimeOrDuration(path.Child("maxTime"), tier.MaxTime)...)
+		errs = append(errs, validateCacheConfig(path.Child("indexCacheConfig"), tier.IndexCacheConfig, noManagedCache)...)
+		errs = append(errs, validateCacheConfig(path.Child("cachingBucketConfig"), tier.CachingBucketConfig, noManagedCache)...)
+	}
+
+	if old != nil {
+		errs = append(errs, validateStorageGrowth(spec, old, store)...)
+	}
+
+	objStorePath := spec.Child("objectStorageConfig")
+	switch {
+	case (store.Spec.ObjectStorageConfig == nil) == (store.Spec.ObjectStorage == nil):
+		errs = append(errs, field.Invalid(objStorePath, store.Spec.ObjectStorageConfig, "exactly one of objectStorageConfig and objectStorage must be set"))
+	case store.Spec.ObjectStorage != nil:
+		errs = append(errs, validateObjectStorage(spec.Child("objectStorage"), store.Spec.ObjectStorage)...)
+	default:
+		if refErrs := validateSecretKeySelector(objStorePath, &store.Spec.ObjectStorageConfig.SecretKeySelector); len(refErrs) > 0 {
+			errs = append(errs, refErrs...)
+			break
+		}
+		w, secretErrs, err := v.validateObjectStorageSecret(ctx, objStorePath, store)
+		if err != nil {
+			return warnings, err
+		}
+		if w != "" {
+			warnings = append(warnings, w)
+		}
+		errs = append(errs, secretErrs...)
+	}
+
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(monitoringthanosiov1alpha1.GroupVersion.WithKind("ThanosStore").GroupKind(), store.Name, errs)
+}
+
+// validateStorageGrowth validates that the storage of the Store Gateways of the default shards and of each tier
+// does not shrink, since their volumes can only be expanded. Sizes which fail to parse are reported by validate.
+func validateStorageGrowth(spec *field.Path, old, updated *monitoringthanosiov1alpha1.ThanosStore) field.ErrorList {
+	var errs field.ErrorList
+	if shrinks(old.Spec.StorageSize, updated.Spec.StorageSize) {
+		errs = append(errs, field.Invalid(spec.Child("storageSize"), updated.Spec.StorageSize,
+			fmt.Sprintf("must not be less than %s, volumes cannot shrink", old.Spec.StorageSize)))
+	}
+
+	oldTiers := make(map[string]monitoringthanosiov1alpha1.StorageSize, len(old.Spec.Tiers))
+	for _, tier := range old.Spec.Tiers {
+		oldTiers[tier.Name] = ptr.Deref(tier.StorageSize, old.Spec.StorageSize)
+	}
+	for i, tier := range updated.Spec.Tiers {
+		previous, ok := oldTiers[tier.Name]
+		size := ptr.Deref(tier.StorageSize, updated.Spec.StorageSize)
+		if ok && shrinks(previous, size) {
+			errs = append(errs, field.Invalid(spec.Child("tiers").Index(i).Child("storageSize"), size,
+				fmt.Sprintf("must not be less than %s, volumes cannot shrink", previous)))
+		}
+	}
+	return errs
+}
+
+// shrinks returns true if both sizes are valid and updated is less than old.
+func shrinks(old, updated monitoringthanosiov1alpha1.StorageSize) bool {
+	o, err := resource.ParseQuantity(string(old))
+	if err != nil {
+		return false
+	}
+	u, err := resource.ParseQuantity(string(updated))
+	if err != nil {
+		return false
+	}
+	return u.Cmp(o) < 0
+}
+
+// validateObjectStorageSecret validates that the object storage Secret holds the referenced key.
+// A missing Secret only results in a warning, since it may be created after the ThanosStore.
+func (v *ThanosStoreCustomValidator) validateObjectStorageSecret(ctx context.Context, path *field.Path,
+	store *monitoringthanosiov1alpha1.ThanosStore) (string, field.ErrorList, error) {
+	if v.Reader == nil {
+		return "", nil, nil
+	}
+	ref := store.Spec.ObjectStorageConfig.SecretKeySelector
+	secret := &corev1.Secret{}
+	if err := v.Reader.Get(ctx, types.NamespacedName{Namespace: store.Namespace, Name: ref.Name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("%s: Secret %s does not exist, the Store Gateways will not start until it is created", path, ref.Name), nil, nil
+		}
+		return "", nil, fmt.Errorf("failed to get object storage Secret %s: %w", ref.Name, err)
+	}
+	if _, ok := secret.Data[ref.Key]; !ok {
+		return "", field.ErrorList{field.Invalid(path.Child("key"), ref.Key, fmt.Sprintf("Secret %s has no key %s", ref.Name, ref.Key))}, nil
+	}
+	return "", nil, nil
+}
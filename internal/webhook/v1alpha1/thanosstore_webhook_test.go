@@ -0,0 +1,288 @@
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+)
+
+func TestThanosStoreCustomValidator(t *testing.T) {
+	const namespace = "test"
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "thanos-objstore", Namespace: namespace},
+		Data:       map[string][]byte{"thanos.yaml": []byte("type: S3")},
+	}
+	validator := &ThanosStoreCustomValidator{
+		Reader: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build(),
+	}
+
+	valid := func() *monitoringthanosiov1alpha1.ThanosStore {
+		return &monitoringthanosiov1alpha1.ThanosStore{
+			ObjectMeta: metav1.ObjectMeta{Name: "store", Namespace: namespace},
+			Spec: monitoringthanosiov1alpha1.ThanosStoreSpec{
+				ObjectStorageConfig: &monitoringthanosiov1alpha1.ObjectStorageConfig{
+					SecretKeySelector: corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "thanos-objstore"},
+						Key:                  "thanos.yaml",
+					},
+				},
+				StorageSize:              "10Gi",
+				IgnoreDeletionMarksDelay: "24h",
+				ShardingStrategy:         monitoringthanosiov1alpha1.ShardingStrategy{Shards: 2, ShardReplicas: 2},
+				MinTime:                  ptr.To(monitoringthanosiov1alpha1.TimeOrDuration("-2w")),
+				MaxTime:                  ptr.To(monitoringthanosiov1alpha1.TimeOrDuration("2024-01-01T00:00:00Z")),
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		mutate  func(*monitoringthanosiov1alpha1.ThanosStore)
+		wantErr string
+		warns   bool
+	}{
+		{
+			name:   "valid",
+			mutate: func(*monitoringthanosiov1alpha1.ThanosStore) {},
+		},
+		{
+			name:    "malformed storage size",
+			mutate:  func(s *monitoringthanosiov1alpha1.ThanosStore) { s.Spec.StorageSize = "1.2.3Gi" },
+			wantErr: "spec.storageSize",
+		},
+		{
+			name: "malformed tier storage size",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.Tiers = []monitoringthanosiov1alpha1.StoreTier{{Name: "hot", StorageSize: ptr.To(monitoringthanosiov1alpha1.StorageSize("0"))}}
+			},
+			wantErr: "spec.tiers[0].storageSize",
+		},
+		{
+			name:   "growing storage size",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) { s.Spec.StorageSize = "20Gi" },
+		},
+		{
+			name:    "shrinking storage size",
+			mutate:  func(s *monitoringthanosiov1alpha1.ThanosStore) { s.Spec.StorageSize = "5Gi" },
+			wantErr: "volumes cannot shrink",
+		},
+		{
+			name:    "invalid ignore deletion marks delay",
+			mutate:  func(s *monitoringthanosiov1alpha1.ThanosStore) { s.Spec.IgnoreDeletionMarksDelay = "1d1d" },
+			wantErr: "spec.ignoreDeletionMarksDelay",
+		},
+		{
+			name: "invalid min time",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.MinTime = ptr.To(monitoringthanosiov1alpha1.TimeOrDuration("2024-13-01T00:00:00Z"))
+			},
+			wantErr: "spec.minTime",
+		},
+		{
+			name:    "no shards",
+			mutate:  func(s *monitoringthanosiov1alpha1.ThanosStore) { s.Spec.ShardingStrategy.Shards = 0 },
+			wantErr: "spec.shardingStrategy.shards",
+		},
+		{
+			name: "external cache without key",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.IndexCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					ExternalCacheConfig: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "memcached"}},
+				}
+			},
+			wantErr: "spec.indexCacheConfig.externalCacheConfig.key",
+		},
+		{
+			name: "redis cache",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.IndexCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					RedisCacheConfig: &monitoringthanosiov1alpha1.RedisCacheConfig{Addresses: []string{"redis-0:6379", "redis-1:6379"}},
+				}
+			},
+		},
+		{
+			name: "redis cache without addresses",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.IndexCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					RedisCacheConfig: &monitoringthanosiov1alpha1.RedisCacheConfig{},
+				}
+			},
+			wantErr: "spec.indexCacheConfig.redisCacheConfig.addresses",
+		},
+		{
+			name: "redis cache address without port",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.CachingBucketConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					RedisCacheConfig: &monitoringthanosiov1alpha1.RedisCacheConfig{Addresses: []string{"redis"}},
+				}
+			},
+			wantErr: "spec.cachingBucketConfig.redisCacheConfig.addresses[0]",
+		},
+		{
+			name: "time sharding strategy",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.ShardingStrategy.Type = monitoringthanosiov1alpha1.Time
+				s.Spec.ShardingStrategy.Interval = ptr.To(monitoringthanosiov1alpha1.Duration("2w"))
+			},
+		},
+		{
+			name: "zero sharding interval",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.ShardingStrategy.Type = monitoringthanosiov1alpha1.Time
+				s.Spec.ShardingStrategy.Interval = ptr.To(monitoringthanosiov1alpha1.Duration("0s"))
+			},
+			wantErr: "spec.shardingStrategy.interval",
+		},
+		{
+			name: "zero gRPC server max connection age",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.GRPCServer = &monitoringthanosiov1alpha1.GRPCServerConfig{MaxConnectionAge: ptr.To(monitoringthanosiov1alpha1.Duration("0"))}
+			},
+			wantErr: "spec.grpcServer.maxConnectionAge",
+		},
+		{
+			name: "managed redis cache",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.IndexCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					RedisCacheConfig: &monitoringthanosiov1alpha1.RedisCacheConfig{
+						Managed: &monitoringthanosiov1alpha1.ManagedRedisConfig{MaxMemory: ptr.To(monitoringthanosiov1alpha1.StorageSize("512Mi"))},
+					},
+				}
+			},
+		},
+		{
+			name: "managed redis cache with addresses",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.IndexCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					RedisCacheConfig: &monitoringthanosiov1alpha1.RedisCacheConfig{
+						Addresses: []string{"redis:6379"},
+						Managed:   &monitoringthanosiov1alpha1.ManagedRedisConfig{},
+					},
+				}
+			},
+			wantErr: "spec.indexCacheConfig.redisCacheConfig.managed",
+		},
+		{
+			name: "managed redis cache of a tier",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.Tiers = []monitoringthanosiov1alpha1.StoreTier{{
+					Name: "hot",
+					IndexCacheConfig: &monitoringthanosiov1alpha1.CacheConfig{
+						RedisCacheConfig: &monitoringthanosiov1alpha1.RedisCacheConfig{Managed: &monitoringthanosiov1alpha1.ManagedRedisConfig{}},
+					},
+				}}
+			},
+			wantErr: "spec.tiers[0].indexCacheConfig.redisCacheConfig.managed",
+		},
+		{
+			name: "managed memcached cache",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.IndexCacheConfig = &monitoringthanosiov1alpha1.CacheConfig{
+					MemcachedCacheConfig: &monitoringthanosiov1alpha1.MemcachedCacheConfig{Managed: &monitoringthanosiov1alpha1.ManagedMemcachedConfig{}},
+				}
+			},
+		},
+		{
+			name: "managed memcached cache of a tier",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.Tiers = []monitoringthanosiov1alpha1.StoreTier{{
+					Name: "hot",
+					CachingBucketConfig: &monitoringthanosiov1alpha1.CacheConfig{
+						MemcachedCacheConfig: &monitoringthanosiov1alpha1.MemcachedCacheConfig{Managed: &monitoringthanosiov1alpha1.ManagedMemcachedConfig{}},
+					},
+				}}
+			},
+			wantErr: "spec.tiers[0].cachingBucketConfig.memcachedCacheConfig.managed",
+		},
+		{
+			name: "grpc probes",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.Probes = &monitoringthanosiov1alpha1.Probes{GRPC: true}
+			},
+		},
+		{
+			name: "grpc probes with grpc server tls",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.Probes = &monitoringthanosiov1alpha1.Probes{GRPC: true}
+				s.Spec.GRPCServerTLS = &monitoringthanosiov1alpha1.GRPCServerTLSConfig{CertSecret: "store-tls"}
+			},
+			wantErr: "spec.probes.grpc",
+		},
+		{
+			name:    "object storage secret without key",
+			mutate:  func(s *monitoringthanosiov1alpha1.ThanosStore) { s.Spec.ObjectStorageConfig.Key = "objstore.yaml" },
+			wantErr: "spec.objectStorageConfig.key",
+		},
+		{
+			name:   "missing object storage secret",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) { s.Spec.ObjectStorageConfig.Name = "missing" },
+			warns:  true,
+		},
+		{
+			name: "inline object storage",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.ObjectStorageConfig = nil
+				s.Spec.ObjectStorage = &monitoringthanosiov1alpha1.ObjectStorage{
+					GCS: &monitoringthanosiov1alpha1.GCSObjectStorage{Bucket: "thanos"},
+				}
+			},
+		},
+		{
+			name: "inline object storage and secret",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.ObjectStorage = &monitoringthanosiov1alpha1.ObjectStorage{
+					GCS: &monitoringthanosiov1alpha1.GCSObjectStorage{Bucket: "thanos"},
+				}
+			},
+			wantErr: "exactly one of objectStorageConfig and objectStorage",
+		},
+		{
+			name: "inline object storage with incomplete credentials",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.ObjectStorageConfig = nil
+				s.Spec.ObjectStorage = &monitoringthanosiov1alpha1.ObjectStorage{
+					S3: &monitoringthanosiov1alpha1.S3ObjectStorage{
+						Bucket:    "thanos",
+						Endpoint:  "s3.eu-west-1.amazonaws.com",
+						AccessKey: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s3"}, Key: "access-key"},
+					},
+				}
+			},
+			wantErr: "spec.objectStorage.s3",
+		},
+		{
+			name: "inline object storage without container",
+			mutate: func(s *monitoringthanosiov1alpha1.ThanosStore) {
+				s.Spec.ObjectStorageConfig = nil
+				s.Spec.ObjectStorage = &monitoringthanosiov1alpha1.ObjectStorage{
+					Azure: &monitoringthanosiov1alpha1.AzureObjectStorage{StorageAccount: "thanos"},
+				}
+			},
+			wantErr: "spec.objectStorage.azure.container",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := valid()
+			tc.mutate(store)
+			warnings, err := validator.ValidateUpdate(context.Background(), valid(), store)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("expected error for %s, got %v", tc.wantErr, err)
+			}
+			if tc.warns != (len(warnings) > 0) {
+				t.Errorf("unexpected warnings %v", warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+// Package v1alpha1 implements the admission webhooks of the v1alpha1 Thanos resources.
+// They reject specs which pass the OpenAPI validation of the CRDs but cannot be deployed,
+// such as durations or sizes Thanos fails to parse, which would otherwise only surface as crash looping pods.
+package v1alpha1
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	monitoringthanosiov1alpha1 "github.com/thanos-community/thanos-operator/api/v1alpha1"
+)
+
+// parseDuration returns the parsed duration and appends an error to errs if it is invalid.
+// A nil duration is parsed as zero.
+func parseDuration(path *field.Path, d *monitoringthanosiov1alpha1.Duration, errs *field.ErrorList) model.Duration {
+	if d == nil {
+		return 0
+	}
+	parsed, err := model.ParseDuration(string(*d))
+	if err != nil {
+		*errs = append(*errs, field.Invalid(path, *d, err.Error()))
+	}
+	return parsed
+}
+
+// validatePositiveDuration validates that the duration, if set, is valid and greater than zero.
+func validatePositiveDuration(path *field.Path, d *monitoringthanosiov1alpha1.Duration) field.ErrorList {
+	if d == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if parseDuration(path, d, &errs) == 0 && len(errs) == 0 {
+		errs = append(errs, field.Invalid(path, *d, "must be greater than zero"))
+	}
+	return errs
+}
+
+// validateTimeOrDuration validates that the time, if set, is either an RFC3339 time
+// or a duration optionally prefixed with a minus sign.
+func validateTimeOrDuration(path *field.Path, t *monitoringthanosiov1alpha1.TimeOrDuration) field.ErrorList {
+	if t == nil {
+		return nil
+	}
+	if _, err := model.ParseDuration(strings.TrimPrefix(string(*t), "-")); err == nil {
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339, string(*t)); err != nil {
+		return field.ErrorList{field.Invalid(path, *t, "must be an RFC3339 time or a duration")}
+	}
+	return nil
+}
+
+// parseStorageSize returns the parsed size and appends an error to errs if it is not a positive quantity.
+// A nil size is parsed as zero.
+func parseStorageSize(path *field.Path, s *monitoringthanosiov1alpha1.StorageSize, errs *field.ErrorList) resource.Quantity {
+	if s == nil {
+		return resource.Quantity{}
+	}
+	q, err := resource.ParseQuantity(string(*s))
+	if err != nil {
+		*errs = append(*errs, field.Invalid(path, *s, err.Error()))
+		return resource.Quantity{}
+	}
+	if q.Sign() <= 0 {
+		*errs = append(*errs, field.Invalid(path, *s, "must be greater than zero"))
+	}
+	return q
+}
+
+// validateReplicas validates that a replica count is at least one.
+func validateReplicas(path *field.Path, replicas int32) field.ErrorList {
+	if replicas < 1 {
+		return field.ErrorList{field.Invalid(path, replicas, "must be at least 1")}
+	}
+	return nil
+}
+
+// managedCache is the set of caches the operator may deploy for a cache config.
+type managedCache int
+
+const (
+	noManagedCache    managedCache = 0
+	managedRedisCache managedCache = 1 << iota
+	managedMemcachedCache
+)
+
+// validateCacheConfig validates the sizes of an in-memory cache, the Secret reference of an external cache
+// and the configuration of a Redis or Memcached cache. A managed Redis or Memcached is only accepted if it is in managed.
+func validateCacheConfig(path *field.Path, c *monitoringthanosiov1alpha1.CacheConfig, managed managedCache) field.ErrorList {
+	if c == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if c.ExternalCacheConfig != nil {
+		errs = append(errs, validateSecretKeySelector(path.Child("externalCacheConfig"), c.ExternalCacheConfig)...)
+	}
+	if inMemory := c.InMemoryCacheConfig; inMemory != nil {
+		inMemoryPath := path.Child("inMemoryCacheConfig")
+		maxSize := parseStorageSize(inMemoryPath.Child("maxSize"), inMemory.MaxSize, &errs)
+		maxItemSize := parseStorageSize(inMemoryPath.Child("maxItemSize"), inMemory.MaxItemSize, &errs)
+		if !maxSize.IsZero() && maxItemSize.Cmp(maxSize) > 0 {
+			errs = append(errs, field.Invalid(inMemoryPath.Child("maxItemSize"), *inMemory.MaxItemSize,
+				fmt.Sprintf("must not be larger than maxSize %s", *inMemory.MaxSize)))
+		}
+	}
+	if c.RedisCacheConfig != nil {
+		errs = append(errs, validateRedisCacheConfig(path.Child("redisCacheConfig"), c.RedisCacheConfig, managed&managedRedisCache != 0)...)
+	}
+	if c.MemcachedCacheConfig != nil {
+		errs = append(errs, validateMemcachedCacheConfig(path.Child("memcachedCacheConfig"), c.MemcachedCacheConfig, managed&managedMemcachedCache != 0)...)
+	}
+	return errs
+}
+
+// validateRedisCacheConfig validates the addresses and Secret references of a Redis cache,
+// or the memory of a managed Redis, which excludes the connection settings of an external Redis.
+func validateRedisCacheConfig(path *field.Path, r *monitoringthanosiov1alpha1.RedisCacheConfig, allowManaged bool) field.ErrorList {
+	var errs field.ErrorList
+	if r.Managed != nil {
+		if !allowManaged {
+			return field.ErrorList{field.Forbidden(path.Child("managed"), "a managed Redis is only supported by spec.indexCacheConfig and spec.cachingBucketConfig of a ThanosStore")}
+		}
+		parseStorageSize(path.Child("managed", "maxMemory"), r.Managed.MaxMemory, &errs)
+		if len(r.Addresses) > 0 || r.MasterName != nil || r.Username != nil || r.PasswordSecret != nil || r.TLS != nil {
+			errs = append(errs, field.Forbidden(path.Child("managed"),
+				"addresses, masterName, username, passwordSecret and tls must not be set with a managed Redis"))
+		}
+		return errs
+	}
+
+	if len(r.Addresses) == 0 {
+		errs = append(errs, field.Required(path.Child("addresses"), "at least one address is required unless managed is set"))
+	}
+	for i, addr := range r.Addresses {
+		if _, port, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, field.Invalid(path.Child("addresses").Index(i), addr, "must be in host:port format"))
+		} else if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			errs = append(errs, field.Invalid(path.Child("addresses").Index(i), addr, "must have a numeric port"))
+		}
+	}
+	if r.PasswordSecret != nil {
+		errs = append(errs, validateSecretKeySelector(path.Child("passwordSecret"), r.PasswordSecret)...)
+	}
+	if r.TLS != nil && r.TLS.CA != nil {
+		errs = append(errs, validateSecretKeySelector(path.Child("tls", "ca"), r.TLS.CA)...)
+	}
+	return errs
+}
+
+// validateMemcachedCacheConfig validates the addresses of a Memcached cache, or the sizes of a managed Memcached,
+// which excludes the settings of an external Memcached.
+func validateMemcachedCacheConfig(path *field.Path, m *monitoringthanosiov1alpha1.MemcachedCacheConfig, allowManaged bool) field.ErrorList {
+	var errs field.ErrorList
+	if m.Managed != nil {
+		if !allowManaged {
+			return field.ErrorList{field.Forbidden(path.Child("managed"), "a managed Memcached is only supported by spec.queryFrontend.queryRangeResponseCacheConfig of a ThanosQuery and spec.indexCacheConfig and spec.cachingBucketConfig of a ThanosStore")}
+		}
+		memoryLimit := parseStorageSize(path.Child("managed", "memoryLimit"), m.Managed.MemoryLimit, &errs)
+		maxItemSize := parseStorageSize(path.Child("managed", "maxItemSize"), m.Managed.MaxItemSize, &errs)
+		if !memoryLimit.IsZero() && maxItemSize.Cmp(memoryLimit) > 0 {
+			errs = append(errs, field.Invalid(path.Child("managed", "maxItemSize"), *m.Managed.MaxItemSize,
+				fmt.Sprintf("must not be larger than memoryLimit %s", *m.Managed.MemoryLimit)))
+		}
+		if len(m.Addresses) > 0 || m.MaxItemSize != nil {
+			errs = append(errs, field.Forbidden(path.Child("managed"), "addresses and maxItemSize must not be set with a managed Memcached"))
+		}
+		return errs
+	}
+
+	if len(m.Addresses) == 0 {
+		errs = append(errs, field.Required(path.Child("addresses"), "at least one address is required unless managed is set"))
+	}
+	for i, addr := range m.Addresses {
+		if strings.HasPrefix(addr, "dnssrv+") || strings.HasPrefix(addr, "dnssrvnoa+") {
+			// the port is resolved from the SRV records
+			continue
+		}
+		addr = strings.TrimPrefix(addr, "dns+")
+		if _, port, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, field.Invalid(path.Child("addresses").Index(i), m.Addresses[i], "must be in host:port format"))
+		} else if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			errs = append(errs, field.Invalid(path.Child("addresses").Index(i), m.Addresses[i], "must have a numeric port"))
+		}
+	}
+	parseStorageSize(path.Child("maxItemSize"), m.MaxItemSize, &errs)
+	return errs
+}
+
+// validateSecretKeySelector validates that a Secret reference names both a Secret and a key.
+// validateGRPCProbes validates that gRPC probes are not enabled together with gRPC server TLS,
+// as the kubelet does not support TLS for gRPC probes.
+func validateGRPCProbes(path *field.Path, probes *monitoringthanosiov1alpha1.Probes, tls *monitoringthanosiov1alpha1.GRPCServerTLSConfig) field.ErrorList {
+	if probes == nil || !probes.GRPC || tls == nil {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(path.Child("grpc"), probes.GRPC, "gRPC probes are not supported with grpcServerTLS")}
+}
+
+// validateGRPCServer validates that the max connection age of a gRPC server, if set, is greater than zero.
+func validateGRPCServer(path *field.Path, g *monitoringthanosiov1alpha1.GRPCServerConfig) field.ErrorList {
+	if g == nil {
+		return nil
+	}
+	return validatePositiveDuration(path.Child("maxConnectionAge"), g.MaxConnectionAge)
+}
+
+func validateSecretKeySelector(path *field.Path, s *corev1.SecretKeySelector) field.ErrorList {
+	var errs field.ErrorList
+	if s.Name == "" {
+		errs = append(errs, field.Required(path.Child("name"), "the name of the Secret is required"))
+	}
+	if s.Key == "" {
+		errs = append(errs, field.Required(path.Child("key"), "the key in the Secret is required"))
+	}
+	return errs
+}
+
+// validateObjectStorage validates that an inline object storage configuration sets exactly one provider
+// with its required fields, and that the credentials it references are complete.
+func validateObjectStorage(path *field.Path, o *monitoringthanosiov1alpha1.ObjectStorage) field.ErrorList {
+	var errs field.ErrorList
+	var providers int
+	if o.S3 != nil {
+		providers++
+		s3 := path.Child("s3")
+		if o.S3.Bucket == "" {
+			errs = append(errs, field.Required(s3.Child("bucket"), "the name of the bucket is required"))
+		}
+		if o.S3.Endpoint == "" {
+			errs = append(errs, field.Required(s3.Child("endpoint"), "the endpoint of the S3 API is required"))
+		}
+		if (o.S3.AccessKey == nil) != (o.S3.SecretKey == nil) {
+			errs = append(errs, field.Invalid(s3, "", "accessKey and secretKey must be set together"))
+		}
+		if o.S3.AccessKey != nil {
+			errs = append(errs, validateSecretKeySelector(s3.Child("accessKey"), o.S3.AccessKey)...)
+		}
+		if o.S3.SecretKey != nil {
+			errs = append(errs, validateSecretKeySelector(s3.Child("secretKey"), o.S3.SecretKey)...)
+		}
+	}
+	if o.GCS != nil {
+		providers++
+		if o.GCS.Bucket == "" {
+			errs = append(errs, field.Required(path.Child("gcs", "bucket"), "the name of the bucket is required"))
+		}
+	}
+	if o.Azure != nil {
+		providers++
+		azure := path.Child("azure")
+		if o.Azure.StorageAccount == "" {
+			errs = append(errs, field.Required(azure.Child("storageAccount"), "the name of the storage account is required"))
+		}
+		if o.Azure.Container == "" {
+			errs = append(errs, field.Required(azure.Child("container"), "the name of the container is required"))
+		}
+		if o.Azure.StorageAccountKey != nil {
+			errs = append(errs, validateSecretKeySelector(azure.Child("storageAccountKey"), o.Azure.StorageAccountKey)...)
+		}
+	}
+	if providers != 1 {
+		errs = append(errs, field.Invalid(path, "", "exactly one of s3, gcs or azure must be set"))
+	}
+	return errs
+}
+
+// disruptionWarning warns if the PodDisruptionBudget of a component requires all of its replicas to be available,
+// which blocks voluntary disruptions such as node drains.
+func disruptionWarning(path *field.Path, pdb *monitoringthanosiov1alpha1.PodDisruptionConfig, replicas int32) string {
+	if pdb == nil || pdb.MinAvailable == nil || *pdb.MinAvailable < replicas {
+		return ""
+	}
+	return fmt.Sprintf("%s: minAvailable %d is not less than the %d replicas, which blocks node drains",
+		path.Child("minAvailable"), *pdb.MinAvailable, replicas)
+}
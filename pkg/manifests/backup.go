@@ -0,0 +1,106 @@
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+)
+
+const (
+	// VeleroVolumeSnapshotClassAnnotation selects the VolumeSnapshotClass Velero uses to snapshot a PersistentVolumeClaim.
+	VeleroVolumeSnapshotClassAnnotation = "velero.io/csi-volumesnapshot-class"
+	// VeleroBackupVolumesAnnotation lists the volumes of a pod Velero backs up with file system backup.
+	VeleroBackupVolumesAnnotation = "backup.velero.io/backup-volumes"
+
+	veleroPreBackupHookPrefix  = "pre.hook.backup.velero.io/"
+	veleroPostBackupHookPrefix = "post.hook.backup.velero.io/"
+)
+
+// BackupOptions configures how the persistent volumes of a component participate in cluster backups.
+type BackupOptions struct {
+	// VolumeSnapshotClass is the VolumeSnapshotClass used to snapshot the PersistentVolumeClaims.
+	VolumeSnapshotClass string
+	// FileSystemBackup backs up the data volume with file system backup instead of a snapshot.
+	FileSystemBackup bool
+	// VolumeAnnotations are additional annotations of the PersistentVolumeClaims.
+	VolumeAnnotations map[string]string
+	// PodAnnotations are additional annotations of the pods.
+	PodAnnotations map[string]string
+	// PreBackupHook is run in the pods before their volumes are backed up.
+	PreBackupHook *BackupHook
+	// PostBackupHook is run in the pods after their volumes are backed up.
+	PostBackupHook *BackupHook
+}
+
+// BackupHook is a command run in a container of a pod around the backup of its volumes.
+type BackupHook struct {
+	// Container is the container the command runs in.
+	// If empty, the command runs in the container given to GetPodAnnotations.
+	Container string
+	// Command is the command to run.
+	Command []string
+	// OnError is Continue or Fail, and defines whether the backup continues if the command fails.
+	OnError string
+	// Timeout is the time to wait for the command to complete.
+	Timeout Duration
+}
+
+// GetVolumeAnnotations returns the annotations of the PersistentVolumeClaims of the component.
+// A nil BackupOptions returns nil.
+func (o *BackupOptions) GetVolumeAnnotations() map[string]string {
+	if o == nil {
+		return nil
+	}
+	annotations := make(map[string]string, len(o.VolumeAnnotations)+1)
+	maps.Copy(annotations, o.VolumeAnnotations)
+	if o.VolumeSnapshotClass != "" {
+		annotations[VeleroVolumeSnapshotClassAnnotation] = o.VolumeSnapshotClass
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+// GetPodAnnotations returns the annotations of the pods of the component, which declare the given data volume
+// for file system backup and the backup hooks, run in the given container unless they select another one.
+// A nil BackupOptions returns nil.
+func (o *BackupOptions) GetPodAnnotations(container, dataVolume string) map[string]string {
+	if o == nil {
+		return nil
+	}
+	annotations := make(map[string]string, len(o.PodAnnotations)+9)
+	maps.Copy(annotations, o.PodAnnotations)
+	if o.FileSystemBackup {
+		annotations[VeleroBackupVolumesAnnotation] = dataVolume
+	}
+	o.PreBackupHook.addAnnotations(annotations, veleroPreBackupHookPrefix, container)
+	o.PostBackupHook.addAnnotations(annotations, veleroPostBackupHookPrefix, container)
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+func (h *BackupHook) addAnnotations(annotations map[string]string, prefix, container string) {
+	if h == nil || len(h.Command) == 0 {
+		return
+	}
+	if h.Container != "" {
+		container = h.Container
+	}
+	// a JSON array is the only command format which preserves arguments containing spaces,
+	// shell operators are kept unescaped to keep the annotation readable
+	var command bytes.Buffer
+	enc := json.NewEncoder(&command)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(h.Command)
+	annotations[prefix+"container"] = container
+	annotations[prefix+"command"] = string(bytes.TrimSpace(command.Bytes()))
+	if h.OnError != "" {
+		annotations[prefix+"on-error"] = h.OnError
+	}
+	if h.Timeout != "" {
+		annotations[prefix+"timeout"] = string(h.Timeout)
+	}
+}
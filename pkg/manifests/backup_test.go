@@ -0,0 +1,46 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupOptions(t *testing.T) {
+	var nilOpts *BackupOptions
+	require.Nil(t, nilOpts.GetVolumeAnnotations())
+	require.Nil(t, nilOpts.GetPodAnnotations("thanos-store", "data"))
+	require.Nil(t, (&BackupOptions{}).GetPodAnnotations("thanos-store", "data"))
+
+	opts := &BackupOptions{
+		VolumeSnapshotClass: "csi-snapclass",
+		FileSystemBackup:    true,
+		VolumeAnnotations:   map[string]string{"backup.example.com/policy": "daily"},
+		PodAnnotations:      map[string]string{"backup.example.com/enabled": "true"},
+		PreBackupHook: &BackupHook{
+			Command: []string{"/bin/sh", "-c", "sync && sleep 1"},
+			OnError: "Fail",
+			Timeout: "30s",
+		},
+		PostBackupHook: &BackupHook{
+			Container: "sidecar",
+			Command:   []string{"/bin/true"},
+		},
+	}
+
+	require.Equal(t, map[string]string{
+		"backup.example.com/policy":         "daily",
+		VeleroVolumeSnapshotClassAnnotation: "csi-snapclass",
+	}, opts.GetVolumeAnnotations())
+
+	require.Equal(t, map[string]string{
+		"backup.example.com/enabled":           "true",
+		VeleroBackupVolumesAnnotation:          "data",
+		"pre.hook.backup.velero.io/container":  "thanos-store",
+		"pre.hook.backup.velero.io/command":    `["/bin/sh","-c","sync && sleep 1"]`,
+		"pre.hook.backup.velero.io/on-error":   "Fail",
+		"pre.hook.backup.velero.io/timeout":    "30s",
+		"post.hook.backup.velero.io/container": "sidecar",
+		"post.hook.backup.velero.io/command":   `["/bin/true"]`,
+	}, opts.GetPodAnnotations("thanos-store", "data"))
+}
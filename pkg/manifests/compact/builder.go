@@ -3,7 +3,7 @@ package compact
 import (
 	"fmt"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -31,6 +31,7 @@ const (
 	objectStoreEnvVarName = "OBJSTORE_CONFIG"
 	dataVolumeName        = "data"
 	dataVolumeMountPath   = "var/thanos/compact"
+	downsamplerSuffix     = "downsampler"
 )
 
 // Options for Thanos Compact
@@ -50,24 +51,32 @@ type Options struct {
 	Min, Max   *manifests.Duration
 	ShardName  *string
 	ShardIndex *int
+	// RoutePrefix is the prefix under which the bucket UI is served.
+	RoutePrefix string
+	// Suspended scales the Thanos Compact shard to zero replicas.
+	Suspended bool
+	// Downsampler builds a worker which only downsamples blocks, using the concurrency of Downsampling,
+	// instead of a Compactor. The Compactors must then be built with downsampling disabled.
+	Downsampler bool
 }
 
 // Build compiles all the Kubernetes objects for the Thanos Compact shard.
 // This includes the ServiceAccount, StatefulSet, and Service.
 // Build ignores any manifests.PodDisruptionBudgetOptions as well as replicas set in the Options since
-// enforce running compactor as a single replica.
+// enforce running compactor as a single replica, or no replica at all if Suspended is set.
 func (opts Options) Build() []client.Object {
 	var objs []client.Object
 	selectorLabels := opts.GetSelectorLabels()
 	objectMetaLabels := manifests.MergeLabels(opts.Labels, selectorLabels)
 	name := opts.GetGeneratedResourceName()
 
-	objs = append(objs, manifests.BuildServiceAccount(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, opts.Annotations))
+	objs = append(objs, manifests.BuildServiceAccounts(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, opts.Annotations, opts.ServiceAccount)...)
 	objs = append(objs, newShardStatefulSet(opts, selectorLabels, objectMetaLabels))
 	objs = append(objs, newService(opts, selectorLabels, objectMetaLabels))
 
 	if opts.ServiceMonitorConfig.Enabled {
-		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, objectMetaLabels, selectorLabels, serviceMonitorOpts(opts.ServiceMonitorConfig)))
+		smLabels := manifests.MergeLabels(opts.ServiceMonitorConfig.Labels, objectMetaLabels)
+		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, smLabels, selectorLabels, serviceMonitorOpts(opts.Options)))
 	}
 
 	return objs
@@ -76,8 +85,12 @@ func (opts Options) Build() []client.Object {
 // GetGeneratedResourceName returns the generated name for the Thanos Compact or shard.
 // If no sharding is configured, the name will be generated from the Options.Owner.
 // If sharding is configured, the name will be generated from the Options.Owner, ShardName, and ShardIndex.
+// The name of a downsampling worker is suffixed with downsampler.
 func (opts Options) GetGeneratedResourceName() string {
 	name := fmt.Sprintf("%s-%s", Name, opts.getOwner())
+	if opts.Downsampler {
+		return manifests.ValidateAndSanitizeResourceName(fmt.Sprintf("%s-%s", name, downsamplerSuffix))
+	}
 	if opts.ShardName != nil && opts.ShardIndex != nil {
 		name = fmt.Sprintf("%s-%s-%d", name, *opts.ShardName, *opts.ShardIndex)
 	}
@@ -96,6 +109,10 @@ func NewStatefulSet(opts Options) *appsv1.StatefulSet {
 
 func newShardStatefulSet(opts Options, selectorLabels map[string]string, metaLabels map[string]string) *appsv1.StatefulSet {
 	name := opts.GetGeneratedResourceName()
+	replicas := int32(1)
+	if opts.Suspended {
+		replicas = 0
+	}
 	vc := []corev1.PersistentVolumeClaim{
 		{
 			ObjectMeta: metav1.ObjectMeta{
@@ -140,7 +157,7 @@ func newShardStatefulSet(opts Options, selectorLabels map[string]string, metaLab
 			Name:        name,
 			Namespace:   opts.Namespace,
 			Labels:      metaLabels,
-			Annotations: opts.Annotations,
+			Annotations: opts.GetWorkloadAnnotations(),
 		},
 		Spec: appsv1.StatefulSetSpec{
 			PersistentVolumeClaimRetentionPolicy: &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
@@ -148,7 +165,7 @@ func newShardStatefulSet(opts Options, selectorLabels map[string]string, metaLab
 				WhenScaled:  appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
 			},
 			ServiceName: name,
-			Replicas:    ptr.To(int32(1)),
+			Replicas:    ptr.To(replicas),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: selectorLabels,
 			},
@@ -158,7 +175,7 @@ func newShardStatefulSet(opts Options, selectorLabels map[string]string, metaLab
 					Labels: metaLabels,
 				},
 				Spec: corev1.PodSpec{
-					SecurityContext:    &corev1.PodSecurityContext{},
+					SecurityContext:    manifests.DefaultPodSecurityContext(),
 					ServiceAccountName: name,
 					Containers: []corev1.Container{
 						{
@@ -167,20 +184,12 @@ func newShardStatefulSet(opts Options, selectorLabels map[string]string, metaLab
 							ImagePullPolicy: corev1.PullIfNotPresent,
 							// Ensure restrictive context for the container
 							// More info: https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted
-							SecurityContext: &corev1.SecurityContext{
-								AllowPrivilegeEscalation: ptr.To(false),
-								RunAsNonRoot:             ptr.To(true),
-								Capabilities: &corev1.Capabilities{
-									Drop: []corev1.Capability{
-										"ALL",
-									},
-								},
-							},
+							SecurityContext: manifests.DefaultSecurityContext(),
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/ready",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 								InitialDelaySeconds: 20,
@@ -193,7 +202,7 @@ func newShardStatefulSet(opts Options, selectorLabels map[string]string, metaLab
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/healthy",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 								InitialDelaySeconds: 60,
@@ -211,8 +220,8 @@ func newShardStatefulSet(opts Options, selectorLabels map[string]string, metaLab
 							},
 							Ports: []corev1.ContainerPort{
 								{
-									ContainerPort: HTTPPort,
-									Name:          HTTPPortName,
+									ContainerPort: opts.GetHTTPPort(HTTPPort),
+									Name:          opts.GetHTTPPortName(HTTPPortName),
 								},
 							},
 							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
@@ -235,8 +244,8 @@ func NewService(opts Options) *corev1.Service {
 	selectorLabels := opts.GetSelectorLabels()
 	objectMetaLabels := manifests.MergeLabels(opts.Labels, selectorLabels)
 	svc := newService(opts, selectorLabels, objectMetaLabels)
-	if opts.Additional.ServicePorts != nil {
-		svc.Spec.Ports = append(svc.Spec.Ports, opts.Additional.ServicePorts...)
+	if ports := manifests.AdditionalServicePorts(opts.Additional); ports != nil {
+		svc.Spec.Ports = append(svc.Spec.Ports, ports...)
 	}
 
 	return svc
@@ -245,9 +254,9 @@ func NewService(opts Options) *corev1.Service {
 func newService(opts Options, selectorLabels, objectMetaLabels map[string]string) *corev1.Service {
 	servicePorts := []corev1.ServicePort{
 		{
-			Name:       HTTPPortName,
-			Port:       HTTPPort,
-			TargetPort: intstr.FromInt32(HTTPPort),
+			Name:       opts.GetHTTPPortName(HTTPPortName),
+			Port:       opts.GetHTTPPort(HTTPPort),
+			TargetPort: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 		},
 	}
 
@@ -271,11 +280,14 @@ func newService(opts Options, selectorLabels, objectMetaLabels map[string]string
 }
 
 func compactorArgsFrom(opts Options) []string {
+	if opts.Downsampler {
+		return downsamplerArgsFrom(opts)
+	}
 	args := []string{"compact"}
 	args = append(args, opts.ToFlags()...)
 	args = append(args,
 		"--wait",
-		fmt.Sprintf("--http-address=0.0.0.0:%d", HTTPPort),
+		fmt.Sprintf("--http-address=0.0.0.0:%d", opts.GetHTTPPort(HTTPPort)),
 		fmt.Sprintf("--objstore.config=$(%s)", objectStoreEnvVarName),
 		fmt.Sprintf("--data-dir=%s", dataVolumeMountPath),
 	)
@@ -290,6 +302,9 @@ func compactorArgsFrom(opts Options) []string {
 	if opts.Max != nil {
 		args = append(args, fmt.Sprintf("--max-time=%s", string(*opts.Max)))
 	}
+	if opts.RoutePrefix != "" {
+		args = append(args, fmt.Sprintf("--web.route-prefix=%s", opts.RoutePrefix))
+	}
 
 	args = append(args, opts.RetentionOptions.toArgs()...)
 	args = append(args, opts.BlockConfig.toArgs()...)
@@ -303,6 +318,26 @@ func compactorArgsFrom(opts Options) []string {
 	return manifests.PruneEmptyArgs(args)
 }
 
+// downsamplerArgsFrom returns the arguments of a worker which continuously downsamples the blocks in the object storage.
+func downsamplerArgsFrom(opts Options) []string {
+	args := []string{"tools", "bucket", "downsample"}
+	args = append(args, opts.ToFlags()...)
+	args = append(args,
+		fmt.Sprintf("--http-address=0.0.0.0:%d", opts.GetHTTPPort(HTTPPort)),
+		fmt.Sprintf("--objstore.config=$(%s)", objectStoreEnvVarName),
+		fmt.Sprintf("--data-dir=%s", dataVolumeMountPath),
+	)
+	if opts.Downsampling != nil && opts.Downsampling.Concurrency != nil {
+		args = append(args, fmt.Sprintf("--downsample.concurrency=%d", *opts.Downsampling.Concurrency))
+	}
+
+	if opts.Additional.Args != nil {
+		args = append(args, opts.Additional.Args...)
+	}
+
+	return manifests.PruneEmptyArgs(args)
+}
+
 // GetRequiredLabels returns a map of labels that can be used to look up ThanosCompact resources.
 func GetRequiredLabels() map[string]string {
 	return map[string]string{
@@ -442,9 +477,9 @@ func (do *DownsamplingOptions) toArgs() []string {
 	return args
 }
 
-func serviceMonitorOpts(from manifests.ServiceMonitorConfig) manifests.ServiceMonitorOptions {
+func serviceMonitorOpts(from manifests.Options) manifests.ServiceMonitorOptions {
 	return manifests.ServiceMonitorOptions{
-		Port:     ptr.To(HTTPPortName),
-		Interval: from.Interval,
+		Port:     ptr.To(from.GetHTTPPortName(HTTPPortName)),
+		Interval: from.ServiceMonitorConfig.Interval,
 	}
 }
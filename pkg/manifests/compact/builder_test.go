@@ -2,9 +2,11 @@ package compact
 
 import (
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	"k8s.io/utils/ptr"
@@ -223,3 +225,63 @@ func TestOptions_GetName(t *testing.T) {
 		})
 	}
 }
+
+func TestRoutePrefix(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+	}
+	if slices.ContainsFunc(compactorArgsFrom(opts), func(arg string) bool { return strings.HasPrefix(arg, "--web.route-prefix") }) {
+		t.Errorf("expected no route prefix flag when unset")
+	}
+
+	opts.RoutePrefix = "/prefix/test"
+	if !slices.Contains(compactorArgsFrom(opts), "--web.route-prefix=/prefix/test") {
+		t.Errorf("expected route prefix flag, got %v", compactorArgsFrom(opts))
+	}
+}
+
+func TestSuspended(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+	}
+	if replicas := *NewStatefulSet(opts).Spec.Replicas; replicas != 1 {
+		t.Errorf("expected compact statefulset to have 1 replica, got %d", replicas)
+	}
+
+	opts.Suspended = true
+	if replicas := *NewStatefulSet(opts).Spec.Replicas; replicas != 0 {
+		t.Errorf("expected suspended compact statefulset to have 0 replicas, got %d", replicas)
+	}
+}
+
+func TestDownsampler(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+		RetentionOptions: &RetentionOptions{Raw: ptr.To(manifests.Duration("30d"))},
+		Downsampling:     &DownsamplingOptions{Concurrency: ptr.To(int32(4))},
+		Downsampler:      true,
+	}
+	if name := opts.GetGeneratedResourceName(); name != "thanos-compact-test-downsampler" {
+		t.Errorf("expected downsampler name thanos-compact-test-downsampler, got %s", name)
+	}
+
+	args := NewStatefulSet(opts).Spec.Template.Spec.Containers[0].Args
+	if !slices.Equal(args[:3], []string{"tools", "bucket", "downsample"}) {
+		t.Errorf("expected the downsample command, got %v", args)
+	}
+	if !slices.Contains(args, "--downsample.concurrency=4") {
+		t.Errorf("expected downsample concurrency flag, got %v", args)
+	}
+	if slices.ContainsFunc(args, func(arg string) bool { return strings.HasPrefix(arg, "--retention") || arg == "--wait" }) {
+		t.Errorf("expected no compaction flags, got %v", args)
+	}
+}
@@ -0,0 +1,9 @@
+// Package manifests renders the Kubernetes objects for Thanos components.
+//
+// The component subpackages, such as query, queryfrontend and store, expose an Options struct embedding Options
+// from this package and a Build method returning the objects for the component. The operator reconciles exactly
+// these objects, which allows other platforms to reuse the rendering logic programmatically.
+//
+// This package and its subpackages follow semantic versioning together with the operator module.
+// Exported identifiers are not removed or changed in an incompatible way within a major version.
+package manifests
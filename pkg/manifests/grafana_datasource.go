@@ -0,0 +1,87 @@
+package manifests
+
+import (
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// GrafanaDatasourceKey is the key in the ConfigMap for the Grafana datasource provisioning file.
+	GrafanaDatasourceKey = "datasource.yaml"
+)
+
+// GrafanaDatasourceOptions defines the available options for creating a Grafana datasource ConfigMap.
+type GrafanaDatasourceOptions struct {
+	// DatasourceName is the name of the datasource in Grafana.
+	DatasourceName string
+	// URL is the URL of the Prometheus compatible API Grafana should query.
+	URL string
+	// IsDefault marks the datasource as the default datasource in Grafana.
+	IsDefault bool
+	// TimeInterval is the lowest interval for queries in Grafana.
+	TimeInterval *Duration
+}
+
+type grafanaDatasources struct {
+	APIVersion  int                 `yaml:"apiVersion"`
+	Datasources []grafanaDatasource `yaml:"datasources"`
+}
+
+type grafanaDatasource struct {
+	Name      string                `yaml:"name"`
+	Type      string                `yaml:"type"`
+	Access    string                `yaml:"access"`
+	URL       string                `yaml:"url"`
+	IsDefault bool                  `yaml:"isDefault"`
+	Editable  bool                  `yaml:"editable"`
+	JSONData  grafanaDatasourceData `yaml:"jsonData"`
+}
+
+type grafanaDatasourceData struct {
+	HTTPMethod     string `yaml:"httpMethod"`
+	PrometheusType string `yaml:"prometheusType"`
+	TimeInterval   string `yaml:"timeInterval,omitempty"`
+}
+
+// BuildGrafanaDatasource creates a ConfigMap holding a Grafana datasource provisioning file
+// for a Prometheus datasource backed by a Thanos Query API.
+func BuildGrafanaDatasource(name, namespace string, objectMetaLabels map[string]string, opts GrafanaDatasourceOptions) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    objectMetaLabels,
+		},
+		Data: map[string]string{
+			GrafanaDatasourceKey: opts.toProvisioningConfig(),
+		},
+	}
+}
+
+func (opts GrafanaDatasourceOptions) toProvisioningConfig() string {
+	ds := grafanaDatasource{
+		Name:      opts.DatasourceName,
+		Type:      "prometheus",
+		Access:    "proxy",
+		URL:       opts.URL,
+		IsDefault: opts.IsDefault,
+		JSONData: grafanaDatasourceData{
+			HTTPMethod:     "POST",
+			PrometheusType: "Thanos",
+		},
+	}
+	if opts.TimeInterval != nil {
+		ds.JSONData.TimeInterval = string(*opts.TimeInterval)
+	}
+
+	b, err := yaml.Marshal(grafanaDatasources{APIVersion: 1, Datasources: []grafanaDatasource{ds}})
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,43 @@
+package manifests
+
+import (
+	"testing"
+
+	"k8s.io/utils/ptr"
+)
+
+func TestBuildGrafanaDatasource(t *testing.T) {
+	cm := BuildGrafanaDatasource("test-name", "test-namespace", map[string]string{"grafana_datasource": "1"}, GrafanaDatasourceOptions{
+		DatasourceName: "thanos",
+		URL:            "http://thanos-query-frontend-test.test-namespace.svc.cluster.local:9090",
+		IsDefault:      true,
+		TimeInterval:   ptr.To(Duration("30s")),
+	})
+
+	if cm.Name != "test-name" {
+		t.Errorf("cm.Name = %v, want %v", cm.Name, "test-name")
+	}
+	if cm.Namespace != "test-namespace" {
+		t.Errorf("cm.Namespace = %v, want %v", cm.Namespace, "test-namespace")
+	}
+	if cm.Labels["grafana_datasource"] != "1" {
+		t.Errorf("cm.Labels = %v, want grafana_datasource label", cm.Labels)
+	}
+
+	expect := `apiVersion: 1
+datasources:
+- name: thanos
+  type: prometheus
+  access: proxy
+  url: http://thanos-query-frontend-test.test-namespace.svc.cluster.local:9090
+  isDefault: true
+  editable: false
+  jsonData:
+    httpMethod: POST
+    prometheusType: Thanos
+    timeInterval: 30s
+`
+	if cm.Data[GrafanaDatasourceKey] != expect {
+		t.Errorf("cm.Data[%s] = %v, want %v", GrafanaDatasourceKey, cm.Data[GrafanaDatasourceKey], expect)
+	}
+}
@@ -0,0 +1,29 @@
+package manifests
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// GRPCServerOptions configure the gRPC server of a component.
+type GRPCServerOptions struct {
+	// MaxConnectionAge is the maximum age of the connections to the gRPC server.
+	// If empty, the default of Thanos applies.
+	MaxConnectionAge Duration
+}
+
+// GRPCServerFlags returns the flags configuring the gRPC server of the component.
+// Builders of components serving gRPC must add them when GRPCServer is set.
+func (o Options) GRPCServerFlags() []string {
+	g := o.GRPCServer
+	if g == nil || g.MaxConnectionAge == "" {
+		return nil
+	}
+	// Thanos parses the flag as a Go duration, which does not support units of days and longer
+	maxAge := string(g.MaxConnectionAge)
+	if d, err := model.ParseDuration(maxAge); err == nil {
+		maxAge = time.Duration(d).String()
+	}
+	return []string{"--grpc-server-max-connection-age=" + maxAge}
+}
@@ -0,0 +1,25 @@
+package manifests
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGRPCServerFlags(t *testing.T) {
+	if flags := (Options{}).GRPCServerFlags(); flags != nil {
+		t.Errorf("expected no flags without gRPC server options, got %v", flags)
+	}
+	if flags := (Options{GRPCServer: &GRPCServerOptions{}}).GRPCServerFlags(); flags != nil {
+		t.Errorf("expected no flags without a max connection age, got %v", flags)
+	}
+
+	for maxAge, expect := range map[Duration]string{
+		"30m": "--grpc-server-max-connection-age=30m0s",
+		"1d":  "--grpc-server-max-connection-age=24h0m0s",
+	} {
+		flags := (Options{GRPCServer: &GRPCServerOptions{MaxConnectionAge: maxAge}}).GRPCServerFlags()
+		if !reflect.DeepEqual(flags, []string{expect}) {
+			t.Errorf("expected %q for max connection age %s, got %v", expect, maxAge, flags)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package manifests
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HorizontalPodAutoscalerOptions defines the available options for creating a HorizontalPodAutoscaler object.
+type HorizontalPodAutoscalerOptions struct {
+	// MinReplicas is the lower limit for the number of replicas.
+	// Defaults to 1 if not specified.
+	MinReplicas *int32
+	// MaxReplicas is the upper limit for the number of replicas.
+	MaxReplicas int32
+	// Metrics are the metrics used to calculate the desired number of replicas.
+	// Defaults to a target average CPU utilization of 80% if empty.
+	Metrics []autoscalingv2.MetricSpec
+	// Behavior configures the scaling behavior in both up and down directions.
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior
+}
+
+// NewHorizontalPodAutoscaler creates a new HorizontalPodAutoscaler object scaling the Deployment with the same name.
+func NewHorizontalPodAutoscaler(name, namespace string, objectMetaLabels, annotations map[string]string, opts HorizontalPodAutoscalerOptions) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: autoscalingv2.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      objectMetaLabels,
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+				Name:       name,
+			},
+			MinReplicas: opts.MinReplicas,
+			MaxReplicas: opts.MaxReplicas,
+			Metrics:     opts.Metrics,
+			Behavior:    opts.Behavior,
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package manifests
+
+import (
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/utils/ptr"
+)
+
+func TestNewHorizontalPodAutoscaler(t *testing.T) {
+	metrics := []autoscalingv2.MetricSpec{{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name:   "cpu",
+			Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: ptr.To(int32(70))},
+		},
+	}}
+	hpa := NewHorizontalPodAutoscaler("test-name", "test-namespace", map[string]string{"test": "label"}, nil, HorizontalPodAutoscalerOptions{
+		MinReplicas: ptr.To(int32(2)),
+		MaxReplicas: 5,
+		Metrics:     metrics,
+	})
+
+	if hpa.Name != "test-name" || hpa.Namespace != "test-namespace" {
+		t.Errorf("unexpected name %s/%s", hpa.Namespace, hpa.Name)
+	}
+	ref := hpa.Spec.ScaleTargetRef
+	if ref.Kind != "Deployment" || ref.Name != "test-name" || ref.APIVersion != "apps/v1" {
+		t.Errorf("expected hpa to target deployment test-name, got %+v", ref)
+	}
+	if ptr.Deref(hpa.Spec.MinReplicas, 0) != 2 || hpa.Spec.MaxReplicas != 5 {
+		t.Errorf("expected 2 to 5 replicas, got %d to %d", ptr.Deref(hpa.Spec.MinReplicas, 0), hpa.Spec.MaxReplicas)
+	}
+	if len(hpa.Spec.Metrics) != 1 || hpa.Spec.Metrics[0].Resource.Name != "cpu" {
+		t.Errorf("unexpected metrics %+v", hpa.Spec.Metrics)
+	}
+}
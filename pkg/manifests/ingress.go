@@ -0,0 +1,79 @@
+package manifests
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// IngressOptions defines the available options for creating an Ingress routing paths to multiple Services.
+type IngressOptions struct {
+	// Host is the host name the Ingress serves.
+	Host string
+	// IngressClassName is the name of the IngressClass to use.
+	IngressClassName *string
+	// TLSSecretName is the name of the Secret holding the TLS certificate for the host.
+	TLSSecretName *string
+	// Annotations are the annotations to add to the Ingress.
+	Annotations map[string]string
+	// Paths are the path prefixes routed by the Ingress.
+	Paths []IngressPath
+}
+
+// IngressPath routes a path prefix to a named port of a Service.
+type IngressPath struct {
+	Path        string
+	ServiceName string
+	PortName    string
+}
+
+// BuildIngress creates an Ingress routing the given path prefixes to their Services.
+func BuildIngress(name, namespace string, objectMetaLabels map[string]string, opts IngressOptions) *networkingv1.Ingress {
+	paths := make([]networkingv1.HTTPIngressPath, len(opts.Paths))
+	for i, p := range opts.Paths {
+		paths[i] = networkingv1.HTTPIngressPath{
+			Path:     p.Path,
+			PathType: ptr.To(networkingv1.PathTypePrefix),
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: p.ServiceName,
+					Port: networkingv1.ServiceBackendPort{Name: p.PortName},
+				},
+			},
+		}
+	}
+
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      objectMetaLabels,
+			Annotations: opts.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: opts.IngressClassName,
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: opts.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{Paths: paths},
+					},
+				},
+			},
+		},
+	}
+
+	if opts.TLSSecretName != nil {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      []string{opts.Host},
+				SecretName: *opts.TLSSecretName,
+			},
+		}
+	}
+	return ingress
+}
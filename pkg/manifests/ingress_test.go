@@ -0,0 +1,43 @@
+package manifests
+
+import (
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestBuildIngress(t *testing.T) {
+	ingress := BuildIngress("thanos", "ns", map[string]string{"app": "thanos"}, IngressOptions{
+		Host:             "thanos.example.com",
+		IngressClassName: ptr.To("nginx"),
+		TLSSecretName:    ptr.To("thanos-tls"),
+		Paths: []IngressPath{
+			{Path: "/", ServiceName: "thanos-query", PortName: "http"},
+			{Path: "/ruler/rules", ServiceName: "thanos-ruler-rules", PortName: "http"},
+		},
+	})
+
+	if ingress.GetName() != "thanos" || ingress.GetNamespace() != "ns" || ingress.GetLabels()["app"] != "thanos" {
+		t.Errorf("unexpected object meta %v", ingress.ObjectMeta)
+	}
+	if ptr.Deref(ingress.Spec.IngressClassName, "") != "nginx" {
+		t.Errorf("expected ingress class nginx, got %v", ingress.Spec.IngressClassName)
+	}
+	if len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "thanos-tls" || ingress.Spec.TLS[0].Hosts[0] != "thanos.example.com" {
+		t.Errorf("unexpected tls configuration %v", ingress.Spec.TLS)
+	}
+
+	rule := ingress.Spec.Rules[0]
+	if rule.Host != "thanos.example.com" {
+		t.Errorf("expected host thanos.example.com, got %s", rule.Host)
+	}
+	if len(rule.HTTP.Paths) != 2 {
+		t.Fatalf("expected 2 paths, got %d", len(rule.HTTP.Paths))
+	}
+	path := rule.HTTP.Paths[1]
+	if path.Path != "/ruler/rules" || *path.PathType != networkingv1.PathTypePrefix ||
+		path.Backend.Service.Name != "thanos-ruler-rules" || path.Backend.Service.Port.Name != "http" {
+		t.Errorf("unexpected path %v", path)
+	}
+}
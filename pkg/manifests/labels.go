@@ -0,0 +1,216 @@
+package manifests
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The following labels are used to identify the components and will be set on the resources created by the operator.
+	// These labels cannot be overridden by the user via additional labels configuration.
+	// This is ensured by updates to components.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/#labels
+
+	NameLabel      = "app.kubernetes.io/name"
+	ComponentLabel = "app.kubernetes.io/component"
+	PartOfLabel    = "app.kubernetes.io/part-of"
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	InstanceLabel  = "app.kubernetes.io/instance"
+
+	// The following label is used to identify StoreAPIs and will be set on the resources created by the operator.
+	DefaultStoreAPILabel = "operator.thanos.io/store-api"
+	DefaultStoreAPIValue = "true"
+
+	// The following label is used to identify Query APIs and will be set on the resources created by the operator.
+	DefaultQueryAPILabel = "operator.thanos.io/query-api"
+	DefaultQueryAPIValue = "true"
+
+	// The following label is used to identify Rule Configs and will be set on the resources created by the operator.
+	DefaultRuleConfigLabel = "operator.thanos.io/rule-file"
+	DefaultRuleConfigValue = "true"
+
+	// DefaultPrometheusRuleLabel is the default label key for PrometheusRule CRDs
+	DefaultPrometheusRuleLabel = "operator.thanos.io/prometheus-rule"
+	// DefaultPrometheusRuleValue is the default label value for PrometheusRule CRDs
+	DefaultPrometheusRuleValue = "true"
+
+	// RuleSourceNamespaceLabel is set on ConfigMaps holding rules copied from another namespace
+	// and identifies the namespace the rules were gathered from.
+	RuleSourceNamespaceLabel = "operator.thanos.io/rule-source-namespace"
+
+	// OwnerNamespaceLabel is set on objects published outside the namespace of their owner
+	// and identifies the namespace of the owner, as the OwnerLabel only holds its name.
+	OwnerNamespaceLabel = "operator.thanos.io/owner-namespace"
+
+	// StoreTierLabel is the label used to identify the time based tier a Store Gateway belongs to.
+	StoreTierLabel = "operator.thanos.io/store-tier"
+
+	// StoreShardLabel is the label used to identify the shard a Store Gateway belongs to.
+	// Together with the group endpoint label set on Store Gateways with more than one replica per shard,
+	// it allows a Querier to load balance across the replicas of each shard.
+	StoreShardLabel = "operator.thanos.io/store-shard"
+
+	// QueryEndpointGroupLabel is the label used to identify the endpoint group a Querier serves.
+	QueryEndpointGroupLabel = "operator.thanos.io/query-endpoint-group"
+	// QueryPoolLabel is the label used to identify the query pool a Querier belongs to.
+	QueryPoolLabel = "operator.thanos.io/query-pool"
+)
+
+// The following management labels identify the resources managed by the operator.
+// They can be changed with ConfigureManagementLabels, e.g. by distributions embedding the operator.
+var (
+	// DefaultPartOfLabel is the value of the PartOfLabel.
+	DefaultPartOfLabel = "thanos"
+	// DefaultManagedByLabel is the value of the ManagedByLabel.
+	DefaultManagedByLabel = "thanos-operator"
+
+	// OwnerLabel is the label used to identify the owner of the object.
+	// This relates to the CustomResource or entity that created the object.
+	OwnerLabel = "operator.thanos.io/owner"
+
+	// extraManagementLabels are added to all resources managed by the operator, see AddManagementLabels.
+	extraManagementLabels map[string]string
+)
+
+// ManagementLabels configures the labels the operator uses to identify the resources it manages.
+// Empty fields keep the current value.
+type ManagementLabels struct {
+	// PartOf is the value of the PartOfLabel.
+	PartOf string
+	// ManagedBy is the value of the ManagedByLabel.
+	ManagedBy string
+	// OwnerLabel is the key of the label identifying the owner of a resource.
+	OwnerLabel string
+	// Extra are additional labels added to all resources managed by the operator.
+	// They are not part of any selector, and labels set by the operator take precedence over them.
+	Extra map[string]string
+}
+
+// ConfigureManagementLabels changes the labels the operator uses to identify the resources it manages.
+// It must be called before any resource is built. The management labels are part of the selectors of
+// the workloads, which are immutable, so existing workloads must be recreated after the labels changed.
+func ConfigureManagementLabels(l ManagementLabels) error {
+	var errs []error
+	for _, v := range []string{l.PartOf, l.ManagedBy} {
+		if msgs := validation.IsValidLabelValue(v); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid label value %q: %s", v, strings.Join(msgs, ", ")))
+		}
+	}
+	if l.OwnerLabel != "" {
+		if msgs := validation.IsQualifiedName(l.OwnerLabel); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid owner label %q: %s", l.OwnerLabel, strings.Join(msgs, ", ")))
+		}
+	}
+	for k, v := range l.Extra {
+		if msgs := slices.Concat(validation.IsQualifiedName(k), validation.IsValidLabelValue(v)); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid extra label %s=%s: %s", k, v, strings.Join(msgs, ", ")))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	DefaultPartOfLabel = cmp.Or(l.PartOf, DefaultPartOfLabel)
+	DefaultManagedByLabel = cmp.Or(l.ManagedBy, DefaultManagedByLabel)
+	OwnerLabel = cmp.Or(l.OwnerLabel, OwnerLabel)
+	extraManagementLabels = maps.Clone(l.Extra)
+	return nil
+}
+
+// AddManagementLabels adds the extra management labels configured with ConfigureManagementLabels to the object.
+// Labels already set on the object are kept.
+func AddManagementLabels(obj client.Object) {
+	if len(extraManagementLabels) == 0 {
+		return
+	}
+	obj.SetLabels(MergeLabels(extraManagementLabels, obj.GetLabels()))
+}
+
+// MergeLabels merges the provided labels with the default labels for a component.
+// Returns a new map with the merged labels leaving the original maps unchanged.
+func MergeLabels(baseLabels map[string]string, mergeWithPriority map[string]string) map[string]string {
+	if baseLabels == nil {
+		labelCopy := make(map[string]string, len(mergeWithPriority))
+		maps.Copy(labelCopy, mergeWithPriority)
+		return labelCopy
+	}
+
+	mergedLabels := make(map[string]string, len(baseLabels)+len(mergeWithPriority))
+	maps.Copy(mergedLabels, baseLabels)
+	maps.Copy(mergedLabels, mergeWithPriority)
+	return mergedLabels
+}
+
+// BuildLabelSelectorFrom builds a label selector from the provided label selector and required labels.
+// The required labels will be added to the MatchLabels of the provided label selector.
+// labelSelector is DeepCopied to avoid modifying the original object.
+func BuildLabelSelectorFrom(labelSelector *metav1.LabelSelector, requiredLabels map[string]string) (labels.Selector, error) {
+	ls := labelSelector.DeepCopy()
+
+	if ls == nil {
+		ls = &metav1.LabelSelector{MatchLabels: requiredLabels}
+	} else {
+		for k, v := range requiredLabels {
+			ls.MatchLabels[k] = v
+		}
+	}
+	return metav1.LabelSelectorAsSelector(ls)
+}
+
+// EndpointType is the type of endpoint flag to be used for attaching the StoreAPI to Querier.
+type EndpointType string
+
+const (
+	RegularLabel     EndpointType = "operator.thanos.io/endpoint"
+	StrictLabel      EndpointType = "operator.thanos.io/endpoint-strict"
+	GroupLabel       EndpointType = "operator.thanos.io/endpoint-group"
+	GroupStrictLabel EndpointType = "operator.thanos.io/endpoint-group-strict"
+)
+
+// endpointTypePriorityOrder is the order in which conflicting endpoint labels are resolved.
+var endpointTypePriorityOrder = []EndpointType{
+	GroupStrictLabel,
+	GroupLabel,
+	StrictLabel,
+	RegularLabel,
+}
+
+// SanitizeStoreAPIEndpointLabels ensures StoreAPI has only a single type of endpoint label.
+// If multiple endpoint types are set, the function will remove the conflicting labels based on priority.
+func SanitizeStoreAPIEndpointLabels(baseLabels map[string]string) map[string]string {
+	var foundLabel EndpointType
+	for _, label := range endpointTypePriorityOrder {
+		if _, exists := baseLabels[string(label)]; exists {
+			foundLabel = label
+			break
+		}
+	}
+
+	for _, label := range endpointTypePriorityOrder {
+		if label != foundLabel {
+			delete(baseLabels, string(label))
+		}
+	}
+
+	return baseLabels
+}
+
+// SetStoreAPIEndpointType sets the endpoint label of the given type on the StoreAPI labels,
+// removing any other endpoint labels.
+func SetStoreAPIEndpointType(baseLabels map[string]string, etype EndpointType) map[string]string {
+	for _, label := range endpointTypePriorityOrder {
+		delete(baseLabels, string(label))
+	}
+	baseLabels[string(etype)] = "true"
+	return baseLabels
+}
@@ -173,3 +173,52 @@ func TestSanitizeStoreAPIEndpointLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestSetStoreAPIEndpointType(t *testing.T) {
+	got := SetStoreAPIEndpointType(map[string]string{
+		"app": "thanos",
+		"operator.thanos.io/endpoint-group-strict": "true",
+		"operator.thanos.io/endpoint-group":        "true",
+	}, StrictLabel)
+
+	expected := map[string]string{
+		"app":                                "thanos",
+		"operator.thanos.io/endpoint-strict": "true",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestConfigureManagementLabels(t *testing.T) {
+	partOf, managedBy, owner := DefaultPartOfLabel, DefaultManagedByLabel, OwnerLabel
+	t.Cleanup(func() {
+		DefaultPartOfLabel, DefaultManagedByLabel, OwnerLabel, extraManagementLabels = partOf, managedBy, owner, nil
+	})
+
+	if err := ConfigureManagementLabels(ManagementLabels{OwnerLabel: "not a label"}); err == nil {
+		t.Fatal("expected an error for an invalid owner label")
+	}
+	if OwnerLabel != owner {
+		t.Errorf("expected the owner label to be unchanged after an error, got %s", OwnerLabel)
+	}
+
+	err := ConfigureManagementLabels(ManagementLabels{
+		ManagedBy:  "acme-operator",
+		OwnerLabel: "acme.io/owner",
+		Extra:      map[string]string{"acme.io/distribution": "acme", ManagedByLabel: "ignored"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if DefaultPartOfLabel != partOf || DefaultManagedByLabel != "acme-operator" || OwnerLabel != "acme.io/owner" {
+		t.Errorf("unexpected management labels %s, %s, %s", DefaultPartOfLabel, DefaultManagedByLabel, OwnerLabel)
+	}
+
+	obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{ManagedByLabel: DefaultManagedByLabel}}}
+	AddManagementLabels(obj)
+	expect := map[string]string{ManagedByLabel: "acme-operator", "acme.io/distribution": "acme"}
+	if !reflect.DeepEqual(obj.GetLabels(), expect) {
+		t.Errorf("expected labels %v, got %v", expect, obj.GetLabels())
+	}
+}
@@ -0,0 +1,219 @@
+package manifests
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// DefaultLogForwarderImage is the default image of the log forwarding sidecar.
+	DefaultLogForwarderImage = "docker.io/timberio/vector:0.43.1-distroless-libc"
+	// LogForwarderContainerName is the name of the log forwarding sidecar.
+	LogForwarderContainerName = "log-forwarder"
+
+	logForwardingConfigKey              = "vector.yaml"
+	logForwardingConfigVolumeName       = "log-forwarding-config"
+	logForwardingConfigMountPath        = "/etc/vector"
+	logForwardingLogsVolumeName         = "log-forwarding-logs"
+	logForwardingLogsMountPath          = "/var/log/thanos"
+	logForwardingDataVolumeName         = "log-forwarding-data"
+	logForwardingDataMountPath          = "/var/lib/vector"
+	logForwardingLogFileName            = "thanos.log"
+	logForwardingPipeName               = "thanos.pipe"
+	thanosBinaryPath                    = "/bin/thanos"
+	nobodyUserID                  int64 = 65534
+)
+
+// The defaults of the log forwarding sidecar can be changed with ConfigureLogForwarder,
+// e.g. to pull the image from a mirror in air-gapped environments.
+var (
+	logForwarderImage     = DefaultLogForwarderImage
+	logForwarderResources corev1.ResourceRequirements
+)
+
+// LogForwarderDefaults configures the log forwarding sidecar of all resources which do not override it.
+type LogForwarderDefaults struct {
+	// Image of the sidecar. If empty, DefaultLogForwarderImage is used.
+	Image string
+	// Registry replaces the registry of Image, e.g. with a mirror of the registry.
+	Registry string
+	// Resources are the resource requirements of the sidecar.
+	// They can be overridden per resource with the ContainerResources option.
+	Resources corev1.ResourceRequirements
+}
+
+// ConfigureLogForwarder changes the defaults of the log forwarding sidecar.
+// It must be called before any resource is built.
+func ConfigureLogForwarder(d LogForwarderDefaults) error {
+	var errs []error
+	if strings.Contains(d.Registry, "://") {
+		errs = append(errs, fmt.Errorf("invalid registry %q: must not contain a scheme", d.Registry))
+	}
+	for name, request := range d.Resources.Requests {
+		if limit, ok := d.Resources.Limits[name]; ok && request.Cmp(limit) > 0 {
+			errs = append(errs, fmt.Errorf("invalid resources: %s request %s exceeds the limit %s", name, request.String(), limit.String()))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	logForwarderImage = withRegistry(cmp.Or(d.Image, DefaultLogForwarderImage), d.Registry)
+	logForwarderResources = *d.Resources.DeepCopy()
+	return nil
+}
+
+// withRegistry replaces the registry of the image with the given registry.
+// Images without a registry are prefixed with it. An empty registry leaves the image unchanged.
+func withRegistry(image, registry string) string {
+	if registry == "" {
+		return image
+	}
+	repository := image
+	if host, rest, ok := strings.Cut(image, "/"); ok && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		repository = rest
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + repository
+}
+
+// LogForwardingOptions deploys a sidecar which forwards the logs of the Thanos container, such as request logs
+// and slow query logs, to an HTTP endpoint.
+// The output of the Thanos container is copied to a file on a volume shared with the sidecar, and is still written
+// to the logs of the container.
+type LogForwardingOptions struct {
+	// Image of the sidecar. It must run Vector.
+	// If not set, the image configured with ConfigureLogForwarder is used.
+	Image string
+	// Endpoint is the URL of the HTTP endpoint the logs are sent to as JSON encoded events.
+	Endpoint string
+}
+
+type vectorConfig struct {
+	DataDir string                    `yaml:"data_dir"`
+	Sources map[string]map[string]any `yaml:"sources"`
+	Sinks   map[string]map[string]any `yaml:"sinks"`
+}
+
+// LogForwardingConfigMapName returns the name of the ConfigMap holding the configuration of the sidecar
+// forwarding the logs of the given workload.
+func LogForwardingConfigMapName(workloadName string) string {
+	return ValidateAndSanitizeResourceName(fmt.Sprintf("%s-log-forwarding", workloadName))
+}
+
+// BuildLogForwardingConfigMap creates the ConfigMap holding the configuration of the sidecar
+// forwarding the logs of the given workload.
+func BuildLogForwardingConfigMap(workloadName, namespace string, objectMetaLabels map[string]string, opts LogForwardingOptions) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      LogForwardingConfigMapName(workloadName),
+			Namespace: namespace,
+			Labels:    objectMetaLabels,
+		},
+		Data: map[string]string{
+			logForwardingConfigKey: opts.toConfig(),
+		},
+	}
+}
+
+func (opts LogForwardingOptions) toConfig() string {
+	conf := vectorConfig{
+		DataDir: logForwardingDataMountPath,
+		Sources: map[string]map[string]any{
+			"thanos": {
+				"type":    "file",
+				"include": []string{path.Join(logForwardingLogsMountPath, logForwardingLogFileName)},
+			},
+		},
+		Sinks: map[string]map[string]any{
+			"endpoint": {
+				"type":     "http",
+				"inputs":   []string{"thanos"},
+				"uri":      opts.Endpoint,
+				"encoding": map[string]string{"codec": "json"},
+			},
+		},
+	}
+
+	b, err := yaml.Marshal(conf)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// addLogForwarding adds the log forwarding sidecar to the Pod of the workload with the given name.
+// The first container is run through a shell which copies its output to a file read by the sidecar,
+// while Thanos keeps running as the main process of the container and receives its signals.
+func addLogForwarding(spec *corev1.PodSpec, workloadName string, opts LogForwardingOptions) {
+	spec.Volumes = append(spec.Volumes,
+		corev1.Volume{
+			Name: logForwardingConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: LogForwardingConfigMapName(workloadName)},
+				},
+			},
+		},
+		corev1.Volume{
+			Name:         logForwardingLogsVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		corev1.Volume{
+			Name:         logForwardingDataVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+	)
+
+	logFile := path.Join(logForwardingLogsMountPath, logForwardingLogFileName)
+	pipe := path.Join(logForwardingLogsMountPath, logForwardingPipeName)
+	container := &spec.Containers[0]
+	container.Command = []string{
+		"/bin/sh", "-c",
+		fmt.Sprintf(`[ -p %[1]s ] || mkfifo %[1]s; tee -a %[2]s < %[1]s & exec %[3]s "$@" > %[1]s 2>&1`, pipe, logFile, thanosBinaryPath),
+		"thanos",
+	}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      logForwardingLogsVolumeName,
+		MountPath: logForwardingLogsMountPath,
+	})
+
+	securityContext := DefaultSecurityContext()
+	securityContext.RunAsUser = ptr.To(nobodyUserID)
+	spec.Containers = append(spec.Containers, corev1.Container{
+		Name:            LogForwarderContainerName,
+		Image:           cmp.Or(opts.Image, logForwarderImage),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Resources:       *logForwarderResources.DeepCopy(),
+		Args:            []string{"--config", path.Join(logForwardingConfigMountPath, logForwardingConfigKey), "--watch-config"},
+		SecurityContext: securityContext,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      logForwardingConfigVolumeName,
+				MountPath: logForwardingConfigMountPath,
+				ReadOnly:  true,
+			},
+			{
+				Name:      logForwardingLogsVolumeName,
+				MountPath: logForwardingLogsMountPath,
+				ReadOnly:  true,
+			},
+			{
+				Name:      logForwardingDataVolumeName,
+				MountPath: logForwardingDataMountPath,
+			},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	})
+}
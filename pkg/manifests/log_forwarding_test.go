@@ -0,0 +1,135 @@
+package manifests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAugmentWithOptions_LogForwarding(t *testing.T) {
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "thanos-query-test"}}
+	dep.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: "thanos",
+		Args: []string{"query", "--log.level=info"},
+	}}
+
+	sidecarResources := corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")}}
+	AugmentWithOptions(dep, Options{
+		LogForwarding:      &LogForwardingOptions{Endpoint: "https://audit.example.com/logs"},
+		ContainerResources: map[string]corev1.ResourceRequirements{LogForwarderContainerName: sidecarResources},
+	})
+
+	podSpec := dep.Spec.Template.Spec
+	if len(podSpec.Containers) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(podSpec.Containers))
+	}
+
+	thanos := podSpec.Containers[0]
+	if !reflect.DeepEqual(thanos.Args, []string{"query", "--log.level=info"}) {
+		t.Errorf("expected args of the Thanos container to be unchanged, got %v", thanos.Args)
+	}
+	if len(thanos.Command) != 4 || thanos.Command[0] != "/bin/sh" ||
+		!strings.Contains(thanos.Command[2], `exec /bin/thanos "$@" > /var/log/thanos/thanos.pipe 2>&1`) ||
+		!strings.Contains(thanos.Command[2], "tee -a /var/log/thanos/thanos.log < /var/log/thanos/thanos.pipe &") {
+		t.Errorf("expected Thanos container to copy its output to the shared log file, got %v", thanos.Command)
+	}
+
+	sidecar := podSpec.Containers[1]
+	if sidecar.Name != LogForwarderContainerName || sidecar.Image != DefaultLogForwarderImage {
+		t.Errorf("expected sidecar %s with image %s, got %s with image %s", LogForwarderContainerName, DefaultLogForwarderImage, sidecar.Name, sidecar.Image)
+	}
+	if !reflect.DeepEqual(sidecar.Resources, sidecarResources) {
+		t.Errorf("expected sidecar resources %v, got %v", sidecarResources, sidecar.Resources)
+	}
+	if sidecar.SecurityContext == nil || sidecar.SecurityContext.RunAsUser == nil || !*sidecar.SecurityContext.RunAsNonRoot {
+		t.Errorf("expected sidecar to run as non root user, got %v", sidecar.SecurityContext)
+	}
+
+	var configMap string
+	for _, v := range podSpec.Volumes {
+		if v.ConfigMap != nil {
+			configMap = v.ConfigMap.Name
+		}
+	}
+	if configMap != "thanos-query-test-log-forwarding" {
+		t.Errorf("expected sidecar config to be mounted from thanos-query-test-log-forwarding, got %s", configMap)
+	}
+}
+
+func TestBuildLogForwardingConfigMap(t *testing.T) {
+	cm := BuildLogForwardingConfigMap("thanos-query-test", "ns", map[string]string{"app": "test"}, LogForwardingOptions{Endpoint: "https://audit.example.com/logs"})
+	if cm.GetName() != "thanos-query-test-log-forwarding" || cm.GetNamespace() != "ns" {
+		t.Errorf("unexpected name %s/%s", cm.GetNamespace(), cm.GetName())
+	}
+
+	expect := `data_dir: /var/lib/vector
+sources:
+  thanos:
+    include:
+    - /var/log/thanos/thanos.log
+    type: file
+sinks:
+  endpoint:
+    encoding:
+      codec: json
+    inputs:
+    - thanos
+    type: http
+    uri: https://audit.example.com/logs
+`
+	if got := cm.Data["vector.yaml"]; got != expect {
+		t.Errorf("expected config\n%s\ngot\n%s", expect, got)
+	}
+}
+
+func TestConfigureLogForwarder(t *testing.T) {
+	t.Cleanup(func() {
+		logForwarderImage, logForwarderResources = DefaultLogForwarderImage, corev1.ResourceRequirements{}
+	})
+
+	err := ConfigureLogForwarder(LogForwarderDefaults{Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+	}})
+	if err == nil {
+		t.Fatal("expected an error for a request exceeding the limit")
+	}
+
+	resources := corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")}}
+	if err := ConfigureLogForwarder(LogForwarderDefaults{Registry: "mirror.example.com:5000/", Resources: resources}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "thanos-query-test"}}
+	dep.Spec.Template.Spec.Containers = []corev1.Container{{Name: "thanos"}}
+	AugmentWithOptions(dep, Options{LogForwarding: &LogForwardingOptions{Endpoint: "https://audit.example.com/logs"}})
+	sidecar := dep.Spec.Template.Spec.Containers[1]
+	if expect := "mirror.example.com:5000/timberio/vector:0.43.1-distroless-libc"; sidecar.Image != expect {
+		t.Errorf("expected image %s, got %s", expect, sidecar.Image)
+	}
+	if !reflect.DeepEqual(sidecar.Resources, resources) {
+		t.Errorf("expected sidecar resources %v, got %v", resources, sidecar.Resources)
+	}
+}
+
+func TestWithRegistry(t *testing.T) {
+	for _, tc := range []struct {
+		image    string
+		registry string
+		expect   string
+	}{
+		{image: "docker.io/timberio/vector:0.43.1", registry: "", expect: "docker.io/timberio/vector:0.43.1"},
+		{image: "docker.io/timberio/vector:0.43.1", registry: "mirror.example.com", expect: "mirror.example.com/timberio/vector:0.43.1"},
+		{image: "localhost/vector:0.43.1", registry: "mirror.example.com/vector", expect: "mirror.example.com/vector/vector:0.43.1"},
+		{image: "timberio/vector:0.43.1", registry: "mirror.example.com", expect: "mirror.example.com/timberio/vector:0.43.1"},
+	} {
+		if got := withRegistry(tc.image, tc.registry); got != tc.expect {
+			t.Errorf("expected %s with registry %q to be %s, got %s", tc.image, tc.registry, tc.expect, got)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package manifests
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// MemcachedCacheConfig is the configuration of a Memcached cache.
+type MemcachedCacheConfig struct {
+	// Addresses of the Memcached servers in host:port format, optionally with a DNS service discovery prefix.
+	Addresses []string
+	// MaxItemSize is the maximum size in bytes of an item stored in Memcached.
+	// The default of Thanos is used if zero.
+	MaxItemSize int64
+}
+
+type memcachedCacheConfig struct {
+	Type   string                `yaml:"type"`
+	Config memcachedClientConfig `yaml:"config"`
+}
+
+type memcachedClientConfig struct {
+	Addresses   []string `yaml:"addresses"`
+	MaxItemSize string   `yaml:"max_item_size,omitempty"`
+}
+
+// Config returns the Thanos cache configuration of the Memcached cache.
+func (mc MemcachedCacheConfig) Config() string {
+	conf := memcachedCacheConfig{
+		Type: "MEMCACHED",
+		Config: memcachedClientConfig{
+			Addresses: mc.Addresses,
+		},
+	}
+	if mc.MaxItemSize > 0 {
+		conf.Config.MaxItemSize = fmt.Sprintf("%dB", mc.MaxItemSize)
+	}
+	out, err := yaml.Marshal(conf)
+	if err != nil {
+		// marshalling a struct of strings does not fail
+		panic(err)
+	}
+	return string(out)
+}
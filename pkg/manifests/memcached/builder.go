@@ -0,0 +1,218 @@
+// Package memcached builds Memcached instances deployed by the operator to serve as a cache
+// for non-critical caching of Thanos components.
+package memcached
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Name is the name of the managed Memcached component.
+	Name = "memcached"
+
+	// ComponentName is the name of the managed Memcached component.
+	ComponentName = "cache"
+
+	// DefaultImage is the Memcached image used if none is set.
+	DefaultImage = "docker.io/library/memcached:1.6-alpine"
+
+	Port     = 11211
+	PortName = "client"
+
+	// memcachedUID is the ID of the memcache user of the official Memcached images.
+	memcachedUID = 11211
+)
+
+// Options for a managed Memcached.
+type Options struct {
+	// Owner is the name of the resource the Memcached is a cache of.
+	Owner string
+	// Cache is the name of the cache the Memcached serves, e.g. response-cache.
+	// It distinguishes the Memcached instances of an owner.
+	Cache     string
+	Namespace string
+	Labels    map[string]string
+	// Image is the Memcached container image. DefaultImage is used if not set.
+	Image string
+	// Replicas is the number of Memcached instances. A single instance is deployed if zero.
+	Replicas  int32
+	Resources *corev1.ResourceRequirements
+	// MemoryLimit is the number of bytes each instance uses for items, beyond which the least recently used items are evicted.
+	// The default of Memcached is used if zero.
+	MemoryLimit int64
+	// MaxItemSize is the maximum size in bytes of an item. The default of Memcached is used if zero.
+	MaxItemSize int64
+}
+
+// Build builds the StatefulSet and the headless Service of the Memcached.
+func (opts Options) Build() []client.Object {
+	selectorLabels := opts.GetSelectorLabels()
+	objectMetaLabels := GetLabels(opts)
+	return []client.Object{
+		newStatefulSet(opts, selectorLabels, objectMetaLabels),
+		newService(opts, selectorLabels, objectMetaLabels),
+	}
+}
+
+// GetGeneratedResourceName returns the name of the resources of the Memcached of the cache of the owner.
+func (opts Options) GetGeneratedResourceName() string {
+	return manifests.ValidateAndSanitizeResourceName(fmt.Sprintf("%s-%s-%s", Name, opts.Owner, opts.Cache))
+}
+
+// GetAddress returns the address of the Memcached instances, discovered through the DNS SRV records of the headless Service.
+func (opts Options) GetAddress() string {
+	return fmt.Sprintf("dnssrv+_%s._tcp.%s.%s.svc", PortName, opts.GetGeneratedResourceName(), opts.Namespace)
+}
+
+func newStatefulSet(opts Options, selectorLabels, objectMetaLabels map[string]string) *appsv1.StatefulSet {
+	image := opts.Image
+	if image == "" {
+		image = DefaultImage
+	}
+	replicas := opts.Replicas
+	if replicas < 1 {
+		replicas = 1
+	}
+	args := []string{
+		"-p", strconv.Itoa(Port),
+	}
+	if opts.MemoryLimit > 0 {
+		// memcached takes the memory limit in megabytes
+		args = append(args, "-m", strconv.FormatInt(max(opts.MemoryLimit>>20, 1), 10))
+	}
+	if opts.MaxItemSize > 0 {
+		args = append(args, "-I", strconv.FormatInt(opts.MaxItemSize, 10))
+	}
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(PortName)},
+		},
+		PeriodSeconds: 10,
+	}
+
+	name := opts.GetGeneratedResourceName()
+	return &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: opts.Namespace,
+			Labels:    objectMetaLabels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    ptr.To(replicas),
+			ServiceName: name,
+			// instances hold disjoint items, so there is no ordering to preserve between them
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorLabels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: objectMetaLabels,
+				},
+				Spec: corev1.PodSpec{
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:      ptr.To(int64(memcachedUID)),
+						RunAsGroup:     ptr.To(int64(memcachedUID)),
+						SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            Name,
+							Image:           image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Args:            args,
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          PortName,
+									ContainerPort: Port,
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: ptr.To(false),
+								RunAsNonRoot:             ptr.To(true),
+								ReadOnlyRootFilesystem:   ptr.To(true),
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{
+										"ALL",
+									},
+								},
+							},
+							LivenessProbe:            probe,
+							ReadinessProbe:           probe,
+							Resources:                ptr.Deref(opts.Resources, corev1.ResourceRequirements{}),
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newService(opts Options, selectorLabels, objectMetaLabels map[string]string) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opts.GetGeneratedResourceName(),
+			Namespace: opts.Namespace,
+			Labels:    objectMetaLabels,
+		},
+		Spec: corev1.ServiceSpec{
+			// headless, so that clients discover and shard items across the instances
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  selectorLabels,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       PortName,
+					Port:       Port,
+					TargetPort: intstr.FromString(PortName),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
+
+// GetRequiredLabels returns a map of labels that can be used to look up managed Memcached resources.
+// These labels are guaranteed to be present on all resources created by this package.
+func GetRequiredLabels() map[string]string {
+	return map[string]string{
+		manifests.NameLabel:      Name,
+		manifests.ComponentLabel: ComponentName,
+		manifests.PartOfLabel:    manifests.DefaultPartOfLabel,
+		manifests.ManagedByLabel: manifests.DefaultManagedByLabel,
+	}
+}
+
+// GetSelectorLabels returns a map of labels that can be used to look up the resources of the Memcached.
+func (opts Options) GetSelectorLabels() map[string]string {
+	labels := GetRequiredLabels()
+	labels[manifests.InstanceLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.GetGeneratedResourceName())
+	labels[manifests.OwnerLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.Owner)
+	return labels
+}
+
+// GetLabels returns the labels that will be set as ObjectMeta labels for the resources of the Memcached.
+func GetLabels(opts Options) map[string]string {
+	return manifests.MergeLabels(opts.Labels, opts.GetSelectorLabels())
+}
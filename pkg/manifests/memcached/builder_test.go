@@ -0,0 +1,62 @@
+package memcached
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuild(t *testing.T) {
+	opts := Options{
+		Owner:       "query",
+		Cache:       "response-cache",
+		Namespace:   "ns",
+		Labels:      map[string]string{"team": "a", manifests.NameLabel: "expect-to-be-discarded"},
+		Replicas:    3,
+		MemoryLimit: 256 << 20,
+		MaxItemSize: 1 << 20,
+	}
+	if name := opts.GetGeneratedResourceName(); name != "memcached-query-response-cache" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if addr := opts.GetAddress(); addr != "dnssrv+_client._tcp.memcached-query-response-cache.ns.svc" {
+		t.Errorf("unexpected address %s", addr)
+	}
+
+	objs := opts.Build()
+	if len(objs) != 2 {
+		t.Fatalf("expected a StatefulSet and a Service, got %d objects", len(objs))
+	}
+	sts, ok := objs[0].(*appsv1.StatefulSet)
+	if !ok {
+		t.Fatalf("expected a StatefulSet, got %T", objs[0])
+	}
+	if sts.Labels["team"] != "a" || sts.Labels[manifests.NameLabel] != Name {
+		t.Errorf("unexpected labels %v", sts.Labels)
+	}
+	if *sts.Spec.Replicas != 3 || sts.Spec.ServiceName != "memcached-query-response-cache" {
+		t.Errorf("unexpected statefulset spec %v", sts.Spec)
+	}
+	container := sts.Spec.Template.Spec.Containers[0]
+	if container.Image != DefaultImage {
+		t.Errorf("expected default image, got %s", container.Image)
+	}
+	if i := slices.Index(container.Args, "-m"); i < 0 || container.Args[i+1] != "256" {
+		t.Errorf("expected the memory to be limited in megabytes, got %v", container.Args)
+	}
+	if i := slices.Index(container.Args, "-I"); i < 0 || container.Args[i+1] != "1048576" {
+		t.Errorf("expected the item size to be limited, got %v", container.Args)
+	}
+
+	svc, ok := objs[1].(*corev1.Service)
+	if !ok {
+		t.Fatalf("expected a Service, got %T", objs[1])
+	}
+	if svc.Spec.ClusterIP != corev1.ClusterIPNone || svc.Spec.Ports[0].Name != PortName {
+		t.Errorf("expected a headless service with a named port for SRV discovery, got %v", svc.Spec)
+	}
+}
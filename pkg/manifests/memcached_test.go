@@ -0,0 +1,21 @@
+package manifests
+
+import (
+	"testing"
+)
+
+func TestMemcachedCacheConfig(t *testing.T) {
+	mc := MemcachedCacheConfig{
+		Addresses:   []string{"dnssrv+_client._tcp.memcached.ns.svc"},
+		MaxItemSize: 1 << 20,
+	}
+	expect := `type: MEMCACHED
+config:
+  addresses:
+  - dnssrv+_client._tcp.memcached.ns.svc
+  max_item_size: 1048576B
+`
+	if got := mc.Config(); got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+}
@@ -0,0 +1,129 @@
+package manifests
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Mount mounts a ConfigMap or Secret into the Thanos component container.
+// Exactly one of ConfigMap and Secret must be set.
+type Mount struct {
+	// Name is the name of the generated Volume.
+	Name      string
+	ConfigMap *MountSource
+	Secret    *MountSource
+	// MountPath is the absolute path within the container at which the source is mounted.
+	MountPath string
+	// SubPath is the path within the source to mount at MountPath instead of its root.
+	SubPath string
+}
+
+// MountSource selects the keys of a ConfigMap or Secret to mount.
+type MountSource struct {
+	Name     string
+	Items    []corev1.KeyToPath
+	Optional *bool
+}
+
+// Validate returns an error if the Mounts are invalid, or if they clash with the additional Volumes and VolumeMounts.
+// It also returns an error if the additional Ports or ServicePorts are unnamed or clash with each other.
+func (a Additional) Validate() error {
+	var errs []error
+	if err := a.validatePorts(); err != nil {
+		errs = append(errs, err)
+	}
+	volumes := make(map[string]struct{}, len(a.Volumes)+len(a.Mounts))
+	for _, v := range a.Volumes {
+		volumes[v.Name] = struct{}{}
+	}
+	mountPaths := make(map[string]struct{}, len(a.VolumeMounts)+len(a.Mounts))
+	for _, vm := range a.VolumeMounts {
+		mountPaths[vm.MountPath] = struct{}{}
+	}
+
+	for _, m := range a.Mounts {
+		if err := m.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("mount %s: %w", m.Name, err))
+		}
+		if _, ok := volumes[m.Name]; ok {
+			errs = append(errs, fmt.Errorf("mount %s: volume name is already in use", m.Name))
+		}
+		volumes[m.Name] = struct{}{}
+		if _, ok := mountPaths[m.MountPath]; ok {
+			errs = append(errs, fmt.Errorf("mount %s: mount path %s is already in use", m.Name, m.MountPath))
+		}
+		mountPaths[m.MountPath] = struct{}{}
+	}
+	return errors.Join(errs...)
+}
+
+func (m Mount) validate() error {
+	if (m.ConfigMap == nil) == (m.Secret == nil) {
+		return errors.New("exactly one of configMap or secret must be set")
+	}
+	if !path.IsAbs(m.MountPath) {
+		return fmt.Errorf("mount path %q must be absolute", m.MountPath)
+	}
+	if m.SubPath == "" {
+		return nil
+	}
+	if path.IsAbs(m.SubPath) || slices.Contains(strings.Split(m.SubPath, "/"), "..") {
+		return fmt.Errorf("sub path %q must be a relative path within the source", m.SubPath)
+	}
+	if items := m.source().Items; len(items) > 0 && !slices.ContainsFunc(items, func(item corev1.KeyToPath) bool {
+		return item.Path == m.SubPath || strings.HasPrefix(item.Path, m.SubPath+"/")
+	}) {
+		return fmt.Errorf("sub path %q does not match the path of any item", m.SubPath)
+	}
+	return nil
+}
+
+func (m Mount) source() MountSource {
+	if m.ConfigMap != nil {
+		return *m.ConfigMap
+	}
+	return *m.Secret
+}
+
+// Volume returns the Volume for the Mount.
+func (m Mount) Volume() corev1.Volume {
+	v := corev1.Volume{Name: m.Name}
+	switch {
+	case m.ConfigMap != nil:
+		v.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: m.ConfigMap.Name},
+			Items:                m.ConfigMap.Items,
+			Optional:             m.ConfigMap.Optional,
+		}
+	case m.Secret != nil:
+		v.Secret = &corev1.SecretVolumeSource{
+			SecretName: m.Secret.Name,
+			Items:      m.Secret.Items,
+			Optional:   m.Secret.Optional,
+		}
+	}
+	return v
+}
+
+// VolumeMount returns the read-only VolumeMount for the Mount.
+func (m Mount) VolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      m.Name,
+		MountPath: m.MountPath,
+		SubPath:   m.SubPath,
+		ReadOnly:  true,
+	}
+}
+
+// addMounts adds the Volumes of the mounts to the Pod and mounts them into its first container.
+func addMounts(spec *corev1.PodSpec, mounts []Mount) {
+	for _, m := range mounts {
+		spec.Volumes = append(spec.Volumes, m.Volume())
+		spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, m.VolumeMount())
+	}
+}
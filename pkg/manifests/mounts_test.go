@@ -0,0 +1,123 @@
+package manifests
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAdditional_Validate(t *testing.T) {
+	configMap := &MountSource{
+		Name:  "config",
+		Items: []corev1.KeyToPath{{Key: "config.yaml", Path: "conf/config.yaml"}},
+	}
+
+	tests := []struct {
+		name       string
+		additional Additional
+		wantErr    bool
+	}{
+		{
+			name: "valid mounts",
+			additional: Additional{
+				Mounts: []Mount{
+					{Name: "config", ConfigMap: configMap, MountPath: "/etc/config.yaml", SubPath: "conf/config.yaml"},
+					{Name: "certs", Secret: &MountSource{Name: "certs"}, MountPath: "/etc/certs"},
+				},
+			},
+		},
+		{
+			name: "sub path of directory of items",
+			additional: Additional{
+				Mounts: []Mount{{Name: "config", ConfigMap: configMap, MountPath: "/etc/conf", SubPath: "conf"}},
+			},
+		},
+		{
+			name: "no source",
+			additional: Additional{
+				Mounts: []Mount{{Name: "config", MountPath: "/etc/config"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "both sources",
+			additional: Additional{
+				Mounts: []Mount{{Name: "config", ConfigMap: configMap, Secret: &MountSource{Name: "certs"}, MountPath: "/etc/config"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative mount path",
+			additional: Additional{
+				Mounts: []Mount{{Name: "config", ConfigMap: configMap, MountPath: "etc/config"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sub path escaping source",
+			additional: Additional{
+				Mounts: []Mount{{Name: "certs", Secret: &MountSource{Name: "certs"}, MountPath: "/etc/certs", SubPath: "../token"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sub path not matching items",
+			additional: Additional{
+				Mounts: []Mount{{Name: "config", ConfigMap: configMap, MountPath: "/etc/config.yaml", SubPath: "config.yaml"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "name clashes with additional volume",
+			additional: Additional{
+				Volumes: []corev1.Volume{{Name: "config"}},
+				Mounts:  []Mount{{Name: "config", ConfigMap: configMap, MountPath: "/etc/config"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mount path clashes with additional volume mount",
+			additional: Additional{
+				VolumeMounts: []corev1.VolumeMount{{Name: "other", MountPath: "/etc/config"}},
+				Mounts:       []Mount{{Name: "config", ConfigMap: configMap, MountPath: "/etc/config"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.additional.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAugmentWithOptions_Mounts(t *testing.T) {
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "thanos"}}
+
+	AugmentWithOptions(deployment, Options{
+		Additional: Additional{
+			Mounts: []Mount{
+				{Name: "certs", Secret: &MountSource{Name: "tls"}, MountPath: "/etc/tls/ca.crt", SubPath: "ca.crt"},
+			},
+		},
+	})
+
+	expectVolumes := []corev1.Volume{
+		{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls"}}},
+	}
+	if !reflect.DeepEqual(deployment.Spec.Template.Spec.Volumes, expectVolumes) {
+		t.Errorf("expected volumes %v, got %v", expectVolumes, deployment.Spec.Template.Spec.Volumes)
+	}
+
+	expectMounts := []corev1.VolumeMount{
+		{Name: "certs", MountPath: "/etc/tls/ca.crt", SubPath: "ca.crt", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, expectMounts) {
+		t.Errorf("expected volume mounts %v, got %v", expectMounts, deployment.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+}
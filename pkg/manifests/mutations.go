@@ -9,8 +9,12 @@ import (
 	"github.com/imdario/mergo"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -26,6 +30,10 @@ import (
 //   - Deployment
 //   - StatefulSet
 //   - ServiceMonitor
+//   - PodDisruptionBudget
+//   - Job
+//   - Ingress
+//   - HorizontalPodAutoscaler
 func MutateFuncFor(existing, desired client.Object) controllerutil.MutateFn {
 	return func() error {
 		existingAnnotations := existing.GetAnnotations()
@@ -86,6 +94,21 @@ func MutateFuncFor(existing, desired client.Object) controllerutil.MutateFn {
 			pdb := existing.(*policyv1.PodDisruptionBudget)
 			wantPdb := desired.(*policyv1.PodDisruptionBudget)
 			mutatePodDisruptionBudget(pdb, wantPdb)
+
+		case *batchv1.Job:
+			job := existing.(*batchv1.Job)
+			wantJob := desired.(*batchv1.Job)
+			mutateJob(job, wantJob)
+
+		case *networkingv1.Ingress:
+			ing := existing.(*networkingv1.Ingress)
+			wantIng := desired.(*networkingv1.Ingress)
+			mutateIngress(ing, wantIng)
+
+		case *autoscalingv2.HorizontalPodAutoscaler:
+			hpa := existing.(*autoscalingv2.HorizontalPodAutoscaler)
+			wantHpa := desired.(*autoscalingv2.HorizontalPodAutoscaler)
+			mutateHorizontalPodAutoscaler(hpa, wantHpa)
 		default:
 			t := reflect.TypeOf(existing).String()
 			return fmt.Errorf("missing mutate implementation for resource type %v", t)
@@ -94,6 +117,69 @@ func MutateFuncFor(existing, desired client.Object) controllerutil.MutateFn {
 	}
 }
 
+// ImmutableFieldsChanged returns true if the desired object changes fields of the existing object which cannot be
+// updated, so that the object must be recreated to apply them.
+// It currently considers the following fields:
+//
+//   - the selector of a Deployment
+//   - the selector, service name and volume claim templates of a StatefulSet
+//   - switching a Service from or to being headless
+//
+// Fields which are defaulted by the API server are only compared if the desired object sets them.
+// Jobs are never considered, since recreating them reruns their workload.
+func ImmutableFieldsChanged(existing, desired client.Object) bool {
+	switch e := existing.(type) {
+	case *appsv1.Deployment:
+		d, ok := desired.(*appsv1.Deployment)
+		return ok && !equality.Semantic.DeepEqual(e.Spec.Selector, d.Spec.Selector)
+	case *appsv1.StatefulSet:
+		d, ok := desired.(*appsv1.StatefulSet)
+		return ok && (!equality.Semantic.DeepEqual(e.Spec.Selector, d.Spec.Selector) ||
+			e.Spec.ServiceName != d.Spec.ServiceName ||
+			volumeClaimTemplatesChanged(e.Spec.VolumeClaimTemplates, d.Spec.VolumeClaimTemplates))
+	case *corev1.Service:
+		d, ok := desired.(*corev1.Service)
+		return ok && (e.Spec.ClusterIP == corev1.ClusterIPNone) != (d.Spec.ClusterIP == corev1.ClusterIPNone)
+	}
+	return false
+}
+
+// RetainImmutableFields copies the fields of the existing object which cannot be updated, but are not considered
+// by ImmutableFieldsChanged, to the desired object, so that the desired object can be applied to the existing one.
+// It currently retains the following fields:
+//
+//   - the volume claim templates of a StatefulSet, whose storage is expanded and whose metadata is updated on the claims instead
+//   - the spec of a Job
+func RetainImmutableFields(existing, desired client.Object) {
+	switch e := existing.(type) {
+	case *appsv1.StatefulSet:
+		if d, ok := desired.(*appsv1.StatefulSet); ok {
+			d.Spec.VolumeClaimTemplates = e.Spec.VolumeClaimTemplates
+		}
+	case *batchv1.Job:
+		if d, ok := desired.(*batchv1.Job); ok {
+			d.Spec = e.Spec
+		}
+	}
+}
+
+// volumeClaimTemplatesChanged returns true if templates are added, removed or request another storage class.
+// Changes of the requested storage are handled by expanding the existing claims instead.
+func volumeClaimTemplatesChanged(existing, desired []corev1.PersistentVolumeClaim) bool {
+	if len(existing) != len(desired) {
+		return true
+	}
+	for i := range desired {
+		if existing[i].GetName() != desired[i].GetName() {
+			return true
+		}
+		if desired[i].Spec.StorageClassName != nil && !equality.Semantic.DeepEqual(existing[i].Spec.StorageClassName, desired[i].Spec.StorageClassName) {
+			return true
+		}
+	}
+	return false
+}
+
 func mergeWithOverride(dst, src interface{}) error {
 	err := mergo.Merge(dst, src, mergo.WithOverride)
 	if err != nil {
@@ -134,8 +220,14 @@ func mutateDeployment(existing, desired *appsv1.Deployment) {
 	if existing.CreationTimestamp.IsZero() {
 		existing.Spec.Selector = desired.Spec.Selector
 	}
-	existing.Spec.Replicas = desired.Spec.Replicas
+	// the replicas of an autoscaled Deployment are left to its HorizontalPodAutoscaler
+	if desired.Spec.Replicas != nil {
+		existing.Spec.Replicas = desired.Spec.Replicas
+	}
 	existing.Spec.Strategy = desired.Spec.Strategy
+	if desired.Spec.ProgressDeadlineSeconds != nil {
+		existing.Spec.ProgressDeadlineSeconds = desired.Spec.ProgressDeadlineSeconds
+	}
 	mutatePodTemplate(&existing.Spec.Template, &desired.Spec.Template)
 }
 
@@ -146,6 +238,9 @@ func mutateStatefulSet(existing, desired *appsv1.StatefulSet) {
 		existing.Spec.Selector = desired.Spec.Selector
 	}
 	existing.Spec.Replicas = desired.Spec.Replicas
+	if desired.Spec.PersistentVolumeClaimRetentionPolicy != nil {
+		existing.Spec.PersistentVolumeClaimRetentionPolicy = desired.Spec.PersistentVolumeClaimRetentionPolicy
+	}
 	mutatePodTemplate(&existing.Spec.Template, &desired.Spec.Template)
 }
 
@@ -160,6 +255,8 @@ func mutatePodSpec(existing *corev1.PodSpec, desired *corev1.PodSpec) {
 	existing.Containers = desired.Containers
 	existing.InitContainers = desired.InitContainers
 	existing.NodeSelector = desired.NodeSelector
+	existing.SecurityContext = desired.SecurityContext
+	existing.ServiceAccountName = desired.ServiceAccountName
 	existing.Tolerations = desired.Tolerations
 	existing.TopologySpreadConstraints = desired.TopologySpreadConstraints
 	existing.Volumes = desired.Volumes
@@ -178,3 +275,23 @@ func mutatePodDisruptionBudget(existing, desired *policyv1.PodDisruptionBudget)
 	existing.Labels = desired.Labels
 	existing.Spec = desired.Spec
 }
+
+func mutateJob(existing, desired *batchv1.Job) {
+	// Job pod template is immutable so we set the spec only if
+	// a new object is going to be created
+	if existing.CreationTimestamp.IsZero() {
+		existing.Spec = desired.Spec
+	}
+}
+
+func mutateIngress(existing, desired *networkingv1.Ingress) {
+	existing.Annotations = desired.Annotations
+	existing.Labels = desired.Labels
+	existing.Spec = desired.Spec
+}
+
+func mutateHorizontalPodAutoscaler(existing, desired *autoscalingv2.HorizontalPodAutoscaler) {
+	existing.Annotations = desired.Annotations
+	existing.Labels = desired.Labels
+	existing.Spec = desired.Spec
+}
@@ -8,11 +8,15 @@ import (
 	"github.com/stretchr/testify/require"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestGetMutateFunc_MutateObjectMeta(t *testing.T) {
@@ -350,6 +354,40 @@ func TestMutateFuncFor_MutateDeploymentSpec(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "update service account of pod",
+			got: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{ServiceAccountName: "generated"},
+					},
+				},
+			},
+			want: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{ServiceAccountName: "existing"},
+					},
+				},
+			},
+		},
+		{
+			name: "update security context of pod",
+			got: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{SecurityContext: &corev1.PodSecurityContext{RunAsUser: ptr.To[int64](1000)}},
+					},
+				},
+			},
+			want: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{SecurityContext: &corev1.PodSecurityContext{RunAsUser: ptr.To[int64](65534), RunAsNonRoot: ptr.To(true)}},
+					},
+				},
+			},
+		},
 		{
 			name: "remove extra annotations and labels on pod",
 			got: &appsv1.Deployment{
@@ -407,6 +445,22 @@ func TestMutateFuncFor_MutateDeploymentSpec(t *testing.T) {
 	}
 }
 
+func TestMutateFuncFor_MutateAutoscaledDeployment(t *testing.T) {
+	got := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{Replicas: ptr.To(int32(4))},
+	}
+	want := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}},
+	}
+
+	f := MutateFuncFor(got, want)
+	err := f()
+
+	require.NoError(t, err)
+	require.Exactly(t, ptr.To(int32(4)), got.Spec.Replicas)
+	require.Exactly(t, want.Spec.Strategy, got.Spec.Strategy)
+}
+
 func TestMutateFuncFor_MutateStatefulSetSpec(t *testing.T) {
 	type test struct {
 		name string
@@ -446,6 +500,10 @@ func TestMutateFuncFor_MutateStatefulSetSpec(t *testing.T) {
 			want: &appsv1.StatefulSet{
 				Spec: appsv1.StatefulSetSpec{
 					PodManagementPolicy: appsv1.OrderedReadyPodManagement,
+					PersistentVolumeClaimRetentionPolicy: &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+						WhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+						WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+					},
 					Selector: &metav1.LabelSelector{
 						MatchLabels: map[string]string{
 							"test": "test",
@@ -592,6 +650,7 @@ func TestMutateFuncFor_MutateStatefulSetSpec(t *testing.T) {
 			// Ensure partial mutation applied
 			require.Equal(t, tst.got.Spec.Replicas, tst.want.Spec.Replicas)
 			require.Equal(t, tst.got.Spec.Template, tst.want.Spec.Template)
+			require.Equal(t, tst.got.Spec.PersistentVolumeClaimRetentionPolicy, tst.want.Spec.PersistentVolumeClaimRetentionPolicy)
 			require.Equal(t, tst.got.Spec.VolumeClaimTemplates, tst.got.Spec.VolumeClaimTemplates)
 		})
 	}
@@ -669,3 +728,251 @@ func TestMutateFuncFor_ServiceMonitor(t *testing.T) {
 	require.Exactly(t, got.Spec.NamespaceSelector, want.Spec.NamespaceSelector)
 	require.Exactly(t, got.Spec.Selector, want.Spec.Selector)
 }
+
+func TestMutateFuncFor_MutateIngress(t *testing.T) {
+	got := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"test": "test",
+			},
+		},
+	}
+
+	want := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"test": "test",
+				"new":  "label",
+			},
+			Annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/auth-type": "basic",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: ptr.To("nginx"),
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "example.com",
+				},
+			},
+		},
+	}
+
+	f := MutateFuncFor(got, want)
+	err := f()
+
+	require.NoError(t, err)
+	require.Exactly(t, got.Labels, want.Labels)
+	require.Exactly(t, got.Annotations, want.Annotations)
+	require.Exactly(t, got.Spec, want.Spec)
+}
+
+func TestMutateFuncFor_MutateJob(t *testing.T) {
+	type test struct {
+		name string
+		got  *batchv1.Job
+		want *batchv1.Job
+	}
+	table := []test{
+		{
+			name: "initial creation",
+			got: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+			want: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"test": "test",
+						"new":  "label",
+					},
+				},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: ptr.To[int32](1),
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "test",
+									Image: "test",
+									Args:  []string{"tools", "bucket", "mark"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "update",
+			got: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					CreationTimestamp: metav1.Now(),
+					Labels: map[string]string{
+						"test": "test",
+					},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "test",
+									Image: "test",
+									Args:  []string{"tools", "bucket", "mark"},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"test": "test",
+						"new":  "label",
+					},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "test",
+									Image: "test",
+									Args:  []string{"tools", "bucket", "retention"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			f := MutateFuncFor(tst.got, tst.want)
+			err := f()
+			require.NoError(t, err)
+
+			require.Exactly(t, tst.got.Labels, tst.want.Labels)
+			if tst.got.CreationTimestamp.IsZero() {
+				require.Exactly(t, tst.got.Spec, tst.want.Spec)
+			} else {
+				// Ensure the immutable pod template is not mutated
+				require.NotEqual(t, tst.got.Spec.Template, tst.want.Spec.Template)
+			}
+		})
+	}
+}
+
+func TestImmutableFieldsChanged(t *testing.T) {
+	sts := func(app, serviceName string, storageClass *string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{
+				Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+				ServiceName: serviceName,
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "data"},
+						Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: storageClass},
+					},
+				},
+			},
+		}
+	}
+	svc := func(clusterIP string) *corev1.Service {
+		return &corev1.Service{Spec: corev1.ServiceSpec{ClusterIP: clusterIP}}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		existing client.Object
+		desired  client.Object
+		expect   bool
+	}{
+		{
+			name:     "unchanged statefulset",
+			existing: sts("a", "svc", ptr.To("standard")),
+			desired:  sts("a", "svc", ptr.To("standard")),
+		},
+		{
+			name:     "statefulset selector",
+			existing: sts("a", "svc", nil),
+			desired:  sts("b", "svc", nil),
+			expect:   true,
+		},
+		{
+			name:     "statefulset service name",
+			existing: sts("a", "svc", nil),
+			desired:  sts("a", "other", nil),
+			expect:   true,
+		},
+		{
+			name:     "statefulset storage class",
+			existing: sts("a", "svc", ptr.To("standard")),
+			desired:  sts("a", "svc", ptr.To("fast")),
+			expect:   true,
+		},
+		{
+			name:     "statefulset defaulted storage class",
+			existing: sts("a", "svc", ptr.To("standard")),
+			desired:  sts("a", "svc", nil),
+		},
+		{
+			name:     "deployment selector",
+			existing: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}}}},
+			desired:  &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "b"}}}},
+			expect:   true,
+		},
+		{
+			name:     "service made headless",
+			existing: svc("10.0.0.1"),
+			desired:  svc(corev1.ClusterIPNone),
+			expect:   true,
+		},
+		{
+			name:     "service with allocated cluster IP",
+			existing: svc("10.0.0.1"),
+			desired:  svc(""),
+		},
+		{
+			name:     "job",
+			existing: &batchv1.Job{Spec: batchv1.JobSpec{Parallelism: ptr.To(int32(1))}},
+			desired:  &batchv1.Job{Spec: batchv1.JobSpec{Parallelism: ptr.To(int32(2))}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expect, ImmutableFieldsChanged(tc.existing, tc.desired))
+		})
+	}
+}
+
+func TestRetainImmutableFields(t *testing.T) {
+	existingSts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{
+		VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+	}}
+	desiredSts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{
+		Replicas: ptr.To(int32(3)),
+		VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+			{ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: map[string]string{"team": "a"}}},
+		},
+	}}
+	RetainImmutableFields(existingSts, desiredSts)
+	require.Equal(t, existingSts.Spec.VolumeClaimTemplates, desiredSts.Spec.VolumeClaimTemplates)
+	require.Equal(t, int32(3), *desiredSts.Spec.Replicas)
+
+	existingJob := &batchv1.Job{Spec: batchv1.JobSpec{BackoffLimit: ptr.To(int32(1))}}
+	desiredJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "tools"}},
+		Spec:       batchv1.JobSpec{BackoffLimit: ptr.To(int32(6))},
+	}
+	RetainImmutableFields(existingJob, desiredJob)
+	require.Equal(t, existingJob.Spec, desiredJob.Spec)
+	require.Equal(t, "tools", desiredJob.Labels["app"])
+}
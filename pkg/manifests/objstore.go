@@ -0,0 +1,193 @@
+package manifests
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// ObjectStorageConfigKey is the key in the Secret for the rendered object storage configuration.
+	ObjectStorageConfigKey = "thanos.yaml"
+
+	objStoreAccessKeyEnvVarName  = "OBJSTORE_ACCESS_KEY"
+	objStoreSecretKeyEnvVarName  = "OBJSTORE_SECRET_KEY"
+	objStoreAccountKeyEnvVarName = "OBJSTORE_ACCOUNT_KEY"
+)
+
+// ObjectStorageOptions defines an object storage configuration rendered by the operator.
+// Exactly one provider must be set.
+// Credentials are not rendered into the configuration. They are read from the referenced Secrets into environment
+// variables of the Thanos container, returned by EnvVars, which Thanos substitutes in the configuration.
+type ObjectStorageOptions struct {
+	S3    *S3Options
+	GCS   *GCSOptions
+	Azure *AzureOptions
+}
+
+// S3Options configures an S3 compatible bucket.
+type S3Options struct {
+	Bucket   string
+	Endpoint string
+	Region   string
+	Insecure bool
+	// AccessKey and SecretKey are the static credentials of the bucket.
+	// If not set, credentials are discovered from the environment, e.g. through IRSA.
+	AccessKey *corev1.SecretKeySelector
+	SecretKey *corev1.SecretKeySelector
+}
+
+// GCSOptions configures a Google Cloud Storage bucket, accessed with the application default credentials.
+type GCSOptions struct {
+	Bucket string
+}
+
+// AzureOptions configures an Azure Blob Storage container.
+type AzureOptions struct {
+	StorageAccount string
+	Container      string
+	Endpoint       string
+	// StorageAccountKey is the key of the storage account.
+	// If not set, credentials are discovered from the environment, e.g. through Azure Workload Identity.
+	StorageAccountKey *corev1.SecretKeySelector
+}
+
+type objStoreConfig struct {
+	Type   string         `yaml:"type"`
+	Config map[string]any `yaml:"config"`
+	Prefix string         `yaml:"prefix,omitempty"`
+}
+
+// objStoreRequiredFields are the fields of the configuration Thanos requires per object storage provider.
+// Providers without required fields, or whose requirements depend on the chosen authentication, map to nil.
+var objStoreRequiredFields = map[string][]string{
+	"S3":         {"bucket", "endpoint"},
+	"GCS":        {"bucket"},
+	"AZURE":      {"storage_account", "container"},
+	"SWIFT":      {"container_name"},
+	"COS":        {"bucket"},
+	"ALIYUNOSS":  {"endpoint", "bucket"},
+	"BOS":        {"bucket", "endpoint"},
+	"OCI":        {"bucket"},
+	"OBS":        {"bucket", "endpoint"},
+	"FILESYSTEM": {"directory"},
+}
+
+// ValidateObjectStorageConfig checks that the object storage configuration, as read by Thanos from a Secret,
+// parses and names a known provider with the fields Thanos requires for it.
+// It does not check that the bucket is reachable with the configuration.
+func ValidateObjectStorageConfig(data []byte) error {
+	var conf objStoreConfig
+	if err := yaml.UnmarshalStrict(data, &conf); err != nil {
+		return fmt.Errorf("failed to parse object storage configuration: %w", err)
+	}
+	if conf.Type == "" {
+		return errors.New("object storage type is not set")
+	}
+	required, ok := objStoreRequiredFields[strings.ToUpper(conf.Type)]
+	if !ok {
+		return fmt.Errorf("unknown object storage type %q", conf.Type)
+	}
+	var missing []string
+	for _, field := range required {
+		if v, ok := conf.Config[field]; !ok || v == nil || v == "" {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("object storage configuration of type %s is missing %s", strings.ToUpper(conf.Type), strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// BuildObjectStorageSecret creates a Secret holding the object storage configuration rendered from the options,
+// under the ObjectStorageConfigKey key.
+func BuildObjectStorageSecret(name, namespace string, objectMetaLabels map[string]string, opts ObjectStorageOptions) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    objectMetaLabels,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			ObjectStorageConfigKey: []byte(opts.toConfig()),
+		},
+	}
+}
+
+// EnvVars returns the environment variables holding the credentials referenced by the rendered configuration.
+func (opts ObjectStorageOptions) EnvVars() []corev1.EnvVar {
+	var env []corev1.EnvVar
+	switch {
+	case opts.S3 != nil && opts.S3.AccessKey != nil && opts.S3.SecretKey != nil:
+		env = append(env, secretEnvVar(objStoreAccessKeyEnvVarName, *opts.S3.AccessKey), secretEnvVar(objStoreSecretKeyEnvVarName, *opts.S3.SecretKey))
+	case opts.Azure != nil && opts.Azure.StorageAccountKey != nil:
+		env = append(env, secretEnvVar(objStoreAccountKeyEnvVarName, *opts.Azure.StorageAccountKey))
+	}
+	return env
+}
+
+func (opts ObjectStorageOptions) toConfig() string {
+	var conf objStoreConfig
+	switch {
+	case opts.S3 != nil:
+		conf = objStoreConfig{Type: "S3", Config: map[string]any{
+			"bucket":   opts.S3.Bucket,
+			"endpoint": opts.S3.Endpoint,
+			"insecure": opts.S3.Insecure,
+		}}
+		if opts.S3.Region != "" {
+			conf.Config["region"] = opts.S3.Region
+		}
+		if opts.S3.AccessKey != nil && opts.S3.SecretKey != nil {
+			conf.Config["access_key"] = fmt.Sprintf("$(%s)", objStoreAccessKeyEnvVarName)
+			conf.Config["secret_key"] = fmt.Sprintf("$(%s)", objStoreSecretKeyEnvVarName)
+		}
+	case opts.GCS != nil:
+		conf = objStoreConfig{Type: "GCS", Config: map[string]any{
+			"bucket": opts.GCS.Bucket,
+		}}
+	case opts.Azure != nil:
+		conf = objStoreConfig{Type: "AZURE", Config: map[string]any{
+			"storage_account": opts.Azure.StorageAccount,
+			"container":       opts.Azure.Container,
+		}}
+		if opts.Azure.Endpoint != "" {
+			conf.Config["endpoint"] = opts.Azure.Endpoint
+		}
+		if opts.Azure.StorageAccountKey != nil {
+			conf.Config["storage_account_key"] = fmt.Sprintf("$(%s)", objStoreAccountKeyEnvVarName)
+		}
+	default:
+		return ""
+	}
+
+	b, err := yaml.Marshal(conf)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func secretEnvVar(name string, selector corev1.SecretKeySelector) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: selector.LocalObjectReference,
+				Key:                  selector.Key,
+				Optional:             ptr.To(false),
+			},
+		},
+	}
+}
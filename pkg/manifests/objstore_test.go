@@ -0,0 +1,129 @@
+package manifests
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuildObjectStorageSecret(t *testing.T) {
+	accessKey := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s3"}, Key: "access-key"}
+	secretKey := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s3"}, Key: "secret-key"}
+
+	for _, tc := range []struct {
+		name    string
+		opts    ObjectStorageOptions
+		want    string
+		wantEnv []string
+	}{
+		{
+			name: "s3 with static credentials",
+			opts: ObjectStorageOptions{S3: &S3Options{
+				Bucket:    "thanos",
+				Endpoint:  "s3.eu-west-1.amazonaws.com",
+				Region:    "eu-west-1",
+				AccessKey: accessKey,
+				SecretKey: secretKey,
+			}},
+			want: `type: S3
+config:
+  access_key: $(OBJSTORE_ACCESS_KEY)
+  bucket: thanos
+  endpoint: s3.eu-west-1.amazonaws.com
+  insecure: false
+  region: eu-west-1
+  secret_key: $(OBJSTORE_SECRET_KEY)
+`,
+			wantEnv: []string{"OBJSTORE_ACCESS_KEY", "OBJSTORE_SECRET_KEY"},
+		},
+		{
+			name: "gcs",
+			opts: ObjectStorageOptions{GCS: &GCSOptions{Bucket: "thanos"}},
+			want: `type: GCS
+config:
+  bucket: thanos
+`,
+		},
+		{
+			name: "azure with workload identity",
+			opts: ObjectStorageOptions{Azure: &AzureOptions{StorageAccount: "account", Container: "thanos"}},
+			want: `type: AZURE
+config:
+  container: thanos
+  storage_account: account
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			secret := BuildObjectStorageSecret("objstore", "ns", nil, tc.opts)
+			if got := string(secret.Data[ObjectStorageConfigKey]); got != tc.want {
+				t.Errorf("expected config\n%s\ngot\n%s", tc.want, got)
+			}
+			env := tc.opts.EnvVars()
+			if len(env) != len(tc.wantEnv) {
+				t.Fatalf("expected env vars %v, got %v", tc.wantEnv, env)
+			}
+			for i, name := range tc.wantEnv {
+				if env[i].Name != name || env[i].ValueFrom.SecretKeyRef == nil {
+					t.Errorf("expected env var %s from a Secret, got %v", name, env[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateObjectStorageConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:   "s3",
+			config: "type: s3\nconfig:\n  bucket: thanos\n  endpoint: s3.eu-west-1.amazonaws.com\nprefix: tenant-a\n",
+		},
+		{
+			name:   "rendered by the operator",
+			config: ObjectStorageOptions{Azure: &AzureOptions{StorageAccount: "thanos", Container: "metrics"}}.toConfig(),
+		},
+		{
+			name:    "not yaml",
+			config:  "type: [S3",
+			wantErr: "failed to parse",
+		},
+		{
+			name:    "unknown field",
+			config:  "type: S3\nconfig:\n  bucket: thanos\n  endpoint: s3.amazonaws.com\nbucket: thanos\n",
+			wantErr: "failed to parse",
+		},
+		{
+			name:    "no type",
+			config:  "config:\n  bucket: thanos\n",
+			wantErr: "type is not set",
+		},
+		{
+			name:    "unknown type",
+			config:  "type: S4\nconfig:\n  bucket: thanos\n",
+			wantErr: `unknown object storage type "S4"`,
+		},
+		{
+			name:    "missing fields",
+			config:  "type: S3\nconfig:\n  bucket: thanos\n  endpoint: \"\"\n",
+			wantErr: "type S3 is missing endpoint",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateObjectStorageConfig([]byte(tc.config))
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
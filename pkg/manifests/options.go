@@ -0,0 +1,699 @@
+package manifests
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	DefaultThanosImage   = "quay.io/thanos/thanos"
+	DefaultThanosVersion = "v0.35.1"
+
+	// ThanosVersionAnnotation is set on workloads and holds the Thanos version they deploy.
+	// Unlike the image, which may be pinned to a digest, it allows to track the progress of upgrades.
+	ThanosVersionAnnotation = "operator.thanos.io/thanos-version"
+
+	defaultLogLevel  = "info"
+	defaultLogFormat = "logfmt"
+)
+
+var (
+	logLevels  = []string{"debug", "info", "warn", "error"}
+	logFormats = []string{"logfmt", "json"}
+)
+
+type Buildable interface {
+	Build() []client.Object
+	// GetGeneratedResourceName is the name of the objects that will be generated by BuildServiceMonitor.
+	// Implementations should use this value to populate the InstanceLabel after it has been
+	// run through ValidateAndSanitizeNameToValidLabelValue.
+	// In many cases, implementations will require just Name and Owner to generate this value.
+	// In some cases, particularly where sharded resources are built, an implementation may require additional
+	// information to generate this value. This should be documented in the implementation.
+	GetGeneratedResourceName() string
+	// GetSelectorLabels returns the labels that should be used to select the object
+	// Implementations must ensure that the following labels are set:
+	// - NameLabel
+	// - ComponentLabel
+	// - PartOfLabel
+	// - ManagedByLabel
+	// - InstanceLabel
+	// - OwnerLabel
+	GetSelectorLabels() map[string]string
+}
+
+// GetLabelSelectorForOwner is a convenience function to enable building a ListOption for the Owner label.
+// This strips out the instance label and returns a ListOption that can be used
+// to select resources that were built by Buildable and owned by a given object.
+func GetLabelSelectorForOwner(opts Buildable) client.ListOption {
+	if opts == nil {
+		return nil
+	}
+
+	selectorLabels := opts.GetSelectorLabels()
+	listOption := make(client.MatchingLabels, len(selectorLabels)-1)
+	for k, v := range selectorLabels {
+		if k != InstanceLabel {
+			listOption[k] = v
+		}
+	}
+	return listOption
+}
+
+// Options is a struct that holds the options for the common manifests
+type Options struct {
+	Additional
+	// Owner is the name of the owner of the object. This relates to the CustomResource or entity that created the object.
+	// This value will be used to populate the OwnerLabel after it has been run through ValidateAndSanitizeResourceName.
+	// This should be set to the name of the CustomResource that is creating the object and is a required field.
+	Owner string
+	// Namespace is the namespace for the object
+	Namespace string
+	// Replicas is the number of replicas for the object.
+	// Specific build functions may override this value.
+	Replicas int32
+	// Labels is the labels for the object
+	// Labels will be merged with the default labels for the component.
+	// The builders should ensure that the default labels are set on the object.
+	// The builders will overwrite the default labels if they are set in the Labels.
+	Labels map[string]string
+	// Annotations is the annotations for the object
+	Annotations map[string]string
+	// Image is the image to use for the component
+	// If not set, DefaultThanosImage will be used
+	Image *string
+	// Version is the version of Thanos
+	// If not set, DefaultThanosVersion will be used
+	Version *string
+	// ResourceRequirements for the component
+	ResourceRequirements *corev1.ResourceRequirements
+	// ContainerResources are the resource requirements of containers of the Pod, keyed by container name.
+	// They apply to all containers and init containers, including additional containers,
+	// and take precedence over ResourceRequirements.
+	ContainerResources map[string]corev1.ResourceRequirements
+	// ListenPorts overrides the default ports of the component.
+	// Builders must read ports with GetGRPCPort and GetHTTPPort.
+	ListenPorts *ListenPortOptions
+	// HTTPPortName overrides the name of the port of the HTTP server of the component, which serves its metrics.
+	// Builders must read the name with GetHTTPPortName.
+	HTTPPortName string
+	// LogLevel is the log level for the component
+	LogLevel *string
+	// LogFormat is the log format for the component
+	LogFormat *string
+	//ServiceMonitorConfig is the configuration for the ServiceMonitor
+	ServiceMonitorConfig ServiceMonitorConfig
+	// PodDisruptionConfig is the configuration for the PodDisruptionBudget
+	// If not set, the PodDisruptionBudget will not be created.
+	PodDisruptionConfig *PodDisruptionBudgetOptions
+	// Autoscaling is the configuration for the HorizontalPodAutoscaler of the Deployment of the component.
+	// If set, the Deployment is built without replicas, which are left to the HorizontalPodAutoscaler.
+	Autoscaling *HorizontalPodAutoscalerOptions
+	// ObjStoreTokenProjection projects a service account token used to authenticate to the object storage.
+	// If not set, no token is projected.
+	ObjStoreTokenProjection *TokenProjection
+	// Scheduling controls the nodes the pods of the component are scheduled on.
+	Scheduling *SchedulingOptions
+	// GRPCServerTLS enables TLS on the gRPC server of the component.
+	// Builders must add the flags returned by GRPCServerTLSFlags.
+	GRPCServerTLS *TLSOptions
+	// GRPCServer configures the gRPC server of the component.
+	// Builders must add the flags returned by GRPCServerFlags.
+	GRPCServer *GRPCServerOptions
+	// GRPCClientTLS enables TLS on the connections of the component to gRPC servers.
+	// Builders must add the flags returned by GRPCClientTLSFlags.
+	GRPCClientTLS *TLSOptions
+	// TerminationMessagePolicy overrides the termination message policy of the Thanos container set by the builder.
+	TerminationMessagePolicy corev1.TerminationMessagePolicy
+	// Probes tune the probes of the Thanos container set by the builder.
+	Probes *ProbeOptions
+	// PodSecurityContext overrides fields of the security context of the Pod set by the builder.
+	PodSecurityContext *corev1.PodSecurityContext
+	// SecurityContext overrides fields of the security context of the Thanos container set by the builder.
+	SecurityContext *corev1.SecurityContext
+	// ServiceAccount configures the ServiceAccount of the component.
+	// Builders must build the ServiceAccount with BuildServiceAccounts.
+	ServiceAccount *ServiceAccountOptions
+	// LogForwarding adds a sidecar forwarding the logs of the Thanos container to an HTTP endpoint.
+	// It is only supported for Deployments. Builders must build its ConfigMap with BuildLogForwardingConfigMap.
+	LogForwarding *LogForwardingOptions
+}
+
+// ValidateAndSanitizeResourceName sanitizes the provided name to a valid DNS-1123 subdomain.
+func ValidateAndSanitizeResourceName(name string) string {
+	if n := validation.IsDNS1123Subdomain(name); len(n) == 0 {
+		return name
+	}
+	// we can create a longer name here if we wish, it will be obfuscated at this point regardless
+	return sanitizeToLength(name, 63)
+}
+
+// ValidateAndSanitizeNameToValidLabelValue sanitizes the provided name to a valid label value.
+// The core of this function was copied from https://github.com/solo-io/k8s-utils
+func ValidateAndSanitizeNameToValidLabelValue(value string) string {
+	if l := validation.IsValidLabelValue(value); len(l) == 0 {
+		return value
+	}
+	return sanitizeToLength(value, 63)
+}
+
+func sanitizeToLength(value string, length int) string {
+	value = strings.Replace(value, "*", "-", -1)
+	value = strings.Replace(value, "/", "-", -1)
+	value = strings.Replace(value, ".", "-", -1)
+	value = strings.Replace(value, "[", "", -1)
+	value = strings.Replace(value, "]", "", -1)
+	value = strings.Replace(value, ":", "-", -1)
+	value = strings.Replace(value, "_", "-", -1)
+	value = strings.Replace(value, " ", "-", -1)
+	value = strings.Replace(value, "\n", "", -1)
+	value = strings.Replace(value, "\"", "", -1)
+	value = strings.Replace(value, "'", "", -1)
+	if len(value) > length {
+		hash := md5.Sum([]byte(value))
+		value = fmt.Sprintf("%s-%x", value[:31], hash)
+		value = value[:length]
+	}
+	value = strings.Replace(value, ".", "-", -1)
+	value = strings.ToLower(value)
+	return value
+}
+
+var badChars = []rune{
+	'.',
+	'_',
+}
+
+// SanitizeName sanitizes the name to make it clean for writing kubernetes objects
+// The core of this function was copied from https://github.com/solo-io/k8s-utils
+func SanitizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		for _, badChar := range badChars {
+			if r == badChar {
+				return '-'
+			}
+		}
+		return r
+	}, name)
+	if len(name) > 63 {
+		hash := md5.Sum([]byte(name))
+		name = fmt.Sprintf("%s-%x", name[:46], hash[:8])
+	}
+	return name
+}
+
+// ApplyDefaults returns a copy of the Options with the defaults set for the image, the version
+// and the logging configuration, if they are not set.
+// Controllers apply the defaults when building the Options, so that the defaults are the same for all components
+// and can be reported as they are deployed. The getters of the Options fall back to the same defaults.
+func (o Options) ApplyDefaults() Options {
+	if o.Image == nil || *o.Image == "" {
+		o.Image = ptr.To(DefaultThanosImage)
+	}
+	if o.Version == nil || *o.Version == "" {
+		o.Version = ptr.To(DefaultThanosVersion)
+	}
+	if o.LogLevel == nil || *o.LogLevel == "" {
+		o.LogLevel = ptr.To(defaultLogLevel)
+	}
+	if o.LogFormat == nil || *o.LogFormat == "" {
+		o.LogFormat = ptr.To(defaultLogFormat)
+	}
+	return o
+}
+
+// Validate returns an error if the Options can not be built into valid objects.
+// Unset fields which are defaulted by ApplyDefaults are valid.
+func (o Options) Validate() error {
+	var errs []error
+	if o.Owner == "" {
+		errs = append(errs, errors.New("owner must be set"))
+	}
+	if o.Replicas < 0 {
+		errs = append(errs, fmt.Errorf("replicas must not be negative, got %d", o.Replicas))
+	}
+	o = o.ApplyDefaults()
+	if !slices.Contains(logLevels, *o.LogLevel) {
+		errs = append(errs, fmt.Errorf("log level must be one of %s, got %q", strings.Join(logLevels, ", "), *o.LogLevel))
+	}
+	if !slices.Contains(logFormats, *o.LogFormat) {
+		errs = append(errs, fmt.Errorf("log format must be one of %s, got %q", strings.Join(logFormats, ", "), *o.LogFormat))
+	}
+	if o.ListenPorts != nil {
+		errs = append(errs, validateListenPort("gRPC", o.ListenPorts.GRPC), validateListenPort("HTTP", o.ListenPorts.HTTP))
+	}
+	errs = append(errs, o.Additional.Validate())
+	return errors.Join(errs...)
+}
+
+// validateListenPort returns an error if the given port is set and out of range.
+func validateListenPort(name string, port *int32) error {
+	if port != nil && (*port < 1 || *port > 65535) {
+		return fmt.Errorf("%s port must be between 1 and 65535, got %d", name, *port)
+	}
+	return nil
+}
+
+// ToFlags returns the flags for the Options
+func (o Options) ToFlags() []string {
+	o = o.ApplyDefaults()
+	return []string{
+		fmt.Sprintf("--log.level=%s", *o.LogLevel),
+		fmt.Sprintf("--log.format=%s", *o.LogFormat),
+	}
+}
+
+// GetContainerImage for the Options
+func (o Options) GetContainerImage() string {
+	o = o.ApplyDefaults()
+	return fmt.Sprintf("%s:%s", *o.Image, *o.Version)
+}
+
+// GetVersion returns the Thanos version of the Options, or DefaultThanosVersion if it is not set.
+func (o Options) GetVersion() string {
+	return *o.ApplyDefaults().Version
+}
+
+// GetDeploymentReplicas returns the replicas of the Deployment of the component,
+// or nil if they are managed by a HorizontalPodAutoscaler.
+func (o Options) GetDeploymentReplicas() *int32 {
+	if o.Autoscaling != nil {
+		return nil
+	}
+	return ptr.To(o.Replicas)
+}
+
+// GetWorkloadAnnotations returns the annotations of the workload of the component,
+// which include the Thanos version it deploys.
+func (o Options) GetWorkloadAnnotations() map[string]string {
+	annotations := make(map[string]string, len(o.Annotations)+1)
+	maps.Copy(annotations, o.Annotations)
+	annotations[ThanosVersionAnnotation] = o.GetVersion()
+	return annotations
+}
+
+// ListenPortOptions are the ports a component listens on.
+// A nil port leaves the default port of the component in place.
+type ListenPortOptions struct {
+	GRPC *int32
+	HTTP *int32
+}
+
+// GetGRPCPort returns the gRPC port of the component, or def if it is not overridden.
+func (o Options) GetGRPCPort(def int32) int32 {
+	if o.ListenPorts == nil || o.ListenPorts.GRPC == nil {
+		return def
+	}
+	return *o.ListenPorts.GRPC
+}
+
+// GetHTTPPort returns the HTTP port of the component, or def if it is not overridden.
+func (o Options) GetHTTPPort(def int32) int32 {
+	if o.ListenPorts == nil || o.ListenPorts.HTTP == nil {
+		return def
+	}
+	return *o.ListenPorts.HTTP
+}
+
+// GetHTTPPortName returns the name of the HTTP port of the component, or def if it is not overridden.
+func (o Options) GetHTTPPortName(def string) string {
+	if o.HTTPPortName == "" {
+		return def
+	}
+	return o.HTTPPortName
+}
+
+// AugmentWithOptions augments the object with the options.
+// Supported objects are Deployment, StatefulSet and Job.
+func AugmentWithOptions(obj client.Object, opts Options) {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		o.Spec.Template.Spec.Containers[0].Image = opts.GetContainerImage()
+
+		if opts.ResourceRequirements != nil {
+			o.Spec.Template.Spec.Containers[0].Resources = *opts.ResourceRequirements
+		}
+
+		if opts.Additional.VolumeMounts != nil {
+			o.Spec.Template.Spec.Containers[0].VolumeMounts = append(
+				o.Spec.Template.Spec.Containers[0].VolumeMounts,
+				opts.Additional.VolumeMounts...)
+		}
+
+		if opts.Additional.Containers != nil {
+			o.Spec.Template.Spec.Containers = append(
+				o.Spec.Template.Spec.Containers,
+				opts.Additional.Containers...)
+		}
+
+		if opts.Additional.Volumes != nil {
+			o.Spec.Template.Spec.Volumes = append(
+				o.Spec.Template.Spec.Volumes,
+				opts.Additional.Volumes...)
+		}
+
+		if opts.Additional.Ports != nil {
+			o.Spec.Template.Spec.Containers[0].Ports = append(
+				o.Spec.Template.Spec.Containers[0].Ports,
+				opts.Additional.Ports...)
+		}
+
+		if opts.Additional.Env != nil {
+			o.Spec.Template.Spec.Containers[0].Env = append(
+				o.Spec.Template.Spec.Containers[0].Env,
+				opts.Additional.Env...)
+		}
+
+		if opts.Additional.Mounts != nil {
+			addMounts(&o.Spec.Template.Spec, opts.Additional.Mounts)
+		}
+
+		if opts.ObjStoreTokenProjection != nil {
+			addTokenProjection(&o.Spec.Template.Spec, *opts.ObjStoreTokenProjection)
+		}
+
+		addTLSVolumes(&o.Spec.Template.Spec, grpcServerTLSName, opts.GRPCServerTLS)
+		addTLSVolumes(&o.Spec.Template.Spec, grpcClientTLSName, opts.GRPCClientTLS)
+
+		if opts.LogForwarding != nil {
+			addLogForwarding(&o.Spec.Template.Spec, o.GetName(), *opts.LogForwarding)
+		}
+
+		applyContainerResources(&o.Spec.Template.Spec, opts.ContainerResources)
+		applyTerminationMessagePolicy(&o.Spec.Template.Spec, opts.TerminationMessagePolicy)
+		applyProbes(&o.Spec.Template.Spec, opts.Probes, opts.GRPCServerTLS)
+		applySecurityContext(&o.Spec.Template.Spec, opts.PodSecurityContext, opts.SecurityContext)
+		applyServiceAccount(&o.Spec.Template.Spec, opts.ServiceAccount)
+		applyScheduling(&o.Spec.Template.Spec, opts.Scheduling, o.Spec.Selector)
+	case *appsv1.StatefulSet:
+		o.Spec.Template.Spec.Containers[0].Image = opts.GetContainerImage()
+
+		if opts.ResourceRequirements != nil {
+			o.Spec.Template.Spec.Containers[0].Resources = *opts.ResourceRequirements
+		}
+
+		if opts.Additional.VolumeMounts != nil {
+			o.Spec.Template.Spec.Containers[0].VolumeMounts = append(
+				o.Spec.Template.Spec.Containers[0].VolumeMounts,
+				opts.Additional.VolumeMounts...)
+		}
+
+		if opts.Additional.Containers != nil {
+			o.Spec.Template.Spec.Containers = append(
+				o.Spec.Template.Spec.Containers,
+				opts.Additional.Containers...)
+		}
+
+		if opts.Additional.Volumes != nil {
+			o.Spec.Template.Spec.Volumes = append(
+				o.Spec.Template.Spec.Volumes,
+				opts.Additional.Volumes...)
+		}
+
+		if opts.Additional.Ports != nil {
+			o.Spec.Template.Spec.Containers[0].Ports = append(
+				o.Spec.Template.Spec.Containers[0].Ports,
+				opts.Additional.Ports...)
+		}
+
+		if opts.Additional.Env != nil {
+			o.Spec.Template.Spec.Containers[0].Env = append(
+				o.Spec.Template.Spec.Containers[0].Env,
+				opts.Additional.Env...)
+		}
+
+		if opts.Additional.Mounts != nil {
+			addMounts(&o.Spec.Template.Spec, opts.Additional.Mounts)
+		}
+
+		if opts.ObjStoreTokenProjection != nil {
+			addTokenProjection(&o.Spec.Template.Spec, *opts.ObjStoreTokenProjection)
+		}
+
+		addTLSVolumes(&o.Spec.Template.Spec, grpcServerTLSName, opts.GRPCServerTLS)
+		addTLSVolumes(&o.Spec.Template.Spec, grpcClientTLSName, opts.GRPCClientTLS)
+
+		applyContainerResources(&o.Spec.Template.Spec, opts.ContainerResources)
+		applyTerminationMessagePolicy(&o.Spec.Template.Spec, opts.TerminationMessagePolicy)
+		applyProbes(&o.Spec.Template.Spec, opts.Probes, opts.GRPCServerTLS)
+		applySecurityContext(&o.Spec.Template.Spec, opts.PodSecurityContext, opts.SecurityContext)
+		applyServiceAccount(&o.Spec.Template.Spec, opts.ServiceAccount)
+		applyScheduling(&o.Spec.Template.Spec, opts.Scheduling, o.Spec.Selector)
+	case *batchv1.Job:
+		o.Spec.Template.Spec.Containers[0].Image = opts.GetContainerImage()
+
+		if opts.ResourceRequirements != nil {
+			o.Spec.Template.Spec.Containers[0].Resources = *opts.ResourceRequirements
+		}
+
+		if opts.Additional.VolumeMounts != nil {
+			o.Spec.Template.Spec.Containers[0].VolumeMounts = append(
+				o.Spec.Template.Spec.Containers[0].VolumeMounts,
+				opts.Additional.VolumeMounts...)
+		}
+
+		if opts.Additional.Containers != nil {
+			o.Spec.Template.Spec.Containers = append(
+				o.Spec.Template.Spec.Containers,
+				opts.Additional.Containers...)
+		}
+
+		if opts.Additional.Volumes != nil {
+			o.Spec.Template.Spec.Volumes = append(
+				o.Spec.Template.Spec.Volumes,
+				opts.Additional.Volumes...)
+		}
+
+		if opts.Additional.Env != nil {
+			o.Spec.Template.Spec.Containers[0].Env = append(
+				o.Spec.Template.Spec.Containers[0].Env,
+				opts.Additional.Env...)
+		}
+
+		if opts.Additional.Mounts != nil {
+			addMounts(&o.Spec.Template.Spec, opts.Additional.Mounts)
+		}
+
+		if opts.ObjStoreTokenProjection != nil {
+			addTokenProjection(&o.Spec.Template.Spec, *opts.ObjStoreTokenProjection)
+		}
+
+		addTLSVolumes(&o.Spec.Template.Spec, grpcServerTLSName, opts.GRPCServerTLS)
+		addTLSVolumes(&o.Spec.Template.Spec, grpcClientTLSName, opts.GRPCClientTLS)
+
+		applyContainerResources(&o.Spec.Template.Spec, opts.ContainerResources)
+		applyTerminationMessagePolicy(&o.Spec.Template.Spec, opts.TerminationMessagePolicy)
+		applySecurityContext(&o.Spec.Template.Spec, opts.PodSecurityContext, opts.SecurityContext)
+		applyServiceAccount(&o.Spec.Template.Spec, opts.ServiceAccount)
+		// the selector of a Job is generated by the API server, its pods are selected by their labels
+		applyScheduling(&o.Spec.Template.Spec, opts.Scheduling, &metav1.LabelSelector{MatchLabels: o.Spec.Template.Labels})
+	default:
+		//no-op
+	}
+}
+
+// applyContainerResources sets the resource requirements of the containers and init containers of the Pod by name.
+func applyContainerResources(spec *corev1.PodSpec, resources map[string]corev1.ResourceRequirements) {
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			if r, ok := resources[containers[i].Name]; ok {
+				containers[i].Resources = r
+			}
+		}
+	}
+}
+
+// applyTerminationMessagePolicy sets the termination message policy of the Thanos container of the Pod, if set.
+func applyTerminationMessagePolicy(spec *corev1.PodSpec, policy corev1.TerminationMessagePolicy) {
+	if policy != "" {
+		spec.Containers[0].TerminationMessagePolicy = policy
+	}
+}
+
+type Additional struct {
+	// Additional arguments to pass to the Thanos components.
+	Args []string
+	// Additional containers to add to the Thanos components.
+	Containers []corev1.Container
+	// Additional volumes to add to the Thanos components.
+	Volumes []corev1.Volume
+	// Additional volume mounts to add to the Thanos component container in a Deployment or StatefulSet
+	// controlled by the operator.
+	VolumeMounts []corev1.VolumeMount
+	// Additional ports to expose on the Thanos component container in a Deployment or StatefulSet
+	// controlled by the operator.
+	Ports []corev1.ContainerPort
+	// Additional environment variables to add to the Thanos component container in a Deployment or StatefulSet
+	// controlled by the operator.
+	Env []corev1.EnvVar
+	// AdditionalServicePorts are additional ports to expose on the Services for the Thanos component.
+	// See AdditionalServicePorts.
+	ServicePorts []corev1.ServicePort
+	// Mounts are ConfigMaps and Secrets to mount into the Thanos component container in a Deployment or StatefulSet
+	// controlled by the operator. See Additional.Validate.
+	Mounts []Mount
+}
+
+// RelabelConfig is a struct that holds the relabel configuration
+type RelabelConfig struct {
+	Action      string
+	SourceLabel string
+	TargetLabel string
+	// Modulus is relevant for the hashmod action.
+	Modulus int
+	// Regex is relevant for non-hashmod actions.
+	Regex string
+}
+
+// RelabelConfigs is a slice of RelabelConfig
+type RelabelConfigs []RelabelConfig
+
+// String returns the string representation of the RelabelConfig
+func (r RelabelConfig) String() string {
+	if r.Action == "hashmod" {
+		return fmt.Sprintf(`
+- action: hashmod
+  source_labels: ["%s"]
+  target_label: %s
+  modulus: %d`, r.SourceLabel, r.TargetLabel, r.Modulus)
+	}
+
+	if r.TargetLabel == "" {
+		return fmt.Sprintf(`
+- action: %s
+  source_labels: ["%s"]
+  regex: %s`, r.Action, r.SourceLabel, r.Regex)
+	}
+
+	return fmt.Sprintf(`
+- action: %s
+  source_labels: ["%s"]
+  target_label: %s
+  regex: %s`, r.Action, r.SourceLabel, r.TargetLabel, r.Regex)
+}
+
+// String returns the string representation of the RelabelConfigs
+func (rc RelabelConfigs) String() string {
+	var result string
+	for _, r := range rc {
+		result += r.String()
+	}
+	return result
+}
+
+// ToFlags returns the flags for the RelabelConfigs
+func (rc RelabelConfigs) ToFlags() string {
+	return fmt.Sprintf("--selector.relabel-config=%s", rc.String())
+}
+
+type Duration string
+
+type CacheConfig struct {
+	InMemoryCacheConfig *InMemoryCacheConfig
+	FromSecret          *corev1.SecretKeySelector
+	// Redis is the configuration of a Redis cache.
+	// It takes precedence over Memcached and InMemoryCacheConfig, FromSecret takes precedence over it.
+	Redis *RedisCacheConfig
+	// Memcached is the configuration of a Memcached cache.
+	// It takes precedence over InMemoryCacheConfig.
+	Memcached *MemcachedCacheConfig
+}
+
+type InMemoryCacheConfig struct {
+	MaxSize     string
+	MaxItemSize string
+}
+
+func (ic InMemoryCacheConfig) String() string {
+	base := `type: IN-MEMORY
+config:
+`
+	if ic.MaxSize != "" {
+		base += fmt.Sprintf("  max_size: %s\n", ic.MaxSize)
+	}
+	if ic.MaxItemSize != "" {
+		base += fmt.Sprintf("  max_item_size: %s\n", ic.MaxItemSize)
+	}
+	return base
+}
+
+// RequestLoggingConfig is the configuration for request logging.
+type RequestLoggingConfig struct {
+	HTTP *RequestLoggingOptions
+	GRPC *RequestLoggingOptions
+}
+
+// RequestLoggingOptions holds the level and the phases of a request that are logged.
+type RequestLoggingOptions struct {
+	Level    string
+	LogStart bool
+	LogEnd   bool
+}
+
+type requestLoggingDecision struct {
+	LogStart bool `yaml:"log_start"`
+	LogEnd   bool `yaml:"log_end"`
+}
+
+type requestLoggingOptions struct {
+	Level    string                 `yaml:"level,omitempty"`
+	Decision requestLoggingDecision `yaml:"decision"`
+}
+
+type requestLoggingProtocol struct {
+	Options requestLoggingOptions `yaml:"options"`
+}
+
+type requestLoggingConfig struct {
+	HTTP *requestLoggingProtocol `yaml:"http,omitempty"`
+	GRPC *requestLoggingProtocol `yaml:"grpc,omitempty"`
+}
+
+func toRequestLoggingProtocol(from *RequestLoggingOptions) *requestLoggingProtocol {
+	if from == nil {
+		return nil
+	}
+	return &requestLoggingProtocol{
+		Options: requestLoggingOptions{
+			Level: from.Level,
+			Decision: requestLoggingDecision{
+				LogStart: from.LogStart,
+				LogEnd:   from.LogEnd,
+			},
+		},
+	}
+}
+
+// String returns the YAML representation of the RequestLoggingConfig as expected by Thanos.
+func (rc RequestLoggingConfig) String() string {
+	content, err := yaml.Marshal(requestLoggingConfig{
+		HTTP: toRequestLoggingProtocol(rc.HTTP),
+		GRPC: toRequestLoggingProtocol(rc.GRPC),
+	})
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// ToFlags returns the flags for the RequestLoggingConfig
+func (rc RequestLoggingConfig) ToFlags() string {
+	if rc.HTTP == nil && rc.GRPC == nil {
+		return ""
+	}
+	return fmt.Sprintf("--request.logging-config=%s", rc.String())
+}
@@ -0,0 +1,453 @@
+package manifests
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func TestOptions_GetContainerImage(t *testing.T) {
+	tests := []struct {
+		name string
+		o    Options
+		want string
+	}{
+		{
+			name: "get default image",
+			o:    Options{},
+			want: DefaultThanosImage + ":" + DefaultThanosVersion,
+		},
+		{
+			name: "get custom image from options",
+			o: Options{
+				Image:   ptr.To("quay.io/thanos/thanos"),
+				Version: ptr.To("latest"),
+			},
+			want: "quay.io/thanos/thanos:latest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.o.GetContainerImage(); got != tt.want {
+				t.Errorf("Options.GetContainerImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptions_ToFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		o    Options
+		want []string
+	}{
+		{
+			name: "get default flags",
+			o:    Options{},
+			want: []string{
+				fmt.Sprintf("--log.level=%s", defaultLogLevel),
+				fmt.Sprintf("--log.format=%s", defaultLogFormat),
+			},
+		},
+		{
+			name: "get custom flags",
+			o: Options{
+				LogLevel:  ptr.To("debug"),
+				LogFormat: ptr.To("json"),
+			},
+			want: []string{
+				"--log.level=debug",
+				"--log.format=json",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.o.ToFlags(), tt.want) {
+				t.Errorf("Options.ToFlags() = %v, want %v", tt.o.ToFlags(), tt.want)
+			}
+		})
+	}
+}
+
+func TestOptions_ApplyDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		o    Options
+		want Options
+	}{
+		{
+			name: "set defaults of unset fields",
+			o:    Options{Owner: "test"},
+			want: Options{
+				Owner:     "test",
+				Image:     ptr.To(DefaultThanosImage),
+				Version:   ptr.To(DefaultThanosVersion),
+				LogLevel:  ptr.To(defaultLogLevel),
+				LogFormat: ptr.To(defaultLogFormat),
+			},
+		},
+		{
+			name: "set defaults of empty fields",
+			o:    Options{Image: ptr.To(""), Version: ptr.To(""), LogLevel: ptr.To(""), LogFormat: ptr.To("")},
+			want: Options{
+				Image:     ptr.To(DefaultThanosImage),
+				Version:   ptr.To(DefaultThanosVersion),
+				LogLevel:  ptr.To(defaultLogLevel),
+				LogFormat: ptr.To(defaultLogFormat),
+			},
+		},
+		{
+			name: "keep set fields",
+			o: Options{
+				Image:     ptr.To("example.com/thanos"),
+				Version:   ptr.To("v0.36.0"),
+				LogLevel:  ptr.To("debug"),
+				LogFormat: ptr.To("json"),
+			},
+			want: Options{
+				Image:     ptr.To("example.com/thanos"),
+				Version:   ptr.To("v0.36.0"),
+				LogLevel:  ptr.To("debug"),
+				LogFormat: ptr.To("json"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.o.ApplyDefaults()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Options.ApplyDefaults() = %+v, want %+v", got, tt.want)
+			}
+			// the getters must agree with the defaults
+			if got.GetContainerImage() != tt.o.GetContainerImage() {
+				t.Errorf("GetContainerImage() = %q after defaulting, want %q", got.GetContainerImage(), tt.o.GetContainerImage())
+			}
+			if !reflect.DeepEqual(got.ToFlags(), tt.o.ToFlags()) {
+				t.Errorf("ToFlags() = %v after defaulting, want %v", got.ToFlags(), tt.o.ToFlags())
+			}
+		})
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		o       Options
+		wantErr bool
+	}{
+		{
+			name: "valid with defaults",
+			o:    Options{Owner: "test"},
+		},
+		{
+			name: "valid",
+			o: Options{
+				Owner:       "test",
+				Replicas:    3,
+				LogLevel:    ptr.To("warn"),
+				LogFormat:   ptr.To("json"),
+				ListenPorts: &ListenPortOptions{GRPC: ptr.To(int32(10901)), HTTP: ptr.To(int32(10902))},
+			},
+		},
+		{
+			name:    "missing owner",
+			o:       Options{},
+			wantErr: true,
+		},
+		{
+			name:    "negative replicas",
+			o:       Options{Owner: "test", Replicas: -1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid log level",
+			o:       Options{Owner: "test", LogLevel: ptr.To("verbose")},
+			wantErr: true,
+		},
+		{
+			name:    "invalid log format",
+			o:       Options{Owner: "test", LogFormat: ptr.To("text")},
+			wantErr: true,
+		},
+		{
+			name:    "port out of range",
+			o:       Options{Owner: "test", ListenPorts: &ListenPortOptions{HTTP: ptr.To(int32(70000))}},
+			wantErr: true,
+		},
+		{
+			name: "invalid additional configuration",
+			o: Options{Owner: "test", Additional: Additional{
+				Mounts: []Mount{{Name: "config", MountPath: "/etc/config"}},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRelabelConfig_String(t *testing.T) {
+	tests := []struct {
+		name string
+		r    RelabelConfig
+		want string
+	}{
+		{
+			name: "get value for hashmod",
+			r: RelabelConfig{
+				SourceLabel: "any",
+				TargetLabel: "some_target",
+				Modulus:     1,
+				Action:      "hashmod",
+			},
+			want: `
+- action: hashmod
+  source_labels: ["any"]
+  target_label: some_target
+  modulus: 1`,
+		},
+		{
+			name: "get value for keep",
+			r: RelabelConfig{
+				SourceLabel: "any",
+				TargetLabel: "some_target",
+				Regex:       "^test",
+				Action:      "keep",
+			},
+			want: `
+- action: keep
+  source_labels: ["any"]
+  target_label: some_target
+  regex: ^test`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.String(); got != tt.want {
+				t.Errorf("RelabelConfig.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelabelConfigs_ToFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		rc   RelabelConfigs
+		want string
+	}{
+		{
+			name: "get flags for relabel configs",
+			rc: RelabelConfigs{
+				{
+					SourceLabel: "any",
+					TargetLabel: "some_target",
+					Modulus:     1,
+					Action:      "hashmod",
+				},
+				{
+					SourceLabel: "any",
+					TargetLabel: "some_target",
+					Regex:       "^test",
+					Action:      "keep",
+				},
+			},
+			want: "--selector.relabel-config=" + `
+- action: hashmod
+  source_labels: ["any"]
+  target_label: some_target
+  modulus: 1
+- action: keep
+  source_labels: ["any"]
+  target_label: some_target
+  regex: ^test`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rc.ToFlags(); got != tt.want {
+				t.Errorf("RelabelConfigs.ToFlags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInMemoryCacheConfig_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   InMemoryCacheConfig
+		expected string
+	}{
+		{
+			name:   "EmptyConfig",
+			config: InMemoryCacheConfig{},
+			expected: `type: IN-MEMORY
+config:
+`,
+		},
+		{
+			name:   "WithMaxSize",
+			config: InMemoryCacheConfig{MaxSize: "100MB"},
+			expected: `type: IN-MEMORY
+config:
+  max_size: 100MB
+`,
+		},
+		{
+			name:   "WithMaxItemSize",
+			config: InMemoryCacheConfig{MaxItemSize: "10MB"},
+			expected: `type: IN-MEMORY
+config:
+  max_item_size: 10MB
+`,
+		},
+		{
+			name:   "WithMaxSizeAndMaxItemSize",
+			config: InMemoryCacheConfig{MaxSize: "100MB", MaxItemSize: "10MB"},
+			expected: `type: IN-MEMORY
+config:
+  max_size: 100MB
+  max_item_size: 10MB
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.String(); got != tt.expected {
+				t.Errorf("InMemoryCacheConfig.String() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRequestLoggingConfig_ToFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		rc   RequestLoggingConfig
+		want string
+	}{
+		{
+			name: "empty config",
+			rc:   RequestLoggingConfig{},
+			want: "",
+		},
+		{
+			name: "http only",
+			rc: RequestLoggingConfig{
+				HTTP: &RequestLoggingOptions{Level: "INFO", LogEnd: true},
+			},
+			want: `--request.logging-config=http:
+  options:
+    level: INFO
+    decision:
+      log_start: false
+      log_end: true
+`,
+		},
+		{
+			name: "http and grpc",
+			rc: RequestLoggingConfig{
+				HTTP: &RequestLoggingOptions{Level: "DEBUG", LogStart: true, LogEnd: true},
+				GRPC: &RequestLoggingOptions{Level: "ERROR", LogEnd: true},
+			},
+			want: `--request.logging-config=http:
+  options:
+    level: DEBUG
+    decision:
+      log_start: true
+      log_end: true
+grpc:
+  options:
+    level: ERROR
+    decision:
+      log_start: false
+      log_end: true
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rc.ToFlags(); got != tt.want {
+				t.Errorf("RequestLoggingConfig.ToFlags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAugmentWithOptions_ContainerResources(t *testing.T) {
+	defaultResources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+	}
+	sidecarResources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+	}
+	initResources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+	}
+
+	sts := &appsv1.StatefulSet{}
+	sts.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "init"}}
+	sts.Spec.Template.Spec.Containers = []corev1.Container{{Name: "thanos"}}
+
+	AugmentWithOptions(sts, Options{
+		ResourceRequirements: &defaultResources,
+		ContainerResources: map[string]corev1.ResourceRequirements{
+			"sidecar": sidecarResources,
+			"init":    initResources,
+		},
+		Additional: Additional{
+			Containers: []corev1.Container{{Name: "sidecar"}},
+		},
+	})
+
+	podSpec := sts.Spec.Template.Spec
+	if !reflect.DeepEqual(podSpec.Containers[0].Resources, defaultResources) {
+		t.Errorf("expected main container to keep default resources, got %v", podSpec.Containers[0].Resources)
+	}
+	if !reflect.DeepEqual(podSpec.Containers[1].Resources, sidecarResources) {
+		t.Errorf("expected sidecar resources to be set, got %v", podSpec.Containers[1].Resources)
+	}
+	if !reflect.DeepEqual(podSpec.InitContainers[0].Resources, initResources) {
+		t.Errorf("expected init container resources to be set, got %v", podSpec.InitContainers[0].Resources)
+	}
+}
+
+func TestAugmentWithOptions_TerminationMessagePolicy(t *testing.T) {
+	newDeployment := func() *appsv1.Deployment {
+		d := &appsv1.Deployment{}
+		d.Spec.Template.Spec.Containers = []corev1.Container{
+			{Name: "thanos", TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError},
+		}
+		return d
+	}
+
+	d := newDeployment()
+	AugmentWithOptions(d, Options{})
+	if got := d.Spec.Template.Spec.Containers[0].TerminationMessagePolicy; got != corev1.TerminationMessageFallbackToLogsOnError {
+		t.Errorf("expected policy of the builder to be kept, got %s", got)
+	}
+
+	d = newDeployment()
+	AugmentWithOptions(d, Options{
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		Additional:               Additional{Containers: []corev1.Container{{Name: "sidecar"}}},
+	})
+	if got := d.Spec.Template.Spec.Containers[0].TerminationMessagePolicy; got != corev1.TerminationMessageReadFile {
+		t.Errorf("expected policy to be overridden, got %s", got)
+	}
+	if got := d.Spec.Template.Spec.Containers[1].TerminationMessagePolicy; got != "" {
+		t.Errorf("expected additional container to be left untouched, got %s", got)
+	}
+}
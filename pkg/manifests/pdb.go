@@ -10,7 +10,7 @@ import (
 // PodDisruptionBudgetOptions defines the available options for creating a PodDisruptionBudget object.
 type PodDisruptionBudgetOptions struct {
 	// MaxUnavailable is the maximum number of pods that can be unavailable during the disruption.
-	// Defaults to 1 if not specified.
+	// Defaults to 1 if neither MaxUnavailable nor MinAvailable is specified.
 	MaxUnavailable *int32
 	// MinAvailable is the minimum number of pods that must still be available during the disruption.
 	// Defaults to nil if not specified.
@@ -20,7 +20,7 @@ type PodDisruptionBudgetOptions struct {
 // NewPodDisruptionBudget creates a new PodDisruptionBudget object.
 // It sets the object name, namespace, selector labels, object meta labels, and maxUnavailable.
 // The maxUnavailable is a pointer to an int32 value.
-// If the maxUnavailable is nil, it defaults to 1.
+// If both the maxUnavailable and minAvailable are nil, maxUnavailable defaults to 1.
 func NewPodDisruptionBudget(name, namespace string, selectorLabels, objectMetaLabels, annotations map[string]string, opts PodDisruptionBudgetOptions) *policyv1.PodDisruptionBudget {
 	minValue, maxValue := opts.getMinAndMax()
 	return &policyv1.PodDisruptionBudget{
@@ -46,8 +46,9 @@ func NewPodDisruptionBudget(name, namespace string, selectorLabels, objectMetaLa
 
 func (opts PodDisruptionBudgetOptions) getMinAndMax() (min *intstr.IntOrString, max *intstr.IntOrString) {
 	if opts.MinAvailable != nil {
+		// a PodDisruptionBudget may only set one of minAvailable and maxUnavailable
 		minValue := intstr.FromInt32(*opts.MinAvailable)
-		min = &minValue
+		return &minValue, nil
 	}
 
 	if opts.MaxUnavailable == nil {
@@ -2,6 +2,8 @@ package manifests
 
 import (
 	"testing"
+
+	"k8s.io/utils/ptr"
 )
 
 func TestNewPodDisruptionBudget(t *testing.T) {
@@ -44,3 +46,14 @@ func TestNewPodDisruptionBudget(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPodDisruptionBudgetMinAvailable(t *testing.T) {
+	pdb := NewPodDisruptionBudget("test-name", "test-namespace", nil, nil, nil, PodDisruptionBudgetOptions{MinAvailable: ptr.To(int32(2))})
+	if pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.IntVal != 2 {
+		t.Errorf("pdb.Spec.MinAvailable = %v, want %v", pdb.Spec.MinAvailable, 2)
+	}
+	// only one of minAvailable and maxUnavailable may be set
+	if pdb.Spec.MaxUnavailable != nil {
+		t.Errorf("pdb.Spec.MaxUnavailable = %v, want nil", pdb.Spec.MaxUnavailable)
+	}
+}
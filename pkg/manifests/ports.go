@@ -0,0 +1,129 @@
+package manifests
+
+import (
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AdditionalServicePorts returns the ports to add to the Services of the Thanos component.
+// These are the additional ServicePorts, followed by a ServicePort targeting each additional container Port
+// by name, unless a ServicePort with the same name is already given.
+func AdditionalServicePorts(a Additional) []corev1.ServicePort {
+	if len(a.ServicePorts) == 0 && len(a.Ports) == 0 {
+		return nil
+	}
+	ports := make([]corev1.ServicePort, 0, len(a.ServicePorts)+len(a.Ports))
+	names := make(map[string]struct{}, len(a.ServicePorts))
+	for _, sp := range a.ServicePorts {
+		ports = append(ports, sp)
+		names[sp.Name] = struct{}{}
+	}
+	for _, p := range a.Ports {
+		if _, ok := names[p.Name]; ok {
+			continue
+		}
+		ports = append(ports, corev1.ServicePort{
+			Name:       p.Name,
+			Port:       p.ContainerPort,
+			TargetPort: intstr.FromString(p.Name),
+			Protocol:   p.Protocol,
+		})
+	}
+	return ports
+}
+
+// validatePorts returns an error if the additional Ports or ServicePorts are unnamed or clash with each other.
+// Names are required so that the ports can be exposed on Services and referenced by probes and discovery.
+func (a Additional) validatePorts() error {
+	var errs []error
+	names := make(map[string]struct{}, len(a.Ports))
+	numbers := make(map[int32]struct{}, len(a.Ports))
+	for _, p := range a.Ports {
+		if msgs := validation.IsValidPortName(p.Name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("additional port %d: invalid name %q: %s", p.ContainerPort, p.Name, msgs[0]))
+		}
+		if _, ok := names[p.Name]; ok {
+			errs = append(errs, fmt.Errorf("additional port %s: name is already in use", p.Name))
+		}
+		names[p.Name] = struct{}{}
+		if _, ok := numbers[p.ContainerPort]; ok {
+			errs = append(errs, fmt.Errorf("additional port %s: port %d is already in use", p.Name, p.ContainerPort))
+		}
+		numbers[p.ContainerPort] = struct{}{}
+	}
+
+	serviceNames := make(map[string]struct{}, len(a.ServicePorts))
+	serviceNumbers := make(map[int32]struct{}, len(a.ServicePorts))
+	for _, sp := range a.ServicePorts {
+		if msgs := validation.IsValidPortName(sp.Name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("additional service port %d: invalid name %q: %s", sp.Port, sp.Name, msgs[0]))
+		}
+		if _, ok := serviceNames[sp.Name]; ok {
+			errs = append(errs, fmt.Errorf("additional service port %s: name is already in use", sp.Name))
+		}
+		serviceNames[sp.Name] = struct{}{}
+		if _, ok := serviceNumbers[sp.Port]; ok {
+			errs = append(errs, fmt.Errorf("additional service port %s: port %d is already in use", sp.Name, sp.Port))
+		}
+		serviceNumbers[sp.Port] = struct{}{}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidatePorts returns an error if the ports of the Thanos component container of a Deployment or StatefulSet,
+// or the ports of a Service, share a name or number.
+// This catches additional ports which collide with the ports managed by the operator.
+// Other objects are always valid.
+func ValidatePorts(obj client.Object) error {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return validateContainerPorts(o.Spec.Template.Spec.Containers)
+	case *appsv1.StatefulSet:
+		return validateContainerPorts(o.Spec.Template.Spec.Containers)
+	case *corev1.Service:
+		var errs []error
+		names := make(map[string]struct{}, len(o.Spec.Ports))
+		numbers := make(map[int32]struct{}, len(o.Spec.Ports))
+		for _, sp := range o.Spec.Ports {
+			if _, ok := names[sp.Name]; ok {
+				errs = append(errs, fmt.Errorf("service port name %s is used more than once", sp.Name))
+			}
+			names[sp.Name] = struct{}{}
+			if _, ok := numbers[sp.Port]; ok {
+				errs = append(errs, fmt.Errorf("service port %d is used more than once", sp.Port))
+			}
+			numbers[sp.Port] = struct{}{}
+		}
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func validateContainerPorts(containers []corev1.Container) error {
+	if len(containers) == 0 {
+		return nil
+	}
+	var errs []error
+	ports := containers[0].Ports
+	names := make(map[string]struct{}, len(ports))
+	numbers := make(map[int32]struct{}, len(ports))
+	for _, p := range ports {
+		if p.Name != "" {
+			if _, ok := names[p.Name]; ok {
+				errs = append(errs, fmt.Errorf("container port name %s is used more than once", p.Name))
+			}
+			names[p.Name] = struct{}{}
+		}
+		if _, ok := numbers[p.ContainerPort]; ok {
+			errs = append(errs, fmt.Errorf("container port %d is used more than once", p.ContainerPort))
+		}
+		numbers[p.ContainerPort] = struct{}{}
+	}
+	return errors.Join(errs...)
+}
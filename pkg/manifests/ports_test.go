@@ -0,0 +1,116 @@
+package manifests
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func TestAdditionalServicePorts(t *testing.T) {
+	additional := Additional{
+		Ports: []corev1.ContainerPort{
+			{Name: "profiling", ContainerPort: 6060},
+			{Name: "otlp", ContainerPort: 4317, Protocol: corev1.ProtocolTCP},
+		},
+		ServicePorts: []corev1.ServicePort{
+			{Name: "otlp", Port: 4317, TargetPort: intstr.FromString("otlp"), AppProtocol: ptr.To("grpc")},
+		},
+	}
+
+	want := []corev1.ServicePort{
+		{Name: "otlp", Port: 4317, TargetPort: intstr.FromString("otlp"), AppProtocol: ptr.To("grpc")},
+		{Name: "profiling", Port: 6060, TargetPort: intstr.FromString("profiling")},
+	}
+	if got := AdditionalServicePorts(additional); !reflect.DeepEqual(got, want) {
+		t.Errorf("AdditionalServicePorts() = %v, want %v", got, want)
+	}
+	if got := AdditionalServicePorts(Additional{}); got != nil {
+		t.Errorf("AdditionalServicePorts() = %v, want nil", got)
+	}
+}
+
+func TestAdditional_ValidatePorts(t *testing.T) {
+	tests := []struct {
+		name       string
+		additional Additional
+		wantErr    bool
+	}{
+		{
+			name: "valid ports",
+			additional: Additional{
+				Ports:        []corev1.ContainerPort{{Name: "profiling", ContainerPort: 6060}},
+				ServicePorts: []corev1.ServicePort{{Name: "profiling", Port: 6060}},
+			},
+		},
+		{
+			name: "unnamed port",
+			additional: Additional{
+				Ports: []corev1.ContainerPort{{ContainerPort: 6060}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unnamed service port",
+			additional: Additional{
+				ServicePorts: []corev1.ServicePort{{Port: 6060}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate port name",
+			additional: Additional{
+				Ports: []corev1.ContainerPort{{Name: "profiling", ContainerPort: 6060}, {Name: "profiling", ContainerPort: 6061}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate service port number",
+			additional: Additional{
+				ServicePorts: []corev1.ServicePort{{Name: "a", Port: 6060}, {Name: "b", Port: 6060}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.additional.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePorts(t *testing.T) {
+	withPorts := func(ports ...corev1.ContainerPort) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "thanos", Ports: ports}},
+		}}}}
+	}
+
+	if err := ValidatePorts(withPorts(
+		corev1.ContainerPort{Name: "grpc", ContainerPort: 10901},
+		corev1.ContainerPort{Name: "http", ContainerPort: 10902},
+		corev1.ContainerPort{Name: "profiling", ContainerPort: 6060},
+	)); err != nil {
+		t.Errorf("ValidatePorts() unexpected error = %v", err)
+	}
+	if err := ValidatePorts(withPorts(
+		corev1.ContainerPort{Name: "grpc", ContainerPort: 10901},
+		corev1.ContainerPort{Name: "grpc", ContainerPort: 6060},
+	)); err == nil {
+		t.Error("ValidatePorts() expected error for colliding container port name")
+	}
+	if err := ValidatePorts(&corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+		{Name: "http", Port: 10902},
+		{Name: "metrics", Port: 10902},
+	}}}); err == nil {
+		t.Error("ValidatePorts() expected error for colliding service port number")
+	}
+	if err := ValidatePorts(&corev1.ConfigMap{}); err != nil {
+		t.Errorf("ValidatePorts() unexpected error = %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package manifests
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// grpcPortName is the name of the container port of the gRPC server of the Thanos components.
+const grpcPortName = "grpc"
+
+// ProbeOptions tune the probes of the Thanos container set by the builder.
+type ProbeOptions struct {
+	// Liveness overrides the settings of the liveness probe.
+	Liveness *ProbeSettings
+	// Readiness overrides the settings of the readiness probe.
+	Readiness *ProbeSettings
+	// Startup adds a startup probe with the handler of the readiness probe, if set.
+	Startup *ProbeSettings
+	// GRPC replaces the handler of the readiness and startup probes with a gRPC health check
+	// against the gRPC port of the container. It is ignored if the container has no gRPC port.
+	GRPC bool
+}
+
+// ProbeSettings are the timing and threshold settings of a probe.
+// Settings which are not set keep the value set by the builder.
+type ProbeSettings struct {
+	InitialDelaySeconds *int32
+	TimeoutSeconds      *int32
+	PeriodSeconds       *int32
+	SuccessThreshold    *int32
+	FailureThreshold    *int32
+}
+
+// applyProbes applies the probe options to the Thanos container of the Pod.
+// gRPC health checks are only used without gRPC server TLS, which the kubelet does not support.
+func applyProbes(spec *corev1.PodSpec, opts *ProbeOptions, tls *TLSOptions) {
+	if opts == nil || len(spec.Containers) == 0 {
+		return
+	}
+	c := &spec.Containers[0]
+
+	if opts.GRPC && tls == nil && c.ReadinessProbe != nil {
+		for _, p := range c.Ports {
+			if p.Name == grpcPortName {
+				c.ReadinessProbe.ProbeHandler = corev1.ProbeHandler{
+					GRPC: &corev1.GRPCAction{Port: p.ContainerPort},
+				}
+				break
+			}
+		}
+	}
+
+	if opts.Startup != nil && c.ReadinessProbe != nil {
+		c.StartupProbe = &corev1.Probe{ProbeHandler: *c.ReadinessProbe.ProbeHandler.DeepCopy()}
+		opts.Startup.apply(c.StartupProbe)
+	}
+	if c.ReadinessProbe != nil {
+		opts.Readiness.apply(c.ReadinessProbe)
+	}
+	if c.LivenessProbe != nil {
+		opts.Liveness.apply(c.LivenessProbe)
+	}
+}
+
+func (s *ProbeSettings) apply(p *corev1.Probe) {
+	if s == nil {
+		return
+	}
+	if s.InitialDelaySeconds != nil {
+		p.InitialDelaySeconds = *s.InitialDelaySeconds
+	}
+	if s.TimeoutSeconds != nil {
+		p.TimeoutSeconds = *s.TimeoutSeconds
+	}
+	if s.PeriodSeconds != nil {
+		p.PeriodSeconds = *s.PeriodSeconds
+	}
+	if s.SuccessThreshold != nil {
+		p.SuccessThreshold = *s.SuccessThreshold
+	}
+	if s.FailureThreshold != nil {
+		p.FailureThreshold = *s.FailureThreshold
+	}
+}
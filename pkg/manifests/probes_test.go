@@ -0,0 +1,59 @@
+package manifests
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func TestAugmentWithOptions_Probes(t *testing.T) {
+	newStatefulSet := func() *appsv1.StatefulSet {
+		sts := &appsv1.StatefulSet{}
+		sts.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name:  "thanos",
+			Ports: []corev1.ContainerPort{{Name: "grpc", ContainerPort: 10901}, {Name: "http", ContainerPort: 10902}},
+			ReadinessProbe: &corev1.Probe{
+				ProbeHandler:     corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/-/ready", Port: intstr.FromInt32(10902)}},
+				PeriodSeconds:    30,
+				FailureThreshold: 15,
+			},
+			LivenessProbe: &corev1.Probe{
+				ProbeHandler:     corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/-/healthy", Port: intstr.FromInt32(10902)}},
+				PeriodSeconds:    30,
+				FailureThreshold: 8,
+			},
+		}}
+		return sts
+	}
+
+	sts := newStatefulSet()
+	AugmentWithOptions(sts, Options{Probes: &ProbeOptions{
+		Liveness: &ProbeSettings{FailureThreshold: ptr.To(int32(3))},
+		Startup:  &ProbeSettings{PeriodSeconds: ptr.To(int32(10)), FailureThreshold: ptr.To(int32(60))},
+		GRPC:     true,
+	}})
+	c := sts.Spec.Template.Spec.Containers[0]
+	grpc := corev1.ProbeHandler{GRPC: &corev1.GRPCAction{Port: 10901}}
+	if !reflect.DeepEqual(c.ReadinessProbe, &corev1.Probe{ProbeHandler: grpc, PeriodSeconds: 30, FailureThreshold: 15}) {
+		t.Errorf("unexpected readiness probe %v", c.ReadinessProbe)
+	}
+	if !reflect.DeepEqual(c.StartupProbe, &corev1.Probe{ProbeHandler: grpc, PeriodSeconds: 10, FailureThreshold: 60}) {
+		t.Errorf("unexpected startup probe %v", c.StartupProbe)
+	}
+	if c.LivenessProbe.HTTPGet == nil || c.LivenessProbe.FailureThreshold != 3 || c.LivenessProbe.PeriodSeconds != 30 {
+		t.Errorf("unexpected liveness probe %v", c.LivenessProbe)
+	}
+
+	sts = newStatefulSet()
+	AugmentWithOptions(sts, Options{
+		Probes:        &ProbeOptions{GRPC: true},
+		GRPCServerTLS: &TLSOptions{CertSecret: "tls"},
+	})
+	if c := sts.Spec.Template.Spec.Containers[0]; c.ReadinessProbe.HTTPGet == nil || c.StartupProbe != nil {
+		t.Errorf("expected unchanged probes with gRPC server TLS, got %v and %v", c.ReadinessProbe, c.StartupProbe)
+	}
+}
@@ -3,7 +3,7 @@ package query
 import (
 	"fmt"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -36,7 +36,24 @@ type Options struct {
 	LookbackDelta string
 	MaxConcurrent int
 
+	RequestLoggingConfig *manifests.RequestLoggingConfig
+
 	Endpoints []Endpoint
+	// CustomEndpoints are attached to the Querier by their address, after the Endpoints.
+	CustomEndpoints []CustomEndpoint
+
+	// EndpointGroup is the name of the endpoint group served by the Querier.
+	// If set, the generated resource names are suffixed with the group name
+	// and the Querier is not advertised as a Query API.
+	EndpointGroup string
+	// Pool is the name of the query pool the Querier belongs to.
+	// If set, the generated resource names are suffixed with the pool name
+	// and the resources are labeled with the pool name.
+	Pool string
+	// StoreResponseTimeout is the maximum time to wait for a response from an endpoint.
+	StoreResponseTimeout manifests.Duration
+	// MaxConcurrentSelect is the maximum number of concurrent selects per query.
+	MaxConcurrentSelect *int32
 }
 
 // Endpoint represents a single StoreAPI DNS formatted address.
@@ -48,13 +65,20 @@ type Endpoint struct {
 	Port        int32
 }
 
+// CustomEndpoint is a StoreAPI endpoint attached to the Querier by its address rather than through a Service.
+type CustomEndpoint struct {
+	// Address is the address of the endpoint, optionally prefixed for DNS service discovery.
+	Address string
+	Type    manifests.EndpointType
+}
+
 func (opts Options) Build() []client.Object {
 	var objs []client.Object
 	selectorLabels := opts.GetSelectorLabels()
 	objectMetaLabels := GetLabels(opts)
 	name := opts.GetGeneratedResourceName()
 
-	objs = append(objs, manifests.BuildServiceAccount(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, opts.Annotations))
+	objs = append(objs, manifests.BuildServiceAccounts(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, opts.Annotations, opts.ServiceAccount)...)
 	objs = append(objs, newQueryDeployment(opts, selectorLabels, objectMetaLabels))
 	objs = append(objs, newQueryService(opts, selectorLabels, objectMetaLabels))
 
@@ -62,14 +86,31 @@ func (opts Options) Build() []client.Object {
 		objs = append(objs, manifests.NewPodDisruptionBudget(name, opts.Namespace, selectorLabels, objectMetaLabels, opts.Annotations, *opts.PodDisruptionConfig))
 	}
 
+	if opts.Autoscaling != nil {
+		objs = append(objs, manifests.NewHorizontalPodAutoscaler(name, opts.Namespace, objectMetaLabels, opts.Annotations, *opts.Autoscaling))
+	}
+
+	if opts.LogForwarding != nil {
+		objs = append(objs, manifests.BuildLogForwardingConfigMap(name, opts.Namespace, objectMetaLabels, *opts.LogForwarding))
+	}
+
 	if opts.ServiceMonitorConfig.Enabled {
-		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, objectMetaLabels, selectorLabels, serviceMonitorOpts(opts.ServiceMonitorConfig)))
+		smLabels := manifests.MergeLabels(opts.ServiceMonitorConfig.Labels, objectMetaLabels)
+		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, smLabels, selectorLabels, serviceMonitorOpts(opts.Options)))
 	}
 	return objs
 }
 
+// GetGeneratedResourceName returns the name of the Thanos Query component.
+// If an endpoint group or pool is provided, the name will be suffixed with the group or pool name.
 func (opts Options) GetGeneratedResourceName() string {
 	name := fmt.Sprintf("%s-%s", Name, opts.getOwner())
+	if opts.EndpointGroup != "" {
+		name = fmt.Sprintf("%s-%s", name, opts.EndpointGroup)
+	}
+	if opts.Pool != "" {
+		name = fmt.Sprintf("%s-%s", name, opts.Pool)
+	}
 	return manifests.ValidateAndSanitizeResourceName(name)
 }
 
@@ -110,20 +151,12 @@ func newQueryDeployment(opts Options, selectorLabels, objectMetaLabels map[strin
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		// Ensure restrictive context for the container
 		// More info: https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted
-		SecurityContext: &corev1.SecurityContext{
-			RunAsNonRoot:             ptr.To(true),
-			AllowPrivilegeEscalation: ptr.To(false),
-			Capabilities: &corev1.Capabilities{
-				Drop: []corev1.Capability{
-					"ALL",
-				},
-			},
-		},
+		SecurityContext: manifests.DefaultSecurityContext(),
 		ReadinessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path:   "/-/ready",
-					Port:   intstr.FromInt32(HTTPPort),
+					Port:   intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 					Scheme: corev1.URISchemeHTTP,
 				},
 			},
@@ -137,7 +170,7 @@ func newQueryDeployment(opts Options, selectorLabels, objectMetaLabels map[strin
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path: "/-/healthy",
-					Port: intstr.FromInt32(HTTPPort),
+					Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 				},
 			},
 			InitialDelaySeconds: 30,
@@ -148,12 +181,12 @@ func newQueryDeployment(opts Options, selectorLabels, objectMetaLabels map[strin
 		},
 		Ports: []corev1.ContainerPort{
 			{
-				ContainerPort: GRPCPort,
+				ContainerPort: opts.GetGRPCPort(GRPCPort),
 				Name:          GRPCPortName,
 			},
 			{
-				ContainerPort: HTTPPort,
-				Name:          HTTPPortName,
+				ContainerPort: opts.GetHTTPPort(HTTPPort),
+				Name:          opts.GetHTTPPortName(HTTPPortName),
 			},
 		},
 		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
@@ -170,10 +203,10 @@ func newQueryDeployment(opts Options, selectorLabels, objectMetaLabels map[strin
 			Name:        name,
 			Namespace:   opts.Namespace,
 			Labels:      objectMetaLabels,
-			Annotations: opts.Annotations,
+			Annotations: opts.GetWorkloadAnnotations(),
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &opts.Replicas,
+			Replicas: opts.GetDeploymentReplicas(),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: selectorLabels,
 			},
@@ -183,7 +216,7 @@ func newQueryDeployment(opts Options, selectorLabels, objectMetaLabels map[strin
 				},
 				Spec: corev1.PodSpec{
 					Affinity:           &podAffinity,
-					SecurityContext:    &corev1.PodSecurityContext{},
+					SecurityContext:    manifests.DefaultPodSecurityContext(),
 					Containers:         []corev1.Container{queryContainer},
 					ServiceAccountName: name,
 				},
@@ -204,18 +237,23 @@ func newQueryService(opts Options, selectorLabels, objectMetaLabels map[string]s
 	servicePorts := []corev1.ServicePort{
 		{
 			Name:       GRPCPortName,
-			Port:       GRPCPort,
-			TargetPort: intstr.FromInt32(GRPCPort),
+			Port:       opts.GetGRPCPort(GRPCPort),
+			TargetPort: intstr.FromInt32(opts.GetGRPCPort(GRPCPort)),
 		},
 		{
-			Name:       HTTPPortName,
-			Port:       HTTPPort,
-			TargetPort: intstr.FromInt32(HTTPPort),
+			Name:       opts.GetHTTPPortName(HTTPPortName),
+			Port:       opts.GetHTTPPort(HTTPPort),
+			TargetPort: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 		},
 	}
 
-	if opts.Additional.ServicePorts != nil {
-		servicePorts = append(servicePorts, opts.Additional.ServicePorts...)
+	if ports := manifests.AdditionalServicePorts(opts.Additional); ports != nil {
+		servicePorts = append(servicePorts, ports...)
+	}
+
+	annotations := opts.Annotations
+	if name := opts.GetHTTPPortName(HTTPPortName); name != HTTPPortName {
+		annotations = manifests.MergeLabels(annotations, map[string]string{manifests.HTTPPortNameAnnotation: name})
 	}
 
 	return &corev1.Service{
@@ -227,7 +265,7 @@ func newQueryService(opts Options, selectorLabels, objectMetaLabels map[string]s
 			Name:        opts.GetGeneratedResourceName(),
 			Namespace:   opts.Namespace,
 			Labels:      objectMetaLabels,
-			Annotations: opts.Annotations,
+			Annotations: annotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Selector:  selectorLabels,
@@ -241,8 +279,8 @@ func queryArgs(opts Options) []string {
 	args := []string{"query"}
 	args = append(args, opts.ToFlags()...)
 	args = append(args,
-		fmt.Sprintf("--grpc-address=0.0.0.0:%d", GRPCPort),
-		fmt.Sprintf("--http-address=0.0.0.0:%d", HTTPPort),
+		fmt.Sprintf("--grpc-address=0.0.0.0:%d", opts.GetGRPCPort(GRPCPort)),
+		fmt.Sprintf("--http-address=0.0.0.0:%d", opts.GetHTTPPort(HTTPPort)),
 		"--web.prefix-header=X-Forwarded-Prefix",
 		fmt.Sprintf("--query.timeout=%s", opts.Timeout),
 		fmt.Sprintf("--query.lookback-delta=%s", opts.LookbackDelta),
@@ -250,8 +288,13 @@ func queryArgs(opts Options) []string {
 		"--grpc.proxy-strategy=eager",
 		"--query.promql-engine=thanos",
 		fmt.Sprintf("--query.max-concurrent=%d", opts.MaxConcurrent),
+		fmt.Sprintf("--store.response-timeout=%s", opts.StoreResponseTimeout),
 	)
 
+	if opts.MaxConcurrentSelect != nil {
+		args = append(args, fmt.Sprintf("--query.max-concurrent-select=%d", *opts.MaxConcurrentSelect))
+	}
+
 	for _, label := range opts.ReplicaLabels {
 		args = append(args, fmt.Sprintf("--query.replica-label=%s", label))
 	}
@@ -272,6 +315,29 @@ func queryArgs(opts Options) []string {
 		}
 	}
 
+	for _, ep := range opts.CustomEndpoints {
+		switch ep.Type {
+		case manifests.RegularLabel:
+			args = append(args, fmt.Sprintf("--endpoint=%s", ep.Address))
+		case manifests.StrictLabel:
+			args = append(args, fmt.Sprintf("--endpoint-strict=%s", ep.Address))
+		case manifests.GroupLabel:
+			args = append(args, fmt.Sprintf("--endpoint-group=%s", ep.Address))
+		case manifests.GroupStrictLabel:
+			args = append(args, fmt.Sprintf("--endpoint-group-strict=%s", ep.Address))
+		default:
+			panic("unknown endpoint type")
+		}
+	}
+
+	args = append(args, opts.GRPCServerTLSFlags()...)
+	args = append(args, opts.GRPCServerFlags()...)
+	args = append(args, opts.GRPCClientTLSFlags()...)
+
+	if opts.RequestLoggingConfig != nil {
+		args = append(args, opts.RequestLoggingConfig.ToFlags())
+	}
+
 	// TODO(saswatamcode): Add some validation.
 	if opts.Additional.Args != nil {
 		args = append(args, opts.Additional.Args...)
@@ -281,7 +347,8 @@ func queryArgs(opts Options) []string {
 }
 
 // GetRequiredLabels returns a map of labels that can be used to look up query resources.
-// These labels are guaranteed to be present on all resources created by this package.
+// These labels are guaranteed to be present on all resources created by this package,
+// except for the Query API label which is not set on the resources of endpoint group Queriers.
 func GetRequiredLabels() map[string]string {
 	return map[string]string{
 		manifests.NameLabel:            Name,
@@ -297,17 +364,29 @@ func (opts Options) GetSelectorLabels() map[string]string {
 	labels := GetRequiredLabels()
 	labels[manifests.InstanceLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.GetGeneratedResourceName())
 	labels[manifests.OwnerLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.getOwner())
+	if opts.EndpointGroup != "" {
+		// the Querier of an endpoint group only serves the Querier of its owner
+		delete(labels, manifests.DefaultQueryAPILabel)
+		labels[manifests.QueryEndpointGroupLabel] = opts.EndpointGroup
+	}
+	if opts.Pool != "" {
+		labels[manifests.QueryPoolLabel] = opts.Pool
+	}
 	return labels
 }
 
 // GetLabels returns a map of labels that can be used to look up query resources.
 func GetLabels(opts Options) map[string]string {
-	return manifests.MergeLabels(opts.Labels, opts.GetSelectorLabels())
+	lbls := manifests.MergeLabels(opts.Labels, opts.GetSelectorLabels())
+	if opts.EndpointGroup != "" {
+		delete(lbls, manifests.DefaultQueryAPILabel)
+	}
+	return lbls
 }
 
-func serviceMonitorOpts(from manifests.ServiceMonitorConfig) manifests.ServiceMonitorOptions {
+func serviceMonitorOpts(from manifests.Options) manifests.ServiceMonitorOptions {
 	return manifests.ServiceMonitorOptions{
-		Port:     ptr.To(HTTPPortName),
-		Interval: from.Interval,
+		Port:     ptr.To(from.GetHTTPPortName(HTTPPortName)),
+		Interval: from.ServiceMonitorConfig.Interval,
 	}
 }
@@ -0,0 +1,481 @@
+package query
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/test/utils"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	someCustomLabelValue string = "xyz"
+	someOtherLabelValue  string = "abc"
+)
+
+func TestBuildQuery(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "any",
+			Namespace: "ns",
+			Image:     ptr.To("some-custom-image"),
+			Labels: map[string]string{
+				"some-custom-label":      someCustomLabelValue,
+				"some-other-label":       someOtherLabelValue,
+				"app.kubernetes.io/name": "expect-to-be-discarded",
+			},
+			PodDisruptionConfig: &manifests.PodDisruptionBudgetOptions{},
+		},
+		Timeout:       "15m",
+		LookbackDelta: "5m",
+		MaxConcurrent: 20,
+	}
+
+	objs := opts.Build()
+	if len(objs) != 4 {
+		t.Fatalf("expected 4 objects, got %d", len(objs))
+	}
+
+	utils.ValidateIsNamedServiceAccount(t, objs[0], opts, opts.Namespace)
+	utils.ValidateObjectsEqual(t, objs[1], NewQueryDeployment(opts))
+	utils.ValidateObjectsEqual(t, objs[2], NewQueryService(opts))
+	utils.ValidateIsNamedPodDisruptionBudget(t, objs[3], opts, opts.Namespace, objs[1])
+
+	wantLabels := opts.GetSelectorLabels()
+	wantLabels["some-custom-label"] = someCustomLabelValue
+	wantLabels["some-other-label"] = someOtherLabelValue
+	utils.ValidateObjectLabelsEqual(t, wantLabels, []client.Object{objs[1], objs[2]}...)
+}
+
+func TestNewQueryDeployment(t *testing.T) {
+	extraLabels := map[string]string{
+		"some-custom-label": someCustomLabelValue,
+		"some-other-label":  someOtherLabelValue,
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "test query deployment correctness",
+			opts: Options{
+				Options: manifests.Options{
+					Namespace: "ns",
+					Image:     ptr.To("some-custom-image"),
+					Labels: map[string]string{
+						"some-custom-label":      someCustomLabelValue,
+						"some-other-label":       someOtherLabelValue,
+						"app.kubernetes.io/name": "expect-to-be-discarded",
+					},
+					Annotations: map[string]string{
+						"test": "annotation",
+					},
+				},
+				Timeout:       "15m",
+				LookbackDelta: "5m",
+				MaxConcurrent: 20,
+			},
+		},
+		{
+			name: "test additional volumemount",
+			opts: Options{
+				Options: manifests.Options{
+					Namespace: "ns",
+					Image:     ptr.To("some-custom-image"),
+					Labels: map[string]string{
+						"some-custom-label":      someCustomLabelValue,
+						"some-other-label":       someOtherLabelValue,
+						"app.kubernetes.io/name": "expect-to-be-discarded",
+					},
+					Annotations: map[string]string{
+						"test": "annotation",
+					},
+					Additional: manifests.Additional{
+						VolumeMounts: []corev1.VolumeMount{
+							{
+								Name:      "test-sd",
+								MountPath: "/test-sd-file",
+							},
+						},
+					},
+				},
+				Timeout:       "15m",
+				LookbackDelta: "5m",
+				MaxConcurrent: 20,
+			},
+		},
+		{
+			name: "test additional container",
+			opts: Options{
+				Options: manifests.Options{
+					Namespace: "ns",
+					Image:     ptr.To("some-custom-image"),
+					Labels: map[string]string{
+						"some-custom-label":      someCustomLabelValue,
+						"some-other-label":       someOtherLabelValue,
+						"app.kubernetes.io/name": "expect-to-be-discarded",
+					},
+					Annotations: map[string]string{
+						"test": "annotation",
+					},
+					Additional: manifests.Additional{
+						Containers: []corev1.Container{
+							{
+								Name:  "test-container",
+								Image: "test-image:latest",
+								Args:  []string{"--test-arg"},
+								Env: []corev1.EnvVar{{
+									Name:  "TEST_ENV",
+									Value: "test",
+								}},
+							},
+						},
+					},
+				},
+				Timeout:       "15m",
+				LookbackDelta: "5m",
+				MaxConcurrent: 20,
+			},
+		},
+		{
+			name: "test request logging config",
+			opts: Options{
+				Options: manifests.Options{
+					Namespace: "ns",
+					Image:     ptr.To("some-custom-image"),
+					Labels: map[string]string{
+						"some-custom-label":      someCustomLabelValue,
+						"some-other-label":       someOtherLabelValue,
+						"app.kubernetes.io/name": "expect-to-be-discarded",
+					},
+					Annotations: map[string]string{
+						"test": "annotation",
+					},
+				},
+				Timeout:       "15m",
+				LookbackDelta: "5m",
+				MaxConcurrent: 20,
+				RequestLoggingConfig: &manifests.RequestLoggingConfig{
+					HTTP: &manifests.RequestLoggingOptions{Level: "INFO", LogEnd: true},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			name := tc.opts.GetGeneratedResourceName()
+			query := NewQueryDeployment(tc.opts)
+			objectMetaLabels := GetLabels(tc.opts)
+			utils.ValidateNameNamespaceAndLabels(t, query, name, tc.opts.Namespace, objectMetaLabels)
+			utils.ValidateHasLabels(t, query, tc.opts.GetSelectorLabels())
+			utils.ValidateHasLabels(t, query, extraLabels)
+
+			if query.Spec.Template.Spec.ServiceAccountName != name {
+				t.Errorf("expected deployment to use service account %s, got %s", name, query.Spec.Template.Spec.ServiceAccountName)
+			}
+			if len(query.Spec.Template.Spec.Containers) != (len(tc.opts.Additional.Containers) + 1) {
+				t.Errorf("expected deployment to have %d containers, got %d", len(tc.opts.Additional.Containers)+1, len(query.Spec.Template.Spec.Containers))
+			}
+			if len(query.Annotations) != 2 {
+				t.Errorf("expected deployment to have 2 annotations, got %d", len(query.Annotations))
+			}
+			if query.Annotations["test"] != "annotation" {
+				t.Errorf("expected deployment annotation test to be annotation, got %s", query.Annotations["test"])
+			}
+			if query.Annotations[manifests.ThanosVersionAnnotation] != tc.opts.GetVersion() {
+				t.Errorf("expected deployment to be annotated with version %s, got %s", tc.opts.GetVersion(), query.Annotations[manifests.ThanosVersionAnnotation])
+			}
+
+			expectArgs := queryArgs(tc.opts)
+			var found bool
+			for _, c := range query.Spec.Template.Spec.Containers {
+				if c.Name == Name {
+					found = true
+					if c.Image != tc.opts.GetContainerImage() {
+						t.Errorf("expected query deployment to have image %s, got %s", tc.opts.GetContainerImage(), c.Image)
+					}
+					if len(c.Args) != len(expectArgs) {
+						t.Errorf("expected query deployment to have %d args, got %d", len(expectArgs), len(c.Args))
+					}
+					for i, arg := range c.Args {
+						if arg != expectArgs[i] {
+							t.Errorf("expected query deployment to have arg %s, got %s", expectArgs[i], arg)
+						}
+					}
+					if tc.opts.RequestLoggingConfig != nil && !slices.Contains(c.Args, tc.opts.RequestLoggingConfig.ToFlags()) {
+						t.Errorf("expected query deployment to have request logging config arg")
+					}
+
+					if len(c.VolumeMounts) != len(tc.opts.Additional.VolumeMounts) {
+						t.Errorf("expected query deployment to have 1 volumemount, got %d", len(c.VolumeMounts))
+					}
+					if len(c.VolumeMounts) > 0 {
+						if c.VolumeMounts[0].Name != "test-sd" {
+							t.Errorf("expected query deployment to have volumemount named test-sd, got %s", c.VolumeMounts[0].Name)
+						}
+						if c.VolumeMounts[0].MountPath != "/test-sd-file" {
+							t.Errorf("expected query deployment to have volumemount mounted at /test-sd-file, got %s", c.VolumeMounts[0].MountPath)
+						}
+					}
+
+				}
+			}
+			if !found {
+				t.Errorf("expected query deployment to have container named %s", Name)
+			}
+		})
+	}
+}
+
+func TestNewQueryService(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: "ns",
+			Image:     ptr.To("some-custom-image"),
+			Labels: map[string]string{
+				"some-custom-label":      someCustomLabelValue,
+				"some-other-label":       someOtherLabelValue,
+				"app.kubernetes.io/name": "expect-to-be-discarded",
+			},
+		},
+		Timeout:       "15m",
+		LookbackDelta: "5m",
+		MaxConcurrent: 20,
+	}
+
+	extraLabels := map[string]string{
+		"some-custom-label": someCustomLabelValue,
+		"some-other-label":  someOtherLabelValue,
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "test query service correctness",
+			opts: opts,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			querySvc := NewQueryService(tc.opts)
+			objectMetaLabels := GetLabels(tc.opts)
+			utils.ValidateNameNamespaceAndLabels(t, querySvc, tc.opts.GetGeneratedResourceName(), tc.opts.Namespace, objectMetaLabels)
+			utils.ValidateHasLabels(t, querySvc, extraLabels)
+			utils.ValidateHasLabels(t, querySvc, tc.opts.GetSelectorLabels())
+		})
+	}
+}
+
+func TestEndpointGroupQuery(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "any",
+			Namespace: "ns",
+			Labels: map[string]string{
+				manifests.DefaultQueryAPILabel: manifests.DefaultQueryAPIValue,
+			},
+		},
+		Timeout:              "15m",
+		LookbackDelta:        "5m",
+		MaxConcurrent:        20,
+		EndpointGroup:        "federated",
+		StoreResponseTimeout: "2m",
+		MaxConcurrentSelect:  ptr.To(int32(8)),
+	}
+
+	if name := opts.GetGeneratedResourceName(); name != "thanos-query-any-federated" {
+		t.Errorf("expected name thanos-query-any-federated, got %s", name)
+	}
+
+	objs := opts.Build()
+	for _, obj := range objs {
+		if _, ok := obj.GetLabels()[manifests.DefaultQueryAPILabel]; ok {
+			t.Errorf("expected %s to not be advertised as a query API", obj.GetName())
+		}
+		if obj.GetLabels()[manifests.QueryEndpointGroupLabel] != "federated" {
+			t.Errorf("expected %s to have endpoint group label", obj.GetName())
+		}
+	}
+
+	args := NewQueryDeployment(opts).Spec.Template.Spec.Containers[0].Args
+	for _, arg := range []string{"--store.response-timeout=2m", "--query.max-concurrent-select=8"} {
+		if !slices.Contains(args, arg) {
+			t.Errorf("expected arg %s in %v", arg, args)
+		}
+	}
+
+	opts.StoreResponseTimeout = ""
+	opts.MaxConcurrentSelect = nil
+	for _, arg := range NewQueryDeployment(opts).Spec.Template.Spec.Containers[0].Args {
+		if strings.HasPrefix(arg, "--store.response-timeout") || strings.HasPrefix(arg, "--query.max-concurrent-select") {
+			t.Errorf("expected no fan-out flags when unset, got %s", arg)
+		}
+	}
+}
+
+func TestQueryPool(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "any",
+			Namespace: "ns",
+		},
+		Timeout:       "5m",
+		LookbackDelta: "5m",
+		MaxConcurrent: 100,
+		Pool:          "rule-evaluation",
+	}
+
+	if name := opts.GetGeneratedResourceName(); name != "thanos-query-any-rule-evaluation" {
+		t.Errorf("expected name thanos-query-any-rule-evaluation, got %s", name)
+	}
+
+	for _, obj := range opts.Build() {
+		if obj.GetLabels()[manifests.DefaultQueryAPILabel] != manifests.DefaultQueryAPIValue {
+			t.Errorf("expected %s to be advertised as a query API", obj.GetName())
+		}
+		if obj.GetLabels()[manifests.QueryPoolLabel] != "rule-evaluation" {
+			t.Errorf("expected %s to have query pool label", obj.GetName())
+		}
+	}
+
+	args := NewQueryDeployment(opts).Spec.Template.Spec.Containers[0].Args
+	for _, arg := range []string{"--query.timeout=5m", "--query.max-concurrent=100"} {
+		if !slices.Contains(args, arg) {
+			t.Errorf("expected arg %s in %v", arg, args)
+		}
+	}
+}
+
+func TestQueryAutoscaling(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:       "any",
+			Namespace:   "ns",
+			Replicas:    3,
+			Autoscaling: &manifests.HorizontalPodAutoscalerOptions{MinReplicas: ptr.To(int32(2)), MaxReplicas: 6},
+		},
+		Timeout:       "15m",
+		LookbackDelta: "5m",
+		MaxConcurrent: 20,
+	}
+
+	if replicas := NewQueryDeployment(opts).Spec.Replicas; replicas != nil {
+		t.Errorf("expected autoscaled deployment to not set replicas, got %d", *replicas)
+	}
+
+	var hpa *autoscalingv2.HorizontalPodAutoscaler
+	for _, obj := range opts.Build() {
+		if h, ok := obj.(*autoscalingv2.HorizontalPodAutoscaler); ok {
+			hpa = h
+		}
+	}
+	if hpa == nil {
+		t.Fatal("expected a horizontal pod autoscaler")
+	}
+	if hpa.Spec.ScaleTargetRef.Name != opts.GetGeneratedResourceName() || hpa.Spec.MaxReplicas != 6 {
+		t.Errorf("unexpected horizontal pod autoscaler spec %+v", hpa.Spec)
+	}
+}
+
+func TestQueryGRPCTLS(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:         "any",
+			Namespace:     "ns",
+			GRPCServerTLS: &manifests.TLSOptions{CertSecret: "query-server"},
+			GRPCClientTLS: &manifests.TLSOptions{CertSecret: "query-client"},
+		},
+		Timeout:       "15m",
+		LookbackDelta: "5m",
+		MaxConcurrent: 20,
+	}
+
+	container := NewQueryDeployment(opts).Spec.Template.Spec.Containers[0]
+	for _, flag := range append(opts.GRPCServerTLSFlags(), opts.GRPCClientTLSFlags()...) {
+		if !slices.Contains(container.Args, flag) {
+			t.Errorf("expected flag %s in args %v", flag, container.Args)
+		}
+	}
+	if len(container.VolumeMounts) != 2 {
+		t.Errorf("expected server and client certificates to be mounted, got %v", container.VolumeMounts)
+	}
+}
+
+func TestQueryMetricsPortName(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:                "any",
+			Namespace:            "ns",
+			HTTPPortName:         "http-metrics",
+			ServiceMonitorConfig: manifests.ServiceMonitorConfig{Enabled: true},
+		},
+		Timeout:       "15m",
+		LookbackDelta: "5m",
+		MaxConcurrent: 20,
+	}
+
+	container := NewQueryDeployment(opts).Spec.Template.Spec.Containers[0]
+	if !slices.ContainsFunc(container.Ports, func(p corev1.ContainerPort) bool { return p.Name == "http-metrics" && p.ContainerPort == HTTPPort }) {
+		t.Errorf("expected container port http-metrics, got %v", container.Ports)
+	}
+	svc := NewQueryService(opts)
+	if !slices.ContainsFunc(svc.Spec.Ports, func(p corev1.ServicePort) bool { return p.Name == "http-metrics" && p.Port == HTTPPort }) {
+		t.Errorf("expected service port http-metrics, got %v", svc.Spec.Ports)
+	}
+	if name := manifests.GetHTTPPortName(svc); name != "http-metrics" {
+		t.Errorf("expected service to record the http port name, got %s", name)
+	}
+
+	var sm *monitoringv1.ServiceMonitor
+	for _, obj := range opts.Build() {
+		if m, ok := obj.(*monitoringv1.ServiceMonitor); ok {
+			sm = m
+		}
+	}
+	if sm == nil || sm.Spec.Endpoints[0].Port != "http-metrics" {
+		t.Errorf("expected service monitor to scrape port http-metrics, got %v", sm)
+	}
+}
+
+func TestCustomEndpoints(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "any",
+			Namespace: "ns",
+		},
+		Timeout:       "15m",
+		LookbackDelta: "5m",
+		MaxConcurrent: 20,
+		Endpoints: []Endpoint{
+			{ServiceName: "store", Namespace: "ns", Type: manifests.RegularLabel},
+		},
+		CustomEndpoints: []CustomEndpoint{
+			{Address: "10.0.0.1:10901", Type: manifests.RegularLabel},
+			{Address: "dnssrv+_grpc._tcp.sidecar.example.com", Type: manifests.StrictLabel},
+			{Address: "thanos-query.example.com:443", Type: manifests.GroupLabel},
+			{Address: "thanos-receive.example.com:10901", Type: manifests.GroupStrictLabel},
+		},
+	}
+
+	args := NewQueryDeployment(opts).Spec.Template.Spec.Containers[0].Args
+	for _, arg := range []string{
+		"--endpoint=dnssrv+_grpc._tcp.store.ns.svc.cluster.local",
+		"--endpoint=10.0.0.1:10901",
+		"--endpoint-strict=dnssrv+_grpc._tcp.sidecar.example.com",
+		"--endpoint-group=thanos-query.example.com:443",
+		"--endpoint-group-strict=thanos-receive.example.com:10901",
+	} {
+		if !slices.Contains(args, arg) {
+			t.Errorf("expected arg %s in %v", arg, args)
+		}
+	}
+}
@@ -3,7 +3,7 @@ package queryfrontend
 import (
 	"fmt"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -25,13 +25,16 @@ const (
 	HTTPPortName = "http"
 
 	externalCacheEnvVarName = "CACHE_CONFIG"
+	responseCacheName       = "response-cache"
 )
 
 // Options for Thanos Query Frontend
 type Options struct {
 	manifests.Options
-	QueryService           string
-	QueryPort              int32
+	QueryService string
+	QueryPort    int32
+	// DownstreamURL overrides QueryService and QueryPort when set.
+	DownstreamURL          string
 	LogQueriesLongerThan   manifests.Duration
 	CompressResponses      bool
 	ResponseCacheConfig    manifests.CacheConfig
@@ -40,6 +43,37 @@ type Options struct {
 	RangeMaxRetries        int
 	LabelsMaxRetries       int
 	LabelsDefaultTimeRange manifests.Duration
+	// QueryAffinity prefers scheduling the Query Frontend alongside the Queriers it forwards to.
+	// If not set, no pod affinity is configured.
+	QueryAffinity *QueryAffinity
+}
+
+// QueryAffinity is the preferred pod affinity of the Query Frontend towards its Queriers.
+type QueryAffinity struct {
+	// QuerySelector selects the pods of the Queriers.
+	QuerySelector map[string]string
+	// TopologyKey is the node label identifying the topology domain, e.g. topology.kubernetes.io/zone.
+	TopologyKey string
+	// Weight of the preference in the range 1-100.
+	Weight int32
+}
+
+func (a *QueryAffinity) affinity(namespace string) *corev1.Affinity {
+	if a == nil {
+		return nil
+	}
+	return &corev1.Affinity{
+		PodAffinity: &corev1.PodAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+				Weight: a.Weight,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: a.QuerySelector},
+					Namespaces:    []string{namespace},
+					TopologyKey:   a.TopologyKey,
+				},
+			}},
+		},
+	}
 }
 
 func (opts Options) Build() []client.Object {
@@ -48,7 +82,7 @@ func (opts Options) Build() []client.Object {
 	objectMetaLabels := GetLabels(opts)
 	name := opts.GetGeneratedResourceName()
 
-	objs = append(objs, manifests.BuildServiceAccount(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, opts.Annotations))
+	objs = append(objs, manifests.BuildServiceAccounts(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, opts.Annotations, opts.ServiceAccount)...)
 	objs = append(objs, newQueryFrontendDeployment(opts, selectorLabels, objectMetaLabels))
 	objs = append(objs, newQueryFrontendService(opts, selectorLabels, objectMetaLabels))
 
@@ -56,6 +90,19 @@ func (opts Options) Build() []client.Object {
 		objs = append(objs, manifests.NewPodDisruptionBudget(name, opts.Namespace, selectorLabels, objectMetaLabels, opts.Annotations, *opts.PodDisruptionConfig))
 	}
 
+	if opts.Autoscaling != nil {
+		objs = append(objs, manifests.NewHorizontalPodAutoscaler(name, opts.Namespace, objectMetaLabels, opts.Annotations, *opts.Autoscaling))
+	}
+
+	if opts.LogForwarding != nil {
+		objs = append(objs, manifests.BuildLogForwardingConfigMap(name, opts.Namespace, objectMetaLabels, *opts.LogForwarding))
+	}
+
+	if opts.ServiceMonitorConfig.Enabled {
+		smLabels := manifests.MergeLabels(opts.ServiceMonitorConfig.Labels, objectMetaLabels)
+		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, smLabels, selectorLabels, serviceMonitorOpts(opts.Options)))
+	}
+
 	return objs
 }
 
@@ -98,10 +145,10 @@ func newQueryFrontendDeployment(opts Options, selectorLabels, objectMetaLabels m
 			Name:        name,
 			Namespace:   opts.Namespace,
 			Labels:      objectMetaLabels,
-			Annotations: opts.Annotations,
+			Annotations: opts.GetWorkloadAnnotations(),
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &opts.Replicas,
+			Replicas: opts.GetDeploymentReplicas(),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: selectorLabels,
 			},
@@ -111,7 +158,8 @@ func newQueryFrontendDeployment(opts Options, selectorLabels, objectMetaLabels m
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: name,
-					SecurityContext:    &corev1.PodSecurityContext{},
+					SecurityContext:    manifests.DefaultPodSecurityContext(),
+					Affinity:           opts.QueryAffinity.affinity(opts.Namespace),
 					Containers: []corev1.Container{
 						{
 							Name:  Name,
@@ -119,26 +167,18 @@ func newQueryFrontendDeployment(opts Options, selectorLabels, objectMetaLabels m
 							Args:  queryFrontendArgs(opts),
 							Ports: []corev1.ContainerPort{
 								{
-									Name:          HTTPPortName,
-									ContainerPort: HTTPPort,
+									Name:          opts.GetHTTPPortName(HTTPPortName),
+									ContainerPort: opts.GetHTTPPort(HTTPPort),
 									Protocol:      corev1.ProtocolTCP,
 								},
 							},
-							Env: env,
-							SecurityContext: &corev1.SecurityContext{
-								AllowPrivilegeEscalation: ptr.To(false),
-								RunAsNonRoot:             ptr.To(true),
-								Capabilities: &corev1.Capabilities{
-									Drop: []corev1.Capability{
-										"ALL",
-									},
-								},
-							},
+							Env:             env,
+							SecurityContext: manifests.DefaultSecurityContext(),
 							LivenessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/healthy",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 							},
@@ -146,7 +186,7 @@ func newQueryFrontendDeployment(opts Options, selectorLabels, objectMetaLabels m
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/ready",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 							},
@@ -156,6 +196,9 @@ func newQueryFrontendDeployment(opts Options, selectorLabels, objectMetaLabels m
 			},
 		},
 	}
+	if opts.ResponseCacheConfig.FromSecret == nil && opts.ResponseCacheConfig.Redis != nil {
+		opts.ResponseCacheConfig.Redis.AddToPodSpec(&deployment.Spec.Template.Spec, responseCacheName)
+	}
 	manifests.AugmentWithOptions(deployment, opts.Options)
 	return deployment
 }
@@ -181,9 +224,9 @@ func newQueryFrontendService(opts Options, selectorLabels, objectMetaLabels map[
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
 				{
-					Name:       HTTPPortName,
-					Port:       HTTPPort,
-					TargetPort: intstr.FromInt32(HTTPPort),
+					Name:       opts.GetHTTPPortName(HTTPPortName),
+					Port:       opts.GetHTTPPort(HTTPPort),
+					TargetPort: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
@@ -191,18 +234,25 @@ func newQueryFrontendService(opts Options, selectorLabels, objectMetaLabels map[
 		},
 	}
 
-	if opts.Additional.ServicePorts != nil {
-		service.Spec.Ports = append(service.Spec.Ports, opts.Additional.ServicePorts...)
+	if ports := manifests.AdditionalServicePorts(opts.Additional); ports != nil {
+		service.Spec.Ports = append(service.Spec.Ports, ports...)
 	}
 
 	return service
 }
 
+func (opts Options) getDownstreamURL() string {
+	if opts.DownstreamURL != "" {
+		return opts.DownstreamURL
+	}
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", opts.QueryService, opts.Namespace, opts.QueryPort)
+}
+
 func queryFrontendArgs(opts Options) []string {
 	args := []string{
 		"query-frontend",
-		fmt.Sprintf("--http-address=0.0.0.0:%d", HTTPPort),
-		fmt.Sprintf("--query-frontend.downstream-url=http://%s.%s.svc.cluster.local:%d", opts.QueryService, opts.Namespace, opts.QueryPort),
+		fmt.Sprintf("--http-address=0.0.0.0:%d", opts.GetHTTPPort(HTTPPort)),
+		fmt.Sprintf("--query-frontend.downstream-url=%s", opts.getDownstreamURL()),
 		fmt.Sprintf("--query-frontend.log-queries-longer-than=%s", opts.LogQueriesLongerThan),
 		fmt.Sprintf("--query-range.split-interval=%s", opts.RangeSplitInterval),
 		fmt.Sprintf("--labels.split-interval=%s", opts.LabelsSplitInterval),
@@ -215,6 +265,14 @@ func queryFrontendArgs(opts Options) []string {
 	if opts.ResponseCacheConfig.FromSecret != nil {
 		args = append(args, fmt.Sprintf("--query-range.response-cache-config=$(%s)", externalCacheEnvVarName))
 		args = append(args, fmt.Sprintf("--labels.response-cache-config=$(%s)", externalCacheEnvVarName))
+	} else if opts.ResponseCacheConfig.Redis != nil {
+		conf := opts.ResponseCacheConfig.Redis.Config(responseCacheName)
+		args = append(args, fmt.Sprintf("--query-range.response-cache-config=%s", conf))
+		args = append(args, fmt.Sprintf("--labels.response-cache-config=%s", conf))
+	} else if opts.ResponseCacheConfig.Memcached != nil {
+		conf := opts.ResponseCacheConfig.Memcached.Config()
+		args = append(args, fmt.Sprintf("--query-range.response-cache-config=%s", conf))
+		args = append(args, fmt.Sprintf("--labels.response-cache-config=%s", conf))
 	} else if opts.ResponseCacheConfig.InMemoryCacheConfig != nil {
 		conf := opts.ResponseCacheConfig.InMemoryCacheConfig.String()
 		args = append(args, fmt.Sprintf("--query-range.response-cache-config=%s", conf))
@@ -255,3 +313,10 @@ func (opts Options) GetSelectorLabels() map[string]string {
 func GetLabels(opts Options) map[string]string {
 	return manifests.MergeLabels(opts.Labels, opts.GetSelectorLabels())
 }
+
+func serviceMonitorOpts(from manifests.Options) manifests.ServiceMonitorOptions {
+	return manifests.ServiceMonitorOptions{
+		Port:     ptr.To(from.GetHTTPPortName(HTTPPortName)),
+		Interval: from.ServiceMonitorConfig.Interval,
+	}
+}
@@ -2,9 +2,12 @@ package queryfrontend
 
 import (
 	"reflect"
+	"slices"
 	"testing"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	corev1 "k8s.io/api/core/v1"
@@ -307,3 +310,108 @@ func TestNewQueryFrontendService(t *testing.T) {
 		t.Errorf("expected service to have 1 port (%d), got %v", HTTPPort, service.Spec.Ports)
 	}
 }
+
+func TestQueryFrontendDownstreamURL(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: "ns",
+		},
+		QueryService: "thanos-query",
+		QueryPort:    9090,
+	}
+
+	args := queryFrontendArgs(opts)
+	if !slices.Contains(args, "--query-frontend.downstream-url=http://thanos-query.ns.svc.cluster.local:9090") {
+		t.Errorf("expected query frontend to use in-cluster query service, got %v", args)
+	}
+
+	opts.DownstreamURL = "https://thanos-query.example.com"
+	args = queryFrontendArgs(opts)
+	if !slices.Contains(args, "--query-frontend.downstream-url=https://thanos-query.example.com") {
+		t.Errorf("expected query frontend to use external downstream url, got %v", args)
+	}
+}
+
+func TestQueryFrontendMemcachedCache(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: "ns",
+		},
+		ResponseCacheConfig: manifests.CacheConfig{
+			Memcached:           &manifests.MemcachedCacheConfig{Addresses: []string{"memcached:11211"}},
+			InMemoryCacheConfig: &manifests.InMemoryCacheConfig{MaxSize: "1Gi"},
+		},
+	}
+
+	conf := opts.ResponseCacheConfig.Memcached.Config()
+	args := queryFrontendArgs(opts)
+	for _, arg := range []string{"--query-range.response-cache-config=" + conf, "--labels.response-cache-config=" + conf} {
+		if !slices.Contains(args, arg) {
+			t.Errorf("expected memcached to take precedence over the in-memory cache, got %v", args)
+		}
+	}
+}
+
+func TestQueryFrontendQueryAffinity(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: "ns",
+		},
+		QueryService: "thanos-query",
+		QueryPort:    9090,
+	}
+	if affinity := NewQueryFrontendDeployment(opts).Spec.Template.Spec.Affinity; affinity != nil {
+		t.Errorf("expected no affinity without co-scheduling, got %v", affinity)
+	}
+
+	opts.QueryAffinity = &QueryAffinity{
+		QuerySelector: map[string]string{"app.kubernetes.io/instance": "thanos-query"},
+		TopologyKey:   "topology.kubernetes.io/zone",
+		Weight:        50,
+	}
+	affinity := NewQueryFrontendDeployment(opts).Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.PodAffinity == nil || len(affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 {
+		t.Fatalf("expected a single preferred pod affinity term, got %v", affinity)
+	}
+	term := affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0]
+	if term.Weight != 50 || term.PodAffinityTerm.TopologyKey != "topology.kubernetes.io/zone" ||
+		!reflect.DeepEqual(term.PodAffinityTerm.LabelSelector.MatchLabels, opts.QueryAffinity.QuerySelector) ||
+		!reflect.DeepEqual(term.PodAffinityTerm.Namespaces, []string{"ns"}) {
+		t.Errorf("unexpected pod affinity term %v", term)
+	}
+}
+
+func TestQueryFrontendServiceMonitor(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: "ns",
+			Owner:     "any",
+			ServiceMonitorConfig: manifests.ServiceMonitorConfig{
+				Enabled: true,
+				Labels:  map[string]string{"release": "prometheus"},
+			},
+		},
+		QueryService: "thanos-query",
+	}
+
+	objs := opts.Build()
+	if len(objs) != 4 {
+		t.Fatalf("expected 4 objects, got %d", len(objs))
+	}
+	sm, ok := objs[3].(*monitoringv1.ServiceMonitor)
+	if !ok {
+		t.Fatalf("expected a ServiceMonitor, got %T", objs[3])
+	}
+	if sm.GetName() != opts.GetGeneratedResourceName() || sm.GetNamespace() != "ns" {
+		t.Errorf("unexpected ServiceMonitor %s/%s", sm.GetNamespace(), sm.GetName())
+	}
+	if sm.GetLabels()["release"] != "prometheus" {
+		t.Errorf("expected ServiceMonitor to carry the configured labels, got %v", sm.GetLabels())
+	}
+	if len(sm.Spec.Endpoints) != 1 || sm.Spec.Endpoints[0].Port != HTTPPortName {
+		t.Errorf("expected ServiceMonitor to scrape the %s port, got %v", HTTPPortName, sm.Spec.Endpoints)
+	}
+	if !reflect.DeepEqual(sm.Spec.Selector.MatchLabels, opts.GetSelectorLabels()) {
+		t.Errorf("expected ServiceMonitor to select the query frontend service, got %v", sm.Spec.Selector.MatchLabels)
+	}
+}
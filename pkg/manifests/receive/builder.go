@@ -3,8 +3,8 @@ package receive
 import (
 	"fmt"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestsstore "github.com/thanos-community/thanos-operator/internal/pkg/manifests/store"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestsstore "github.com/thanos-community/thanos-operator/pkg/manifests/store"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -14,6 +14,8 @@ import (
 	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -42,6 +44,8 @@ const (
 	HashringConfigKey = "hashrings.json"
 	// EmptyHashringConfig is the empty hashring configuration.
 	EmptyHashringConfig = "[{}]"
+	// LimitsConfigKey is the key in the ConfigMap for the limits configuration.
+	LimitsConfigKey = "limits.yaml"
 )
 
 // IngesterOptions for Thanos Receive components
@@ -53,6 +57,14 @@ type IngesterOptions struct {
 	ExternalLabels map[string]string
 	// HashringName is the name of the hashring and is a required field.
 	HashringName string
+	// StoreAPIServiceLabels are additional labels added only to the Store API Service.
+	// The labels required for discovery take precedence over labels set here.
+	StoreAPIServiceLabels map[string]string
+	// EndpointType is the type of endpoint advertised to Queriers through the endpoint label.
+	// If set, it takes precedence over any endpoint label set in Labels.
+	EndpointType manifests.EndpointType
+	// Backup configures how the persistent volumes of the ingesters participate in cluster backups.
+	Backup *manifests.BackupOptions
 }
 
 type TSDBOpts struct {
@@ -66,6 +78,9 @@ type RouterOptions struct {
 	ExternalLabels    map[string]string
 	HashringConfig    string
 	HashringAlgorithm string
+	// LimitsConfig is the rendered limits configuration for the router.
+	// See BuildLimitsConfig.
+	LimitsConfig string
 }
 
 // Build builds the ingester for Thanos Receive
@@ -75,7 +90,7 @@ func (opts IngesterOptions) Build() []client.Object {
 	objectMetaLabels := GetIngesterLabels(opts)
 	name := opts.GetGeneratedResourceName()
 
-	objs = append(objs, manifests.BuildServiceAccount(name, opts.Namespace, selectorLabels, opts.Annotations))
+	objs = append(objs, manifests.BuildServiceAccounts(name, opts.Namespace, selectorLabels, opts.Annotations, opts.ServiceAccount)...)
 	objs = append(objs, newIngestorService(opts, selectorLabels, objectMetaLabels))
 	objs = append(objs, newIngestorStatefulSet(opts, selectorLabels, objectMetaLabels))
 
@@ -85,7 +100,7 @@ func (opts IngesterOptions) Build() []client.Object {
 
 	if opts.ServiceMonitorConfig.Enabled {
 		smLabels := manifests.MergeLabels(opts.ServiceMonitorConfig.Labels, objectMetaLabels)
-		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, selectorLabels, smLabels, serviceMonitorOpts(opts.ServiceMonitorConfig)))
+		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, smLabels, selectorLabels, serviceMonitorOpts(opts.Options)))
 	}
 	return objs
 }
@@ -102,17 +117,22 @@ func (opts RouterOptions) Build() []client.Object {
 	objectMetaLabels := GetRouterLabels(opts)
 	name := opts.GetGeneratedResourceName()
 
-	objs = append(objs, manifests.BuildServiceAccount(name, opts.Namespace, selectorLabels, opts.Annotations))
+	objs = append(objs, manifests.BuildServiceAccounts(name, opts.Namespace, selectorLabels, opts.Annotations, opts.ServiceAccount)...)
 	objs = append(objs, newRouterService(opts, selectorLabels, objectMetaLabels))
 	objs = append(objs, newRouterDeployment(opts, selectorLabels, objectMetaLabels))
-	objs = append(objs, newHashringConfigMap(name, opts.Namespace, opts.HashringConfig, objectMetaLabels))
+	cm := newHashringConfigMap(name, opts.Namespace, opts.HashringConfig, objectMetaLabels)
+	if opts.LimitsConfig != "" {
+		cm.Data[LimitsConfigKey] = opts.LimitsConfig
+	}
+	objs = append(objs, cm)
 
 	if opts.PodDisruptionConfig != nil {
 		objs = append(objs, manifests.NewPodDisruptionBudget(name, opts.Namespace, selectorLabels, objectMetaLabels, opts.Annotations, *opts.PodDisruptionConfig))
 	}
 
 	if opts.ServiceMonitorConfig.Enabled {
-		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, objectMetaLabels, selectorLabels, serviceMonitorOpts(opts.ServiceMonitorConfig)))
+		smLabels := manifests.MergeLabels(opts.ServiceMonitorConfig.Labels, objectMetaLabels)
+		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, smLabels, selectorLabels, serviceMonitorOpts(opts.Options)))
 	}
 	return objs
 }
@@ -129,6 +149,12 @@ const (
 	dataVolumeMountPath = "var/thanos/receive"
 )
 
+// IngesterVolumeClaimName returns the name of the PersistentVolumeClaim holding the data of the ingester
+// with the given ordinal in the StatefulSet with the given name.
+func IngesterVolumeClaimName(statefulSet string, ordinal int32) string {
+	return fmt.Sprintf("%s-%s-%d", dataVolumeName, statefulSet, ordinal)
+}
+
 // NewIngestorStatefulSet creates a new StatefulSet for the Thanos Receive ingester.
 func NewIngestorStatefulSet(opts IngesterOptions) *appsv1.StatefulSet {
 	selectorLabels := opts.GetSelectorLabels()
@@ -141,9 +167,10 @@ func newIngestorStatefulSet(opts IngesterOptions, selectorLabels, objectMetaLabe
 	vc := []corev1.PersistentVolumeClaim{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      dataVolumeName,
-				Namespace: opts.Namespace,
-				Labels:    objectMetaLabels,
+				Name:        dataVolumeName,
+				Namespace:   opts.Namespace,
+				Labels:      objectMetaLabels,
+				Annotations: opts.Backup.GetVolumeAnnotations(),
 			},
 			Spec: corev1.PersistentVolumeClaimSpec{
 				AccessModes: []corev1.PersistentVolumeAccessMode{
@@ -167,7 +194,7 @@ func newIngestorStatefulSet(opts IngesterOptions, selectorLabels, objectMetaLabe
 			Name:        name,
 			Namespace:   opts.Namespace,
 			Labels:      objectMetaLabels,
-			Annotations: opts.Annotations,
+			Annotations: opts.GetWorkloadAnnotations(),
 		},
 		Spec: appsv1.StatefulSetSpec{
 			ServiceName: name,
@@ -178,11 +205,12 @@ func newIngestorStatefulSet(opts IngesterOptions, selectorLabels, objectMetaLabe
 			VolumeClaimTemplates: vc,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: objectMetaLabels,
+					Labels:      objectMetaLabels,
+					Annotations: opts.Backup.GetPodAnnotations(IngestComponentName, dataVolumeName),
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: name,
-					SecurityContext:    &corev1.PodSecurityContext{},
+					SecurityContext:    manifests.DefaultPodSecurityContext(),
 					Containers: []corev1.Container{
 						{
 							Image:           opts.GetContainerImage(),
@@ -190,20 +218,12 @@ func newIngestorStatefulSet(opts IngesterOptions, selectorLabels, objectMetaLabe
 							ImagePullPolicy: corev1.PullAlways,
 							// Ensure restrictive context for the container
 							// More info: https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted
-							SecurityContext: &corev1.SecurityContext{
-								AllowPrivilegeEscalation: ptr.To(false),
-								RunAsNonRoot:             ptr.To(true),
-								Capabilities: &corev1.Capabilities{
-									Drop: []corev1.Capability{
-										"ALL",
-									},
-								},
-							},
+							SecurityContext: manifests.DefaultSecurityContext(),
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/ready",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 								InitialDelaySeconds: 20,
@@ -216,7 +236,7 @@ func newIngestorStatefulSet(opts IngesterOptions, selectorLabels, objectMetaLabe
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/healthy",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 								InitialDelaySeconds: 60,
@@ -263,12 +283,12 @@ func newIngestorStatefulSet(opts IngesterOptions, selectorLabels, objectMetaLabe
 							},
 							Ports: []corev1.ContainerPort{
 								{
-									ContainerPort: GRPCPort,
+									ContainerPort: opts.GetGRPCPort(GRPCPort),
 									Name:          GRPCPortName,
 								},
 								{
-									ContainerPort: HTTPPort,
-									Name:          HTTPPortName,
+									ContainerPort: opts.GetHTTPPort(HTTPPort),
+									Name:          opts.GetHTTPPortName(HTTPPortName),
 								},
 								{
 									ContainerPort: RemoteWritePort,
@@ -291,22 +311,24 @@ func newIngestorStatefulSet(opts IngesterOptions, selectorLabels, objectMetaLabe
 // NewIngestorService creates a new Service for the Thanos Receive ingester.
 func NewIngestorService(opts IngesterOptions) *corev1.Service {
 	selectorLabels := opts.GetSelectorLabels()
-	svc := newService(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, manifests.MergeLabels(opts.Labels, selectorLabels), opts.Annotations)
+	objectMetaLabels := manifests.MergeLabels(opts.StoreAPIServiceLabels, GetIngesterLabels(opts))
+	svc := newService(opts.GetGeneratedResourceName(), opts.Options, selectorLabels, objectMetaLabels)
 	svc.Spec.ClusterIP = corev1.ClusterIPNone
 
-	if opts.Additional.ServicePorts != nil {
-		svc.Spec.Ports = append(svc.Spec.Ports, opts.Additional.ServicePorts...)
+	if ports := manifests.AdditionalServicePorts(opts.Additional); ports != nil {
+		svc.Spec.Ports = append(svc.Spec.Ports, ports...)
 	}
 
 	return svc
 }
 
 func newIngestorService(opts IngesterOptions, selectorLabels, objectMetaLabels map[string]string) *corev1.Service {
-	svc := newService(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, objectMetaLabels, opts.Annotations)
+	objectMetaLabels = manifests.MergeLabels(opts.StoreAPIServiceLabels, objectMetaLabels)
+	svc := newService(opts.GetGeneratedResourceName(), opts.Options, selectorLabels, objectMetaLabels)
 	svc.Spec.ClusterIP = corev1.ClusterIPNone
 
-	if opts.Additional.ServicePorts != nil {
-		svc.Spec.Ports = append(svc.Spec.Ports, opts.Additional.ServicePorts...)
+	if ports := manifests.AdditionalServicePorts(opts.Additional); ports != nil {
+		svc.Spec.Ports = append(svc.Spec.Ports, ports...)
 	}
 
 	return svc
@@ -320,26 +342,26 @@ func NewRouterService(opts RouterOptions) *corev1.Service {
 }
 
 func newRouterService(opts RouterOptions, selectorLabels, objectMetaLabels map[string]string) *corev1.Service {
-	svc := newService(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, objectMetaLabels, opts.Annotations)
-	if opts.Additional.ServicePorts != nil {
-		svc.Spec.Ports = append(svc.Spec.Ports, opts.Additional.ServicePorts...)
+	svc := newService(opts.GetGeneratedResourceName(), opts.Options, selectorLabels, objectMetaLabels)
+	if ports := manifests.AdditionalServicePorts(opts.Additional); ports != nil {
+		svc.Spec.Ports = append(svc.Spec.Ports, ports...)
 	}
 	return svc
 }
 
 // newService creates a new Service for the Thanos Receive components.
-func newService(name, namespace string, selectorLabels, objectMetaLabels map[string]string, annotations map[string]string) *corev1.Service {
+func newService(name string, opts manifests.Options, selectorLabels, objectMetaLabels map[string]string) *corev1.Service {
 	servicePorts := []corev1.ServicePort{
 		{
 			Name:       GRPCPortName,
-			Port:       GRPCPort,
-			TargetPort: intstr.FromInt32(GRPCPort),
+			Port:       opts.GetGRPCPort(GRPCPort),
+			TargetPort: intstr.FromInt32(opts.GetGRPCPort(GRPCPort)),
 			Protocol:   "TCP",
 		},
 		{
-			Name:       HTTPPortName,
-			Port:       HTTPPort,
-			TargetPort: intstr.FromInt32(HTTPPort),
+			Name:       opts.GetHTTPPortName(HTTPPortName),
+			Port:       opts.GetHTTPPort(HTTPPort),
+			TargetPort: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 			Protocol:   "TCP",
 		},
 		{
@@ -357,9 +379,9 @@ func newService(name, namespace string, selectorLabels, objectMetaLabels map[str
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
-			Namespace:   namespace,
+			Namespace:   opts.Namespace,
 			Labels:      objectMetaLabels,
-			Annotations: annotations,
+			Annotations: opts.Annotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Selector: selectorLabels,
@@ -392,7 +414,7 @@ func newRouterDeployment(opts RouterOptions, selectorLabels, objectMetaLabels ma
 			Name:        name,
 			Namespace:   opts.Namespace,
 			Labels:      objectMetaLabels,
-			Annotations: opts.Annotations,
+			Annotations: opts.GetWorkloadAnnotations(),
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: ptr.To(opts.Replicas),
@@ -406,7 +428,7 @@ func newRouterDeployment(opts RouterOptions, selectorLabels, objectMetaLabels ma
 					Labels:    objectMetaLabels,
 				},
 				Spec: corev1.PodSpec{
-					SecurityContext: &corev1.PodSecurityContext{},
+					SecurityContext: manifests.DefaultPodSecurityContext(),
 					Volumes: []corev1.Volume{
 						{
 							Name: hashringVolumeName,
@@ -427,20 +449,12 @@ func newRouterDeployment(opts RouterOptions, selectorLabels, objectMetaLabels ma
 							ImagePullPolicy: corev1.PullAlways,
 							// Ensure restrictive context for the container
 							// More info: https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted
-							SecurityContext: &corev1.SecurityContext{
-								RunAsNonRoot:             ptr.To(true),
-								AllowPrivilegeEscalation: ptr.To(false),
-								Capabilities: &corev1.Capabilities{
-									Drop: []corev1.Capability{
-										"ALL",
-									},
-								},
-							},
+							SecurityContext: manifests.DefaultSecurityContext(),
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/ready",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 								InitialDelaySeconds: 5,
@@ -453,7 +467,7 @@ func newRouterDeployment(opts RouterOptions, selectorLabels, objectMetaLabels ma
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/healthy",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 								InitialDelaySeconds: 5,
@@ -488,12 +502,12 @@ func newRouterDeployment(opts RouterOptions, selectorLabels, objectMetaLabels ma
 							},
 							Ports: []corev1.ContainerPort{
 								{
-									ContainerPort: GRPCPort,
+									ContainerPort: opts.GetGRPCPort(GRPCPort),
 									Name:          GRPCPortName,
 								},
 								{
-									ContainerPort: HTTPPort,
-									Name:          HTTPPortName,
+									ContainerPort: opts.GetHTTPPort(HTTPPort),
+									Name:          opts.GetHTTPPortName(HTTPPortName),
 								},
 								{
 									ContainerPort: RemoteWritePort,
@@ -528,20 +542,19 @@ func ingestorArgsFrom(opts IngesterOptions) []string {
 	args = append(args, opts.ToFlags()...)
 
 	args = append(args,
-		fmt.Sprintf("--grpc-address=0.0.0.0:%d", GRPCPort),
-		fmt.Sprintf("--http-address=0.0.0.0:%d", HTTPPort),
+		fmt.Sprintf("--grpc-address=0.0.0.0:%d", opts.GetGRPCPort(GRPCPort)),
+		fmt.Sprintf("--http-address=0.0.0.0:%d", opts.GetHTTPPort(HTTPPort)),
 		fmt.Sprintf("--remote-write.address=0.0.0.0:%d", RemoteWritePort),
 		fmt.Sprintf("--tsdb.path=%s", dataVolumeMountPath),
 		fmt.Sprintf("--tsdb.retention=%s", opts.Retention),
 		fmt.Sprintf("--objstore.config=$(%s)", ingestObjectStoreEnvVarName),
 		fmt.Sprintf("--receive.local-endpoint=$(POD_NAME).%s.$(POD_NAMESPACE).svc.cluster.local:%d",
-			opts.GetGeneratedResourceName(), GRPCPort),
+			opts.GetGeneratedResourceName(), opts.GetGRPCPort(GRPCPort)),
 		"--receive.grpc-compression=none",
 	)
+	args = append(args, opts.GRPCServerFlags()...)
 
-	for k, v := range opts.ExternalLabels {
-		args = append(args, fmt.Sprintf(`--label=%s="%s"`, k, v))
-	}
+	args = append(args, manifests.ExternalLabelFlags(opts.ExternalLabels)...)
 
 	// TODO(saswatamcode): Add some validation.
 	if opts.Additional.Args != nil {
@@ -555,16 +568,17 @@ func routerArgsFrom(opts RouterOptions) []string {
 	args := []string{"receive"}
 	args = append(args, opts.ToFlags()...)
 	args = append(args,
-		fmt.Sprintf("--grpc-address=0.0.0.0:%d", GRPCPort),
-		fmt.Sprintf("--http-address=0.0.0.0:%d", HTTPPort),
+		fmt.Sprintf("--grpc-address=0.0.0.0:%d", opts.GetGRPCPort(GRPCPort)),
+		fmt.Sprintf("--http-address=0.0.0.0:%d", opts.GetHTTPPort(HTTPPort)),
 		fmt.Sprintf("--remote-write.address=0.0.0.0:%d", RemoteWritePort),
 		fmt.Sprintf("--receive.replication-factor=%d", opts.ReplicationFactor),
 		fmt.Sprintf("--receive.hashrings-algorithm=%s", opts.HashringAlgorithm),
 		fmt.Sprintf("--receive.hashrings-file=%s/%s", hashringMountPath, HashringConfigKey),
 	)
-	for k, v := range opts.ExternalLabels {
-		args = append(args, fmt.Sprintf(`--label=%s="%s"`, k, v))
+	if opts.LimitsConfig != "" {
+		args = append(args, fmt.Sprintf("--receive.limits-config-file=%s/%s", hashringMountPath, LimitsConfigKey))
 	}
+	args = append(args, manifests.ExternalLabelFlags(opts.ExternalLabels)...)
 
 	// TODO(saswatamcode): Add some validation.
 	if opts.Additional.Args != nil {
@@ -595,6 +609,113 @@ func newHashringConfigMap(name, namespace, contents string, objectMetaLabels map
 	}
 }
 
+// QuorumMaxUnavailable returns the maximum number of ingesters in a hashring that can be unavailable
+// while still guaranteeing write quorum for every series under the given replication factor.
+// Thanos Receive requires (replicationFactor/2)+1 successful writes, so up to (replicationFactor-1)/2
+// replicas of any series may be lost. Without replication there is no quorum to preserve, so this returns 1.
+func QuorumMaxUnavailable(replicationFactor int32) int32 {
+	if replicationFactor <= 1 {
+		return 1
+	}
+	return (replicationFactor - 1) / 2
+}
+
+// LimitsConfig are the write limits enforced by the router.
+type LimitsConfig struct {
+	// MaxConcurrency is the maximum number of concurrent remote write requests.
+	MaxConcurrency *int32
+	// MetaMonitoringURL is the URL queried for the number of active series of the tenants.
+	MetaMonitoringURL string
+	// MetaMonitoringLimitQuery is the query returning the number of active series per tenant.
+	MetaMonitoringLimitQuery string
+	// Default are the limits of the tenants not listed in Tenants.
+	Default *TenantWriteLimits
+	// Tenants are the limits per tenant ID.
+	Tenants map[string]TenantWriteLimits
+}
+
+// TenantWriteLimits are the write limits for a single tenant.
+type TenantWriteLimits struct {
+	// Request are the per request limits.
+	Request *TenantLimits
+	// HeadSeriesLimit is the maximum number of active series of the tenant.
+	HeadSeriesLimit *int64
+}
+
+// TenantLimits are the per request write limits for a single tenant.
+type TenantLimits struct {
+	SizeBytesLimit *int64 `yaml:"size_bytes_limit,omitempty"`
+	SeriesLimit    *int64 `yaml:"series_limit,omitempty"`
+	SamplesLimit   *int64 `yaml:"samples_limit,omitempty"`
+}
+
+type tenantLimitsConfig struct {
+	Request         *TenantLimits `yaml:"request,omitempty"`
+	HeadSeriesLimit *int64        `yaml:"head_series_limit,omitempty"`
+}
+
+type globalLimitsConfig struct {
+	MaxConcurrency           *int32 `yaml:"max_concurrency,omitempty"`
+	MetaMonitoringURL        string `yaml:"meta_monitoring_url,omitempty"`
+	MetaMonitoringLimitQuery string `yaml:"meta_monitoring_limit_query,omitempty"`
+}
+
+type writeLimitsConfig struct {
+	Global  *globalLimitsConfig           `yaml:"global,omitempty"`
+	Default *tenantLimitsConfig           `yaml:"default,omitempty"`
+	Tenants map[string]tenantLimitsConfig `yaml:"tenants,omitempty"`
+}
+
+type limitsConfig struct {
+	Write writeLimitsConfig `yaml:"write"`
+}
+
+func (l TenantWriteLimits) config() tenantLimitsConfig {
+	return tenantLimitsConfig{Request: l.Request, HeadSeriesLimit: l.HeadSeriesLimit}
+}
+
+// BuildLimitsConfig renders the limits configuration for the router from the given per tenant request limits.
+// It returns an empty string if no tenant limits are given.
+// See BuildLimitsConfigFrom for the global, default and active series limits.
+func BuildLimitsConfig(tenants map[string]TenantLimits) (string, error) {
+	in := LimitsConfig{Tenants: make(map[string]TenantWriteLimits, len(tenants))}
+	for tenant, limits := range tenants {
+		in.Tenants[tenant] = TenantWriteLimits{Request: &limits}
+	}
+	return BuildLimitsConfigFrom(in)
+}
+
+// BuildLimitsConfigFrom renders the limits configuration for the router.
+// It returns an empty string if no limits are given.
+func BuildLimitsConfigFrom(in LimitsConfig) (string, error) {
+	var conf limitsConfig
+	if in.MaxConcurrency != nil || in.MetaMonitoringURL != "" || in.MetaMonitoringLimitQuery != "" {
+		conf.Write.Global = &globalLimitsConfig{
+			MaxConcurrency:           in.MaxConcurrency,
+			MetaMonitoringURL:        in.MetaMonitoringURL,
+			MetaMonitoringLimitQuery: in.MetaMonitoringLimitQuery,
+		}
+	}
+	if in.Default != nil {
+		conf.Write.Default = ptr.To(in.Default.config())
+	}
+	if len(in.Tenants) > 0 {
+		conf.Write.Tenants = make(map[string]tenantLimitsConfig, len(in.Tenants))
+		for tenant, limits := range in.Tenants {
+			conf.Write.Tenants[tenant] = limits.config()
+		}
+	}
+	if conf.Write.Global == nil && conf.Write.Default == nil && conf.Write.Tenants == nil {
+		return "", nil
+	}
+
+	b, err := yaml.Marshal(conf)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal limits config: %w", err)
+	}
+	return string(b), nil
+}
+
 // GetRequiredLabels returns a map of labels that can be used to look up thanos receive resources.
 // These labels are guaranteed to be present on all resources created by this package.
 func GetRequiredLabels() map[string]string {
@@ -621,8 +742,11 @@ func (opts IngesterOptions) GetSelectorLabels() map[string]string {
 }
 
 func GetIngesterLabels(opts IngesterOptions) map[string]string {
-	l := opts.GetSelectorLabels()
-	return manifests.SanitizeStoreAPIEndpointLabels(manifests.MergeLabels(opts.Labels, l))
+	l := manifests.MergeLabels(opts.Labels, opts.GetSelectorLabels())
+	if opts.EndpointType != "" {
+		return manifests.SetStoreAPIEndpointType(l, opts.EndpointType)
+	}
+	return manifests.SanitizeStoreAPIEndpointLabels(l)
 }
 
 // GetRequiredRouterLabels returns a map of labels that can be used to look up thanos receive router resources.
@@ -645,9 +769,9 @@ func GetRouterLabels(opts RouterOptions) map[string]string {
 	return manifests.MergeLabels(opts.Labels, l)
 }
 
-func serviceMonitorOpts(from manifests.ServiceMonitorConfig) manifests.ServiceMonitorOptions {
+func serviceMonitorOpts(from manifests.Options) manifests.ServiceMonitorOptions {
 	return manifests.ServiceMonitorOptions{
-		Port:     ptr.To(HTTPPortName),
-		Interval: from.Interval,
+		Port:     ptr.To(from.GetHTTPPortName(HTTPPortName)),
+		Interval: from.ServiceMonitorConfig.Interval,
 	}
 }
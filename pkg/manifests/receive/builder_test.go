@@ -1,10 +1,12 @@
 package receive
 
 import (
+	"fmt"
 	"reflect"
+	"slices"
 	"testing"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	corev1 "k8s.io/api/core/v1"
@@ -424,3 +426,155 @@ func TestNewRouterService(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildRouterWithLimits(t *testing.T) {
+	limits, err := BuildLimitsConfig(map[string]TenantLimits{
+		"team-a": {
+			SeriesLimit:  ptr.To(int64(1000)),
+			SamplesLimit: ptr.To(int64(5000)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error building limits config: %v", err)
+	}
+
+	expectLimits := `write:
+  tenants:
+    team-a:
+      request:
+        series_limit: 1000
+        samples_limit: 5000
+`
+	if limits != expectLimits {
+		t.Fatalf("expected limits config %q, got %q", expectLimits, limits)
+	}
+
+	opts := RouterOptions{
+		Options: manifests.Options{
+			Owner:     "any",
+			Namespace: "ns",
+		},
+		LimitsConfig: limits,
+	}
+
+	objs := opts.Build()
+	cm, ok := objs[3].(*corev1.ConfigMap)
+	if !ok {
+		t.Fatalf("expected object to be a ConfigMap, got %T", objs[3])
+	}
+	if cm.Data[LimitsConfigKey] != limits {
+		t.Errorf("expected ConfigMap to contain limits config %q, got %q", limits, cm.Data[LimitsConfigKey])
+	}
+	if cm.Data[HashringConfigKey] != EmptyHashringConfig {
+		t.Errorf("expected ConfigMap to contain empty hashring config, got %q", cm.Data[HashringConfigKey])
+	}
+
+	args := NewRouterDeployment(opts).Spec.Template.Spec.Containers[0].Args
+	expectArg := "--receive.limits-config-file=var/lib/thanos-receive/limits.yaml"
+	if !slices.Contains(args, expectArg) {
+		t.Errorf("expected router args to contain %s, got %v", expectArg, args)
+	}
+
+	if noLimits, _ := BuildLimitsConfig(nil); noLimits != "" {
+		t.Errorf("expected empty limits config when no limits are given, got %q", noLimits)
+	}
+}
+
+func TestBuildLimitsConfigFrom(t *testing.T) {
+	limits, err := BuildLimitsConfigFrom(LimitsConfig{
+		MaxConcurrency:    ptr.To(int32(30)),
+		MetaMonitoringURL: "http://prometheus:9090",
+		Default: &TenantWriteLimits{
+			Request:         &TenantLimits{SizeBytesLimit: ptr.To(int64(1 << 20))},
+			HeadSeriesLimit: ptr.To(int64(100000)),
+		},
+		Tenants: map[string]TenantWriteLimits{
+			"team-a": {HeadSeriesLimit: ptr.To(int64(500000))},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error building limits config: %v", err)
+	}
+
+	expectLimits := `write:
+  global:
+    max_concurrency: 30
+    meta_monitoring_url: http://prometheus:9090
+  default:
+    request:
+      size_bytes_limit: 1048576
+    head_series_limit: 100000
+  tenants:
+    team-a:
+      head_series_limit: 500000
+`
+	if limits != expectLimits {
+		t.Errorf("expected limits config %q, got %q", expectLimits, limits)
+	}
+
+	if noLimits, _ := BuildLimitsConfigFrom(LimitsConfig{}); noLimits != "" {
+		t.Errorf("expected empty limits config when no limits are given, got %q", noLimits)
+	}
+}
+
+func TestQuorumMaxUnavailable(t *testing.T) {
+	for _, tc := range []struct {
+		replicationFactor int32
+		expect            int32
+	}{
+		{replicationFactor: 1, expect: 1},
+		{replicationFactor: 3, expect: 1},
+		{replicationFactor: 5, expect: 2},
+	} {
+		if got := QuorumMaxUnavailable(tc.replicationFactor); got != tc.expect {
+			t.Errorf("expected max unavailable %d for replication factor %d, got %d", tc.expect, tc.replicationFactor, got)
+		}
+	}
+}
+
+func TestIngesterStoreAPIServiceLabels(t *testing.T) {
+	opts := IngesterOptions{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+		HashringName: "test-hashring",
+		StoreAPIServiceLabels: map[string]string{
+			"query-layer":                  "recent",
+			manifests.DefaultStoreAPILabel: "expect-to-be-discarded",
+		},
+	}
+
+	objs := opts.Build()
+	utils.ValidateObjectsEqual(t, objs[1], NewIngestorService(opts))
+	utils.ValidateHasLabels(t, objs[1], GetRequiredIngesterLabels())
+	utils.ValidateHasLabels(t, objs[1], map[string]string{"query-layer": "recent"})
+
+	if _, ok := objs[2].GetLabels()["query-layer"]; ok {
+		t.Errorf("expected store service labels to be set on the service only, got %v", objs[2].GetLabels())
+	}
+}
+
+func TestIngesterListenPorts(t *testing.T) {
+	opts := IngesterOptions{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+			ListenPorts: &manifests.ListenPortOptions{
+				GRPC: ptr.To(int32(20901)),
+			},
+		},
+		HashringName: "test-hashring",
+	}
+
+	args := NewIngestorStatefulSet(opts).Spec.Template.Spec.Containers[0].Args
+	for _, arg := range []string{
+		"--grpc-address=0.0.0.0:20901",
+		fmt.Sprintf("--http-address=0.0.0.0:%d", HTTPPort),
+		fmt.Sprintf("--receive.local-endpoint=$(POD_NAME).%s.$(POD_NAMESPACE).svc.cluster.local:20901", opts.GetGeneratedResourceName()),
+	} {
+		if !slices.Contains(args, arg) {
+			t.Errorf("expected arg %s, got %v", arg, args)
+		}
+	}
+}
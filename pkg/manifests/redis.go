@@ -0,0 +1,118 @@
+package manifests
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// RedisCacheConfig is the configuration of a Redis cache.
+type RedisCacheConfig struct {
+	// Addresses of the Redis servers in host:port format.
+	// More than one address are the seed nodes of a Redis Cluster, unless MasterName is set.
+	Addresses []string
+	// MasterName is the name of the master of a Redis Sentinel deployment.
+	MasterName string
+	// Username to authenticate to Redis with ACLs.
+	Username string
+	// Password selects the password in a Secret.
+	// It is exposed to the container in an environment variable and substituted into the configuration,
+	// so it must not contain characters requiring quoting in YAML.
+	Password *corev1.SecretKeySelector
+	// DB is the database to select after connecting.
+	DB int32
+	// TLS enables TLS on the connections to Redis if set.
+	TLS *TLSOptions
+}
+
+type redisCacheConfig struct {
+	Type   string            `yaml:"type"`
+	Config redisClientConfig `yaml:"config"`
+}
+
+type redisClientConfig struct {
+	Addr       string          `yaml:"addr"`
+	Username   string          `yaml:"username,omitempty"`
+	Password   string          `yaml:"password,omitempty"`
+	DB         int32           `yaml:"db,omitempty"`
+	MasterName string          `yaml:"master_name,omitempty"`
+	TLSEnabled bool            `yaml:"tls_enabled,omitempty"`
+	TLSConfig  *redisTLSConfig `yaml:"tls_config,omitempty"`
+}
+
+type redisTLSConfig struct {
+	CAFile             string `yaml:"ca_file,omitempty"`
+	CertFile           string `yaml:"cert_file,omitempty"`
+	KeyFile            string `yaml:"key_file,omitempty"`
+	ServerName         string `yaml:"server_name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// Config returns the Thanos cache configuration of the Redis cache called name.
+// The name must be unique among the caches of a component, since it names the environment variable
+// holding the password and the directories the TLS Secrets are mounted into by AddToPodSpec.
+func (rc RedisCacheConfig) Config(name string) string {
+	conf := redisCacheConfig{
+		Type: "REDIS",
+		Config: redisClientConfig{
+			Addr:       strings.Join(rc.Addresses, ","),
+			Username:   rc.Username,
+			DB:         rc.DB,
+			MasterName: rc.MasterName,
+		},
+	}
+	if rc.Password != nil {
+		conf.Config.Password = fmt.Sprintf("$(%s)", redisPasswordEnvVarName(name))
+	}
+	if t := rc.TLS; t != nil {
+		conf.Config.TLSEnabled = true
+		conf.Config.TLSConfig = &redisTLSConfig{
+			ServerName:         t.ServerName,
+			InsecureSkipVerify: t.InsecureSkipVerify,
+		}
+		volume := redisTLSVolumeName(name)
+		if t.CertSecret != "" {
+			conf.Config.TLSConfig.CertFile = path.Join(tlsMountPath, volume, "cert", corev1.TLSCertKey)
+			conf.Config.TLSConfig.KeyFile = path.Join(tlsMountPath, volume, "cert", corev1.TLSPrivateKeyKey)
+		}
+		if t.CA != nil {
+			conf.Config.TLSConfig.CAFile = path.Join(tlsMountPath, volume, "ca", t.CA.Key)
+		}
+	}
+	out, err := yaml.Marshal(conf)
+	if err != nil {
+		// marshalling a struct of strings, booleans and numbers does not fail
+		panic(err)
+	}
+	return string(out)
+}
+
+// AddToPodSpec adds the password environment variable and the TLS Secret volumes of the Redis cache called name
+// to the first container of the Pod. It must be called for each Redis cache rendered with Config.
+func (rc RedisCacheConfig) AddToPodSpec(spec *corev1.PodSpec, name string) {
+	if rc.Password != nil {
+		spec.Containers[0].Env = append(spec.Containers[0].Env, corev1.EnvVar{
+			Name: redisPasswordEnvVarName(name),
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: rc.Password.LocalObjectReference,
+					Key:                  rc.Password.Key,
+					Optional:             ptr.To(false),
+				},
+			},
+		})
+	}
+	addTLSVolumes(spec, redisTLSVolumeName(name), rc.TLS)
+}
+
+func redisPasswordEnvVarName(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_REDIS_PASSWORD"
+}
+
+func redisTLSVolumeName(name string) string {
+	return name + "-redis-tls"
+}
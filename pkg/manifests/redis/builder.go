@@ -0,0 +1,224 @@
+// Package redis builds a single Redis instance without persistence, deployed by the operator
+// to serve as a cache for non-critical caching of Thanos components.
+package redis
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Name is the name of the managed Redis component.
+	Name = "redis"
+
+	// ComponentName is the name of the managed Redis component.
+	ComponentName = "cache"
+
+	// DefaultImage is the Redis image used if none is set.
+	DefaultImage = "docker.io/library/redis:7.2-alpine"
+
+	Port     = 6379
+	PortName = "redis"
+
+	// redisUID is the ID of the redis user of the official Redis images.
+	redisUID        = 999
+	dataVolumeName  = "data"
+	dataVolumeMount = "/data"
+)
+
+// Options for a managed Redis.
+type Options struct {
+	// Owner is the name of the resource the Redis is a cache of.
+	Owner string
+	// Cache is the name of the cache the Redis serves, e.g. index-cache.
+	// It distinguishes the Redis instances of an owner.
+	Cache     string
+	Namespace string
+	Labels    map[string]string
+	// Image is the Redis container image. DefaultImage is used if not set.
+	Image     string
+	Resources *corev1.ResourceRequirements
+	// MaxMemory is the number of bytes Redis uses for the cache, beyond which the least recently used keys are evicted.
+	// Redis does not limit its memory if zero.
+	MaxMemory int64
+}
+
+// Build builds the Deployment and the Service of the Redis.
+func (opts Options) Build() []client.Object {
+	selectorLabels := opts.GetSelectorLabels()
+	objectMetaLabels := GetLabels(opts)
+	return []client.Object{
+		newDeployment(opts, selectorLabels, objectMetaLabels),
+		newService(opts, selectorLabels, objectMetaLabels),
+	}
+}
+
+// GetGeneratedResourceName returns the name of the resources of the Redis of the cache of the owner.
+func (opts Options) GetGeneratedResourceName() string {
+	return manifests.ValidateAndSanitizeResourceName(fmt.Sprintf("%s-%s-%s", Name, opts.Owner, opts.Cache))
+}
+
+// GetAddress returns the address of the Redis in host:port format.
+func (opts Options) GetAddress() string {
+	return fmt.Sprintf("%s.%s.svc:%d", opts.GetGeneratedResourceName(), opts.Namespace, Port)
+}
+
+func newDeployment(opts Options, selectorLabels, objectMetaLabels map[string]string) *appsv1.Deployment {
+	image := opts.Image
+	if image == "" {
+		image = DefaultImage
+	}
+	args := []string{
+		"redis-server",
+		// the cache is not persisted, restarts start with an empty cache
+		"--save", "",
+		"--appendonly", "no",
+		"--maxmemory-policy", "allkeys-lru",
+	}
+	if opts.MaxMemory > 0 {
+		args = append(args, "--maxmemory", strconv.FormatInt(opts.MaxMemory, 10))
+	}
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(PortName)},
+		},
+		PeriodSeconds: 10,
+	}
+
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opts.GetGeneratedResourceName(),
+			Namespace: opts.Namespace,
+			Labels:    objectMetaLabels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(1)),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorLabels,
+			},
+			// a second instance would serve an empty cache and double the memory during the rollout
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: objectMetaLabels,
+				},
+				Spec: corev1.PodSpec{
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:      ptr.To(int64(redisUID)),
+						RunAsGroup:     ptr.To(int64(redisUID)),
+						FSGroup:        ptr.To(int64(redisUID)),
+						SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            Name,
+							Image:           image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Args:            args,
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          PortName,
+									ContainerPort: Port,
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: ptr.To(false),
+								RunAsNonRoot:             ptr.To(true),
+								ReadOnlyRootFilesystem:   ptr.To(true),
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{
+										"ALL",
+									},
+								},
+							},
+							LivenessProbe:            probe,
+							ReadinessProbe:           probe,
+							Resources:                ptr.Deref(opts.Resources, corev1.ResourceRequirements{}),
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      dataVolumeName,
+									MountPath: dataVolumeMount,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: dataVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	return deployment
+}
+
+func newService(opts Options, selectorLabels, objectMetaLabels map[string]string) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opts.GetGeneratedResourceName(),
+			Namespace: opts.Namespace,
+			Labels:    objectMetaLabels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selectorLabels,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       PortName,
+					Port:       Port,
+					TargetPort: intstr.FromString(PortName),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
+
+// GetRequiredLabels returns a map of labels that can be used to look up managed Redis resources.
+// These labels are guaranteed to be present on all resources created by this package.
+func GetRequiredLabels() map[string]string {
+	return map[string]string{
+		manifests.NameLabel:      Name,
+		manifests.ComponentLabel: ComponentName,
+		manifests.PartOfLabel:    manifests.DefaultPartOfLabel,
+		manifests.ManagedByLabel: manifests.DefaultManagedByLabel,
+	}
+}
+
+// GetSelectorLabels returns a map of labels that can be used to look up the resources of the Redis.
+func (opts Options) GetSelectorLabels() map[string]string {
+	labels := GetRequiredLabels()
+	labels[manifests.InstanceLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.GetGeneratedResourceName())
+	labels[manifests.OwnerLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.Owner)
+	return labels
+}
+
+// GetLabels returns the labels that will be set as ObjectMeta labels for the resources of the Redis.
+func GetLabels(opts Options) map[string]string {
+	return manifests.MergeLabels(opts.Labels, opts.GetSelectorLabels())
+}
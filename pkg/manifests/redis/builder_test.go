@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuild(t *testing.T) {
+	opts := Options{
+		Owner:     "store",
+		Cache:     "index-cache",
+		Namespace: "ns",
+		Labels:    map[string]string{"team": "a", manifests.NameLabel: "expect-to-be-discarded"},
+		MaxMemory: 256 << 20,
+	}
+	if name := opts.GetGeneratedResourceName(); name != "redis-store-index-cache" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if addr := opts.GetAddress(); addr != "redis-store-index-cache.ns.svc:6379" {
+		t.Errorf("unexpected address %s", addr)
+	}
+
+	objs := opts.Build()
+	if len(objs) != 2 {
+		t.Fatalf("expected a Deployment and a Service, got %d objects", len(objs))
+	}
+	deployment, ok := objs[0].(*appsv1.Deployment)
+	if !ok {
+		t.Fatalf("expected a Deployment, got %T", objs[0])
+	}
+	if deployment.Labels["team"] != "a" || deployment.Labels[manifests.NameLabel] != Name {
+		t.Errorf("unexpected labels %v", deployment.Labels)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.Image != DefaultImage {
+		t.Errorf("expected default image, got %s", container.Image)
+	}
+	if i := slices.Index(container.Args, "--maxmemory"); i < 0 || container.Args[i+1] != "268435456" {
+		t.Errorf("expected the memory to be limited, got %v", container.Args)
+	}
+	if !slices.Contains(container.Args, "allkeys-lru") {
+		t.Errorf("expected least recently used keys to be evicted, got %v", container.Args)
+	}
+
+	svc, ok := objs[1].(*corev1.Service)
+	if !ok {
+		t.Fatalf("expected a Service, got %T", objs[1])
+	}
+	if svc.Spec.Selector[manifests.InstanceLabel] != "redis-store-index-cache" || svc.Spec.Ports[0].Port != Port {
+		t.Errorf("unexpected service spec %v", svc.Spec)
+	}
+}
@@ -0,0 +1,61 @@
+package manifests
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRedisCacheConfig(t *testing.T) {
+	plain := RedisCacheConfig{Addresses: []string{"redis-0:6379", "redis-1:6379"}}
+	expect := `type: REDIS
+config:
+  addr: redis-0:6379,redis-1:6379
+`
+	if got := plain.Config("index-cache"); got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+
+	rc := RedisCacheConfig{
+		Addresses:  []string{"sentinel:26379"},
+		MasterName: "mymaster",
+		Username:   "thanos",
+		Password:   &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "redis"}, Key: "password"},
+		DB:         2,
+		TLS: &TLSOptions{
+			CertSecret: "redis-client",
+			CA:         &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "redis-ca"}, Key: "ca.crt"},
+			ServerName: "redis.example.com",
+		},
+	}
+	expect = `type: REDIS
+config:
+  addr: sentinel:26379
+  username: thanos
+  password: $(INDEX_CACHE_REDIS_PASSWORD)
+  db: 2
+  master_name: mymaster
+  tls_enabled: true
+  tls_config:
+    ca_file: /etc/thanos/tls/index-cache-redis-tls/ca/ca.crt
+    cert_file: /etc/thanos/tls/index-cache-redis-tls/cert/tls.crt
+    key_file: /etc/thanos/tls/index-cache-redis-tls/cert/tls.key
+    server_name: redis.example.com
+`
+	if got := rc.Config("index-cache"); got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+
+	spec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "thanos"}}}
+	rc.AddToPodSpec(spec, "index-cache")
+	env := spec.Containers[0].Env
+	if len(env) != 1 || env[0].Name != "INDEX_CACHE_REDIS_PASSWORD" || env[0].ValueFrom.SecretKeyRef.Name != "redis" {
+		t.Errorf("expected the password to be exposed in an environment variable, got %v", env)
+	}
+	if len(spec.Volumes) != 2 || spec.Volumes[0].Name != "index-cache-redis-tls-cert" || spec.Volumes[1].Name != "index-cache-redis-tls-ca" {
+		t.Errorf("expected the TLS Secrets to be mounted, got %v", spec.Volumes)
+	}
+	if mounts := spec.Containers[0].VolumeMounts; len(mounts) != 2 || mounts[1].MountPath != "/etc/thanos/tls/index-cache-redis-tls/ca" {
+		t.Errorf("unexpected volume mounts %v", mounts)
+	}
+}
@@ -1,6 +1,7 @@
 package ruler
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 
@@ -13,8 +14,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestsstore "github.com/thanos-community/thanos-operator/internal/pkg/manifests/store"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestsstore "github.com/thanos-community/thanos-operator/pkg/manifests/store"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -45,6 +46,11 @@ type Options struct {
 	AlertLabelDrop     []string
 	StorageSize        resource.Quantity
 	EvaluationInterval manifests.Duration
+	// RoutePrefix is the prefix under which the web UI and API are served.
+	RoutePrefix string
+	// StoreAPIServiceLabels are additional labels added only to the Store API Service.
+	// The labels required for discovery take precedence over labels set here.
+	StoreAPIServiceLabels map[string]string
 }
 
 // Endpoint represents a single QueryAPI DNS formatted address.
@@ -53,6 +59,9 @@ type Endpoint struct {
 	ServiceName string
 	Namespace   string
 	Port        int32
+	// HTTPPortName is the name of the HTTP port of the Service, used to discover the Queriers through DNS SRV records.
+	// Defaults to http.
+	HTTPPortName string
 }
 
 func (opts Options) Build() []client.Object {
@@ -61,7 +70,7 @@ func (opts Options) Build() []client.Object {
 	objectMetaLabels := GetLabels(opts)
 	name := opts.GetGeneratedResourceName()
 
-	objs = append(objs, manifests.BuildServiceAccount(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, opts.Annotations))
+	objs = append(objs, manifests.BuildServiceAccounts(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, opts.Annotations, opts.ServiceAccount)...)
 	objs = append(objs, newRulerStatefulSet(opts, selectorLabels, objectMetaLabels))
 	objs = append(objs, newRulerService(opts, selectorLabels, objectMetaLabels))
 
@@ -71,7 +80,7 @@ func (opts Options) Build() []client.Object {
 
 	if opts.ServiceMonitorConfig.Enabled {
 		smLabels := manifests.MergeLabels(opts.ServiceMonitorConfig.Labels, objectMetaLabels)
-		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, selectorLabels, smLabels, serviceMonitorOpts(opts.ServiceMonitorConfig)))
+		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, smLabels, selectorLabels, serviceMonitorOpts(opts.Options)))
 	}
 	return objs
 }
@@ -136,20 +145,12 @@ func newRulerStatefulSet(opts Options, selectorLabels, objectMetaLabels map[stri
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		// Ensure restrictive context for the container
 		// More info: https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted
-		SecurityContext: &corev1.SecurityContext{
-			AllowPrivilegeEscalation: ptr.To(false),
-			RunAsNonRoot:             ptr.To(true),
-			Capabilities: &corev1.Capabilities{
-				Drop: []corev1.Capability{
-					"ALL",
-				},
-			},
-		},
+		SecurityContext: manifests.DefaultSecurityContext(),
 		ReadinessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path:   "/-/ready",
-					Port:   intstr.FromInt32(HTTPPort),
+					Port:   intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 					Scheme: corev1.URISchemeHTTP,
 				},
 			},
@@ -163,7 +164,7 @@ func newRulerStatefulSet(opts Options, selectorLabels, objectMetaLabels map[stri
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path: "/-/healthy",
-					Port: intstr.FromInt32(HTTPPort),
+					Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 				},
 			},
 			InitialDelaySeconds: 30,
@@ -197,12 +198,12 @@ func newRulerStatefulSet(opts Options, selectorLabels, objectMetaLabels map[stri
 		VolumeMounts: volumeMounts,
 		Ports: []corev1.ContainerPort{
 			{
-				ContainerPort: GRPCPort,
+				ContainerPort: opts.GetGRPCPort(GRPCPort),
 				Name:          GRPCPortName,
 			},
 			{
-				ContainerPort: HTTPPort,
-				Name:          HTTPPortName,
+				ContainerPort: opts.GetHTTPPort(HTTPPort),
+				Name:          opts.GetHTTPPortName(HTTPPortName),
 			},
 		},
 		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
@@ -252,7 +253,7 @@ func newRulerStatefulSet(opts Options, selectorLabels, objectMetaLabels map[stri
 			Name:        name,
 			Namespace:   opts.Namespace,
 			Labels:      objectMetaLabels,
-			Annotations: opts.Annotations,
+			Annotations: opts.GetWorkloadAnnotations(),
 		},
 		Spec: appsv1.StatefulSetSpec{
 			Replicas:             &opts.Replicas,
@@ -266,7 +267,7 @@ func newRulerStatefulSet(opts Options, selectorLabels, objectMetaLabels map[stri
 				},
 				Spec: corev1.PodSpec{
 					Affinity:           &podAffinity,
-					SecurityContext:    &corev1.PodSecurityContext{},
+					SecurityContext:    manifests.DefaultPodSecurityContext(),
 					Containers:         []corev1.Container{rulerContainer},
 					ServiceAccountName: name,
 					Volumes:            volumes,
@@ -282,22 +283,22 @@ func newRulerStatefulSet(opts Options, selectorLabels, objectMetaLabels map[stri
 
 func NewRulerService(opts Options) *corev1.Service {
 	selectorLabels := opts.GetSelectorLabels()
-	objectMetaLabels := GetLabels(opts)
+	objectMetaLabels := manifests.MergeLabels(opts.StoreAPIServiceLabels, GetLabels(opts))
 	servicePorts := []corev1.ServicePort{
 		{
 			Name:       GRPCPortName,
-			Port:       GRPCPort,
-			TargetPort: intstr.FromInt32(GRPCPort),
+			Port:       opts.GetGRPCPort(GRPCPort),
+			TargetPort: intstr.FromInt32(opts.GetGRPCPort(GRPCPort)),
 		},
 		{
-			Name:       HTTPPortName,
-			Port:       HTTPPort,
-			TargetPort: intstr.FromInt32(HTTPPort),
+			Name:       opts.GetHTTPPortName(HTTPPortName),
+			Port:       opts.GetHTTPPort(HTTPPort),
+			TargetPort: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 		},
 	}
 
-	if opts.Additional.ServicePorts != nil {
-		servicePorts = append(servicePorts, opts.Additional.ServicePorts...)
+	if ports := manifests.AdditionalServicePorts(opts.Additional); ports != nil {
+		servicePorts = append(servicePorts, ports...)
 	}
 
 	return &corev1.Service{
@@ -320,21 +321,22 @@ func NewRulerService(opts Options) *corev1.Service {
 }
 
 func newRulerService(opts Options, selectorLabels, objectMetaLabels map[string]string) *corev1.Service {
+	objectMetaLabels = manifests.MergeLabels(opts.StoreAPIServiceLabels, objectMetaLabels)
 	servicePorts := []corev1.ServicePort{
 		{
 			Name:       GRPCPortName,
-			Port:       GRPCPort,
-			TargetPort: intstr.FromInt32(GRPCPort),
+			Port:       opts.GetGRPCPort(GRPCPort),
+			TargetPort: intstr.FromInt32(opts.GetGRPCPort(GRPCPort)),
 		},
 		{
-			Name:       HTTPPortName,
-			Port:       HTTPPort,
-			TargetPort: intstr.FromInt32(HTTPPort),
+			Name:       opts.GetHTTPPortName(HTTPPortName),
+			Port:       opts.GetHTTPPort(HTTPPort),
+			TargetPort: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 		},
 	}
 
-	if opts.Additional.ServicePorts != nil {
-		servicePorts = append(servicePorts, opts.Additional.ServicePorts...)
+	if ports := manifests.AdditionalServicePorts(opts.Additional); ports != nil {
+		servicePorts = append(servicePorts, ports...)
 	}
 
 	return &corev1.Service{
@@ -360,8 +362,8 @@ func rulerArgs(opts Options) []string {
 	args := []string{"rule"}
 	args = append(args, opts.ToFlags()...)
 	args = append(args,
-		fmt.Sprintf("--http-address=0.0.0.0:%d", HTTPPort),
-		fmt.Sprintf("--grpc-address=0.0.0.0:%d", GRPCPort),
+		fmt.Sprintf("--http-address=0.0.0.0:%d", opts.GetHTTPPort(HTTPPort)),
+		fmt.Sprintf("--grpc-address=0.0.0.0:%d", opts.GetGRPCPort(GRPCPort)),
 		fmt.Sprintf("--tsdb.retention=%s", string(opts.Retention)),
 		"--data-dir=/var/thanos/rule",
 		fmt.Sprintf("--objstore.config=$(%s)", rulerObjectStoreEnvVarName),
@@ -372,16 +374,18 @@ func rulerArgs(opts Options) []string {
 		args = append(args, fmt.Sprintf("--eval-interval=%s", string(opts.EvaluationInterval)))
 	}
 
-	for key, val := range opts.ExternalLabels {
-		args = append(args, fmt.Sprintf("--label=%s=\"%s\"", key, val))
+	if opts.RoutePrefix != "" {
+		args = append(args, fmt.Sprintf("--web.route-prefix=%s", opts.RoutePrefix))
 	}
 
+	args = append(args, manifests.ExternalLabelFlags(opts.ExternalLabels)...)
+
 	for _, ruleFile := range opts.RuleFiles {
 		args = append(args, fmt.Sprintf("--rule-file=%s", fmt.Sprintf("/etc/thanos/rules/%s", ruleFile.Key)))
 	}
 
 	for _, endpoint := range opts.Endpoints {
-		args = append(args, fmt.Sprintf("--query=dnssrv+_http._tcp.%s.%s.svc.cluster.local", endpoint.ServiceName, endpoint.Namespace))
+		args = append(args, fmt.Sprintf("--query=dnssrv+_%s._tcp.%s.%s.svc.cluster.local", cmp.Or(endpoint.HTTPPortName, "http"), endpoint.ServiceName, endpoint.Namespace))
 	}
 
 	for _, label := range opts.AlertLabelDrop {
@@ -419,10 +423,10 @@ func GetLabels(opts Options) map[string]string {
 	return manifests.SanitizeStoreAPIEndpointLabels(manifests.MergeLabels(lbls, manifestsstore.GetRequiredStoreServiceLabel()))
 }
 
-func serviceMonitorOpts(from manifests.ServiceMonitorConfig) manifests.ServiceMonitorOptions {
+func serviceMonitorOpts(from manifests.Options) manifests.ServiceMonitorOptions {
 	return manifests.ServiceMonitorOptions{
-		Port:     ptr.To(HTTPPortName),
-		Interval: from.Interval,
+		Port:     ptr.To(from.GetHTTPPortName(HTTPPortName)),
+		Interval: from.ServiceMonitorConfig.Interval,
 	}
 }
 
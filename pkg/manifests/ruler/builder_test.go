@@ -2,6 +2,8 @@ package ruler
 
 import (
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
@@ -9,8 +11,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	manifestsstore "github.com/thanos-community/thanos-operator/internal/pkg/manifests/store"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestsstore "github.com/thanos-community/thanos-operator/pkg/manifests/store"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -415,3 +417,64 @@ func TestGenerateRuleFileContent(t *testing.T) {
 		})
 	}
 }
+
+func TestRulerRoutePrefix(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+	}
+	if slices.ContainsFunc(rulerArgs(opts), func(arg string) bool { return strings.HasPrefix(arg, "--web.route-prefix") }) {
+		t.Errorf("expected no route prefix flag when unset")
+	}
+
+	opts.RoutePrefix = "/prefix/test"
+	if !slices.Contains(rulerArgs(opts), "--web.route-prefix=/prefix/test") {
+		t.Errorf("expected route prefix flag, got %v", rulerArgs(opts))
+	}
+}
+
+func TestRulerQueryEndpointPortName(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+		Endpoints: []Endpoint{
+			{ServiceName: "thanos-query-a", Namespace: "ns"},
+			{ServiceName: "thanos-query-b", Namespace: "ns", HTTPPortName: "http-metrics"},
+		},
+	}
+	args := rulerArgs(opts)
+	for _, want := range []string{
+		"--query=dnssrv+_http._tcp.thanos-query-a.ns.svc.cluster.local",
+		"--query=dnssrv+_http-metrics._tcp.thanos-query-b.ns.svc.cluster.local",
+	} {
+		if !slices.Contains(args, want) {
+			t.Errorf("expected %s in args %v", want, args)
+		}
+	}
+}
+
+func TestRulerStoreAPIServiceLabels(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+		StoreAPIServiceLabels: map[string]string{
+			"query-layer":                  "rules",
+			manifests.DefaultStoreAPILabel: "expect-to-be-discarded",
+		},
+	}
+
+	svc := NewRulerService(opts)
+	utils.ValidateHasLabels(t, svc, GetRequiredLabels())
+	utils.ValidateHasLabels(t, svc, map[string]string{"query-layer": "rules"})
+
+	sts := NewRulerStatefulSet(opts)
+	if _, ok := sts.GetLabels()["query-layer"]; ok {
+		t.Errorf("expected store service labels to be set on the service only, got %v", sts.GetLabels())
+	}
+}
@@ -0,0 +1,64 @@
+package manifests
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SchedulingOptions control the nodes the pods of a component are scheduled on.
+type SchedulingOptions struct {
+	// Affinity are the scheduling constraints of the pods.
+	// Each of node affinity, pod affinity and pod anti-affinity which is set replaces the one set by the builder, if any.
+	Affinity *corev1.Affinity
+	// Tolerations allow the pods to be scheduled on nodes with matching taints.
+	Tolerations []corev1.Toleration
+	// NodeSelector restricts the pods to nodes with matching labels.
+	NodeSelector map[string]string
+	// TopologySpreadConstraints control how the pods are spread across topology domains.
+	// Constraints without a label selector select the pods of the workload.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint
+}
+
+// applyScheduling applies the scheduling options to the Pod.
+// The selector of the workload is used for topology spread constraints without a label selector.
+func applyScheduling(spec *corev1.PodSpec, opts *SchedulingOptions, selector *metav1.LabelSelector) {
+	if opts == nil {
+		return
+	}
+
+	if opts.Affinity != nil {
+		affinity := &corev1.Affinity{}
+		if spec.Affinity != nil {
+			affinity = spec.Affinity.DeepCopy()
+		}
+		if opts.Affinity.NodeAffinity != nil {
+			affinity.NodeAffinity = opts.Affinity.NodeAffinity.DeepCopy()
+		}
+		if opts.Affinity.PodAffinity != nil {
+			affinity.PodAffinity = opts.Affinity.PodAffinity.DeepCopy()
+		}
+		if opts.Affinity.PodAntiAffinity != nil {
+			affinity.PodAntiAffinity = opts.Affinity.PodAntiAffinity.DeepCopy()
+		}
+		spec.Affinity = affinity
+	}
+
+	spec.Tolerations = append(spec.Tolerations, opts.Tolerations...)
+
+	if len(opts.NodeSelector) > 0 {
+		if spec.NodeSelector == nil {
+			spec.NodeSelector = make(map[string]string, len(opts.NodeSelector))
+		}
+		maps.Copy(spec.NodeSelector, opts.NodeSelector)
+	}
+
+	for _, tsc := range opts.TopologySpreadConstraints {
+		tsc = *tsc.DeepCopy()
+		if tsc.LabelSelector == nil && selector != nil {
+			tsc.LabelSelector = selector.DeepCopy()
+		}
+		spec.TopologySpreadConstraints = append(spec.TopologySpreadConstraints, tsc)
+	}
+}
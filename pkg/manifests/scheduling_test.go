@@ -0,0 +1,86 @@
+package manifests
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAugmentWithOptions_Scheduling(t *testing.T) {
+	defaultAntiAffinity := &corev1.PodAntiAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+			Weight:          100,
+			PodAffinityTerm: corev1.PodAffinityTerm{TopologyKey: "kubernetes.io/hostname"},
+		}},
+	}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{NameLabel: "thanos-query"}}
+
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Selector = selector
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "thanos"}}
+	deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: defaultAntiAffinity}
+
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "node-pool",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{"thanos"},
+				}},
+			}},
+		},
+	}
+	toleration := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "thanos", Effect: corev1.TaintEffectNoSchedule}
+	zoneSpread := corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       "topology.kubernetes.io/zone",
+		WhenUnsatisfiable: corev1.ScheduleAnyway,
+	}
+	customSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "custom"}}
+	hostSpread := corev1.TopologySpreadConstraint{
+		MaxSkew:           2,
+		TopologyKey:       "kubernetes.io/hostname",
+		WhenUnsatisfiable: corev1.DoNotSchedule,
+		LabelSelector:     customSelector,
+	}
+
+	AugmentWithOptions(deployment, Options{
+		Scheduling: &SchedulingOptions{
+			Affinity:                  &corev1.Affinity{NodeAffinity: nodeAffinity},
+			Tolerations:               []corev1.Toleration{toleration},
+			NodeSelector:              map[string]string{"kubernetes.io/os": "linux"},
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{zoneSpread, hostSpread},
+		},
+	})
+
+	spec := deployment.Spec.Template.Spec
+	if !reflect.DeepEqual(spec.Affinity.NodeAffinity, nodeAffinity) {
+		t.Errorf("expected node affinity to be set, got %v", spec.Affinity.NodeAffinity)
+	}
+	if !reflect.DeepEqual(spec.Affinity.PodAntiAffinity, defaultAntiAffinity) {
+		t.Errorf("expected default pod anti-affinity to be kept, got %v", spec.Affinity.PodAntiAffinity)
+	}
+	if !reflect.DeepEqual(spec.Tolerations, []corev1.Toleration{toleration}) {
+		t.Errorf("unexpected tolerations %v", spec.Tolerations)
+	}
+	if !reflect.DeepEqual(spec.NodeSelector, map[string]string{"kubernetes.io/os": "linux"}) {
+		t.Errorf("unexpected node selector %v", spec.NodeSelector)
+	}
+
+	zoneSpread.LabelSelector = selector
+	if !reflect.DeepEqual(spec.TopologySpreadConstraints, []corev1.TopologySpreadConstraint{zoneSpread, hostSpread}) {
+		t.Errorf("unexpected topology spread constraints %v", spec.TopologySpreadConstraints)
+	}
+
+	antiAffinity := &corev1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{TopologyKey: "kubernetes.io/hostname"}},
+	}
+	AugmentWithOptions(deployment, Options{Scheduling: &SchedulingOptions{Affinity: &corev1.Affinity{PodAntiAffinity: antiAffinity}}})
+	if !reflect.DeepEqual(deployment.Spec.Template.Spec.Affinity.PodAntiAffinity, antiAffinity) {
+		t.Errorf("expected pod anti-affinity to be replaced, got %v", deployment.Spec.Template.Spec.Affinity.PodAntiAffinity)
+	}
+}
@@ -0,0 +1,123 @@
+package manifests
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// DefaultPodSecurityContext returns the security context of the Pods of the Thanos components.
+// Together with DefaultSecurityContext, it complies with the restricted Pod Security Standard.
+// More info: https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted
+func DefaultPodSecurityContext() *corev1.PodSecurityContext {
+	return &corev1.PodSecurityContext{
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// DefaultSecurityContext returns the security context of the Thanos containers.
+func DefaultSecurityContext() *corev1.SecurityContext {
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		RunAsNonRoot:             ptr.To(true),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{
+				"ALL",
+			},
+		},
+	}
+}
+
+// applySecurityContext merges the given security contexts into the security context of the Pod
+// and of its Thanos container. Each field which is set replaces the one set by the builder.
+func applySecurityContext(spec *corev1.PodSpec, pod *corev1.PodSecurityContext, container *corev1.SecurityContext) {
+	if pod != nil {
+		if spec.SecurityContext == nil {
+			spec.SecurityContext = &corev1.PodSecurityContext{}
+		}
+		mergePodSecurityContext(spec.SecurityContext, pod.DeepCopy())
+	}
+	if container != nil && len(spec.Containers) > 0 {
+		if spec.Containers[0].SecurityContext == nil {
+			spec.Containers[0].SecurityContext = &corev1.SecurityContext{}
+		}
+		mergeSecurityContext(spec.Containers[0].SecurityContext, container.DeepCopy())
+	}
+}
+
+func mergePodSecurityContext(dst, src *corev1.PodSecurityContext) {
+	if src.SELinuxOptions != nil {
+		dst.SELinuxOptions = src.SELinuxOptions
+	}
+	if src.WindowsOptions != nil {
+		dst.WindowsOptions = src.WindowsOptions
+	}
+	if src.RunAsUser != nil {
+		dst.RunAsUser = src.RunAsUser
+	}
+	if src.RunAsGroup != nil {
+		dst.RunAsGroup = src.RunAsGroup
+	}
+	if src.RunAsNonRoot != nil {
+		dst.RunAsNonRoot = src.RunAsNonRoot
+	}
+	if src.SupplementalGroups != nil {
+		dst.SupplementalGroups = src.SupplementalGroups
+	}
+	if src.SupplementalGroupsPolicy != nil {
+		dst.SupplementalGroupsPolicy = src.SupplementalGroupsPolicy
+	}
+	if src.FSGroup != nil {
+		dst.FSGroup = src.FSGroup
+	}
+	if src.Sysctls != nil {
+		dst.Sysctls = src.Sysctls
+	}
+	if src.FSGroupChangePolicy != nil {
+		dst.FSGroupChangePolicy = src.FSGroupChangePolicy
+	}
+	if src.SeccompProfile != nil {
+		dst.SeccompProfile = src.SeccompProfile
+	}
+	if src.AppArmorProfile != nil {
+		dst.AppArmorProfile = src.AppArmorProfile
+	}
+}
+
+func mergeSecurityContext(dst, src *corev1.SecurityContext) {
+	if src.Capabilities != nil {
+		dst.Capabilities = src.Capabilities
+	}
+	if src.Privileged != nil {
+		dst.Privileged = src.Privileged
+	}
+	if src.SELinuxOptions != nil {
+		dst.SELinuxOptions = src.SELinuxOptions
+	}
+	if src.WindowsOptions != nil {
+		dst.WindowsOptions = src.WindowsOptions
+	}
+	if src.RunAsUser != nil {
+		dst.RunAsUser = src.RunAsUser
+	}
+	if src.RunAsGroup != nil {
+		dst.RunAsGroup = src.RunAsGroup
+	}
+	if src.RunAsNonRoot != nil {
+		dst.RunAsNonRoot = src.RunAsNonRoot
+	}
+	if src.ReadOnlyRootFilesystem != nil {
+		dst.ReadOnlyRootFilesystem = src.ReadOnlyRootFilesystem
+	}
+	if src.AllowPrivilegeEscalation != nil {
+		dst.AllowPrivilegeEscalation = src.AllowPrivilegeEscalation
+	}
+	if src.ProcMount != nil {
+		dst.ProcMount = src.ProcMount
+	}
+	if src.SeccompProfile != nil {
+		dst.SeccompProfile = src.SeccompProfile
+	}
+	if src.AppArmorProfile != nil {
+		dst.AppArmorProfile = src.AppArmorProfile
+	}
+}
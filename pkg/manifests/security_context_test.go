@@ -0,0 +1,47 @@
+package manifests
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestAugmentWithOptions_SecurityContext(t *testing.T) {
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.SecurityContext = DefaultPodSecurityContext()
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "thanos", SecurityContext: DefaultSecurityContext()}}
+
+	AugmentWithOptions(deployment, Options{})
+	spec := deployment.Spec.Template.Spec
+	if !reflect.DeepEqual(spec.SecurityContext, DefaultPodSecurityContext()) || !reflect.DeepEqual(spec.Containers[0].SecurityContext, DefaultSecurityContext()) {
+		t.Errorf("expected the default security contexts to be kept, got %v and %v", spec.SecurityContext, spec.Containers[0].SecurityContext)
+	}
+
+	override := &corev1.SecurityContext{ReadOnlyRootFilesystem: ptr.To(true), RunAsUser: ptr.To(int64(1000))}
+	AugmentWithOptions(deployment, Options{
+		PodSecurityContext: &corev1.PodSecurityContext{FSGroup: ptr.To(int64(2000))},
+		SecurityContext:    override,
+	})
+
+	wantPod := &corev1.PodSecurityContext{
+		FSGroup:        ptr.To(int64(2000)),
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	if got := deployment.Spec.Template.Spec.SecurityContext; !reflect.DeepEqual(got, wantPod) {
+		t.Errorf("expected pod security context %v, got %v", wantPod, got)
+	}
+	want := DefaultSecurityContext()
+	want.ReadOnlyRootFilesystem = ptr.To(true)
+	want.RunAsUser = ptr.To(int64(1000))
+	if got := deployment.Spec.Template.Spec.Containers[0].SecurityContext; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected container security context %v, got %v", want, got)
+	}
+
+	*override.RunAsUser = 0
+	if got := *deployment.Spec.Template.Spec.Containers[0].SecurityContext.RunAsUser; got != 1000 {
+		t.Errorf("expected the override to be copied, got user %d", got)
+	}
+}
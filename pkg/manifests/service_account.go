@@ -0,0 +1,59 @@
+package manifests
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ServiceAccountOptions configures the ServiceAccount of a component.
+type ServiceAccountOptions struct {
+	// Name is the name of an existing ServiceAccount the Pods run as.
+	// If set, no ServiceAccount is built.
+	Name string
+	// Labels are additional labels of the built ServiceAccount.
+	// The labels of the component take precedence over labels set here.
+	Labels map[string]string
+	// Annotations are additional annotations of the built ServiceAccount.
+	// They take precedence over the annotations of the component.
+	Annotations map[string]string
+}
+
+// BuildServiceAccount returns a new ServiceAccount from Options.
+func BuildServiceAccount(name, namespace string, labels, annotations map[string]string) client.Object {
+	return &corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ServiceAccount",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		AutomountServiceAccountToken: ptr.To(true),
+	}
+}
+
+// BuildServiceAccounts returns the ServiceAccount of a component, with the labels and annotations of the given options.
+// It returns no object if the component runs as an existing ServiceAccount.
+func BuildServiceAccounts(name, namespace string, labels, annotations map[string]string, opts *ServiceAccountOptions) []client.Object {
+	if opts == nil {
+		return []client.Object{BuildServiceAccount(name, namespace, labels, annotations)}
+	}
+	if opts.Name != "" {
+		return nil
+	}
+	return []client.Object{BuildServiceAccount(name, namespace, MergeLabels(opts.Labels, labels), MergeLabels(annotations, opts.Annotations))}
+}
+
+// applyServiceAccount sets the ServiceAccount of the Pod to the existing ServiceAccount of the given options, if any.
+func applyServiceAccount(spec *corev1.PodSpec, opts *ServiceAccountOptions) {
+	if opts == nil || opts.Name == "" {
+		return
+	}
+	spec.ServiceAccountName = opts.Name
+}
@@ -0,0 +1,89 @@
+package manifests
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuildServiceAccount(t *testing.T) {
+	const name = "thanos-stack"
+	opts := Options{
+		Namespace: "ns",
+		Labels: map[string]string{
+			"app.kubernetes.io/name":     "thanos",
+			"app.kubernetes.io/instance": "thanos-stack",
+		},
+		Annotations: map[string]string{
+			"test": "annotation",
+		},
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "test service account correctness",
+			opts: opts,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := BuildServiceAccount(name, tc.opts.Namespace, tc.opts.Labels, tc.opts.Annotations)
+			if sa.GetName() != name {
+				t.Errorf("expected service account name to be %s, got %s", name, sa.GetName())
+			}
+			if sa.GetNamespace() != tc.opts.Namespace {
+				t.Errorf("expected service account namespace to be %s, got %s", tc.opts.Namespace, sa.GetNamespace())
+			}
+			if len(sa.GetLabels()) != 2 {
+				t.Errorf("expected service account to have 2 labels, got %d", len(sa.GetLabels()))
+			}
+			if sa.GetLabels()["app.kubernetes.io/name"] != "thanos" {
+				t.Errorf("expected service account label app.kubernetes.io/name to be thanos, got %s", sa.GetLabels()["app.kubernetes.io/name"])
+			}
+			if sa.GetLabels()["app.kubernetes.io/instance"] != "thanos-stack" {
+				t.Errorf("expected service account label app.kubernetes.io/instance to be thanos-stack, got %s", sa.GetLabels()["app.kubernetes.io/instance"])
+			}
+			if len(sa.GetAnnotations()) != 1 {
+				t.Errorf("expected service account to have 1 annotation, got %d", len(sa.GetAnnotations()))
+			}
+			if sa.GetAnnotations()["test"] != "annotation" {
+				t.Errorf("expected service account annotation test to be annotation, got %s", sa.GetAnnotations()["test"])
+			}
+		})
+	}
+}
+
+func TestBuildServiceAccounts(t *testing.T) {
+	labels := map[string]string{"app.kubernetes.io/name": "thanos"}
+	annotations := map[string]string{"test": "annotation"}
+
+	objs := BuildServiceAccounts("thanos-stack", "ns", labels, annotations, &ServiceAccountOptions{
+		Labels:      map[string]string{"app.kubernetes.io/name": "other", "team": "a"},
+		Annotations: map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/thanos"},
+	})
+	if len(objs) != 1 {
+		t.Fatalf("expected a ServiceAccount, got %d objects", len(objs))
+	}
+	if want := map[string]string{"app.kubernetes.io/name": "thanos", "team": "a"}; !reflect.DeepEqual(objs[0].GetLabels(), want) {
+		t.Errorf("expected labels %v, got %v", want, objs[0].GetLabels())
+	}
+	if want := map[string]string{"test": "annotation", "eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/thanos"}; !reflect.DeepEqual(objs[0].GetAnnotations(), want) {
+		t.Errorf("expected annotations %v, got %v", want, objs[0].GetAnnotations())
+	}
+
+	existing := &ServiceAccountOptions{Name: "existing"}
+	if objs := BuildServiceAccounts("thanos-stack", "ns", labels, annotations, existing); len(objs) != 0 {
+		t.Errorf("expected no ServiceAccount with an existing ServiceAccount, got %d objects", len(objs))
+	}
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.ServiceAccountName = "thanos-stack"
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "thanos"}}
+	AugmentWithOptions(deployment, Options{ServiceAccount: existing})
+	if got := deployment.Spec.Template.Spec.ServiceAccountName; got != "existing" {
+		t.Errorf("expected the Pods to run as the existing ServiceAccount, got %s", got)
+	}
+}
@@ -73,7 +73,7 @@ func (opts ServiceMonitorOptions) applyDefaults() ServiceMonitorOptions {
 }
 
 func HasServiceMonitorEnabled(in *v1alpha1.FeatureGates) bool {
-	return in != nil && in.ServiceMonitorConfig != nil && *in.ServiceMonitorConfig.Enable
+	return in != nil && in.ServiceMonitorConfig != nil && ptr.Deref(in.ServiceMonitorConfig.Enable, true)
 }
 
 func HasPrometheusRuleEnabled(in *v1alpha1.FeatureGates) bool {
@@ -1,9 +1,13 @@
 package store
 
 import (
+	"cmp"
+	"crypto/sha256"
 	"fmt"
+	"strconv"
+	"strings"
 
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -30,6 +34,12 @@ const (
 	GRPCPortName = "grpc"
 	// GRPCPort is the port number for the gRPC port for the Thanos Store components.
 	GRPCPort = 10901
+
+	// RelabelConfigKey is the key in the ConfigMap of a shard for the block selector relabel configuration.
+	RelabelConfigKey = "relabel-config.yaml"
+	// RelabelConfigHashAnnotation is set on the pod template of a shard and holds a hash of its relabel configuration.
+	// Thanos Store only reads the relabel configuration on startup, so a changed configuration rolls out the pods of the shard.
+	RelabelConfigHashAnnotation = "monitoring.thanos.io/relabel-config-hash"
 )
 
 // Options for Thanos Store components
@@ -44,6 +54,30 @@ type Options struct {
 	Min, Max                 manifests.Duration
 	RelabelConfigs           manifests.RelabelConfigs
 	ShardIndex               *int32
+	RequestLoggingConfig     *manifests.RequestLoggingConfig
+	// Tier is the name of the time based tier this store belongs to.
+	// If set, the generated resource names are suffixed with the tier name.
+	Tier string
+	// StoreAPIServiceLabels are additional labels added only to the Store API Service.
+	// The labels required for discovery take precedence over labels set here.
+	StoreAPIServiceLabels map[string]string
+	// EndpointType is the type of endpoint advertised to Queriers through the endpoint label.
+	// If set, it takes precedence over any endpoint label set in Labels.
+	EndpointType manifests.EndpointType
+	// Backup configures how the persistent volumes of the Store Gateway participate in cluster backups.
+	Backup *manifests.BackupOptions
+	// StorageClassName is the StorageClass of the PersistentVolumeClaims of the Store Gateway.
+	// If nil, the default StorageClass of the cluster is used.
+	StorageClassName *string
+	// VolumeClaimLabels are additional labels added to the PersistentVolumeClaims of the Store Gateway.
+	// The labels of the Store Gateway take precedence over labels set here.
+	VolumeClaimLabels map[string]string
+	// VolumeClaimAnnotations are additional annotations added to the PersistentVolumeClaims of the Store Gateway.
+	// The backup annotations take precedence over annotations set here.
+	VolumeClaimAnnotations map[string]string
+	// VolumeClaimRetentionPolicy controls whether the PersistentVolumeClaims of the Store Gateway are deleted
+	// when the StatefulSet is scaled down or deleted. Claims are retained in both cases by default.
+	VolumeClaimRetentionPolicy *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy
 }
 
 // Build builds Thanos Store shards.
@@ -53,24 +87,33 @@ func (opts Options) Build() []client.Object {
 	objectMetaLabels := GetLabels(opts)
 	name := opts.GetGeneratedResourceName()
 
-	objs = append(objs, manifests.BuildServiceAccount(name, opts.Namespace, selectorLabels, opts.Annotations))
+	objs = append(objs, manifests.BuildServiceAccounts(name, opts.Namespace, selectorLabels, opts.Annotations, opts.ServiceAccount)...)
 	objs = append(objs, newStoreService(opts, selectorLabels, objectMetaLabels))
 	objs = append(objs, newStoreShardStatefulSet(opts, selectorLabels, objectMetaLabels))
 
+	if len(opts.RelabelConfigs) > 0 {
+		objs = append(objs, newRelabelConfigMap(opts, objectMetaLabels))
+	}
+
 	if opts.PodDisruptionConfig != nil {
 		objs = append(objs, manifests.NewPodDisruptionBudget(name, opts.Namespace, selectorLabels, objectMetaLabels, opts.Annotations, *opts.PodDisruptionConfig))
 	}
 
 	if opts.ServiceMonitorConfig.Enabled {
-		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, objectMetaLabels, selectorLabels, serviceMonitorOpts(opts.ServiceMonitorConfig)))
+		smLabels := manifests.MergeLabels(opts.ServiceMonitorConfig.Labels, objectMetaLabels)
+		objs = append(objs, manifests.BuildServiceMonitor(name, opts.Namespace, smLabels, selectorLabels, serviceMonitorOpts(opts.Options)))
 	}
 	return objs
 }
 
 // GetGeneratedResourceName returns the name of the Thanos Store component.
+// If a tier is provided, the name will be suffixed with the tier name.
 // If a shard index is provided, the name will be suffixed with the shard index.
 func (opts Options) GetGeneratedResourceName() string {
 	name := fmt.Sprintf("%s-%s", Name, opts.Owner)
+	if opts.Tier != "" {
+		name = fmt.Sprintf("%s-%s", name, opts.Tier)
+	}
 	if opts.ShardIndex == nil {
 		return manifests.ValidateAndSanitizeResourceName(name)
 	}
@@ -82,14 +125,20 @@ const (
 	indexCacheConfigEnvVarName    = "INDEX_CACHE_CONFIG"
 	cachingBucketConfigEnvVarName = "CACHING_BUCKET_CONFIG"
 
+	indexCacheName    = "index-cache"
+	cachingBucketName = "caching-bucket"
+
 	dataVolumeName      = "data"
 	dataVolumeMountPath = "var/thanos/store"
+
+	relabelConfigVolumeName = "relabel-config"
+	relabelConfigMountPath  = "/etc/thanos/relabel"
 )
 
 // NewStoreStatefulSet creates a new StatefulSet for the Thanos Store.
 func NewStoreStatefulSet(opts Options) *appsv1.StatefulSet {
 	selectorLabels := opts.GetSelectorLabels()
-	objectMetaLabels := manifests.MergeLabels(opts.Labels, selectorLabels)
+	objectMetaLabels := GetLabels(opts)
 	return newStoreShardStatefulSet(opts, selectorLabels, objectMetaLabels)
 }
 
@@ -98,11 +147,13 @@ func newStoreShardStatefulSet(opts Options, selectorLabels, objectMetaLabels map
 	vc := []corev1.PersistentVolumeClaim{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      dataVolumeName,
-				Namespace: opts.Namespace,
-				Labels:    objectMetaLabels,
+				Name:        dataVolumeName,
+				Namespace:   opts.Namespace,
+				Labels:      manifests.MergeLabels(opts.VolumeClaimLabels, objectMetaLabels),
+				Annotations: manifests.MergeLabels(opts.VolumeClaimAnnotations, opts.Backup.GetVolumeAnnotations()),
 			},
 			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: opts.StorageClassName,
 				AccessModes: []corev1.PersistentVolumeAccessMode{
 					corev1.ReadWriteOnce,
 				},
@@ -171,7 +222,7 @@ func newStoreShardStatefulSet(opts Options, selectorLabels, objectMetaLabels map
 			Name:        name,
 			Namespace:   opts.Namespace,
 			Labels:      objectMetaLabels,
-			Annotations: opts.Annotations,
+			Annotations: opts.GetWorkloadAnnotations(),
 		},
 		Spec: appsv1.StatefulSetSpec{
 			ServiceName: name,
@@ -179,13 +230,15 @@ func newStoreShardStatefulSet(opts Options, selectorLabels, objectMetaLabels map
 			Selector: &metav1.LabelSelector{
 				MatchLabels: selectorLabels,
 			},
-			VolumeClaimTemplates: vc,
+			VolumeClaimTemplates:                 vc,
+			PersistentVolumeClaimRetentionPolicy: volumeClaimRetentionPolicy(opts.VolumeClaimRetentionPolicy),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: objectMetaLabels,
+					Labels:      objectMetaLabels,
+					Annotations: opts.Backup.GetPodAnnotations(Name, dataVolumeName),
 				},
 				Spec: corev1.PodSpec{
-					SecurityContext:    &corev1.PodSecurityContext{},
+					SecurityContext:    manifests.DefaultPodSecurityContext(),
 					ServiceAccountName: name,
 					Containers: []corev1.Container{
 						{
@@ -194,20 +247,12 @@ func newStoreShardStatefulSet(opts Options, selectorLabels, objectMetaLabels map
 							ImagePullPolicy: corev1.PullIfNotPresent,
 							// Ensure restrictive context for the container
 							// More info: https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted
-							SecurityContext: &corev1.SecurityContext{
-								AllowPrivilegeEscalation: ptr.To(false),
-								RunAsNonRoot:             ptr.To(true),
-								Capabilities: &corev1.Capabilities{
-									Drop: []corev1.Capability{
-										"ALL",
-									},
-								},
-							},
+							SecurityContext: manifests.DefaultSecurityContext(),
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/ready",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 								InitialDelaySeconds: 20,
@@ -220,7 +265,7 @@ func newStoreShardStatefulSet(opts Options, selectorLabels, objectMetaLabels map
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
 										Path: "/-/healthy",
-										Port: intstr.FromInt32(HTTPPort),
+										Port: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 									},
 								},
 								InitialDelaySeconds: 60,
@@ -238,12 +283,12 @@ func newStoreShardStatefulSet(opts Options, selectorLabels, objectMetaLabels map
 							},
 							Ports: []corev1.ContainerPort{
 								{
-									ContainerPort: GRPCPort,
+									ContainerPort: opts.GetGRPCPort(GRPCPort),
 									Name:          GRPCPortName,
 								},
 								{
-									ContainerPort: HTTPPort,
-									Name:          HTTPPortName,
+									ContainerPort: opts.GetHTTPPort(HTTPPort),
+									Name:          opts.GetHTTPPortName(HTTPPortName),
 								},
 							},
 							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
@@ -255,6 +300,15 @@ func newStoreShardStatefulSet(opts Options, selectorLabels, objectMetaLabels map
 			},
 		},
 	}
+	if opts.IndexCacheConfig.FromSecret == nil && opts.IndexCacheConfig.Redis != nil {
+		opts.IndexCacheConfig.Redis.AddToPodSpec(&sts.Spec.Template.Spec, indexCacheName)
+	}
+	if opts.CachingBucketConfig.FromSecret == nil && opts.CachingBucketConfig.Redis != nil {
+		opts.CachingBucketConfig.Redis.AddToPodSpec(&sts.Spec.Template.Spec, cachingBucketName)
+	}
+	if len(opts.RelabelConfigs) > 0 {
+		addRelabelConfigVolume(sts, name, opts.RelabelConfigs)
+	}
 	manifests.AugmentWithOptions(sts, opts.Options)
 	return sts
 }
@@ -266,10 +320,10 @@ func NewStoreService(opts Options) *corev1.Service {
 }
 
 func newStoreService(opts Options, selectorLabels, objectMetaLabels map[string]string) *corev1.Service {
-	svc := newService(opts, selectorLabels, objectMetaLabels)
+	svc := newService(opts, selectorLabels, manifests.MergeLabels(opts.StoreAPIServiceLabels, objectMetaLabels))
 	svc.Spec.ClusterIP = corev1.ClusterIPNone
-	if opts.Additional.ServicePorts != nil {
-		svc.Spec.Ports = append(svc.Spec.Ports, opts.Additional.ServicePorts...)
+	if ports := manifests.AdditionalServicePorts(opts.Additional); ports != nil {
+		svc.Spec.Ports = append(svc.Spec.Ports, ports...)
 	}
 
 	return svc
@@ -280,13 +334,13 @@ func newService(opts Options, selectorLabels, objectMetaLabels map[string]string
 	servicePorts := []corev1.ServicePort{
 		{
 			Name:       GRPCPortName,
-			Port:       GRPCPort,
-			TargetPort: intstr.FromInt32(GRPCPort),
+			Port:       opts.GetGRPCPort(GRPCPort),
+			TargetPort: intstr.FromInt32(opts.GetGRPCPort(GRPCPort)),
 		},
 		{
-			Name:       HTTPPortName,
-			Port:       HTTPPort,
-			TargetPort: intstr.FromInt32(HTTPPort),
+			Name:       opts.GetHTTPPortName(HTTPPortName),
+			Port:       opts.GetHTTPPort(HTTPPort),
+			TargetPort: intstr.FromInt32(opts.GetHTTPPort(HTTPPort)),
 		},
 	}
 
@@ -312,8 +366,8 @@ func storeArgsFrom(opts Options) []string {
 	args := []string{"store"}
 	args = append(args, opts.ToFlags()...)
 	args = append(args,
-		fmt.Sprintf("--grpc-address=0.0.0.0:%d", GRPCPort),
-		fmt.Sprintf("--http-address=0.0.0.0:%d", HTTPPort),
+		fmt.Sprintf("--grpc-address=0.0.0.0:%d", opts.GetGRPCPort(GRPCPort)),
+		fmt.Sprintf("--http-address=0.0.0.0:%d", opts.GetHTTPPort(HTTPPort)),
 		fmt.Sprintf("--objstore.config=$(%s)", storeObjectStoreEnvVarName),
 		"--data-dir=/var/thanos/store",
 		fmt.Sprintf("--ignore-deletion-marks-delay=%s", string(opts.IgnoreDeletionMarksDelay)),
@@ -323,18 +377,33 @@ func storeArgsFrom(opts Options) []string {
 
 	if opts.IndexCacheConfig.FromSecret != nil {
 		args = append(args, fmt.Sprintf("--index-cache.config=$(%s)", indexCacheConfigEnvVarName))
+	} else if opts.IndexCacheConfig.Redis != nil {
+		args = append(args, fmt.Sprintf("--index-cache.config=%s", opts.IndexCacheConfig.Redis.Config(indexCacheName)))
+	} else if opts.IndexCacheConfig.Memcached != nil {
+		args = append(args, fmt.Sprintf("--index-cache.config=%s", opts.IndexCacheConfig.Memcached.Config()))
 	} else if opts.IndexCacheConfig.InMemoryCacheConfig != nil {
 		args = append(args, fmt.Sprintf("--index-cache.config=%s", opts.IndexCacheConfig.InMemoryCacheConfig.String()))
 	}
 
 	if opts.CachingBucketConfig.FromSecret != nil {
 		args = append(args, fmt.Sprintf("--store.caching-bucket.config=$(%s)", cachingBucketConfigEnvVarName))
+	} else if opts.CachingBucketConfig.Redis != nil {
+		args = append(args, fmt.Sprintf("--store.caching-bucket.config=%s", opts.CachingBucketConfig.Redis.Config(cachingBucketName)))
+	} else if opts.CachingBucketConfig.Memcached != nil {
+		args = append(args, fmt.Sprintf("--store.caching-bucket.config=%s", opts.CachingBucketConfig.Memcached.Config()))
 	} else if opts.CachingBucketConfig.InMemoryCacheConfig != nil {
 		args = append(args, fmt.Sprintf("--store.caching-bucket.config=%s", opts.CachingBucketConfig.InMemoryCacheConfig.String()))
 	}
 
 	if len(opts.RelabelConfigs) > 0 {
-		args = append(args, opts.RelabelConfigs.ToFlags())
+		args = append(args, fmt.Sprintf("--selector.relabel-config-file=%s/%s", relabelConfigMountPath, RelabelConfigKey))
+	}
+
+	args = append(args, opts.GRPCServerTLSFlags()...)
+	args = append(args, opts.GRPCServerFlags()...)
+
+	if opts.RequestLoggingConfig != nil {
+		args = append(args, opts.RequestLoggingConfig.ToFlags())
 	}
 
 	// TODO(saswatamcode): Add some validation.
@@ -345,6 +414,53 @@ func storeArgsFrom(opts Options) []string {
 	return manifests.PruneEmptyArgs(args)
 }
 
+// newRelabelConfigMap creates the ConfigMap holding the block selector relabel configuration of the shard.
+// It has the name of the shard.
+func newRelabelConfigMap(opts Options, objectMetaLabels map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opts.GetGeneratedResourceName(),
+			Namespace: opts.Namespace,
+			Labels:    objectMetaLabels,
+		},
+		Data: map[string]string{
+			RelabelConfigKey: relabelConfigFile(opts.RelabelConfigs),
+		},
+	}
+}
+
+// addRelabelConfigVolume mounts the relabel configuration ConfigMap of the shard into the Thanos Store container
+// and annotates the pod template with the hash of the configuration.
+func addRelabelConfigVolume(sts *appsv1.StatefulSet, name string, configs manifests.RelabelConfigs) {
+	spec := &sts.Spec.Template.Spec
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: relabelConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				DefaultMode:          ptr.To(int32(420)),
+			},
+		},
+	})
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      relabelConfigVolumeName,
+		MountPath: relabelConfigMountPath,
+		ReadOnly:  true,
+	})
+	if sts.Spec.Template.Annotations == nil {
+		sts.Spec.Template.Annotations = map[string]string{}
+	}
+	sts.Spec.Template.Annotations[RelabelConfigHashAnnotation] = fmt.Sprintf("%x", sha256.Sum256([]byte(relabelConfigFile(configs))))[:16]
+}
+
+func relabelConfigFile(configs manifests.RelabelConfigs) string {
+	return strings.TrimPrefix(configs.String(), "\n") + "\n"
+}
+
 // GetRequiredStoreServiceLabel returns the minimum set of labels that can be used to look up Services
 // that implement the Store API. Implementations of manifests.Buildable that provide Store API services
 // should include these labels in their Service ObjectMeta.
@@ -375,17 +491,42 @@ func (opts Options) GetSelectorLabels() map[string]string {
 }
 
 // GetLabels returns the labels that will be set as ObjectMeta labels for store resources.
+// Each shard with more than one replica is advertised as an endpoint group, so that Queriers
+// load balance across the replicas of the shard rather than fanning out to all of them.
 func GetLabels(opts Options) map[string]string {
 	lbls := manifests.MergeLabels(opts.Labels, opts.GetSelectorLabels())
 	if opts.Replicas > 1 {
 		lbls[string(manifests.GroupLabel)] = "true"
 	}
+	if opts.Tier != "" {
+		lbls[manifests.StoreTierLabel] = opts.Tier
+	}
+	if opts.ShardIndex != nil {
+		lbls[manifests.StoreShardLabel] = strconv.Itoa(int(*opts.ShardIndex))
+	}
+	if opts.EndpointType != "" {
+		return manifests.SetStoreAPIEndpointType(lbls, opts.EndpointType)
+	}
 	return manifests.SanitizeStoreAPIEndpointLabels(lbls)
 }
 
-func serviceMonitorOpts(from manifests.ServiceMonitorConfig) manifests.ServiceMonitorOptions {
+func serviceMonitorOpts(from manifests.Options) manifests.ServiceMonitorOptions {
 	return manifests.ServiceMonitorOptions{
-		Port:     ptr.To(HTTPPortName),
-		Interval: from.Interval,
+		Port:     ptr.To(from.GetHTTPPortName(HTTPPortName)),
+		Interval: from.ServiceMonitorConfig.Interval,
+	}
+}
+
+// volumeClaimRetentionPolicy returns the given policy with unset fields defaulted to retaining the claims.
+// The policy is always set, so that it does not differ from the policy defaulted by the API server.
+func volumeClaimRetentionPolicy(in *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy) *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy {
+	policy := &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+	if in != nil {
+		policy.WhenDeleted = cmp.Or(in.WhenDeleted, policy.WhenDeleted)
+		policy.WhenScaled = cmp.Or(in.WhenScaled, policy.WhenScaled)
 	}
+	return policy
 }
@@ -0,0 +1,454 @@
+package store
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/test/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	someCustomLabelValue string = "xyz"
+	someOtherLabelValue  string = "abc"
+)
+
+func TestBuildStore(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: "ns",
+			Image:     ptr.To("some-custom-image"),
+			Labels: map[string]string{
+				"some-custom-label":      someCustomLabelValue,
+				"some-other-label":       someOtherLabelValue,
+				"app.kubernetes.io/name": "expect-to-be-discarded",
+			},
+			Owner:               "any",
+			PodDisruptionConfig: &manifests.PodDisruptionBudgetOptions{},
+		},
+	}
+
+	objs := opts.Build()
+	if len(objs) != 4 {
+		t.Fatalf("expected 4 objects, got %d", len(objs))
+	}
+
+	utils.ValidateIsNamedServiceAccount(t, objs[0], opts, opts.Namespace)
+	utils.ValidateObjectsEqual(t, objs[1], NewStoreService(opts))
+	utils.ValidateObjectsEqual(t, objs[2], NewStoreStatefulSet(opts))
+	utils.ValidateIsNamedPodDisruptionBudget(t, objs[3], opts, opts.Namespace, objs[2])
+
+	wantLabels := opts.GetSelectorLabels()
+	wantLabels["some-custom-label"] = someCustomLabelValue
+	wantLabels["some-other-label"] = someOtherLabelValue
+	utils.ValidateObjectLabelsEqual(t, wantLabels, []client.Object{objs[1], objs[2]}...)
+}
+
+func TestNewStoreStatefulSet(t *testing.T) {
+	const (
+		owner = "test"
+		ns    = "ns"
+	)
+
+	extraLabels := map[string]string{
+		"some-custom-label": someCustomLabelValue,
+		"some-other-label":  someOtherLabelValue,
+	}
+
+	buildDefaultOpts := func() Options {
+		return Options{
+			Options: manifests.Options{
+				Owner:     owner,
+				Namespace: ns,
+				Image:     ptr.To("some-custom-image"),
+				Labels: map[string]string{
+					"some-custom-label":       someCustomLabelValue,
+					"some-other-label":        someOtherLabelValue,
+					"app.kubernetes.io/owner": "expect-to-be-discarded",
+				},
+				Annotations: map[string]string{
+					"test": "annotation",
+				},
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts func() Options
+	}{
+		{
+			name: "test store stateful correctness",
+			opts: func() Options {
+				return buildDefaultOpts()
+			},
+		},
+		{
+			name: "test additional volumemount",
+			opts: func() Options {
+				opts := buildDefaultOpts()
+				opts.Additional.VolumeMounts = []corev1.VolumeMount{
+					{
+						Name:      "test-sd",
+						MountPath: "/test-sd-file",
+					},
+				}
+				return opts
+			},
+		},
+		{
+			name: "test additional container",
+			opts: func() Options {
+				opts := buildDefaultOpts()
+				opts.Additional.Containers = []corev1.Container{
+					{
+						Name:  "test-container",
+						Image: "test-image:latest",
+						Args:  []string{"--test-arg"},
+						Env: []corev1.EnvVar{{
+							Name:  "TEST_ENV",
+							Value: "test",
+						}},
+					},
+				}
+				return opts
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			builtOpts := tc.opts()
+			store := NewStoreStatefulSet(builtOpts)
+			objectMetaLabels := GetLabels(builtOpts)
+			name := builtOpts.GetGeneratedResourceName()
+
+			utils.ValidateNameNamespaceAndLabels(t, store, name, ns, objectMetaLabels)
+			utils.ValidateHasLabels(t, store, builtOpts.GetSelectorLabels())
+			utils.ValidateHasLabels(t, store, extraLabels)
+			objName := builtOpts.GetGeneratedResourceName()
+
+			if store.Spec.ServiceName != objName {
+				t.Errorf("expected store statefulset to have serviceName %s, got %s", objName, store.Spec.ServiceName)
+			}
+
+			if store.Spec.Template.Spec.ServiceAccountName != objName {
+				t.Errorf("expected store statefulset to have service account owner %s, got %s", objName, store.Spec.Template.Spec.ServiceAccountName)
+			}
+
+			if len(store.Spec.Template.Spec.Containers) != (len(builtOpts.Additional.Containers) + 1) {
+				t.Errorf("expected store statefulset to have %d containers, got %d", len(builtOpts.Additional.Containers)+1, len(store.Spec.Template.Spec.Containers))
+			}
+
+			if store.Annotations["test"] != "annotation" {
+				t.Errorf("expected store statefulset annotation test to be annotation, got %s", store.Annotations["test"])
+			}
+
+			expectArgs := storeArgsFrom(builtOpts)
+			var found bool
+			for _, c := range store.Spec.Template.Spec.Containers {
+				if c.Name == Name {
+					found = true
+					if c.Image != builtOpts.GetContainerImage() {
+						t.Errorf("expected store statefulset to have image %s, got %s", builtOpts.GetContainerImage(), c.Image)
+					}
+
+					if !reflect.DeepEqual(c.Args, expectArgs) {
+						t.Errorf("expected store statefulset to have args %v, got %v", expectArgs, c.Args)
+					}
+
+					if len(c.VolumeMounts) != len(builtOpts.Additional.VolumeMounts)+1 {
+						if c.VolumeMounts[0].Name != dataVolumeName {
+							t.Errorf("expected store statefulset to have volumemount named data, got %s", c.VolumeMounts[0].Name)
+						}
+						if c.VolumeMounts[0].MountPath != dataVolumeMountPath {
+							t.Errorf("expected store statefulset to have volumemount mounted at var/thanos/store, got %s", c.VolumeMounts[0].MountPath)
+						}
+					}
+				}
+			}
+			if !found {
+				t.Errorf("expected store statefulset to have container named %s", Name)
+			}
+		})
+	}
+}
+
+func TestNewStoreService(t *testing.T) {
+	const (
+		ns = "ns"
+	)
+
+	extraLabels := map[string]string{
+		"some-custom-label":          someCustomLabelValue,
+		"some-other-label":           someOtherLabelValue,
+		string(manifests.GroupLabel): "true",
+	}
+
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: ns,
+			Image:     ptr.To("some-custom-image"),
+			Labels: map[string]string{
+				"some-custom-label":      someCustomLabelValue,
+				"some-other-label":       someOtherLabelValue,
+				"app.kubernetes.io/name": "expect-to-be-discarded",
+			},
+			Replicas: int32(3),
+		},
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts func() Options
+	}{
+		{
+			name: "test store service correctness",
+			opts: func() Options {
+				return opts
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			builtOpts := tc.opts()
+			storeSvc := NewStoreService(builtOpts)
+			objectMetaLabels := GetLabels(builtOpts)
+			utils.ValidateNameNamespaceAndLabels(t, storeSvc, opts.GetGeneratedResourceName(), ns, objectMetaLabels)
+			utils.ValidateHasLabels(t, storeSvc, extraLabels)
+			utils.ValidateHasLabels(t, storeSvc, opts.GetSelectorLabels())
+
+			if storeSvc.Spec.ClusterIP != corev1.ClusterIPNone {
+				t.Errorf("expected store service to have ClusterIP 'None', got %s", storeSvc.Spec.ClusterIP)
+			}
+		})
+	}
+}
+
+func TestStoreTier(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: "ns",
+			Owner:     "any",
+		},
+		Tier:       "hot",
+		ShardIndex: ptr.To(int32(1)),
+		Min:        "-2w",
+		Max:        "0",
+	}
+
+	if got := opts.GetGeneratedResourceName(); got != "thanos-store-any-hot-shard-1" {
+		t.Errorf("expected name thanos-store-any-hot-shard-1, got %s", got)
+	}
+
+	objs := opts.Build()
+	utils.ValidateHasLabels(t, objs[1], map[string]string{manifests.StoreTierLabel: "hot"})
+	utils.ValidateHasLabels(t, objs[2], map[string]string{manifests.StoreTierLabel: "hot"})
+	utils.ValidateHasLabels(t, objs[1], map[string]string{manifests.StoreShardLabel: "1"})
+	utils.ValidateHasLabels(t, objs[1], GetRequiredStoreServiceLabel())
+
+	sts := objs[2].(*appsv1.StatefulSet)
+	for _, arg := range []string{"--min-time=-2w", "--max-time=0"} {
+		if !slices.Contains(sts.Spec.Template.Spec.Containers[0].Args, arg) {
+			t.Errorf("expected arg %s to be set", arg)
+		}
+	}
+}
+
+func TestStoreRedisCache(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: "ns",
+			Owner:     "any",
+		},
+		IndexCacheConfig: manifests.CacheConfig{
+			Redis: &manifests.RedisCacheConfig{
+				Addresses: []string{"redis:6379"},
+				Password:  &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "redis"}, Key: "password"},
+			},
+		},
+		CachingBucketConfig: manifests.CacheConfig{
+			FromSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "memcached"}, Key: "config.yaml"},
+			Redis:      &manifests.RedisCacheConfig{Addresses: []string{"redis:6379"}},
+		},
+	}
+
+	container := NewStoreStatefulSet(opts).Spec.Template.Spec.Containers[0]
+	expect := "--index-cache.config=type: REDIS\nconfig:\n  addr: redis:6379\n  password: $(INDEX_CACHE_REDIS_PASSWORD)\n"
+	if !slices.Contains(container.Args, expect) {
+		t.Errorf("expected arg %q, got %v", expect, container.Args)
+	}
+	if !slices.Contains(container.Args, "--store.caching-bucket.config=$(CACHING_BUCKET_CONFIG)") {
+		t.Errorf("expected the external caching bucket config to take precedence, got %v", container.Args)
+	}
+	if !slices.ContainsFunc(container.Env, func(env corev1.EnvVar) bool { return env.Name == "INDEX_CACHE_REDIS_PASSWORD" }) {
+		t.Errorf("expected the redis password to be exposed, got %v", container.Env)
+	}
+}
+
+func TestStoreRelabelConfig(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Namespace: "ns",
+			Owner:     "any",
+		},
+		ShardIndex: ptr.To(int32(1)),
+		RelabelConfigs: manifests.RelabelConfigs{
+			{Action: "hashmod", SourceLabel: "__block_id", TargetLabel: "shard", Modulus: 2},
+			{Action: "keep", SourceLabel: "shard", Regex: "1"},
+		},
+	}
+
+	objs := opts.Build()
+	idx := slices.IndexFunc(objs, func(obj client.Object) bool { _, ok := obj.(*corev1.ConfigMap); return ok })
+	if idx < 0 {
+		t.Fatalf("expected a relabel config ConfigMap, got %v", objs)
+	}
+	cm := objs[idx].(*corev1.ConfigMap)
+	if cm.GetName() != opts.GetGeneratedResourceName() {
+		t.Errorf("expected the ConfigMap to have the name of the shard, got %s", cm.GetName())
+	}
+	expect := "- action: hashmod\n  source_labels: [\"__block_id\"]\n  target_label: shard\n  modulus: 2\n- action: keep\n  source_labels: [\"shard\"]\n  regex: 1\n"
+	if cm.Data[RelabelConfigKey] != expect {
+		t.Errorf("expected relabel config %q, got %q", expect, cm.Data[RelabelConfigKey])
+	}
+
+	sts := NewStoreStatefulSet(opts)
+	container := sts.Spec.Template.Spec.Containers[0]
+	if !slices.Contains(container.Args, "--selector.relabel-config-file=/etc/thanos/relabel/relabel-config.yaml") {
+		t.Errorf("expected the relabel config file arg, got %v", container.Args)
+	}
+	if !slices.ContainsFunc(container.VolumeMounts, func(vm corev1.VolumeMount) bool { return vm.MountPath == "/etc/thanos/relabel" }) {
+		t.Errorf("expected the relabel config to be mounted, got %v", container.VolumeMounts)
+	}
+	hash := sts.Spec.Template.Annotations[RelabelConfigHashAnnotation]
+	if hash == "" {
+		t.Fatal("expected the pod template to be annotated with the relabel config hash")
+	}
+
+	opts.RelabelConfigs[1].Regex = "0"
+	if NewStoreStatefulSet(opts).Spec.Template.Annotations[RelabelConfigHashAnnotation] == hash {
+		t.Error("expected the relabel config hash to change with the relabel config")
+	}
+
+	opts.RelabelConfigs = nil
+	if slices.ContainsFunc(opts.Build(), func(obj client.Object) bool { _, ok := obj.(*corev1.ConfigMap); return ok }) {
+		t.Error("expected no ConfigMap without relabel config")
+	}
+}
+
+func TestStoreAPIServiceLabels(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+		StoreAPIServiceLabels: map[string]string{
+			"query-layer":                  "long-term",
+			manifests.DefaultStoreAPILabel: "expect-to-be-discarded",
+		},
+	}
+
+	objs := opts.Build()
+	utils.ValidateObjectsEqual(t, objs[1], NewStoreService(opts))
+	utils.ValidateHasLabels(t, objs[1], GetRequiredStoreServiceLabel())
+	utils.ValidateHasLabels(t, objs[1], map[string]string{"query-layer": "long-term"})
+
+	if _, ok := objs[2].GetLabels()["query-layer"]; ok {
+		t.Errorf("expected store service labels to be set on the service only, got %v", objs[2].GetLabels())
+	}
+}
+
+func TestStoreEndpointType(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+			Replicas:  3,
+		},
+	}
+	if lbls := GetLabels(opts); lbls[string(manifests.GroupLabel)] != "true" {
+		t.Errorf("expected replicated store to default to group endpoint, got %v", lbls)
+	}
+
+	opts.EndpointType = manifests.GroupStrictLabel
+	lbls := NewStoreService(opts).GetLabels()
+	if lbls[string(manifests.GroupStrictLabel)] != "true" {
+		t.Errorf("expected store service to advertise group-strict endpoint, got %v", lbls)
+	}
+	if _, ok := lbls[string(manifests.GroupLabel)]; ok {
+		t.Errorf("expected explicit endpoint type to replace default, got %v", lbls)
+	}
+}
+
+func TestStoreListenPorts(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+			ListenPorts: &manifests.ListenPortOptions{
+				GRPC: ptr.To(int32(20901)),
+				HTTP: ptr.To(int32(20902)),
+			},
+		},
+	}
+
+	sts := NewStoreStatefulSet(opts)
+	container := sts.Spec.Template.Spec.Containers[0]
+	for _, arg := range []string{"--grpc-address=0.0.0.0:20901", "--http-address=0.0.0.0:20902"} {
+		if !slices.Contains(container.Args, arg) {
+			t.Errorf("expected arg %s, got %v", arg, container.Args)
+		}
+	}
+	if container.Ports[0].ContainerPort != 20901 || container.Ports[1].ContainerPort != 20902 {
+		t.Errorf("expected container ports to be overridden, got %v", container.Ports)
+	}
+	if container.ReadinessProbe.HTTPGet.Port.IntVal != 20902 || container.LivenessProbe.HTTPGet.Port.IntVal != 20902 {
+		t.Errorf("expected probes to use overridden HTTP port")
+	}
+
+	svc := NewStoreService(opts)
+	port, ok := manifests.IsGrpcServiceWithLabels(svc, GetRequiredStoreServiceLabel())
+	if !ok || port != 20901 {
+		t.Errorf("expected gRPC service port 20901, got %d", port)
+	}
+}
+
+func TestStoreVolumeClaims(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+		StorageClassName:       ptr.To("fast"),
+		VolumeClaimLabels:      map[string]string{"team": "observability", manifests.OwnerLabel: "expect-to-be-discarded"},
+		VolumeClaimAnnotations: map[string]string{"example.com/tier": "ssd"},
+	}
+
+	sts := NewStoreStatefulSet(opts)
+	if policy := sts.Spec.PersistentVolumeClaimRetentionPolicy; policy.WhenDeleted != appsv1.RetainPersistentVolumeClaimRetentionPolicyType ||
+		policy.WhenScaled != appsv1.RetainPersistentVolumeClaimRetentionPolicyType {
+		t.Errorf("expected claims to be retained by default, got %v", policy)
+	}
+	claim := sts.Spec.VolumeClaimTemplates[0]
+	if ptr.Deref(claim.Spec.StorageClassName, "") != "fast" {
+		t.Errorf("expected storage class fast, got %v", claim.Spec.StorageClassName)
+	}
+	utils.ValidateHasLabels(t, &claim, map[string]string{"team": "observability", manifests.OwnerLabel: "test"})
+	if claim.Annotations["example.com/tier"] != "ssd" {
+		t.Errorf("expected claim annotations to be set, got %v", claim.Annotations)
+	}
+
+	opts.VolumeClaimRetentionPolicy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenScaled: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+	}
+	policy := NewStoreStatefulSet(opts).Spec.PersistentVolumeClaimRetentionPolicy
+	if policy.WhenDeleted != appsv1.RetainPersistentVolumeClaimRetentionPolicyType ||
+		policy.WhenScaled != appsv1.DeletePersistentVolumeClaimRetentionPolicyType {
+		t.Errorf("expected claims to be deleted on scale down only, got %v", policy)
+	}
+}
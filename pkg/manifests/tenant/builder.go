@@ -0,0 +1,342 @@
+package tenant
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	manifestreceive "github.com/thanos-community/thanos-operator/pkg/manifests/receive"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Name is the name of the Thanos Tenant component.
+	Name = "thanos-tenant"
+
+	// ComponentName is the name of the Thanos Tenant component.
+	ComponentName = "thanos-tenant"
+
+	// TenantHeader is the HTTP header used by Thanos Receive to identify the tenant of a remote write request.
+	TenantHeader = "THANOS-TENANT"
+
+	// RemoteWriteConfigKey is the key in the published ConfigMap for the remote write configuration.
+	RemoteWriteConfigKey = "remote-write.yaml"
+
+	// DefaultRemoteWritePath is the path of the remote write endpoint of Thanos Receive.
+	DefaultRemoteWritePath = "/api/v1/receive"
+
+	// PrometheusRemoteWriteUsernameKey, PrometheusRemoteWritePasswordKey and PrometheusRemoteWriteCAKey are the keys
+	// of the credentials in the published Prometheus remote write Secret.
+	PrometheusRemoteWriteUsernameKey = "username"
+	PrometheusRemoteWritePasswordKey = "password"
+	PrometheusRemoteWriteCAKey       = "ca.crt"
+)
+
+const (
+	basicAuthTypeAnnotation   = "nginx.ingress.kubernetes.io/auth-type"
+	basicAuthSecretAnnotation = "nginx.ingress.kubernetes.io/auth-secret"
+	basicAuthRealmAnnotation  = "nginx.ingress.kubernetes.io/auth-realm"
+)
+
+// Options for a Thanos Tenant.
+type Options struct {
+	manifests.Options
+	// TenantID is the ID of the tenant.
+	TenantID string
+	// RouterService is the name of the Thanos Receive router Service the tenant writes to.
+	// It must exist in Options.Namespace.
+	RouterService string
+	// TargetNamespace is the namespace the remote write ConfigMap is published to.
+	TargetNamespace string
+	// Ingress configures a dedicated Ingress for the tenant if set.
+	Ingress *IngressOptions
+}
+
+// PrometheusRemoteWriteCredentials are the credentials published with the Prometheus remote write Secret of a tenant.
+type PrometheusRemoteWriteCredentials struct {
+	// Username and Password configure basic authentication if both are set.
+	Username []byte
+	Password []byte
+	// CA is the CA bundle the remote write endpoint is verified against if set.
+	CA []byte
+}
+
+// IngressOptions for the Ingress of a Thanos Tenant.
+type IngressOptions struct {
+	Host             string
+	Path             string
+	IngressClassName *string
+	TLSSecretName    *string
+	// BasicAuthSecret is the name of a Secret holding a htpasswd file under the key `auth`.
+	BasicAuthSecret string
+	Annotations     map[string]string
+}
+
+// Build compiles the Kubernetes objects for the Thanos Tenant in the namespace of the Thanos Receive.
+// This includes the Ingress if configured.
+// The remote write ConfigMap lives in the namespace of the tenant and must be built with NewRemoteWriteConfigMap.
+func (opts Options) Build() []client.Object {
+	var objs []client.Object
+	if opts.Ingress != nil {
+		objs = append(objs, newIngress(opts, GetLabels(opts)))
+	}
+	return objs
+}
+
+// GetGeneratedResourceName returns the generated name for the Thanos Tenant resources.
+func (opts Options) GetGeneratedResourceName() string {
+	name := fmt.Sprintf("%s-%s", Name, opts.Owner)
+	return manifests.ValidateAndSanitizeResourceName(name)
+}
+
+// GetRemoteWriteURL returns the URL remote write clients of the tenant should send data to.
+// If an Ingress is configured, the URL is built from the host and path of the Ingress.
+// Otherwise, the in-cluster address of the Thanos Receive router is used.
+func (opts Options) GetRemoteWriteURL() string {
+	if opts.Ingress != nil {
+		scheme := "http"
+		if opts.Ingress.TLSSecretName != nil {
+			scheme = "https"
+		}
+		return fmt.Sprintf("%s://%s%s", scheme, opts.Ingress.Host, opts.Ingress.getPath())
+	}
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d%s", opts.RouterService, opts.Namespace, manifestreceive.RemoteWritePort, DefaultRemoteWritePath)
+}
+
+func (io *IngressOptions) getPath() string {
+	if io.Path == "" {
+		return DefaultRemoteWritePath
+	}
+	return io.Path
+}
+
+// NewIngress creates a new Ingress for the Thanos Tenant.
+func NewIngress(opts Options) *networkingv1.Ingress {
+	return newIngress(opts, GetLabels(opts))
+}
+
+func newIngress(opts Options, objectMetaLabels map[string]string) *networkingv1.Ingress {
+	annotations := maps.Clone(opts.Annotations)
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	maps.Copy(annotations, opts.Ingress.Annotations)
+	if opts.Ingress.BasicAuthSecret != "" {
+		annotations[basicAuthTypeAnnotation] = "basic"
+		annotations[basicAuthSecretAnnotation] = opts.Ingress.BasicAuthSecret
+		annotations[basicAuthRealmAnnotation] = fmt.Sprintf("Authentication required for tenant %s", opts.TenantID)
+	}
+
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        opts.GetGeneratedResourceName(),
+			Namespace:   opts.Namespace,
+			Labels:      objectMetaLabels,
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: opts.Ingress.IngressClassName,
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: opts.Ingress.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     opts.Ingress.getPath(),
+									PathType: ptr.To(networkingv1.PathTypeExact),
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: opts.RouterService,
+											Port: networkingv1.ServiceBackendPort{
+												Name: manifestreceive.RemoteWritePortName,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if opts.Ingress.TLSSecretName != nil {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      []string{opts.Ingress.Host},
+				SecretName: *opts.Ingress.TLSSecretName,
+			},
+		}
+	}
+	return ingress
+}
+
+type remoteWriteConfig struct {
+	RemoteWrite []remoteWriteEndpoint `yaml:"remote_write"`
+}
+
+type remoteWriteEndpoint struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// GenerateRemoteWriteConfig returns a Prometheus remote write configuration snippet for the Thanos Tenant.
+func GenerateRemoteWriteConfig(opts Options) string {
+	conf := remoteWriteConfig{
+		RemoteWrite: []remoteWriteEndpoint{
+			{
+				URL:     opts.GetRemoteWriteURL(),
+				Headers: map[string]string{TenantHeader: opts.TenantID},
+			},
+		},
+	}
+
+	b, err := yaml.Marshal(conf)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// NewRemoteWriteConfigMap creates a ConfigMap in the target namespace holding the remote write configuration for the tenant.
+func NewRemoteWriteConfigMap(opts Options) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        opts.GetGeneratedResourceName(),
+			Namespace:   opts.TargetNamespace,
+			Labels:      GetLabels(opts),
+			Annotations: opts.Annotations,
+		},
+		Data: map[string]string{
+			RemoteWriteConfigKey: GenerateRemoteWriteConfig(opts),
+		},
+	}
+}
+
+type prometheusRemoteWrite struct {
+	URL       string                    `yaml:"url"`
+	Headers   map[string]string         `yaml:"headers"`
+	BasicAuth *prometheusBasicAuth      `yaml:"basicAuth,omitempty"`
+	TLSConfig *prometheusRemoteWriteTLS `yaml:"tlsConfig,omitempty"`
+}
+
+type prometheusBasicAuth struct {
+	Username secretKeyRef `yaml:"username"`
+	Password secretKeyRef `yaml:"password"`
+}
+
+type prometheusRemoteWriteTLS struct {
+	CA struct {
+		Secret secretKeyRef `yaml:"secret"`
+	} `yaml:"ca"`
+}
+
+type secretKeyRef struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+}
+
+// GeneratePrometheusRemoteWrite returns the remoteWrite field of a prometheus-operator Prometheus resource
+// sending the samples of the Thanos Tenant to the remote write endpoint.
+// The credentials are referenced from the Secret built by NewPrometheusRemoteWriteSecret.
+func GeneratePrometheusRemoteWrite(opts Options, creds PrometheusRemoteWriteCredentials) string {
+	name := opts.GetGeneratedResourceName()
+	rw := prometheusRemoteWrite{
+		URL:     opts.GetRemoteWriteURL(),
+		Headers: map[string]string{TenantHeader: opts.TenantID},
+	}
+	if creds.Username != nil && creds.Password != nil {
+		rw.BasicAuth = &prometheusBasicAuth{
+			Username: secretKeyRef{Name: name, Key: PrometheusRemoteWriteUsernameKey},
+			Password: secretKeyRef{Name: name, Key: PrometheusRemoteWritePasswordKey},
+		}
+	}
+	if creds.CA != nil {
+		rw.TLSConfig = &prometheusRemoteWriteTLS{}
+		rw.TLSConfig.CA.Secret = secretKeyRef{Name: name, Key: PrometheusRemoteWriteCAKey}
+	}
+
+	b, err := yaml.Marshal([]prometheusRemoteWrite{rw})
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// NewPrometheusRemoteWriteSecret creates a Secret in the given namespace holding the remoteWrite field of
+// prometheus-operator Prometheus resources for the tenant and the credentials it references.
+func NewPrometheusRemoteWriteSecret(opts Options, namespace string, creds PrometheusRemoteWriteCredentials) *corev1.Secret {
+	data := map[string][]byte{
+		RemoteWriteConfigKey: []byte(GeneratePrometheusRemoteWrite(opts, creds)),
+	}
+	if creds.Username != nil && creds.Password != nil {
+		data[PrometheusRemoteWriteUsernameKey] = creds.Username
+		data[PrometheusRemoteWritePasswordKey] = creds.Password
+	}
+	if creds.CA != nil {
+		data[PrometheusRemoteWriteCAKey] = creds.CA
+	}
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        opts.GetGeneratedResourceName(),
+			Namespace:   namespace,
+			Labels:      manifests.MergeLabels(opts.Labels, opts.GetPrometheusRemoteWriteSelectorLabels()),
+			Annotations: opts.Annotations,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// GetRequiredLabels returns a map of labels that can be used to look up Thanos Tenant resources.
+func GetRequiredLabels() map[string]string {
+	return map[string]string{
+		manifests.NameLabel:      Name,
+		manifests.ComponentLabel: ComponentName,
+		manifests.PartOfLabel:    manifests.DefaultPartOfLabel,
+		manifests.ManagedByLabel: manifests.DefaultManagedByLabel,
+	}
+}
+
+// GetSelectorLabels returns a map of labels that can be used to look up Thanos Tenant resources.
+func (opts Options) GetSelectorLabels() map[string]string {
+	labels := GetRequiredLabels()
+	labels[manifests.InstanceLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.GetGeneratedResourceName())
+	labels[manifests.OwnerLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.Owner)
+	return labels
+}
+
+// GetPrometheusRemoteWriteSelectorLabels returns a map of labels that can be used to look up the Prometheus
+// remote write Secrets of the tenant. The Secrets are published to other namespaces, so the labels also identify
+// the namespace of the tenant to tell them apart from the Secrets of same-named tenants.
+func (opts Options) GetPrometheusRemoteWriteSelectorLabels() map[string]string {
+	labels := opts.GetSelectorLabels()
+	labels[manifests.OwnerNamespaceLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.Namespace)
+	return labels
+}
+
+// GetLabels returns the ObjectMeta labels for Thanos Tenant.
+func GetLabels(opts Options) map[string]string {
+	return manifests.MergeLabels(opts.Labels, opts.GetSelectorLabels())
+}
@@ -0,0 +1,198 @@
+package tenant
+
+import (
+	"testing"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/test/utils"
+
+	"k8s.io/utils/ptr"
+)
+
+const (
+	someCustomLabelValue string = "xyz"
+	someOtherLabelValue  string = "abc"
+)
+
+func TestBuild(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+		TenantID:        "team-a",
+		RouterService:   "thanos-receive-router-test",
+		TargetNamespace: "team-a",
+	}
+
+	if objs := opts.Build(); len(objs) != 0 {
+		t.Fatalf("expected no objects without ingress, got %d", len(objs))
+	}
+
+	opts.Ingress = &IngressOptions{Host: "receive.example.com"}
+	objs := opts.Build()
+	if len(objs) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(objs))
+	}
+	utils.ValidateObjectsEqual(t, objs[0], NewIngress(opts))
+}
+
+func TestNewIngress(t *testing.T) {
+	extraLabels := map[string]string{
+		"some-custom-label": someCustomLabelValue,
+		"some-other-label":  someOtherLabelValue,
+	}
+
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+			Labels: map[string]string{
+				"some-custom-label":      someCustomLabelValue,
+				"some-other-label":       someOtherLabelValue,
+				"app.kubernetes.io/name": "expect-to-be-discarded",
+			},
+		},
+		TenantID:      "team-a",
+		RouterService: "thanos-receive-router-test",
+		Ingress: &IngressOptions{
+			Host:             "receive.example.com",
+			IngressClassName: ptr.To("nginx"),
+			TLSSecretName:    ptr.To("receive-tls"),
+			BasicAuthSecret:  "team-a-auth",
+			Annotations: map[string]string{
+				"some": "annotation",
+			},
+		},
+	}
+
+	ingress := NewIngress(opts)
+	utils.ValidateNameNamespaceAndLabels(t, ingress, opts.GetGeneratedResourceName(), opts.Namespace, GetLabels(opts))
+	utils.ValidateHasLabels(t, ingress, extraLabels)
+	utils.ValidateHasLabels(t, ingress, opts.GetSelectorLabels())
+
+	if ingress.Annotations["some"] != "annotation" {
+		t.Errorf("expected ingress to have additional annotations, got %v", ingress.Annotations)
+	}
+	if ingress.Annotations[basicAuthSecretAnnotation] != "team-a-auth" {
+		t.Errorf("expected ingress to reference basic auth secret team-a-auth, got %s", ingress.Annotations[basicAuthSecretAnnotation])
+	}
+
+	path := ingress.Spec.Rules[0].HTTP.Paths[0]
+	if path.Path != DefaultRemoteWritePath {
+		t.Errorf("expected ingress path to default to %s, got %s", DefaultRemoteWritePath, path.Path)
+	}
+	if path.Backend.Service.Name != opts.RouterService {
+		t.Errorf("expected ingress backend to be %s, got %s", opts.RouterService, path.Backend.Service.Name)
+	}
+	if ingress.Spec.TLS[0].SecretName != "receive-tls" {
+		t.Errorf("expected ingress tls secret to be receive-tls, got %s", ingress.Spec.TLS[0].SecretName)
+	}
+}
+
+func TestNewRemoteWriteConfigMap(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		opts      Options
+		expectURL string
+	}{
+		{
+			name: "test in cluster remote write url",
+			opts: Options{
+				Options: manifests.Options{
+					Owner:     "test",
+					Namespace: "ns",
+				},
+				TenantID:        "team-a",
+				RouterService:   "thanos-receive-router-test",
+				TargetNamespace: "team-a",
+			},
+			expectURL: "http://thanos-receive-router-test.ns.svc.cluster.local:19291/api/v1/receive",
+		},
+		{
+			name: "test ingress remote write url",
+			opts: Options{
+				Options: manifests.Options{
+					Owner:     "test",
+					Namespace: "ns",
+				},
+				TenantID:        "team-a",
+				RouterService:   "thanos-receive-router-test",
+				TargetNamespace: "team-a",
+				Ingress: &IngressOptions{
+					Host:          "receive.example.com",
+					Path:          "/team-a/api/v1/receive",
+					TLSSecretName: ptr.To("receive-tls"),
+				},
+			},
+			expectURL: "https://receive.example.com/team-a/api/v1/receive",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.opts.GetRemoteWriteURL(); got != tc.expectURL {
+				t.Errorf("expected remote write url %s, got %s", tc.expectURL, got)
+			}
+
+			cm := NewRemoteWriteConfigMap(tc.opts)
+			utils.ValidateNameNamespaceAndLabels(t, cm, tc.opts.GetGeneratedResourceName(), tc.opts.TargetNamespace, GetLabels(tc.opts))
+
+			expect := "remote_write:\n- url: " + tc.expectURL + "\n  headers:\n    THANOS-TENANT: team-a\n"
+			if cm.Data[RemoteWriteConfigKey] != expect {
+				t.Errorf("expected remote write config %q, got %q", expect, cm.Data[RemoteWriteConfigKey])
+			}
+		})
+	}
+}
+
+func TestNewPrometheusRemoteWriteSecret(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+		TenantID:        "team-a",
+		RouterService:   "thanos-receive-router-test",
+		TargetNamespace: "team-a",
+	}
+
+	secret := NewPrometheusRemoteWriteSecret(opts, "team-b", PrometheusRemoteWriteCredentials{})
+	utils.ValidateNameNamespaceAndLabels(t, secret, opts.GetGeneratedResourceName(), "team-b", opts.GetPrometheusRemoteWriteSelectorLabels())
+	if secret.Labels[manifests.OwnerNamespaceLabel] != "ns" {
+		t.Errorf("expected Secret to be labelled with the tenant namespace, got %v", secret.Labels)
+	}
+	expect := `- url: http://thanos-receive-router-test.ns.svc.cluster.local:19291/api/v1/receive
+  headers:
+    THANOS-TENANT: team-a
+`
+	if got := string(secret.Data[RemoteWriteConfigKey]); got != expect {
+		t.Errorf("expected remote write %q, got %q", expect, got)
+	}
+	if len(secret.Data) != 1 {
+		t.Errorf("expected no credentials, got %v", secret.Data)
+	}
+
+	creds := PrometheusRemoteWriteCredentials{Username: []byte("team-a"), Password: []byte("secret"), CA: []byte("ca")}
+	secret = NewPrometheusRemoteWriteSecret(opts, "team-b", creds)
+	expect = `- url: http://thanos-receive-router-test.ns.svc.cluster.local:19291/api/v1/receive
+  headers:
+    THANOS-TENANT: team-a
+  basicAuth:
+    username:
+      name: thanos-tenant-test
+      key: username
+    password:
+      name: thanos-tenant-test
+      key: password
+  tlsConfig:
+    ca:
+      secret:
+        name: thanos-tenant-test
+        key: ca.crt
+`
+	if got := string(secret.Data[RemoteWriteConfigKey]); got != expect {
+		t.Errorf("expected remote write %q, got %q", expect, got)
+	}
+	if string(secret.Data[PrometheusRemoteWritePasswordKey]) != "secret" || string(secret.Data[PrometheusRemoteWriteCAKey]) != "ca" {
+		t.Errorf("expected credentials to be copied, got %v", secret.Data)
+	}
+}
@@ -0,0 +1,100 @@
+package manifests
+
+import (
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	grpcServerTLSName = "grpc-server-tls"
+	grpcClientTLSName = "grpc-client-tls"
+	tlsMountPath      = "/etc/thanos/tls"
+)
+
+// TLSOptions configure the TLS of the gRPC server or client of a component.
+// Certificates are mounted into the Thanos container from Secrets.
+type TLSOptions struct {
+	// CertSecret is the name of a Secret holding the certificate and key in tls.crt and tls.key.
+	// It is required for servers. Clients present the certificate to servers requiring client certificates.
+	CertSecret string
+	// CA selects the CA bundle in a Secret.
+	// Servers require and verify client certificates against it. Clients verify servers against it.
+	CA *corev1.SecretKeySelector
+	// ServerName is the name clients verify the server certificate against.
+	ServerName string
+	// InsecureSkipVerify disables the verification of the server certificate by clients.
+	InsecureSkipVerify bool
+}
+
+// GRPCServerTLSFlags returns the flags enabling TLS on the gRPC server of the component.
+// Builders of components serving gRPC must add them when GRPCServerTLS is set.
+func (o Options) GRPCServerTLSFlags() []string {
+	t := o.GRPCServerTLS
+	if t == nil {
+		return nil
+	}
+	certDir := path.Join(tlsMountPath, grpcServerTLSName, "cert")
+	flags := []string{
+		"--grpc-server-tls-cert=" + path.Join(certDir, corev1.TLSCertKey),
+		"--grpc-server-tls-key=" + path.Join(certDir, corev1.TLSPrivateKeyKey),
+	}
+	if t.CA != nil {
+		flags = append(flags, "--grpc-server-tls-client-ca="+path.Join(tlsMountPath, grpcServerTLSName, "ca", t.CA.Key))
+	}
+	return flags
+}
+
+// GRPCClientTLSFlags returns the flags enabling TLS on the connections of the component to gRPC servers.
+// Builders of components dialing gRPC servers must add them when GRPCClientTLS is set.
+func (o Options) GRPCClientTLSFlags() []string {
+	t := o.GRPCClientTLS
+	if t == nil {
+		return nil
+	}
+	flags := []string{"--grpc-client-tls-secure"}
+	if t.CertSecret != "" {
+		certDir := path.Join(tlsMountPath, grpcClientTLSName, "cert")
+		flags = append(flags,
+			"--grpc-client-tls-cert="+path.Join(certDir, corev1.TLSCertKey),
+			"--grpc-client-tls-key="+path.Join(certDir, corev1.TLSPrivateKeyKey),
+		)
+	}
+	if t.CA != nil {
+		flags = append(flags, "--grpc-client-tls-ca="+path.Join(tlsMountPath, grpcClientTLSName, "ca", t.CA.Key))
+	}
+	if t.ServerName != "" {
+		flags = append(flags, "--grpc-client-server-name="+t.ServerName)
+	}
+	if t.InsecureSkipVerify {
+		flags = append(flags, "--grpc-client-tls-skip-verify")
+	}
+	return flags
+}
+
+// addTLSVolumes mounts the certificate and CA Secrets of the TLS options into the first container of the Pod.
+func addTLSVolumes(spec *corev1.PodSpec, name string, t *TLSOptions) {
+	if t == nil {
+		return
+	}
+	if t.CertSecret != "" {
+		addSecretVolume(spec, name+"-cert", t.CertSecret, path.Join(tlsMountPath, name, "cert"))
+	}
+	if t.CA != nil {
+		addSecretVolume(spec, name+"-ca", t.CA.Name, path.Join(tlsMountPath, name, "ca"))
+	}
+}
+
+func addSecretVolume(spec *corev1.PodSpec, volume, secret, mountPath string) {
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: volume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: secret},
+		},
+	})
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      volume,
+		MountPath: mountPath,
+		ReadOnly:  true,
+	})
+}
@@ -0,0 +1,77 @@
+package manifests
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGRPCTLSFlags(t *testing.T) {
+	if flags := (Options{}).GRPCServerTLSFlags(); flags != nil {
+		t.Errorf("expected no server flags without TLS, got %v", flags)
+	}
+	if flags := (Options{}).GRPCClientTLSFlags(); flags != nil {
+		t.Errorf("expected no client flags without TLS, got %v", flags)
+	}
+
+	ca := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "thanos-ca"}, Key: "ca.crt"}
+	opts := Options{
+		GRPCServerTLS: &TLSOptions{CertSecret: "thanos-server", CA: ca},
+		GRPCClientTLS: &TLSOptions{CertSecret: "thanos-client", CA: ca, ServerName: "thanos.example.com"},
+	}
+
+	expectServer := []string{
+		"--grpc-server-tls-cert=/etc/thanos/tls/grpc-server-tls/cert/tls.crt",
+		"--grpc-server-tls-key=/etc/thanos/tls/grpc-server-tls/cert/tls.key",
+		"--grpc-server-tls-client-ca=/etc/thanos/tls/grpc-server-tls/ca/ca.crt",
+	}
+	if flags := opts.GRPCServerTLSFlags(); !reflect.DeepEqual(flags, expectServer) {
+		t.Errorf("expected server flags %v, got %v", expectServer, flags)
+	}
+
+	expectClient := []string{
+		"--grpc-client-tls-secure",
+		"--grpc-client-tls-cert=/etc/thanos/tls/grpc-client-tls/cert/tls.crt",
+		"--grpc-client-tls-key=/etc/thanos/tls/grpc-client-tls/cert/tls.key",
+		"--grpc-client-tls-ca=/etc/thanos/tls/grpc-client-tls/ca/ca.crt",
+		"--grpc-client-server-name=thanos.example.com",
+	}
+	if flags := opts.GRPCClientTLSFlags(); !reflect.DeepEqual(flags, expectClient) {
+		t.Errorf("expected client flags %v, got %v", expectClient, flags)
+	}
+
+	opts.GRPCClientTLS = &TLSOptions{InsecureSkipVerify: true}
+	if flags := opts.GRPCClientTLSFlags(); !reflect.DeepEqual(flags, []string{"--grpc-client-tls-secure", "--grpc-client-tls-skip-verify"}) {
+		t.Errorf("unexpected client flags %v", flags)
+	}
+}
+
+func TestAugmentWithOptions_GRPCTLS(t *testing.T) {
+	sts := &appsv1.StatefulSet{}
+	sts.Spec.Template.Spec.Containers = []corev1.Container{{Name: "thanos"}}
+
+	AugmentWithOptions(sts, Options{
+		GRPCServerTLS: &TLSOptions{
+			CertSecret: "thanos-server",
+			CA:         &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "thanos-ca"}, Key: "ca.crt"},
+		},
+	})
+
+	expectVolumes := []corev1.Volume{
+		{Name: "grpc-server-tls-cert", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "thanos-server"}}},
+		{Name: "grpc-server-tls-ca", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "thanos-ca"}}},
+	}
+	if !reflect.DeepEqual(sts.Spec.Template.Spec.Volumes, expectVolumes) {
+		t.Errorf("expected volumes %v, got %v", expectVolumes, sts.Spec.Template.Spec.Volumes)
+	}
+
+	expectMounts := []corev1.VolumeMount{
+		{Name: "grpc-server-tls-cert", MountPath: "/etc/thanos/tls/grpc-server-tls/cert", ReadOnly: true},
+		{Name: "grpc-server-tls-ca", MountPath: "/etc/thanos/tls/grpc-server-tls/ca", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(sts.Spec.Template.Spec.Containers[0].VolumeMounts, expectMounts) {
+		t.Errorf("expected volume mounts %v, got %v", expectMounts, sts.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+}
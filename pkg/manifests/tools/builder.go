@@ -0,0 +1,296 @@
+package tools
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Name is the name of the Thanos Tools component.
+	Name = "thanos-tools"
+
+	// ComponentName is the name of the Thanos Tools component.
+	ComponentName = "thanos-tools"
+)
+
+const (
+	objectStoreEnvVarName = "OBJSTORE_CONFIG"
+	tmpVolumeName         = "tmp"
+	tmpVolumeMountPath    = "/var/thanos/tmp"
+)
+
+// Options for Thanos Tools.
+// Exactly one of Mark, Rewrite, Retention or Verify should be set.
+type Options struct {
+	manifests.Options
+	// NamePrefix replaces Name as the prefix of the generated resource name, so that the Jobs run on behalf of
+	// other resources do not collide with the Jobs of ThanosTools resources.
+	NamePrefix string
+	// OperationName is an optional name for the operation.
+	// If set, it is appended to the generated resource name so that an owner can run multiple operations.
+	OperationName string
+	// ObjStoreSecret is the secret key selector for the object store configuration.
+	ObjStoreSecret corev1.SecretKeySelector
+	// BackoffLimit is the number of retries before the Job is considered failed.
+	BackoffLimit *int32
+	Mark         *MarkOptions
+	Rewrite      *RewriteOptions
+	Retention    *RetentionOptions
+	// Verify runs `thanos tools bucket verify` against the whole bucket.
+	Verify bool
+}
+
+// MarkOptions for the `thanos tools bucket mark` operation.
+type MarkOptions struct {
+	Marker   string
+	BlockIDs []string
+	Details  string
+	Remove   bool
+}
+
+func (mo *MarkOptions) toArgs() []string {
+	var args []string
+	if mo == nil {
+		return args
+	}
+
+	args = append(args, "mark", fmt.Sprintf("--marker=%s", mo.Marker), fmt.Sprintf("--details=%s", mo.Details))
+	for _, id := range mo.BlockIDs {
+		args = append(args, fmt.Sprintf("--id=%s", id))
+	}
+	if mo.Remove {
+		args = append(args, "--remove")
+	}
+	return args
+}
+
+// RewriteOptions for the `thanos tools bucket rewrite` operation.
+type RewriteOptions struct {
+	BlockIDs           []string
+	DeleteMatchers     []string
+	DryRun             bool
+	DeleteSourceBlocks bool
+}
+
+func (ro *RewriteOptions) toArgs() []string {
+	var args []string
+	if ro == nil {
+		return args
+	}
+
+	args = append(args, "rewrite",
+		fmt.Sprintf("--tmp.dir=%s", tmpVolumeMountPath),
+		fmt.Sprintf("--rewrite.to-delete-config=%s", ro.toDeleteConfig()),
+	)
+	for _, id := range ro.BlockIDs {
+		args = append(args, fmt.Sprintf("--id=%s", id))
+	}
+	if ro.DryRun {
+		args = append(args, "--dry-run")
+	} else {
+		args = append(args, "--no-dry-run")
+		if ro.DeleteSourceBlocks {
+			args = append(args, "--delete-blocks")
+		}
+	}
+	return args
+}
+
+func (ro *RewriteOptions) toDeleteConfig() string {
+	var sb strings.Builder
+	for _, m := range ro.DeleteMatchers {
+		sb.WriteString(fmt.Sprintf("- matchers: '%s'\n", m))
+	}
+	return sb.String()
+}
+
+// RetentionOptions for the `thanos tools bucket retention` operation.
+type RetentionOptions struct {
+	// Raw is the retention configuration for the raw samples.
+	Raw *manifests.Duration
+	// FiveMinutes is the retention configuration for samples of resolution 1 (5 minutes).
+	FiveMinutes *manifests.Duration
+	// OneHour is the retention configuration for samples of resolution 2 (1 hour).
+	OneHour *manifests.Duration
+}
+
+func (ro *RetentionOptions) toArgs() []string {
+	var args []string
+	if ro == nil {
+		return args
+	}
+
+	args = append(args, "retention")
+	if ro.Raw != nil {
+		args = append(args, fmt.Sprintf("--retention.resolution-raw=%s", string(*ro.Raw)))
+	}
+	if ro.FiveMinutes != nil {
+		args = append(args, fmt.Sprintf("--retention.resolution-5m=%s", string(*ro.FiveMinutes)))
+	}
+	if ro.OneHour != nil {
+		args = append(args, fmt.Sprintf("--retention.resolution-1h=%s", string(*ro.OneHour)))
+	}
+	return args
+}
+
+// Build compiles all the Kubernetes objects for the Thanos Tools operation.
+// This includes the ServiceAccount and Job.
+func (opts Options) Build() []client.Object {
+	var objs []client.Object
+	selectorLabels := opts.GetSelectorLabels()
+	objectMetaLabels := GetLabels(opts)
+
+	objs = append(objs, manifests.BuildServiceAccounts(opts.GetGeneratedResourceName(), opts.Namespace, selectorLabels, opts.Annotations, opts.ServiceAccount)...)
+	objs = append(objs, newJob(opts, objectMetaLabels))
+	return objs
+}
+
+// GetGeneratedResourceName returns the generated name for the Thanos Tools operation.
+// If OperationName is set, the name will be generated from the Options.Owner and OperationName.
+func (opts Options) GetGeneratedResourceName() string {
+	name := fmt.Sprintf("%s-%s", cmp.Or(opts.NamePrefix, Name), opts.getOwner())
+	if opts.OperationName != "" {
+		name = fmt.Sprintf("%s-%s", name, opts.OperationName)
+	}
+	return manifests.ValidateAndSanitizeResourceName(name)
+}
+
+func (opts Options) getOwner() string {
+	return opts.Owner
+}
+
+// NewJob creates a new Job for the Thanos Tools operation.
+func NewJob(opts Options) *batchv1.Job {
+	return newJob(opts, GetLabels(opts))
+}
+
+func newJob(opts Options, objectMetaLabels map[string]string) *batchv1.Job {
+	name := opts.GetGeneratedResourceName()
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   opts.Namespace,
+			Labels:      objectMetaLabels,
+			Annotations: opts.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: opts.BackoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: objectMetaLabels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					SecurityContext:    manifests.DefaultPodSecurityContext(),
+					ServiceAccountName: name,
+					Containers: []corev1.Container{
+						{
+							Image:           opts.GetContainerImage(),
+							Name:            Name,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							// Ensure restrictive context for the container
+							// More info: https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted
+							SecurityContext: manifests.DefaultSecurityContext(),
+							Env: []corev1.EnvVar{
+								{
+									Name: objectStoreEnvVarName,
+									ValueFrom: &corev1.EnvVarSource{
+										SecretKeyRef: &corev1.SecretKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: opts.ObjStoreSecret.Name,
+											},
+											Key:      opts.ObjStoreSecret.Key,
+											Optional: ptr.To(false),
+										},
+									},
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      tmpVolumeName,
+									MountPath: tmpVolumeMountPath,
+								},
+							},
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+							TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+							Args:                     toolsArgsFrom(opts),
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: tmpVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	manifests.AugmentWithOptions(job, opts.Options)
+	return job
+}
+
+func toolsArgsFrom(opts Options) []string {
+	args := []string{"tools", "bucket"}
+
+	switch {
+	case opts.Mark != nil:
+		args = append(args, opts.Mark.toArgs()...)
+	case opts.Rewrite != nil:
+		args = append(args, opts.Rewrite.toArgs()...)
+	case opts.Retention != nil:
+		args = append(args, opts.Retention.toArgs()...)
+	case opts.Verify:
+		args = append(args, "verify")
+	}
+
+	args = append(args, opts.ToFlags()...)
+	args = append(args, fmt.Sprintf("--objstore.config=$(%s)", objectStoreEnvVarName))
+
+	if opts.Additional.Args != nil {
+		args = append(args, opts.Additional.Args...)
+	}
+
+	return manifests.PruneEmptyArgs(args)
+}
+
+// GetRequiredLabels returns a map of labels that can be used to look up ThanosTools resources.
+func GetRequiredLabels() map[string]string {
+	return map[string]string{
+		manifests.NameLabel:      Name,
+		manifests.ComponentLabel: ComponentName,
+		manifests.PartOfLabel:    manifests.DefaultPartOfLabel,
+		manifests.ManagedByLabel: manifests.DefaultManagedByLabel,
+	}
+}
+
+// GetSelectorLabels returns a map of labels that can be used to look up ThanosTools resources.
+func (opts Options) GetSelectorLabels() map[string]string {
+	labels := GetRequiredLabels()
+	labels[manifests.InstanceLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.GetGeneratedResourceName())
+	labels[manifests.OwnerLabel] = manifests.ValidateAndSanitizeNameToValidLabelValue(opts.getOwner())
+	return labels
+}
+
+// GetLabels returns the ObjectMeta labels for Thanos Tools.
+func GetLabels(opts Options) map[string]string {
+	return manifests.MergeLabels(opts.Labels, opts.GetSelectorLabels())
+}
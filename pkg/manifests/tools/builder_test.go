@@ -0,0 +1,264 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/test/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	someCustomLabelValue string = "xyz"
+	someOtherLabelValue  string = "abc"
+)
+
+func TestNewJob(t *testing.T) {
+	extraLabels := map[string]string{
+		"some-custom-label": someCustomLabelValue,
+		"some-other-label":  someOtherLabelValue,
+	}
+
+	buildOpts := func() Options {
+		return Options{
+			Options: manifests.Options{
+				Owner:     "test",
+				Namespace: "ns",
+				Image:     ptr.To("some-custom-image"),
+				Labels: map[string]string{
+					"some-custom-label":      someCustomLabelValue,
+					"some-other-label":       someOtherLabelValue,
+					"app.kubernetes.io/name": "expect-to-be-discarded",
+				},
+				Annotations: map[string]string{
+					"test": "annotation",
+				},
+			},
+			ObjStoreSecret: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "objstore"},
+				Key:                  "thanos.yaml",
+			},
+			BackoffLimit: ptr.To(int32(0)),
+		}
+	}
+
+	for _, tc := range []struct {
+		name       string
+		opts       func() Options
+		expectArgs []string
+	}{
+		{
+			name: "test mark job",
+			opts: func() Options {
+				opts := buildOpts()
+				opts.Mark = &MarkOptions{
+					Marker:   "no-compact-mark.json",
+					BlockIDs: []string{"01JBS8GGM7BEZRPEAQ1J39DKQ4", "01JBS8GGM7BEZRPEAQ1J39DKQ5"},
+					Details:  "halts compaction",
+				}
+				return opts
+			},
+			expectArgs: []string{
+				"tools",
+				"bucket",
+				"mark",
+				"--marker=no-compact-mark.json",
+				"--details=halts compaction",
+				"--id=01JBS8GGM7BEZRPEAQ1J39DKQ4",
+				"--id=01JBS8GGM7BEZRPEAQ1J39DKQ5",
+				"--log.level=info",
+				"--log.format=logfmt",
+				"--objstore.config=$(OBJSTORE_CONFIG)",
+			},
+		},
+		{
+			name: "test rewrite job",
+			opts: func() Options {
+				opts := buildOpts()
+				opts.Rewrite = &RewriteOptions{
+					BlockIDs:           []string{"01JBS8GGM7BEZRPEAQ1J39DKQ4"},
+					DeleteMatchers:     []string{`{__name__="up"}`},
+					DeleteSourceBlocks: true,
+				}
+				return opts
+			},
+			expectArgs: []string{
+				"tools",
+				"bucket",
+				"rewrite",
+				"--tmp.dir=/var/thanos/tmp",
+				"--rewrite.to-delete-config=- matchers: '{__name__=\"up\"}'\n",
+				"--id=01JBS8GGM7BEZRPEAQ1J39DKQ4",
+				"--no-dry-run",
+				"--delete-blocks",
+				"--log.level=info",
+				"--log.format=logfmt",
+				"--objstore.config=$(OBJSTORE_CONFIG)",
+			},
+		},
+		{
+			name: "test retention job",
+			opts: func() Options {
+				opts := buildOpts()
+				opts.Retention = &RetentionOptions{
+					Raw:     ptr.To(manifests.Duration("30d")),
+					OneHour: ptr.To(manifests.Duration("1y")),
+				}
+				return opts
+			},
+			expectArgs: []string{
+				"tools",
+				"bucket",
+				"retention",
+				"--retention.resolution-raw=30d",
+				"--retention.resolution-1h=1y",
+				"--log.level=info",
+				"--log.format=logfmt",
+				"--objstore.config=$(OBJSTORE_CONFIG)",
+			},
+		},
+		{
+			name: "test verify job",
+			opts: func() Options {
+				opts := buildOpts()
+				opts.NamePrefix = "thanos-verify"
+				opts.Verify = true
+				return opts
+			},
+			expectArgs: []string{
+				"tools",
+				"bucket",
+				"verify",
+				"--log.level=info",
+				"--log.format=logfmt",
+				"--objstore.config=$(OBJSTORE_CONFIG)",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := tc.opts()
+			job := NewJob(opts)
+			objectMetaLabels := GetLabels(opts)
+			name := opts.GetGeneratedResourceName()
+			utils.ValidateNameNamespaceAndLabels(t, job, name, opts.Namespace, objectMetaLabels)
+			utils.ValidateHasLabels(t, job, opts.GetSelectorLabels())
+			utils.ValidateHasLabels(t, job, extraLabels)
+
+			if job.Spec.Template.Spec.ServiceAccountName != name {
+				t.Errorf("expected tools job to have service account name %s, got %s", name, job.Spec.Template.Spec.ServiceAccountName)
+			}
+
+			if job.Spec.Template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+				t.Errorf("expected tools job to have restart policy Never, got %s", job.Spec.Template.Spec.RestartPolicy)
+			}
+
+			if *job.Spec.BackoffLimit != 0 {
+				t.Errorf("expected tools job to have backoff limit 0, got %d", *job.Spec.BackoffLimit)
+			}
+
+			c := job.Spec.Template.Spec.Containers[0]
+			if c.Image != opts.GetContainerImage() {
+				t.Errorf("expected tools job to have image %s, got %s", opts.GetContainerImage(), c.Image)
+			}
+
+			if !reflect.DeepEqual(c.Args, tc.expectArgs) {
+				t.Errorf("expected tools job to have args %v, got %v", tc.expectArgs, c.Args)
+			}
+
+			if c.Env[0].ValueFrom.SecretKeyRef.Name != "objstore" {
+				t.Errorf("expected tools job to reference objstore secret, got %s", c.Env[0].ValueFrom.SecretKeyRef.Name)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	opts := Options{
+		Options: manifests.Options{
+			Owner:     "test",
+			Namespace: "ns",
+		},
+		Mark: &MarkOptions{
+			Marker:   "deletion-mark.json",
+			BlockIDs: []string{"01JBS8GGM7BEZRPEAQ1J39DKQ4"},
+			Details:  "bad block",
+		},
+	}
+
+	objs := opts.Build()
+	if len(objs) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objs))
+	}
+
+	utils.ValidateIsNamedServiceAccount(t, objs[0], opts, opts.Namespace)
+	utils.ValidateObjectsEqual(t, objs[1], NewJob(opts))
+}
+
+func TestRewriteDryRun(t *testing.T) {
+	ro := &RewriteOptions{
+		BlockIDs:           []string{"01JBS8GGM7BEZRPEAQ1J39DKQ4"},
+		DeleteMatchers:     []string{`{job="foo"}`},
+		DryRun:             true,
+		DeleteSourceBlocks: true,
+	}
+
+	args := ro.toArgs()
+	for _, arg := range args {
+		if arg == "--delete-blocks" || arg == "--no-dry-run" {
+			t.Errorf("expected dry run to not include %s", arg)
+		}
+	}
+	if args[len(args)-1] != "--dry-run" {
+		t.Errorf("expected dry run to include --dry-run, got %v", args)
+	}
+}
+
+func TestOptions_GetGeneratedResourceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{
+			name: "Test without operation name",
+			opts: Options{
+				Options: manifests.Options{
+					Owner: "valid-owner",
+				},
+			},
+			expected: "thanos-tools-valid-owner",
+		},
+		{
+			name: "Test with operation name",
+			opts: Options{
+				Options: manifests.Options{
+					Owner: "valid-owner",
+				},
+				OperationName: "bad-blocks",
+			},
+			expected: "thanos-tools-valid-owner-bad-blocks",
+		},
+		{
+			name: "Test with name prefix",
+			opts: Options{
+				Options: manifests.Options{
+					Owner: "valid-owner",
+				},
+				NamePrefix:    "thanos-compact",
+				OperationName: "mark-bad-blocks",
+			},
+			expected: "thanos-compact-valid-owner-mark-bad-blocks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.GetGeneratedResourceName(); got != tt.expected {
+				t.Errorf("GetGeneratedResourceName() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
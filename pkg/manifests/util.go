@@ -2,6 +2,7 @@ package manifests
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -40,6 +41,21 @@ func PruneEmptyArgs(args []string) []string {
 	return args
 }
 
+// ExternalLabelFlags returns the --label flags setting the given external labels, sorted by label name,
+// so that the arguments of the container do not change between reconciliations and trigger needless rollouts.
+func ExternalLabelFlags(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	flags := make([]string, 0, len(names))
+	for _, name := range names {
+		flags = append(flags, fmt.Sprintf(`--label=%s="%s"`, name, labels[name]))
+	}
+	return flags
+}
+
 // IsGrpcServiceWithLabels returns true if the given object is a gRPC service with required labels.
 // The requiredLabels map is used to match the labels of the object.
 // The function returns false if the object is not a service or if it does not have a gRPC port.
@@ -62,6 +78,19 @@ func IsGrpcServiceWithLabels(obj client.Object, requiredLabels map[string]string
 	return 0, false
 }
 
+// HTTPPortNameAnnotation is set on Services whose HTTP port is not named http and holds the name of the port,
+// so that components discovering the Service through DNS SRV records look up the right port.
+const HTTPPortNameAnnotation = "monitoring.thanos.io/http-port-name"
+
+// GetHTTPPortName returns the name of the HTTP port of the Service, which is http unless the Service
+// has the HTTPPortNameAnnotation.
+func GetHTTPPortName(svc *corev1.Service) string {
+	if name := svc.GetAnnotations()[HTTPPortNameAnnotation]; name != "" {
+		return name
+	}
+	return "http"
+}
+
 // HasRequiredLabels returns true if the given object has the required labels.
 func HasRequiredLabels(obj client.Object, requiredLabels map[string]string) bool {
 	for k, v := range requiredLabels {
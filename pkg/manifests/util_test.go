@@ -1,6 +1,7 @@
 package manifests
 
 import (
+	"reflect"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
@@ -143,3 +144,13 @@ func TestHasRequiredLabels(t *testing.T) {
 		t.Errorf("expected true, got false")
 	}
 }
+
+func TestExternalLabelFlags(t *testing.T) {
+	expect := []string{`--label=cluster="eu-1"`, `--label=receive="true"`, `--label=replica="$(POD_NAME)"`}
+	for i := 0; i < 10; i++ {
+		flags := ExternalLabelFlags(map[string]string{"replica": "$(POD_NAME)", "cluster": "eu-1", "receive": "true"})
+		if !reflect.DeepEqual(flags, expect) {
+			t.Fatalf("expected %v, got %v", expect, flags)
+		}
+	}
+}
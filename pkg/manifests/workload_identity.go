@@ -0,0 +1,64 @@
+package manifests
+
+import (
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// ObjStoreTokenEnvVarName is the environment variable holding the path of the projected service account token
+	// used to authenticate to the object storage. Thanos substitutes $(OBJSTORE_TOKEN_FILE) in its object storage configuration.
+	ObjStoreTokenEnvVarName = "OBJSTORE_TOKEN_FILE"
+	// DefaultTokenExpirationSeconds is the default lifetime of a projected service account token.
+	DefaultTokenExpirationSeconds int64 = 3600
+
+	objStoreTokenVolumeName = "objstore-token"
+	objStoreTokenMountPath  = "/var/run/secrets/thanos.io/objstore"
+	objStoreTokenFileName   = "token"
+)
+
+// TokenProjection projects a service account token into the Thanos component container,
+// for object storage providers which accept OIDC federation tokens.
+type TokenProjection struct {
+	// Audience is the intended audience of the token.
+	Audience string
+	// ExpirationSeconds is the requested lifetime of the token.
+	ExpirationSeconds int64
+}
+
+// ObjStoreTokenPath is the path at which the projected service account token is mounted.
+func ObjStoreTokenPath() string {
+	return path.Join(objStoreTokenMountPath, objStoreTokenFileName)
+}
+
+// addTokenProjection adds the Volume of the projected token to the Pod, mounts it into its first container
+// and exposes its path in the ObjStoreTokenEnvVarName environment variable.
+// The environment variable is prepended, so that additional environment variables can reference it.
+func addTokenProjection(spec *corev1.PodSpec, tp TokenProjection) {
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: objStoreTokenVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          tp.Audience,
+							ExpirationSeconds: ptr.To(tp.ExpirationSeconds),
+							Path:              objStoreTokenFileName,
+						},
+					},
+				},
+			},
+		},
+	})
+
+	container := &spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      objStoreTokenVolumeName,
+		MountPath: objStoreTokenMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append([]corev1.EnvVar{{Name: ObjStoreTokenEnvVarName, Value: ObjStoreTokenPath()}}, container.Env...)
+}
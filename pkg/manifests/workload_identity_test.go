@@ -0,0 +1,64 @@
+package manifests
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestAugmentWithOptions_TokenProjection(t *testing.T) {
+	sts := &appsv1.StatefulSet{}
+	sts.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: "thanos",
+		Env:  []corev1.EnvVar{{Name: "OBJSTORE_CONFIG", Value: "config"}},
+	}}
+
+	AugmentWithOptions(sts, Options{
+		Additional: Additional{
+			Env: []corev1.EnvVar{{Name: "AWS_WEB_IDENTITY_TOKEN_FILE", Value: "$(OBJSTORE_TOKEN_FILE)"}},
+		},
+		ObjStoreTokenProjection: &TokenProjection{Audience: "sts.amazonaws.com", ExpirationSeconds: 7200},
+	})
+
+	expectVolumes := []corev1.Volume{
+		{
+			Name: "objstore-token",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{
+						{
+							ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+								Audience:          "sts.amazonaws.com",
+								ExpirationSeconds: ptr.To(int64(7200)),
+								Path:              "token",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(sts.Spec.Template.Spec.Volumes, expectVolumes) {
+		t.Errorf("expected volumes %v, got %v", expectVolumes, sts.Spec.Template.Spec.Volumes)
+	}
+
+	expectMounts := []corev1.VolumeMount{
+		{Name: "objstore-token", MountPath: "/var/run/secrets/thanos.io/objstore", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(sts.Spec.Template.Spec.Containers[0].VolumeMounts, expectMounts) {
+		t.Errorf("expected volume mounts %v, got %v", expectMounts, sts.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+
+	// the token path must be defined before the environment variables referencing it
+	expectEnv := []corev1.EnvVar{
+		{Name: ObjStoreTokenEnvVarName, Value: "/var/run/secrets/thanos.io/objstore/token"},
+		{Name: "OBJSTORE_CONFIG", Value: "config"},
+		{Name: "AWS_WEB_IDENTITY_TOKEN_FILE", Value: "$(OBJSTORE_TOKEN_FILE)"},
+	}
+	if !reflect.DeepEqual(sts.Spec.Template.Spec.Containers[0].Env, expectEnv) {
+		t.Errorf("expected env %v, got %v", expectEnv, sts.Spec.Template.Spec.Containers[0].Env)
+	}
+}
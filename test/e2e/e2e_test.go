@@ -30,9 +30,9 @@ import (
 
 	"github.com/thanos-community/thanos-operator/api/v1alpha1"
 	"github.com/thanos-community/thanos-operator/internal/controller"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests/compact"
-	"github.com/thanos-community/thanos-operator/internal/pkg/manifests/receive"
+	"github.com/thanos-community/thanos-operator/pkg/manifests"
+	"github.com/thanos-community/thanos-operator/pkg/manifests/compact"
+	"github.com/thanos-community/thanos-operator/pkg/manifests/receive"
 	"github.com/thanos-community/thanos-operator/test/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -220,10 +220,12 @@ var _ = Describe("controller", Ordered, func() {
 					Spec: v1alpha1.ThanosReceiveSpec{
 						Ingester: v1alpha1.IngesterSpec{
 							DefaultObjectStorageConfig: v1alpha1.ObjectStorageConfig{
-								LocalObjectReference: corev1.LocalObjectReference{
-									Name: objStoreSecret,
+								SecretKeySelector: corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: objStoreSecret,
+									},
+									Key: objStoreSecretKey,
 								},
-								Key: objStoreSecretKey,
 							},
 							Hashrings: []v1alpha1.IngesterHashringSpec{
 								{
@@ -452,10 +454,12 @@ var _ = Describe("controller", Ordered, func() {
 							},
 						},
 						ObjectStorageConfig: v1alpha1.ObjectStorageConfig{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: objStoreSecret,
+							SecretKeySelector: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: objStoreSecret,
+								},
+								Key: objStoreSecretKey,
 							},
-							Key: objStoreSecretKey,
 						},
 						AlertmanagerURL: "http://alertmanager.com:9093",
 					},
@@ -573,11 +577,13 @@ var _ = Describe("controller", Ordered, func() {
 							ShardReplicas: 2,
 						},
 						StorageSize: "100Mi",
-						ObjectStorageConfig: v1alpha1.ObjectStorageConfig{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: objStoreSecret,
+						ObjectStorageConfig: &v1alpha1.ObjectStorageConfig{
+							SecretKeySelector: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: objStoreSecret,
+								},
+								Key: objStoreSecretKey,
 							},
-							Key: objStoreSecretKey,
 						},
 					},
 				}
@@ -617,10 +623,12 @@ var _ = Describe("controller", Ordered, func() {
 						CommonFields: v1alpha1.CommonFields{},
 						StorageSize:  "100Mi",
 						ObjectStorageConfig: v1alpha1.ObjectStorageConfig{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: objStoreSecret,
+							SecretKeySelector: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: objStoreSecret,
+								},
+								Key: objStoreSecretKey,
 							},
-							Key: objStoreSecretKey,
 						},
 					},
 				}